@@ -0,0 +1,82 @@
+// Comando createadmin: cria uma conta de administrador diretamente no banco.
+// Uso: go run ./cmd/createadmin -email admin@gostock.com [-tenant acme]
+// Sem -tenant, o administrador pertence ao tenant padrão, que opera a plataforma.
+// A senha é lida de ADMIN_PASSWORD ou, se ausente, da entrada padrão (não fica no histórico do shell).
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+
+	"gostock/config"
+	"gostock/internal/domain"
+	"gostock/internal/pkg/cache"
+	"gostock/internal/pkg/database"
+	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/tenant"
+	"gostock/internal/repository/tenantrepo"
+	"gostock/internal/repository/userrepo"
+	"gostock/internal/service/userservice"
+)
+
+func main() {
+	// Load .env file
+	if err := godotenv.Load(); err != nil {
+		log.Printf("⚠️ Aviso: Arquivo .env não encontrado ou erro de leitura. Carregando configs apenas do ambiente do sistema: %v", err)
+	}
+
+	cfg := config.LoadConfig()
+
+	var email, tenantSlug string
+	flag.StringVar(&email, "email", "", "email do administrador")
+	flag.StringVar(&tenantSlug, "tenant", "", "identificador (slug) da empresa; vazio: tenant padrão")
+	flag.Parse()
+	if email == "" {
+		log.Fatal("createadmin: informe o email com -email")
+	}
+
+	password := os.Getenv("ADMIN_PASSWORD")
+	if password == "" {
+		fmt.Print("Senha do administrador: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			log.Fatalf("createadmin: falha ao ler a senha: %v", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+
+	db, err := database.NewPostgresDB(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("createadmin: falha ao conectar ao DB: %v", err)
+	}
+	defer db.Close()
+
+	// Apenas o repositório é necessário: a criação de contas não emite nem revoga tokens
+	appLog := logger.NewLogger(cfg.LogLevel)
+	userRepo := userrepo.NewUserRepository(db, cfg.DBTimeout, appLog)
+	userSvc := userservice.NewService(userRepo, userRepo, nil, nil, cfg.RefreshTokenExpiry, appLog)
+
+	ctx := context.Background()
+	if tenantSlug != "" {
+		tenantRepo := tenantrepo.NewTenantRepository(db, cache.NewRedisClient(cfg.RedisAddr), cfg.DBTimeout, appLog)
+		found, err := tenantRepo.FindTenantBySlug(ctx, strings.ToLower(tenantSlug))
+		if err != nil {
+			log.Fatalf("createadmin: %v", err)
+		}
+		ctx = tenant.WithID(ctx, found.ID)
+	}
+
+	admin, err := userSvc.CreateAdmin(ctx, domain.UserRegistration{Email: email, Password: password})
+	if err != nil {
+		log.Fatalf("createadmin: %v", err)
+	}
+
+	fmt.Printf("Administrador criado: %s (%s)\n", admin.Email, admin.ID)
+}
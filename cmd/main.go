@@ -17,6 +17,10 @@
 // @in header
 // @name Authorization
 
+// @securityDefinitions.apiKey IntegrationKey
+// @in header
+// @name X-API-Key
+
 package main
 
 import (
@@ -25,6 +29,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -32,24 +37,43 @@ import (
 
 	// Nossos pacotes de infraestrutura e utilitários
 	"gostock/config"
+	"gostock/internal/domain"
 	"gostock/internal/pkg/cache"
 	"gostock/internal/pkg/database"
 	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/loginguard"
+	"gostock/internal/pkg/mailer"
+	"gostock/internal/pkg/middleware"
+	"gostock/internal/pkg/oidc"
+	"gostock/internal/pkg/storage"
 	"gostock/internal/pkg/token"
+	"gostock/internal/pkg/totp"
 
 	// Camadas do Produto para Injeção de Dependências
+	"gostock/internal/api/barcode" // Handler de Códigos de Barras
+	"gostock/internal/api/export"  // Handler de Exportação
+	"gostock/internal/api/jwks"    // Chaves públicas (JWKS)
+	"gostock/internal/api/price"   // Handler de Preços
 	"gostock/internal/api/product" // Handlers
+	"gostock/internal/api/role"    // Handler de Papéis e Permissões
 	"gostock/internal/api/router"  // Roteador central
 	"gostock/internal/api/user"
 	"gostock/internal/api/stock" // Handler de Estoque
+	"gostock/internal/api/tenant" // Handler de Empresas
 	"gostock/internal/api/warehouse" // NOVO: Handler de Armazém
 	"gostock/internal/repository/productrepo" // Acesso a Dados
 	"gostock/internal/repository/userrepo"
+	"gostock/internal/repository/pricerepo" // Repositório de Preços
+	"gostock/internal/repository/rolerepo"  // Repositório de Papéis
 	"gostock/internal/repository/stockrepo" // Repositório de Estoque
+	"gostock/internal/repository/tenantrepo" // Repositório de Empresas
 	"gostock/internal/repository/warehouserepo" // NOVO: Repositório de Armazém
+	"gostock/internal/service/priceservice"   // Serviço de Preços
 	"gostock/internal/service/productservice" // Lógica de Negócio
+	"gostock/internal/service/roleservice"    // Serviço de Papéis e Permissões
 	"gostock/internal/service/userservice"
 	"gostock/internal/service/stockservice" // Serviço de Estoque
+	"gostock/internal/service/tenantservice" // Serviço de Empresas
 	"gostock/internal/service/warehouseservice" // NOVO: Serviço de Armazém
 )
 
@@ -94,13 +118,35 @@ func main() {
 	productSvc := productservice.NewService(productRepo, log)
 	log.Debug("Serviço de Produto inicializado.", nil)
 
+	// B.1 Armazenamento local das mídias enviadas por upload
+	mediaStorage, err := storage.NewLocalStorage(cfg.MediaDir, cfg.MediaBaseURL)
+	if err != nil {
+		log.Fatal("Falha ao preparar o diretório de mídias.", err)
+	}
+	productSvc.SetMediaStorage(mediaStorage)
+	log.Debug("Armazenamento de mídias inicializado.", map[string]interface{}{"dir": cfg.MediaDir})
+
 	// C. Handler de Produto (Camada de Apresentação)
 	productHandler := product.NewHandler(productSvc, log)
 	log.Debug("Handler de Produto inicializado.", nil)
 
 	// D. Serviço de Tokens (JWT)
-	jwtExpiry := time.Hour * time.Duration(cfg.JWTExpiryHours)
-	tokenSvc := token.NewService(cfg.JWTSecretKey, jwtExpiry)
+	// Com JWT_KEYS, assina com chaves assimétricas (kid); senão, HS256 com JWT_SECRET_KEY
+	tokenSvc := token.NewService(cfg.JWTSecretKey, cfg.JWTExpiry)
+	if cfg.JWTKeys != "" {
+		keySet, err := token.LoadKeySet(cfg.JWTKeys, cfg.JWTActiveKeyID)
+		if err != nil {
+			log.Fatal("Falha ao carregar as chaves de assinatura JWT.", err)
+		}
+		tokenSvc = token.NewServiceWithKeys(keySet, cfg.JWTExpiry)
+		log.Info("Assinatura JWT com chaves assimétricas.", map[string]interface{}{"active_kid": cfg.JWTActiveKeyID})
+		if cfg.JWTAcceptLegacyHMAC {
+			// Apenas durante a migração: desative depois que os tokens HS256 expirarem
+			tokenSvc.AcceptLegacyHMAC(cfg.JWTSecretKey)
+			log.Warn("Tokens HS256 antigos continuam aceitos (JWT_ACCEPT_LEGACY_HMAC).", nil)
+		}
+	}
+	revocationList := token.NewRevocationList(cacheClient) // Tokens revogados (logout/reuso de refresh token)
 	log.Debug("Serviço de Tokens JWT inicializado.", nil)
 
 	// E. Repositório de Usuário (Camada de Acesso a Dados)
@@ -108,9 +154,74 @@ func main() {
 	log.Debug("Repositório de Usuário inicializado.", nil)
 
 	// F. Serviço de Usuário (Camada de Lógica de Negócio)
-	userSvc := userservice.NewService(userRepo, tokenSvc, log)
+	userSvc := userservice.NewService(userRepo, userRepo, tokenSvc, revocationList, cfg.RefreshTokenExpiry, log)
 	log.Debug("Serviço de Usuário inicializado.", nil)
 
+	// F.1 Envio de e-mails: SMTP quando SMTP_HOST está definido; senão, arquivos em MAIL_DIR ou o log
+	var mail mailer.Mailer
+	if cfg.SMTPHost != "" {
+		smtpMailer, err := mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		})
+		if err != nil {
+			log.Fatal("Falha ao configurar o envio de e-mails (SMTP).", err)
+		}
+		mail = smtpMailer
+	} else {
+		logMailer, err := mailer.NewLogMailer(cfg.MailDir, log)
+		if err != nil {
+			log.Fatal("Falha ao preparar o diretório de e-mails.", err)
+		}
+		mail = logMailer
+		log.Warn("SMTP_HOST não definido: e-mails gravados localmente, sem envio.", map[string]interface{}{"mail_dir": cfg.MailDir})
+	}
+	// Redefinição de senha e verificação de e-mail
+	userSvc.SetAccountEmails(userRepo, mail, userservice.AccountEmailConfig{
+		PasswordResetExpiry:       cfg.PasswordResetExpiry,
+		PasswordResetURL:          cfg.PasswordResetURL,
+		EmailVerificationExpiry:   cfg.EmailVerificationExpiry,
+		VerifyEmailURL:            cfg.VerifyEmailURL,
+		EmailVerificationRequired: cfg.EmailVerificationRequired,
+	})
+
+	// F.2 Proteção contra força bruta no login (falhas por conta e por IP no Redis)
+	userSvc.SetLoginGuard(loginguard.NewGuard(cacheClient, loginguard.Policy{
+		MaxAttempts:   cfg.LoginMaxAttempts,
+		IPMaxAttempts: cfg.LoginIPMaxAttempts,
+		Window:        cfg.LoginAttemptWindow,
+		Lockout:       cfg.LoginLockout,
+		MaxLockout:    cfg.LoginMaxLockout,
+	}))
+
+	// F.3 Autenticação em dois fatores (TOTP); os segredos são cifrados com MFA_ENCRYPTION_KEY
+	if cfg.MFAEncryptionKey != "" {
+		secretCipher, err := totp.NewSecretCipher(cfg.MFAEncryptionKey)
+		if err != nil {
+			log.Fatal("Falha ao preparar a cifragem dos segredos TOTP.", err)
+		}
+		requiredRoles := make([]domain.UserRole, 0, len(cfg.MFARequiredRoles))
+		for _, role := range cfg.MFARequiredRoles {
+			requiredRoles = append(requiredRoles, domain.UserRole(strings.ToLower(role)))
+		}
+		userSvc.SetMFA(userRepo, tokenSvc, secretCipher, userservice.MFAConfig{
+			Issuer:          cfg.MFAIssuer,
+			ChallengeExpiry: cfg.MFAChallengeExpiry,
+			RequiredRoles:   requiredRoles,
+		})
+		log.Debug("Autenticação em dois fatores habilitada.", map[string]interface{}{"required_roles": cfg.MFARequiredRoles})
+	} else if len(cfg.MFARequiredRoles) > 0 {
+		log.Fatal("MFA_REQUIRED_ROLES exige MFA_ENCRYPTION_KEY.", nil)
+	} else {
+		log.Warn("Autenticação em dois fatores desativada: defina MFA_ENCRYPTION_KEY.", nil)
+	}
+
+	// F.4 Chaves de API (header X-API-Key) e contas de serviço para integrações
+	userSvc.SetAPIKeys(userRepo)
+
 	// G. Handler de Usuário
 	userHandler := user.NewHandler(userSvc, log)
 	log.Debug("Handler de Usuário inicializado.", nil)
@@ -120,8 +231,9 @@ func main() {
 	stockRepo := stockrepo.NewStockRepository(db, cfg.DBTimeout, log)
 	log.Debug("Repositório de Estoque inicializado.", nil)
 
-	// I. Serviço de Estoque
-	stockSvc := stockservice.NewService(stockRepo, log)
+	// I. Serviço de Estoque (os armazéns atribuídos a cada usuário limitam o acesso ao estoque)
+	warehouseRepo := warehouserepo.NewWarehouseRepository(db, cfg.DBTimeout, log)
+	stockSvc := stockservice.NewService(stockRepo, warehouseRepo, log)
 	log.Debug("Serviço de Estoque inicializado.", nil)
 
 	// J. Handler de Estoque
@@ -130,8 +242,7 @@ func main() {
 	// --- FIM Estoque ---
 
 	// --- NOVO: Armazéns ---
-	// K. Repositório de Armazéns
-	warehouseRepo := warehouserepo.NewWarehouseRepository(db, cfg.DBTimeout, log)
+	// K. Repositório de Armazéns (criado junto ao Serviço de Estoque)
 	log.Debug("Repositório de Armazéns inicializado.", nil)
 
 	// L. Serviço de Armazéns
@@ -143,14 +254,95 @@ func main() {
 	log.Debug("Handler de Armazéns inicializado.", nil)
 	// --- FIM NOVO: Armazéns ---
 
+	// N. Handler de Exportação (usa os serviços de Produto, Estoque e Armazéns)
+	exportHandler := export.NewHandler(productSvc, stockSvc, warehouseSvc, log)
+	log.Debug("Handler de Exportação inicializado.", nil)
+
+	// O. Tabelas de Preços (Repositório -> Serviço -> Handler)
+	priceRepo := pricerepo.NewPriceRepository(db, cfg.DBTimeout, log)
+	priceSvc := priceservice.NewService(priceRepo, log)
+	priceHandler := price.NewHandler(priceSvc, log)
+	log.Debug("Handler de Preços inicializado.", nil)
+
+	// P. Handler de Códigos de Barras (usa os serviços de Produto e Estoque)
+	barcodeHandler := barcode.NewHandler(productSvc, stockSvc, log)
+	log.Debug("Handler de Códigos de Barras inicializado.", nil)
+
+	// Q. Handler de JWKS (chaves públicas de verificação)
+	jwksHandler := jwks.NewHandler(tokenSvc, log)
+
+	// R. Papéis e Permissões (Repositório -> Serviço -> Handler); o serviço também
+	// atende o middleware RequirePermission
+	roleRepo := rolerepo.NewRoleRepository(db, cacheClient, cfg.DBTimeout, log)
+	roleSvc := roleservice.NewService(roleRepo, revocationList, cfg.JWTExpiry, log)
+	// Primeiro administrador (SETUP_TOKEN) e convites assinados, que validam o papel convidado
+	userSvc.SetOnboarding(roleRepo, tokenSvc, userservice.OnboardingConfig{
+		SetupToken:       cfg.SetupToken,
+		InvitationExpiry: cfg.InvitationExpiry,
+		InvitationURL:    cfg.InvitationURL,
+	})
+	roleHandler := role.NewHandler(roleSvc, log)
+	log.Debug("Handler de Papéis inicializado.", nil)
+
+	// S. Empresas (Repositório -> Serviço -> Handler): as contas respeitam a ativação, o registro
+	// público e o limite de usuários de cada empresa; o serviço também resolve o header X-Tenant
+	tenantRepo := tenantrepo.NewTenantRepository(db, cacheClient, cfg.DBTimeout, log)
+	tenantSvc := tenantservice.NewService(tenantRepo, log)
+	userSvc.SetTenants(tenantRepo)
+	tenantHandler := tenant.NewHandler(tenantSvc, log)
+	log.Debug("Handler de Empresas inicializado.", nil)
+
+	// T. Login com provedor OpenID Connect (o login local continua disponível); o papel dos
+	// usuários pode seguir os grupos do provedor (OIDC_ROLE_MAPPING)
+	if cfg.OIDCIssuerURL != "" {
+		if cfg.OIDCClientID == "" {
+			log.Fatal("OIDC_ISSUER_URL exige OIDC_CLIENT_ID.", nil)
+		}
+		roleMapping := make([]userservice.OIDCRoleMapping, 0, len(cfg.OIDCRoleMapping))
+		for _, pair := range cfg.OIDCRoleMapping {
+			group, roleName, ok := strings.Cut(pair, "=")
+			if !ok || strings.TrimSpace(group) == "" || strings.TrimSpace(roleName) == "" {
+				log.Fatal("OIDC_ROLE_MAPPING inválido: use pares grupo=papel separados por vírgula.", nil)
+			}
+			roleMapping = append(roleMapping, userservice.OIDCRoleMapping{Group: strings.TrimSpace(group), Role: strings.ToLower(strings.TrimSpace(roleName))})
+		}
+		scopes := cfg.OIDCScopes
+		if len(scopes) == 0 {
+			scopes = []string{"profile", "email"}
+		}
+		oidcClient := oidc.NewClient(oidc.Config{
+			IssuerURL:    cfg.OIDCIssuerURL,
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: cfg.OIDCClientSecret,
+			RedirectURL:  cfg.OIDCRedirectURL,
+			Scopes:       scopes,
+			GroupsClaim:  cfg.OIDCGroupsClaim,
+		})
+		userSvc.SetOIDC(oidcClient, oidc.NewStateStore(cacheClient), userRepo, roleRepo, userservice.OIDCConfig{
+			DefaultRole:   strings.ToLower(cfg.OIDCDefaultRole),
+			AutoProvision: cfg.OIDCAutoProvision,
+			RoleMapping:   roleMapping,
+			StateExpiry:   cfg.OIDCStateExpiry,
+		})
+		log.Info("Login OIDC habilitado.", map[string]interface{}{"issuer": cfg.OIDCIssuerURL, "auto_provision": cfg.OIDCAutoProvision, "role_mapping": cfg.OIDCRoleMapping})
+	}
+
 	// 4. Configuração e Início do Roteador/Servidor
 
 	// O roteador recebe os Handlers e aplica middlewares (futuramente)
-	r := router.NewRouter(productHandler, userHandler, stockHandler, warehouseHandler, tokenSvc, cacheClient)
+	r := router.NewRouter(productHandler, userHandler, stockHandler, warehouseHandler, exportHandler, priceHandler, barcodeHandler, jwksHandler, roleHandler, tenantHandler, tokenSvc, userSvc, roleSvc, cacheClient)
+
+	// Arquivos de mídia enviados por upload (públicos, como as imagens do catálogo, mas servidos
+	// apenas para a empresa da requisição)
+	r.Handle("GET /media/", http.StripPrefix("/media", mediaStorage.Handler()))
+
+	// O roteador final, com a empresa do header X-Tenant e o ID da requisição (header X-Request-ID)
+	// em todas as respostas e linhas de log
+	handler := middleware.RequestID(middleware.NewTenantMiddleware(tenantSvc)(r))
 
 	server := &http.Server{
 		Addr:         ":" + cfg.Port,
-		Handler:      r, // O roteador final
+		Handler:      handler,
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  60 * time.Second,
@@ -0,0 +1,45 @@
+// Comando mockoidc: provedor OpenID Connect local para testar o login OIDC sem um provedor real.
+// Toda autorização é aprovada automaticamente para o usuário definido pelas flags.
+// Uso: go run ./cmd/mockoidc -email maria@empresa.com -groups estoque,admins
+// e, na API: OIDC_ISSUER_URL=http://localhost:9000 OIDC_CLIENT_ID=gostock
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"strings"
+
+	"gostock/internal/pkg/oidc/oidctest"
+)
+
+func main() {
+	var addr, issuer, clientID, clientSecret, email, name, groups, subject string
+	flag.StringVar(&addr, "addr", ":9000", "endereço em que o provedor escuta")
+	flag.StringVar(&issuer, "issuer", "http://localhost:9000", "emissor anunciado (deve ser igual a OIDC_ISSUER_URL)")
+	flag.StringVar(&clientID, "client-id", "gostock", "client_id aceito")
+	flag.StringVar(&clientSecret, "client-secret", "", "segredo do cliente (vazio: cliente público)")
+	flag.StringVar(&email, "email", "usuario@empresa.com", "e-mail do usuário autenticado")
+	flag.StringVar(&name, "name", "Usuário de Teste", "nome do usuário autenticado")
+	flag.StringVar(&groups, "groups", "", "grupos do usuário, separados por vírgula")
+	flag.StringVar(&subject, "sub", "", "sub do usuário (padrão: o e-mail)")
+	flag.Parse()
+
+	provider, err := oidctest.NewProvider(strings.TrimRight(issuer, "/"), clientID, clientSecret)
+	if err != nil {
+		log.Fatalf("mockoidc: %v", err)
+	}
+	if subject == "" {
+		subject = email
+	}
+	user := oidctest.User{Subject: subject, Email: email, EmailVerified: true, Name: name}
+	for _, group := range strings.Split(groups, ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			user.Groups = append(user.Groups, group)
+		}
+	}
+	provider.SetUser(&user)
+
+	log.Printf("mockoidc: emissor %s, client_id '%s', usuário %s (grupos %v)", provider.Issuer, clientID, email, user.Groups)
+	log.Fatal(http.ListenAndServe(addr, provider))
+}
@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -24,12 +25,65 @@ type Config struct {
 	CacheTimeout time.Duration
 
 	// Segurança (JWT)
-	JWTSecretKey   string
-	JWTExpiryHours int
+	JWTSecretKey        string
+	JWTKeys             string        // Chaves assimétricas "kid=arquivo.pem,..." (vazio: HS256 com JWTSecretKey)
+	JWTActiveKeyID      string        // kid da chave usada para assinar
+	JWTAcceptLegacyHMAC bool          // Com JWT_KEYS, continua aceitando tokens HS256 assinados com JWTSecretKey
+	JWTExpiry           time.Duration // Validade dos access tokens
+	RefreshTokenExpiry  time.Duration // Validade de cada refresh token
+
+	// Criação de contas (primeiro administrador e convites)
+	SetupToken       string        // Token de uso único para criar o primeiro admin (vazio: desativado)
+	InvitationExpiry time.Duration // Validade dos convites
+	InvitationURL    string        // Endereço de aceite usado nos links de convite
+
+	// Redefinição de senha e verificação de e-mail
+	PasswordResetExpiry       time.Duration // Validade dos links de redefinição de senha
+	PasswordResetURL          string        // Formulário de redefinição (vazio: o e-mail traz só o token); o token é anexado como ?token=
+	EmailVerificationExpiry   time.Duration // Validade dos links de verificação de e-mail
+	VerifyEmailURL            string        // Endereço de verificação usado nos links enviados no registro
+	EmailVerificationRequired bool          // Bloqueia o login de contas com e-mail não verificado
+
+	// Autenticação em dois fatores (TOTP)
+	MFAIssuer          string        // Nome exibido no aplicativo autenticador
+	MFAEncryptionKey   string        // Chave de cifragem dos segredos TOTP (vazio: segundo fator desativado)
+	MFAChallengeExpiry time.Duration // Validade do desafio entre os dois passos do login
+	MFARequiredRoles   []string      // Papéis que exigem o segundo fator (ex.: "admin")
+
+	// Login com provedor OpenID Connect (sem OIDC_ISSUER_URL, apenas o login local)
+	OIDCIssuerURL     string // Emissor do provedor (descoberta em /.well-known/openid-configuration)
+	OIDCClientID      string
+	OIDCClientSecret  string        // Vazio: cliente público (apenas PKCE)
+	OIDCRedirectURL   string        // Endereço de retorno registrado no provedor
+	OIDCScopes        []string      // Escopos pedidos além de "openid"
+	OIDCGroupsClaim   string        // Claim do ID token com os grupos do usuário
+	OIDCDefaultRole   string        // Papel dos usuários criados no primeiro login
+	OIDCAutoProvision bool          // Cria o usuário no primeiro login
+	OIDCRoleMapping   []string      // Pares "grupo=papel", em ordem de prioridade
+	OIDCStateExpiry   time.Duration // Tempo máximo para concluir o login no provedor
+
+	// Envio de e-mails (sem SMTP_HOST, os e-mails são gravados em MailDir ou no log)
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	MailFrom     string
+	MailDir      string
 
 	// Rate Limiting (RNF 5.2)
 	RateLimitMaxRequests int
 	RateLimitPeriod      time.Duration
+
+	// Proteção contra força bruta no login
+	LoginMaxAttempts   int           // Falhas por conta na janela até o bloqueio
+	LoginIPMaxAttempts int           // Falhas por IP na janela até o bloqueio
+	LoginAttemptWindow time.Duration // Janela de contagem das falhas
+	LoginLockout       time.Duration // Duração do primeiro bloqueio (dobra a cada reincidência)
+	LoginMaxLockout    time.Duration // Duração máxima do bloqueio
+
+	// Mídias de produto (upload local)
+	MediaDir     string // Diretório onde os arquivos enviados são gravados
+	MediaBaseURL string // URL base pública dos arquivos (servidos pela API em /media quando relativa)
 }
 
 // LoadConfig carrega as configurações a partir das variáveis de ambiente.
@@ -53,12 +107,85 @@ func LoadConfig() *Config {
 		CacheTimeout: getDurationEnv("CACHE_TIMEOUT_SEC", 10) * time.Second, // 10s padrão
 
 		// 4. Segurança (JWT)
-		JWTSecretKey:   mustGetEnv("JWT_SECRET_KEY"),
-		JWTExpiryHours: getIntEnv("JWT_EXPIRY_HOURS", 24), // 60 min padrão
+		JWTSecretKey:        getEnv("JWT_SECRET_KEY", ""),
+		JWTKeys:             getEnv("JWT_KEYS", ""),
+		JWTActiveKeyID:      getEnv("JWT_ACTIVE_KID", ""),
+		JWTAcceptLegacyHMAC: getBoolEnv("JWT_ACCEPT_LEGACY_HMAC", false),
+		JWTExpiry:           getDurationEnv("JWT_EXPIRY_MIN", 15) * time.Minute,            // 15 min padrão
+		RefreshTokenExpiry:  getDurationEnv("REFRESH_TOKEN_EXPIRY_HOURS", 720) * time.Hour, // 30 dias padrão
+
+		// Criação de contas
+		SetupToken:       getEnv("SETUP_TOKEN", ""),
+		InvitationExpiry: getDurationEnv("INVITATION_EXPIRY_HOURS", 72) * time.Hour, // 3 dias padrão
+		InvitationURL:    getEnv("INVITATION_URL", "http://localhost:8080/v1/invitations/accept"),
+
+		// Redefinição de senha e verificação de e-mail
+		PasswordResetExpiry:       getDurationEnv("PASSWORD_RESET_EXPIRY_MIN", 60) * time.Minute, // 1 hora padrão
+		PasswordResetURL:          getEnv("PASSWORD_RESET_URL", ""),
+		EmailVerificationExpiry:   getDurationEnv("EMAIL_VERIFICATION_EXPIRY_HOURS", 48) * time.Hour, // 2 dias padrão
+		VerifyEmailURL:            getEnv("VERIFY_EMAIL_URL", "http://localhost:8080/v1/verify-email"),
+		EmailVerificationRequired: getBoolEnv("EMAIL_VERIFICATION_REQUIRED", false),
+
+		// Autenticação em dois fatores
+		MFAIssuer:          getEnv("MFA_ISSUER", "GoStock"),
+		MFAEncryptionKey:   getEnv("MFA_ENCRYPTION_KEY", ""),
+		MFAChallengeExpiry: getDurationEnv("MFA_CHALLENGE_EXPIRY_MIN", 5) * time.Minute,
+		MFARequiredRoles:   getListEnv("MFA_REQUIRED_ROLES"),
+
+		// Login com provedor OpenID Connect
+		OIDCIssuerURL:     getEnv("OIDC_ISSUER_URL", ""),
+		OIDCClientID:      getEnv("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret:  getEnv("OIDC_CLIENT_SECRET", ""),
+		OIDCRedirectURL:   getEnv("OIDC_REDIRECT_URL", "http://localhost:8080/v1/oidc/callback"),
+		OIDCScopes:        getListEnv("OIDC_SCOPES"),
+		OIDCGroupsClaim:   getEnv("OIDC_GROUPS_CLAIM", "groups"),
+		OIDCDefaultRole:   getEnv("OIDC_DEFAULT_ROLE", "user"),
+		OIDCAutoProvision: getBoolEnv("OIDC_AUTO_PROVISION", true),
+		OIDCRoleMapping:   getListEnv("OIDC_ROLE_MAPPING"),
+		OIDCStateExpiry:   getDurationEnv("OIDC_STATE_EXPIRY_MIN", 10) * time.Minute,
+
+		// Envio de e-mails
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		MailFrom:     getEnv("MAIL_FROM", "GoStock <no-reply@gostock.local>"),
+		MailDir:      getEnv("MAIL_DIR", ""),
 
 		// 5. Rate Limiting
 		RateLimitMaxRequests: getIntEnv("RATE_LIMIT_MAX_REQUESTS", 100),
 		RateLimitPeriod:      getDurationEnv("RATE_LIMIT_PERIOD_MIN", 1) * time.Minute, // 1 min padrão
+
+		// Proteção contra força bruta no login
+		LoginMaxAttempts:   getIntEnv("LOGIN_MAX_ATTEMPTS", 5),
+		LoginIPMaxAttempts: getIntEnv("LOGIN_IP_MAX_ATTEMPTS", 20),
+		LoginAttemptWindow: getDurationEnv("LOGIN_ATTEMPT_WINDOW_MIN", 15) * time.Minute,
+		LoginLockout:       getDurationEnv("LOGIN_LOCKOUT_MIN", 15) * time.Minute,
+		LoginMaxLockout:    getDurationEnv("LOGIN_MAX_LOCKOUT_MIN", 1440) * time.Minute, // 24 horas padrão
+
+		// 6. Mídias
+		MediaDir:     getEnv("MEDIA_DIR", "./uploads"),
+		MediaBaseURL: getEnv("MEDIA_BASE_URL", "/media"),
+	}
+
+	// JWT_EXPIRY_HOURS foi substituída por JWT_EXPIRY_MIN; continua aceita (com aviso) enquanto as
+	// implantações migram, mas JWT_EXPIRY_MIN prevalece quando as duas estão definidas
+	if getEnv("JWT_EXPIRY_HOURS", "") != "" {
+		if getEnv("JWT_EXPIRY_MIN", "") == "" {
+			cfg.JWTExpiry = getDurationEnv("JWT_EXPIRY_HOURS", 0) * time.Hour
+		}
+		log.Printf("⚠️ JWT_EXPIRY_HOURS está obsoleta; use JWT_EXPIRY_MIN (validade dos access tokens: %s).", cfg.JWTExpiry)
+	}
+
+	// Sem chaves assimétricas, o segredo HMAC é obrigatório; com elas, só é usado com
+	// JWT_ACCEPT_LEGACY_HMAC, para aceitar os tokens HS256 emitidos antes da migração
+	if cfg.JWTKeys == "" || cfg.JWTAcceptLegacyHMAC {
+		cfg.JWTSecretKey = mustGetEnv("JWT_SECRET_KEY")
+	}
+
+	// Os segredos TOTP têm chave própria: quem obtiver o segredo dos tokens não decifra o segundo fator
+	if cfg.MFAEncryptionKey != "" && cfg.MFAEncryptionKey == cfg.JWTSecretKey {
+		log.Fatalf("❌ Erro de Configuração: MFA_ENCRYPTION_KEY deve ser diferente de JWT_SECRET_KEY.")
 	}
 
 	return cfg
@@ -112,3 +239,29 @@ func getIntEnv(key string, defaultValue int) int {
 	}
 	return value
 }
+
+// getBoolEnv lê uma variável de ambiente booleana ("true", "1", "false", "0"...).
+func getBoolEnv(key string, defaultValue bool) bool {
+	valueStr := getEnv(key, "")
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		log.Printf("⚠️ Aviso: Valor de %s ('%s') não é um booleano válido. Usando padrão (%t).", key, valueStr, defaultValue)
+		return defaultValue
+	}
+	return value
+}
+
+// getListEnv lê uma variável de ambiente com valores separados por vírgula (vazia: nil).
+func getListEnv(key string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key, ""), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -24,123 +24,61 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/login": {
-            "post": {
-                "description": "Recebe email/senha, verifica a validade e emite um JSON Web Token.",
-                "consumes": [
-                    "application/json"
-                ],
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "Publica, no formato JSON Web Key Set, as chaves públicas que verificam os access tokens: a chave ativa e as chaves em rotação. Outros serviços escolhem a chave pelo kid do cabeçalho do JWT. Com assinatura HMAC (HS256), a lista é vazia.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "users"
                 ],
-                "summary": "Autentica um usuário e retorna um JWT",
-                "parameters": [
-                    {
-                        "description": "Credenciais do usuário (email e senha)",
-                        "name": "login",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/user.LoginRequest"
-                        }
-                    }
-                ],
+                "summary": "Chaves públicas de verificação (JWKS)",
                 "responses": {
                     "200": {
-                        "description": "Token JWT emitido",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "400": {
-                        "description": "Payload inválido",
-                        "schema": {
-                            "$ref": "#/definitions/domain.ErrorResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "Credenciais inválidas",
+                        "description": "Conjunto de chaves",
                         "schema": {
-                            "$ref": "#/definitions/domain.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Erro interno do servidor",
-                        "schema": {
-                            "$ref": "#/definitions/domain.ErrorResponse"
+                            "$ref": "#/definitions/token.JWKS"
                         }
                     }
                 }
             }
         },
-        "/products": {
+        "/api-keys": {
             "get": {
-                "description": "Retorna uma lista de produtos com base em filtros e paginação.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lista as chaves do usuário autenticado (inclusive revogadas e expiradas), sem os segredos. Admins podem informar user_id para listar as chaves de outro usuário.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "api-keys"
                 ],
-                "summary": "Lista produtos com filtros e paginação",
+                "summary": "Lista as chaves de API",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Número da página",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Limite de itens por página",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filtrar por nome do produto",
-                        "name": "name",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
-                        "description": "Filtrar por SKU",
-                        "name": "sku",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "Filtrar apenas por produtos ativos",
-                        "name": "active_only",
+                        "description": "ID do dono das chaves (apenas admin)",
+                        "name": "user_id",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Lista de produtos",
+                        "description": "Chaves de API",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/domain.Product"
+                                "$ref": "#/definitions/domain.APIKey"
                             }
                         }
                     },
-                    "400": {
-                        "description": "Parâmetros de query inválidos",
-                        "schema": {
-                            "$ref": "#/definitions/domain.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Erro interno do servidor",
+                    "403": {
+                        "description": "Chaves de outro usuário sem ser admin",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResponse"
                         }
@@ -153,7 +91,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Cria um novo produto principal e suas variantes.",
+                "description": "Cria uma chave para integrações, enviada no header X-API-Key. A chave completa só é exibida nesta resposta. Os escopos (permissões do catálogo) limitam a chave, além do papel do dono; sem escopos, a chave só acessa as rotas de leitura. Admins podem criar chaves para outros usuários (user_id), como contas de serviço.",
                 "consumes": [
                     "application/json"
                 ],
@@ -161,35 +99,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "api-keys"
                 ],
-                "summary": "Cria um novo produto com variantes",
+                "summary": "Cria uma chave de API",
                 "parameters": [
                     {
-                        "description": "Dados do produto e variantes",
-                        "name": "product",
+                        "description": "Nome, escopos e expiração da chave",
+                        "name": "key",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/product.ProductCreateRequest"
+                            "$ref": "#/definitions/domain.APIKeyCreate"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Produto criado com sucesso",
+                        "description": "Chave criada (exibida apenas uma vez)",
                         "schema": {
-                            "$ref": "#/definitions/domain.Product"
+                            "$ref": "#/definitions/domain.APIKeyCreated"
                         }
                     },
                     "400": {
-                        "description": "Payload inválido",
+                        "description": "Nome, escopo ou expiração inválidos",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Erro interno do servidor",
+                    "403": {
+                        "description": "Chave para outro usuário sem ser admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Usuário não encontrado",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResponse"
                         }
@@ -197,40 +141,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/products/{id}": {
-            "get": {
-                "description": "Busca um produto específico e suas variantes pelo ID.",
-                "produces": [
-                    "application/json"
+        "/api-keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "A chave deixa de ser aceita imediatamente. O dono revoga as próprias chaves; admins, qualquer chave.",
                 "tags": [
-                    "products"
+                    "api-keys"
                 ],
-                "summary": "Obtém um produto por ID",
+                "summary": "Revoga uma chave de API",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID do Produto",
+                        "description": "ID da chave",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Produto encontrado",
-                        "schema": {
-                            "$ref": "#/definitions/domain.Product"
-                        }
+                    "204": {
+                        "description": "Chave revogada"
                     },
-                    "404": {
-                        "description": "Produto não encontrado",
+                    "400": {
+                        "description": "ID inválido",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Erro interno do servidor",
+                    "404": {
+                        "description": "Chave não encontrada",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResponse"
                         }
@@ -238,9 +181,34 @@ const docTemplate = `{
                 }
             }
         },
-        "/register": {
+        "/attributes": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attributes"
+                ],
+                "summary": "Lista as definições de atributos",
+                "responses": {
+                    "200": {
+                        "description": "Definições de atributos",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.AttributeDefinition"
+                            }
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Cria um novo usuário, hasheia a senha e salva no banco de dados.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Define um eixo de variação (ex.: \"Tamanho\") e seus valores permitidos, na ordem de exibição. Variantes que usam um atributo definido só aceitam os valores permitidos.",
                 "consumes": [
                     "application/json"
                 ],
@@ -248,56 +216,82 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "attributes"
                 ],
-                "summary": "Registra um novo usuário",
+                "summary": "Cria uma definição de atributo",
                 "parameters": [
                     {
-                        "description": "Credenciais de registro (email e senha)",
-                        "name": "registration",
+                        "description": "Nome e valores permitidos",
+                        "name": "attribute",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.UserRegistration"
+                            "$ref": "#/definitions/domain.AttributeDefinition"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Usuário criado com sucesso",
+                        "description": "Definição criada",
                         "schema": {
-                            "$ref": "#/definitions/domain.User"
+                            "$ref": "#/definitions/domain.AttributeDefinition"
                         }
                     },
                     "400": {
-                        "description": "Payload inválido (JSON malformado ou campos obrigatórios ausentes)",
+                        "description": "Payload inválido",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResponse"
                         }
                     },
                     "409": {
-                        "description": "Email já cadastrado",
+                        "description": "Atributo já definido",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResponse"
                         }
+                    }
+                }
+            }
+        },
+        "/attributes/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attributes"
+                ],
+                "summary": "Busca uma definição de atributo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do atributo",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Definição de atributo",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AttributeDefinition"
+                        }
                     },
-                    "500": {
-                        "description": "Erro interno do servidor",
+                    "404": {
+                        "description": "Atributo não encontrado",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/stock/update": {
-            "post": {
+            },
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Atualiza a quantidade de estoque para uma variante de produto em um armazém específico.",
+                "description": "Substitui o nome e os valores permitidos. Variantes existentes não são alteradas; os valores só restringem novas combinações.",
                 "consumes": [
                     "application/json"
                 ],
@@ -305,25 +299,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "stock"
+                    "attributes"
                 ],
-                "summary": "Ajusta o nível de estoque de um produto em um armazém",
+                "summary": "Atualiza uma definição de atributo",
                 "parameters": [
                     {
-                        "description": "Dados para ajuste de estoque",
-                        "name": "adjustment",
+                        "type": "string",
+                        "description": "ID do atributo",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Nome e valores permitidos",
+                        "name": "attribute",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.StockAdjustmentRequest"
+                            "$ref": "#/definitions/domain.AttributeDefinition"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Nível de estoque atualizado",
+                        "description": "Definição atualizada",
                         "schema": {
-                            "$ref": "#/definitions/domain.StockLevel"
+                            "$ref": "#/definitions/domain.AttributeDefinition"
                         }
                     },
                     "400": {
@@ -332,14 +333,46 @@ const docTemplate = `{
                             "$ref": "#/definitions/domain.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Atributo não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
                     "409": {
-                        "description": "Conflito de concorrência (versão)",
+                        "description": "Nome já usado por outro atributo",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResponse"
                         }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "As variantes que usam o atributo são mantidas; o eixo apenas deixa de ter valores restritos.",
+                "tags": [
+                    "attributes"
+                ],
+                "summary": "Remove uma definição de atributo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do atributo",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Definição removida"
                     },
-                    "500": {
-                        "description": "Erro interno do servidor",
+                    "404": {
+                        "description": "Atributo não encontrado",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResponse"
                         }
@@ -347,28 +380,42 @@ const docTemplate = `{
                 }
             }
         },
-        "/warehouses": {
+        "/barcodes": {
             "get": {
-                "description": "Retorna uma lista de todos os armazéns cadastrados.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "O código principal da variante continua no campo 'barcode' da variante e não é listado aqui.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "warehouses"
+                    "barcodes"
+                ],
+                "summary": "Lista os códigos de barras adicionais de uma variante",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID da variante",
+                        "name": "variant_id",
+                        "in": "query",
+                        "required": true
+                    }
                 ],
-                "summary": "Lista todos os armazéns",
                 "responses": {
                     "200": {
-                        "description": "Lista de armazéns",
+                        "description": "Códigos adicionais",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/domain.Warehouse"
+                                "$ref": "#/definitions/domain.VariantBarcode"
                             }
                         }
                     },
-                    "500": {
-                        "description": "Erro interno do servidor",
+                    "400": {
+                        "description": "ID inválido",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResponse"
                         }
@@ -381,7 +428,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Cria um novo armazém no sistema.",
+                "description": "Associa mais um código de barras a uma variante, opcionalmente mapeado para uma unidade de medida (ex.: o código da caixa para \"cx\", configurada em /stock/units). Códigos GTIN (EAN-8, UPC-A, EAN-13, GTIN-14) têm o dígito verificador validado.",
                 "consumes": [
                     "application/json"
                 ],
@@ -389,35 +436,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "warehouses"
+                    "barcodes"
                 ],
-                "summary": "Cria um novo armazém",
+                "summary": "Cadastra um código de barras adicional",
                 "parameters": [
                     {
-                        "description": "Dados do armazém para criação",
-                        "name": "warehouse",
+                        "description": "Código de barras adicional",
+                        "name": "barcode",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.Warehouse"
+                            "$ref": "#/definitions/domain.VariantBarcode"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Armazém criado com sucesso",
+                        "description": "Código cadastrado",
                         "schema": {
-                            "$ref": "#/definitions/domain.Warehouse"
+                            "$ref": "#/definitions/domain.VariantBarcode"
                         }
                     },
                     "400": {
-                        "description": "Payload inválido",
+                        "description": "Código inválido",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Erro interno do servidor",
+                    "404": {
+                        "description": "Variante não encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Código já está em uso",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResponse"
                         }
@@ -425,40 +478,140 @@ const docTemplate = `{
                 }
             }
         },
-        "/warehouses/{id}": {
+        "/barcodes/{code}": {
             "get": {
-                "description": "Busca um armazém específico pelo seu ID.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retorna o produto e a variante donos do código (principal ou adicional). Para códigos adicionais, 'unit' indica a unidade de medida representada (ex.: \"cx\"). Com include_stock=true, inclui o estoque atual da variante por armazém. GTINs são encontrados com ou sem zeros à esquerda (UPC-A, EAN-13 e GTIN-14 equivalentes). A leitura tem rate limit próprio (300 por minuto por IP).",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "warehouses"
+                    "barcodes"
                 ],
-                "summary": "Obtém um armazém por ID",
+                "summary": "Resolve um código de barras",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID do Armazém",
-                        "name": "id",
+                        "description": "Código de barras lido",
+                        "name": "code",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Incluir o estoque atual da variante",
+                        "name": "include_stock",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Armazém encontrado",
+                        "description": "Produto e variante do código",
                         "schema": {
-                            "$ref": "#/definitions/domain.Warehouse"
+                            "$ref": "#/definitions/domain.BarcodeLookup"
                         }
                     },
                     "404": {
-                        "description": "Armazém não encontrado",
+                        "description": "Código de barras não cadastrado",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Erro interno do servidor",
+                    "429": {
+                        "description": "Limite de leituras excedido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Códigos principais fazem parte da variante e não podem ser removidos por aqui.",
+                "tags": [
+                    "barcodes"
+                ],
+                "summary": "Remove um código de barras adicional",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código de barras adicional",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Código removido"
+                    },
+                    "404": {
+                        "description": "Código adicional não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/categories": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "categories"
+                ],
+                "summary": "Lista os esquemas de atributos personalizados",
+                "responses": {
+                    "200": {
+                        "description": "Esquemas por categoria",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.CategorySchema"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/categories/{category}/schema": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "categories"
+                ],
+                "summary": "Busca o esquema de atributos personalizados de uma categoria",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Categoria",
+                        "name": "category",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Esquema da categoria",
+                        "schema": {
+                            "$ref": "#/definitions/domain.CategorySchema"
+                        }
+                    },
+                    "404": {
+                        "description": "Categoria sem esquema",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResponse"
                         }
@@ -471,7 +624,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Atualiza os dados de um armazém existente.",
+                "description": "Define os campos tipados (string, number, bool ou enum com \"values\") aceitos em custom_attributes pelos produtos da categoria. Produtos já gravados não são revalidados.",
                 "consumes": [
                     "application/json"
                 ],
@@ -479,48 +632,36 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "warehouses"
+                    "categories"
                 ],
-                "summary": "Atualiza um armazém",
+                "summary": "Cria ou substitui o esquema de uma categoria",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID do Armazém",
-                        "name": "id",
+                        "description": "Categoria",
+                        "name": "category",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Dados do armazém para atualização",
-                        "name": "warehouse",
+                        "description": "Campos do esquema",
+                        "name": "schema",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.Warehouse"
+                            "$ref": "#/definitions/product.CategorySchemaRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Armazém atualizado com sucesso",
+                        "description": "Esquema gravado",
                         "schema": {
-                            "$ref": "#/definitions/domain.Warehouse"
+                            "$ref": "#/definitions/domain.CategorySchema"
                         }
                     },
                     "400": {
-                        "description": "Payload inválido",
-                        "schema": {
-                            "$ref": "#/definitions/domain.ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Armazém não encontrado",
-                        "schema": {
-                            "$ref": "#/definitions/domain.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Erro interno do servidor",
+                        "description": "Esquema inválido",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResponse"
                         }
@@ -533,26 +674,171 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Remove um armazém do sistema pelo seu ID.",
+                "description": "Os produtos mantêm os atributos gravados; novas gravações com custom_attributes na categoria passam a ser recusadas.",
                 "tags": [
-                    "warehouses"
+                    "categories"
                 ],
-                "summary": "Deleta um armazém",
+                "summary": "Remove o esquema de uma categoria",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID do Armazém",
-                        "name": "id",
+                        "description": "Categoria",
+                        "name": "category",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "204": {
-                        "description": "Nenhum conteúdo"
+                        "description": "Esquema removido"
                     },
                     "404": {
-                        "description": "Armazém não encontrado",
+                        "description": "Categoria sem esquema",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/export/products": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Exporta produtos (uma linha por variante) com o estoque por armazém, em CSV ou NDJSON, transmitindo linha a linha. Aceita os mesmos filtros e ordenação de GET /products (page e limit são ignorados). No CSV há uma coluna \"qty:\u003carmazém\u003e\" por armazém; no NDJSON, o objeto \"quantities\" é indexado pelo ID do armazém.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "export"
+                ],
+                "summary": "Exporta o catálogo de produtos",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Formato de saída",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por nome do produto",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por SKU",
+                        "name": "sku",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filtrar apenas por produtos ativos",
+                        "name": "is_active",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "name",
+                            "price",
+                            "sku",
+                            "created_at",
+                            "updated_at"
+                        ],
+                        "type": "string",
+                        "description": "Campo de ordenação",
+                        "name": "sort_by",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Direção da ordenação",
+                        "name": "sort_order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Preço mínimo (inclusivo)",
+                        "name": "min_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Preço máximo (inclusivo)",
+                        "name": "max_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Criados a partir de (AAAA-MM-DD ou RFC3339)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Criados até (AAAA-MM-DD ou RFC3339)",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Atualizados a partir de (AAAA-MM-DD ou RFC3339)",
+                        "name": "updated_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Atualizados até (AAAA-MM-DD ou RFC3339)",
+                        "name": "updated_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filtrar produtos com (true) ou sem (false) variantes",
+                        "name": "has_variants",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por código de barras de uma variante",
+                        "name": "barcode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por atributo de variante",
+                        "name": "attribute",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Valor do atributo de variante; exige 'attribute'",
+                        "name": "attribute_value",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Arquivo CSV ou NDJSON",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Parâmetros inválidos",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResponse"
                         }
@@ -565,47 +851,4779 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "domain.ErrorResponse": {
-            "description": "Estrutura padronizada para respostas de erro na API.",
-            "type": "object",
-            "properties": {
-                "category": {
-                    "type": "string",
-                    "example": "VALIDATION_ERROR"
-                },
-                "code": {
-                    "type": "integer",
-                    "example": 400
-                },
-                "message": {
-                    "type": "string",
-                    "example": "O nome do armazém não pode ser vazio."
-                }
-            }
         },
-        "domain.Product": {
-            "type": "object",
-            "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "description": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "is_active": {
+        "/export/stock": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Exporta os níveis de estoque (uma linha por variante e armazém) em CSV ou NDJSON, transmitindo linha a linha. Aceita os filtros de produto de GET /products e, opcionalmente, um armazém.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "export"
+                ],
+                "summary": "Exporta os níveis de estoque",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Formato de saída",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Restringe a exportação a um armazém",
+                        "name": "warehouse_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por nome do produto",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por SKU",
+                        "name": "sku",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filtrar apenas por produtos ativos",
+                        "name": "is_active",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "name",
+                            "price",
+                            "sku",
+                            "created_at",
+                            "updated_at"
+                        ],
+                        "type": "string",
+                        "description": "Ordenação dos produtos dentro de cada armazém",
+                        "name": "sort_by",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Direção da ordenação",
+                        "name": "sort_order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Preço mínimo (inclusivo)",
+                        "name": "min_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Preço máximo (inclusivo)",
+                        "name": "max_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por código de barras de uma variante",
+                        "name": "barcode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por atributo de variante",
+                        "name": "attribute",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Valor do atributo de variante; exige 'attribute'",
+                        "name": "attribute_value",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Arquivo CSV ou NDJSON",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Parâmetros inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/imports/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retorna o progresso e os erros por linha de uma importação de produtos.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Consulta o status de um job de importação",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do job de importação",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Status do job",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ImportReport"
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Job não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invitations": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Emite um convite assinado para o e-mail, com o papel da conta a ser criada (padrão \"user\"). O convite expira após INVITATION_EXPIRY_HOURS.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Convida um usuário",
+                "parameters": [
+                    {
+                        "description": "E-mail e papel do convidado",
+                        "name": "invitation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.InvitationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Convite emitido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Invitation"
+                        }
+                    },
+                    "400": {
+                        "description": "E-mail inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Papel não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "E-mail já cadastrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invitations/accept": {
+            "post": {
+                "description": "Cria a conta do convite com a senha informada. O token pode vir no corpo ou na query (?token=, como no link do convite).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Aceita um convite",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token do convite",
+                        "name": "token",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Token do convite e senha",
+                        "name": "acceptance",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.InvitationAcceptance"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Conta criada",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Payload inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Convite inválido ou expirado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Convite já aceito (e-mail em uso)",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/lockouts/ips/{ip}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retorna as falhas de login recentes a partir do IP e o bloqueio ativo, se houver.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Consulta o bloqueio de login de um IP",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Endereço IP",
+                        "name": "ip",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Situação do IP",
+                        "schema": {
+                            "$ref": "#/definitions/domain.LoginLockout"
+                        }
+                    },
+                    "400": {
+                        "description": "IP inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove o bloqueio e zera as falhas de login do IP.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Desbloqueia o login de um IP",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Endereço IP",
+                        "name": "ip",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Bloqueio removido"
+                    },
+                    "400": {
+                        "description": "IP inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Recebe email/senha, verifica a validade e emite um access token (JWT de curta duração) e um refresh token. O campo token repete o access token para clientes antigos.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Autentica um usuário e retorna os tokens da sessão",
+                "parameters": [
+                    {
+                        "description": "Credenciais do usuário (email e senha)",
+                        "name": "login",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tokens emitidos ou, com o segundo fator, o desafio (mfa_required e mfa_token)",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AuthTokens"
+                        }
+                    },
+                    "400": {
+                        "description": "Payload inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Credenciais inválidas",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Muitas tentativas: conta ou IP bloqueados temporariamente (ver Retry-After)",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/login/mfa": {
+            "post": {
+                "description": "Troca o desafio retornado pelo login (mfa_token) e um código do autenticador, ou um código de recuperação, pelos tokens da sessão. Com um desafio de cadastro (mfa_setup_required), o código confirma o autenticador registrado em /login/mfa/setup e a resposta traz também os códigos de recuperação.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Conclui o login com o segundo fator",
+                "parameters": [
+                    {
+                        "description": "Desafio e código",
+                        "name": "verification",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.MFAVerification"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tokens emitidos",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AuthTokens"
+                        }
+                    },
+                    "400": {
+                        "description": "Payload inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Desafio inválido ou expirado, ou código incorreto",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Muitas tentativas (ver Retry-After)",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/login/mfa/setup": {
+            "post": {
+                "description": "Para papéis que exigem o segundo fator: com o desafio de cadastro retornado pelo login, gera o segredo TOTP e a URI otpauth:// do QR code. Conclua em /login/mfa com o primeiro código.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Cadastra o segundo fator durante o login",
+                "parameters": [
+                    {
+                        "description": "Desafio de cadastro",
+                        "name": "challenge",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.MFAChallengeToken"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Segredo e URI de provisionamento",
+                        "schema": {
+                            "$ref": "#/definitions/domain.MFAEnrollment"
+                        }
+                    },
+                    "401": {
+                        "description": "Desafio inválido ou expirado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/logout": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Revoga o access token usado na requisição e todos os refresh tokens da sessão.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Encerra a sessão",
+                "responses": {
+                    "204": {
+                        "description": "Sessão encerrada"
+                    },
+                    "401": {
+                        "description": "Token ausente, inválido ou já revogado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Obtém o próprio perfil",
+                "responses": {
+                    "200": {
+                        "description": "Usuário autenticado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "401": {
+                        "description": "Token ausente ou inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/mfa": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Situação do segundo fator",
+                "responses": {
+                    "200": {
+                        "description": "Situação do segundo fator",
+                        "schema": {
+                            "$ref": "#/definitions/domain.MFAStatus"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Confere a senha e um código (do autenticador ou de recuperação) e desativa o segundo fator. Não permitido para papéis que o exigem.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Desativa o segundo fator",
+                "parameters": [
+                    {
+                        "description": "Senha e código",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.MFADisable"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Segundo fator desativado"
+                    },
+                    "401": {
+                        "description": "Senha ou código incorretos",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Segundo fator inativo ou exigido pelo papel",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/mfa/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Confere o primeiro código do autenticador, ativa o segundo fator e retorna os códigos de recuperação (exibidos apenas nesta resposta).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Ativa o segundo fator",
+                "parameters": [
+                    {
+                        "description": "Código do autenticador",
+                        "name": "code",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.MFACode"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Códigos de recuperação",
+                        "schema": {
+                            "$ref": "#/definitions/domain.RecoveryCodes"
+                        }
+                    },
+                    "401": {
+                        "description": "Código inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Nenhum cadastro pendente ou segundo fator já ativo",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/mfa/enroll": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Gera um segredo TOTP e a URI otpauth:// para o QR code do aplicativo autenticador. O segundo fator só é ativado após a confirmação com o primeiro código (/me/mfa/confirm).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Inicia o cadastro do segundo fator",
+                "responses": {
+                    "200": {
+                        "description": "Segredo e URI de provisionamento",
+                        "schema": {
+                            "$ref": "#/definitions/domain.MFAEnrollment"
+                        }
+                    },
+                    "409": {
+                        "description": "Segundo fator já ativo",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/mfa/recovery-codes": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Confere um código do autenticador e substitui os códigos de recuperação; os anteriores deixam de valer.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Gera novos códigos de recuperação",
+                "parameters": [
+                    {
+                        "description": "Código do autenticador",
+                        "name": "code",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.MFACode"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Novos códigos de recuperação",
+                        "schema": {
+                            "$ref": "#/definitions/domain.RecoveryCodes"
+                        }
+                    },
+                    "401": {
+                        "description": "Código inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Segundo fator inativo",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/password": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Confere a senha atual e grava a nova. Todas as sessões do usuário, inclusive a atual, são encerradas.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Troca a própria senha",
+                "parameters": [
+                    {
+                        "description": "Senha atual e nova senha",
+                        "name": "change",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.PasswordChange"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Senha alterada"
+                    },
+                    "400": {
+                        "description": "Payload inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Senha atual incorreta",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oidc/callback": {
+            "get": {
+                "description": "Endereço de retorno do provedor. Valida o state, troca o código pelo ID token e emite os tokens da sessão. No primeiro login, a identidade é vinculada à conta com o mesmo e-mail (se verificado pelo provedor) ou um usuário é criado com o papel padrão; com OIDC_ROLE_MAPPING, o papel segue os grupos do provedor. Se o segundo fator for exigido, a resposta traz o desafio (conclua em /login/mfa).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Conclui o login com o provedor OpenID Connect",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código de autorização",
+                        "name": "code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "State do início do login",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Erro retornado pelo provedor",
+                        "name": "error",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tokens emitidos ou desafio do segundo fator",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AuthTokens"
+                        }
+                    },
+                    "400": {
+                        "description": "Parâmetros ausentes",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "State inválido ou expirado, ou login recusado pelo provedor",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Sem e-mail verificado ou sem conta (criação automática desativada)",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Login OIDC não configurado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oidc/login": {
+            "get": {
+                "description": "Redireciona o navegador para o provedor de identidade (fluxo authorization code com PKCE). O provedor retorna para /oidc/callback. O login com e-mail e senha continua disponível.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Inicia o login com o provedor OpenID Connect",
+                "responses": {
+                    "302": {
+                        "description": "Redirecionamento para o provedor"
+                    },
+                    "404": {
+                        "description": "Login OIDC não configurado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Provedor indisponível",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/password/forgot": {
+            "post": {
+                "description": "Envia ao e-mail um link de redefinição de senha, de uso único, que expira após PASSWORD_RESET_EXPIRY_MIN. A resposta é sempre 202, exista ou não a conta.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Pede a redefinição de senha",
+                "parameters": [
+                    {
+                        "description": "E-mail da conta",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.PasswordForgot"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Pedido aceito",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Payload inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/password/reset": {
+            "post": {
+                "description": "Grava a nova senha usando o token recebido por e-mail. O token é consumido e todas as sessões do usuário são encerradas.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Redefine a senha",
+                "parameters": [
+                    {
+                        "description": "Token recebido por e-mail e nova senha",
+                        "name": "reset",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.PasswordReset"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Senha redefinida"
+                    },
+                    "400": {
+                        "description": "Payload inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido, já usado ou expirado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/permissions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retorna o catálogo de permissões que podem ser atribuídas a papéis.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Lista as permissões",
+                "responses": {
+                    "200": {
+                        "description": "Permissões",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.PermissionInfo"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Apenas administradores",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/price-lists": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retorna todas as tabelas de preços cadastradas, ordenadas por nome.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Lista as tabelas de preços",
+                "responses": {
+                    "200": {
+                        "description": "Lista de tabelas de preços",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.PriceList"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Cria uma tabela de preços nomeada em uma moeda (ISO 4217), opcionalmente associada a um segmento de clientes. Se 'is_active' for omitido, a tabela é criada ativa.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Cria uma tabela de preços",
+                "parameters": [
+                    {
+                        "description": "Dados da tabela de preços",
+                        "name": "price_list",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.PriceList"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Tabela de preços criada",
+                        "schema": {
+                            "$ref": "#/definitions/domain.PriceList"
+                        }
+                    },
+                    "400": {
+                        "description": "Payload inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Nome já utilizado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/price-lists/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Obtém uma tabela de preços por ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID da tabela de preços",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tabela de preços encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/domain.PriceList"
+                        }
+                    },
+                    "404": {
+                        "description": "Tabela de preços não encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Substitui nome, moeda, segmento e status da tabela. Os valores dos preços já cadastrados não são convertidos ao trocar a moeda.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Atualiza uma tabela de preços",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID da tabela de preços",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Dados da tabela de preços",
+                        "name": "price_list",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.PriceList"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tabela de preços atualizada",
+                        "schema": {
+                            "$ref": "#/definitions/domain.PriceList"
+                        }
+                    },
+                    "400": {
+                        "description": "Payload inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Tabela de preços não encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Nome já utilizado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove a tabela de preços e todos os preços cadastrados nela.",
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Deleta uma tabela de preços",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID da tabela de preços",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Nenhum conteúdo"
+                    },
+                    "404": {
+                        "description": "Tabela de preços não encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/price-lists/{id}/prices": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Lista os preços de uma tabela",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID da tabela de preços",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por variante",
+                        "name": "variant_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Preços da tabela",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.VariantPrice"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Parâmetros inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Tabela de preços não encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Cadastra o preço de uma variante na tabela, em unidades mínimas da moeda (ex.: 4990 = 49,90 BRL), com janela de validade opcional [valid_from, valid_to). Janelas podem se sobrepor: na resolução vence a de início mais recente (ex.: uma promoção sobre o preço padrão).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Cadastra o preço de uma variante",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID da tabela de preços",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Preço da variante",
+                        "name": "price",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.VariantPrice"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Preço cadastrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.VariantPrice"
+                        }
+                    },
+                    "400": {
+                        "description": "Payload inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Tabela de preços ou variante não encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/price-lists/{id}/prices/{priceId}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Remove o preço de uma variante",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID da tabela de preços",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID do preço",
+                        "name": "priceId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Nenhum conteúdo"
+                    },
+                    "404": {
+                        "description": "Preço não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/prices/resolve": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retorna o preço vigente da variante na tabela de preços no instante informado (padrão: agora).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Resolve o preço efetivo de uma variante",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID da variante",
+                        "name": "variant_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID da tabela de preços",
+                        "name": "price_list_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Instante da consulta (RFC3339)",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Preço efetivo",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ResolvedPrice"
+                        }
+                    },
+                    "400": {
+                        "description": "Parâmetros inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Tabela não encontrada ou nenhum preço vigente",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Tabela de preços inativa",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products": {
+            "get": {
+                "description": "Retorna uma lista de produtos com base em filtros e paginação. Parâmetros no formato attr.\u003cNome\u003e=\u003cvalor\u003e (ex.: attr.Cor=Azul\u0026attr.Tamanho=M) filtram por valor exato de atributo; todos os atributos informados devem estar na mesma variante. Parâmetros spec.\u003ccampo\u003e=\u003cvalor\u003e (ex.: spec.material=Algodão\u0026spec.peso_kg=0.2) filtram por valor exato dos atributos personalizados do produto.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Lista produtos com filtros e paginação",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Número da página",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Limite de itens por página",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por nome do produto",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por SKU",
+                        "name": "sku",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filtrar apenas por produtos ativos",
+                        "name": "active_only",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "name",
+                            "price",
+                            "sku",
+                            "created_at",
+                            "updated_at"
+                        ],
+                        "type": "string",
+                        "description": "Campo de ordenação",
+                        "name": "sort_by",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "asc",
+                        "description": "Direção da ordenação",
+                        "name": "sort_order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Preço mínimo (inclusivo)",
+                        "name": "min_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Preço máximo (inclusivo)",
+                        "name": "max_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Criados a partir de (AAAA-MM-DD ou RFC3339)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Criados até (AAAA-MM-DD ou RFC3339)",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Atualizados a partir de (AAAA-MM-DD ou RFC3339)",
+                        "name": "updated_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Atualizados até (AAAA-MM-DD ou RFC3339)",
+                        "name": "updated_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filtrar produtos com (true) ou sem (false) variantes",
+                        "name": "has_variants",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por código de barras de uma variante",
+                        "name": "barcode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por atributo de variante (ex: Cor)",
+                        "name": "attribute",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Valor do atributo de variante (ex: Vermelho); exige 'attribute'",
+                        "name": "attribute_value",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por categoria (sem diferenciar maiúsculas)",
+                        "name": "category",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Lista de produtos",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Product"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Parâmetros de query inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Cria um novo produto principal e suas variantes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Cria um novo produto com variantes",
+                "parameters": [
+                    {
+                        "description": "Dados do produto e variantes",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/product.ProductCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Produto criado com sucesso",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Payload inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/import": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Importa produtos e variantes fazendo upsert por SKU. No CSV cada linha é uma variante (colunas: sku, name, description, price, attribute, value, barcode, price_diff; colunas extras \"attr:\u003cNome\u003e\" definem atributos adicionais da variante; a coluna opcional category e colunas \"spec:\u003ccampo\u003e\" definem a categoria e os atributos personalizados do produto) e linhas consecutivas com o mesmo SKU formam um produto. No NDJSON cada linha é um produto com o array \"variants\". Com dry_run=true o arquivo é apenas validado. Arquivos grandes, uploads sem Content-Length (chunked) ou async=true são processados em segundo plano e retornam 202 com o ID do job.",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Importa produtos em lote (CSV ou NDJSON)",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Formato do arquivo (padrão: deduzido do Content-Type)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Apenas valida, sem gravar",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Força o processamento em segundo plano",
+                        "name": "async",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Conteúdo do arquivo CSV ou NDJSON",
+                        "name": "file",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Relatório da importação síncrona",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ImportReport"
+                        }
+                    },
+                    "202": {
+                        "description": "Job de importação criado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ImportReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Formato ou arquivo inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}": {
+            "get": {
+                "description": "Busca um produto específico e suas variantes pelo ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Obtém um produto por ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do Produto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Produto encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Product"
+                        }
+                    },
+                    "404": {
+                        "description": "Produto não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/custom-attributes": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Substitui a categoria e os atributos personalizados, validados pelo esquema da categoria. Enviar custom_attributes vazio remove as especificações. A alteração é registrada no histórico.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Altera a categoria e as especificações de um produto",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do Produto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Categoria e atributos personalizados",
+                        "name": "attributes",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/product.ProductCustomAttributesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Produto atualizado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Atributos inválidos para o esquema",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Produto não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retorna as versões do produto (mais recente primeiro), com os campos alterados, o snapshot completo e quem fez a alteração.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Lista o histórico de versões de um produto",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do Produto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Número da página",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Limite de itens por página (máx. 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Histórico do produto",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.ProductVersion"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Parâmetros inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Produto não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/media": {
+            "get": {
+                "description": "Retorna as imagens do produto em ordem de exibição (sort_order). As mídias também acompanham o GET /products/{id}.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "media"
+                ],
+                "summary": "Lista as mídias de um produto",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do Produto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Mídias do produto",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.ProductMedia"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Com corpo JSON, cadastra uma mídia externa pela URL. Com multipart/form-data, envia o arquivo (campo \"file\", até 10 MiB; JPEG, PNG, GIF ou WebP) para o armazenamento local, com os campos opcionais \"variant_id\", \"alt_text\" e \"sort_order\".",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "media"
+                ],
+                "summary": "Cadastra uma mídia do produto",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do Produto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Mídia por URL (corpo JSON)",
+                        "name": "media",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/product.ProductMediaRequest"
+                        }
+                    },
+                    {
+                        "type": "file",
+                        "description": "Arquivo de imagem (multipart)",
+                        "name": "file",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Mídia cadastrada",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProductMedia"
+                        }
+                    },
+                    "400": {
+                        "description": "Payload, arquivo ou variante inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Produto não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Arquivo maior que o limite",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/media/{media_id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Altera a variante associada, o texto alternativo e a ordem de exibição. A URL não é alterada.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "media"
+                ],
+                "summary": "Atualiza uma mídia do produto",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do Produto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID da mídia",
+                        "name": "media_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Novos metadados (url é ignorada)",
+                        "name": "media",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/product.ProductMediaRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Mídia atualizada",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProductMedia"
+                        }
+                    },
+                    "400": {
+                        "description": "Payload ou variante inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Mídia não encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Arquivos enviados por upload também são apagados do armazenamento local.",
+                "tags": [
+                    "media"
+                ],
+                "summary": "Remove uma mídia do produto",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do Produto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID da mídia",
+                        "name": "media_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Mídia removida"
+                    },
+                    "404": {
+                        "description": "Mídia não encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/revert": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Restaura o produto e suas variantes ao estado de uma versão do histórico. A reversão é registrada como uma nova versão. Variantes criadas depois da versão restaurada são removidas, exceto se tiverem estoque (409).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Reverte um produto para uma versão anterior",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do Produto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Versão a restaurar",
+                        "name": "revert",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/product.ProductRevertRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Produto restaurado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Payload inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Produto ou versão não encontrados",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Versão não pode ser restaurada",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/variants/generate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Cria uma variante para cada combinação dos valores informados (ex.: {\"attributes\": {\"Cor\": [\"Azul\", \"Verde\"], \"Tamanho\": []}}). Todo atributo precisa estar definido em /attributes; lista vazia usa todos os valores permitidos. Combinações já existentes no produto são ignoradas. O limite é de 500 combinações por requisição.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Gera variantes a partir de combinações de atributos",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do Produto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Valores de cada atributo",
+                        "name": "generation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.VariantGenerationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Variantes geradas",
+                        "schema": {
+                            "$ref": "#/definitions/domain.VariantGenerationResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Atributo não definido, valor não permitido ou combinações demais",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Produto não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "Cria um novo usuário com o papel padrão (\"user\"), hasheia a senha e salva no banco de dados. Não é possível escolher o papel no registro; outros papéis são concedidos por um administrador (atribuição ou convite).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Registra um novo usuário",
+                "parameters": [
+                    {
+                        "description": "Credenciais de registro (email e senha)",
+                        "name": "registration",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UserRegistration"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Usuário criado com sucesso",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Payload inválido (JSON malformado ou campos obrigatórios ausentes)",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Email já cadastrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retorna todos os papéis com as suas permissões.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Lista os papéis",
+                "responses": {
+                    "200": {
+                        "description": "Papéis",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Role"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Apenas administradores",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Cria um papel com um conjunto de permissões do catálogo (GET /v1/permissions).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Cria um papel",
+                "parameters": [
+                    {
+                        "description": "Nome, descrição e permissões",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/role.RoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Papel criado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Role"
+                        }
+                    },
+                    "400": {
+                        "description": "Nome inválido ou permissão desconhecida",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Papel já existe",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/{name}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Obtém um papel",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Nome do papel",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Papel",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Role"
+                        }
+                    },
+                    "404": {
+                        "description": "Papel não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Substitui a descrição e as permissões do papel. O papel admin não pode ser alterado. As novas permissões valem imediatamente para os usuários do papel.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Altera um papel",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Nome do papel",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Descrição e permissões",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/role.RoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Papel alterado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Role"
+                        }
+                    },
+                    "400": {
+                        "description": "Permissão desconhecida",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Papel não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Papel admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove um papel que não é do sistema e não está atribuído a nenhum usuário.",
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Remove um papel",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Nome do papel",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Papel removido"
+                    },
+                    "404": {
+                        "description": "Papel não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Papel do sistema ou em uso",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/service-accounts": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Cria um usuário sem senha para integrações (ERP, e-commerce). A conta não faz login: acessa a API apenas com chaves de API, criadas por um admin em POST /api-keys com user_id. O papel limita as permissões de todas as chaves da conta.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api-keys"
+                ],
+                "summary": "Cria uma conta de serviço",
+                "parameters": [
+                    {
+                        "description": "Identificador (e-mail) e papel da conta",
+                        "name": "account",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ServiceAccountCreate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Conta de serviço criada",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Identificador inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Papel não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Identificador já cadastrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/setup": {
+            "post": {
+                "description": "Cria uma conta admin com o token de setup (SETUP_TOKEN). Aceito apenas enquanto não existir nenhum administrador.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Cria o primeiro administrador",
+                "parameters": [
+                    {
+                        "description": "Token de setup e credenciais do administrador",
+                        "name": "setup",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.AdminSetup"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Administrador criado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Payload inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Token de setup inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Setup desativado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Já existe um administrador",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock/units/{variant_id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retorna a unidade-base, a precisão decimal e as conversões (ex.: cx = 12) da variante. Variantes sem configuração usam a unidade discreta \"un\".",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock"
+                ],
+                "summary": "Obtém as unidades de medida de uma variante",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID da variante",
+                        "name": "variant_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Unidades de medida",
+                        "schema": {
+                            "$ref": "#/definitions/domain.VariantUnits"
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Define a unidade-base, a precisão ('decimal_places' \u003e 0 para variantes fracionárias, como kg ou litros) e as conversões em unidades-base (ex.: {\"unit\": \"cx\", \"factor\": \"12\"}). Substitui a configuração anterior. A precisão não pode ser alterada se a variante tiver estoque.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock"
+                ],
+                "summary": "Configura as unidades de medida de uma variante",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID da variante",
+                        "name": "variant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Unidades de medida",
+                        "name": "units",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.VariantUnits"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Unidades de medida configuradas",
+                        "schema": {
+                            "$ref": "#/definitions/domain.VariantUnits"
+                        }
+                    },
+                    "400": {
+                        "description": "Payload inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Precisão alterada com estoque existente",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock/update": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Atualiza a quantidade de estoque para uma variante de produto em um armazém específico. O ajuste pode ser informado em 'delta' (inteiro) ou 'quantity' (decimal), opcionalmente em uma unidade configurada para a variante ('unit', ex.: \"cx\"); a quantidade é convertida e gravada em unidades-base.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock"
+                ],
+                "summary": "Ajusta o nível de estoque de um produto em um armazém",
+                "parameters": [
+                    {
+                        "description": "Dados para ajuste de estoque",
+                        "name": "adjustment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.StockAdjustmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Nível de estoque atualizado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.StockLevel"
+                        }
+                    },
+                    "400": {
+                        "description": "Payload inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflito de concorrência (versão)",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tenant": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retorna a empresa e as configurações do administrador autenticado.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tenants"
+                ],
+                "summary": "Obtém a própria empresa",
+                "responses": {
+                    "200": {
+                        "description": "Empresa",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Tenant"
+                        }
+                    },
+                    "403": {
+                        "description": "Apenas administradores",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Altera o nome e as configurações da empresa do administrador autenticado. A ativação é exclusiva da plataforma.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tenants"
+                ],
+                "summary": "Altera a própria empresa",
+                "parameters": [
+                    {
+                        "description": "Nome e configurações",
+                        "name": "tenant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.TenantUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Empresa alterada",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Tenant"
+                        }
+                    },
+                    "400": {
+                        "description": "Configurações inválidas",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Apenas administradores ou alteração da ativação",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tenants": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retorna todas as empresas atendidas pela instalação. Restrito aos administradores da plataforma (tenant padrão).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tenants"
+                ],
+                "summary": "Lista as empresas",
+                "responses": {
+                    "200": {
+                        "description": "Empresas",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Tenant"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Apenas administradores da plataforma",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Cadastra uma empresa. O primeiro administrador é convidado por POST /v1/invitations com o header X-Tenant da nova empresa.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tenants"
+                ],
+                "summary": "Cria uma empresa",
+                "parameters": [
+                    {
+                        "description": "Identificador, nome e configurações",
+                        "name": "tenant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.TenantCreate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Empresa criada",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Tenant"
+                        }
+                    },
+                    "400": {
+                        "description": "Identificador ou configurações inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Apenas administradores da plataforma",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Identificador já em uso",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tenants/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tenants"
+                ],
+                "summary": "Obtém uma empresa",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID da empresa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Empresa",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Tenant"
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Apenas administradores da plataforma",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Empresa não encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Altera o nome, as configurações ou a ativação da empresa. Empresas desativadas não fazem login nem renovam tokens.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tenants"
+                ],
+                "summary": "Altera uma empresa",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID da empresa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Campos alterados",
+                        "name": "tenant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.TenantUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Empresa alterada",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Tenant"
+                        }
+                    },
+                    "400": {
+                        "description": "Configurações inválidas",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Apenas administradores da plataforma",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Empresa não encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Desativação da empresa padrão",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/token/refresh": {
+            "post": {
+                "description": "Troca um refresh token por um novo access token e um novo refresh token. Cada refresh token só pode ser usado uma vez; reapresentar um token já trocado revoga toda a sessão.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Renova os tokens da sessão",
+                "parameters": [
+                    {
+                        "description": "Refresh token recebido no login ou na última renovação",
+                        "name": "refresh",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Novos tokens",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AuthTokens"
+                        }
+                    },
+                    "400": {
+                        "description": "Payload inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Refresh token inválido, expirado, revogado ou reutilizado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lista os usuários cadastrados, ordenados por e-mail, com paginação.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Lista os usuários",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Número da página",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Itens por página (máximo 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Página de usuários",
+                        "schema": {
+                            "$ref": "#/definitions/domain.UserList"
+                        }
+                    },
+                    "400": {
+                        "description": "Parâmetros de paginação inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Requer o papel admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Obtém um usuário",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do usuário",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Usuário",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Usuário não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove o usuário e invalida imediatamente os tokens emitidos para ele.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Remove um usuário",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do usuário",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Usuário removido"
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Usuário não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Remoção da própria conta",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/disable": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Desativa a conta: o login passa a ser recusado e os tokens já emitidos deixam de valer imediatamente.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Desativa um usuário",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do usuário",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Usuário desativado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Usuário não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Desativação da própria conta",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/enable": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Reativa a conta; o usuário precisa fazer login novamente.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Reativa um usuário",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do usuário",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Usuário reativado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Usuário não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/lockout": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retorna as falhas de login recentes da conta e o bloqueio ativo, se houver.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Consulta o bloqueio de login da conta",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do usuário",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Situação da conta",
+                        "schema": {
+                            "$ref": "#/definitions/domain.LoginLockout"
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Usuário não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove o bloqueio e zera as falhas de login da conta.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Desbloqueia o login da conta",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do usuário",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Bloqueio removido"
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Usuário não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/mfa": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Para autenticadores perdidos: remove o segundo fator e os códigos de recuperação do usuário e encerra as sessões dele.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Remove o segundo fator de um usuário",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do usuário",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Segundo fator removido"
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Usuário não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/role": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Altera o papel do usuário. Os access tokens já emitidos são revogados; o novo papel vale a partir do próximo token (login ou renovação).",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Atribui um papel a um usuário",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do usuário",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Papel",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UserRoleAssignment"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Papel atribuído"
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Usuário ou papel não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Remoção do próprio papel de administrador",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/warehouses": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retorna os armazéns atribuídos ao usuário. Usuários que não são administradores só consultam e ajustam o estoque desses armazéns.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouses"
+                ],
+                "summary": "Lista os armazéns de um usuário",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do usuário",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Armazéns do usuário",
+                        "schema": {
+                            "$ref": "#/definitions/domain.UserWarehouses"
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Usuário não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Substitui os armazéns atribuídos ao usuário. Uma lista vazia remove todas as atribuições. Administradores têm acesso a todos os armazéns.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouses"
+                ],
+                "summary": "Atribui armazéns a um usuário",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do usuário",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Armazéns (warehouse_ids)",
+                        "name": "assignment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UserWarehouses"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Armazéns atribuídos",
+                        "schema": {
+                            "$ref": "#/definitions/domain.UserWarehouses"
+                        }
+                    },
+                    "400": {
+                        "description": "Payload inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Usuário ou armazém não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/verify-email": {
+            "get": {
+                "description": "Confirma o e-mail do usuário com o token do link enviado no registro.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Verifica o e-mail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token de verificação",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "E-mail verificado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Token ausente",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Token inválido, já usado ou expirado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/warehouses": {
+            "get": {
+                "description": "Retorna uma lista de todos os armazéns cadastrados.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouses"
+                ],
+                "summary": "Lista todos os armazéns",
+                "responses": {
+                    "200": {
+                        "description": "Lista de armazéns",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Warehouse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Cria um novo armazém no sistema.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouses"
+                ],
+                "summary": "Cria um novo armazém",
+                "parameters": [
+                    {
+                        "description": "Dados do armazém para criação",
+                        "name": "warehouse",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Warehouse"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Armazém criado com sucesso",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Warehouse"
+                        }
+                    },
+                    "400": {
+                        "description": "Payload inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/warehouses/{id}": {
+            "get": {
+                "description": "Busca um armazém específico pelo seu ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouses"
+                ],
+                "summary": "Obtém um armazém por ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do Armazém",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Armazém encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Warehouse"
+                        }
+                    },
+                    "404": {
+                        "description": "Armazém não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Atualiza os dados de um armazém existente.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouses"
+                ],
+                "summary": "Atualiza um armazém",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do Armazém",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Dados do armazém para atualização",
+                        "name": "warehouse",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Warehouse"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Armazém atualizado com sucesso",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Warehouse"
+                        }
+                    },
+                    "400": {
+                        "description": "Payload inválido",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Armazém não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove um armazém do sistema pelo seu ID.",
+                "tags": [
+                    "warehouses"
+                ],
+                "summary": "Deleta um armazém",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do Armazém",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Nenhum conteúdo"
+                    },
+                    "404": {
+                        "description": "Armazém não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro interno do servidor",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Conector ERP"
+                },
+                "prefix": {
+                    "description": "Identifica a chave sem revelar o segredo",
+                    "type": "string",
+                    "example": "gsk_3f9a1c2b7d4e"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Permission"
+                    },
+                    "example": [
+                        "stock:adjust"
+                    ]
+                },
+                "user_id": {
+                    "description": "Dono da chave: as requisições são feitas em nome dele",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.APIKeyCreate": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "expires_at": {
+                    "description": "Vazio: a chave não expira",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Conector ERP"
+                },
+                "scopes": {
+                    "description": "Vazio: a chave acessa apenas as rotas de leitura",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Permission"
+                    },
+                    "example": [
+                        "stock:adjust"
+                    ]
+                },
+                "user_id": {
+                    "description": "Apenas admin: cria a chave para outro usuário ou conta de serviço",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.APIKeyCreated": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "gsk_3f9a1c2b7d4e_Zm9vYmFyYmF6..."
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Conector ERP"
+                },
+                "prefix": {
+                    "description": "Identifica a chave sem revelar o segredo",
+                    "type": "string",
+                    "example": "gsk_3f9a1c2b7d4e"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Permission"
+                    },
+                    "example": [
+                        "stock:adjust"
+                    ]
+                },
+                "user_id": {
+                    "description": "Dono da chave: as requisições são feitas em nome dele",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.AdminSetup": {
+            "type": "object",
+            "required": [
+                "email",
+                "password",
+                "setup_token"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "setup_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.AttributeDefinition": {
+            "type": "object",
+            "required": [
+                "allowed_values",
+                "name"
+            ],
+            "properties": {
+                "allowed_values": {
+                    "type": "array",
+                    "maxItems": 200,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "P",
+                        "M",
+                        "G"
+                    ]
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "example": "Tamanho"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.AuthTokens": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "description": "Validade do access token, em segundos",
+                    "type": "integer",
+                    "example": 900
+                },
+                "mfa_required": {
+                    "description": "Informe o código em POST /v1/login/mfa",
+                    "type": "boolean"
+                },
+                "mfa_setup_required": {
+                    "description": "O papel exige o segundo fator e ele ainda não foi cadastrado",
+                    "type": "boolean"
+                },
+                "mfa_token": {
+                    "description": "Desafio de curta duração do segundo passo",
+                    "type": "string"
+                },
+                "recovery_codes": {
+                    "description": "Gerados ao concluir o cadastro no login",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "token": {
+                    "description": "Mesmo valor de access_token (compatibilidade com clientes antigos)",
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string",
+                    "example": "Bearer"
+                }
+            }
+        },
+        "domain.BarcodeLookup": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "type": "string"
+                },
+                "primary": {
+                    "description": "true para o código principal da variante (variants.barcode)",
+                    "type": "boolean"
+                },
+                "product": {
+                    "$ref": "#/definitions/domain.Product"
+                },
+                "stock": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.StockLevel"
+                    }
+                },
+                "unit": {
+                    "description": "Unidade representada pelo código (vazio = unidade-base)",
+                    "type": "string"
+                },
+                "variant": {
+                    "$ref": "#/definitions/domain.Variant"
+                }
+            }
+        },
+        "domain.CategorySchema": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "example": "Vestuário"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.CustomAttributeField"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.CustomAttributeField": {
+            "type": "object",
+            "required": [
+                "name",
+                "type"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "material"
+                },
+                "required": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "enum": [
+                        "string",
+                        "number",
+                        "bool",
+                        "enum"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.CustomAttributeType"
+                        }
+                    ],
+                    "example": "enum"
+                },
+                "values": {
+                    "description": "Apenas para o tipo enum",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Algodão",
+                        "Poliéster"
+                    ]
+                }
+            }
+        },
+        "domain.CustomAttributeType": {
+            "type": "string",
+            "enum": [
+                "string",
+                "number",
+                "bool",
+                "enum"
+            ],
+            "x-enum-varnames": [
+                "CustomAttributeString",
+                "CustomAttributeNumber",
+                "CustomAttributeBool",
+                "CustomAttributeEnum"
+            ]
+        },
+        "domain.ErrorResponse": {
+            "description": "Estrutura padronizada para respostas de erro na API.",
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "example": "VALIDATION_ERROR"
+                },
+                "code": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "errors": {
+                    "description": "Campos inválidos (apenas erros de validação)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FieldError"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "O nome do armazém não pode ser vazio."
+                },
+                "request_id": {
+                    "description": "ID da requisição (header X-Request-ID)",
+                    "type": "string",
+                    "example": "9f1c2d7e4b3a4c5d"
+                }
+            }
+        },
+        "domain.FieldChange": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "price"
+                },
+                "new": {},
+                "old": {}
+            }
+        },
+        "domain.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "settings.timezone"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Fuso horário inválido: 'Lua/Base'."
+                }
+            }
+        },
+        "domain.ImportFormat": {
+            "type": "string",
+            "enum": [
+                "csv",
+                "ndjson"
+            ],
+            "x-enum-varnames": [
+                "ImportFormatCSV",
+                "ImportFormatNDJSON"
+            ]
+        },
+        "domain.ImportReport": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ImportRowError"
+                    }
+                },
+                "errors_truncated": {
+                    "description": "true se houve mais erros do que os reportados",
+                    "type": "boolean"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "format": {
+                    "$ref": "#/definitions/domain.ImportFormat"
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "products": {
+                    "description": "Produtos distintos (por SKU) processados",
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.ImportStatus"
+                },
+                "total_rows": {
+                    "type": "integer"
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.ImportRowError": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "O preço do produto deve ser um valor positivo."
+                },
+                "row": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "sku": {
+                    "type": "string",
+                    "example": "CAM-001"
+                }
+            }
+        },
+        "domain.ImportStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "running",
+                "completed",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "ImportStatusPending",
+                "ImportStatusRunning",
+                "ImportStatusCompleted",
+                "ImportStatusFailed"
+            ]
+        },
+        "domain.Invitation": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "link": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.InvitationAcceptance": {
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.InvitationRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "role"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.LockoutScope": {
+            "type": "string",
+            "enum": [
+                "account",
+                "ip"
+            ],
+            "x-enum-comments": {
+                "LockoutScopeAccount": "Tentativas contra um e-mail",
+                "LockoutScopeIP": "Tentativas a partir de um IP"
+            },
+            "x-enum-descriptions": [
+                "Tentativas contra um e-mail",
+                "Tentativas a partir de um IP"
+            ],
+            "x-enum-varnames": [
+                "LockoutScopeAccount",
+                "LockoutScopeIP"
+            ]
+        },
+        "domain.LoginLockout": {
+            "type": "object",
+            "properties": {
+                "failed_attempts": {
+                    "description": "Falhas na janela atual",
+                    "type": "integer"
+                },
+                "locked": {
+                    "type": "boolean"
+                },
+                "locked_until": {
+                    "type": "string"
+                },
+                "lockouts": {
+                    "description": "Bloqueios recentes; cada reincidência dobra a duração",
+                    "type": "integer"
+                },
+                "scope": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.LockoutScope"
+                        }
+                    ],
+                    "example": "account"
+                },
+                "subject": {
+                    "description": "E-mail ou IP",
+                    "type": "string",
+                    "example": "maria@gostock.com"
+                }
+            }
+        },
+        "domain.MFAChallengeToken": {
+            "type": "object",
+            "required": [
+                "mfa_token"
+            ],
+            "properties": {
+                "mfa_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.MFACode": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "domain.MFADisable": {
+            "type": "object",
+            "required": [
+                "code",
+                "password"
+            ],
+            "properties": {
+                "code": {
+                    "description": "Código TOTP ou de recuperação",
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.MFAEnrollment": {
+            "type": "object",
+            "properties": {
+                "provisioning_uri": {
+                    "type": "string",
+                    "example": "otpauth://totp/GoStock:maria@gostock.com?secret=JBSWY3DPEHPK3PXP\u0026issuer=GoStock"
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "JBSWY3DPEHPK3PXP"
+                }
+            }
+        },
+        "domain.MFAStatus": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "enabled_at": {
+                    "type": "string"
+                },
+                "recovery_codes_remaining": {
+                    "type": "integer"
+                },
+                "required": {
+                    "description": "O papel do usuário exige o segundo fator",
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.MFAVerification": {
+            "type": "object",
+            "required": [
+                "code",
+                "mfa_token"
+            ],
+            "properties": {
+                "code": {
+                    "description": "Código TOTP ou de recuperação",
+                    "type": "string"
+                },
+                "mfa_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.PasswordChange": {
+            "type": "object",
+            "required": [
+                "current_password",
+                "new_password"
+            ],
+            "properties": {
+                "current_password": {
+                    "type": "string"
+                },
+                "new_password": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.PasswordForgot": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.PasswordReset": {
+            "type": "object",
+            "required": [
+                "new_password",
+                "token"
+            ],
+            "properties": {
+                "new_password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Permission": {
+            "type": "string",
+            "enum": [
+                "*",
+                "product:write",
+                "product:import",
+                "catalog:manage",
+                "stock:adjust",
+                "stock:configure",
+                "warehouse:manage",
+                "price:manage",
+                "export:read"
+            ],
+            "x-enum-comments": {
+                "PermissionAll": "Todas as permissões (papel admin)",
+                "PermissionCatalogManage": "Definições de atributos e esquemas de categoria",
+                "PermissionExportRead": "Exportação do catálogo e do estoque",
+                "PermissionPriceManage": "Tabelas de preços e preços por variante",
+                "PermissionProductImport": "Importação em lote de produtos",
+                "PermissionProductWrite": "Produtos, variantes, mídias e códigos de barras",
+                "PermissionStockAdjust": "Ajustes de estoque",
+                "PermissionStockConfigure": "Unidades de medida das variantes"
+            },
+            "x-enum-descriptions": [
+                "Todas as permissões (papel admin)",
+                "Produtos, variantes, mídias e códigos de barras",
+                "Importação em lote de produtos",
+                "Definições de atributos e esquemas de categoria",
+                "Ajustes de estoque",
+                "Unidades de medida das variantes",
+                "",
+                "Tabelas de preços e preços por variante",
+                "Exportação do catálogo e do estoque"
+            ],
+            "x-enum-varnames": [
+                "PermissionAll",
+                "PermissionProductWrite",
+                "PermissionProductImport",
+                "PermissionCatalogManage",
+                "PermissionStockAdjust",
+                "PermissionStockConfigure",
+                "PermissionWarehouseManage",
+                "PermissionPriceManage",
+                "PermissionExportRead"
+            ]
+        },
+        "domain.PermissionInfo": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Ajustar o nível de estoque"
+                },
+                "name": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Permission"
+                        }
+                    ],
+                    "example": "stock:adjust"
+                }
+            }
+        },
+        "domain.PriceList": {
+            "type": "object",
+            "required": [
+                "currency",
+                "name"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "Código ISO 4217",
+                    "type": "string",
+                    "example": "BRL"
+                },
+                "customer_segment": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "atacado"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 3,
+                    "example": "Atacado BRL"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Product": {
+            "type": "object",
+            "required": [
+                "name",
+                "price",
+                "sku"
+            ],
+            "properties": {
+                "category": {
+                    "description": "Categoria e especificações (ex.: {\"peso_kg\": 0.2, \"material\": \"Algodão\"}), validadas pelo\nesquema da categoria (ver CategorySchema)",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "custom_attributes": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_active": {
                     "type": "boolean"
                 },
+                "media": {
+                    "description": "Mídias do produto, em ordem de exibição (preenchidas apenas na busca por ID)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ProductMedia"
+                    }
+                },
                 "name": {
                     "type": "string"
                 },
                 "price": {
-                    "type": "number"
+                    "type": "number",
+                    "minimum": 0
                 },
                 "sku": {
                     "description": "Stock Keeping Unit (código único de produto)",
@@ -623,75 +5641,466 @@ const docTemplate = `{
                 }
             }
         },
-        "domain.StockAdjustmentRequest": {
+        "domain.ProductChangeType": {
+            "type": "string",
+            "enum": [
+                "create",
+                "import",
+                "revert",
+                "generate_variants",
+                "custom_attributes"
+            ],
+            "x-enum-varnames": [
+                "ProductChangeCreate",
+                "ProductChangeImport",
+                "ProductChangeRevert",
+                "ProductChangeVariants",
+                "ProductChangeCustomAttributes"
+            ]
+        },
+        "domain.ProductMedia": {
+            "type": "object",
+            "properties": {
+                "alt_text": {
+                    "type": "string",
+                    "example": "Camiseta azul, vista frontal"
+                },
+                "content_type": {
+                    "type": "string",
+                    "example": "image/jpeg"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "sort_order": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://cdn.example.com/camiseta-azul.jpg"
+                },
+                "variant_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ProductVersion": {
+            "type": "object",
+            "properties": {
+                "change_type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ProductChangeType"
+                        }
+                    ],
+                    "example": "import"
+                },
+                "changed_by": {
+                    "description": "ID do usuário (claims do JWT); vazio se desconhecido",
+                    "type": "string"
+                },
+                "changed_by_role": {
+                    "type": "string"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FieldChange"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "reverted_to": {
+                    "description": "Versão restaurada, quando ChangeType = revert",
+                    "type": "integer"
+                },
+                "snapshot": {
+                    "$ref": "#/definitions/domain.Product"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "domain.RecoveryCodes": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.RefreshTokenRequest": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ResolvedPrice": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 4990
+                },
+                "at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "BRL"
+                },
+                "formatted": {
+                    "description": "Valor decimal, sem símbolo da moeda",
+                    "type": "string",
+                    "example": "49.90"
+                },
+                "price_id": {
+                    "type": "string"
+                },
+                "price_list_id": {
+                    "type": "string"
+                },
+                "valid_from": {
+                    "type": "string"
+                },
+                "valid_to": {
+                    "type": "string"
+                },
+                "variant_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Role": {
+            "type": "object",
+            "properties": {
+                "built_in": {
+                    "description": "Papéis do sistema (admin, user, guest) não podem ser removidos",
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Equipe de armazém"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "warehouse_staff"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Permission"
+                    },
+                    "example": [
+                        "stock:adjust"
+                    ]
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ServiceAccountCreate": {
+            "type": "object",
+            "required": [
+                "email",
+                "role"
+            ],
+            "properties": {
+                "email": {
+                    "description": "Identificador da conta (não recebe e-mails)",
+                    "type": "string",
+                    "example": "erp@integracoes.gostock.com"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "warehouse_staff"
+                }
+            }
+        },
+        "domain.StockAdjustmentRequest": {
+            "type": "object",
+            "required": [
+                "variant_id",
+                "warehouse_id"
+            ],
+            "properties": {
+                "delta": {
+                    "description": "Quantidade a ser adicionada/removida (ou Quantity)",
+                    "type": "integer"
+                },
+                "quantity": {
+                    "description": "Alternativas a Delta para ajustes em unidades de compra/venda: Quantity é decimal\n(ex.: 2 \"cx\", 1.5 \"kg\") e é convertida para unidades-base antes de ser gravada.\nSem Unit, Delta e Quantity são interpretados na unidade-base.",
+                    "type": "number",
+                    "example": 2
+                },
+                "unit": {
+                    "type": "string",
+                    "example": "cx"
+                },
+                "variant_id": {
+                    "type": "string"
+                },
+                "warehouse_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.StockLevel": {
+            "type": "object",
+            "properties": {
+                "base_quantity": {
+                    "type": "string",
+                    "example": "24"
+                },
+                "base_unit": {
+                    "description": "Preenchidos quando a operação envolve unidades de medida: a quantidade em unidades-base,\nem formato decimal (ex.: \"12.500\" kg para Quantity = 12500 com 3 casas).",
+                    "type": "string",
+                    "example": "un"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "variant_id": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Para Controle de Concorrência Otimista (OCC)",
+                    "type": "integer"
+                },
+                "warehouse_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Tenant": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "disabled": {
+                    "description": "Empresas desativadas não fazem login nem renovam tokens",
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "ACME Distribuidora"
+                },
+                "settings": {
+                    "$ref": "#/definitions/domain.TenantSettings"
+                },
+                "slug": {
+                    "description": "Identificador público, usado no header X-Tenant",
+                    "type": "string",
+                    "example": "acme"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.TenantCreate": {
+            "type": "object",
+            "required": [
+                "name",
+                "slug"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "ACME Distribuidora"
+                },
+                "settings": {
+                    "description": "Ausente: configurações padrão",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.TenantSettings"
+                        }
+                    ]
+                },
+                "slug": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 3,
+                    "example": "acme"
+                }
+            }
+        },
+        "domain.TenantSettings": {
+            "type": "object",
+            "required": [
+                "default_currency",
+                "locale",
+                "timezone"
+            ],
+            "properties": {
+                "allow_registration": {
+                    "description": "Aceita o registro público de usuários",
+                    "type": "boolean"
+                },
+                "default_currency": {
+                    "description": "Moeda padrão da empresa (ISO 4217)",
+                    "type": "string",
+                    "example": "BRL"
+                },
+                "locale": {
+                    "description": "Idioma e formatação preferidos pelos clientes da API",
+                    "type": "string",
+                    "maxLength": 35,
+                    "example": "pt-BR"
+                },
+                "max_users": {
+                    "description": "Limite de usuários, incluindo contas de serviço (0: sem limite)",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 50
+                },
+                "timezone": {
+                    "description": "Fuso horário dos relatórios e exportações",
+                    "type": "string",
+                    "example": "America/Sao_Paulo"
+                }
+            }
+        },
+        "domain.TenantUpdate": {
+            "type": "object",
+            "properties": {
+                "disabled": {
+                    "description": "Apenas a operação da plataforma altera",
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "settings": {
+                    "$ref": "#/definitions/domain.TenantSettings"
+                }
+            }
+        },
+        "domain.UnitConversion": {
             "type": "object",
             "required": [
-                "delta",
-                "variant_id",
-                "warehouse_id"
+                "factor",
+                "unit"
             ],
             "properties": {
-                "delta": {
-                    "description": "Quantidade a ser adicionada/removida",
-                    "type": "integer"
-                },
-                "variant_id": {
-                    "type": "string"
+                "factor": {
+                    "type": "string",
+                    "example": "12"
                 },
-                "warehouse_id": {
-                    "type": "string"
+                "unit": {
+                    "type": "string",
+                    "example": "cx"
                 }
             }
         },
-        "domain.StockLevel": {
+        "domain.User": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
-                "id": {
+                "disabled": {
+                    "description": "Contas desativadas não fazem login e têm os tokens invalidados",
+                    "type": "boolean"
+                },
+                "email": {
                     "type": "string"
                 },
-                "quantity": {
-                    "type": "integer"
+                "email_verified": {
+                    "description": "Confirmado pelo link enviado no registro",
+                    "type": "boolean"
                 },
-                "updated_at": {
+                "id": {
                     "type": "string"
                 },
-                "variant_id": {
-                    "type": "string"
+                "role": {
+                    "$ref": "#/definitions/domain.UserRole"
                 },
-                "version": {
-                    "description": "Para Controle de Concorrência Otimista (OCC)",
-                    "type": "integer"
+                "service_account": {
+                    "description": "Conta de integração: sem senha, acessa apenas com chaves de API",
+                    "type": "boolean"
                 },
-                "warehouse_id": {
+                "tenant_id": {
+                    "description": "Empresa do usuário: todas as requisições dele ficam restritas a ela",
+                    "type": "string"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "domain.User": {
+        "domain.UserList": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "email": {
-                    "type": "string"
+                "limit": {
+                    "type": "integer"
                 },
-                "id": {
-                    "type": "string"
+                "page": {
+                    "type": "integer"
                 },
-                "role": {
-                    "$ref": "#/definitions/domain.UserRole"
+                "total": {
+                    "description": "Total de usuários cadastrados",
+                    "type": "integer"
                 },
-                "updated_at": {
-                    "type": "string"
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.User"
+                    }
                 }
             }
         },
         "domain.UserRegistration": {
             "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
             "properties": {
                 "email": {
                     "type": "string"
@@ -706,21 +6115,59 @@ const docTemplate = `{
             "enum": [
                 "admin",
                 "user",
-                "guest"
+                "guest",
+                "user"
             ],
             "x-enum-varnames": [
                 "RoleAdmin",
                 "RoleUser",
-                "RoleGuest"
+                "RoleGuest",
+                "DefaultRole"
             ]
         },
+        "domain.UserRoleAssignment": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "example": "warehouse_staff"
+                }
+            }
+        },
+        "domain.UserWarehouses": {
+            "type": "object",
+            "properties": {
+                "user_id": {
+                    "type": "string"
+                },
+                "warehouse_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "domain.Variant": {
             "type": "object",
+            "required": [
+                "barcode"
+            ],
             "properties": {
                 "attribute": {
-                    "description": "Ex: \"Cor\"",
+                    "description": "Ex: \"Cor\" (ou \"Cor / Tamanho\" com vários eixos)",
                     "type": "string"
                 },
+                "attributes": {
+                    "description": "Ex: {\"Cor\": \"Vermelho\", \"Tamanho\": \"M\"}",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
                 "barcode": {
                     "type": "string"
                 },
@@ -729,19 +6176,153 @@ const docTemplate = `{
                 },
                 "price_diff": {
                     "description": "Ajuste de preço para esta variante",
-                    "type": "number"
+                    "type": "number",
+                    "minimum": 0
                 },
                 "product_id": {
                     "type": "string"
                 },
                 "value": {
-                    "description": "Ex: \"Vermelho\"",
+                    "description": "Ex: \"Vermelho\" (ou \"Vermelho / M\")",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.VariantBarcode": {
+            "type": "object",
+            "required": [
+                "barcode",
+                "variant_id"
+            ],
+            "properties": {
+                "barcode": {
+                    "type": "string",
+                    "example": "17891234567892"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "unit": {
+                    "type": "string",
+                    "maxLength": 20,
+                    "example": "cx"
+                },
+                "variant_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.VariantGenerationRequest": {
+            "type": "object",
+            "required": [
+                "attributes"
+            ],
+            "properties": {
+                "attributes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "price_diff": {
+                    "description": "Aplicado a todas as variantes geradas",
+                    "type": "number",
+                    "minimum": 0
+                }
+            }
+        },
+        "domain.VariantGenerationResult": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Variant"
+                    }
+                },
+                "product": {
+                    "$ref": "#/definitions/domain.Product"
+                },
+                "skipped": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.VariantPrice": {
+            "type": "object",
+            "required": [
+                "variant_id"
+            ],
+            "properties": {
+                "amount": {
+                    "description": "Em unidades mínimas (4990 = 49,90 BRL)",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 4990
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "price_list_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "valid_from": {
+                    "type": "string"
+                },
+                "valid_to": {
+                    "description": "Exclusivo",
+                    "type": "string"
+                },
+                "variant_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.VariantUnits": {
+            "type": "object",
+            "required": [
+                "base_unit"
+            ],
+            "properties": {
+                "base_unit": {
+                    "type": "string",
+                    "maxLength": 20,
+                    "example": "un"
+                },
+                "conversions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.UnitConversion"
+                    }
+                },
+                "decimal_places": {
+                    "description": "0 = variante discreta",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 0
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "variant_id": {
                     "type": "string"
                 }
             }
         },
         "domain.Warehouse": {
             "type": "object",
+            "required": [
+                "name"
+            ],
             "properties": {
                 "created_at": {
                     "type": "string"
@@ -750,13 +6331,26 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "name": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 3
                 },
                 "updated_at": {
                     "type": "string"
                 }
             }
         },
+        "product.CategorySchemaRequest": {
+            "type": "object",
+            "properties": {
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.CustomAttributeField"
+                    }
+                }
+            }
+        },
         "product.ProductCreateRequest": {
             "type": "object",
             "properties": {
@@ -771,8 +6365,127 @@ const docTemplate = `{
                 }
             }
         },
+        "product.ProductCustomAttributesRequest": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Vestuário"
+                },
+                "custom_attributes": {
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        },
+        "product.ProductMediaRequest": {
+            "type": "object",
+            "properties": {
+                "alt_text": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Camiseta azul, vista frontal"
+                },
+                "sort_order": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://cdn.example.com/camiseta-azul.jpg"
+                },
+                "variant_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "product.ProductRevertRequest": {
+            "type": "object",
+            "required": [
+                "version"
+            ],
+            "properties": {
+                "version": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 2
+                }
+            }
+        },
+        "role.RoleRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Equipe de armazém"
+                },
+                "name": {
+                    "description": "Apenas na criação",
+                    "type": "string",
+                    "maxLength": 50,
+                    "example": "warehouse_staff"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Permission"
+                    },
+                    "example": [
+                        "stock:adjust"
+                    ]
+                }
+            }
+        },
+        "token.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                },
+                "y": {
+                    "type": "string"
+                }
+            }
+        },
+        "token.JWKS": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/token.JWK"
+                    }
+                }
+            }
+        },
         "user.LoginRequest": {
             "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
             "properties": {
                 "email": {
                     "type": "string"
@@ -788,6 +6501,11 @@ const docTemplate = `{
             "type": "apiKey",
             "name": "Authorization",
             "in": "header"
+        },
+        "IntegrationKey": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
         }
     }
 }`
@@ -0,0 +1,124 @@
+package barcode
+
+import (
+	"net/http"
+	"strconv"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/response"
+	"gostock/internal/pkg/validation"
+)
+
+// BarcodeService define o contrato que o Handler espera do serviço de produtos.
+type BarcodeService interface {
+	LookupBarcode(ctx domain.Context, code string) (domain.BarcodeLookup, error)
+	AddVariantBarcode(ctx domain.Context, barcode domain.VariantBarcode) (domain.VariantBarcode, error)
+	GetVariantBarcodes(ctx domain.Context, variantID string) ([]domain.VariantBarcode, error)
+	RemoveVariantBarcode(ctx domain.Context, code string) error
+}
+
+// StockReader define o contrato usado para anexar o estoque atual à leitura do código.
+type StockReader interface {
+	GetVariantStock(ctx domain.Context, variantID string) ([]domain.StockLevel, error)
+}
+
+// Handler agrupa os endpoints de códigos de barras (leitura por scanners e cadastro de códigos adicionais).
+type Handler struct {
+	Products BarcodeService
+	Stock    StockReader
+	Logger   logger.Logger
+}
+
+// NewHandler cria uma nova instância do Handler, injetando os Services e o Logger.
+func NewHandler(products BarcodeService, stock StockReader, log logger.Logger) *Handler {
+	return &Handler{
+		Products: products,
+		Stock:    stock,
+		Logger:   log,
+	}
+}
+
+// LookupBarcodeHandler lida com a requisição GET /v1/barcodes/{code}.
+// @Summary Resolve um código de barras
+// @Description Retorna o produto e a variante donos do código (principal ou adicional). Para códigos adicionais, 'unit' indica a unidade de medida representada (ex.: "cx"). Com include_stock=true, inclui o estoque atual da variante por armazém. GTINs são encontrados com ou sem zeros à esquerda (UPC-A, EAN-13 e GTIN-14 equivalentes). A leitura tem rate limit próprio (300 por minuto por IP).
+// @Tags barcodes
+// @Produce json
+// @Param code path string true "Código de barras lido"
+// @Param include_stock query bool false "Incluir o estoque atual da variante" default(false)
+// @Success 200 {object} domain.BarcodeLookup "Produto e variante do código"
+// @Failure 404 {object} domain.ErrorResponse "Código de barras não cadastrado"
+// @Failure 429 {object} domain.ErrorResponse "Limite de leituras excedido"
+// @Security ApiKeyAuth
+// @Router /barcodes/{code} [get]
+func (h *Handler) LookupBarcodeHandler(w http.ResponseWriter, r *http.Request) {
+	includeStock := false
+	if raw := r.URL.Query().Get("include_stock"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			response.Send(w, r, h.Logger, nil, apperror.NewValidationError("O parâmetro 'include_stock' deve ser 'true' ou 'false'."), http.StatusBadRequest)
+			return
+		}
+		includeStock = parsed
+	}
+
+	lookup, err := h.Products.LookupBarcode(r.Context(), r.PathValue("code"))
+	if err == nil && includeStock {
+		lookup.Stock, err = h.Stock.GetVariantStock(r.Context(), lookup.Variant.ID)
+	}
+	response.Send(w, r, h.Logger, lookup, err, http.StatusOK)
+}
+
+// CreateVariantBarcodeHandler lida com a requisição POST /v1/barcodes.
+// @Summary Cadastra um código de barras adicional
+// @Description Associa mais um código de barras a uma variante, opcionalmente mapeado para uma unidade de medida (ex.: o código da caixa para "cx", configurada em /stock/units). Códigos GTIN (EAN-8, UPC-A, EAN-13, GTIN-14) têm o dígito verificador validado.
+// @Tags barcodes
+// @Accept json
+// @Produce json
+// @Param barcode body domain.VariantBarcode true "Código de barras adicional"
+// @Success 201 {object} domain.VariantBarcode "Código cadastrado"
+// @Failure 400 {object} domain.ErrorResponse "Código inválido"
+// @Failure 404 {object} domain.ErrorResponse "Variante não encontrada"
+// @Failure 409 {object} domain.ErrorResponse "Código já está em uso"
+// @Security ApiKeyAuth
+// @Router /barcodes [post]
+func (h *Handler) CreateVariantBarcodeHandler(w http.ResponseWriter, r *http.Request) {
+	var barcode domain.VariantBarcode
+	if err := validation.DecodeJSON(w, r, &barcode); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+
+	created, err := h.Products.AddVariantBarcode(r.Context(), barcode)
+	response.Send(w, r, h.Logger, created, err, http.StatusCreated)
+}
+
+// GetVariantBarcodesHandler lida com a requisição GET /v1/barcodes?variant_id=....
+// @Summary Lista os códigos de barras adicionais de uma variante
+// @Description O código principal da variante continua no campo 'barcode' da variante e não é listado aqui.
+// @Tags barcodes
+// @Produce json
+// @Param variant_id query string true "ID da variante"
+// @Success 200 {array} domain.VariantBarcode "Códigos adicionais"
+// @Failure 400 {object} domain.ErrorResponse "ID inválido"
+// @Security ApiKeyAuth
+// @Router /barcodes [get]
+func (h *Handler) GetVariantBarcodesHandler(w http.ResponseWriter, r *http.Request) {
+	barcodes, err := h.Products.GetVariantBarcodes(r.Context(), r.URL.Query().Get("variant_id"))
+	response.Send(w, r, h.Logger, barcodes, err, http.StatusOK)
+}
+
+// DeleteVariantBarcodeHandler lida com a requisição DELETE /v1/barcodes/{code}.
+// @Summary Remove um código de barras adicional
+// @Description Códigos principais fazem parte da variante e não podem ser removidos por aqui.
+// @Tags barcodes
+// @Param code path string true "Código de barras adicional"
+// @Success 204 "Código removido"
+// @Failure 404 {object} domain.ErrorResponse "Código adicional não encontrado"
+// @Security ApiKeyAuth
+// @Router /barcodes/{code} [delete]
+func (h *Handler) DeleteVariantBarcodeHandler(w http.ResponseWriter, r *http.Request) {
+	err := h.Products.RemoveVariantBarcode(r.Context(), r.PathValue("code"))
+	response.Send(w, r, h.Logger, nil, err, http.StatusNoContent)
+}
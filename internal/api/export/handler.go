@@ -0,0 +1,313 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/response"
+)
+
+// ProductExporter define o contrato que o Handler espera do serviço de produtos.
+type ProductExporter interface {
+	BuildProductFilter(filters map[string]string) (domain.ProductFilter, error)
+	ExportProducts(ctx domain.Context, filters map[string]string, fn func(domain.ProductExportRow) error) error
+}
+
+// StockExporter define o contrato que o Handler espera do serviço de estoque.
+type StockExporter interface {
+	ExportStock(ctx domain.Context, filter domain.StockExportFilter, fn func(domain.StockExportRow) error) error
+}
+
+// WarehouseLister define o contrato usado para montar as colunas de estoque por armazém.
+type WarehouseLister interface {
+	GetAllWarehouses(ctx domain.Context) ([]domain.Warehouse, error)
+}
+
+// Handler agrupa os endpoints de exportação (catálogo e estoque).
+type Handler struct {
+	Products   ProductExporter
+	Stock      StockExporter
+	Warehouses WarehouseLister
+	Logger     logger.Logger
+}
+
+// NewHandler cria uma nova instância do Handler, injetando os Services e o Logger.
+func NewHandler(products ProductExporter, stock StockExporter, warehouses WarehouseLister, log logger.Logger) *Handler {
+	return &Handler{
+		Products:   products,
+		Stock:      stock,
+		Warehouses: warehouses,
+		Logger:     log,
+	}
+}
+
+// flushEvery define a cada quantas linhas a resposta é enviada ao cliente.
+const flushEvery = 500
+
+// ExportProductsHandler lida com a requisição GET /v1/export/products.
+// @Summary Exporta o catálogo de produtos
+// @Description Exporta produtos (uma linha por variante) com o estoque por armazém, em CSV ou NDJSON, transmitindo linha a linha. Aceita os mesmos filtros e ordenação de GET /products (page e limit são ignorados). No CSV há uma coluna "qty:<armazém>" por armazém; no NDJSON, o objeto "quantities" é indexado pelo ID do armazém.
+// @Tags export
+// @Produce plain
+// @Param format query string false "Formato de saída" Enums(csv, ndjson) default(csv)
+// @Param name query string false "Filtrar por nome do produto"
+// @Param sku query string false "Filtrar por SKU"
+// @Param is_active query boolean false "Filtrar apenas por produtos ativos"
+// @Param sort_by query string false "Campo de ordenação" Enums(name, price, sku, created_at, updated_at)
+// @Param sort_order query string false "Direção da ordenação" Enums(asc, desc)
+// @Param min_price query number false "Preço mínimo (inclusivo)"
+// @Param max_price query number false "Preço máximo (inclusivo)"
+// @Param created_from query string false "Criados a partir de (AAAA-MM-DD ou RFC3339)"
+// @Param created_to query string false "Criados até (AAAA-MM-DD ou RFC3339)"
+// @Param updated_from query string false "Atualizados a partir de (AAAA-MM-DD ou RFC3339)"
+// @Param updated_to query string false "Atualizados até (AAAA-MM-DD ou RFC3339)"
+// @Param has_variants query boolean false "Filtrar produtos com (true) ou sem (false) variantes"
+// @Param barcode query string false "Filtrar por código de barras de uma variante"
+// @Param attribute query string false "Filtrar por atributo de variante"
+// @Param attribute_value query string false "Valor do atributo de variante; exige 'attribute'"
+// @Success 200 {string} string "Arquivo CSV ou NDJSON"
+// @Failure 400 {object} domain.ErrorResponse "Parâmetros inválidos"
+// @Failure 500 {object} domain.ErrorResponse "Erro interno do servidor"
+// @Security ApiKeyAuth
+// @Router /export/products [get]
+func (h *Handler) ExportProductsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	format, err := parseExportFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+	filters := queryFilters(r)
+
+	// As colunas de estoque por armazém precisam ser conhecidas antes da primeira linha.
+	warehouses, err := h.Warehouses.GetAllWarehouses(ctx)
+	if err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+
+	out := newStreamWriter(w, format, "products")
+	writeRow := func(row domain.ProductExportRow) error {
+		if format == domain.ExportFormatNDJSON {
+			return out.writeJSON(row)
+		}
+		if !out.started {
+			header := []string{"product_id", "sku", "name", "description", "price", "is_active", "created_at", "updated_at",
+				"variant_id", "attribute", "value", "barcode", "price_diff", "total_quantity"}
+			for _, wh := range warehouses {
+				header = append(header, "qty:"+wh.Name)
+			}
+			if err := out.writeCSV(header); err != nil {
+				return err
+			}
+		}
+		record := []string{
+			row.ProductID, row.SKU, row.Name, row.Description, formatFloat(row.Price), strconv.FormatBool(row.IsActive),
+			row.CreatedAt.UTC().Format(time.RFC3339), row.UpdatedAt.UTC().Format(time.RFC3339),
+			row.VariantID, row.Attribute, row.Value, row.Barcode, formatFloat(row.PriceDiff), strconv.Itoa(row.TotalQuantity),
+		}
+		for _, wh := range warehouses {
+			record = append(record, strconv.Itoa(row.Quantities[wh.ID]))
+		}
+		return out.writeCSV(record)
+	}
+
+	err = h.Products.ExportProducts(ctx, filters, writeRow)
+	h.finish(w, r, out, err)
+}
+
+// ExportStockHandler lida com a requisição GET /v1/export/stock.
+// @Summary Exporta os níveis de estoque
+// @Description Exporta os níveis de estoque (uma linha por variante e armazém) em CSV ou NDJSON, transmitindo linha a linha. Aceita os filtros de produto de GET /products e, opcionalmente, um armazém.
+// @Tags export
+// @Produce plain
+// @Param format query string false "Formato de saída" Enums(csv, ndjson) default(csv)
+// @Param warehouse_id query string false "Restringe a exportação a um armazém"
+// @Param name query string false "Filtrar por nome do produto"
+// @Param sku query string false "Filtrar por SKU"
+// @Param is_active query boolean false "Filtrar apenas por produtos ativos"
+// @Param sort_by query string false "Ordenação dos produtos dentro de cada armazém" Enums(name, price, sku, created_at, updated_at)
+// @Param sort_order query string false "Direção da ordenação" Enums(asc, desc)
+// @Param min_price query number false "Preço mínimo (inclusivo)"
+// @Param max_price query number false "Preço máximo (inclusivo)"
+// @Param barcode query string false "Filtrar por código de barras de uma variante"
+// @Param attribute query string false "Filtrar por atributo de variante"
+// @Param attribute_value query string false "Valor do atributo de variante; exige 'attribute'"
+// @Success 200 {string} string "Arquivo CSV ou NDJSON"
+// @Failure 400 {object} domain.ErrorResponse "Parâmetros inválidos"
+// @Failure 500 {object} domain.ErrorResponse "Erro interno do servidor"
+// @Security ApiKeyAuth
+// @Router /export/stock [get]
+func (h *Handler) ExportStockHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	format, err := parseExportFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+
+	filters := queryFilters(r)
+	productFilter, err := h.Products.BuildProductFilter(filters)
+	if err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+	filter := domain.StockExportFilter{Product: productFilter, WarehouseID: filters["warehouse_id"]}
+
+	out := newStreamWriter(w, format, "stock")
+	writeRow := func(row domain.StockExportRow) error {
+		if format == domain.ExportFormatNDJSON {
+			return out.writeJSON(row)
+		}
+		if !out.started {
+			header := []string{"warehouse_id", "warehouse_name", "product_id", "sku", "product_name",
+				"variant_id", "attribute", "value", "barcode", "quantity", "version", "updated_at"}
+			if err := out.writeCSV(header); err != nil {
+				return err
+			}
+		}
+		return out.writeCSV([]string{
+			row.WarehouseID, row.WarehouseName, row.ProductID, row.SKU, row.ProductName,
+			row.VariantID, row.Attribute, row.Value, row.Barcode,
+			strconv.Itoa(row.Quantity), strconv.Itoa(row.Version), row.UpdatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	err = h.Stock.ExportStock(ctx, filter, writeRow)
+	h.finish(w, r, out, err)
+}
+
+// finish encerra a exportação. Se o streaming ainda não começou, o erro vira uma resposta
+// JSON padronizada; depois do primeiro byte só é possível registrar o erro e interromper.
+func (h *Handler) finish(w http.ResponseWriter, r *http.Request, out *streamWriter, err error) {
+	if err != nil && !out.started {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+	if err != nil {
+		h.Logger.WithContext(r.Context()).Error("Exportação interrompida após o início do envio.", err)
+		return
+	}
+	if !out.started {
+		out.begin() // Nenhuma linha: envia apenas os cabeçalhos HTTP (corpo vazio)
+	}
+	if flushErr := out.flush(); flushErr != nil {
+		h.Logger.WithContext(r.Context()).Warn("Falha ao finalizar envio da exportação.", map[string]interface{}{"path": r.URL.Path, "error": flushErr.Error()})
+		return
+	}
+	h.Logger.WithContext(r.Context()).Info("Exportação concluída com sucesso", map[string]interface{}{"path": r.URL.Path, "rows": out.rows})
+}
+
+// streamWriter escreve a resposta linha a linha, enviando os cabeçalhos HTTP só na primeira
+// escrita (para que erros anteriores ainda possam virar uma resposta JSON) e descarregando
+// periodicamente para manter a memória constante.
+type streamWriter struct {
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	format  domain.ExportFormat
+	name    string
+	csv     *csv.Writer
+	json    *json.Encoder
+	started bool
+	rows    int
+}
+
+func newStreamWriter(w http.ResponseWriter, format domain.ExportFormat, name string) *streamWriter {
+	return &streamWriter{w: w, rc: http.NewResponseController(w), format: format, name: name}
+}
+
+// begin envia os cabeçalhos HTTP e remove o WriteTimeout do servidor para esta resposta,
+// já que exportações grandes podem levar mais tempo que uma requisição comum.
+func (s *streamWriter) begin() {
+	s.started = true
+	_ = s.rc.SetWriteDeadline(time.Time{}) // Nem todo ResponseWriter suporta; nesse caso vale o timeout do servidor
+
+	filename := fmt.Sprintf("%s-%s.%s", s.name, time.Now().UTC().Format("20060102-150405"), s.format)
+	if s.format == domain.ExportFormatNDJSON {
+		s.w.Header().Set("Content-Type", "application/x-ndjson")
+	} else {
+		s.w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	}
+	s.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	s.w.WriteHeader(http.StatusOK)
+
+	s.csv = csv.NewWriter(s.w)
+	s.json = json.NewEncoder(s.w)
+}
+
+func (s *streamWriter) writeCSV(record []string) error {
+	if !s.started {
+		s.begin()
+	}
+	if err := s.csv.Write(record); err != nil {
+		return err
+	}
+	return s.afterRow()
+}
+
+func (s *streamWriter) writeJSON(v interface{}) error {
+	if !s.started {
+		s.begin()
+	}
+	if err := s.json.Encode(v); err != nil {
+		return err
+	}
+	return s.afterRow()
+}
+
+func (s *streamWriter) afterRow() error {
+	s.rows++
+	if s.rows%flushEvery == 0 {
+		return s.flush()
+	}
+	return nil
+}
+
+func (s *streamWriter) flush() error {
+	if s.csv != nil {
+		s.csv.Flush()
+		if err := s.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if err := s.rc.Flush(); err != nil && err != http.ErrNotSupported {
+		return err
+	}
+	return nil
+}
+
+// parseExportFormat valida o parâmetro format (padrão: csv).
+func parseExportFormat(format string) (domain.ExportFormat, error) {
+	switch strings.ToLower(format) {
+	case "", "csv":
+		return domain.ExportFormatCSV, nil
+	case "ndjson", "jsonl":
+		return domain.ExportFormatNDJSON, nil
+	}
+	return "", apperror.NewValidationError("Parâmetro 'format' inválido. Use 'csv' ou 'ndjson'.")
+}
+
+// queryFilters copia os parâmetros de query (um valor por chave), ignorando os de paginação e formato.
+func queryFilters(r *http.Request) map[string]string {
+	filters := make(map[string]string)
+	for key, values := range r.URL.Query() {
+		if key != "page" && key != "limit" && key != "format" {
+			filters[key] = values[0]
+		}
+	}
+	return filters
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,44 @@
+package jwks
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/token"
+)
+
+// KeyProvider define o contrato de quem publica as chaves públicas de verificação (token.Service).
+type KeyProvider interface {
+	JWKS() token.JWKS
+}
+
+// Handler publica as chaves públicas usadas para verificar os JWTs emitidos pela API.
+type Handler struct {
+	Keys   KeyProvider
+	Logger logger.Logger
+}
+
+// NewHandler cria uma nova instância do Handler de JWKS.
+func NewHandler(keys KeyProvider, log logger.Logger) *Handler {
+	return &Handler{
+		Keys:   keys,
+		Logger: log,
+	}
+}
+
+// GetJWKSHandler lida com a requisição GET /.well-known/jwks.json.
+// @Summary Chaves públicas de verificação (JWKS)
+// @Description Publica, no formato JSON Web Key Set, as chaves públicas que verificam os access tokens: a chave ativa e as chaves em rotação. Outros serviços escolhem a chave pelo kid do cabeçalho do JWT. Com assinatura HMAC (HS256), a lista é vazia.
+// @Tags users
+// @Produce json
+// @Success 200 {object} token.JWKS "Conjunto de chaves"
+// @Router /.well-known/jwks.json [get]
+func (h *Handler) GetJWKSHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	// Verificadores podem guardar o documento por pouco tempo; novas chaves aparecem após a expiração
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if err := json.NewEncoder(w).Encode(h.Keys.JWKS()); err != nil {
+		h.Logger.WithContext(r.Context()).Error("Falha ao codificar o JWKS.", err)
+	}
+}
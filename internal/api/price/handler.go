@@ -0,0 +1,207 @@
+package price
+
+import (
+	"net/http"
+
+	"gostock/internal/domain"
+	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/response"
+	"gostock/internal/pkg/validation"
+)
+
+// PriceService define o contrato que o Handler espera da camada de Serviço.
+type PriceService interface {
+	CreatePriceList(ctx domain.Context, list domain.PriceList) (domain.PriceList, error)
+	GetPriceListByID(ctx domain.Context, id string) (domain.PriceList, error)
+	GetAllPriceLists(ctx domain.Context) ([]domain.PriceList, error)
+	UpdatePriceList(ctx domain.Context, list domain.PriceList) (domain.PriceList, error)
+	DeletePriceList(ctx domain.Context, id string) error
+
+	CreateVariantPrice(ctx domain.Context, price domain.VariantPrice) (domain.VariantPrice, error)
+	GetVariantPrices(ctx domain.Context, priceListID, variantID string) ([]domain.VariantPrice, error)
+	DeleteVariantPrice(ctx domain.Context, priceListID, id string) error
+	ResolvePrice(ctx domain.Context, variantID, priceListID, at string) (domain.ResolvedPrice, error)
+}
+
+// Handler agrupa todos os métodos de Handler de tabelas de preços.
+type Handler struct {
+	Service PriceService
+	Logger  logger.Logger
+}
+
+// NewHandler cria uma nova instância do Handler, injetando o Service e o Logger.
+func NewHandler(svc PriceService, log logger.Logger) *Handler {
+	return &Handler{
+		Service: svc,
+		Logger:  log,
+	}
+}
+
+// CreatePriceListHandler lida com a requisição POST /v1/price-lists.
+// @Summary Cria uma tabela de preços
+// @Description Cria uma tabela de preços nomeada em uma moeda (ISO 4217), opcionalmente associada a um segmento de clientes. Se 'is_active' for omitido, a tabela é criada ativa.
+// @Tags prices
+// @Accept json
+// @Produce json
+// @Param price_list body domain.PriceList true "Dados da tabela de preços"
+// @Success 201 {object} domain.PriceList "Tabela de preços criada"
+// @Failure 400 {object} domain.ErrorResponse "Payload inválido"
+// @Failure 409 {object} domain.ErrorResponse "Nome já utilizado"
+// @Failure 500 {object} domain.ErrorResponse "Erro interno do servidor"
+// @Security ApiKeyAuth
+// @Router /price-lists [post]
+func (h *Handler) CreatePriceListHandler(w http.ResponseWriter, r *http.Request) {
+	list := domain.PriceList{IsActive: true}
+	if err := validation.DecodeJSON(w, r, &list); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+	list.ID = ""
+
+	created, err := h.Service.CreatePriceList(r.Context(), list)
+	response.Send(w, r, h.Logger, created, err, http.StatusCreated)
+}
+
+// GetAllPriceListsHandler lida com a requisição GET /v1/price-lists.
+// @Summary Lista as tabelas de preços
+// @Description Retorna todas as tabelas de preços cadastradas, ordenadas por nome.
+// @Tags prices
+// @Produce json
+// @Success 200 {array} domain.PriceList "Lista de tabelas de preços"
+// @Failure 500 {object} domain.ErrorResponse "Erro interno do servidor"
+// @Security ApiKeyAuth
+// @Router /price-lists [get]
+func (h *Handler) GetAllPriceListsHandler(w http.ResponseWriter, r *http.Request) {
+	lists, err := h.Service.GetAllPriceLists(r.Context())
+	response.Send(w, r, h.Logger, lists, err, http.StatusOK)
+}
+
+// GetPriceListByIDHandler lida com a requisição GET /v1/price-lists/{id}.
+// @Summary Obtém uma tabela de preços por ID
+// @Tags prices
+// @Produce json
+// @Param id path string true "ID da tabela de preços"
+// @Success 200 {object} domain.PriceList "Tabela de preços encontrada"
+// @Failure 404 {object} domain.ErrorResponse "Tabela de preços não encontrada"
+// @Security ApiKeyAuth
+// @Router /price-lists/{id} [get]
+func (h *Handler) GetPriceListByIDHandler(w http.ResponseWriter, r *http.Request) {
+	list, err := h.Service.GetPriceListByID(r.Context(), r.PathValue("id"))
+	response.Send(w, r, h.Logger, list, err, http.StatusOK)
+}
+
+// UpdatePriceListHandler lida com a requisição PUT /v1/price-lists/{id}.
+// @Summary Atualiza uma tabela de preços
+// @Description Substitui nome, moeda, segmento e status da tabela. Os valores dos preços já cadastrados não são convertidos ao trocar a moeda.
+// @Tags prices
+// @Accept json
+// @Produce json
+// @Param id path string true "ID da tabela de preços"
+// @Param price_list body domain.PriceList true "Dados da tabela de preços"
+// @Success 200 {object} domain.PriceList "Tabela de preços atualizada"
+// @Failure 400 {object} domain.ErrorResponse "Payload inválido"
+// @Failure 404 {object} domain.ErrorResponse "Tabela de preços não encontrada"
+// @Failure 409 {object} domain.ErrorResponse "Nome já utilizado"
+// @Security ApiKeyAuth
+// @Router /price-lists/{id} [put]
+func (h *Handler) UpdatePriceListHandler(w http.ResponseWriter, r *http.Request) {
+	list := domain.PriceList{IsActive: true}
+	if err := validation.DecodeJSON(w, r, &list); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+	list.ID = r.PathValue("id") // O ID da URL prevalece sobre o do corpo
+
+	updated, err := h.Service.UpdatePriceList(r.Context(), list)
+	response.Send(w, r, h.Logger, updated, err, http.StatusOK)
+}
+
+// DeletePriceListHandler lida com a requisição DELETE /v1/price-lists/{id}.
+// @Summary Deleta uma tabela de preços
+// @Description Remove a tabela de preços e todos os preços cadastrados nela.
+// @Tags prices
+// @Param id path string true "ID da tabela de preços"
+// @Success 204 "Nenhum conteúdo"
+// @Failure 404 {object} domain.ErrorResponse "Tabela de preços não encontrada"
+// @Security ApiKeyAuth
+// @Router /price-lists/{id} [delete]
+func (h *Handler) DeletePriceListHandler(w http.ResponseWriter, r *http.Request) {
+	err := h.Service.DeletePriceList(r.Context(), r.PathValue("id"))
+	response.Send(w, r, h.Logger, nil, err, http.StatusNoContent)
+}
+
+// CreateVariantPriceHandler lida com a requisição POST /v1/price-lists/{id}/prices.
+// @Summary Cadastra o preço de uma variante
+// @Description Cadastra o preço de uma variante na tabela, em unidades mínimas da moeda (ex.: 4990 = 49,90 BRL), com janela de validade opcional [valid_from, valid_to). Janelas podem se sobrepor: na resolução vence a de início mais recente (ex.: uma promoção sobre o preço padrão).
+// @Tags prices
+// @Accept json
+// @Produce json
+// @Param id path string true "ID da tabela de preços"
+// @Param price body domain.VariantPrice true "Preço da variante"
+// @Success 201 {object} domain.VariantPrice "Preço cadastrado"
+// @Failure 400 {object} domain.ErrorResponse "Payload inválido"
+// @Failure 404 {object} domain.ErrorResponse "Tabela de preços ou variante não encontrada"
+// @Security ApiKeyAuth
+// @Router /price-lists/{id}/prices [post]
+func (h *Handler) CreateVariantPriceHandler(w http.ResponseWriter, r *http.Request) {
+	var price domain.VariantPrice
+	if err := validation.DecodeJSON(w, r, &price); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+	price.ID = ""
+	price.PriceListID = r.PathValue("id")
+
+	created, err := h.Service.CreateVariantPrice(r.Context(), price)
+	response.Send(w, r, h.Logger, created, err, http.StatusCreated)
+}
+
+// GetVariantPricesHandler lida com a requisição GET /v1/price-lists/{id}/prices.
+// @Summary Lista os preços de uma tabela
+// @Tags prices
+// @Produce json
+// @Param id path string true "ID da tabela de preços"
+// @Param variant_id query string false "Filtrar por variante"
+// @Success 200 {array} domain.VariantPrice "Preços da tabela"
+// @Failure 400 {object} domain.ErrorResponse "Parâmetros inválidos"
+// @Failure 404 {object} domain.ErrorResponse "Tabela de preços não encontrada"
+// @Security ApiKeyAuth
+// @Router /price-lists/{id}/prices [get]
+func (h *Handler) GetVariantPricesHandler(w http.ResponseWriter, r *http.Request) {
+	prices, err := h.Service.GetVariantPrices(r.Context(), r.PathValue("id"), r.URL.Query().Get("variant_id"))
+	response.Send(w, r, h.Logger, prices, err, http.StatusOK)
+}
+
+// DeleteVariantPriceHandler lida com a requisição DELETE /v1/price-lists/{id}/prices/{priceId}.
+// @Summary Remove o preço de uma variante
+// @Tags prices
+// @Param id path string true "ID da tabela de preços"
+// @Param priceId path string true "ID do preço"
+// @Success 204 "Nenhum conteúdo"
+// @Failure 404 {object} domain.ErrorResponse "Preço não encontrado"
+// @Security ApiKeyAuth
+// @Router /price-lists/{id}/prices/{priceId} [delete]
+func (h *Handler) DeleteVariantPriceHandler(w http.ResponseWriter, r *http.Request) {
+	err := h.Service.DeleteVariantPrice(r.Context(), r.PathValue("id"), r.PathValue("price_id"))
+	response.Send(w, r, h.Logger, nil, err, http.StatusNoContent)
+}
+
+// ResolvePriceHandler lida com a requisição GET /v1/prices/resolve.
+// @Summary Resolve o preço efetivo de uma variante
+// @Description Retorna o preço vigente da variante na tabela de preços no instante informado (padrão: agora).
+// @Tags prices
+// @Produce json
+// @Param variant_id query string true "ID da variante"
+// @Param price_list_id query string true "ID da tabela de preços"
+// @Param at query string false "Instante da consulta (RFC3339)"
+// @Success 200 {object} domain.ResolvedPrice "Preço efetivo"
+// @Failure 400 {object} domain.ErrorResponse "Parâmetros inválidos"
+// @Failure 404 {object} domain.ErrorResponse "Tabela não encontrada ou nenhum preço vigente"
+// @Failure 409 {object} domain.ErrorResponse "Tabela de preços inativa"
+// @Security ApiKeyAuth
+// @Router /prices/resolve [get]
+func (h *Handler) ResolvePriceHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	resolved, err := h.Service.ResolvePrice(r.Context(), query.Get("variant_id"), query.Get("price_list_id"), query.Get("at"))
+	response.Send(w, r, h.Logger, resolved, err, http.StatusOK)
+}
@@ -0,0 +1,143 @@
+package product
+
+import (
+	"net/http"
+
+	"gostock/internal/domain"
+	"gostock/internal/pkg/validation"
+)
+
+// CreateAttributeDefinitionHandler lida com a requisição POST /v1/attributes.
+// @Summary Cria uma definição de atributo
+// @Description Define um eixo de variação (ex.: "Tamanho") e seus valores permitidos, na ordem de exibição. Variantes que usam um atributo definido só aceitam os valores permitidos.
+// @Tags attributes
+// @Accept json
+// @Produce json
+// @Param attribute body domain.AttributeDefinition true "Nome e valores permitidos"
+// @Success 201 {object} domain.AttributeDefinition "Definição criada"
+// @Failure 400 {object} domain.ErrorResponse "Payload inválido"
+// @Failure 409 {object} domain.ErrorResponse "Atributo já definido"
+// @Security ApiKeyAuth
+// @Router /attributes [post]
+func (h *Handler) CreateAttributeDefinitionHandler(w http.ResponseWriter, r *http.Request) {
+	var def domain.AttributeDefinition
+	if err := validation.DecodeJSON(w, r, &def); err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+
+	created, err := h.Service.CreateAttributeDefinition(r.Context(), def)
+	if err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+	h.handleServiceResponse(w, r, created, nil, http.StatusCreated)
+}
+
+// GetAttributeDefinitionsHandler lida com a requisição GET /v1/attributes.
+// @Summary Lista as definições de atributos
+// @Tags attributes
+// @Produce json
+// @Success 200 {array} domain.AttributeDefinition "Definições de atributos"
+// @Router /attributes [get]
+func (h *Handler) GetAttributeDefinitionsHandler(w http.ResponseWriter, r *http.Request) {
+	defs, err := h.Service.GetAttributeDefinitions(r.Context())
+	if err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+	h.handleServiceResponse(w, r, defs, nil, http.StatusOK)
+}
+
+// GetAttributeDefinitionByIDHandler lida com a requisição GET /v1/attributes/{id}.
+// @Summary Busca uma definição de atributo
+// @Tags attributes
+// @Produce json
+// @Param id path string true "ID do atributo"
+// @Success 200 {object} domain.AttributeDefinition "Definição de atributo"
+// @Failure 404 {object} domain.ErrorResponse "Atributo não encontrado"
+// @Router /attributes/{id} [get]
+func (h *Handler) GetAttributeDefinitionByIDHandler(w http.ResponseWriter, r *http.Request) {
+	def, err := h.Service.GetAttributeDefinitionByID(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+	h.handleServiceResponse(w, r, def, nil, http.StatusOK)
+}
+
+// UpdateAttributeDefinitionHandler lida com a requisição PUT /v1/attributes/{id}.
+// @Summary Atualiza uma definição de atributo
+// @Description Substitui o nome e os valores permitidos. Variantes existentes não são alteradas; os valores só restringem novas combinações.
+// @Tags attributes
+// @Accept json
+// @Produce json
+// @Param id path string true "ID do atributo"
+// @Param attribute body domain.AttributeDefinition true "Nome e valores permitidos"
+// @Success 200 {object} domain.AttributeDefinition "Definição atualizada"
+// @Failure 400 {object} domain.ErrorResponse "Payload inválido"
+// @Failure 404 {object} domain.ErrorResponse "Atributo não encontrado"
+// @Failure 409 {object} domain.ErrorResponse "Nome já usado por outro atributo"
+// @Security ApiKeyAuth
+// @Router /attributes/{id} [put]
+func (h *Handler) UpdateAttributeDefinitionHandler(w http.ResponseWriter, r *http.Request) {
+	var def domain.AttributeDefinition
+	if err := validation.DecodeJSON(w, r, &def); err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+	def.ID = r.PathValue("id")
+
+	updated, err := h.Service.UpdateAttributeDefinition(r.Context(), def)
+	if err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+	h.handleServiceResponse(w, r, updated, nil, http.StatusOK)
+}
+
+// DeleteAttributeDefinitionHandler lida com a requisição DELETE /v1/attributes/{id}.
+// @Summary Remove uma definição de atributo
+// @Description As variantes que usam o atributo são mantidas; o eixo apenas deixa de ter valores restritos.
+// @Tags attributes
+// @Param id path string true "ID do atributo"
+// @Success 204 "Definição removida"
+// @Failure 404 {object} domain.ErrorResponse "Atributo não encontrado"
+// @Security ApiKeyAuth
+// @Router /attributes/{id} [delete]
+func (h *Handler) DeleteAttributeDefinitionHandler(w http.ResponseWriter, r *http.Request) {
+	err := h.Service.DeleteAttributeDefinition(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+	h.handleServiceResponse(w, r, nil, nil, http.StatusNoContent)
+}
+
+// GenerateVariantsHandler lida com a requisição POST /v1/products/{id}/variants/generate.
+// @Summary Gera variantes a partir de combinações de atributos
+// @Description Cria uma variante para cada combinação dos valores informados (ex.: {"attributes": {"Cor": ["Azul", "Verde"], "Tamanho": []}}). Todo atributo precisa estar definido em /attributes; lista vazia usa todos os valores permitidos. Combinações já existentes no produto são ignoradas. O limite é de 500 combinações por requisição.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "ID do Produto"
+// @Param generation body domain.VariantGenerationRequest true "Valores de cada atributo"
+// @Success 201 {object} domain.VariantGenerationResult "Variantes geradas"
+// @Failure 400 {object} domain.ErrorResponse "Atributo não definido, valor não permitido ou combinações demais"
+// @Failure 404 {object} domain.ErrorResponse "Produto não encontrado"
+// @Security ApiKeyAuth
+// @Router /products/{id}/variants/generate [post]
+func (h *Handler) GenerateVariantsHandler(w http.ResponseWriter, r *http.Request) {
+	var req domain.VariantGenerationRequest
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+
+	result, err := h.Service.GenerateVariants(r.Context(), r.PathValue("id"), req)
+	if err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+	h.handleServiceResponse(w, r, result, nil, http.StatusCreated)
+}
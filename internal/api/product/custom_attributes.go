@@ -0,0 +1,123 @@
+package product
+
+import (
+	"net/http"
+
+	"gostock/internal/domain"
+	"gostock/internal/pkg/validation"
+)
+
+// CategorySchemaRequest define o payload de gravação do esquema de uma categoria.
+type CategorySchemaRequest struct {
+	Fields []domain.CustomAttributeField `json:"fields"`
+}
+
+// ProductCustomAttributesRequest define o payload de alteração da categoria e das especificações de um produto.
+type ProductCustomAttributesRequest struct {
+	Category         string                 `json:"category" example:"Vestuário" validate:"max=100"`
+	CustomAttributes map[string]interface{} `json:"custom_attributes"`
+}
+
+// GetCategorySchemasHandler lida com a requisição GET /v1/categories.
+// @Summary Lista os esquemas de atributos personalizados
+// @Tags categories
+// @Produce json
+// @Success 200 {array} domain.CategorySchema "Esquemas por categoria"
+// @Router /categories [get]
+func (h *Handler) GetCategorySchemasHandler(w http.ResponseWriter, r *http.Request) {
+	schemas, err := h.Service.GetCategorySchemas(r.Context())
+	if err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+	h.handleServiceResponse(w, r, schemas, nil, http.StatusOK)
+}
+
+// GetCategorySchemaHandler lida com a requisição GET /v1/categories/{category}/schema.
+// @Summary Busca o esquema de atributos personalizados de uma categoria
+// @Tags categories
+// @Produce json
+// @Param category path string true "Categoria"
+// @Success 200 {object} domain.CategorySchema "Esquema da categoria"
+// @Failure 404 {object} domain.ErrorResponse "Categoria sem esquema"
+// @Router /categories/{category}/schema [get]
+func (h *Handler) GetCategorySchemaHandler(w http.ResponseWriter, r *http.Request) {
+	schema, err := h.Service.GetCategorySchema(r.Context(), r.PathValue("category"))
+	if err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+	h.handleServiceResponse(w, r, schema, nil, http.StatusOK)
+}
+
+// PutCategorySchemaHandler lida com a requisição PUT /v1/categories/{category}/schema.
+// @Summary Cria ou substitui o esquema de uma categoria
+// @Description Define os campos tipados (string, number, bool ou enum com "values") aceitos em custom_attributes pelos produtos da categoria. Produtos já gravados não são revalidados.
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param category path string true "Categoria"
+// @Param schema body CategorySchemaRequest true "Campos do esquema"
+// @Success 200 {object} domain.CategorySchema "Esquema gravado"
+// @Failure 400 {object} domain.ErrorResponse "Esquema inválido"
+// @Security ApiKeyAuth
+// @Router /categories/{category}/schema [put]
+func (h *Handler) PutCategorySchemaHandler(w http.ResponseWriter, r *http.Request) {
+	var req CategorySchemaRequest
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+
+	schema, err := h.Service.SaveCategorySchema(r.Context(), domain.CategorySchema{Category: r.PathValue("category"), Fields: req.Fields})
+	if err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+	h.handleServiceResponse(w, r, schema, nil, http.StatusOK)
+}
+
+// DeleteCategorySchemaHandler lida com a requisição DELETE /v1/categories/{category}/schema.
+// @Summary Remove o esquema de uma categoria
+// @Description Os produtos mantêm os atributos gravados; novas gravações com custom_attributes na categoria passam a ser recusadas.
+// @Tags categories
+// @Param category path string true "Categoria"
+// @Success 204 "Esquema removido"
+// @Failure 404 {object} domain.ErrorResponse "Categoria sem esquema"
+// @Security ApiKeyAuth
+// @Router /categories/{category}/schema [delete]
+func (h *Handler) DeleteCategorySchemaHandler(w http.ResponseWriter, r *http.Request) {
+	if err := h.Service.DeleteCategorySchema(r.Context(), r.PathValue("category")); err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+	h.handleServiceResponse(w, r, nil, nil, http.StatusNoContent)
+}
+
+// UpdateProductCustomAttributesHandler lida com a requisição PUT /v1/products/{id}/custom-attributes.
+// @Summary Altera a categoria e as especificações de um produto
+// @Description Substitui a categoria e os atributos personalizados, validados pelo esquema da categoria. Enviar custom_attributes vazio remove as especificações. A alteração é registrada no histórico.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "ID do Produto"
+// @Param attributes body ProductCustomAttributesRequest true "Categoria e atributos personalizados"
+// @Success 200 {object} domain.Product "Produto atualizado"
+// @Failure 400 {object} domain.ErrorResponse "Atributos inválidos para o esquema"
+// @Failure 404 {object} domain.ErrorResponse "Produto não encontrado"
+// @Security ApiKeyAuth
+// @Router /products/{id}/custom-attributes [put]
+func (h *Handler) UpdateProductCustomAttributesHandler(w http.ResponseWriter, r *http.Request) {
+	var req ProductCustomAttributesRequest
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+
+	product, err := h.Service.UpdateProductCustomAttributes(r.Context(), r.PathValue("id"), req.Category, req.CustomAttributes)
+	if err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+	h.handleServiceResponse(w, r, product, nil, http.StatusOK)
+}
@@ -1,16 +1,18 @@
 package product
 
 import (
-	"encoding/json"
 	"errors"
-	"fmt"
 	"gostock/internal/domain"
 	apperror "gostock/internal/errors"
 	"gostock/internal/pkg/logger" // Importação correta do nosso pacote Logger
 	"gostock/internal/pkg/middleware"
+	"gostock/internal/pkg/response"
+	"gostock/internal/pkg/validation"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // ProductService define o contrato que o Handler espera da camada de Serviço.
@@ -19,6 +21,27 @@ type ProductService interface {
 	CreateProduct(ctx domain.Context, p domain.Product, variants []domain.Variant) (domain.Product, error)
 	GetProductByID(ctx domain.Context, id string) (domain.Product, error)
 	GetProducts(ctx domain.Context, page, limit int, filters map[string]string) ([]domain.Product, error)
+	ImportProducts(ctx domain.Context, format domain.ImportFormat, src io.Reader, dryRun bool) (domain.ImportReport, error)
+	StartProductImport(ctx domain.Context, format domain.ImportFormat, src io.Reader, dryRun bool) (domain.ImportReport, error)
+	GetImportJob(ctx domain.Context, id string) (domain.ImportReport, error)
+	GetProductHistory(ctx domain.Context, productID string, page, limit int) ([]domain.ProductVersion, error)
+	RevertProduct(ctx domain.Context, productID string, version int) (domain.Product, error)
+	GenerateVariants(ctx domain.Context, productID string, req domain.VariantGenerationRequest) (domain.VariantGenerationResult, error)
+	CreateAttributeDefinition(ctx domain.Context, def domain.AttributeDefinition) (domain.AttributeDefinition, error)
+	GetAttributeDefinitions(ctx domain.Context) ([]domain.AttributeDefinition, error)
+	GetAttributeDefinitionByID(ctx domain.Context, id string) (domain.AttributeDefinition, error)
+	UpdateAttributeDefinition(ctx domain.Context, def domain.AttributeDefinition) (domain.AttributeDefinition, error)
+	DeleteAttributeDefinition(ctx domain.Context, id string) error
+	SaveCategorySchema(ctx domain.Context, schema domain.CategorySchema) (domain.CategorySchema, error)
+	GetCategorySchemas(ctx domain.Context) ([]domain.CategorySchema, error)
+	GetCategorySchema(ctx domain.Context, category string) (domain.CategorySchema, error)
+	DeleteCategorySchema(ctx domain.Context, category string) error
+	UpdateProductCustomAttributes(ctx domain.Context, productID, category string, attrs map[string]interface{}) (domain.Product, error)
+	GetProductMedia(ctx domain.Context, productID string) ([]domain.ProductMedia, error)
+	AddProductMedia(ctx domain.Context, media domain.ProductMedia) (domain.ProductMedia, error)
+	UploadProductMedia(ctx domain.Context, productID string, upload domain.MediaUpload, src io.Reader) (domain.ProductMedia, error)
+	UpdateProductMedia(ctx domain.Context, media domain.ProductMedia) (domain.ProductMedia, error)
+	DeleteProductMedia(ctx domain.Context, productID, mediaID string) error
 	// ...
 }
 
@@ -39,56 +62,26 @@ func NewHandler(svc ProductService, log logger.Logger) *Handler {
 
 // --- Funções Auxiliares (do passo anterior, adaptadas) ---
 
-// handleServiceResponse processa erros de serviço e envia respostas padronizadas ao cliente.
+// handleServiceResponse envia a resposta do handler, registrando as operações concluídas com sucesso;
+// os erros seguem o formato padronizado do pacote response.
 func (h *Handler) handleServiceResponse(w http.ResponseWriter, r *http.Request, data interface{}, err error, successStatus int) {
 	if err == nil {
-		// Sucesso
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(successStatus)
-
-		// Log de Sucesso (Registro da operação)
-		h.Logger.Info("Requisição concluída com sucesso", map[string]interface{}{
+		h.Logger.WithContext(r.Context()).Info("Requisição concluída com sucesso", map[string]interface{}{
 			"method": r.Method,
 			"path":   r.URL.Path,
 			"status": successStatus,
 		})
-
-		if data != nil {
-			if jsonErr := json.NewEncoder(w).Encode(data); jsonErr != nil {
-				h.Logger.Error("Falha ao codificar JSON de resposta", jsonErr)
-				http.Error(w, "Erro ao codificar resposta", http.StatusInternalServerError)
-			}
-		}
-		return
-	}
-
-	// TRATAMENTO DE ERROS (Módulo: Error Handling)
-	status, category, message := apperror.MapToHTTPStatus(err)
-
-	if status >= 500 {
-		h.Logger.Error(fmt.Sprintf("Erro de Servidor: %s", category), err)
-	} else {
-		// Erros de cliente (4xx) são logged como info/warn
-		h.Logger.Debug(fmt.Sprintf("Requisição rejeitada com status %d. Categoria: %s", status, category), map[string]interface{}{"path": r.URL.Path})
 	}
-
-	errorResponse := map[string]interface{}{
-		"code":     status,
-		"category": category,
-		"message":  message,
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(errorResponse)
+	response.Send(w, r, h.Logger, data, err, successStatus)
 }
 
 // --- Handlers de Produto ---
 
-// ProductCreateRequest define o payload para a criação de um produto.
+// ProductCreateRequest define o payload para a criação de um produto. O produto e as variantes
+// são validados juntos pelo serviço (validateProduct), depois de reunidos.
 type ProductCreateRequest struct {
-	Product  domain.Product   `json:"Product"`
-	Variants []domain.Variant `json:"Variants"`
+	Product  domain.Product   `json:"Product" validate:"-"`
+	Variants []domain.Variant `json:"Variants" validate:"-"`
 }
 
 // CreateProductHandler lida com a requisição POST /v1/products.
@@ -104,19 +97,13 @@ type ProductCreateRequest struct {
 // @Security ApiKeyAuth
 // @Router /products [post]
 func (h *Handler) CreateProductHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		// Aqui, o log de erro simples é aceitável, pois é um erro de protocolo base.
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
-		return
-	}
-
 	// O contexto nativo (context.Context) será passado como domain.Context
 	ctx := r.Context()
 
 	claims, ok := middleware.GetUserClaimsFromContext(ctx)
 	if ok {
 		// Logamos o ID do usuário que está criando o produto
-		h.Logger.Info("Tentativa de criação de produto por", map[string]interface{}{
+		h.Logger.WithContext(r.Context()).Info("Tentativa de criação de produto por", map[string]interface{}{
 			"user_id": claims.UserID,
 			"role":    claims.Role,
 		})
@@ -124,15 +111,13 @@ func (h *Handler) CreateProductHandler(w http.ResponseWriter, r *http.Request) {
 		// Você usaria este ID para anexar o criador ao produto (product.CreatorID = claims.UserID)
 	} else {
 		// Isso só aconteceria se o middleware falhasse ou fosse ignorado na rota, mas é uma boa prática
-		h.Logger.Warn("Tentativa de criação de produto sem claims de usuário no contexto.", nil)
+		h.Logger.WithContext(r.Context()).Warn("Tentativa de criação de produto sem claims de usuário no contexto.", nil)
 	}
 
 	// Decodificação do Payload (Usando struct anônima temporária para incluir Variants)
 	var productRequest ProductCreateRequest
-	if err := json.NewDecoder(r.Body).Decode(&productRequest); err != nil {
-		// Usa a função padronizada para erros de validação
-		// (Ajustei o status para 400 Bad Request, que é o correto para erro de payload)
-		h.handleServiceResponse(w, r, nil, apperror.NewValidationError("Payload inválido. Verifique o formato JSON."), http.StatusBadRequest)
+	if err := validation.DecodeJSON(w, r, &productRequest); err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
 		return
 	}
 	productRequest.Product.Variants = productRequest.Variants
@@ -151,7 +136,7 @@ func (h *Handler) CreateProductHandler(w http.ResponseWriter, r *http.Request) {
 
 			// O erro é um InternalError (que inclui DBError).
 			// O h.Logger irá imprimir a CAUSA RAIZ (o erro SQL subjacente).
-			h.Logger.Error("ERRO CRÍTICO (500) NA TRANSAÇÃO SQL:", internalErr)
+			h.Logger.WithContext(r.Context()).Error("ERRO CRÍTICO (500) NA TRANSAÇÃO SQL:", internalErr)
 
 			// Passamos o erro para a função auxiliar que o formatará como um 500 genérico.
 			h.handleServiceResponse(w, r, nil, internalErr, http.StatusCreated)
@@ -181,27 +166,8 @@ func (h *Handler) GetProductByIDHandler(w http.ResponseWriter, r *http.Request)
 
 	ctx := r.Context()
 
-	// 1. Extrair ID do Segmento da URL
-
-	// a. Remove barras extras no início e no fim para normalizar
-	path := strings.Trim(r.URL.Path, "/")
-	// b. Divide a string em segmentos: ["v1", "products", "3c95b8c8..."]
-	segments := strings.Split(path, "/")
-
-	// O ID deve ser o último segmento (índice 2, pois o roteador já validou len == 3)
-	if len(segments) != 3 {
-		// Se a validação do router falhar, retornamos 400 (Bad Request)
-		h.handleServiceResponse(w, r, nil, apperror.NewValidationError("Formato de URL inválido ou ID ausente."), http.StatusOK)
-		return
-	}
-
-	productID := segments[2]
-
-	// Verificação de ID vazio (embora o len(segments) == 3 já minimize isso)
-	if productID == "" {
-		h.handleServiceResponse(w, r, nil, apperror.NewValidationError("ID do produto é obrigatório."), http.StatusOK)
-		return
-	}
+	// 1. ID do produto (segmento {id} do padrão da rota)
+	productID := r.PathValue("id")
 
 	// 2. Chamar o Serviço (Lógica de Negócio)
 	product, err := h.Service.GetProductByID(ctx, productID)
@@ -219,7 +185,7 @@ func (h *Handler) GetProductByIDHandler(w http.ResponseWriter, r *http.Request)
 
 // GetProductsHandler lida com a requisição GET /v1/products.
 // @Summary Lista produtos com filtros e paginação
-// @Description Retorna uma lista de produtos com base em filtros e paginação.
+// @Description Retorna uma lista de produtos com base em filtros e paginação. Parâmetros no formato attr.<Nome>=<valor> (ex.: attr.Cor=Azul&attr.Tamanho=M) filtram por valor exato de atributo; todos os atributos informados devem estar na mesma variante. Parâmetros spec.<campo>=<valor> (ex.: spec.material=Algodão&spec.peso_kg=0.2) filtram por valor exato dos atributos personalizados do produto.
 // @Tags products
 // @Produce json
 // @Param page query int false "Número da página" default(1)
@@ -227,6 +193,19 @@ func (h *Handler) GetProductByIDHandler(w http.ResponseWriter, r *http.Request)
 // @Param name query string false "Filtrar por nome do produto"
 // @Param sku query string false "Filtrar por SKU"
 // @Param active_only query boolean false "Filtrar apenas por produtos ativos"
+// @Param sort_by query string false "Campo de ordenação" Enums(name, price, sku, created_at, updated_at)
+// @Param sort_order query string false "Direção da ordenação" Enums(asc, desc) default(asc)
+// @Param min_price query number false "Preço mínimo (inclusivo)"
+// @Param max_price query number false "Preço máximo (inclusivo)"
+// @Param created_from query string false "Criados a partir de (AAAA-MM-DD ou RFC3339)"
+// @Param created_to query string false "Criados até (AAAA-MM-DD ou RFC3339)"
+// @Param updated_from query string false "Atualizados a partir de (AAAA-MM-DD ou RFC3339)"
+// @Param updated_to query string false "Atualizados até (AAAA-MM-DD ou RFC3339)"
+// @Param has_variants query boolean false "Filtrar produtos com (true) ou sem (false) variantes"
+// @Param barcode query string false "Filtrar por código de barras de uma variante"
+// @Param attribute query string false "Filtrar por atributo de variante (ex: Cor)"
+// @Param attribute_value query string false "Valor do atributo de variante (ex: Vermelho); exige 'attribute'"
+// @Param category query string false "Filtrar por categoria (sem diferenciar maiúsculas)"
 // @Success 200 {array} domain.Product "Lista de produtos"
 // @Failure 400 {object} domain.ErrorResponse "Parâmetros de query inválidos"
 // @Failure 500 {object} domain.ErrorResponse "Erro interno do servidor"
@@ -270,6 +249,203 @@ func (h *Handler) GetProductsHandler(w http.ResponseWriter, r *http.Request) {
 	h.handleServiceResponse(w, r, products, nil, http.StatusOK)
 }
 
+const (
+	// maxImportBodyBytes limita o tamanho do arquivo de importação aceito.
+	maxImportBodyBytes = 256 << 20 // 256 MiB
+	// asyncImportThreshold define o tamanho a partir do qual a importação vira um job em segundo plano.
+	asyncImportThreshold = 1 << 20 // 1 MiB
+)
+
+// ImportProductsHandler lida com a requisição POST /v1/products/import.
+// @Summary Importa produtos em lote (CSV ou NDJSON)
+// @Description Importa produtos e variantes fazendo upsert por SKU. No CSV cada linha é uma variante (colunas: sku, name, description, price, attribute, value, barcode, price_diff; colunas extras "attr:<Nome>" definem atributos adicionais da variante; a coluna opcional category e colunas "spec:<campo>" definem a categoria e os atributos personalizados do produto) e linhas consecutivas com o mesmo SKU formam um produto. No NDJSON cada linha é um produto com o array "variants". Com dry_run=true o arquivo é apenas validado. Arquivos grandes, uploads sem Content-Length (chunked) ou async=true são processados em segundo plano e retornam 202 com o ID do job.
+// @Tags products
+// @Accept plain
+// @Produce json
+// @Param format query string false "Formato do arquivo (padrão: deduzido do Content-Type)" Enums(csv, ndjson)
+// @Param dry_run query boolean false "Apenas valida, sem gravar" default(false)
+// @Param async query boolean false "Força o processamento em segundo plano" default(false)
+// @Param file body string true "Conteúdo do arquivo CSV ou NDJSON"
+// @Success 200 {object} domain.ImportReport "Relatório da importação síncrona"
+// @Success 202 {object} domain.ImportReport "Job de importação criado"
+// @Failure 400 {object} domain.ErrorResponse "Formato ou arquivo inválido"
+// @Failure 500 {object} domain.ErrorResponse "Erro interno do servidor"
+// @Security ApiKeyAuth
+// @Router /products/import [post]
+func (h *Handler) ImportProductsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	format, err := resolveImportFormat(query.Get("format"), r.Header.Get("Content-Type"))
+	if err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+
+	dryRun, err := parseBoolOrDefault(query.Get("dry_run"), false)
+	if err != nil {
+		h.handleServiceResponse(w, r, nil, apperror.NewValidationError("Parâmetro 'dry_run' inválido."), http.StatusOK)
+		return
+	}
+	async, err := parseBoolOrDefault(query.Get("async"), false)
+	if err != nil {
+		h.handleServiceResponse(w, r, nil, apperror.NewValidationError("Parâmetro 'async' inválido."), http.StatusOK)
+		return
+	}
+
+	// O upload e a importação síncrona podem passar do Read/WriteTimeout do servidor; remove os
+	// prazos apenas desta requisição (o corpo continua limitado por maxImportBodyBytes).
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Time{}) // Nem todo ResponseWriter suporta; nesse caso vale o timeout do servidor
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	body := http.MaxBytesReader(w, r.Body, maxImportBodyBytes)
+
+	// Uploads sem Content-Length (chunked) têm tamanho desconhecido e seguem o caminho assíncrono
+	if async || r.ContentLength < 0 || r.ContentLength > asyncImportThreshold {
+		report, err := h.Service.StartProductImport(ctx, format, body, dryRun)
+		if err != nil {
+			h.handleServiceResponse(w, r, nil, err, http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Location", "/v1/imports/"+report.JobID)
+		h.handleServiceResponse(w, r, report, nil, http.StatusAccepted)
+		return
+	}
+
+	report, err := h.Service.ImportProducts(ctx, format, body, dryRun)
+	if err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+	h.handleServiceResponse(w, r, report, nil, http.StatusOK)
+}
+
+// GetImportJobHandler lida com a requisição GET /v1/imports/{id}.
+// @Summary Consulta o status de um job de importação
+// @Description Retorna o progresso e os erros por linha de uma importação de produtos.
+// @Tags products
+// @Produce json
+// @Param id path string true "ID do job de importação"
+// @Success 200 {object} domain.ImportReport "Status do job"
+// @Failure 400 {object} domain.ErrorResponse "ID inválido"
+// @Failure 404 {object} domain.ErrorResponse "Job não encontrado"
+// @Security ApiKeyAuth
+// @Router /imports/{id} [get]
+func (h *Handler) GetImportJobHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	report, err := h.Service.GetImportJob(ctx, r.PathValue("id"))
+	if err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+	h.handleServiceResponse(w, r, report, nil, http.StatusOK)
+}
+
+// ProductRevertRequest define o payload para a reversão de um produto.
+type ProductRevertRequest struct {
+	Version int `json:"version" example:"2" validate:"required,min=1"`
+}
+
+// GetProductHistoryHandler lida com a requisição GET /v1/products/{id}/history.
+// @Summary Lista o histórico de versões de um produto
+// @Description Retorna as versões do produto (mais recente primeiro), com os campos alterados, o snapshot completo e quem fez a alteração.
+// @Tags products
+// @Produce json
+// @Param id path string true "ID do Produto"
+// @Param page query int false "Número da página" default(1)
+// @Param limit query int false "Limite de itens por página (máx. 100)" default(20)
+// @Success 200 {array} domain.ProductVersion "Histórico do produto"
+// @Failure 400 {object} domain.ErrorResponse "Parâmetros inválidos"
+// @Failure 404 {object} domain.ErrorResponse "Produto não encontrado"
+// @Security ApiKeyAuth
+// @Router /products/{id}/history [get]
+func (h *Handler) GetProductHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	query := r.URL.Query()
+	page, err := parseIntOrDefault(query.Get("page"), 1)
+	if err != nil {
+		h.handleServiceResponse(w, r, nil, apperror.NewValidationError("Parâmetro 'page' inválido."), http.StatusBadRequest)
+		return
+	}
+	limit, err := parseIntOrDefault(query.Get("limit"), 20)
+	if err != nil {
+		h.handleServiceResponse(w, r, nil, apperror.NewValidationError("Parâmetro 'limit' inválido."), http.StatusBadRequest)
+		return
+	}
+
+	versions, err := h.Service.GetProductHistory(ctx, r.PathValue("id"), page, limit)
+	if err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+	h.handleServiceResponse(w, r, versions, nil, http.StatusOK)
+}
+
+// RevertProductHandler lida com a requisição POST /v1/products/{id}/revert.
+// @Summary Reverte um produto para uma versão anterior
+// @Description Restaura o produto e suas variantes ao estado de uma versão do histórico. A reversão é registrada como uma nova versão. Variantes criadas depois da versão restaurada são removidas, exceto se tiverem estoque (409).
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "ID do Produto"
+// @Param revert body ProductRevertRequest true "Versão a restaurar"
+// @Success 200 {object} domain.Product "Produto restaurado"
+// @Failure 400 {object} domain.ErrorResponse "Payload inválido"
+// @Failure 404 {object} domain.ErrorResponse "Produto ou versão não encontrados"
+// @Failure 409 {object} domain.ErrorResponse "Versão não pode ser restaurada"
+// @Security ApiKeyAuth
+// @Router /products/{id}/revert [post]
+func (h *Handler) RevertProductHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req ProductRevertRequest
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+
+	product, err := h.Service.RevertProduct(ctx, r.PathValue("id"), req.Version)
+	if err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+	h.handleServiceResponse(w, r, product, nil, http.StatusOK)
+}
+
+// resolveImportFormat usa o parâmetro format ou, na ausência dele, o Content-Type.
+func resolveImportFormat(format, contentType string) (domain.ImportFormat, error) {
+	switch strings.ToLower(format) {
+	case "csv":
+		return domain.ImportFormatCSV, nil
+	case "ndjson", "jsonl":
+		return domain.ImportFormatNDJSON, nil
+	case "":
+		// Deduz pelo Content-Type abaixo
+	default:
+		return "", apperror.NewValidationError("Parâmetro 'format' inválido. Use 'csv' ou 'ndjson'.")
+	}
+
+	contentType = strings.ToLower(contentType)
+	switch {
+	case strings.Contains(contentType, "csv"):
+		return domain.ImportFormatCSV, nil
+	case strings.Contains(contentType, "ndjson"), strings.Contains(contentType, "jsonl"), strings.Contains(contentType, "json-seq"):
+		return domain.ImportFormatNDJSON, nil
+	}
+	return "", apperror.NewValidationError("Não foi possível determinar o formato do arquivo. Informe o parâmetro 'format' (csv ou ndjson).")
+}
+
+// parseBoolOrDefault é uma função auxiliar para parsear bool ou retornar default.
+func parseBoolOrDefault(s string, defaultValue bool) (bool, error) {
+	if s == "" {
+		return defaultValue, nil
+	}
+	return strconv.ParseBool(s)
+}
+
 // parseIntOrDefault é uma função auxiliar para parsear int ou retornar default.
 func parseIntOrDefault(s string, defaultValue int) (int, error) {
 	if s == "" {
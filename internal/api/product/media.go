@@ -0,0 +1,190 @@
+package product
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/validation"
+)
+
+// maxMediaUploadBytes limita o tamanho de um arquivo de mídia enviado por upload.
+const maxMediaUploadBytes = 10 << 20 // 10 MiB
+
+// ProductMediaRequest define o payload de cadastro e alteração de uma mídia por URL.
+type ProductMediaRequest struct {
+	URL       string `json:"url" example:"https://cdn.example.com/camiseta-azul.jpg"`
+	VariantID string `json:"variant_id,omitempty" validate:"uuid"`
+	AltText   string `json:"alt_text" example:"Camiseta azul, vista frontal" validate:"max=255"`
+	SortOrder int    `json:"sort_order"`
+}
+
+// GetProductMediaHandler lida com a requisição GET /v1/products/{id}/media.
+// @Summary Lista as mídias de um produto
+// @Description Retorna as imagens do produto em ordem de exibição (sort_order). As mídias também acompanham o GET /products/{id}.
+// @Tags media
+// @Produce json
+// @Param id path string true "ID do Produto"
+// @Success 200 {array} domain.ProductMedia "Mídias do produto"
+// @Failure 400 {object} domain.ErrorResponse "ID inválido"
+// @Router /products/{id}/media [get]
+func (h *Handler) GetProductMediaHandler(w http.ResponseWriter, r *http.Request) {
+	productID := r.PathValue("id")
+	media, err := h.Service.GetProductMedia(r.Context(), productID)
+	if err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+	h.handleServiceResponse(w, r, media, nil, http.StatusOK)
+}
+
+// CreateProductMediaHandler lida com a requisição POST /v1/products/{id}/media.
+// @Summary Cadastra uma mídia do produto
+// @Description Com corpo JSON, cadastra uma mídia externa pela URL. Com multipart/form-data, envia o arquivo (campo "file", até 10 MiB; JPEG, PNG, GIF ou WebP) para o armazenamento local, com os campos opcionais "variant_id", "alt_text" e "sort_order".
+// @Tags media
+// @Accept json
+// @Accept mpfd
+// @Produce json
+// @Param id path string true "ID do Produto"
+// @Param media body ProductMediaRequest false "Mídia por URL (corpo JSON)"
+// @Param file formData file false "Arquivo de imagem (multipart)"
+// @Success 201 {object} domain.ProductMedia "Mídia cadastrada"
+// @Failure 400 {object} domain.ErrorResponse "Payload, arquivo ou variante inválidos"
+// @Failure 404 {object} domain.ErrorResponse "Produto não encontrado"
+// @Failure 413 {object} domain.ErrorResponse "Arquivo maior que o limite"
+// @Security ApiKeyAuth
+// @Router /products/{id}/media [post]
+func (h *Handler) CreateProductMediaHandler(w http.ResponseWriter, r *http.Request) {
+	productID := r.PathValue("id")
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		h.uploadProductMedia(w, r, productID)
+		return
+	}
+
+	var req ProductMediaRequest
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+
+	created, err := h.Service.AddProductMedia(r.Context(), domain.ProductMedia{
+		ProductID: productID,
+		URL:       req.URL,
+		VariantID: req.VariantID,
+		AltText:   req.AltText,
+		SortOrder: req.SortOrder,
+	})
+	if err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+	h.handleServiceResponse(w, r, created, nil, http.StatusCreated)
+}
+
+// uploadProductMedia trata o envio multipart de um arquivo de mídia.
+func (h *Handler) uploadProductMedia(w http.ResponseWriter, r *http.Request, productID string) {
+	// Margem de 1 MiB para os demais campos do formulário
+	r.Body = http.MaxBytesReader(w, r.Body, maxMediaUploadBytes+(1<<20))
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.writeTooLarge(w, r)
+			return
+		}
+		h.handleServiceResponse(w, r, nil, apperror.NewValidationError("Envie o arquivo no campo 'file' do formulário multipart."), http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	if header.Size > maxMediaUploadBytes {
+		h.writeTooLarge(w, r)
+		return
+	}
+
+	upload := domain.MediaUpload{
+		Filename:  header.Filename,
+		Size:      header.Size,
+		VariantID: r.FormValue("variant_id"),
+		AltText:   r.FormValue("alt_text"),
+	}
+	if raw := r.FormValue("sort_order"); raw != "" {
+		if upload.SortOrder, err = strconv.Atoi(raw); err != nil {
+			h.handleServiceResponse(w, r, nil, apperror.NewValidationError("O campo 'sort_order' deve ser um número inteiro."), http.StatusBadRequest)
+			return
+		}
+	}
+
+	created, err := h.Service.UploadProductMedia(r.Context(), productID, upload, file)
+	if err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+	h.handleServiceResponse(w, r, created, nil, http.StatusCreated)
+}
+
+// writeTooLarge responde 413 para arquivos acima do limite de upload.
+func (h *Handler) writeTooLarge(w http.ResponseWriter, r *http.Request) {
+	h.handleServiceResponse(w, r, nil, apperror.NewPayloadTooLargeError("O arquivo excede o limite de 10 MiB."), http.StatusOK)
+}
+
+// UpdateProductMediaHandler lida com a requisição PUT /v1/products/{id}/media/{media_id}.
+// @Summary Atualiza uma mídia do produto
+// @Description Altera a variante associada, o texto alternativo e a ordem de exibição. A URL não é alterada.
+// @Tags media
+// @Accept json
+// @Produce json
+// @Param id path string true "ID do Produto"
+// @Param media_id path string true "ID da mídia"
+// @Param media body ProductMediaRequest true "Novos metadados (url é ignorada)"
+// @Success 200 {object} domain.ProductMedia "Mídia atualizada"
+// @Failure 400 {object} domain.ErrorResponse "Payload ou variante inválidos"
+// @Failure 404 {object} domain.ErrorResponse "Mídia não encontrada"
+// @Security ApiKeyAuth
+// @Router /products/{id}/media/{media_id} [put]
+func (h *Handler) UpdateProductMediaHandler(w http.ResponseWriter, r *http.Request) {
+	productID, mediaID := r.PathValue("id"), r.PathValue("media_id")
+
+	var req ProductMediaRequest
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+
+	updated, err := h.Service.UpdateProductMedia(r.Context(), domain.ProductMedia{
+		ID:        mediaID,
+		ProductID: productID,
+		VariantID: req.VariantID,
+		AltText:   req.AltText,
+		SortOrder: req.SortOrder,
+	})
+	if err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+	h.handleServiceResponse(w, r, updated, nil, http.StatusOK)
+}
+
+// DeleteProductMediaHandler lida com a requisição DELETE /v1/products/{id}/media/{media_id}.
+// @Summary Remove uma mídia do produto
+// @Description Arquivos enviados por upload também são apagados do armazenamento local.
+// @Tags media
+// @Param id path string true "ID do Produto"
+// @Param media_id path string true "ID da mídia"
+// @Success 204 "Mídia removida"
+// @Failure 404 {object} domain.ErrorResponse "Mídia não encontrada"
+// @Security ApiKeyAuth
+// @Router /products/{id}/media/{media_id} [delete]
+func (h *Handler) DeleteProductMediaHandler(w http.ResponseWriter, r *http.Request) {
+	productID, mediaID := r.PathValue("id"), r.PathValue("media_id")
+	if err := h.Service.DeleteProductMedia(r.Context(), productID, mediaID); err != nil {
+		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return
+	}
+	h.handleServiceResponse(w, r, nil, nil, http.StatusNoContent)
+}
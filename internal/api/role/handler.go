@@ -0,0 +1,172 @@
+package role
+
+import (
+	"net/http"
+
+	"gostock/internal/domain"
+	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/response"
+	"gostock/internal/pkg/validation"
+)
+
+// RoleService define o contrato que o Handler espera da camada de Serviço.
+type RoleService interface {
+	GetPermissions(ctx domain.Context) []domain.PermissionInfo
+	GetRoles(ctx domain.Context) ([]domain.Role, error)
+	GetRole(ctx domain.Context, name string) (domain.Role, error)
+	CreateRole(ctx domain.Context, role domain.Role) (domain.Role, error)
+	UpdateRole(ctx domain.Context, role domain.Role) (domain.Role, error)
+	DeleteRole(ctx domain.Context, name string) error
+	AssignUserRole(ctx domain.Context, userID, roleName string) error
+}
+
+// RoleRequest representa o payload de criação e alteração de um papel.
+type RoleRequest struct {
+	Name        string              `json:"name,omitempty" example:"warehouse_staff" validate:"max=50"` // Apenas na criação
+	Description string              `json:"description" example:"Equipe de armazém" validate:"max=255"`
+	Permissions []domain.Permission `json:"permissions" example:"stock:adjust"`
+}
+
+// Handler agrupa os métodos de Handler de papéis e permissões.
+type Handler struct {
+	Service RoleService
+	Logger  logger.Logger
+}
+
+// NewHandler cria uma nova instância do Handler, injetando o Service e o Logger.
+func NewHandler(svc RoleService, log logger.Logger) *Handler {
+	return &Handler{
+		Service: svc,
+		Logger:  log,
+	}
+}
+
+// GetPermissionsHandler lida com a requisição GET /v1/permissions.
+// @Summary Lista as permissões
+// @Description Retorna o catálogo de permissões que podem ser atribuídas a papéis.
+// @Tags roles
+// @Produce json
+// @Success 200 {array} domain.PermissionInfo "Permissões"
+// @Failure 403 {object} domain.ErrorResponse "Apenas administradores"
+// @Security ApiKeyAuth
+// @Router /permissions [get]
+func (h *Handler) GetPermissionsHandler(w http.ResponseWriter, r *http.Request) {
+	response.Send(w, r, h.Logger, h.Service.GetPermissions(r.Context()), nil, http.StatusOK)
+}
+
+// GetRolesHandler lida com a requisição GET /v1/roles.
+// @Summary Lista os papéis
+// @Description Retorna todos os papéis com as suas permissões.
+// @Tags roles
+// @Produce json
+// @Success 200 {array} domain.Role "Papéis"
+// @Failure 403 {object} domain.ErrorResponse "Apenas administradores"
+// @Failure 500 {object} domain.ErrorResponse "Erro interno do servidor"
+// @Security ApiKeyAuth
+// @Router /roles [get]
+func (h *Handler) GetRolesHandler(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.Service.GetRoles(r.Context())
+	response.Send(w, r, h.Logger, roles, err, http.StatusOK)
+}
+
+// GetRoleHandler lida com a requisição GET /v1/roles/{name}.
+// @Summary Obtém um papel
+// @Tags roles
+// @Produce json
+// @Param name path string true "Nome do papel"
+// @Success 200 {object} domain.Role "Papel"
+// @Failure 404 {object} domain.ErrorResponse "Papel não encontrado"
+// @Security ApiKeyAuth
+// @Router /roles/{name} [get]
+func (h *Handler) GetRoleHandler(w http.ResponseWriter, r *http.Request) {
+	role, err := h.Service.GetRole(r.Context(), r.PathValue("name"))
+	response.Send(w, r, h.Logger, role, err, http.StatusOK)
+}
+
+// CreateRoleHandler lida com a requisição POST /v1/roles.
+// @Summary Cria um papel
+// @Description Cria um papel com um conjunto de permissões do catálogo (GET /v1/permissions).
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param role body RoleRequest true "Nome, descrição e permissões"
+// @Success 201 {object} domain.Role "Papel criado"
+// @Failure 400 {object} domain.ErrorResponse "Nome inválido ou permissão desconhecida"
+// @Failure 409 {object} domain.ErrorResponse "Papel já existe"
+// @Security ApiKeyAuth
+// @Router /roles [post]
+func (h *Handler) CreateRoleHandler(w http.ResponseWriter, r *http.Request) {
+	var req RoleRequest
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+
+	created, err := h.Service.CreateRole(r.Context(), domain.Role{Name: req.Name, Description: req.Description, Permissions: req.Permissions})
+	response.Send(w, r, h.Logger, created, err, http.StatusCreated)
+}
+
+// UpdateRoleHandler lida com a requisição PUT /v1/roles/{name}.
+// @Summary Altera um papel
+// @Description Substitui a descrição e as permissões do papel. O papel admin não pode ser alterado. As novas permissões valem imediatamente para os usuários do papel.
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param name path string true "Nome do papel"
+// @Param role body RoleRequest true "Descrição e permissões"
+// @Success 200 {object} domain.Role "Papel alterado"
+// @Failure 400 {object} domain.ErrorResponse "Permissão desconhecida"
+// @Failure 404 {object} domain.ErrorResponse "Papel não encontrado"
+// @Failure 409 {object} domain.ErrorResponse "Papel admin"
+// @Security ApiKeyAuth
+// @Router /roles/{name} [put]
+func (h *Handler) UpdateRoleHandler(w http.ResponseWriter, r *http.Request) {
+	var req RoleRequest
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+
+	role := domain.Role{Name: r.PathValue("name"), Description: req.Description, Permissions: req.Permissions}
+	updated, err := h.Service.UpdateRole(r.Context(), role)
+	response.Send(w, r, h.Logger, updated, err, http.StatusOK)
+}
+
+// DeleteRoleHandler lida com a requisição DELETE /v1/roles/{name}.
+// @Summary Remove um papel
+// @Description Remove um papel que não é do sistema e não está atribuído a nenhum usuário.
+// @Tags roles
+// @Param name path string true "Nome do papel"
+// @Success 204 "Papel removido"
+// @Failure 404 {object} domain.ErrorResponse "Papel não encontrado"
+// @Failure 409 {object} domain.ErrorResponse "Papel do sistema ou em uso"
+// @Security ApiKeyAuth
+// @Router /roles/{name} [delete]
+func (h *Handler) DeleteRoleHandler(w http.ResponseWriter, r *http.Request) {
+	err := h.Service.DeleteRole(r.Context(), r.PathValue("name"))
+	response.Send(w, r, h.Logger, nil, err, http.StatusNoContent)
+}
+
+// AssignUserRoleHandler lida com a requisição PUT /v1/users/{id}/role.
+// @Summary Atribui um papel a um usuário
+// @Description Altera o papel do usuário. Os access tokens já emitidos são revogados; o novo papel vale a partir do próximo token (login ou renovação).
+// @Tags roles
+// @Accept json
+// @Param id path string true "ID do usuário"
+// @Param role body domain.UserRoleAssignment true "Papel"
+// @Success 204 "Papel atribuído"
+// @Failure 400 {object} domain.ErrorResponse "ID inválido"
+// @Failure 404 {object} domain.ErrorResponse "Usuário ou papel não encontrado"
+// @Failure 409 {object} domain.ErrorResponse "Remoção do próprio papel de administrador"
+// @Security ApiKeyAuth
+// @Router /users/{id}/role [put]
+func (h *Handler) AssignUserRoleHandler(w http.ResponseWriter, r *http.Request) {
+	var req domain.UserRoleAssignment
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+
+	err := h.Service.AssignUserRole(r.Context(), r.PathValue("id"), req.Role)
+	response.Send(w, r, h.Logger, nil, err, http.StatusNoContent)
+}
@@ -0,0 +1,126 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/response"
+)
+
+// Middleware envolve o handler de uma rota (autenticação, papéis, permissões, rate limit).
+type Middleware func(http.HandlerFunc) http.HandlerFunc
+
+// Router registra as rotas no http.ServeMux com os padrões do Go 1.22 ("GET /v1/products/{id}"):
+// o método e os parâmetros de caminho são resolvidos pelo ServeMux, e os handlers leem os
+// parâmetros com r.PathValue. Rotas inexistentes e métodos não aceitos respondem 404 e 405 em
+// JSON pelo pacote response, no mesmo formato dos erros dos handlers; o 405 informa os métodos
+// aceitos no header Allow.
+type Router struct {
+	mux *http.ServeMux
+}
+
+// New cria um Router vazio.
+func New() *Router {
+	return &Router{mux: http.NewServeMux()}
+}
+
+// Handle registra um handler sem os middlewares dos grupos (ex.: Swagger, JWKS e mídias).
+func (rt *Router) Handle(pattern string, handler http.Handler) {
+	rt.mux.Handle(pattern, handler)
+}
+
+// Group cria um grupo de rotas com o prefixo e os middlewares informados.
+func (rt *Router) Group(prefix string, middlewares ...Middleware) *Group {
+	return &Group{router: rt, prefix: prefix, middlewares: middlewares}
+}
+
+// ServeHTTP despacha a requisição. Quando nenhum padrão atende a requisição, o ServeMux
+// responderia 404 ou 405 em texto; a resposta é convertida para JSON.
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	handler, pattern := rt.mux.Handler(r)
+	if pattern != "" {
+		rt.mux.ServeHTTP(w, r)
+		return
+	}
+
+	// O handler de erro do ServeMux define o status e, no 405, o header Allow
+	rec := &statusRecorder{header: http.Header{}, status: http.StatusOK}
+	handler.ServeHTTP(rec, r)
+	if rec.status == http.StatusMethodNotAllowed {
+		allow := rec.header.Get("Allow")
+		response.Error(w, r, apperror.NewMethodNotAllowedError(fmt.Sprintf("%s não é aceito nesta URL (aceitos: %s).", r.Method, allow), allow))
+		return
+	}
+	notFound(w, r)
+}
+
+// notFound responde 404 em JSON para uma URL sem rota.
+func notFound(w http.ResponseWriter, r *http.Request) {
+	response.Error(w, r, apperror.NewNotFoundError(fmt.Sprintf("nenhuma rota atende %s %s.", r.Method, r.URL.Path)))
+}
+
+// Group é um conjunto de rotas com prefixo e middlewares em comum. Os middlewares do grupo
+// envolvem os da rota: em Post("", h, auth, perm), a ordem de execução é grupo, auth, perm, h.
+type Group struct {
+	router      *Router
+	prefix      string
+	middlewares []Middleware
+}
+
+// Group cria um subgrupo que herda o prefixo e os middlewares deste grupo.
+func (g *Group) Group(prefix string, middlewares ...Middleware) *Group {
+	chain := append(append([]Middleware{}, g.middlewares...), middlewares...)
+	return &Group{router: g.router, prefix: g.prefix + prefix, middlewares: chain}
+}
+
+// Get registra uma rota GET (e HEAD) no caminho relativo ao prefixo do grupo.
+func (g *Group) Get(path string, handler http.HandlerFunc, middlewares ...Middleware) {
+	g.handle(http.MethodGet, path, handler, middlewares)
+}
+
+// Post registra uma rota POST no caminho relativo ao prefixo do grupo.
+func (g *Group) Post(path string, handler http.HandlerFunc, middlewares ...Middleware) {
+	g.handle(http.MethodPost, path, handler, middlewares)
+}
+
+// Put registra uma rota PUT no caminho relativo ao prefixo do grupo.
+func (g *Group) Put(path string, handler http.HandlerFunc, middlewares ...Middleware) {
+	g.handle(http.MethodPut, path, handler, middlewares)
+}
+
+// Patch registra uma rota PATCH no caminho relativo ao prefixo do grupo.
+func (g *Group) Patch(path string, handler http.HandlerFunc, middlewares ...Middleware) {
+	g.handle(http.MethodPatch, path, handler, middlewares)
+}
+
+// Delete registra uma rota DELETE no caminho relativo ao prefixo do grupo.
+func (g *Group) Delete(path string, handler http.HandlerFunc, middlewares ...Middleware) {
+	g.handle(http.MethodDelete, path, handler, middlewares)
+}
+
+// handle aplica os middlewares do grupo e da rota e registra o padrão "MÉTODO /prefixo/caminho".
+func (g *Group) handle(method, path string, handler http.HandlerFunc, middlewares []Middleware) {
+	chain := append(append([]Middleware{}, g.middlewares...), middlewares...)
+	for i := len(chain) - 1; i >= 0; i-- {
+		handler = chain[i](handler)
+	}
+	g.router.mux.Handle(method+" "+g.prefix+path, handler)
+}
+
+// handlerMiddleware adapta um middleware de http.Handler (ex.: rate limiter) para Middleware.
+func handlerMiddleware(m func(http.Handler) http.Handler) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return m(next).ServeHTTP
+	}
+}
+
+// statusRecorder captura o status e os headers das respostas de erro do ServeMux, descartando o corpo.
+type statusRecorder struct {
+	header http.Header
+	status int
+}
+
+func (s *statusRecorder) Header() http.Header         { return s.header }
+func (s *statusRecorder) Write(b []byte) (int, error) { return len(b), nil }
+func (s *statusRecorder) WriteHeader(status int)      { s.status = status }
@@ -2,12 +2,17 @@ package router
 
 import (
 	"net/http"
-	"strings"
 	"time"
 
+	"gostock/internal/api/barcode"
+	"gostock/internal/api/export"
+	"gostock/internal/api/jwks"
+	"gostock/internal/api/price"
 	"gostock/internal/api/product"
-	"gostock/internal/api/user"
+	"gostock/internal/api/role"
 	"gostock/internal/api/stock"
+	"gostock/internal/api/tenant"
+	"gostock/internal/api/user"
 	"gostock/internal/api/warehouse" // Adicionado
 	"gostock/internal/domain"
 	"gostock/internal/pkg/cache"
@@ -23,111 +28,211 @@ type TokenService interface {
 	ValidateToken(tokenString string) (*token.CustomClaims, error)
 }
 
-// NewRouter configura e retorna o roteador da aplicação.
-// 🚨 ATUALIZAÇÃO DA ASSINATURA: Agora recebe o TokenService e o cache.Client.
-func NewRouter(productHandler *product.Handler, userHandler *user.Handler, stockHandler *stock.Handler, warehouseHandler *warehouse.Handler, tokenSvc TokenService, cacheClient cache.Client) *http.ServeMux {
-	mux := http.NewServeMux()
+// NewRouter configura e retorna o roteador da aplicação. As rotas usam os padrões do ServeMux
+// ("MÉTODO /caminho/{parâmetro}") organizados em grupos; os middlewares de cada grupo valem para
+// todas as suas rotas, e cada rota pode acrescentar os seus (autenticação, papel, permissão).
+func NewRouter(productHandler *product.Handler, userHandler *user.Handler, stockHandler *stock.Handler, warehouseHandler *warehouse.Handler, exportHandler *export.Handler, priceHandler *price.Handler, barcodeHandler *barcode.Handler, jwksHandler *jwks.Handler, roleHandler *role.Handler, tenantHandler *tenant.Handler, tokenSvc TokenService, apiKeys middleware.APIKeyAuthenticator, permissions middleware.PermissionChecker, cacheClient cache.Client) *Router {
+	rt := New()
 
 	// 1. Inicializa os Middlewares
-	authMiddleware := middleware.NewAuthMiddleware(tokenSvc)
+	// Aceita o access token (Authorization: Bearer) ou a chave de API (X-API-Key) das integrações
+	auth := Middleware(middleware.NewAuthMiddleware(tokenSvc, apiKeys, cacheClient))
+	// Gestão da própria conta exige o login interativo: chaves de API são recusadas
+	sessionOnly := Middleware(middleware.RequireSession)
+	// Rotas de escrita exigem uma permissão do papel do usuário (papéis configuráveis no DB)
+	requirePermission := func(permission domain.Permission) Middleware {
+		return middleware.RequirePermission(permissions, permission)
+	}
+	// Gestão de papéis: apenas o papel admin, para que nenhum papel configurável se autoconceda permissões
+	adminOnly := Middleware(middleware.PermissionMiddleware(domain.RoleAdmin))
+	// Cadastro de empresas e catálogo de papéis: apenas administradores da plataforma (tenant padrão)
+	platformOnly := Middleware(middleware.RequirePlatform)
 	// Limita a 10 requisições por minuto por IP
-	rateLimitMiddleware := middleware.RateLimiter(cacheClient, 10, time.Minute)
+	rateLimit := handlerMiddleware(middleware.RateLimiter(cacheClient, 10, time.Minute))
+
+	// A leitura de códigos de barras tem cota própria: scanners de caixa e recebimento fazem
+	// leituras em sequência, que esgotariam o limite geral
+	scanRateLimit := handlerMiddleware(middleware.ScopedRateLimiter(cacheClient, "barcode-lookup", 300, time.Minute))
+
+	// A API v1 passa pelo rate limiter (exceto a leitura de códigos de barras, abaixo)
+	v1 := rt.Group("/v1", rateLimit)
 
 	// --- Rotas de Produto (/v1/products) ---
-	// Aplica o Rate Limiter a todas as rotas de produto
-	productRoutes := http.NewServeMux()
-	productRoutes.HandleFunc("/v1/products", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodPost:
-			permissionMware := middleware.PermissionMiddleware(domain.RoleAdmin)
-			finalHandler := permissionMware(productHandler.CreateProductHandler)
-			authMiddleware(finalHandler).ServeHTTP(w, r)
-		case http.MethodGet:
-			productHandler.GetProductsHandler(w, r)
-		default:
-			http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
-		}
-	})
-	productRoutes.HandleFunc("/v1/products/", func(w http.ResponseWriter, r *http.Request) {
-		path := strings.Trim(r.URL.Path, "/")
-		segments := strings.Split(path, "/")
-		if len(segments) != 3 {
-			http.Error(w, "ID do produto inválido ou ausente na URL.", http.StatusNotFound)
-			return
-		}
-		switch r.Method {
-		case http.MethodGet:
-			productHandler.GetProductByIDHandler(w, r)
-		default:
-			http.Error(w, "Método não permitido para esta URL.", http.StatusMethodNotAllowed)
-		}
-	})
+	// Leitura do catálogo é pública; escrita exige product:write e importação, product:import
+	products := v1.Group("/products")
+	writeProducts := requirePermission(domain.PermissionProductWrite)
+	importProducts := requirePermission(domain.PermissionProductImport)
+	products.Get("", productHandler.GetProductsHandler)
+	products.Post("", productHandler.CreateProductHandler, auth, writeProducts)
+	products.Get("/{id}", productHandler.GetProductByIDHandler)
+	// Importação em lote; o status do job fica em /v1/imports/{id}, fora de /v1/products/{id}/...
+	products.Post("/import", productHandler.ImportProductsHandler, auth, importProducts)
+	v1.Get("/imports/{id}", productHandler.GetImportJobHandler, auth, importProducts)
+	// Mídias (leitura pública), histórico (autenticado), reversão, atributos personalizados e
+	// gerador de variantes (product:write)
+	products.Get("/{id}/history", productHandler.GetProductHistoryHandler, auth)
+	products.Get("/{id}/media", productHandler.GetProductMediaHandler)
+	products.Post("/{id}/media", productHandler.CreateProductMediaHandler, auth, writeProducts)
+	products.Put("/{id}/media/{media_id}", productHandler.UpdateProductMediaHandler, auth, writeProducts)
+	products.Delete("/{id}/media/{media_id}", productHandler.DeleteProductMediaHandler, auth, writeProducts)
+	products.Post("/{id}/revert", productHandler.RevertProductHandler, auth, writeProducts)
+	products.Put("/{id}/custom-attributes", productHandler.UpdateProductCustomAttributesHandler, auth, writeProducts)
+	products.Post("/{id}/variants/generate", productHandler.GenerateVariantsHandler, auth, writeProducts)
 
 	// --- Rotas de Usuário ---
-	userRoutes := http.NewServeMux()
-	userRoutes.HandleFunc("/v1/register", userHandler.RegisterUserHandler)
-	userRoutes.HandleFunc("/v1/login", userHandler.LoginUserHandler)
+	// Registro, login e renovação de tokens são públicos
+	v1.Post("/register", userHandler.RegisterUserHandler)
+	v1.Post("/login", userHandler.LoginUserHandler)
+	// Segundo passo do login (segundo fator), com o desafio retornado por /v1/login
+	v1.Post("/login/mfa", userHandler.VerifyMFAHandler)
+	v1.Post("/login/mfa/setup", userHandler.StartMFASetupHandler)
+	// Login com o provedor OpenID Connect (alternativa ao login local)
+	v1.Get("/oidc/login", userHandler.StartOIDCLoginHandler)
+	v1.Get("/oidc/callback", userHandler.OIDCCallbackHandler)
+	v1.Post("/token/refresh", userHandler.RefreshTokenHandler)
+	v1.Post("/logout", userHandler.LogoutHandler, auth, sessionOnly)
+	// Primeiro administrador (token de setup) e convites: o aceite é público, a emissão exige admin
+	v1.Post("/setup", userHandler.SetupAdminHandler)
+	v1.Post("/invitations", userHandler.CreateInvitationHandler, auth, adminOnly)
+	v1.Post("/invitations/accept", userHandler.AcceptInvitationHandler)
+	// Redefinição de senha e verificação de e-mail (públicas; os tokens chegam por e-mail)
+	v1.Post("/password/forgot", userHandler.ForgotPasswordHandler)
+	v1.Post("/password/reset", userHandler.ResetPasswordHandler)
+	v1.Get("/verify-email", userHandler.VerifyEmailHandler)
+
+	// Perfil do próprio usuário (qualquer usuário autenticado); senha e segundo fator (TOTP)
+	// exigem o login interativo
+	me := v1.Group("/me", auth)
+	me.Get("", userHandler.GetProfileHandler)
+	me.Put("/password", userHandler.ChangePasswordHandler, sessionOnly)
+	me.Get("/mfa", userHandler.GetMFAStatusHandler, sessionOnly)
+	me.Delete("/mfa", userHandler.DisableMFAHandler, sessionOnly)
+	me.Post("/mfa/enroll", userHandler.EnrollMFAHandler, sessionOnly)
+	me.Post("/mfa/confirm", userHandler.ConfirmMFAHandler, sessionOnly)
+	me.Post("/mfa/recovery-codes", userHandler.RegenerateRecoveryCodesHandler, sessionOnly)
+
+	// Chaves de API: cada usuário gerencia as próprias; admins, as de qualquer usuário.
+	// Criar e revogar chaves exige o login interativo
+	keyRoutes := v1.Group("/api-keys", auth, sessionOnly)
+	keyRoutes.Get("", userHandler.ListAPIKeysHandler)
+	keyRoutes.Post("", userHandler.CreateAPIKeyHandler)
+	keyRoutes.Delete("/{id}", userHandler.RevokeAPIKeyHandler)
+	// Contas de serviço (integrações sem senha): apenas o papel admin
+	v1.Post("/service-accounts", userHandler.CreateServiceAccountHandler, auth, sessionOnly, adminOnly)
+
+	// Gestão de usuários: apenas o papel admin
+	users := v1.Group("/users", auth, adminOnly)
+	users.Get("", userHandler.ListUsersHandler)
+	users.Get("/{id}", userHandler.GetUserHandler)
+	users.Delete("/{id}", userHandler.DeleteUserHandler)
+	users.Put("/{id}/role", roleHandler.AssignUserRoleHandler)
+	users.Get("/{id}/warehouses", warehouseHandler.GetUserWarehousesHandler)
+	users.Put("/{id}/warehouses", warehouseHandler.SetUserWarehousesHandler)
+	users.Post("/{id}/disable", userHandler.DisableUserHandler)
+	users.Post("/{id}/enable", userHandler.EnableUserHandler)
+	users.Get("/{id}/lockout", userHandler.GetUserLockoutHandler)
+	users.Delete("/{id}/lockout", userHandler.ClearUserLockoutHandler)
+	users.Delete("/{id}/mfa", userHandler.ResetUserMFAHandler)
+	// Bloqueios de login por IP (proteção contra força bruta)
+	ipLockouts := v1.Group("/lockouts/ips", auth, adminOnly)
+	ipLockouts.Get("/{ip}", userHandler.GetIPLockoutHandler)
+	ipLockouts.Delete("/{ip}", userHandler.ClearIPLockoutHandler)
 
 	// --- Rotas de Estoque (/v1/stock) ---
-	stockRoutes := http.NewServeMux()
-	stockRoutes.HandleFunc("/v1/stock/update", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodPost {
-			// Apenas administradores podem ajustar o estoque
-			permissionMware := middleware.PermissionMiddleware(domain.RoleAdmin)
-			finalHandler := permissionMware(stockHandler.AdjustStockHandler)
-			authMiddleware(finalHandler).ServeHTTP(w, r)
-		} else {
-			http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
-		}
-	})
+	stockRoutes := v1.Group("/stock", auth)
+	// Ajustes de estoque exigem stock:adjust (ex.: papel warehouse_staff)
+	stockRoutes.Post("/update", stockHandler.AdjustStockHandler, requirePermission(domain.PermissionStockAdjust))
+	// Unidades de medida por variante: leitura autenticada, configuração exige stock:configure
+	stockRoutes.Get("/units/{variant_id}", stockHandler.GetVariantUnitsHandler)
+	stockRoutes.Put("/units/{variant_id}", stockHandler.SetVariantUnitsHandler, requirePermission(domain.PermissionStockConfigure))
 
 	// --- Rotas de Armazéns (/v1/warehouses) ---
-	warehouseRoutes := http.NewServeMux()
-	warehouseRoutes.HandleFunc("/v1/warehouses", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodPost:
-			permissionMware := middleware.PermissionMiddleware(domain.RoleAdmin)
-			finalHandler := permissionMware(warehouseHandler.CreateWarehouseHandler)
-			authMiddleware(finalHandler).ServeHTTP(w, r)
-		case http.MethodGet:
-			warehouseHandler.GetAllWarehousesHandler(w, r)
-		default:
-			http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
-		}
-	})
-	warehouseRoutes.HandleFunc("/v1/warehouses/", func(w http.ResponseWriter, r *http.Request) {
-		// URLs como /v1/warehouses/{id} ou /v1/warehouses/{id}/...
-		// O roteador go padrão não faz extração de parâmetros de path de forma sofisticada.
-		// A extração do ID é feita dentro do próprio handler.
-		switch r.Method {
-		case http.MethodGet:
-			warehouseHandler.GetWarehouseByIDHandler(w, r)
-		case http.MethodPut:
-			permissionMware := middleware.PermissionMiddleware(domain.RoleAdmin)
-			finalHandler := permissionMware(warehouseHandler.UpdateWarehouseHandler)
-			authMiddleware(finalHandler).ServeHTTP(w, r)
-		case http.MethodDelete:
-			permissionMware := middleware.PermissionMiddleware(domain.RoleAdmin)
-			finalHandler := permissionMware(warehouseHandler.DeleteWarehouseHandler)
-			authMiddleware(finalHandler).ServeHTTP(w, r)
-		default:
-			http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
-		}
-	})
-
-	// Aplica o rate limiter
-	mux.Handle("/v1/products", rateLimitMiddleware(productRoutes))
-	mux.Handle("/v1/products/", rateLimitMiddleware(productRoutes))
-	mux.Handle("/v1/register", rateLimitMiddleware(userRoutes))
-	mux.Handle("/v1/login", rateLimitMiddleware(userRoutes))
-	mux.Handle("/v1/stock/", rateLimitMiddleware(stockRoutes))
-	mux.Handle("/v1/warehouses", rateLimitMiddleware(warehouseRoutes))
-	mux.Handle("/v1/warehouses/", rateLimitMiddleware(warehouseRoutes)) // Adicionada rota de armazéns
+	// Leitura é pública; criação, alteração e remoção exigem warehouse:manage
+	warehouses := v1.Group("/warehouses")
+	manageWarehouses := requirePermission(domain.PermissionWarehouseManage)
+	warehouses.Get("", warehouseHandler.GetAllWarehousesHandler)
+	warehouses.Post("", warehouseHandler.CreateWarehouseHandler, auth, manageWarehouses)
+	warehouses.Get("/{id}", warehouseHandler.GetWarehouseByIDHandler)
+	warehouses.Put("/{id}", warehouseHandler.UpdateWarehouseHandler, auth, manageWarehouses)
+	warehouses.Delete("/{id}", warehouseHandler.DeleteWarehouseHandler, auth, manageWarehouses)
+
+	// --- Rotas de Exportação (/v1/export) ---
+	// Exportações expõem o catálogo e o estoque completos: exigem export:read
+	exports := v1.Group("/export", auth, requirePermission(domain.PermissionExportRead))
+	exports.Get("/products", exportHandler.ExportProductsHandler)
+	exports.Get("/stock", exportHandler.ExportStockHandler)
+
+	// --- Rotas de Preços (/v1/price-lists e /v1/prices) ---
+	// Leitura exige autenticação (tabelas B2B não são públicas); escrita exige price:manage.
+	priceLists := v1.Group("/price-lists", auth)
+	managePrices := requirePermission(domain.PermissionPriceManage)
+	priceLists.Get("", priceHandler.GetAllPriceListsHandler)
+	priceLists.Post("", priceHandler.CreatePriceListHandler, managePrices)
+	priceLists.Get("/{id}", priceHandler.GetPriceListByIDHandler)
+	priceLists.Put("/{id}", priceHandler.UpdatePriceListHandler, managePrices)
+	priceLists.Delete("/{id}", priceHandler.DeletePriceListHandler, managePrices)
+	priceLists.Get("/{id}/prices", priceHandler.GetVariantPricesHandler)
+	priceLists.Post("/{id}/prices", priceHandler.CreateVariantPriceHandler, managePrices)
+	priceLists.Delete("/{id}/prices/{price_id}", priceHandler.DeleteVariantPriceHandler, managePrices)
+	v1.Get("/prices/resolve", priceHandler.ResolvePriceHandler, auth)
+
+	// --- Rotas de Códigos de Barras (/v1/barcodes) ---
+	// Leitura (scanners) exige autenticação e usa o scanRateLimit no lugar do limite da v1;
+	// cadastro e remoção de códigos adicionais exigem product:write.
+	barcodes := v1.Group("/barcodes", auth)
+	barcodes.Get("", barcodeHandler.GetVariantBarcodesHandler)
+	barcodes.Post("", barcodeHandler.CreateVariantBarcodeHandler, writeProducts)
+	rt.Group("/v1/barcodes", scanRateLimit, auth).Get("/{code}", barcodeHandler.LookupBarcodeHandler)
+	barcodes.Delete("/{code}", barcodeHandler.DeleteVariantBarcodeHandler, writeProducts)
+
+	// --- Rotas de Definições de Atributos (/v1/attributes) ---
+	// Leitura é pública, como o catálogo; criação, alteração e remoção exigem catalog:manage.
+	attributes := v1.Group("/attributes")
+	manageCatalog := requirePermission(domain.PermissionCatalogManage)
+	attributes.Get("", productHandler.GetAttributeDefinitionsHandler)
+	attributes.Post("", productHandler.CreateAttributeDefinitionHandler, auth, manageCatalog)
+	attributes.Get("/{id}", productHandler.GetAttributeDefinitionByIDHandler)
+	attributes.Put("/{id}", productHandler.UpdateAttributeDefinitionHandler, auth, manageCatalog)
+	attributes.Delete("/{id}", productHandler.DeleteAttributeDefinitionHandler, auth, manageCatalog)
+
+	// --- Rotas de Esquemas de Categoria (/v1/categories) ---
+	// Leitura é pública; gravação e remoção do esquema exigem catalog:manage.
+	categories := v1.Group("/categories")
+	categories.Get("", productHandler.GetCategorySchemasHandler)
+	categories.Get("/{category}/schema", productHandler.GetCategorySchemaHandler)
+	categories.Put("/{category}/schema", productHandler.PutCategorySchemaHandler, auth, manageCatalog)
+	categories.Delete("/{category}/schema", productHandler.DeleteCategorySchemaHandler, auth, manageCatalog)
+
+	// --- Rotas de Papéis e Permissões (/v1/roles, /v1/permissions) ---
+	// A atribuição de papel a um usuário (/v1/users/{id}/role) fica nas rotas de usuário.
+	// Todas exigem o papel admin; os papéis são compartilhados por todas as empresas, então criar,
+	// alterar e remover papéis é restrito à plataforma.
+	admin := v1.Group("", auth, adminOnly)
+	admin.Get("/permissions", roleHandler.GetPermissionsHandler)
+	admin.Get("/roles", roleHandler.GetRolesHandler)
+	admin.Post("/roles", roleHandler.CreateRoleHandler, platformOnly)
+	admin.Get("/roles/{name}", roleHandler.GetRoleHandler)
+	admin.Put("/roles/{name}", roleHandler.UpdateRoleHandler, platformOnly)
+	admin.Delete("/roles/{name}", roleHandler.DeleteRoleHandler, platformOnly)
+
+	// --- Rotas de Empresas (/v1/tenants e /v1/tenant) ---
+	// /v1/tenants cadastra e administra as empresas (plataforma); /v1/tenant é a própria empresa
+	// do administrador autenticado.
+	tenants := admin.Group("/tenants", platformOnly)
+	tenants.Get("", tenantHandler.GetTenantsHandler)
+	tenants.Post("", tenantHandler.CreateTenantHandler)
+	tenants.Get("/{id}", tenantHandler.GetTenantHandler)
+	tenants.Patch("/{id}", tenantHandler.UpdateTenantHandler)
+	admin.Get("/tenant", tenantHandler.GetCurrentTenantHandler)
+	admin.Patch("/tenant", tenantHandler.UpdateCurrentTenantHandler)
+
+	// Chaves públicas de verificação dos JWTs (sem rate limit: consultadas por outros serviços)
+	rt.Handle("GET /.well-known/jwks.json", http.HandlerFunc(jwksHandler.GetJWKSHandler))
 
 	// Rota para o Swagger UI
-	mux.Handle("/swagger/", httpSwagger.Handler(
+	rt.Handle("GET /swagger/", httpSwagger.Handler(
 		httpSwagger.URL("http://localhost:8080/swagger/doc.json"),
 	))
 
-	return mux
+	return rt
 }
@@ -0,0 +1,234 @@
+package router_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"gostock/internal/api/product"
+	"gostock/internal/api/router"
+	"gostock/internal/domain"
+	"gostock/internal/pkg/cache"
+	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/token"
+)
+
+// memoryCache é um cache.Client em memória (sem expiração) para o rate limiter e a lista de revogação.
+type memoryCache struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func (c *memoryCache) Get(ctx context.Context, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.values[key]
+	if !ok {
+		return "", cache.ErrCacheMiss
+	}
+	return value, nil
+}
+
+func (c *memoryCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key] = fmt.Sprint(value)
+	return nil
+}
+
+func (c *memoryCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.values, key)
+	return nil
+}
+
+func (c *memoryCache) Incr(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n, _ := strconv.Atoi(c.values[key])
+	c.values[key] = strconv.Itoa(n + 1)
+	return nil
+}
+
+func (c *memoryCache) GetInt(ctx context.Context, key string) (int, error) {
+	value, err := c.Get(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(value)
+}
+
+// stubProductService registra os métodos chamados; os demais métodos da interface não são usados
+// pelas rotas testadas (chamá-los causa panic pela interface nil embutida).
+type stubProductService struct {
+	product.ProductService
+	mu    sync.Mutex
+	calls []string
+}
+
+func (s *stubProductService) record(call string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, call)
+}
+
+func (s *stubProductService) GetProductByID(ctx domain.Context, id string) (domain.Product, error) {
+	s.record("GetProductByID:" + id)
+	return domain.Product{ID: id}, nil
+}
+
+func (s *stubProductService) GetImportJob(ctx domain.Context, id string) (domain.ImportReport, error) {
+	s.record("GetImportJob:" + id)
+	return domain.ImportReport{JobID: id}, nil
+}
+
+func (s *stubProductService) GetProductHistory(ctx domain.Context, productID string, page, limit int) ([]domain.ProductVersion, error) {
+	s.record("GetProductHistory:" + productID)
+	return []domain.ProductVersion{}, nil
+}
+
+func (s *stubProductService) GetProductMedia(ctx domain.Context, productID string) ([]domain.ProductMedia, error) {
+	s.record("GetProductMedia:" + productID)
+	return []domain.ProductMedia{}, nil
+}
+
+// allowAll concede todas as permissões.
+type allowAll struct{}
+
+func (allowAll) HasPermission(ctx context.Context, role domain.UserRole, permission domain.Permission) (bool, error) {
+	return true, nil
+}
+
+type testRouter struct {
+	handler  http.Handler
+	products *stubProductService
+	token    string
+	requests int
+}
+
+// newTestRouter monta o roteador da aplicação com o serviço de produtos stub. Os handlers que os
+// testes não exercitam ficam nil.
+func newTestRouter(t *testing.T) *testRouter {
+	t.Helper()
+	tokens := token.NewService("segredo", time.Minute)
+	accessToken, err := tokens.GenerateToken("11111111-1111-1111-1111-111111111111", string(domain.RoleAdmin), "", "")
+	if err != nil {
+		t.Fatalf("falha ao gerar token: %v", err)
+	}
+
+	products := &stubProductService{}
+	rt := router.NewRouter(product.NewHandler(products, logger.NewLogger("error")), nil, nil, nil, nil, nil, nil, nil, nil, nil,
+		tokens, nil, allowAll{}, &memoryCache{values: map[string]string{}})
+	return &testRouter{handler: rt, products: products, token: accessToken}
+}
+
+// do executa a requisição; cada uma vem de um IP diferente para não esgotar o rate limit.
+func (tr *testRouter) do(method, target string, authenticated bool, headers map[string]string) *httptest.ResponseRecorder {
+	tr.requests++
+	req := httptest.NewRequest(method, target, nil)
+	req.RemoteAddr = fmt.Sprintf("10.0.0.%d:1234", tr.requests)
+	if authenticated {
+		req.Header.Set("Authorization", "Bearer "+tr.token)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	tr.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// TestRouter_NotFound testa o 404 em JSON para URLs sem rota, nos dois formatos de erro.
+func TestRouter_NotFound(t *testing.T) {
+	tr := newTestRouter(t)
+
+	rec := tr.do(http.MethodGet, "/v1/nao-existe", false, nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var body domain.ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("corpo não é JSON: %v (%s)", err, rec.Body.String())
+	}
+	assert.Equal(t, http.StatusNotFound, body.Code)
+	assert.Equal(t, "NOT_FOUND", body.Category)
+	assert.Contains(t, body.Message, "/v1/nao-existe")
+
+	// O antigo caminho do status de importação não existe mais como rota própria
+	rec = tr.do(http.MethodGet, "/v1/products/import/abc/extra", true, nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = tr.do(http.MethodGet, "/v1/nao-existe", false, map[string]string{"Accept": "application/problem+json"})
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	var problem domain.ProblemDetails
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("corpo não é JSON: %v (%s)", err, rec.Body.String())
+	}
+	assert.Equal(t, http.StatusNotFound, problem.Status)
+	assert.Equal(t, "/v1/nao-existe", problem.Instance)
+}
+
+// TestRouter_MethodNotAllowed testa o 405 em JSON com o header Allow listando os métodos da URL.
+func TestRouter_MethodNotAllowed(t *testing.T) {
+	tr := newTestRouter(t)
+
+	rec := tr.do(http.MethodPatch, "/v1/products/123/media", true, nil)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	allow := rec.Header().Get("Allow")
+	for _, method := range []string{http.MethodGet, http.MethodHead, http.MethodPost} {
+		assert.Contains(t, allow, method)
+	}
+	var body domain.ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("corpo não é JSON: %v (%s)", err, rec.Body.String())
+	}
+	assert.Equal(t, http.StatusMethodNotAllowed, body.Code)
+	assert.Contains(t, body.Message, "PATCH")
+	assert.Empty(t, tr.products.calls)
+}
+
+// TestRouter_ProductSubresources testa que histórico, mídias e status de importação chegam aos
+// seus handlers com os middlewares de cada rota, inclusive nos caminhos que antes colidiam
+// (ex.: /v1/products/import/history).
+func TestRouter_ProductSubresources(t *testing.T) {
+	cases := []struct {
+		name          string
+		target        string
+		authenticated bool
+		status        int
+		call          string
+	}{
+		{"mídias são públicas", "/v1/products/p1/media", false, http.StatusOK, "GetProductMedia:p1"},
+		{"histórico exige autenticação", "/v1/products/p1/history", false, http.StatusUnauthorized, ""},
+		{"histórico autenticado", "/v1/products/p1/history", true, http.StatusOK, "GetProductHistory:p1"},
+		{"histórico de produto com id 'import'", "/v1/products/import/history", true, http.StatusOK, "GetProductHistory:import"},
+		{"status da importação exige autenticação", "/v1/imports/j1", false, http.StatusUnauthorized, ""},
+		{"status da importação", "/v1/imports/j1", true, http.StatusOK, "GetImportJob:j1"},
+		{"produto por id", "/v1/products/import", false, http.StatusOK, "GetProductByID:import"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := newTestRouter(t)
+
+			rec := tr.do(http.MethodGet, tc.target, tc.authenticated, nil)
+
+			assert.Equal(t, tc.status, rec.Code, rec.Body.String())
+			if tc.call == "" {
+				assert.Empty(t, tr.products.calls)
+			} else {
+				assert.Equal(t, []string{tc.call}, tr.products.calls)
+			}
+		})
+	}
+}
@@ -1,17 +1,18 @@
 package stock
 
 import (
-	"encoding/json"
-	"fmt"
 	"gostock/internal/domain"
-	apperror "gostock/internal/errors"
 	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/response"
+	"gostock/internal/pkg/validation"
 	"net/http"
 )
 
 // StockService define o contrato que o Handler espera da camada de Serviço.
 type StockService interface {
 	AdjustStock(ctx domain.Context, adjustment domain.StockAdjustmentRequest) (domain.StockLevel, error)
+	GetVariantUnits(ctx domain.Context, variantID string) (domain.VariantUnits, error)
+	SetVariantUnits(ctx domain.Context, units domain.VariantUnits) (domain.VariantUnits, error)
 }
 
 // Handler agrupa todos os métodos de Handler de estoque.
@@ -28,44 +29,9 @@ func NewHandler(svc StockService, log logger.Logger) *Handler {
 	}
 }
 
-// handleServiceResponse processa erros de serviço e envia respostas padronizadas ao cliente.
-func (h *Handler) handleServiceResponse(w http.ResponseWriter, r *http.Request, data interface{}, err error, successStatus int) {
-	if err == nil {
-		// Sucesso
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(successStatus)
-		if data != nil {
-			if jsonErr := json.NewEncoder(w).Encode(data); jsonErr != nil {
-				h.Logger.Error("Falha ao codificar JSON de resposta", jsonErr)
-				http.Error(w, "Erro ao codificar resposta", http.StatusInternalServerError)
-			}
-		}
-		return
-	}
-
-	// TRATAMENTO DE ERROS
-	status, category, message := apperror.MapToHTTPStatus(err)
-
-	if status >= 500 {
-		h.Logger.Error(fmt.Sprintf("Erro de Servidor: %s", category), err)
-	} else {
-		h.Logger.Debug(fmt.Sprintf("Requisição rejeitada com status %d. Categoria: %s", status, category), map[string]interface{}{"path": r.URL.Path})
-	}
-
-	errorResponse := map[string]interface{}{
-		"code":     status,
-		"category": category,
-		"message":  message,
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(errorResponse)
-}
-
 // AdjustStockHandler lida com a requisição POST /v1/stock/update.
 // @Summary Ajusta o nível de estoque de um produto em um armazém
-// @Description Atualiza a quantidade de estoque para uma variante de produto em um armazém específico.
+// @Description Atualiza a quantidade de estoque para uma variante de produto em um armazém específico. O ajuste pode ser informado em 'delta' (inteiro) ou 'quantity' (decimal), opcionalmente em uma unidade configurada para a variante ('unit', ex.: "cx"); a quantidade é convertida e gravada em unidades-base.
 // @Tags stock
 // @Accept json
 // @Produce json
@@ -77,23 +43,68 @@ func (h *Handler) handleServiceResponse(w http.ResponseWriter, r *http.Request,
 // @Security ApiKeyAuth
 // @Router /stock/update [post]
 func (h *Handler) AdjustStockHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
-		return
-	}
-
 	ctx := r.Context()
 	var adjustmentRequest domain.StockAdjustmentRequest
-	if err := json.NewDecoder(r.Body).Decode(&adjustmentRequest); err != nil {
-		h.handleServiceResponse(w, r, nil, apperror.NewValidationError("Payload inválido. Verifique o formato JSON."), http.StatusBadRequest)
+	if err := validation.DecodeJSON(w, r, &adjustmentRequest); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
 		return
 	}
 
 	stockLevel, err := h.Service.AdjustStock(ctx, adjustmentRequest)
 	if err != nil {
-		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
 		return
 	}
 
-	h.handleServiceResponse(w, r, stockLevel, nil, http.StatusOK) // 200 OK for successful adjustment
+	response.Send(w, r, h.Logger, stockLevel, nil, http.StatusOK) // 200 OK for successful adjustment
+}
+
+// GetVariantUnitsHandler lida com a requisição GET /v1/stock/units/{variant_id}.
+// @Summary Obtém as unidades de medida de uma variante
+// @Description Retorna a unidade-base, a precisão decimal e as conversões (ex.: cx = 12) da variante. Variantes sem configuração usam a unidade discreta "un".
+// @Tags stock
+// @Produce json
+// @Param variant_id path string true "ID da variante"
+// @Success 200 {object} domain.VariantUnits "Unidades de medida"
+// @Failure 400 {object} domain.ErrorResponse "ID inválido"
+// @Security ApiKeyAuth
+// @Router /stock/units/{variant_id} [get]
+func (h *Handler) GetVariantUnitsHandler(w http.ResponseWriter, r *http.Request) {
+	variantID := r.PathValue("variant_id")
+
+	units, err := h.Service.GetVariantUnits(r.Context(), variantID)
+	if err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+	response.Send(w, r, h.Logger, units, nil, http.StatusOK)
+}
+
+// SetVariantUnitsHandler lida com a requisição PUT /v1/stock/units/{variant_id}.
+// @Summary Configura as unidades de medida de uma variante
+// @Description Define a unidade-base, a precisão ('decimal_places' > 0 para variantes fracionárias, como kg ou litros) e as conversões em unidades-base (ex.: {"unit": "cx", "factor": "12"}). Substitui a configuração anterior. A precisão não pode ser alterada se a variante tiver estoque.
+// @Tags stock
+// @Accept json
+// @Produce json
+// @Param variant_id path string true "ID da variante"
+// @Param units body domain.VariantUnits true "Unidades de medida"
+// @Success 200 {object} domain.VariantUnits "Unidades de medida configuradas"
+// @Failure 400 {object} domain.ErrorResponse "Payload inválido"
+// @Failure 409 {object} domain.ErrorResponse "Precisão alterada com estoque existente"
+// @Security ApiKeyAuth
+// @Router /stock/units/{variant_id} [put]
+func (h *Handler) SetVariantUnitsHandler(w http.ResponseWriter, r *http.Request) {
+	var units domain.VariantUnits
+	if err := validation.DecodeJSON(w, r, &units); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+	units.VariantID = r.PathValue("variant_id") // O ID da URL prevalece
+
+	saved, err := h.Service.SetVariantUnits(r.Context(), units)
+	if err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+	response.Send(w, r, h.Logger, saved, nil, http.StatusOK)
 }
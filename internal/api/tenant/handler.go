@@ -0,0 +1,152 @@
+package tenant
+
+import (
+	"net/http"
+
+	"gostock/internal/domain"
+	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/response"
+	"gostock/internal/pkg/validation"
+)
+
+// TenantService define o contrato que o Handler espera da camada de Serviço.
+type TenantService interface {
+	GetTenants(ctx domain.Context) ([]domain.Tenant, error)
+	GetTenant(ctx domain.Context, id string) (domain.Tenant, error)
+	CreateTenant(ctx domain.Context, req domain.TenantCreate) (domain.Tenant, error)
+	UpdateTenant(ctx domain.Context, id string, req domain.TenantUpdate) (domain.Tenant, error)
+	GetCurrentTenant(ctx domain.Context) (domain.Tenant, error)
+	UpdateCurrentTenant(ctx domain.Context, req domain.TenantUpdate) (domain.Tenant, error)
+}
+
+// Handler agrupa os métodos de Handler de empresas (tenants).
+type Handler struct {
+	Service TenantService
+	Logger  logger.Logger
+}
+
+// NewHandler cria uma nova instância do Handler, injetando o Service e o Logger.
+func NewHandler(svc TenantService, log logger.Logger) *Handler {
+	return &Handler{
+		Service: svc,
+		Logger:  log,
+	}
+}
+
+// GetTenantsHandler lida com a requisição GET /v1/tenants.
+// @Summary Lista as empresas
+// @Description Retorna todas as empresas atendidas pela instalação. Restrito aos administradores da plataforma (tenant padrão).
+// @Tags tenants
+// @Produce json
+// @Success 200 {array} domain.Tenant "Empresas"
+// @Failure 403 {object} domain.ErrorResponse "Apenas administradores da plataforma"
+// @Failure 500 {object} domain.ErrorResponse "Erro interno do servidor"
+// @Security ApiKeyAuth
+// @Router /tenants [get]
+func (h *Handler) GetTenantsHandler(w http.ResponseWriter, r *http.Request) {
+	tenants, err := h.Service.GetTenants(r.Context())
+	response.Send(w, r, h.Logger, tenants, err, http.StatusOK)
+}
+
+// GetTenantHandler lida com a requisição GET /v1/tenants/{id}.
+// @Summary Obtém uma empresa
+// @Tags tenants
+// @Produce json
+// @Param id path string true "ID da empresa"
+// @Success 200 {object} domain.Tenant "Empresa"
+// @Failure 400 {object} domain.ErrorResponse "ID inválido"
+// @Failure 403 {object} domain.ErrorResponse "Apenas administradores da plataforma"
+// @Failure 404 {object} domain.ErrorResponse "Empresa não encontrada"
+// @Security ApiKeyAuth
+// @Router /tenants/{id} [get]
+func (h *Handler) GetTenantHandler(w http.ResponseWriter, r *http.Request) {
+	found, err := h.Service.GetTenant(r.Context(), r.PathValue("id"))
+	response.Send(w, r, h.Logger, found, err, http.StatusOK)
+}
+
+// CreateTenantHandler lida com a requisição POST /v1/tenants.
+// @Summary Cria uma empresa
+// @Description Cadastra uma empresa. O primeiro administrador é convidado por POST /v1/invitations com o header X-Tenant da nova empresa.
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Param tenant body domain.TenantCreate true "Identificador, nome e configurações"
+// @Success 201 {object} domain.Tenant "Empresa criada"
+// @Failure 400 {object} domain.ErrorResponse "Identificador ou configurações inválidos"
+// @Failure 403 {object} domain.ErrorResponse "Apenas administradores da plataforma"
+// @Failure 409 {object} domain.ErrorResponse "Identificador já em uso"
+// @Security ApiKeyAuth
+// @Router /tenants [post]
+func (h *Handler) CreateTenantHandler(w http.ResponseWriter, r *http.Request) {
+	var req domain.TenantCreate
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+
+	created, err := h.Service.CreateTenant(r.Context(), req)
+	response.Send(w, r, h.Logger, created, err, http.StatusCreated)
+}
+
+// UpdateTenantHandler lida com a requisição PATCH /v1/tenants/{id}.
+// @Summary Altera uma empresa
+// @Description Altera o nome, as configurações ou a ativação da empresa. Empresas desativadas não fazem login nem renovam tokens.
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Param id path string true "ID da empresa"
+// @Param tenant body domain.TenantUpdate true "Campos alterados"
+// @Success 200 {object} domain.Tenant "Empresa alterada"
+// @Failure 400 {object} domain.ErrorResponse "Configurações inválidas"
+// @Failure 403 {object} domain.ErrorResponse "Apenas administradores da plataforma"
+// @Failure 404 {object} domain.ErrorResponse "Empresa não encontrada"
+// @Failure 409 {object} domain.ErrorResponse "Desativação da empresa padrão"
+// @Security ApiKeyAuth
+// @Router /tenants/{id} [patch]
+func (h *Handler) UpdateTenantHandler(w http.ResponseWriter, r *http.Request) {
+	var req domain.TenantUpdate
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+
+	updated, err := h.Service.UpdateTenant(r.Context(), r.PathValue("id"), req)
+	response.Send(w, r, h.Logger, updated, err, http.StatusOK)
+}
+
+// GetCurrentTenantHandler lida com a requisição GET /v1/tenant.
+// @Summary Obtém a própria empresa
+// @Description Retorna a empresa e as configurações do administrador autenticado.
+// @Tags tenants
+// @Produce json
+// @Success 200 {object} domain.Tenant "Empresa"
+// @Failure 403 {object} domain.ErrorResponse "Apenas administradores"
+// @Security ApiKeyAuth
+// @Router /tenant [get]
+func (h *Handler) GetCurrentTenantHandler(w http.ResponseWriter, r *http.Request) {
+	found, err := h.Service.GetCurrentTenant(r.Context())
+	response.Send(w, r, h.Logger, found, err, http.StatusOK)
+}
+
+// UpdateCurrentTenantHandler lida com a requisição PATCH /v1/tenant.
+// @Summary Altera a própria empresa
+// @Description Altera o nome e as configurações da empresa do administrador autenticado. A ativação é exclusiva da plataforma.
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Param tenant body domain.TenantUpdate true "Nome e configurações"
+// @Success 200 {object} domain.Tenant "Empresa alterada"
+// @Failure 400 {object} domain.ErrorResponse "Configurações inválidas"
+// @Failure 403 {object} domain.ErrorResponse "Apenas administradores ou alteração da ativação"
+// @Security ApiKeyAuth
+// @Router /tenant [patch]
+func (h *Handler) UpdateCurrentTenantHandler(w http.ResponseWriter, r *http.Request) {
+	var req domain.TenantUpdate
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+
+	updated, err := h.Service.UpdateCurrentTenant(r.Context(), req)
+	response.Send(w, r, h.Logger, updated, err, http.StatusOK)
+}
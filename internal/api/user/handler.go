@@ -2,24 +2,60 @@ package user
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/subtle"
 	"net/http"
+	"strconv"
+	"time"
 
 	"gostock/internal/domain"
 	apperror "gostock/internal/errors"
 	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/middleware"
+	"gostock/internal/pkg/response"
+	"gostock/internal/pkg/validation"
 )
 
-// UserService define o contrato para as operações de registro e login.
+// UserService define o contrato para as operações de registro, login e sessão.
 type UserService interface {
 	Register(ctx context.Context, registration domain.UserRegistration) (domain.User, error)
-	Login(ctx context.Context, email string, password string) (string, error)
+	Login(ctx context.Context, email string, password string) (domain.AuthTokens, error)
+	RefreshTokens(ctx context.Context, refreshToken string) (domain.AuthTokens, error)
+	Logout(ctx context.Context, userID, tokenID, sessionID string, expiresAt time.Time) error
+	ListUsers(ctx context.Context, page, limit int) (domain.UserList, error)
+	GetUser(ctx context.Context, id string) (domain.User, error)
+	SetUserDisabled(ctx context.Context, id string, disabled bool) (domain.User, error)
+	DeleteUser(ctx context.Context, id string) error
+	ChangePassword(ctx context.Context, userID string, change domain.PasswordChange) error
+	SetupAdmin(ctx context.Context, setup domain.AdminSetup) (domain.User, error)
+	InviteUser(ctx context.Context, request domain.InvitationRequest) (domain.Invitation, error)
+	AcceptInvitation(ctx context.Context, acceptance domain.InvitationAcceptance) (domain.User, error)
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, reset domain.PasswordReset) error
+	VerifyEmail(ctx context.Context, token string) error
+	GetUserLockout(ctx context.Context, id string) (domain.LoginLockout, error)
+	ClearUserLockout(ctx context.Context, id string) error
+	GetIPLockout(ctx context.Context, ip string) (domain.LoginLockout, error)
+	ClearIPLockout(ctx context.Context, ip string) error
+	VerifyMFA(ctx context.Context, verification domain.MFAVerification) (domain.AuthTokens, error)
+	StartMFASetup(ctx context.Context, challenge string) (domain.MFAEnrollment, error)
+	GetMFAStatus(ctx context.Context, userID string) (domain.MFAStatus, error)
+	EnrollMFA(ctx context.Context, userID string) (domain.MFAEnrollment, error)
+	ConfirmMFA(ctx context.Context, userID, code string) (domain.RecoveryCodes, error)
+	DisableMFA(ctx context.Context, userID string, request domain.MFADisable) error
+	RegenerateRecoveryCodes(ctx context.Context, userID, code string) (domain.RecoveryCodes, error)
+	ResetUserMFA(ctx context.Context, id string) error
+	CreateServiceAccount(ctx context.Context, request domain.ServiceAccountCreate) (domain.User, error)
+	CreateAPIKey(ctx context.Context, request domain.APIKeyCreate) (domain.APIKeyCreated, error)
+	ListAPIKeys(ctx context.Context, userID string) ([]domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) error
+	StartOIDCLogin(ctx context.Context) (domain.OIDCAuthorization, error)
+	CompleteOIDCLogin(ctx context.Context, callback domain.OIDCCallback) (domain.AuthTokens, error)
 }
 
 // LoginRequest representa o payload de entrada para o login.
 type LoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
 }
 
 // Handler agrupa todos os métodos de Handler do usuário.
@@ -36,44 +72,9 @@ func NewHandler(svc UserService, log logger.Logger) *Handler {
 	}
 }
 
-// handleServiceResponse é uma função auxiliar (similar à que você já tem em product/handler.go)
-// para padronizar o tratamento de erros e respostas HTTP.
-func (h *Handler) handleServiceResponse(w http.ResponseWriter, r *http.Request, data interface{}, err error, successStatus int) {
-	// Função auxiliar completa deve ser implementada para evitar repetição.
-	// Por brevidade, assumimos que ela está presente ou que usaremos o método do ProductHandler
-	// se estiver em um pacote utilitário. Aqui, faremos o essencial:
-
-	if err == nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(successStatus)
-		if data != nil {
-			json.NewEncoder(w).Encode(data)
-		}
-		return
-	}
-
-	// Mapeamento de Erros de Negócio para Status HTTP
-	status, category, message := apperror.MapToHTTPStatus(err)
-
-	// Log apenas de erros graves
-	if status >= 500 {
-		h.Logger.Error("Erro interno no serviço de usuário:", err)
-	}
-
-	errorResponse := map[string]interface{}{
-		"code":     status,
-		"category": category,
-		"message":  message,
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(errorResponse)
-}
-
 // RegisterUserHandler lida com a requisição POST /v1/register.
 // @Summary Registra um novo usuário
-// @Description Cria um novo usuário, hasheia a senha e salva no banco de dados.
+// @Description Cria um novo usuário com o papel padrão ("user"), hasheia a senha e salva no banco de dados. Não é possível escolher o papel no registro; outros papéis são concedidos por um administrador (atribuição ou convite).
 // @Tags users
 // @Accept json
 // @Produce json
@@ -84,16 +85,11 @@ func (h *Handler) handleServiceResponse(w http.ResponseWriter, r *http.Request,
 // @Failure 500 {object} domain.ErrorResponse "Erro interno do servidor"
 // @Router /register [post]
 func (h *Handler) RegisterUserHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
-		return
-	}
-
 	ctx := r.Context()
 
 	var reg domain.UserRegistration
-	if err := json.NewDecoder(r.Body).Decode(&reg); err != nil {
-		h.handleServiceResponse(w, r, nil, apperror.NewValidationError("Payload JSON inválido."), http.StatusCreated)
+	if err := validation.DecodeJSON(w, r, &reg); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
 		return
 	}
 
@@ -101,57 +97,775 @@ func (h *Handler) RegisterUserHandler(w http.ResponseWriter, r *http.Request) {
 	newUser, err := h.Service.Register(ctx, reg)
 
 	if err != nil {
-		// Se o serviço falhar, o handleServiceResponse traduzirá o erro.
+		// Se o serviço falhar, o response.Send traduzirá o erro.
 		// Ex: ConflictError (e-mail duplicado) -> 409
 		// Ex: ValidationError -> 400
-		h.handleServiceResponse(w, r, nil, err, http.StatusCreated)
+		response.Send(w, r, h.Logger, nil, err, http.StatusCreated)
 		return
 	}
 
 	// 2. Resposta de Sucesso (201 Created)
 	// O objeto newUser retornado pelo serviço já tem o PasswordHash limpo,
 	// pois a struct domain.User usa a tag `json:"-"`.
-	h.handleServiceResponse(w, r, newUser, nil, http.StatusCreated)
+	response.Send(w, r, h.Logger, newUser, nil, http.StatusCreated)
 }
 
 // ... (abaixo do RegisterUserHandler) ...
 
 // LoginUserHandler lida com a requisição POST /v1/login.
-// @Summary Autentica um usuário e retorna um JWT
-// @Description Recebe email/senha, verifica a validade e emite um JSON Web Token.
+// @Summary Autentica um usuário e retorna os tokens da sessão
+// @Description Recebe email/senha, verifica a validade e emite um access token (JWT de curta duração) e um refresh token. O campo token repete o access token para clientes antigos.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param login body LoginRequest true "Credenciais do usuário (email e senha)"
-// @Success 200 {object} map[string]string "Token JWT emitido"
+// @Success 200 {object} domain.AuthTokens "Tokens emitidos ou, com o segundo fator, o desafio (mfa_required e mfa_token)"
 // @Failure 400 {object} domain.ErrorResponse "Payload inválido"
 // @Failure 401 {object} domain.ErrorResponse "Credenciais inválidas"
+// @Failure 429 {object} domain.ErrorResponse "Muitas tentativas: conta ou IP bloqueados temporariamente (ver Retry-After)"
 // @Failure 500 {object} domain.ErrorResponse "Erro interno do servidor"
 // @Router /login [post]
 func (h *Handler) LoginUserHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+	// O IP de origem entra na contagem de falhas de login (proteção contra força bruta)
+	ctx := middleware.WithClientIP(r.Context(), middleware.ClientIP(r))
+
+	var loginReq LoginRequest
+	if err := validation.DecodeJSON(w, r, &loginReq); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
 		return
 	}
 
-	ctx := r.Context()
+	// 1. Chamar o Serviço de Login
+	tokens, err := h.Service.Login(ctx, loginReq.Email, loginReq.Password)
 
-	var loginReq LoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&loginReq); err != nil {
-		h.handleServiceResponse(w, r, nil, apperror.NewValidationError("Payload JSON inválido."), http.StatusOK) // Usando 200/StatusOK temporariamente
+	if err != nil {
+		// O response.Send traduz 401 Unauthorized, 400 Validation, 500 Internal
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
 		return
 	}
 
-	// 1. Chamar o Serviço de Login
-	token, err := h.Service.Login(ctx, loginReq.Email, loginReq.Password)
+	// 2. Resposta de Sucesso (200 OK com os Tokens)
+	response.Send(w, r, h.Logger, tokens, nil, http.StatusOK)
+}
+
+// RefreshTokenHandler lida com a requisição POST /v1/token/refresh.
+// @Summary Renova os tokens da sessão
+// @Description Troca um refresh token por um novo access token e um novo refresh token. Cada refresh token só pode ser usado uma vez; reapresentar um token já trocado revoga toda a sessão.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param refresh body domain.RefreshTokenRequest true "Refresh token recebido no login ou na última renovação"
+// @Success 200 {object} domain.AuthTokens "Novos tokens"
+// @Failure 400 {object} domain.ErrorResponse "Payload inválido"
+// @Failure 401 {object} domain.ErrorResponse "Refresh token inválido, expirado, revogado ou reutilizado"
+// @Failure 500 {object} domain.ErrorResponse "Erro interno do servidor"
+// @Router /token/refresh [post]
+func (h *Handler) RefreshTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var req domain.RefreshTokenRequest
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+
+	tokens, err := h.Service.RefreshTokens(r.Context(), req.RefreshToken)
+	response.Send(w, r, h.Logger, tokens, err, http.StatusOK)
+}
+
+// LogoutHandler lida com a requisição POST /v1/logout.
+// @Summary Encerra a sessão
+// @Description Revoga o access token usado na requisição e todos os refresh tokens da sessão.
+// @Tags users
+// @Security ApiKeyAuth
+// @Success 204 "Sessão encerrada"
+// @Failure 401 {object} domain.ErrorResponse "Token ausente, inválido ou já revogado"
+// @Failure 500 {object} domain.ErrorResponse "Erro interno do servidor"
+// @Router /logout [post]
+func (h *Handler) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserClaimsFromContext(r.Context())
+	if !ok {
+		response.Send(w, r, h.Logger, nil, apperror.NewUnauthorizedError("Autorização necessária."), http.StatusNoContent)
+		return
+	}
+
+	err := h.Service.Logout(r.Context(), claims.UserID, claims.TokenID, claims.SessionID, claims.ExpiresAt)
+	response.Send(w, r, h.Logger, nil, err, http.StatusNoContent)
+}
+
+// SetupAdminHandler lida com a requisição POST /v1/setup.
+// @Summary Cria o primeiro administrador
+// @Description Cria uma conta admin com o token de setup (SETUP_TOKEN). Aceito apenas enquanto não existir nenhum administrador.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param setup body domain.AdminSetup true "Token de setup e credenciais do administrador"
+// @Success 201 {object} domain.User "Administrador criado"
+// @Failure 400 {object} domain.ErrorResponse "Payload inválido"
+// @Failure 401 {object} domain.ErrorResponse "Token de setup inválido"
+// @Failure 404 {object} domain.ErrorResponse "Setup desativado"
+// @Failure 409 {object} domain.ErrorResponse "Já existe um administrador"
+// @Router /setup [post]
+func (h *Handler) SetupAdminHandler(w http.ResponseWriter, r *http.Request) {
+	var setup domain.AdminSetup
+	if err := validation.DecodeJSON(w, r, &setup); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+
+	admin, err := h.Service.SetupAdmin(r.Context(), setup)
+	response.Send(w, r, h.Logger, admin, err, http.StatusCreated)
+}
+
+// CreateInvitationHandler lida com a requisição POST /v1/invitations.
+// @Summary Convida um usuário
+// @Description Emite um convite assinado para o e-mail, com o papel da conta a ser criada (padrão "user"). O convite expira após INVITATION_EXPIRY_HOURS.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param invitation body domain.InvitationRequest true "E-mail e papel do convidado"
+// @Success 201 {object} domain.Invitation "Convite emitido"
+// @Failure 400 {object} domain.ErrorResponse "E-mail inválido"
+// @Failure 404 {object} domain.ErrorResponse "Papel não encontrado"
+// @Failure 409 {object} domain.ErrorResponse "E-mail já cadastrado"
+// @Security ApiKeyAuth
+// @Router /invitations [post]
+func (h *Handler) CreateInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	var req domain.InvitationRequest
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+
+	invitation, err := h.Service.InviteUser(r.Context(), req)
+	response.Send(w, r, h.Logger, invitation, err, http.StatusCreated)
+}
+
+// AcceptInvitationHandler lida com a requisição POST /v1/invitations/accept.
+// @Summary Aceita um convite
+// @Description Cria a conta do convite com a senha informada. O token pode vir no corpo ou na query (?token=, como no link do convite).
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param token query string false "Token do convite"
+// @Param acceptance body domain.InvitationAcceptance true "Token do convite e senha"
+// @Success 201 {object} domain.User "Conta criada"
+// @Failure 400 {object} domain.ErrorResponse "Payload inválido"
+// @Failure 401 {object} domain.ErrorResponse "Convite inválido ou expirado"
+// @Failure 409 {object} domain.ErrorResponse "Convite já aceito (e-mail em uso)"
+// @Router /invitations/accept [post]
+func (h *Handler) AcceptInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	var acceptance domain.InvitationAcceptance
+	if err := validation.DecodeJSON(w, r, &acceptance); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+	if acceptance.Token == "" {
+		acceptance.Token = r.URL.Query().Get("token")
+	}
+
+	created, err := h.Service.AcceptInvitation(r.Context(), acceptance)
+	response.Send(w, r, h.Logger, created, err, http.StatusCreated)
+}
+
+// ListUsersHandler lida com a requisição GET /v1/users.
+// @Summary Lista os usuários
+// @Description Lista os usuários cadastrados, ordenados por e-mail, com paginação.
+// @Tags users
+// @Produce json
+// @Param page query int false "Número da página" default(1)
+// @Param limit query int false "Itens por página (máximo 100)" default(10)
+// @Success 200 {object} domain.UserList "Página de usuários"
+// @Failure 400 {object} domain.ErrorResponse "Parâmetros de paginação inválidos"
+// @Failure 403 {object} domain.ErrorResponse "Requer o papel admin"
+// @Security ApiKeyAuth
+// @Router /users [get]
+func (h *Handler) ListUsersHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	page, err := parsePositiveInt(query.Get("page"), 1)
+	if err != nil {
+		response.Send(w, r, h.Logger, nil, apperror.NewValidationError("Parâmetro 'page' inválido."), http.StatusBadRequest)
+		return
+	}
+	limit, err := parsePositiveInt(query.Get("limit"), 10)
+	if err != nil {
+		response.Send(w, r, h.Logger, nil, apperror.NewValidationError("Parâmetro 'limit' inválido."), http.StatusBadRequest)
+		return
+	}
+	if limit > 100 { // Limite máximo para evitar sobrecarga
+		limit = 100
+	}
+
+	users, err := h.Service.ListUsers(r.Context(), page, limit)
+	response.Send(w, r, h.Logger, users, err, http.StatusOK)
+}
+
+// GetUserHandler lida com a requisição GET /v1/users/{id}.
+// @Summary Obtém um usuário
+// @Tags users
+// @Produce json
+// @Param id path string true "ID do usuário"
+// @Success 200 {object} domain.User "Usuário"
+// @Failure 400 {object} domain.ErrorResponse "ID inválido"
+// @Failure 404 {object} domain.ErrorResponse "Usuário não encontrado"
+// @Security ApiKeyAuth
+// @Router /users/{id} [get]
+func (h *Handler) GetUserHandler(w http.ResponseWriter, r *http.Request) {
+	found, err := h.Service.GetUser(r.Context(), r.PathValue("id"))
+	response.Send(w, r, h.Logger, found, err, http.StatusOK)
+}
+
+// DeleteUserHandler lida com a requisição DELETE /v1/users/{id}.
+// @Summary Remove um usuário
+// @Description Remove o usuário e invalida imediatamente os tokens emitidos para ele.
+// @Tags users
+// @Param id path string true "ID do usuário"
+// @Success 204 "Usuário removido"
+// @Failure 400 {object} domain.ErrorResponse "ID inválido"
+// @Failure 404 {object} domain.ErrorResponse "Usuário não encontrado"
+// @Failure 409 {object} domain.ErrorResponse "Remoção da própria conta"
+// @Security ApiKeyAuth
+// @Router /users/{id} [delete]
+func (h *Handler) DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
+	err := h.Service.DeleteUser(r.Context(), r.PathValue("id"))
+	response.Send(w, r, h.Logger, nil, err, http.StatusNoContent)
+}
+
+// DisableUserHandler lida com a requisição POST /v1/users/{id}/disable.
+// @Summary Desativa um usuário
+// @Description Desativa a conta: o login passa a ser recusado e os tokens já emitidos deixam de valer imediatamente.
+// @Tags users
+// @Produce json
+// @Param id path string true "ID do usuário"
+// @Success 200 {object} domain.User "Usuário desativado"
+// @Failure 400 {object} domain.ErrorResponse "ID inválido"
+// @Failure 404 {object} domain.ErrorResponse "Usuário não encontrado"
+// @Failure 409 {object} domain.ErrorResponse "Desativação da própria conta"
+// @Security ApiKeyAuth
+// @Router /users/{id}/disable [post]
+func (h *Handler) DisableUserHandler(w http.ResponseWriter, r *http.Request) {
+	updated, err := h.Service.SetUserDisabled(r.Context(), r.PathValue("id"), true)
+	response.Send(w, r, h.Logger, updated, err, http.StatusOK)
+}
+
+// EnableUserHandler lida com a requisição POST /v1/users/{id}/enable.
+// @Summary Reativa um usuário
+// @Description Reativa a conta; o usuário precisa fazer login novamente.
+// @Tags users
+// @Produce json
+// @Param id path string true "ID do usuário"
+// @Success 200 {object} domain.User "Usuário reativado"
+// @Failure 400 {object} domain.ErrorResponse "ID inválido"
+// @Failure 404 {object} domain.ErrorResponse "Usuário não encontrado"
+// @Security ApiKeyAuth
+// @Router /users/{id}/enable [post]
+func (h *Handler) EnableUserHandler(w http.ResponseWriter, r *http.Request) {
+	updated, err := h.Service.SetUserDisabled(r.Context(), r.PathValue("id"), false)
+	response.Send(w, r, h.Logger, updated, err, http.StatusOK)
+}
+
+// GetUserLockoutHandler lida com a requisição GET /v1/users/{id}/lockout.
+// @Summary Consulta o bloqueio de login da conta
+// @Description Retorna as falhas de login recentes da conta e o bloqueio ativo, se houver.
+// @Tags users
+// @Produce json
+// @Param id path string true "ID do usuário"
+// @Success 200 {object} domain.LoginLockout "Situação da conta"
+// @Failure 400 {object} domain.ErrorResponse "ID inválido"
+// @Failure 404 {object} domain.ErrorResponse "Usuário não encontrado"
+// @Security ApiKeyAuth
+// @Router /users/{id}/lockout [get]
+func (h *Handler) GetUserLockoutHandler(w http.ResponseWriter, r *http.Request) {
+	lockout, err := h.Service.GetUserLockout(r.Context(), r.PathValue("id"))
+	response.Send(w, r, h.Logger, lockout, err, http.StatusOK)
+}
+
+// ClearUserLockoutHandler lida com a requisição DELETE /v1/users/{id}/lockout.
+// @Summary Desbloqueia o login da conta
+// @Description Remove o bloqueio e zera as falhas de login da conta.
+// @Tags users
+// @Param id path string true "ID do usuário"
+// @Success 204 "Bloqueio removido"
+// @Failure 400 {object} domain.ErrorResponse "ID inválido"
+// @Failure 404 {object} domain.ErrorResponse "Usuário não encontrado"
+// @Security ApiKeyAuth
+// @Router /users/{id}/lockout [delete]
+func (h *Handler) ClearUserLockoutHandler(w http.ResponseWriter, r *http.Request) {
+	err := h.Service.ClearUserLockout(r.Context(), r.PathValue("id"))
+	response.Send(w, r, h.Logger, nil, err, http.StatusNoContent)
+}
+
+// GetIPLockoutHandler lida com a requisição GET /v1/lockouts/ips/{ip}.
+// @Summary Consulta o bloqueio de login de um IP
+// @Description Retorna as falhas de login recentes a partir do IP e o bloqueio ativo, se houver.
+// @Tags users
+// @Produce json
+// @Param ip path string true "Endereço IP"
+// @Success 200 {object} domain.LoginLockout "Situação do IP"
+// @Failure 400 {object} domain.ErrorResponse "IP inválido"
+// @Security ApiKeyAuth
+// @Router /lockouts/ips/{ip} [get]
+func (h *Handler) GetIPLockoutHandler(w http.ResponseWriter, r *http.Request) {
+	lockout, err := h.Service.GetIPLockout(r.Context(), r.PathValue("ip"))
+	response.Send(w, r, h.Logger, lockout, err, http.StatusOK)
+}
+
+// ClearIPLockoutHandler lida com a requisição DELETE /v1/lockouts/ips/{ip}.
+// @Summary Desbloqueia o login de um IP
+// @Description Remove o bloqueio e zera as falhas de login do IP.
+// @Tags users
+// @Param ip path string true "Endereço IP"
+// @Success 204 "Bloqueio removido"
+// @Failure 400 {object} domain.ErrorResponse "IP inválido"
+// @Security ApiKeyAuth
+// @Router /lockouts/ips/{ip} [delete]
+func (h *Handler) ClearIPLockoutHandler(w http.ResponseWriter, r *http.Request) {
+	err := h.Service.ClearIPLockout(r.Context(), r.PathValue("ip"))
+	response.Send(w, r, h.Logger, nil, err, http.StatusNoContent)
+}
+
+// VerifyMFAHandler lida com a requisição POST /v1/login/mfa.
+// @Summary Conclui o login com o segundo fator
+// @Description Troca o desafio retornado pelo login (mfa_token) e um código do autenticador, ou um código de recuperação, pelos tokens da sessão. Com um desafio de cadastro (mfa_setup_required), o código confirma o autenticador registrado em /login/mfa/setup e a resposta traz também os códigos de recuperação.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param verification body domain.MFAVerification true "Desafio e código"
+// @Success 200 {object} domain.AuthTokens "Tokens emitidos"
+// @Failure 400 {object} domain.ErrorResponse "Payload inválido"
+// @Failure 401 {object} domain.ErrorResponse "Desafio inválido ou expirado, ou código incorreto"
+// @Failure 429 {object} domain.ErrorResponse "Muitas tentativas (ver Retry-After)"
+// @Router /login/mfa [post]
+func (h *Handler) VerifyMFAHandler(w http.ResponseWriter, r *http.Request) {
+	var verification domain.MFAVerification
+	if err := validation.DecodeJSON(w, r, &verification); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+
+	// Códigos errados contam como falhas de login, por conta e por IP
+	ctx := middleware.WithClientIP(r.Context(), middleware.ClientIP(r))
+	tokens, err := h.Service.VerifyMFA(ctx, verification)
+	response.Send(w, r, h.Logger, tokens, err, http.StatusOK)
+}
+
+// StartMFASetupHandler lida com a requisição POST /v1/login/mfa/setup.
+// @Summary Cadastra o segundo fator durante o login
+// @Description Para papéis que exigem o segundo fator: com o desafio de cadastro retornado pelo login, gera o segredo TOTP e a URI otpauth:// do QR code. Conclua em /login/mfa com o primeiro código.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param challenge body domain.MFAChallengeToken true "Desafio de cadastro"
+// @Success 200 {object} domain.MFAEnrollment "Segredo e URI de provisionamento"
+// @Failure 401 {object} domain.ErrorResponse "Desafio inválido ou expirado"
+// @Router /login/mfa/setup [post]
+func (h *Handler) StartMFASetupHandler(w http.ResponseWriter, r *http.Request) {
+	var challenge domain.MFAChallengeToken
+	if err := validation.DecodeJSON(w, r, &challenge); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+
+	enrollment, err := h.Service.StartMFASetup(r.Context(), challenge.MFAToken)
+	response.Send(w, r, h.Logger, enrollment, err, http.StatusOK)
+}
 
+// GetMFAStatusHandler lida com a requisição GET /v1/me/mfa.
+// @Summary Situação do segundo fator
+// @Tags users
+// @Produce json
+// @Success 200 {object} domain.MFAStatus "Situação do segundo fator"
+// @Security ApiKeyAuth
+// @Router /me/mfa [get]
+func (h *Handler) GetMFAStatusHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserClaimsFromContext(r.Context())
+	if !ok {
+		response.Send(w, r, h.Logger, nil, apperror.NewUnauthorizedError("Autorização necessária."), http.StatusOK)
+		return
+	}
+
+	status, err := h.Service.GetMFAStatus(r.Context(), claims.UserID)
+	response.Send(w, r, h.Logger, status, err, http.StatusOK)
+}
+
+// EnrollMFAHandler lida com a requisição POST /v1/me/mfa/enroll.
+// @Summary Inicia o cadastro do segundo fator
+// @Description Gera um segredo TOTP e a URI otpauth:// para o QR code do aplicativo autenticador. O segundo fator só é ativado após a confirmação com o primeiro código (/me/mfa/confirm).
+// @Tags users
+// @Produce json
+// @Success 200 {object} domain.MFAEnrollment "Segredo e URI de provisionamento"
+// @Failure 409 {object} domain.ErrorResponse "Segundo fator já ativo"
+// @Security ApiKeyAuth
+// @Router /me/mfa/enroll [post]
+func (h *Handler) EnrollMFAHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserClaimsFromContext(r.Context())
+	if !ok {
+		response.Send(w, r, h.Logger, nil, apperror.NewUnauthorizedError("Autorização necessária."), http.StatusOK)
+		return
+	}
+
+	enrollment, err := h.Service.EnrollMFA(r.Context(), claims.UserID)
+	response.Send(w, r, h.Logger, enrollment, err, http.StatusOK)
+}
+
+// ConfirmMFAHandler lida com a requisição POST /v1/me/mfa/confirm.
+// @Summary Ativa o segundo fator
+// @Description Confere o primeiro código do autenticador, ativa o segundo fator e retorna os códigos de recuperação (exibidos apenas nesta resposta).
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param code body domain.MFACode true "Código do autenticador"
+// @Success 200 {object} domain.RecoveryCodes "Códigos de recuperação"
+// @Failure 401 {object} domain.ErrorResponse "Código inválido"
+// @Failure 409 {object} domain.ErrorResponse "Nenhum cadastro pendente ou segundo fator já ativo"
+// @Security ApiKeyAuth
+// @Router /me/mfa/confirm [post]
+func (h *Handler) ConfirmMFAHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserClaimsFromContext(r.Context())
+	if !ok {
+		response.Send(w, r, h.Logger, nil, apperror.NewUnauthorizedError("Autorização necessária."), http.StatusOK)
+		return
+	}
+
+	var code domain.MFACode
+	if err := validation.DecodeJSON(w, r, &code); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+
+	codes, err := h.Service.ConfirmMFA(r.Context(), claims.UserID, code.Code)
+	response.Send(w, r, h.Logger, codes, err, http.StatusOK)
+}
+
+// DisableMFAHandler lida com a requisição DELETE /v1/me/mfa.
+// @Summary Desativa o segundo fator
+// @Description Confere a senha e um código (do autenticador ou de recuperação) e desativa o segundo fator. Não permitido para papéis que o exigem.
+// @Tags users
+// @Accept json
+// @Param request body domain.MFADisable true "Senha e código"
+// @Success 204 "Segundo fator desativado"
+// @Failure 401 {object} domain.ErrorResponse "Senha ou código incorretos"
+// @Failure 409 {object} domain.ErrorResponse "Segundo fator inativo ou exigido pelo papel"
+// @Security ApiKeyAuth
+// @Router /me/mfa [delete]
+func (h *Handler) DisableMFAHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserClaimsFromContext(r.Context())
+	if !ok {
+		response.Send(w, r, h.Logger, nil, apperror.NewUnauthorizedError("Autorização necessária."), http.StatusNoContent)
+		return
+	}
+
+	var request domain.MFADisable
+	if err := validation.DecodeJSON(w, r, &request); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+
+	err := h.Service.DisableMFA(r.Context(), claims.UserID, request)
+	response.Send(w, r, h.Logger, nil, err, http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodesHandler lida com a requisição POST /v1/me/mfa/recovery-codes.
+// @Summary Gera novos códigos de recuperação
+// @Description Confere um código do autenticador e substitui os códigos de recuperação; os anteriores deixam de valer.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param code body domain.MFACode true "Código do autenticador"
+// @Success 200 {object} domain.RecoveryCodes "Novos códigos de recuperação"
+// @Failure 401 {object} domain.ErrorResponse "Código inválido"
+// @Failure 409 {object} domain.ErrorResponse "Segundo fator inativo"
+// @Security ApiKeyAuth
+// @Router /me/mfa/recovery-codes [post]
+func (h *Handler) RegenerateRecoveryCodesHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserClaimsFromContext(r.Context())
+	if !ok {
+		response.Send(w, r, h.Logger, nil, apperror.NewUnauthorizedError("Autorização necessária."), http.StatusOK)
+		return
+	}
+
+	var code domain.MFACode
+	if err := validation.DecodeJSON(w, r, &code); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+
+	codes, err := h.Service.RegenerateRecoveryCodes(r.Context(), claims.UserID, code.Code)
+	response.Send(w, r, h.Logger, codes, err, http.StatusOK)
+}
+
+// ResetUserMFAHandler lida com a requisição DELETE /v1/users/{id}/mfa.
+// @Summary Remove o segundo fator de um usuário
+// @Description Para autenticadores perdidos: remove o segundo fator e os códigos de recuperação do usuário e encerra as sessões dele.
+// @Tags users
+// @Param id path string true "ID do usuário"
+// @Success 204 "Segundo fator removido"
+// @Failure 400 {object} domain.ErrorResponse "ID inválido"
+// @Failure 404 {object} domain.ErrorResponse "Usuário não encontrado"
+// @Security ApiKeyAuth
+// @Router /users/{id}/mfa [delete]
+func (h *Handler) ResetUserMFAHandler(w http.ResponseWriter, r *http.Request) {
+	err := h.Service.ResetUserMFA(r.Context(), r.PathValue("id"))
+	response.Send(w, r, h.Logger, nil, err, http.StatusNoContent)
+}
+
+// CreateServiceAccountHandler lida com a requisição POST /v1/service-accounts.
+// @Summary Cria uma conta de serviço
+// @Description Cria um usuário sem senha para integrações (ERP, e-commerce). A conta não faz login: acessa a API apenas com chaves de API, criadas por um admin em POST /api-keys com user_id. O papel limita as permissões de todas as chaves da conta.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param account body domain.ServiceAccountCreate true "Identificador (e-mail) e papel da conta"
+// @Success 201 {object} domain.User "Conta de serviço criada"
+// @Failure 400 {object} domain.ErrorResponse "Identificador inválido"
+// @Failure 404 {object} domain.ErrorResponse "Papel não encontrado"
+// @Failure 409 {object} domain.ErrorResponse "Identificador já cadastrado"
+// @Security ApiKeyAuth
+// @Router /service-accounts [post]
+func (h *Handler) CreateServiceAccountHandler(w http.ResponseWriter, r *http.Request) {
+	var req domain.ServiceAccountCreate
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+
+	account, err := h.Service.CreateServiceAccount(r.Context(), req)
+	response.Send(w, r, h.Logger, account, err, http.StatusCreated)
+}
+
+// CreateAPIKeyHandler lida com a requisição POST /v1/api-keys.
+// @Summary Cria uma chave de API
+// @Description Cria uma chave para integrações, enviada no header X-API-Key. A chave completa só é exibida nesta resposta. Os escopos (permissões do catálogo) limitam a chave, além do papel do dono; sem escopos, a chave só acessa as rotas de leitura. Admins podem criar chaves para outros usuários (user_id), como contas de serviço.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param key body domain.APIKeyCreate true "Nome, escopos e expiração da chave"
+// @Success 201 {object} domain.APIKeyCreated "Chave criada (exibida apenas uma vez)"
+// @Failure 400 {object} domain.ErrorResponse "Nome, escopo ou expiração inválidos"
+// @Failure 403 {object} domain.ErrorResponse "Chave para outro usuário sem ser admin"
+// @Failure 404 {object} domain.ErrorResponse "Usuário não encontrado"
+// @Security ApiKeyAuth
+// @Router /api-keys [post]
+func (h *Handler) CreateAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	var req domain.APIKeyCreate
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+
+	created, err := h.Service.CreateAPIKey(r.Context(), req)
+	response.Send(w, r, h.Logger, created, err, http.StatusCreated)
+}
+
+// ListAPIKeysHandler lida com a requisição GET /v1/api-keys.
+// @Summary Lista as chaves de API
+// @Description Lista as chaves do usuário autenticado (inclusive revogadas e expiradas), sem os segredos. Admins podem informar user_id para listar as chaves de outro usuário.
+// @Tags api-keys
+// @Produce json
+// @Param user_id query string false "ID do dono das chaves (apenas admin)"
+// @Success 200 {array} domain.APIKey "Chaves de API"
+// @Failure 403 {object} domain.ErrorResponse "Chaves de outro usuário sem ser admin"
+// @Security ApiKeyAuth
+// @Router /api-keys [get]
+func (h *Handler) ListAPIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.Service.ListAPIKeys(r.Context(), r.URL.Query().Get("user_id"))
+	response.Send(w, r, h.Logger, keys, err, http.StatusOK)
+}
+
+// RevokeAPIKeyHandler lida com a requisição DELETE /v1/api-keys/{id}.
+// @Summary Revoga uma chave de API
+// @Description A chave deixa de ser aceita imediatamente. O dono revoga as próprias chaves; admins, qualquer chave.
+// @Tags api-keys
+// @Param id path string true "ID da chave"
+// @Success 204 "Chave revogada"
+// @Failure 400 {object} domain.ErrorResponse "ID inválido"
+// @Failure 404 {object} domain.ErrorResponse "Chave não encontrada"
+// @Security ApiKeyAuth
+// @Router /api-keys/{id} [delete]
+func (h *Handler) RevokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	err := h.Service.RevokeAPIKey(r.Context(), r.PathValue("id"))
+	response.Send(w, r, h.Logger, nil, err, http.StatusNoContent)
+}
+
+// GetProfileHandler lida com a requisição GET /v1/me.
+// @Summary Obtém o próprio perfil
+// @Tags users
+// @Produce json
+// @Success 200 {object} domain.User "Usuário autenticado"
+// @Failure 401 {object} domain.ErrorResponse "Token ausente ou inválido"
+// @Security ApiKeyAuth
+// @Router /me [get]
+func (h *Handler) GetProfileHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserClaimsFromContext(r.Context())
+	if !ok {
+		response.Send(w, r, h.Logger, nil, apperror.NewUnauthorizedError("Autorização necessária."), http.StatusOK)
+		return
+	}
+
+	profile, err := h.Service.GetUser(r.Context(), claims.UserID)
+	response.Send(w, r, h.Logger, profile, err, http.StatusOK)
+}
+
+// ChangePasswordHandler lida com a requisição PUT /v1/me/password.
+// @Summary Troca a própria senha
+// @Description Confere a senha atual e grava a nova. Todas as sessões do usuário, inclusive a atual, são encerradas.
+// @Tags users
+// @Accept json
+// @Param change body domain.PasswordChange true "Senha atual e nova senha"
+// @Success 204 "Senha alterada"
+// @Failure 400 {object} domain.ErrorResponse "Payload inválido"
+// @Failure 401 {object} domain.ErrorResponse "Senha atual incorreta"
+// @Security ApiKeyAuth
+// @Router /me/password [put]
+func (h *Handler) ChangePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserClaimsFromContext(r.Context())
+	if !ok {
+		response.Send(w, r, h.Logger, nil, apperror.NewUnauthorizedError("Autorização necessária."), http.StatusNoContent)
+		return
+	}
+
+	var change domain.PasswordChange
+	if err := validation.DecodeJSON(w, r, &change); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+
+	err := h.Service.ChangePassword(r.Context(), claims.UserID, change)
+	response.Send(w, r, h.Logger, nil, err, http.StatusNoContent)
+}
+
+// ForgotPasswordHandler lida com a requisição POST /v1/password/forgot.
+// @Summary Pede a redefinição de senha
+// @Description Envia ao e-mail um link de redefinição de senha, de uso único, que expira após PASSWORD_RESET_EXPIRY_MIN. A resposta é sempre 202, exista ou não a conta.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body domain.PasswordForgot true "E-mail da conta"
+// @Success 202 {object} map[string]string "Pedido aceito"
+// @Failure 400 {object} domain.ErrorResponse "Payload inválido"
+// @Router /password/forgot [post]
+func (h *Handler) ForgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var req domain.PasswordForgot
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+
+	err := h.Service.ForgotPassword(r.Context(), req.Email)
+	response.Send(w, r, h.Logger, map[string]string{
+		"message": "Se o e-mail estiver cadastrado, você receberá um link para redefinir a senha.",
+	}, err, http.StatusAccepted)
+}
+
+// ResetPasswordHandler lida com a requisição POST /v1/password/reset.
+// @Summary Redefine a senha
+// @Description Grava a nova senha usando o token recebido por e-mail. O token é consumido e todas as sessões do usuário são encerradas.
+// @Tags users
+// @Accept json
+// @Param reset body domain.PasswordReset true "Token recebido por e-mail e nova senha"
+// @Success 204 "Senha redefinida"
+// @Failure 400 {object} domain.ErrorResponse "Payload inválido"
+// @Failure 401 {object} domain.ErrorResponse "Token inválido, já usado ou expirado"
+// @Router /password/reset [post]
+func (h *Handler) ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var reset domain.PasswordReset
+	if err := validation.DecodeJSON(w, r, &reset); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+
+	err := h.Service.ResetPassword(r.Context(), reset)
+	response.Send(w, r, h.Logger, nil, err, http.StatusNoContent)
+}
+
+// VerifyEmailHandler lida com a requisição GET /v1/verify-email?token=.
+// @Summary Verifica o e-mail
+// @Description Confirma o e-mail do usuário com o token do link enviado no registro.
+// @Tags users
+// @Produce json
+// @Param token query string true "Token de verificação"
+// @Success 200 {object} map[string]string "E-mail verificado"
+// @Failure 400 {object} domain.ErrorResponse "Token ausente"
+// @Failure 401 {object} domain.ErrorResponse "Token inválido, já usado ou expirado"
+// @Router /verify-email [get]
+func (h *Handler) VerifyEmailHandler(w http.ResponseWriter, r *http.Request) {
+	err := h.Service.VerifyEmail(r.Context(), r.URL.Query().Get("token"))
+	response.Send(w, r, h.Logger, map[string]string{"message": "E-mail verificado com sucesso."}, err, http.StatusOK)
+}
+
+// parsePositiveInt converte um parâmetro de paginação, usando o padrão quando vazio ou não positivo.
+func parsePositiveInt(s string, defaultValue int) (int, error) {
+	if s == "" {
+		return defaultValue, nil
+	}
+	val, err := strconv.Atoi(s)
 	if err != nil {
-		// O handleServiceResponse traduzirá 401 Unauthorized, 400 Validation, 500 Internal
-		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		return 0, err
+	}
+	if val <= 0 {
+		return defaultValue, nil
+	}
+	return val, nil
+}
+
+// oidcStateCookie vincula o login OIDC ao navegador que o iniciou: o retorno só é aceito com o
+// mesmo state no cookie, o que impede que um terceiro conclua o login dele na sessão da vítima.
+const oidcStateCookie = "gostock_oidc_state"
+
+// StartOIDCLoginHandler lida com a requisição GET /v1/oidc/login.
+// @Summary Inicia o login com o provedor OpenID Connect
+// @Description Redireciona o navegador para o provedor de identidade (fluxo authorization code com PKCE). O provedor retorna para /oidc/callback. O login com e-mail e senha continua disponível.
+// @Tags users
+// @Success 302 "Redirecionamento para o provedor"
+// @Failure 404 {object} domain.ErrorResponse "Login OIDC não configurado"
+// @Failure 500 {object} domain.ErrorResponse "Provedor indisponível"
+// @Router /oidc/login [get]
+func (h *Handler) StartOIDCLoginHandler(w http.ResponseWriter, r *http.Request) {
+	authorization, err := h.Service.StartOIDCLogin(r.Context())
+	if err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    authorization.State,
+		Path:     "/v1/oidc",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode, // Enviado no redirecionamento de volta do provedor
+	})
+	http.Redirect(w, r, authorization.AuthorizationURL, http.StatusFound)
+}
+
+// OIDCCallbackHandler lida com a requisição GET /v1/oidc/callback.
+// @Summary Conclui o login com o provedor OpenID Connect
+// @Description Endereço de retorno do provedor. Valida o state, troca o código pelo ID token e emite os tokens da sessão. No primeiro login, a identidade é vinculada à conta com o mesmo e-mail (se verificado pelo provedor) ou um usuário é criado com o papel padrão; com OIDC_ROLE_MAPPING, o papel segue os grupos do provedor. Se o segundo fator for exigido, a resposta traz o desafio (conclua em /login/mfa).
+// @Tags users
+// @Produce json
+// @Param code query string false "Código de autorização"
+// @Param state query string true "State do início do login"
+// @Param error query string false "Erro retornado pelo provedor"
+// @Success 200 {object} domain.AuthTokens "Tokens emitidos ou desafio do segundo fator"
+// @Failure 400 {object} domain.ErrorResponse "Parâmetros ausentes"
+// @Failure 401 {object} domain.ErrorResponse "State inválido ou expirado, ou login recusado pelo provedor"
+// @Failure 403 {object} domain.ErrorResponse "Sem e-mail verificado ou sem conta (criação automática desativada)"
+// @Failure 404 {object} domain.ErrorResponse "Login OIDC não configurado"
+// @Router /oidc/callback [get]
+func (h *Handler) OIDCCallbackHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	callback := domain.OIDCCallback{
+		Code:             query.Get("code"),
+		State:            query.Get("state"),
+		Error:            query.Get("error"),
+		ErrorDescription: query.Get("error_description"),
+	}
+
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil || callback.State == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(callback.State)) != 1 {
+		response.Send(w, r, h.Logger, nil, apperror.NewUnauthorizedError("Login OIDC iniciado em outro navegador ou expirado. Inicie o login novamente."), http.StatusOK)
 		return
 	}
+	http.SetCookie(w, &http.Cookie{Name: oidcStateCookie, Value: "", Path: "/v1/oidc", MaxAge: -1, HttpOnly: true})
 
-	// 2. Resposta de Sucesso (200 OK com o Token)
-	response := map[string]string{"token": token}
-	h.handleServiceResponse(w, r, response, nil, http.StatusOK)
+	tokens, err := h.Service.CompleteOIDCLogin(r.Context(), callback)
+	response.Send(w, r, h.Logger, tokens, err, http.StatusOK)
 }
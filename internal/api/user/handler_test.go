@@ -0,0 +1,67 @@
+package user_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gostock/internal/api/user"
+	"gostock/internal/domain"
+	"gostock/internal/pkg/logger"
+)
+
+// stubUserService registra as chaves criadas; os demais métodos da interface não são usados
+// pelos testes (chamá-los causa panic pela interface nil embutida).
+type stubUserService struct {
+	user.UserService
+	created []domain.APIKeyCreate
+}
+
+func (s *stubUserService) CreateAPIKey(ctx context.Context, request domain.APIKeyCreate) (domain.APIKeyCreated, error) {
+	s.created = append(s.created, request)
+	return domain.APIKeyCreated{APIKey: domain.APIKey{ID: "key-1", Name: request.Name, Scopes: []domain.Permission{}}, Key: "gsk_teste"}, nil
+}
+
+// TestCreateAPIKeyHandler_WithoutScopes testa a criação de uma chave sem escopos (apenas leitura):
+// o campo scopes pode ser omitido ou vazio.
+func TestCreateAPIKeyHandler_WithoutScopes(t *testing.T) {
+	for _, body := range []string{`{"name": "Leitura"}`, `{"name": "Leitura", "scopes": []}`} {
+		t.Run(body, func(t *testing.T) {
+			svc := &stubUserService{}
+			h := user.NewHandler(svc, logger.NewLogger("error"))
+			req := httptest.NewRequest(http.MethodPost, "/v1/api-keys", strings.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			h.CreateAPIKeyHandler(rec, req)
+
+			assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+			if assert.Len(t, svc.created, 1) {
+				assert.Equal(t, "Leitura", svc.created[0].Name)
+				assert.Empty(t, svc.created[0].Scopes)
+			}
+			var created domain.APIKeyCreated
+			if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+				t.Fatalf("corpo não é JSON: %v (%s)", err, rec.Body.String())
+			}
+			assert.Equal(t, "gsk_teste", created.Key)
+		})
+	}
+}
+
+// TestCreateAPIKeyHandler_Fail_MissingName testa que o nome continua obrigatório.
+func TestCreateAPIKeyHandler_Fail_MissingName(t *testing.T) {
+	svc := &stubUserService{}
+	h := user.NewHandler(svc, logger.NewLogger("error"))
+	req := httptest.NewRequest(http.MethodPost, "/v1/api-keys", strings.NewReader(`{"scopes": ["stock:adjust"]}`))
+	rec := httptest.NewRecorder()
+
+	h.CreateAPIKeyHandler(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+	assert.Empty(t, svc.created)
+}
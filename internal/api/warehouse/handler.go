@@ -1,14 +1,12 @@
 package warehouse
 
 import (
-	"encoding/json"
-	"fmt"
 	"net/http"
-	"strings"
 
 	"gostock/internal/domain"
-	apperror "gostock/internal/errors"
 	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/response"
+	"gostock/internal/pkg/validation"
 )
 
 // WarehouseService define o contrato que o Handler espera da camada de Serviço.
@@ -18,6 +16,8 @@ type WarehouseService interface {
 	GetAllWarehouses(ctx domain.Context) ([]domain.Warehouse, error)
 	UpdateWarehouse(ctx domain.Context, warehouse domain.Warehouse) (domain.Warehouse, error)
 	DeleteWarehouse(ctx domain.Context, id string) error
+	GetUserWarehouses(ctx domain.Context, userID string) (domain.UserWarehouses, error)
+	SetUserWarehouses(ctx domain.Context, assignment domain.UserWarehouses) (domain.UserWarehouses, error)
 }
 
 // Handler agrupa todos os métodos de Handler de armazéns.
@@ -34,41 +34,6 @@ func NewHandler(svc WarehouseService, log logger.Logger) *Handler {
 	}
 }
 
-// handleServiceResponse processa erros de serviço e envia respostas padronizadas ao cliente.
-func (h *Handler) handleServiceResponse(w http.ResponseWriter, r *http.Request, data interface{}, err error, successStatus int) {
-	if err == nil {
-		// Sucesso
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(successStatus)
-		if data != nil {
-			if jsonErr := json.NewEncoder(w).Encode(data); jsonErr != nil {
-				h.Logger.Error("Falha ao codificar JSON de resposta", jsonErr)
-				http.Error(w, "Erro ao codificar resposta", http.StatusInternalServerError)
-			}
-		}
-		return
-	}
-
-	// TRATAMENTO DE ERROS
-	status, category, message := apperror.MapToHTTPStatus(err)
-
-	if status >= 500 {
-		h.Logger.Error(fmt.Sprintf("Erro de Servidor: %s", category), err)
-	} else {
-		h.Logger.Debug(fmt.Sprintf("Requisição rejeitada com status %d. Categoria: %s", status, category), map[string]interface{}{"path": r.URL.Path})
-	}
-
-	errorResponse := map[string]interface{}{
-		"code":     status,
-		"category": category,
-		"message":  message,
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(errorResponse)
-}
-
 // CreateWarehouseHandler lida com a requisição POST /v1/warehouses.
 // @Summary Cria um novo armazém
 // @Description Cria um novo armazém no sistema.
@@ -82,25 +47,20 @@ func (h *Handler) handleServiceResponse(w http.ResponseWriter, r *http.Request,
 // @Security ApiKeyAuth
 // @Router /warehouses [post]
 func (h *Handler) CreateWarehouseHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
-		return
-	}
-
 	ctx := r.Context()
 	var warehouse domain.Warehouse
-	if err := json.NewDecoder(r.Body).Decode(&warehouse); err != nil {
-		h.handleServiceResponse(w, r, nil, apperror.NewValidationError("Payload inválido. Verifique o formato JSON."), http.StatusBadRequest)
+	if err := validation.DecodeJSON(w, r, &warehouse); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
 		return
 	}
 
 	createdWarehouse, err := h.Service.CreateWarehouse(ctx, warehouse)
 	if err != nil {
-		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
 		return
 	}
 
-	h.handleServiceResponse(w, r, createdWarehouse, nil, http.StatusCreated)
+	response.Send(w, r, h.Logger, createdWarehouse, nil, http.StatusCreated)
 }
 
 // GetWarehouseByIDHandler lida com a requisição GET /v1/warehouses/{id}.
@@ -114,21 +74,16 @@ func (h *Handler) CreateWarehouseHandler(w http.ResponseWriter, r *http.Request)
 // @Failure 500 {object} domain.ErrorResponse "Erro interno do servidor"
 // @Router /warehouses/{id} [get]
 func (h *Handler) GetWarehouseByIDHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
-		return
-	}
-
 	ctx := r.Context()
-	id := strings.TrimPrefix(r.URL.Path, "/v1/warehouses/") // Assumes URL path like /v1/warehouses/{id}
+	id := r.PathValue("id")
 
 	warehouse, err := h.Service.GetWarehouseByID(ctx, id)
 	if err != nil {
-		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
 		return
 	}
 
-	h.handleServiceResponse(w, r, warehouse, nil, http.StatusOK)
+	response.Send(w, r, h.Logger, warehouse, nil, http.StatusOK)
 }
 
 // GetAllWarehousesHandler lida com a requisição GET /v1/warehouses.
@@ -140,19 +95,14 @@ func (h *Handler) GetWarehouseByIDHandler(w http.ResponseWriter, r *http.Request
 // @Failure 500 {object} domain.ErrorResponse "Erro interno do servidor"
 // @Router /warehouses [get]
 func (h *Handler) GetAllWarehousesHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
-		return
-	}
-
 	ctx := r.Context()
 	warehouses, err := h.Service.GetAllWarehouses(ctx)
 	if err != nil {
-		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
 		return
 	}
 
-	h.handleServiceResponse(w, r, warehouses, nil, http.StatusOK)
+	response.Send(w, r, h.Logger, warehouses, nil, http.StatusOK)
 }
 
 // UpdateWarehouseHandler lida com a requisição PUT /v1/warehouses/{id}.
@@ -170,28 +120,23 @@ func (h *Handler) GetAllWarehousesHandler(w http.ResponseWriter, r *http.Request
 // @Security ApiKeyAuth
 // @Router /warehouses/{id} [put]
 func (h *Handler) UpdateWarehouseHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPut {
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
-		return
-	}
-
 	ctx := r.Context()
-	id := strings.TrimPrefix(r.URL.Path, "/v1/warehouses/") // Assumes URL path like /v1/warehouses/{id}
+	id := r.PathValue("id")
 
 	var warehouse domain.Warehouse
-	if err := json.NewDecoder(r.Body).Decode(&warehouse); err != nil {
-		h.handleServiceResponse(w, r, nil, apperror.NewValidationError("Payload inválido. Verifique o formato JSON."), http.StatusBadRequest)
+	if err := validation.DecodeJSON(w, r, &warehouse); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
 		return
 	}
 	warehouse.ID = id // Ensure ID from URL path is used
 
 	updatedWarehouse, err := h.Service.UpdateWarehouse(ctx, warehouse)
 	if err != nil {
-		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
 		return
 	}
 
-	h.handleServiceResponse(w, r, updatedWarehouse, nil, http.StatusOK)
+	response.Send(w, r, h.Logger, updatedWarehouse, nil, http.StatusOK)
 }
 
 // DeleteWarehouseHandler lida com a requisição DELETE /v1/warehouses/{id}.
@@ -205,19 +150,55 @@ func (h *Handler) UpdateWarehouseHandler(w http.ResponseWriter, r *http.Request)
 // @Security ApiKeyAuth
 // @Router /warehouses/{id} [delete]
 func (h *Handler) DeleteWarehouseHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodDelete {
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
-		return
-	}
-
 	ctx := r.Context()
-	id := strings.TrimPrefix(r.URL.Path, "/v1/warehouses/") // Assumes URL path like /v1/warehouses/{id}
+	id := r.PathValue("id")
 
 	err := h.Service.DeleteWarehouse(ctx, id)
 	if err != nil {
-		h.handleServiceResponse(w, r, nil, err, http.StatusOK)
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
+		return
+	}
+
+	response.Send(w, r, h.Logger, nil, nil, http.StatusNoContent)
+}
+
+// GetUserWarehousesHandler lida com a requisição GET /v1/users/{id}/warehouses.
+// @Summary Lista os armazéns de um usuário
+// @Description Retorna os armazéns atribuídos ao usuário. Usuários que não são administradores só consultam e ajustam o estoque desses armazéns.
+// @Tags warehouses
+// @Produce json
+// @Param id path string true "ID do usuário"
+// @Success 200 {object} domain.UserWarehouses "Armazéns do usuário"
+// @Failure 400 {object} domain.ErrorResponse "ID inválido"
+// @Failure 404 {object} domain.ErrorResponse "Usuário não encontrado"
+// @Security ApiKeyAuth
+// @Router /users/{id}/warehouses [get]
+func (h *Handler) GetUserWarehousesHandler(w http.ResponseWriter, r *http.Request) {
+	assignment, err := h.Service.GetUserWarehouses(r.Context(), r.PathValue("id"))
+	response.Send(w, r, h.Logger, assignment, err, http.StatusOK)
+}
+
+// SetUserWarehousesHandler lida com a requisição PUT /v1/users/{id}/warehouses.
+// @Summary Atribui armazéns a um usuário
+// @Description Substitui os armazéns atribuídos ao usuário. Uma lista vazia remove todas as atribuições. Administradores têm acesso a todos os armazéns.
+// @Tags warehouses
+// @Accept json
+// @Produce json
+// @Param id path string true "ID do usuário"
+// @Param assignment body domain.UserWarehouses true "Armazéns (warehouse_ids)"
+// @Success 200 {object} domain.UserWarehouses "Armazéns atribuídos"
+// @Failure 400 {object} domain.ErrorResponse "Payload inválido"
+// @Failure 404 {object} domain.ErrorResponse "Usuário ou armazém não encontrado"
+// @Security ApiKeyAuth
+// @Router /users/{id}/warehouses [put]
+func (h *Handler) SetUserWarehousesHandler(w http.ResponseWriter, r *http.Request) {
+	var assignment domain.UserWarehouses
+	if err := validation.DecodeJSON(w, r, &assignment); err != nil {
+		response.Send(w, r, h.Logger, nil, err, http.StatusOK)
 		return
 	}
+	assignment.UserID = r.PathValue("id") // O ID do caminho prevalece sobre o do corpo
 
-	h.handleServiceResponse(w, r, nil, nil, http.StatusNoContent)
+	saved, err := h.Service.SetUserWarehouses(r.Context(), assignment)
+	response.Send(w, r, h.Logger, saved, err, http.StatusOK)
 }
@@ -0,0 +1,64 @@
+package domain
+
+import "time"
+
+// APIKey é uma chave de acesso para integrações máquina a máquina, enviada no header X-API-Key.
+// O segredo só é exibido na criação; o banco guarda apenas o hash.
+type APIKey struct {
+	ID         string       `json:"id"`
+	UserID     string       `json:"user_id"` // Dono da chave: as requisições são feitas em nome dele
+	Name       string       `json:"name" example:"Conector ERP"`
+	Prefix     string       `json:"prefix" example:"gsk_3f9a1c2b7d4e"` // Identifica a chave sem revelar o segredo
+	KeyHash    string       `json:"-"`
+	Scopes     []Permission `json:"scopes" example:"stock:adjust"`
+	ExpiresAt  *time.Time   `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time   `json:"last_used_at,omitempty"`
+	CreatedBy  string       `json:"created_by,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+	RevokedAt  *time.Time   `json:"revoked_at,omitempty"`
+}
+
+// Active indica se a chave pode ser usada no instante informado (não revogada nem expirada).
+func (k APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// HasScope indica se a chave libera a permissão.
+func (k APIKey) HasScope(p Permission) bool {
+	for _, scope := range k.Scopes {
+		if scope == p || scope == PermissionAll {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyCreate representa o payload de criação de uma chave de API.
+type APIKeyCreate struct {
+	Name      string       `json:"name" example:"Conector ERP" validate:"required,max=100"`
+	Scopes    []Permission `json:"scopes,omitempty" example:"stock:adjust"` // Vazio: a chave acessa apenas as rotas de leitura
+	ExpiresAt *time.Time   `json:"expires_at,omitempty"`                    // Vazio: a chave não expira
+	UserID    string       `json:"user_id,omitempty" validate:"uuid"`       // Apenas admin: cria a chave para outro usuário ou conta de serviço
+}
+
+// APIKeyCreated é a resposta da criação: a única vez em que a chave completa é exibida.
+type APIKeyCreated struct {
+	APIKey
+	Key string `json:"key" example:"gsk_3f9a1c2b7d4e_Zm9vYmFyYmF6..."`
+}
+
+// ServiceAccountCreate representa o payload de criação de uma conta de serviço.
+type ServiceAccountCreate struct {
+	Email string `json:"email" example:"erp@integracoes.gostock.com" validate:"required,email"` // Identificador da conta (não recebe e-mails)
+	Role  string `json:"role" example:"warehouse_staff" validate:"required"`
+}
+
+// APIKeyRepository define o contrato de persistência das chaves de API.
+type APIKeyRepository interface {
+	SaveAPIKey(ctx Context, key APIKey) error
+	FindAPIKeyByPrefix(ctx Context, prefix string) (APIKey, error)
+	GetAPIKey(ctx Context, id string) (APIKey, error)
+	ListAPIKeys(ctx Context, userID string) ([]APIKey, error)
+	RevokeAPIKey(ctx Context, id string) error
+	TouchAPIKey(ctx Context, id string, usedAt time.Time) error
+}
@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxGeneratedVariants limita quantas combinações o gerador de variantes cria de uma vez.
+const MaxGeneratedVariants = 500
+
+// attributeLabelSeparator une nomes e valores no rótulo legado (Attribute/Value) de variantes com vários eixos.
+const attributeLabelSeparator = " / "
+
+// AttributeDefinition define um eixo de variação (ex.: "Tamanho") e os valores permitidos, na ordem de exibição.
+type AttributeDefinition struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name" example:"Tamanho" validate:"required,max=50"`
+	AllowedValues []string  `json:"allowed_values" example:"P,M,G" validate:"required,max=200"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// CanonicalValue retorna o valor permitido equivalente a value (sem diferenciar maiúsculas) e se ele existe.
+func (d AttributeDefinition) CanonicalValue(value string) (string, bool) {
+	for _, allowed := range d.AllowedValues {
+		if strings.EqualFold(allowed, value) {
+			return allowed, true
+		}
+	}
+	return "", false
+}
+
+// VariantGenerationRequest é o payload do gerador de variantes: para cada atributo, os valores
+// escolhidos (lista vazia usa todos os valores permitidos da definição).
+type VariantGenerationRequest struct {
+	Attributes map[string][]string `json:"attributes" validate:"required"`
+	PriceDiff  float64             `json:"price_diff" validate:"min=0"` // Aplicado a todas as variantes geradas
+}
+
+// VariantGenerationResult é o resultado do gerador: o produto atualizado, as variantes criadas
+// e quantas combinações foram ignoradas por já existirem.
+type VariantGenerationResult struct {
+	Product Product   `json:"product"`
+	Created []Variant `json:"created"`
+	Skipped int       `json:"skipped"`
+}
+
+// NormalizeAttributes prepara os atributos da variante: variantes no formato legado (Attribute/Value)
+// viram uma combinação de um eixo, espaços são removidos e o rótulo Attribute/Value é derivado
+// da combinação (nomes e valores unidos por " / ", em ordem alfabética de nome).
+func (v *Variant) NormalizeAttributes() {
+	if len(v.Attributes) == 0 && (v.Attribute != "" || v.Value != "") {
+		v.Attributes = map[string]string{v.Attribute: v.Value}
+	}
+
+	normalized := make(map[string]string, len(v.Attributes))
+	for name, value := range v.Attributes {
+		normalized[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	v.Attributes = normalized
+
+	names := v.AttributeNames()
+	values := make([]string, len(names))
+	for i, name := range names {
+		values[i] = v.Attributes[name]
+	}
+	v.Attribute = strings.Join(names, attributeLabelSeparator)
+	v.Value = strings.Join(values, attributeLabelSeparator)
+}
+
+// AttributeNames retorna os nomes dos atributos da variante em ordem alfabética.
+func (v Variant) AttributeNames() []string {
+	names := make([]string, 0, len(v.Attributes))
+	for name := range v.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CombinationKey identifica a combinação de atributos da variante sem diferenciar maiúsculas,
+// para detectar variantes repetidas dentro de um produto.
+func (v Variant) CombinationKey() string {
+	names := v.AttributeNames()
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = strings.ToLower(name) + "=" + strings.ToLower(v.Attributes[name])
+	}
+	return strings.Join(parts, ";")
+}
+
+// AttributeCombinations gera o produto cartesiano dos valores de cada eixo. Os eixos são percorridos
+// em ordem alfabética e os valores na ordem informada, para que o resultado seja determinístico.
+func AttributeCombinations(axes map[string][]string) ([]map[string]string, error) {
+	names := make([]string, 0, len(axes))
+	total := 1
+	for name, values := range axes {
+		if len(values) == 0 {
+			return nil, fmt.Errorf("o atributo '%s' não tem valores", name)
+		}
+		total *= len(values)
+		if total > MaxGeneratedVariants {
+			return nil, fmt.Errorf("a combinação dos valores gera mais de %d variantes", MaxGeneratedVariants)
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("informe ao menos um atributo")
+	}
+	sort.Strings(names)
+
+	combinations := []map[string]string{{}}
+	for _, name := range names {
+		next := make([]map[string]string, 0, len(combinations)*len(axes[name]))
+		for _, partial := range combinations {
+			for _, value := range axes[name] {
+				combination := make(map[string]string, len(partial)+1)
+				for k, v := range partial {
+					combination[k] = v
+				}
+				combination[name] = value
+				next = append(next, combination)
+			}
+		}
+		combinations = next
+	}
+	return combinations, nil
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxBarcodeLength limita o tamanho de códigos de barras internos (não-GTIN).
+const MaxBarcodeLength = 64
+
+// gtinLengths são os tamanhos numéricos tratados como GTIN: EAN-8, UPC-A (GTIN-12), EAN-13 e GTIN-14.
+var gtinLengths = map[int]string{8: "EAN-8", 12: "UPC-A", 13: "EAN-13", 14: "GTIN-14"}
+
+// VariantBarcode é um código de barras adicional de uma variante (ex.: o código da caixa com 12 unidades).
+// Unit é a unidade de medida que o código representa; vazio significa a unidade-base.
+type VariantBarcode struct {
+	Barcode   string    `json:"barcode" example:"17891234567892" validate:"required"`
+	VariantID string    `json:"variant_id" validate:"required,uuid"`
+	Unit      string    `json:"unit,omitempty" example:"cx" validate:"max=20"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BarcodeLookup é o resultado da leitura de um código de barras: o produto e a variante a que ele
+// pertence e, opcionalmente, o estoque atual da variante por armazém.
+type BarcodeLookup struct {
+	Barcode string       `json:"barcode"`
+	Primary bool         `json:"primary"`        // true para o código principal da variante (variants.barcode)
+	Unit    string       `json:"unit,omitempty"` // Unidade representada pelo código (vazio = unidade-base)
+	Product Product      `json:"product"`
+	Variant Variant      `json:"variant"`
+	Stock   []StockLevel `json:"stock,omitempty"`
+}
+
+// GTINCheckDigit calcula o dígito verificador GS1 (módulo 10) para os dígitos informados,
+// que devem ser o código sem o dígito verificador.
+func GTINCheckDigit(body string) int {
+	sum := 0
+	for i := len(body) - 1; i >= 0; i-- {
+		digit := int(body[i] - '0')
+		if (len(body)-1-i)%2 == 0 {
+			digit *= 3 // Da direita para a esquerda, as posições ímpares têm peso 3
+		}
+		sum += digit
+	}
+	return (10 - sum%10) % 10
+}
+
+// ValidateBarcode verifica um código de barras. Códigos numéricos com 8, 12, 13 ou 14 dígitos são
+// tratados como GTIN (EAN-8, UPC-A, EAN-13, GTIN-14) e precisam ter o dígito verificador correto.
+// Outros formatos (códigos internos, Code 128 alfanumérico) são aceitos sem verificação.
+func ValidateBarcode(code string) error {
+	if code == "" {
+		return fmt.Errorf("o código de barras é obrigatório")
+	}
+	if len(code) > MaxBarcodeLength {
+		return fmt.Errorf("o código de barras deve ter no máximo %d caracteres", MaxBarcodeLength)
+	}
+
+	symbology, ok := gtinLengths[len(code)]
+	if !ok || !isDigits(code) {
+		return nil
+	}
+	expected := GTINCheckDigit(code[:len(code)-1])
+	if int(code[len(code)-1]-'0') != expected {
+		return fmt.Errorf("o código de barras '%s' (%s) tem dígito verificador inválido: esperado %d", code, symbology, expected)
+	}
+	return nil
+}
+
+// BarcodeForms retorna as representações equivalentes de um código de barras, para que a leitura
+// encontre o produto independentemente de como o código foi cadastrado. Um GTIN válido é o mesmo
+// item com ou sem zeros à esquerda: o UPC-A 012345678905 também é lido como o EAN-13 0012345678905
+// e o GTIN-14 00012345678905. Retorna as formas de 14, 13, 12 e 8 dígitos que o valor admite;
+// códigos que não são GTIN são retornados como estão.
+func BarcodeForms(code string) []string {
+	if _, ok := gtinLengths[len(code)]; !ok || !isDigits(code) || ValidateBarcode(code) != nil {
+		return []string{code}
+	}
+
+	gtin14 := strings.Repeat("0", 14-len(code)) + code
+	forms := make([]string, 0, len(gtinLengths))
+	for _, length := range []int{14, 13, 12, 8} {
+		if strings.Trim(gtin14[:14-length], "0") == "" {
+			forms = append(forms, gtin14[14-length:])
+		}
+	}
+	return forms
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CustomAttributeType é o tipo de um campo de atributo personalizado do produto.
+type CustomAttributeType string
+
+// Tipos aceitos nos esquemas de atributos personalizados.
+const (
+	CustomAttributeString CustomAttributeType = "string"
+	CustomAttributeNumber CustomAttributeType = "number"
+	CustomAttributeBool   CustomAttributeType = "bool"
+	CustomAttributeEnum   CustomAttributeType = "enum"
+)
+
+// CustomAttributeField descreve um campo do esquema de uma categoria (ex.: "peso_kg", number).
+type CustomAttributeField struct {
+	Name     string              `json:"name" example:"material" validate:"required"`
+	Type     CustomAttributeType `json:"type" example:"enum" validate:"required,oneof=string number bool enum"`
+	Required bool                `json:"required"`
+	Values   []string            `json:"values,omitempty" example:"Algodão,Poliéster"` // Apenas para o tipo enum
+}
+
+// CategorySchema é o esquema de atributos personalizados (especificações) dos produtos de uma categoria.
+type CategorySchema struct {
+	Category  string                 `json:"category" example:"Vestuário"`
+	Fields    []CustomAttributeField `json:"fields"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+// Field retorna o campo do esquema com o nome informado (sem diferenciar maiúsculas).
+func (s CategorySchema) Field(name string) (CustomAttributeField, bool) {
+	for _, f := range s.Fields {
+		if strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+	return CustomAttributeField{}, false
+}
+
+// ValidateCustomAttributes confere os atributos com o esquema e retorna uma cópia normalizada:
+// nomes como definidos no esquema, números como float64, booleanos como bool e valores de enum
+// como cadastrados. Textos "12.5" ou "true" são aceitos para campos numéricos e booleanos
+// (ex.: valores vindos de CSV). Campos fora do esquema e campos obrigatórios ausentes são recusados.
+func (s CategorySchema) ValidateCustomAttributes(attrs map[string]interface{}) (map[string]interface{}, error) {
+	normalized := make(map[string]interface{}, len(attrs))
+	for name, raw := range attrs {
+		field, ok := s.Field(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("o campo '%s' não existe no esquema da categoria '%s'", name, s.Category)
+		}
+		if _, repeated := normalized[field.Name]; repeated {
+			return nil, fmt.Errorf("o campo '%s' está repetido", field.Name)
+		}
+		if raw == nil {
+			continue // null equivale a ausente
+		}
+		value, err := field.coerce(raw)
+		if err != nil {
+			return nil, err
+		}
+		normalized[field.Name] = value
+	}
+
+	for _, field := range s.Fields {
+		if _, ok := normalized[field.Name]; field.Required && !ok {
+			return nil, fmt.Errorf("o campo '%s' é obrigatório na categoria '%s'", field.Name, s.Category)
+		}
+	}
+	return normalized, nil
+}
+
+// coerce converte o valor para o tipo do campo.
+func (f CustomAttributeField) coerce(raw interface{}) (interface{}, error) {
+	switch f.Type {
+	case CustomAttributeNumber:
+		switch v := raw.(type) {
+		case float64:
+			return v, nil
+		case string:
+			n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err == nil && !math.IsInf(n, 0) && !math.IsNaN(n) {
+				return n, nil
+			}
+		}
+		return nil, fmt.Errorf("o campo '%s' deve ser numérico", f.Name)
+	case CustomAttributeBool:
+		switch v := raw.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+				return b, nil
+			}
+		}
+		return nil, fmt.Errorf("o campo '%s' deve ser true ou false", f.Name)
+	case CustomAttributeEnum:
+		if v, ok := raw.(string); ok {
+			for _, allowed := range f.Values {
+				if strings.EqualFold(allowed, strings.TrimSpace(v)) {
+					return allowed, nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("o valor de '%s' deve ser um de: %s", f.Name, strings.Join(f.Values, ", "))
+	default:
+		if v, ok := raw.(string); ok {
+			return strings.TrimSpace(v), nil
+		}
+		return nil, fmt.Errorf("o campo '%s' deve ser texto", f.Name)
+	}
+}
+
+// Validate confere a definição do esquema: nomes não vazios e únicos, tipos conhecidos
+// e valores (únicos) apenas para campos enum. Nomes e valores têm os espaços removidos.
+func (s *CategorySchema) Validate() error {
+	s.Category = strings.TrimSpace(s.Category)
+	if s.Category == "" || len(s.Category) > 100 {
+		return fmt.Errorf("a categoria deve ter entre 1 e 100 caracteres")
+	}
+
+	seen := make(map[string]bool, len(s.Fields))
+	for i := range s.Fields {
+		f := &s.Fields[i]
+		f.Name = strings.TrimSpace(f.Name)
+		if f.Name == "" || len(f.Name) > 50 {
+			return fmt.Errorf("o nome do campo %d deve ter entre 1 e 50 caracteres", i+1)
+		}
+		if seen[strings.ToLower(f.Name)] {
+			return fmt.Errorf("o campo '%s' está repetido", f.Name)
+		}
+		seen[strings.ToLower(f.Name)] = true
+
+		switch f.Type {
+		case CustomAttributeString, CustomAttributeNumber, CustomAttributeBool:
+			if len(f.Values) > 0 {
+				return fmt.Errorf("o campo '%s' só aceita 'values' com o tipo enum", f.Name)
+			}
+		case CustomAttributeEnum:
+			if len(f.Values) == 0 {
+				return fmt.Errorf("o campo enum '%s' precisa de ao menos um valor", f.Name)
+			}
+			values := make(map[string]bool, len(f.Values))
+			for j, v := range f.Values {
+				v = strings.TrimSpace(v)
+				if v == "" || values[strings.ToLower(v)] {
+					return fmt.Errorf("os valores do campo '%s' devem ser únicos e não vazios", f.Name)
+				}
+				values[strings.ToLower(v)] = true
+				f.Values[j] = v
+			}
+		default:
+			return fmt.Errorf("o tipo '%s' do campo '%s' é inválido (use string, number, bool ou enum)", f.Type, f.Name)
+		}
+	}
+	return nil
+}
+
+// CustomAttributeNames retorna os nomes dos atributos personalizados em ordem alfabética.
+func CustomAttributeNames(attrs map[string]interface{}) []string {
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
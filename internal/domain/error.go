@@ -3,7 +3,29 @@ package domain
 // ErrorResponse é a estrutura padronizada para respostas de erro na API.
 // @Description Estrutura padronizada para respostas de erro na API.
 type ErrorResponse struct {
-	Code     int    `json:"code" example:"400"`
-	Category string `json:"category" example:"VALIDATION_ERROR"`
-	Message  string `json:"message" example:"O nome do armazém não pode ser vazio."`
+	Code      int          `json:"code" example:"400"`
+	Category  string       `json:"category" example:"VALIDATION_ERROR"`
+	Message   string       `json:"message" example:"O nome do armazém não pode ser vazio."`
+	Errors    []FieldError `json:"errors,omitempty"`                                // Campos inválidos (apenas erros de validação)
+	RequestID string       `json:"request_id,omitempty" example:"9f1c2d7e4b3a4c5d"` // ID da requisição (header X-Request-ID)
+}
+
+// ProblemDetails é a resposta de erro no formato RFC 7807 (application/problem+json), enviada
+// quando o cliente a solicita no header Accept. Code repete a categoria do ErrorResponse.
+// @Description Resposta de erro no formato RFC 7807 (Accept: application/problem+json).
+type ProblemDetails struct {
+	Type      string       `json:"type" example:"urn:gostock:error:VALIDATION_ERROR"`
+	Title     string       `json:"title" example:"Bad Request"`
+	Status    int          `json:"status" example:"400"`
+	Detail    string       `json:"detail" example:"Erro de Validação: Configurações da empresa inválidas."`
+	Instance  string       `json:"instance" example:"/v1/tenant"`
+	Code      string       `json:"code" example:"VALIDATION_ERROR"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	RequestID string       `json:"request_id,omitempty" example:"9f1c2d7e4b3a4c5d"`
+}
+
+// FieldError detalha um campo inválido nas respostas de erro (espelha apperror.FieldError).
+type FieldError struct {
+	Field   string `json:"field" example:"settings.timezone"`
+	Message string `json:"message" example:"Fuso horário inválido: 'Lua/Base'."`
 }
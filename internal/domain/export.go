@@ -0,0 +1,56 @@
+package domain
+
+import "time"
+
+// ExportFormat identifica o formato de saída das exportações.
+type ExportFormat string
+
+// Formatos de exportação suportados.
+const (
+	ExportFormatCSV    ExportFormat = "csv"
+	ExportFormatNDJSON ExportFormat = "ndjson"
+)
+
+// ProductExportRow é uma linha da exportação de catálogo: um produto com uma de suas
+// variantes (ou sem variante, se o produto não tiver nenhuma) e o estoque por armazém.
+type ProductExportRow struct {
+	ProductID     string         `json:"product_id"`
+	SKU           string         `json:"sku"`
+	Name          string         `json:"name"`
+	Description   string         `json:"description"`
+	Price         float64        `json:"price"`
+	IsActive      bool           `json:"is_active"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	VariantID     string         `json:"variant_id,omitempty"`
+	Attribute     string         `json:"attribute,omitempty"`
+	Value         string         `json:"value,omitempty"`
+	Barcode       string         `json:"barcode,omitempty"`
+	PriceDiff     float64        `json:"price_diff"`
+	Quantities    map[string]int `json:"quantities"` // warehouse_id -> quantidade
+	TotalQuantity int            `json:"total_quantity"`
+}
+
+// StockExportRow é uma linha da exportação de estoque: o nível de uma variante em um armazém.
+type StockExportRow struct {
+	WarehouseID   string    `json:"warehouse_id"`
+	WarehouseName string    `json:"warehouse_name"`
+	ProductID     string    `json:"product_id"`
+	SKU           string    `json:"sku"`
+	ProductName   string    `json:"product_name"`
+	VariantID     string    `json:"variant_id"`
+	Attribute     string    `json:"attribute"`
+	Value         string    `json:"value"`
+	Barcode       string    `json:"barcode"`
+	Quantity      int       `json:"quantity"`
+	Version       int       `json:"version"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// StockExportFilter define os filtros da exportação de estoque: os mesmos filtros
+// da listagem de produtos, opcionalmente restritos a um armazém.
+type StockExportFilter struct {
+	Product      ProductFilter
+	WarehouseID  string
+	WarehouseIDs []string // Armazéns permitidos ao usuário; nil quando o acesso é global
+}
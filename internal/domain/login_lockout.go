@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// LockoutScope identifica o alvo da proteção contra força bruta no login.
+type LockoutScope string
+
+const (
+	LockoutScopeAccount LockoutScope = "account" // Tentativas contra um e-mail
+	LockoutScopeIP      LockoutScope = "ip"      // Tentativas a partir de um IP
+)
+
+// LoginLockout é a situação de um e-mail ou IP na proteção contra força bruta.
+type LoginLockout struct {
+	Scope          LockoutScope `json:"scope" example:"account"`
+	Subject        string       `json:"subject" example:"maria@gostock.com"` // E-mail ou IP
+	FailedAttempts int          `json:"failed_attempts"`                     // Falhas na janela atual
+	Locked         bool         `json:"locked"`
+	LockedUntil    *time.Time   `json:"locked_until,omitempty"`
+	Lockouts       int          `json:"lockouts"` // Bloqueios recentes; cada reincidência dobra a duração
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// ProductMedia é uma imagem (ou outra mídia) do produto, opcionalmente associada a uma variante.
+// Path é preenchido apenas para arquivos enviados ao armazenamento local e não é exposto na API.
+type ProductMedia struct {
+	ID          string    `json:"id"`
+	ProductID   string    `json:"product_id"`
+	VariantID   string    `json:"variant_id,omitempty"`
+	URL         string    `json:"url" example:"https://cdn.example.com/camiseta-azul.jpg"`
+	Path        string    `json:"-"`
+	ContentType string    `json:"content_type,omitempty" example:"image/jpeg"`
+	AltText     string    `json:"alt_text" example:"Camiseta azul, vista frontal"`
+	SortOrder   int       `json:"sort_order"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// MediaUpload é um arquivo de mídia enviado para o armazenamento local, com os metadados da mídia.
+type MediaUpload struct {
+	Filename  string
+	Size      int64
+	VariantID string
+	AltText   string
+	SortOrder int
+}
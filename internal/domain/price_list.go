@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// PriceList representa uma tabela de preços nomeada, em uma moeda e (opcionalmente)
+// destinada a um segmento de clientes (ex.: "varejo", "atacado", "distribuidor").
+type PriceList struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name" example:"Atacado BRL" validate:"required,min=3,max=100"`
+	Currency        string    `json:"currency" example:"BRL" validate:"required,len=3"` // Código ISO 4217
+	CustomerSegment string    `json:"customer_segment,omitempty" example:"atacado" validate:"max=100"`
+	IsActive        bool      `json:"is_active"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// VariantPrice é o preço de uma variante em uma tabela de preços, válido em uma janela de tempo.
+// O valor é armazenado em unidades mínimas da moeda (ex.: centavos) para evitar erros de ponto flutuante.
+// ValidFrom/ValidTo nulos significam "desde sempre"/"sem data de término".
+type VariantPrice struct {
+	ID          string     `json:"id"`
+	PriceListID string     `json:"price_list_id"`
+	VariantID   string     `json:"variant_id" validate:"required,uuid"`
+	Amount      int64      `json:"amount" example:"4990" validate:"min=0"` // Em unidades mínimas (4990 = 49,90 BRL)
+	ValidFrom   *time.Time `json:"valid_from,omitempty"`
+	ValidTo     *time.Time `json:"valid_to,omitempty"` // Exclusivo
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// ResolvedPrice é o preço efetivo de uma variante em uma tabela de preços num dado instante.
+type ResolvedPrice struct {
+	VariantID   string     `json:"variant_id"`
+	PriceListID string     `json:"price_list_id"`
+	PriceID     string     `json:"price_id"`
+	Currency    string     `json:"currency" example:"BRL"`
+	Amount      int64      `json:"amount" example:"4990"`
+	Formatted   string     `json:"formatted" example:"49.90"` // Valor decimal, sem símbolo da moeda
+	At          time.Time  `json:"at"`
+	ValidFrom   *time.Time `json:"valid_from,omitempty"`
+	ValidTo     *time.Time `json:"valid_to,omitempty"`
+}
+
+// currencyMinorUnits mapeia as moedas aceitas para o número de casas decimais da unidade mínima (ISO 4217).
+var currencyMinorUnits = map[string]int{
+	"BRL": 2, "USD": 2, "EUR": 2, "GBP": 2, "ARS": 2, "MXN": 2, "COP": 2, "PEN": 2, "UYU": 2,
+	"CAD": 2, "AUD": 2, "CHF": 2, "CNY": 2, "PYG": 0, "CLP": 0, "JPY": 0, "KRW": 0,
+	"BHD": 3, "KWD": 3, "OMR": 3, "TND": 3,
+}
+
+// CurrencyMinorUnits retorna o número de casas decimais da moeda e se ela é suportada.
+func CurrencyMinorUnits(currency string) (int, bool) {
+	digits, ok := currencyMinorUnits[currency]
+	return digits, ok
+}
+
+// FormatMinorUnits converte um valor em unidades mínimas para a representação decimal da moeda
+// (ex.: 4990 BRL -> "49.90", 1500 JPY -> "1500"), sem passar por float64.
+func FormatMinorUnits(amount int64, currency string) string {
+	digits, ok := CurrencyMinorUnits(currency)
+	if !ok || digits == 0 {
+		return fmt.Sprintf("%d", amount)
+	}
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	raw := fmt.Sprintf("%0*d", digits+1, amount)
+	split := len(raw) - digits
+	return sign + raw[:split] + "." + raw[split:]
+}
@@ -8,28 +8,38 @@ import (
 // Contém informações essenciais e de metadados.
 type Product struct {
 	ID          string    `json:"id"`
-	SKU         string    `json:"sku"` // Stock Keeping Unit (código único de produto)
-	Name        string    `json:"name"`
+	SKU         string    `json:"sku" validate:"required"` // Stock Keeping Unit (código único de produto)
+	Name        string    `json:"name" validate:"required"`
 	Description string    `json:"description"`
-	Price       float64   `json:"price"`
+	Price       float64   `json:"price" validate:"required,min=0"`
 	IsActive    bool      `json:"is_active"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 
+	// Categoria e especificações (ex.: {"peso_kg": 0.2, "material": "Algodão"}), validadas pelo
+	// esquema da categoria (ver CategorySchema)
+	Category         string                 `json:"category,omitempty"`
+	CustomAttributes map[string]interface{} `json:"custom_attributes,omitempty"`
+
 	// Relações (serão gerenciadas por outras entidades/serviços)
 	// Variantes []Variant // Exemplo: Lista de tamanhos/cores
 	Variants []Variant `json:"variants"`
+	// Mídias do produto, em ordem de exibição (preenchidas apenas na busca por ID)
+	Media []ProductMedia `json:"media,omitempty"`
 }
 
 // Variant representa as variações de um Produto (e.g., cor, tamanho).
+// Uma variante é uma combinação de atributos (Attributes); Attribute/Value são o rótulo
+// derivado dessa combinação, mantidos para clientes que conhecem apenas um eixo.
 // O controle de estoque (StockLevels) será feito a nível de Variant.
 type Variant struct {
-	ID        string  `json:"id"`
-	ProductID string  `json:"product_id"`
-	Attribute string  `json:"attribute"` // Ex: "Cor"
-	Value     string  `json:"value"`     // Ex: "Vermelho"
-	Barcode   string  `json:"barcode"`
-	PriceDiff float64 `json:"price_diff"` // Ajuste de preço para esta variante
+	ID         string            `json:"id"`
+	ProductID  string            `json:"product_id"`
+	Attribute  string            `json:"attribute"`            // Ex: "Cor" (ou "Cor / Tamanho" com vários eixos)
+	Value      string            `json:"value"`                // Ex: "Vermelho" (ou "Vermelho / M")
+	Attributes map[string]string `json:"attributes,omitempty"` // Ex: {"Cor": "Vermelho", "Tamanho": "M"}
+	Barcode    string            `json:"barcode" validate:"required"`
+	PriceDiff  float64           `json:"price_diff" validate:"min=0"` // Ajuste de preço para esta variante
 }
 
 // --- Interfaces de Contrato (O CORAÇÃO DA ARQUITETURA LIMPA) ---
@@ -56,15 +66,58 @@ type ProductRepository interface {
 
 // --- Estruturas Auxiliares (Filtros e Contexto) ---
 
-// ProductFilter define os parâmetros de busca e paginação (RF 1.3).
+// ProductFilter define os parâmetros de busca, ordenação e paginação (RF 1.3).
+// Campos ponteiro são opcionais: nil significa que o filtro não foi informado.
 type ProductFilter struct {
 	Page       int
 	Limit      int
 	Name       string
 	SKU        string
 	ActiveOnly bool
+
+	// Ordenação (ver ProductSort*). Vazio usa a ordenação padrão (created_at DESC).
+	SortBy    string
+	SortOrder string
+
+	// Faixa de preço (inclusiva)
+	MinPrice *float64
+	MaxPrice *float64
+
+	// Faixas de data (inclusivas)
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+
+	// Filtros sobre as variantes do produto
+	HasVariants    *bool
+	Barcode        string
+	AttributeName  string // Ex: "Cor"
+	AttributeValue string // Ex: "Vermelho"
+
+	// Combinação exata de atributos de uma mesma variante (ex.: Cor=Vermelho e Tamanho=M)
+	Attributes map[string]string
+
+	// Categoria (sem diferenciar maiúsculas) e valores exatos de atributos personalizados
+	Category         string
+	CustomAttributes map[string]string
 }
 
+// Campos aceitos para ordenação da listagem de produtos.
+const (
+	ProductSortName      = "name"
+	ProductSortPrice     = "price"
+	ProductSortSKU       = "sku"
+	ProductSortCreatedAt = "created_at"
+	ProductSortUpdatedAt = "updated_at"
+)
+
+// Direções de ordenação.
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
 // Context é uma interface que encapsula o Go context.Context.
 // É usado para propagar o timeout e sinais de cancelamento pelas camadas.
 // Isso evita a dependência direta do pacote "context".
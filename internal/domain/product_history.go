@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ProductChangeType identifica a operação que originou uma versão do produto.
+type ProductChangeType string
+
+// Operações registradas no histórico de produtos.
+const (
+	ProductChangeCreate   ProductChangeType = "create"
+	ProductChangeImport   ProductChangeType = "import"
+	ProductChangeRevert   ProductChangeType = "revert"
+	ProductChangeVariants ProductChangeType = "generate_variants"
+
+	ProductChangeCustomAttributes ProductChangeType = "custom_attributes"
+)
+
+// FieldChange descreve a alteração de um campo entre duas versões.
+// Campos de variantes usam a forma "variants[<id>].<campo>"; variantes inteiras
+// adicionadas ou removidas aparecem como "variants[<id>]".
+type FieldChange struct {
+	Field string      `json:"field" example:"price"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// ProductVersion é uma versão do produto: o estado completo após a alteração (Snapshot),
+// os campos alterados em relação à versão anterior e quem fez a alteração.
+type ProductVersion struct {
+	ID            string            `json:"id"`
+	ProductID     string            `json:"product_id"`
+	Version       int               `json:"version" example:"3"`
+	ChangeType    ProductChangeType `json:"change_type" example:"import"`
+	Changes       []FieldChange     `json:"changes"`
+	Snapshot      Product           `json:"snapshot"`
+	ChangedBy     string            `json:"changed_by,omitempty"` // ID do usuário (claims do JWT); vazio se desconhecido
+	ChangedByRole string            `json:"changed_by_role,omitempty"`
+	RevertedTo    *int              `json:"reverted_to,omitempty"` // Versão restaurada, quando ChangeType = revert
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+// DiffProducts compara dois estados de um produto e retorna os campos alterados.
+// Um 'before' nil indica que não há versão anterior (todos os campos são considerados novos).
+func DiffProducts(before *Product, after Product) []FieldChange {
+	changes := []FieldChange{}
+	if before == nil {
+		before = &Product{}
+	}
+
+	addIfChanged := func(field string, old, new interface{}) {
+		if old != new {
+			changes = append(changes, FieldChange{Field: field, Old: old, New: new})
+		}
+	}
+	addIfChanged("sku", before.SKU, after.SKU)
+	addIfChanged("name", before.Name, after.Name)
+	addIfChanged("description", before.Description, after.Description)
+	addIfChanged("price", before.Price, after.Price)
+	addIfChanged("is_active", before.IsActive, after.IsActive)
+	addIfChanged("category", before.Category, after.Category)
+	// Atributos personalizados são comparados campo a campo ("custom_attributes.<nome>")
+	for _, name := range CustomAttributeNames(mergeCustomAttributes(before.CustomAttributes, after.CustomAttributes)) {
+		old, new := before.CustomAttributes[name], after.CustomAttributes[name]
+		if !reflect.DeepEqual(old, new) {
+			changes = append(changes, FieldChange{Field: "custom_attributes." + name, Old: old, New: new})
+		}
+	}
+
+	oldVariants := make(map[string]Variant, len(before.Variants))
+	for _, v := range before.Variants {
+		oldVariants[v.ID] = v
+	}
+	for _, v := range after.Variants {
+		key := fmt.Sprintf("variants[%s]", v.ID)
+		old, existed := oldVariants[v.ID]
+		if !existed {
+			changes = append(changes, FieldChange{Field: key, Old: nil, New: v})
+			continue
+		}
+		delete(oldVariants, v.ID)
+		addIfChanged(key+".attribute", old.Attribute, v.Attribute)
+		addIfChanged(key+".value", old.Value, v.Value)
+		addIfChanged(key+".barcode", old.Barcode, v.Barcode)
+		addIfChanged(key+".price_diff", old.PriceDiff, v.PriceDiff)
+	}
+	// Variantes que deixaram de existir, na ordem original
+	for _, v := range before.Variants {
+		if _, removed := oldVariants[v.ID]; removed {
+			changes = append(changes, FieldChange{Field: fmt.Sprintf("variants[%s]", v.ID), Old: v, New: nil})
+		}
+	}
+
+	return changes
+}
+
+// mergeCustomAttributes une as chaves de dois mapas de atributos personalizados.
+func mergeCustomAttributes(a, b map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(a)+len(b))
+	for k, v := range a {
+		merged[k] = v
+	}
+	for k, v := range b {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,50 @@
+package domain
+
+import "time"
+
+// ImportFormat identifica o formato do arquivo de importação de produtos.
+type ImportFormat string
+
+// Formatos de importação suportados.
+const (
+	ImportFormatCSV    ImportFormat = "csv"
+	ImportFormatNDJSON ImportFormat = "ndjson"
+)
+
+// ImportStatus representa o estado de um job de importação.
+type ImportStatus string
+
+// Estados possíveis de um job de importação.
+const (
+	ImportStatusPending   ImportStatus = "pending"
+	ImportStatusRunning   ImportStatus = "running"
+	ImportStatusCompleted ImportStatus = "completed"
+	ImportStatusFailed    ImportStatus = "failed"
+)
+
+// ImportRowError descreve uma falha de validação ou persistência em uma linha do arquivo.
+type ImportRowError struct {
+	Row     int    `json:"row" example:"12"`
+	SKU     string `json:"sku,omitempty" example:"CAM-001"`
+	Message string `json:"message" example:"O preço do produto deve ser um valor positivo."`
+}
+
+// ImportReport é o resultado (parcial ou final) de uma importação de produtos.
+// Em modo dry_run nada é gravado: Created/Updated indicam o que seria feito.
+type ImportReport struct {
+	JobID      string           `json:"job_id"`
+	Status     ImportStatus     `json:"status"`
+	Format     ImportFormat     `json:"format"`
+	DryRun     bool             `json:"dry_run"`
+	TotalRows  int              `json:"total_rows"`
+	Products   int              `json:"products"` // Produtos distintos (por SKU) processados
+	Created    int              `json:"created"`
+	Updated    int              `json:"updated"`
+	Failed     int              `json:"failed"`
+	Errors     []ImportRowError `json:"errors"`
+	Truncated  bool             `json:"errors_truncated"` // true se houve mais erros do que os reportados
+	Message    string           `json:"message,omitempty"`
+	StartedAt  time.Time        `json:"started_at"`
+	FinishedAt *time.Time       `json:"finished_at,omitempty"`
+	TenantID   string           `json:"-"` // Empresa que criou o job; apenas ela consulta o status
+}
@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+// RefreshToken é um refresh token emitido para uma sessão. Apenas o hash do token é persistido.
+// Cada login inicia uma família (FamilyID); a cada renovação o token usado é substituído
+// (ReplacedBy) por um novo da mesma família.
+type RefreshToken struct {
+	ID         string
+	UserID     string
+	FamilyID   string
+	TokenHash  string
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+	ReplacedBy string     // ID do token que substituiu este na rotação (vazio se ainda não usado)
+	RevokedAt  *time.Time // Preenchido no logout ou quando a família é revogada
+}
+
+// AuthTokens é a resposta do login e da renovação de tokens. Com o segundo fator ativo (ou
+// exigido pelo papel), o login retorna apenas o desafio (MFAToken), sem os tokens da sessão.
+type AuthTokens struct {
+	AccessToken  string `json:"access_token,omitempty"`
+	TokenType    string `json:"token_type,omitempty" example:"Bearer"`
+	ExpiresIn    int    `json:"expires_in,omitempty" example:"900"` // Validade do access token, em segundos
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Token        string `json:"token,omitempty"` // Mesmo valor de access_token (compatibilidade com clientes antigos)
+
+	MFARequired      bool     `json:"mfa_required,omitempty"`       // Informe o código em POST /v1/login/mfa
+	MFASetupRequired bool     `json:"mfa_setup_required,omitempty"` // O papel exige o segundo fator e ele ainda não foi cadastrado
+	MFAToken         string   `json:"mfa_token,omitempty"`          // Desafio de curta duração do segundo passo
+	RecoveryCodes    []string `json:"recovery_codes,omitempty"`     // Gerados ao concluir o cadastro no login
+}
+
+// RefreshTokenRequest representa o payload de entrada da renovação de tokens.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// RefreshTokenRepository define o contrato de persistência dos refresh tokens.
+type RefreshTokenRepository interface {
+	SaveRefreshToken(ctx Context, token RefreshToken) error
+	FindRefreshTokenByHash(ctx Context, tokenHash string) (RefreshToken, error)
+	// RotateRefreshToken marca o token como substituído por replacedBy, apenas se ele ainda não
+	// foi usado nem revogado. Retorna false se outra requisição já o usou.
+	RotateRefreshToken(ctx Context, id, replacedBy string) (bool, error)
+	RevokeRefreshTokenFamily(ctx Context, familyID string) error
+	// RevokeUserRefreshTokens revoga os refresh tokens ativos de todas as sessões do usuário.
+	RevokeUserRefreshTokens(ctx Context, userID string) error
+}
@@ -0,0 +1,75 @@
+package domain
+
+import "time"
+
+// Permission é uma permissão de operação no formato "recurso:ação".
+type Permission string
+
+// Permissões verificadas pelas rotas de escrita.
+const (
+	PermissionAll             Permission = "*"               // Todas as permissões (papel admin)
+	PermissionProductWrite    Permission = "product:write"   // Produtos, variantes, mídias e códigos de barras
+	PermissionProductImport   Permission = "product:import"  // Importação em lote de produtos
+	PermissionCatalogManage   Permission = "catalog:manage"  // Definições de atributos e esquemas de categoria
+	PermissionStockAdjust     Permission = "stock:adjust"    // Ajustes de estoque
+	PermissionStockConfigure  Permission = "stock:configure" // Unidades de medida das variantes
+	PermissionWarehouseManage Permission = "warehouse:manage"
+	PermissionPriceManage     Permission = "price:manage" // Tabelas de preços e preços por variante
+	PermissionExportRead      Permission = "export:read"  // Exportação do catálogo e do estoque
+)
+
+// PermissionInfo descreve uma permissão do catálogo.
+type PermissionInfo struct {
+	Name        Permission `json:"name" example:"stock:adjust"`
+	Description string     `json:"description" example:"Ajustar o nível de estoque"`
+}
+
+// Permissions é o catálogo das permissões que podem ser atribuídas a papéis.
+var Permissions = []PermissionInfo{
+	{PermissionProductWrite, "Criar produtos e alterar variantes, mídias, atributos personalizados e códigos de barras"},
+	{PermissionProductImport, "Importar produtos em lote e acompanhar as importações"},
+	{PermissionCatalogManage, "Gerenciar definições de atributos e esquemas de categoria"},
+	{PermissionStockAdjust, "Ajustar o nível de estoque"},
+	{PermissionStockConfigure, "Configurar as unidades de medida das variantes"},
+	{PermissionWarehouseManage, "Criar, alterar e remover armazéns"},
+	{PermissionPriceManage, "Gerenciar tabelas de preços e preços por variante"},
+	{PermissionExportRead, "Exportar o catálogo e os níveis de estoque"},
+}
+
+// IsKnownPermission indica se a permissão existe no catálogo (ou é o curinga '*').
+func IsKnownPermission(p Permission) bool {
+	if p == PermissionAll {
+		return true
+	}
+	for _, info := range Permissions {
+		if info.Name == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Role é um papel configurável: um conjunto de permissões atribuído aos usuários.
+type Role struct {
+	Name        string       `json:"name" example:"warehouse_staff"`
+	Description string       `json:"description" example:"Equipe de armazém"`
+	Permissions []Permission `json:"permissions" example:"stock:adjust"`
+	BuiltIn     bool         `json:"built_in"` // Papéis do sistema (admin, user, guest) não podem ser removidos
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// HasPermission indica se o papel concede a permissão.
+func (r Role) HasPermission(p Permission) bool {
+	for _, granted := range r.Permissions {
+		if granted == p || granted == PermissionAll {
+			return true
+		}
+	}
+	return false
+}
+
+// UserRoleAssignment representa o payload de atribuição de papel a um usuário.
+type UserRoleAssignment struct {
+	Role string `json:"role" example:"warehouse_staff" validate:"required"`
+}
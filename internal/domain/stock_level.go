@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // StockLevel representa o nível de estoque de uma variante específica em um armazém.
 // Inclui uma coluna 'version' para controle de concorrência otimista.
@@ -12,11 +15,22 @@ type StockLevel struct {
 	Version     int       `json:"version"` // Para Controle de Concorrência Otimista (OCC)
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Preenchidos quando a operação envolve unidades de medida: a quantidade em unidades-base,
+	// em formato decimal (ex.: "12.500" kg para Quantity = 12500 com 3 casas).
+	BaseUnit     string `json:"base_unit,omitempty" example:"un"`
+	BaseQuantity string `json:"base_quantity,omitempty" example:"24"`
 }
 
 // StockAdjustmentRequest é o payload esperado para a requisição de ajuste de estoque.
 type StockAdjustmentRequest struct {
 	VariantID   string `json:"variant_id" validate:"required,uuid"`
 	WarehouseID string `json:"warehouse_id" validate:"required,uuid"`
-	Delta       int    `json:"delta" validate:"required,numeric"` // Quantidade a ser adicionada/removida
+	Delta       int    `json:"delta"` // Quantidade a ser adicionada/removida (ou Quantity)
+
+	// Alternativas a Delta para ajustes em unidades de compra/venda: Quantity é decimal
+	// (ex.: 2 "cx", 1.5 "kg") e é convertida para unidades-base antes de ser gravada.
+	// Sem Unit, Delta e Quantity são interpretados na unidade-base.
+	Quantity json.Number `json:"quantity,omitempty" swaggertype:"number" example:"2"`
+	Unit     string      `json:"unit,omitempty" example:"cx"`
 }
@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+// Tenant é uma empresa cliente atendida pela mesma instalação do GoStock. Produtos, armazéns,
+// estoque, preços e usuários pertencem a um único tenant.
+type Tenant struct {
+	ID        string         `json:"id"`
+	Slug      string         `json:"slug" example:"acme"` // Identificador público, usado no header X-Tenant
+	Name      string         `json:"name" example:"ACME Distribuidora"`
+	Settings  TenantSettings `json:"settings"`
+	Disabled  bool           `json:"disabled"` // Empresas desativadas não fazem login nem renovam tokens
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// TenantSettings são as configurações de uma empresa.
+type TenantSettings struct {
+	DefaultCurrency   string `json:"default_currency" example:"BRL" validate:"required,len=3"` // Moeda padrão da empresa (ISO 4217)
+	Timezone          string `json:"timezone" example:"America/Sao_Paulo" validate:"required"` // Fuso horário dos relatórios e exportações
+	Locale            string `json:"locale" example:"pt-BR" validate:"required,max=35"`        // Idioma e formatação preferidos pelos clientes da API
+	AllowRegistration bool   `json:"allow_registration"`                                       // Aceita o registro público de usuários
+	MaxUsers          int    `json:"max_users" example:"50" validate:"min=0"`                  // Limite de usuários, incluindo contas de serviço (0: sem limite)
+}
+
+// TenantCreate representa o payload de criação de uma empresa.
+type TenantCreate struct {
+	Slug     string          `json:"slug" example:"acme" validate:"required,min=3,max=50"`
+	Name     string          `json:"name" example:"ACME Distribuidora" validate:"required,max=255"`
+	Settings *TenantSettings `json:"settings,omitempty"` // Ausente: configurações padrão
+}
+
+// TenantUpdate representa o payload de alteração de uma empresa; campos ausentes são mantidos.
+type TenantUpdate struct {
+	Name     *string         `json:"name,omitempty" validate:"max=255"`
+	Settings *TenantSettings `json:"settings,omitempty"`
+	Disabled *bool           `json:"disabled,omitempty"` // Apenas a operação da plataforma altera
+}
+
+// TenantRepository define o contrato de persistência das empresas.
+type TenantRepository interface {
+	CreateTenant(ctx Context, tenant Tenant) (Tenant, error)
+	FindTenant(ctx Context, id string) (Tenant, error)
+	FindTenantBySlug(ctx Context, slug string) (Tenant, error)
+	ListTenants(ctx Context) ([]Tenant, error)
+	UpdateTenant(ctx Context, tenant Tenant) (Tenant, error)
+	// CountTenantUsers conta os usuários da empresa (limite MaxUsers).
+	CountTenantUsers(ctx Context, id string) (int, error)
+}
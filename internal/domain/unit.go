@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// MaxUnitDecimalPlaces limita a precisão de variantes decimais (ex.: 3 casas = gramas para "kg").
+const MaxUnitDecimalPlaces = 6
+
+// DefaultBaseUnit é a unidade-base assumida para variantes sem unidades configuradas.
+const DefaultBaseUnit = "un"
+
+// UnitConversion define quantas unidades-base cabem em uma unidade de compra/venda
+// (ex.: "cx" = 12, "pallet" = 480). O fator é decimal em texto para evitar ponto flutuante.
+type UnitConversion struct {
+	Unit   string `json:"unit" example:"cx" validate:"required"`
+	Factor string `json:"factor" example:"12" validate:"required"`
+}
+
+// VariantUnits é a configuração de unidades de medida de uma variante.
+// O estoque é sempre armazenado em unidades-base; em variantes decimais (DecimalPlaces > 0),
+// em frações de 10^-DecimalPlaces da unidade-base (ex.: kg com 3 casas é armazenado em gramas).
+type VariantUnits struct {
+	VariantID     string           `json:"variant_id"`
+	BaseUnit      string           `json:"base_unit" example:"un" validate:"required,max=20"`
+	DecimalPlaces int              `json:"decimal_places" example:"0" validate:"min=0"` // 0 = variante discreta
+	Conversions   []UnitConversion `json:"conversions"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+}
+
+// DefaultVariantUnits retorna a configuração implícita de uma variante sem unidades cadastradas.
+func DefaultVariantUnits(variantID string) VariantUnits {
+	return VariantUnits{VariantID: variantID, BaseUnit: DefaultBaseUnit, Conversions: []UnitConversion{}}
+}
+
+// scale retorna 10^DecimalPlaces como número racional.
+func (u VariantUnits) scale() *big.Rat {
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(u.DecimalPlaces)), nil))
+}
+
+// factor retorna o fator de conversão da unidade para a unidade-base (1 para a própria unidade-base).
+func (u VariantUnits) factor(unit string) (*big.Rat, error) {
+	if unit == "" || unit == u.BaseUnit {
+		return big.NewRat(1, 1), nil
+	}
+	for _, c := range u.Conversions {
+		if c.Unit == unit {
+			f, ok := new(big.Rat).SetString(c.Factor)
+			if !ok {
+				return nil, fmt.Errorf("fator de conversão inválido para a unidade '%s'", unit)
+			}
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("a unidade '%s' não está configurada para esta variante (unidade-base: '%s')", unit, u.BaseUnit)
+}
+
+// ToStorage converte uma quantidade decimal na unidade informada para a quantidade armazenada
+// (unidades-base escaladas por 10^DecimalPlaces). A conversão deve ser exata: 1.5 "un" de uma
+// variante discreta, por exemplo, é rejeitado.
+func (u VariantUnits) ToStorage(quantity, unit string) (int64, error) {
+	q, ok := new(big.Rat).SetString(strings.TrimSpace(quantity))
+	if !ok {
+		return 0, fmt.Errorf("quantidade '%s' inválida", quantity)
+	}
+	f, err := u.factor(unit)
+	if err != nil {
+		return 0, err
+	}
+
+	stored := new(big.Rat).Mul(q, f)
+	stored.Mul(stored, u.scale())
+	if !stored.IsInt() {
+		if u.DecimalPlaces == 0 {
+			return 0, fmt.Errorf("a quantidade %s %s não corresponde a um número inteiro de '%s'", quantity, unit, u.BaseUnit)
+		}
+		return 0, fmt.Errorf("a quantidade %s %s excede a precisão de %d casas decimais de '%s'", quantity, unit, u.DecimalPlaces, u.BaseUnit)
+	}
+	if !stored.Num().IsInt64() {
+		return 0, fmt.Errorf("a quantidade %s %s é grande demais", quantity, unit)
+	}
+	return stored.Num().Int64(), nil
+}
+
+// FormatStorage converte a quantidade armazenada para a representação decimal na unidade-base.
+func (u VariantUnits) FormatStorage(stored int64) string {
+	return new(big.Rat).Quo(new(big.Rat).SetInt64(stored), u.scale()).FloatString(u.DecimalPlaces)
+}
+
+// Validate verifica a consistência da configuração: unidades únicas e fatores positivos,
+// representáveis na precisão da unidade-base.
+func (u VariantUnits) Validate() error {
+	if u.BaseUnit == "" || len(u.BaseUnit) > 20 {
+		return fmt.Errorf("a unidade-base deve ter entre 1 e 20 caracteres")
+	}
+	if u.DecimalPlaces < 0 || u.DecimalPlaces > MaxUnitDecimalPlaces {
+		return fmt.Errorf("'decimal_places' deve estar entre 0 e %d", MaxUnitDecimalPlaces)
+	}
+	seen := map[string]bool{u.BaseUnit: true}
+	for _, c := range u.Conversions {
+		if c.Unit == "" || len(c.Unit) > 20 {
+			return fmt.Errorf("o nome da unidade deve ter entre 1 e 20 caracteres")
+		}
+		if seen[c.Unit] {
+			return fmt.Errorf("a unidade '%s' está duplicada ou é a própria unidade-base", c.Unit)
+		}
+		seen[c.Unit] = true
+
+		f, ok := new(big.Rat).SetString(c.Factor)
+		if !ok || f.Sign() <= 0 {
+			return fmt.Errorf("o fator da unidade '%s' deve ser um número decimal positivo", c.Unit)
+		}
+		if !new(big.Rat).Mul(f, u.scale()).IsInt() {
+			return fmt.Errorf("o fator da unidade '%s' tem mais casas decimais do que a precisão da unidade-base (%d)", c.Unit, u.DecimalPlaces)
+		}
+	}
+	return nil
+}
@@ -4,12 +4,16 @@ import "time"
 
 // User representa a entidade do usuário no sistema.
 type User struct {
-	ID           string    `json:"id"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"` // Oculta o hash da senha no JSON de resposta
-	Role         UserRole  `json:"role"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID             string    `json:"id"`
+	TenantID       string    `json:"tenant_id"` // Empresa do usuário: todas as requisições dele ficam restritas a ela
+	Email          string    `json:"email"`
+	PasswordHash   string    `json:"-"` // Oculta o hash da senha no JSON de resposta
+	Role           UserRole  `json:"role"`
+	Disabled       bool      `json:"disabled"`        // Contas desativadas não fazem login e têm os tokens invalidados
+	EmailVerified  bool      `json:"email_verified"`  // Confirmado pelo link enviado no registro
+	ServiceAccount bool      `json:"service_account"` // Conta de integração: sem senha, acessa apenas com chaves de API
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
 // UserRole é um tipo string para representar o papel do usuário no sistema.
@@ -20,22 +24,100 @@ const (
 	RoleAdmin UserRole = "admin"
 	RoleUser  UserRole = "user"
 	RoleGuest UserRole = "guest"
+
+	// DefaultRole é o papel de toda conta criada pelo registro público. Papéis diferentes só são
+	// concedidos por um administrador (atribuição ou convite).
+	DefaultRole = RoleUser
 )
 
 // UserRegistration representa o payload de entrada para o registro.
 type UserRegistration struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+// AdminSetup representa o payload de criação do primeiro administrador com o token de setup.
+type AdminSetup struct {
+	SetupToken string `json:"setup_token" validate:"required"`
+	Email      string `json:"email" validate:"required,email"`
+	Password   string `json:"password" validate:"required"`
+}
+
+// InvitationRequest representa o payload de criação de um convite.
+type InvitationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required"`
+}
+
+// Invitation é um convite assinado: quem recebe o link cria a conta com o papel indicado.
+type Invitation struct {
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	Token     string    `json:"token"`
+	Link      string    `json:"link"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// InvitationAcceptance representa o payload de aceite de um convite.
+type InvitationAcceptance struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// PasswordForgot representa o payload do pedido de redefinição de senha.
+type PasswordForgot struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// PasswordReset representa o payload da redefinição de senha com o token recebido por e-mail.
+type PasswordReset struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+// UserList é uma página da listagem de usuários.
+type UserList struct {
+	Users []User `json:"users"`
+	Page  int    `json:"page"`
+	Limit int    `json:"limit"`
+	Total int    `json:"total"` // Total de usuários cadastrados
+}
+
+// PasswordChange representa o payload de troca da própria senha.
+type PasswordChange struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
 }
 
 // UserRepository define o contrato de persistência para a entidade User.
 type UserRepository interface {
 	Save(ctx Context, user User) (User, error)
 	FindByEmail(ctx Context, email string) (User, error)
+	FindByID(ctx Context, id string) (User, error)
+	List(ctx Context, limit, offset int) ([]User, int, error)
+	CountByRole(ctx Context, role UserRole) (int, error)
+	SetDisabled(ctx Context, id string, disabled bool) (User, error)
+	UpdatePassword(ctx Context, id string, passwordHash string) error
+	MarkEmailVerified(ctx Context, id string) error
+	Delete(ctx Context, id string) error
 }
 
 // UserService define o contrato de lógica de negócio para a entidade User.
 type UserService interface {
 	Register(ctx Context, registration UserRegistration) (User, error)
-	Login(ctx Context, email string, password string) (string, error)
+	Login(ctx Context, email string, password string) (AuthTokens, error)
+	RefreshTokens(ctx Context, refreshToken string) (AuthTokens, error)
+	Logout(ctx Context, userID, tokenID, sessionID string, expiresAt time.Time) error
+	ListUsers(ctx Context, page, limit int) (UserList, error)
+	GetUser(ctx Context, id string) (User, error)
+	SetUserDisabled(ctx Context, id string, disabled bool) (User, error)
+	DeleteUser(ctx Context, id string) error
+	ChangePassword(ctx Context, userID string, change PasswordChange) error
+	CreateAdmin(ctx Context, registration UserRegistration) (User, error)
+	SetupAdmin(ctx Context, setup AdminSetup) (User, error)
+	InviteUser(ctx Context, request InvitationRequest) (Invitation, error)
+	AcceptInvitation(ctx Context, acceptance InvitationAcceptance) (User, error)
+	ForgotPassword(ctx Context, email string) error
+	ResetPassword(ctx Context, reset PasswordReset) error
+	VerifyEmail(ctx Context, token string) error
 }
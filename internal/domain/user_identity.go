@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+// UserIdentity vincula um usuário a uma identidade de um provedor OpenID Connect. A identidade é
+// (Issuer, Subject): o e-mail só é usado para vincular uma conta local no primeiro login.
+type UserIdentity struct {
+	Issuer      string
+	Subject     string
+	UserID      string
+	Email       string
+	CreatedAt   time.Time
+	LastLoginAt time.Time
+}
+
+// OIDCAuthorization é o início do login OIDC: o endereço do provedor para o qual redirecionar o
+// navegador e o state que o provedor devolve no retorno.
+type OIDCAuthorization struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+}
+
+// OIDCCallback são os parâmetros recebidos do provedor no endereço de retorno.
+type OIDCCallback struct {
+	Code             string
+	State            string
+	Error            string
+	ErrorDescription string
+}
+
+// UserIdentityRepository define o contrato de persistência das identidades externas.
+type UserIdentityRepository interface {
+	FindIdentity(ctx Context, issuer, subject string) (UserIdentity, error)
+	// SaveIdentity grava a identidade ou, se já existir, atualiza o e-mail e o último login.
+	SaveIdentity(ctx Context, identity UserIdentity) error
+}
@@ -0,0 +1,72 @@
+package domain
+
+import "time"
+
+// UserMFA é o cadastro de autenticação em dois fatores (TOTP) do usuário. Secret é o segredo
+// cifrado; o cadastro só vale após a confirmação com o primeiro código (Enabled).
+type UserMFA struct {
+	UserID       string
+	Secret       string
+	Enabled      bool
+	LastUsedStep int64 // Último intervalo TOTP aceito (impede o reuso de um código)
+	CreatedAt    time.Time
+	EnabledAt    *time.Time
+}
+
+// MFAStatus é a situação do segundo fator do usuário.
+type MFAStatus struct {
+	Enabled                bool       `json:"enabled"`
+	Required               bool       `json:"required"` // O papel do usuário exige o segundo fator
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+}
+
+// MFAEnrollment é a resposta do início do cadastro: o segredo e a URI otpauth:// para o QR code.
+type MFAEnrollment struct {
+	Secret          string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	ProvisioningURI string `json:"provisioning_uri" example:"otpauth://totp/GoStock:maria@gostock.com?secret=JBSWY3DPEHPK3PXP&issuer=GoStock"`
+}
+
+// MFACode representa um código do aplicativo autenticador (ou de recuperação, quando aceito).
+type MFACode struct {
+	Code string `json:"code" example:"123456" validate:"required"`
+}
+
+// MFADisable representa o payload de desativação do segundo fator.
+type MFADisable struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"` // Código TOTP ou de recuperação
+}
+
+// RecoveryCodes são os códigos de recuperação gerados; só são exibidos uma vez.
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
+
+// MFAVerification representa o segundo passo do login: o desafio recebido e o código.
+type MFAVerification struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"` // Código TOTP ou de recuperação
+}
+
+// MFAChallengeToken representa o payload com apenas o desafio (início do cadastro no login).
+type MFAChallengeToken struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+}
+
+// MFARepository define o contrato de persistência do segundo fator.
+type MFARepository interface {
+	GetUserMFA(ctx Context, userID string) (UserMFA, error)
+	// SaveUserMFA grava ou substitui o cadastro do usuário.
+	SaveUserMFA(ctx Context, mfa UserMFA) error
+	// AdvanceMFAStep registra o intervalo TOTP usado, apenas se for posterior ao último aceito.
+	// Retorna false se o código (ou um mais recente) já foi usado.
+	AdvanceMFAStep(ctx Context, userID string, step int64) (bool, error)
+	// DeleteUserMFA remove o cadastro e os códigos de recuperação.
+	DeleteUserMFA(ctx Context, userID string) error
+	// ReplaceRecoveryCodes substitui os códigos de recuperação do usuário (apenas os hashes).
+	ReplaceRecoveryCodes(ctx Context, userID string, codeHashes []string) error
+	// UseRecoveryCode marca o código como usado, apenas se ainda não foi.
+	UseRecoveryCode(ctx Context, userID, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx Context, userID string) (int, error)
+}
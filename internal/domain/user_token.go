@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// UserTokenPurpose identifica o uso de um token de uso único enviado por e-mail.
+type UserTokenPurpose string
+
+const (
+	TokenPurposePasswordReset     UserTokenPurpose = "password_reset"
+	TokenPurposeEmailVerification UserTokenPurpose = "email_verification"
+)
+
+// UserToken é um token de uso único (redefinição de senha ou verificação de e-mail). Apenas o
+// hash do token é persistido; o token em texto puro só existe no e-mail enviado.
+type UserToken struct {
+	ID        string
+	UserID    string
+	Purpose   UserTokenPurpose
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UsedAt    *time.Time // Preenchido quando o token é usado ou invalidado
+}
+
+// UserTokenRepository define o contrato de persistência dos tokens de uso único.
+type UserTokenRepository interface {
+	SaveUserToken(ctx Context, token UserToken) error
+	FindUserTokenByHash(ctx Context, purpose UserTokenPurpose, tokenHash string) (UserToken, error)
+	// ConsumeUserToken marca o token como usado, apenas se ainda não foi. Retorna false se outra
+	// requisição já o usou.
+	ConsumeUserToken(ctx Context, id string) (bool, error)
+	// InvalidateUserTokens invalida os tokens ainda não usados do usuário para a finalidade.
+	InvalidateUserTokens(ctx Context, userID string, purpose UserTokenPurpose) error
+}
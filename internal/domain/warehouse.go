@@ -7,7 +7,14 @@ import (
 // Warehouse representa um armazém físico ou lógico no sistema.
 type Warehouse struct {
 	ID        string    `json:"id"`
-	Name      string    `json:"name"`
+	Name      string    `json:"name" validate:"required,min=3,max=100"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// UserWarehouses lista os armazéns atribuídos a um usuário. Usuários que não são administradores
+// só consultam e ajustam o estoque desses armazéns.
+type UserWarehouses struct {
+	UserID       string   `json:"user_id"`
+	WarehouseIDs []string `json:"warehouse_ids"`
+}
@@ -3,6 +3,7 @@ package errors
 import (
 	"fmt"
 	"net/http"
+	"time"
 )
 
 // AppError é a interface central para todos os erros customizados do GoStock.
@@ -17,8 +18,16 @@ type AppError interface {
 // --- Tipos de Erro Específicos (Erros de Domínio) ---
 
 // ValidationError representa falhas de validação de dados de entrada.
+// Fields detalha os campos inválidos, quando a validação os identifica (resposta "errors").
 type ValidationError struct {
-	Msg string
+	Msg    string
+	Fields []FieldError
+}
+
+// FieldError descreve a falha de validação de um campo do payload.
+type FieldError struct {
+	Field   string // Caminho do campo no JSON (ex.: "settings.timezone")
+	Message string
 }
 
 func (e *ValidationError) Error() string    { return fmt.Sprintf("Erro de Validação: %s", e.Msg) }
@@ -31,6 +40,11 @@ func NewValidationError(msg string) AppError {
 	return &ValidationError{Msg: msg}
 }
 
+// NewFieldValidationError cria um erro de validação com o detalhe dos campos inválidos.
+func NewFieldValidationError(msg string, fields ...FieldError) AppError {
+	return &ValidationError{Msg: msg, Fields: fields}
+}
+
 // NotFoundError representa a ausência de um recurso solicitado.
 type NotFoundError struct {
 	Msg string
@@ -61,6 +75,57 @@ func NewConflictError(msg string) AppError {
 	return &ConflictError{Msg: msg}
 }
 
+// TooManyRequestsError representa um limite de tentativas atingido (e.g., bloqueio de login).
+// RetryAfter indica quando o cliente pode tentar novamente (cabeçalho Retry-After).
+type TooManyRequestsError struct {
+	Msg        string
+	RetryAfter time.Duration
+}
+
+func (e *TooManyRequestsError) Error() string    { return fmt.Sprintf("Muitas tentativas: %s", e.Msg) }
+func (e *TooManyRequestsError) Category() string { return "TOO_MANY_REQUESTS" }
+func (e *TooManyRequestsError) HTTPStatus() int  { return http.StatusTooManyRequests } // 429
+func (e *TooManyRequestsError) Unwrap() error    { return nil }
+
+// NewTooManyRequestsError cria um erro de limite de tentativas.
+func NewTooManyRequestsError(msg string, retryAfter time.Duration) AppError {
+	return &TooManyRequestsError{Msg: msg, RetryAfter: retryAfter}
+}
+
+// MethodNotAllowedError representa um método HTTP não aceito pela rota.
+// Allow lista os métodos aceitos (cabeçalho Allow).
+type MethodNotAllowedError struct {
+	Msg   string
+	Allow string
+}
+
+func (e *MethodNotAllowedError) Error() string {
+	return fmt.Sprintf("Método não permitido: %s", e.Msg)
+}
+func (e *MethodNotAllowedError) Category() string { return "METHOD_NOT_ALLOWED" }
+func (e *MethodNotAllowedError) HTTPStatus() int  { return http.StatusMethodNotAllowed } // 405
+func (e *MethodNotAllowedError) Unwrap() error    { return nil }
+
+// NewMethodNotAllowedError cria um erro de método não permitido com os métodos aceitos.
+func NewMethodNotAllowedError(msg, allow string) AppError {
+	return &MethodNotAllowedError{Msg: msg, Allow: allow}
+}
+
+// PayloadTooLargeError representa um corpo de requisição acima do limite aceito.
+type PayloadTooLargeError struct {
+	Msg string
+}
+
+func (e *PayloadTooLargeError) Error() string    { return fmt.Sprintf("Payload muito grande: %s", e.Msg) }
+func (e *PayloadTooLargeError) Category() string { return "PAYLOAD_TOO_LARGE" }
+func (e *PayloadTooLargeError) HTTPStatus() int  { return http.StatusRequestEntityTooLarge } // 413
+func (e *PayloadTooLargeError) Unwrap() error    { return nil }
+
+// NewPayloadTooLargeError cria um erro de corpo de requisição acima do limite.
+func NewPayloadTooLargeError(msg string) AppError {
+	return &PayloadTooLargeError{Msg: msg}
+}
+
 // --- Tipos de Erro de Infraestrutura (Encapsulamento) ---
 
 // InternalError representa falhas inesperadas no servidor, serviço ou repositório.
@@ -100,16 +165,33 @@ func MapToHTTPStatus(err error) (int, string, string) {
 }
 
 // UnauthorizedError representa falha na autenticação (credenciais inválidas ou token ausente/inválido).
+// Com Forbidden, representa um usuário autenticado sem acesso ao recurso (403).
 type UnauthorizedError struct {
-	Msg string
+	Msg       string
+	Forbidden bool
 }
 
-func (e *UnauthorizedError) Error() string    { return fmt.Sprintf("Não Autorizado: %s", e.Msg) }
-func (e *UnauthorizedError) Category() string { return "UNAUTHORIZED" }
-func (e *UnauthorizedError) HTTPStatus() int  { return http.StatusUnauthorized } // 401
-func (e *UnauthorizedError) Unwrap() error    { return nil }
+func (e *UnauthorizedError) Error() string { return fmt.Sprintf("Não Autorizado: %s", e.Msg) }
+func (e *UnauthorizedError) Category() string {
+	if e.Forbidden {
+		return "FORBIDDEN"
+	}
+	return "UNAUTHORIZED"
+}
+func (e *UnauthorizedError) HTTPStatus() int {
+	if e.Forbidden {
+		return http.StatusForbidden // 403
+	}
+	return http.StatusUnauthorized // 401
+}
+func (e *UnauthorizedError) Unwrap() error { return nil }
 
 // NewUnauthorizedError cria um novo erro de autenticação/autorização falha.
 func NewUnauthorizedError(msg string) AppError {
 	return &UnauthorizedError{Msg: msg}
 }
+
+// NewForbiddenError cria um erro de acesso negado (403) para um usuário autenticado.
+func NewForbiddenError(msg string) AppError {
+	return &UnauthorizedError{Msg: msg, Forbidden: true}
+}
@@ -1,11 +1,15 @@
 package logger
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"os"
 	"strings" // Adicionado para strings.ToLower
 	"time"
+
+	"gostock/internal/domain"
+	"gostock/internal/pkg/requestid"
 )
 
 // Logger define a interface para logging estruturado.
@@ -16,6 +20,10 @@ type Logger interface {
 	Error(msg string, err error)
 	Fatal(msg string, err error)
 	Warn(msg string, fields map[string]interface{})
+
+	// WithContext retorna um logger que inclui em cada linha o ID da requisição do contexto
+	// (middleware.RequestID). Sem ID no contexto, retorna o próprio logger.
+	WithContext(ctx domain.Context) Logger
 }
 
 // LogEntry define a estrutura de um log para garantir o formato JSON.
@@ -23,6 +31,7 @@ type LogEntry struct {
 	Timestamp string                 `json:"timestamp"`
 	Level     string                 `json:"level"`
 	Message   string                 `json:"message"`
+	RequestID string                 `json:"request_id,omitempty"` // Correlaciona as linhas de uma mesma requisição
 	Fields    map[string]interface{} `json:"fields,omitempty"`
 	Error     string                 `json:"error,omitempty"`
 }
@@ -30,7 +39,8 @@ type LogEntry struct {
 // SimpleLogger é uma implementação concreta da interface Logger
 // que usa o pacote log nativo, mas com output JSON estruturado.
 type SimpleLogger struct {
-	logLevel string // e.g., "debug", "info", "error"
+	logLevel  string // e.g., "debug", "info", "error"
+	requestID string // Preenchido pelos loggers criados com WithContext
 }
 
 // NewLogger cria e retorna uma nova instância do Logger.
@@ -52,6 +62,7 @@ func (l *SimpleLogger) logf(level, msg string, fields map[string]interface{}, er
 		Timestamp: time.Now().Format(time.RFC3339),
 		Level:     level,
 		Message:   msg,
+		RequestID: l.requestID,
 	}
 
 	if fields != nil {
@@ -99,6 +110,18 @@ func (l *SimpleLogger) shouldLog(level string) bool {
 
 // Implementações da Interface Logger
 
+func (l *SimpleLogger) WithContext(ctx domain.Context) Logger {
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		return l
+	}
+	id, ok := requestid.FromContext(ctxGo)
+	if !ok || id == l.requestID {
+		return l
+	}
+	return &SimpleLogger{logLevel: l.logLevel, requestID: id}
+}
+
 func (l *SimpleLogger) Debug(msg string, fields map[string]interface{}) {
 	l.logf("DEBUG", msg, fields, nil)
 }
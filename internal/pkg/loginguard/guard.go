@@ -0,0 +1,298 @@
+package loginguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gostock/internal/domain"
+	"gostock/internal/pkg/cache"
+)
+
+// Chaves no cache. %s é o escopo (account/ip) e o alvo (e-mail normalizado ou IP).
+const (
+	failuresKey = "login:fail:%s:%s"     // Falhas na janela atual
+	lockKey     = "login:lock:%s:%s"     // Bloqueio ativo: instante (Unix) de liberação
+	lockoutsKey = "login:lockouts:%s:%s" // Bloqueios recentes (duração progressiva)
+)
+
+// Policy define os limites da proteção contra força bruta.
+type Policy struct {
+	MaxAttempts   int           // Falhas por conta na janela até o bloqueio
+	IPMaxAttempts int           // Falhas por IP na janela até o bloqueio (o IP pode testar várias contas)
+	Window        time.Duration // Janela de contagem das falhas
+	Lockout       time.Duration // Duração do primeiro bloqueio; dobra a cada reincidência
+	MaxLockout    time.Duration // Limite da duração e período em que as reincidências são lembradas
+	DelayStep     time.Duration // Atraso a partir da segunda falha; dobra a cada falha seguinte
+	MaxDelay      time.Duration // Limite do atraso
+}
+
+// DefaultPolicy retorna os limites padrão.
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts:   5,
+		IPMaxAttempts: 20,
+		Window:        15 * time.Minute,
+		Lockout:       15 * time.Minute,
+		MaxLockout:    24 * time.Hour,
+		DelayStep:     250 * time.Millisecond,
+		MaxDelay:      4 * time.Second,
+	}
+}
+
+// Attempt é o resultado do registro de uma falha de login.
+type Attempt struct {
+	Failures    int           // Falhas da conta na janela, incluindo esta
+	Delay       time.Duration // Atraso a aplicar antes de responder
+	LockedUntil time.Time     // Preenchido se esta falha bloqueou a conta ou o IP
+}
+
+// Guard conta as falhas de login por conta e por IP no cache, aplica atrasos progressivos e
+// bloqueios temporários. Os contadores expiram sozinhos (Window e duração do bloqueio).
+type Guard struct {
+	cache  cache.Client
+	policy Policy
+	now    func() time.Time
+}
+
+// NewGuard cria a proteção sobre o cliente de cache. Campos zerados da política recebem os
+// valores padrão.
+func NewGuard(cacheClient cache.Client, policy Policy) *Guard {
+	defaults := DefaultPolicy()
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = defaults.MaxAttempts
+	}
+	if policy.IPMaxAttempts <= 0 {
+		policy.IPMaxAttempts = defaults.IPMaxAttempts
+	}
+	if policy.Window <= 0 {
+		policy.Window = defaults.Window
+	}
+	if policy.Lockout <= 0 {
+		policy.Lockout = defaults.Lockout
+	}
+	if policy.MaxLockout < policy.Lockout {
+		policy.MaxLockout = max(defaults.MaxLockout, policy.Lockout)
+	}
+	if policy.DelayStep <= 0 {
+		policy.DelayStep = defaults.DelayStep
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = defaults.MaxDelay
+	}
+	return &Guard{cache: cacheClient, policy: policy, now: time.Now}
+}
+
+// Check retorna até quando o e-mail ou o IP estão bloqueados (zero se nenhum estiver).
+func (g *Guard) Check(ctx context.Context, email, ip string) (time.Time, error) {
+	var until time.Time
+	for _, target := range g.targets(email, ip) {
+		lockedUntil, err := g.lockedUntil(ctx, target.scope, target.subject)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if lockedUntil.After(until) {
+			until = lockedUntil
+		}
+	}
+	return until, nil
+}
+
+// RecordFailure conta uma falha de login para o e-mail e o IP. Ao atingir o limite, o alvo é
+// bloqueado e o contador de falhas recomeça.
+func (g *Guard) RecordFailure(ctx context.Context, email, ip string) (Attempt, error) {
+	var attempt Attempt
+	for _, target := range g.targets(email, ip) {
+		limit := g.policy.MaxAttempts
+		if target.scope == domain.LockoutScopeIP {
+			limit = g.policy.IPMaxAttempts
+		}
+
+		failures, err := g.increment(ctx, key(failuresKey, target.scope, target.subject), g.policy.Window)
+		if err != nil {
+			return Attempt{}, err
+		}
+		if target.scope == domain.LockoutScopeAccount {
+			attempt.Failures = failures
+		}
+		if failures < limit {
+			continue
+		}
+
+		until, err := g.lock(ctx, target.scope, target.subject)
+		if err != nil {
+			return Attempt{}, err
+		}
+		if until.After(attempt.LockedUntil) {
+			attempt.LockedUntil = until
+		}
+	}
+
+	if attempt.LockedUntil.IsZero() {
+		attempt.Delay = g.delay(attempt.Failures)
+	}
+	return attempt, nil
+}
+
+// RecordSuccess zera as falhas da conta após um login com a senha correta. As falhas do IP são
+// mantidas: um atacante com uma conta válida não deve conseguir zerá-las entre tentativas.
+func (g *Guard) RecordSuccess(ctx context.Context, email string) error {
+	return g.cache.Delete(ctx, key(failuresKey, domain.LockoutScopeAccount, normalizeEmail(email)))
+}
+
+// Status retorna a situação do e-mail ou IP.
+func (g *Guard) Status(ctx context.Context, scope domain.LockoutScope, subject string) (domain.LoginLockout, error) {
+	subject = normalize(scope, subject)
+	status := domain.LoginLockout{Scope: scope, Subject: subject}
+
+	failures, err := g.getInt(ctx, key(failuresKey, scope, subject))
+	if err != nil {
+		return domain.LoginLockout{}, err
+	}
+	lockouts, err := g.getInt(ctx, key(lockoutsKey, scope, subject))
+	if err != nil {
+		return domain.LoginLockout{}, err
+	}
+	until, err := g.lockedUntil(ctx, scope, subject)
+	if err != nil {
+		return domain.LoginLockout{}, err
+	}
+
+	status.FailedAttempts = failures
+	status.Lockouts = lockouts
+	if !until.IsZero() {
+		status.Locked = true
+		status.LockedUntil = &until
+	}
+	return status, nil
+}
+
+// Clear remove o bloqueio, as falhas e o histórico de bloqueios do e-mail ou IP.
+func (g *Guard) Clear(ctx context.Context, scope domain.LockoutScope, subject string) error {
+	subject = normalize(scope, subject)
+	for _, pattern := range []string{failuresKey, lockKey, lockoutsKey} {
+		if err := g.cache.Delete(ctx, key(pattern, scope, subject)); err != nil {
+			return fmt.Errorf("falha ao remover bloqueio de login: %w", err)
+		}
+	}
+	return nil
+}
+
+// lock bloqueia o alvo. A duração dobra a cada bloqueio lembrado (até MaxLockout).
+func (g *Guard) lock(ctx context.Context, scope domain.LockoutScope, subject string) (time.Time, error) {
+	lockouts, err := g.increment(ctx, key(lockoutsKey, scope, subject), g.policy.MaxLockout)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	duration := g.policy.Lockout
+	for i := 1; i < lockouts && duration < g.policy.MaxLockout; i++ {
+		duration *= 2
+	}
+	duration = min(duration, g.policy.MaxLockout)
+
+	until := g.now().Add(duration)
+	if err := g.cache.Set(ctx, key(lockKey, scope, subject), strconv.FormatInt(until.Unix(), 10), duration); err != nil {
+		return time.Time{}, fmt.Errorf("falha ao gravar bloqueio de login: %w", err)
+	}
+	if err := g.cache.Delete(ctx, key(failuresKey, scope, subject)); err != nil {
+		return time.Time{}, fmt.Errorf("falha ao zerar falhas de login: %w", err)
+	}
+	return until, nil
+}
+
+// lockedUntil lê o bloqueio ativo do alvo (zero se não houver).
+func (g *Guard) lockedUntil(ctx context.Context, scope domain.LockoutScope, subject string) (time.Time, error) {
+	value, err := g.cache.Get(ctx, key(lockKey, scope, subject))
+	if errors.Is(err, cache.ErrCacheMiss) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("falha ao consultar bloqueio de login: %w", err)
+	}
+	unix, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return g.now().Add(g.policy.Lockout), nil // Valor inesperado: na dúvida, mantém o bloqueio
+	}
+	until := time.Unix(unix, 0)
+	if !until.After(g.now()) {
+		return time.Time{}, nil
+	}
+	return until, nil
+}
+
+// increment soma 1 ao contador, criando-o com a validade informada (como o RateLimiter).
+func (g *Guard) increment(ctx context.Context, counterKey string, ttl time.Duration) (int, error) {
+	count, err := g.cache.GetInt(ctx, counterKey)
+	if errors.Is(err, cache.ErrCacheMiss) {
+		if err := g.cache.Set(ctx, counterKey, 1, ttl); err != nil {
+			return 0, fmt.Errorf("falha ao registrar tentativa de login: %w", err)
+		}
+		return 1, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("falha ao consultar tentativas de login: %w", err)
+	}
+	if err := g.cache.Incr(ctx, counterKey); err != nil {
+		return 0, fmt.Errorf("falha ao registrar tentativa de login: %w", err)
+	}
+	return count + 1, nil
+}
+
+// getInt lê um contador (zero se não existir).
+func (g *Guard) getInt(ctx context.Context, counterKey string) (int, error) {
+	count, err := g.cache.GetInt(ctx, counterKey)
+	if errors.Is(err, cache.ErrCacheMiss) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("falha ao consultar tentativas de login: %w", err)
+	}
+	return count, nil
+}
+
+// delay retorna o atraso progressivo: nenhum na primeira falha, DelayStep na segunda e o dobro
+// a cada falha seguinte, até MaxDelay.
+func (g *Guard) delay(failures int) time.Duration {
+	if failures < 2 {
+		return 0
+	}
+	delay := g.policy.DelayStep
+	for i := 2; i < failures && delay < g.policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, g.policy.MaxDelay)
+}
+
+type target struct {
+	scope   domain.LockoutScope
+	subject string
+}
+
+// targets retorna os alvos de uma tentativa: a conta sempre e o IP quando conhecido.
+func (g *Guard) targets(email, ip string) []target {
+	targets := []target{{scope: domain.LockoutScopeAccount, subject: normalizeEmail(email)}}
+	if ip != "" {
+		targets = append(targets, target{scope: domain.LockoutScopeIP, subject: ip})
+	}
+	return targets
+}
+
+func key(pattern string, scope domain.LockoutScope, subject string) string {
+	return fmt.Sprintf(pattern, scope, subject)
+}
+
+func normalize(scope domain.LockoutScope, subject string) string {
+	if scope == domain.LockoutScopeAccount {
+		return normalizeEmail(subject)
+	}
+	return strings.TrimSpace(subject)
+}
+
+// normalizeEmail evita contadores distintos para variações de caixa e espaços do mesmo e-mail.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,60 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gostock/internal/pkg/logger"
+)
+
+// LogMailer é o Mailer de desenvolvimento local: em vez de enviar, grava cada e-mail como um
+// arquivo .eml no diretório configurado ou, sem diretório, registra o conteúdo no log.
+type LogMailer struct {
+	dir    string
+	logger logger.Logger
+}
+
+// NewLogMailer cria o LogMailer. Com dir vazio, os e-mails vão apenas para o log.
+func NewLogMailer(dir string, logger logger.Logger) (*LogMailer, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, fmt.Errorf("falha ao criar diretório de e-mails: %w", err)
+		}
+	}
+	return &LogMailer{dir: dir, logger: logger}, nil
+}
+
+// Send grava ou registra a mensagem.
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	if m.dir == "" {
+		m.logger.WithContext(ctx).Info("E-mail (não enviado, ambiente local).", map[string]interface{}{
+			"to": msg.To, "subject": msg.Subject, "body": msg.Body,
+		})
+		return nil
+	}
+
+	content := fmt.Sprintf("To: %s\nSubject: %s\nDate: %s\n\n%s\n", msg.To, msg.Subject, time.Now().Format(time.RFC1123Z), msg.Body)
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), sanitize(msg.To))
+	path := filepath.Join(m.dir, name)
+	// Os e-mails trazem tokens de uso único: apenas o dono do processo lê os arquivos
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		return fmt.Errorf("falha ao gravar e-mail: %w", err)
+	}
+
+	m.logger.WithContext(ctx).Info("E-mail gravado em arquivo (ambiente local).", map[string]interface{}{"to": msg.To, "subject": msg.Subject, "file": path})
+	return nil
+}
+
+// sanitize mantém apenas caracteres seguros para nome de arquivo.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '@' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, s)
+}
@@ -0,0 +1,16 @@
+package mailer
+
+import "context"
+
+// Message é um e-mail em texto puro.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer envia e-mails. A implementação SMTP é usada em produção; a LogMailer, em
+// desenvolvimento local.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
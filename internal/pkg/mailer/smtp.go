@@ -0,0 +1,84 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPConfig configura o servidor de envio.
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string // Vazio: envio sem autenticação (ex.: relay interno)
+	Password string
+	From     string // Remetente, ex.: "GoStock <no-reply@gostock.local>"
+}
+
+// SMTPMailer envia e-mails por um servidor SMTP. A autenticação PLAIN só é usada com TLS
+// (STARTTLS), exceto em localhost, conforme net/smtp.
+type SMTPMailer struct {
+	cfg  SMTPConfig
+	from *mail.Address
+}
+
+// NewSMTPMailer valida o remetente e cria o SMTPMailer.
+func NewSMTPMailer(cfg SMTPConfig) (*SMTPMailer, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("host SMTP não configurado")
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("remetente inválido (MAIL_FROM): %w", err)
+	}
+	return &SMTPMailer{cfg: cfg, from: from}, nil
+}
+
+// Send envia a mensagem. O contexto não é propagado pelo net/smtp; o envio é feito em uma
+// goroutine e Send retorna quando o contexto é cancelado.
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if _, err := mail.ParseAddress(msg.To); err != nil {
+		return fmt.Errorf("destinatário inválido: %w", err)
+	}
+
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+
+	addr := net.JoinHostPort(m.cfg.Host, m.cfg.Port)
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, m.from.Address, []string{msg.To}, m.build(msg))
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("falha ao enviar e-mail: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// build monta a mensagem no formato RFC 5322.
+func (m *SMTPMailer) build(msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + m.from.String() + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
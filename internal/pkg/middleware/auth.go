@@ -2,10 +2,14 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"gostock/internal/domain" // Para usar a role do usuário
 	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/cache"
+	"gostock/internal/pkg/response"
 	"gostock/internal/pkg/token"
 	"net/http"
+	"time"
 )
 
 // UserClaimsKey é a chave usada para armazenar as claims do usuário no contexto.
@@ -15,13 +19,34 @@ type ContextKey int
 
 const (
 	UserClaimsKey ContextKey = iota
+	ClientIPKey              // IP de origem da requisição (proteção contra força bruta no login)
 )
 
 // UserClaims representa os dados do usuário extraídos do token JWT,
 // que serão anexados ao contexto.
 type UserClaims struct {
-	UserID string
-	Role   domain.UserRole
+	UserID    string
+	Role      domain.UserRole
+	TokenID   string              // jti do access token (usado no logout)
+	SessionID string              // Sessão (família de refresh tokens) que emitiu o token
+	ExpiresAt time.Time           // Expiração do access token
+	APIKeyID  string              // Preenchido quando a requisição foi autenticada por chave de API
+	Scopes    []domain.Permission // Escopos da chave de API (vazio para access tokens)
+	TenantID  string              // Empresa do usuário; escopa todos os dados acessados na requisição
+}
+
+// AllowsScope indica se a credencial libera a permissão. Access tokens não têm escopos: valem as
+// permissões do papel; chaves de API ficam limitadas aos escopos concedidos na criação.
+func (c UserClaims) AllowsScope(p domain.Permission) bool {
+	if c.APIKeyID == "" {
+		return true
+	}
+	for _, scope := range c.Scopes {
+		if scope == p || scope == domain.PermissionAll {
+			return true
+		}
+	}
+	return false
 }
 
 // TokenService define o contrato de validação necessário para o middleware.
@@ -29,17 +54,61 @@ type TokenService interface {
 	ValidateToken(tokenString string) (*token.CustomClaims, error)
 }
 
+// APIKeyAuthenticator define o contrato de validação das chaves de API (userservice.UserService).
+// Retorna a chave e o seu dono; chaves inválidas, expiradas ou revogadas resultam em UnauthorizedError.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (domain.APIKey, domain.User, error)
+}
+
+// APIKeyHeader é o header usado pelas integrações para enviar a chave de API.
+const APIKeyHeader = "X-API-Key"
+
 // NewAuthMiddleware cria uma função de middleware que valida um JWT e anexa as claims
-// (UserID e Role) ao contexto da requisição.
-func NewAuthMiddleware(tokenSvc TokenService) func(next http.HandlerFunc) http.HandlerFunc {
+// (UserID, Role e a empresa) ao contexto da requisição. Tokens revogados (logout ou sessão revogada)
+// são recusados com base na lista de revogação mantida no cache. Sem o header Authorization,
+// a chave de API do header X-API-Key é aceita (apiKeys nil desativa as chaves).
+func NewAuthMiddleware(tokenSvc TokenService, apiKeys APIKeyAuthenticator, cacheClient cache.Client) func(next http.HandlerFunc) http.HandlerFunc {
+	revocations := token.NewRevocationList(cacheClient)
+
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
 
+			// 0. Integrações: chave de API no header X-API-Key
+			if apiKey := r.Header.Get(APIKeyHeader); apiKey != "" && apiKeys != nil && r.Header.Get("Authorization") == "" {
+				key, owner, err := apiKeys.AuthenticateAPIKey(r.Context(), apiKey)
+				if err != nil {
+					var unauthorizedErr *apperror.UnauthorizedError
+					if errors.As(err, &unauthorizedErr) {
+						response.Error(w, r, err)
+						return
+					}
+					response.Error(w, r, apperror.NewInternalError("Não foi possível verificar a chave de API.", err))
+					return
+				}
+				userClaims := UserClaims{
+					UserID:   owner.ID,
+					Role:     owner.Role, // Papel atual do dono: mudanças de papel valem imediatamente
+					APIKeyID: key.ID,
+					Scopes:   key.Scopes,
+					TenantID: owner.TenantID,
+				}
+				if key.ExpiresAt != nil {
+					userClaims.ExpiresAt = *key.ExpiresAt
+				}
+				ctx, ok := withTenant(r.Context(), userClaims)
+				if !ok {
+					response.Error(w, r, apperror.NewForbiddenError("A chave de API não pertence à empresa informada."))
+					return
+				}
+				next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, UserClaimsKey, userClaims)))
+				return
+			}
+
 			// 1. Extrair o Token do Header Authorization: Bearer <token>
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" || len(authHeader) < 7 || authHeader[:7] != "Bearer " {
 				// Se o header estiver ausente ou malformado, retorna 401
-				http.Error(w, apperror.NewUnauthorizedError("Token de autorização ausente ou malformado.").Error(), http.StatusUnauthorized)
+				response.Error(w, r, apperror.NewUnauthorizedError("Token de autorização ausente ou malformado."))
 				return
 			}
 
@@ -49,18 +118,43 @@ func NewAuthMiddleware(tokenSvc TokenService) func(next http.HandlerFunc) http.H
 			claims, err := tokenSvc.ValidateToken(tokenString)
 			if err != nil {
 				// Se a validação falhar (expirado, inválido, etc.), retorna 401
-				http.Error(w, apperror.NewUnauthorizedError("Token inválido ou expirado.").Error(), http.StatusUnauthorized)
+				response.Error(w, r, apperror.NewUnauthorizedError("Token inválido ou expirado."))
 				return
 			}
 
-			// 3. Anexar Claims ao Contexto
+			// 3. Consultar a Lista de Revogação
+			revoked, err := revocations.IsRevoked(r.Context(), claims)
+			if err != nil {
+				// Sem o cache não é possível garantir que o token não foi revogado
+				response.Error(w, r, apperror.NewInternalError("Não foi possível verificar o token.", err))
+				return
+			}
+			if revoked {
+				response.Error(w, r, apperror.NewUnauthorizedError("Token revogado."))
+				return
+			}
+
+			// 4. Anexar Claims ao Contexto
 			userClaims := UserClaims{
-				UserID: claims.UserID,
-				Role:   domain.UserRole(claims.Role), // Converte a string da claim para domain.UserRole
+				UserID:    claims.UserID,
+				Role:      domain.UserRole(claims.Role), // Converte a string da claim para domain.UserRole
+				TokenID:   claims.ID,
+				SessionID: claims.SessionID,
+				TenantID:  claims.TenantID,
+			}
+			if claims.ExpiresAt != nil {
+				userClaims.ExpiresAt = claims.ExpiresAt.Time
+			}
+
+			// 5. Empresa do token: escopa os repositórios e não pode divergir do header X-Tenant
+			ctx, ok := withTenant(r.Context(), userClaims)
+			if !ok {
+				response.Error(w, r, apperror.NewForbiddenError("O token não pertence à empresa informada."))
+				return
 			}
 
 			// Cria um novo contexto com as claims anexadas
-			ctx := context.WithValue(r.Context(), UserClaimsKey, userClaims)
+			ctx = context.WithValue(ctx, UserClaimsKey, userClaims)
 
 			// Chama o próximo handler com o novo contexto
 			next.ServeHTTP(w, r.WithContext(ctx))
@@ -74,6 +168,23 @@ func GetUserClaimsFromContext(ctx context.Context) (UserClaims, bool) {
 	return claims, ok
 }
 
+// RequireSession recusa as chaves de API em rotas de gestão da própria conta (senha, segundo fator,
+// logout e as próprias chaves), que exigem o login interativo do usuário.
+func RequireSession(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := GetUserClaimsFromContext(r.Context())
+		if !ok {
+			response.Error(w, r, apperror.NewUnauthorizedError("Autorização necessária. Token não processado."))
+			return
+		}
+		if claims.APIKeyID != "" {
+			response.Error(w, r, apperror.NewForbiddenError("Esta operação não aceita chaves de API; faça login."))
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
 // RequiredRoles define a lista de funções que têm permissão para acessar o recurso.
 func PermissionMiddleware(requiredRoles ...domain.UserRole) func(next http.HandlerFunc) http.HandlerFunc {
 	return func(next http.HandlerFunc) http.HandlerFunc {
@@ -85,7 +196,7 @@ func PermissionMiddleware(requiredRoles ...domain.UserRole) func(next http.Handl
 			// Se o AuthMiddleware não foi executado ou falhou em anexar as claims,
 			// ou se houver uma falha interna, tratamos como não autorizado.
 			if !ok {
-				http.Error(w, apperror.NewUnauthorizedError("Autorização necessária. Token não processado.").Error(), http.StatusUnauthorized)
+				response.Error(w, r, apperror.NewUnauthorizedError("Autorização necessária. Token não processado."))
 				return
 			}
 
@@ -101,9 +212,15 @@ func PermissionMiddleware(requiredRoles ...domain.UserRole) func(next http.Handl
 				}
 			}
 
+			// Chaves de API só acessam as rotas restritas por papel com o escopo '*'
+			if isAuthorized && !claims.AllowsScope(domain.PermissionAll) {
+				response.Error(w, r, apperror.NewForbiddenError("Acesso negado. A chave de API não tem o escopo necessário: *."))
+				return
+			}
+
 			if !isAuthorized {
 				// Se a role do usuário não estiver na lista de roles permitidas (requiredRoles)
-				http.Error(w, apperror.NewUnauthorizedError("Acesso negado. Você não tem a permissão necessária.").Error(), http.StatusForbidden) // 403 Forbidden
+				response.Error(w, r, apperror.NewForbiddenError("Acesso negado. Você não tem a permissão necessária.")) // 403 Forbidden
 				return
 			}
 
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+)
+
+// ClientIP retorna o IP de origem da requisição. Como o RateLimiter, usa apenas o endereço da
+// conexão: cabeçalhos como X-Forwarded-For podem ser forjados pelo cliente.
+func ClientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+// WithClientIP anexa o IP de origem ao contexto, para uso na camada de serviço.
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, ClientIPKey, ip)
+}
+
+// GetClientIPFromContext recupera o IP de origem anexado por WithClientIP.
+func GetClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(ClientIPKey).(string)
+	return ip
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/response"
+	"net/http"
+)
+
+// PermissionChecker define o contrato para consultar as permissões de um papel (roleservice.Service).
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, role domain.UserRole, permission domain.Permission) (bool, error)
+}
+
+// RequirePermission exige que o papel do usuário autenticado conceda a permissão informada e, para
+// chaves de API, que a permissão esteja entre os escopos da chave.
+// Deve ser aplicado depois do middleware de autenticação, que anexa as claims ao contexto.
+func RequirePermission(checker PermissionChecker, permission domain.Permission) func(next http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+
+			// 1. Tentar extrair as Claims do contexto
+			claims, ok := GetUserClaimsFromContext(r.Context())
+			if !ok {
+				response.Error(w, r, apperror.NewUnauthorizedError("Autorização necessária. Token não processado."))
+				return
+			}
+
+			// 2. Consultar as permissões do papel
+			allowed, err := checker.HasPermission(r.Context(), claims.Role, permission)
+			if err != nil {
+				response.Error(w, r, apperror.NewInternalError("Não foi possível verificar as permissões.", err))
+				return
+			}
+			if !allowed {
+				response.Error(w, r, apperror.NewForbiddenError("Acesso negado. Permissão necessária: "+string(permission)+".")) // 403 Forbidden
+				return
+			}
+			// Chaves de API ficam limitadas aos próprios escopos, além das permissões do papel do dono
+			if !claims.AllowsScope(permission) {
+				response.Error(w, r, apperror.NewForbiddenError("Acesso negado. A chave de API não tem o escopo necessário: "+string(permission)+"."))
+				return
+			}
+
+			// 3. Permissão concedida: Chama o próximo handler
+			next.ServeHTTP(w, r)
+		}
+	}
+}
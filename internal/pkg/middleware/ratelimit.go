@@ -2,7 +2,9 @@ package middleware
 
 import (
 	"context"
+	apperror "gostock/internal/errors"
 	"gostock/internal/pkg/cache"
+	"gostock/internal/pkg/response"
 	"net"
 	"net/http"
 	"strconv"
@@ -10,10 +12,21 @@ import (
 )
 
 func RateLimiter(client cache.Client, limit int, duration time.Duration) func(http.Handler) http.Handler {
+	return ScopedRateLimiter(client, "", limit, duration)
+}
+
+// ScopedRateLimiter limita as requisições por IP com um contador próprio do escopo, independente do
+// limite geral (RateLimiter): rotas com tráfego característico, como a leitura de códigos de barras
+// pelos scanners, têm a sua cota sem consumir a da API.
+func ScopedRateLimiter(client cache.Client, scope string, limit int, duration time.Duration) func(http.Handler) http.Handler {
+	prefix := "rate-limit:"
+	if scope != "" {
+		prefix += scope + ":"
+	}
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			ip, _, _ := net.SplitHostPort(r.RemoteAddr)
-			key := "rate-limit:" + ip
+			key := prefix + ip
 			ctx := context.Background()
 
 			count, err := client.GetInt(ctx, key)
@@ -23,12 +36,12 @@ func RateLimiter(client cache.Client, limit int, duration time.Duration) func(ht
 				next.ServeHTTP(w, r)
 				return
 			} else if err != nil {
-				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				response.Error(w, r, apperror.NewInternalError("Não foi possível verificar o limite de requisições.", err))
 				return
 			}
 
 			if count >= limit {
-				http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+				response.Error(w, r, apperror.NewTooManyRequestsError("Limite de requisições excedido; aguarde para tentar novamente.", duration))
 				return
 			}
 
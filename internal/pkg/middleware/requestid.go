@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"net/http"
+
+	"gostock/internal/pkg/requestid"
+)
+
+// RequestID aceita o header X-Request-ID do cliente (ou gera um ID quando ausente ou inválido),
+// anexa o ID ao contexto, onde os loggers o leem (logger.WithContext), e o devolve no header da
+// resposta. Deve envolver todos os demais middlewares, para que as suas respostas de erro também o tragam.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := requestid.Resolve(r.Header.Get(requestid.Header))
+		w.Header().Set(requestid.Header, id)
+		next.ServeHTTP(w, r.WithContext(requestid.WithID(r.Context(), id)))
+	})
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/response"
+	"gostock/internal/pkg/tenant"
+)
+
+// TenantHeader é o header em que o cliente informa a empresa (slug) das requisições sem token,
+// como o catálogo público e o login. A query string ?tenant= também é aceita (ex.: links de e-mail).
+const TenantHeader = "X-Tenant"
+
+// TenantResolver resolve o slug da empresa (tenantservice.Service).
+type TenantResolver interface {
+	ResolveTenant(ctx context.Context, slug string) (domain.Tenant, error)
+}
+
+// NewTenantMiddleware anexa ao contexto a empresa informada no header X-Tenant. Sem o header, a
+// empresa vem do token (middleware de autenticação) ou, nas rotas públicas, é o tenant padrão.
+// Empresas inexistentes resultam em 404 e desativadas em 403.
+func NewTenantMiddleware(resolver TenantResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			slug := r.Header.Get(TenantHeader)
+			if slug == "" {
+				slug = r.URL.Query().Get("tenant")
+			}
+			if slug == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			found, err := resolver.ResolveTenant(r.Context(), slug)
+			if err != nil {
+				var notFoundErr *apperror.NotFoundError
+				if errors.As(err, &notFoundErr) {
+					response.Error(w, r, err)
+					return
+				}
+				response.Error(w, r, apperror.NewInternalError("Não foi possível identificar a empresa.", err))
+				return
+			}
+			if found.Disabled {
+				response.Error(w, r, apperror.NewForbiddenError("Empresa desativada."))
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(tenant.WithID(r.Context(), found.ID)))
+		})
+	}
+}
+
+// withTenant anexa a empresa da credencial ao contexto. Se a requisição informou outra empresa
+// (header X-Tenant), retorna false: um token nunca acessa os dados de outra empresa. A exceção são
+// os administradores da plataforma, que atuam na empresa informada (ex.: convidar o seu primeiro admin).
+func withTenant(ctx context.Context, claims UserClaims) (context.Context, bool) {
+	tenantID := claims.TenantID
+	if tenantID == "" {
+		tenantID = tenant.DefaultID // Tokens emitidos antes da separação por empresas
+	}
+	if requested, ok := tenant.FromContext(ctx); ok && requested != tenantID {
+		if tenant.IsPlatform(tenantID) && claims.Role == domain.RoleAdmin {
+			return ctx, true
+		}
+		return ctx, false
+	}
+	return tenant.WithID(ctx, tenantID), true
+}
+
+// RequirePlatform restringe a rota aos usuários do tenant padrão, que operam a plataforma
+// (cadastro de empresas e catálogo de papéis). Deve ser aplicado depois do middleware de autenticação.
+func RequirePlatform(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := GetUserClaimsFromContext(r.Context())
+		if !ok {
+			response.Error(w, r, apperror.NewUnauthorizedError("Autorização necessária. Token não processado."))
+			return
+		}
+		if !tenant.IsPlatform(claims.TenantID) {
+			response.Error(w, r, apperror.NewForbiddenError("Acesso negado. Operação restrita à administração da plataforma."))
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,312 @@
+// Package oidc implementa o login com um provedor OpenID Connect (fluxo authorization code com PKCE):
+// descoberta do provedor, troca do código pelo ID token e validação do ID token pelas chaves (JWKS)
+// publicadas pelo provedor.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"gostock/internal/pkg/token"
+)
+
+// jwksRefreshInterval limita a recarga das chaves do provedor quando chega um kid desconhecido.
+const jwksRefreshInterval = time.Minute
+
+// Config configura o cliente OIDC registrado no provedor.
+type Config struct {
+	IssuerURL    string   // Emissor (ex.: https://login.empresa.com/realms/gostock)
+	ClientID     string   // Identificador do cliente no provedor
+	ClientSecret string   // Segredo do cliente (vazio: cliente público, apenas PKCE)
+	RedirectURL  string   // Endereço de retorno registrado no provedor (/v1/oidc/callback)
+	Scopes       []string // Escopos pedidos; "openid" é sempre incluído
+	GroupsClaim  string   // Claim com os grupos do usuário (padrão "groups")
+}
+
+// Identity é a identidade externa extraída de um ID token válido.
+type Identity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Groups        []string
+}
+
+// discovery é o subconjunto usado do documento /.well-known/openid-configuration.
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Client é o cliente OIDC. A descoberta é feita no primeiro uso, para que a API possa iniciar
+// mesmo com o provedor fora do ar.
+type Client struct {
+	cfg  Config
+	http *http.Client
+
+	mu         sync.Mutex
+	provider   *discovery
+	keys       map[string]token.JWK
+	keysLoaded time.Time
+}
+
+// NewClient cria o cliente OIDC.
+func NewClient(cfg Config) *Client {
+	cfg.IssuerURL = strings.TrimRight(cfg.IssuerURL, "/")
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+	scopes := []string{"openid"}
+	for _, scope := range cfg.Scopes {
+		if scope != "openid" {
+			scopes = append(scopes, scope)
+		}
+	}
+	cfg.Scopes = scopes
+	return &Client{cfg: cfg, http: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Issuer retorna o emissor configurado (identifica o provedor nas identidades vinculadas).
+func (c *Client) Issuer() string {
+	return c.cfg.IssuerURL
+}
+
+// AuthCodeURL monta o endereço de autorização do provedor para o qual o navegador é redirecionado.
+// codeChallenge é o desafio PKCE (S256) do verificador guardado até o retorno.
+func (c *Client) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	provider, err := c.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {c.cfg.ClientID},
+		"redirect_uri":          {c.cfg.RedirectURL},
+		"scope":                 {strings.Join(c.cfg.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(provider.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return provider.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// Exchange troca o código de autorização pelo ID token (ainda não validado).
+func (c *Client) Exchange(ctx context.Context, code, codeVerifier string) (string, error) {
+	provider, err := c.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.cfg.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+	if c.cfg.ClientSecret == "" {
+		form.Set("client_id", c.cfg.ClientID) // Cliente público
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, provider.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("falha ao montar a requisição de token: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(c.cfg.ClientID), url.QueryEscape(c.cfg.ClientSecret))
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("falha ao contatar o provedor OIDC: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("resposta de token inválida (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || body.Error != "" {
+		return "", fmt.Errorf("provedor OIDC recusou o código (HTTP %d): %s %s", resp.StatusCode, body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return "", errors.New("resposta de token sem id_token")
+	}
+	return body.IDToken, nil
+}
+
+// VerifyIDToken valida a assinatura (JWKS do provedor), o emissor, o público (client_id), a
+// expiração e o nonce do ID token, e retorna a identidade.
+func (c *Client) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (Identity, error) {
+	provider, err := c.discover(ctx)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return c.publicKey(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithIssuer(provider.Issuer),
+		jwt.WithAudience(c.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(30*time.Second),
+	)
+	if err != nil {
+		return Identity{}, fmt.Errorf("ID token inválido: %w", err)
+	}
+
+	if got, _ := claims["nonce"].(string); nonce == "" || got != nonce {
+		return Identity{}, errors.New("ID token inválido: nonce não confere")
+	}
+	// Com mais de um público, o ID token precisa ter sido emitido para este cliente (azp)
+	if aud, _ := claims.GetAudience(); len(aud) > 1 {
+		if azp, _ := claims["azp"].(string); azp != c.cfg.ClientID {
+			return Identity{}, errors.New("ID token inválido: azp não confere")
+		}
+	}
+
+	identity := Identity{Issuer: provider.Issuer}
+	identity.Subject, _ = claims["sub"].(string)
+	identity.Email, _ = claims["email"].(string)
+	identity.Name, _ = claims["name"].(string)
+	identity.Groups = stringList(claims[c.cfg.GroupsClaim])
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		identity.EmailVerified = verified
+	case string: // Alguns provedores enviam "true" como texto
+		identity.EmailVerified = verified == "true"
+	}
+	if identity.Subject == "" {
+		return Identity{}, errors.New("ID token inválido: sem sub")
+	}
+	return identity, nil
+}
+
+// discover carrega (uma vez) o documento de descoberta do provedor.
+func (c *Client) discover(ctx context.Context) (*discovery, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.provider != nil {
+		return c.provider, nil
+	}
+
+	var doc discovery
+	if err := c.getJSON(ctx, c.cfg.IssuerURL+"/.well-known/openid-configuration", &doc); err != nil {
+		return nil, fmt.Errorf("falha na descoberta do provedor OIDC: %w", err)
+	}
+	if strings.TrimRight(doc.Issuer, "/") != c.cfg.IssuerURL {
+		return nil, fmt.Errorf("o provedor OIDC se identificou como '%s', e não '%s'", doc.Issuer, c.cfg.IssuerURL)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, errors.New("documento de descoberta OIDC incompleto")
+	}
+	c.provider = &doc
+	return c.provider, nil
+}
+
+// publicKey retorna a chave do provedor com o kid informado, recarregando o JWKS (no máximo uma
+// vez por minuto) quando o kid é desconhecido, o que acompanha a rotação de chaves do provedor.
+func (c *Client) publicKey(ctx context.Context, kid string) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if jwk, ok := c.lookupKey(kid); ok {
+		return jwk.PublicKey()
+	}
+	if time.Since(c.keysLoaded) < jwksRefreshInterval {
+		return nil, fmt.Errorf("chave '%s' desconhecida", kid)
+	}
+
+	var set token.JWKS
+	if err := c.getJSON(ctx, c.provider.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("falha ao carregar as chaves do provedor OIDC: %w", err)
+	}
+	c.keys = make(map[string]token.JWK, len(set.Keys))
+	for _, key := range set.Keys {
+		if key.Use == "" || key.Use == "sig" {
+			c.keys[key.Kid] = key
+		}
+	}
+	c.keysLoaded = time.Now()
+
+	if jwk, ok := c.lookupKey(kid); ok {
+		return jwk.PublicKey()
+	}
+	return nil, fmt.Errorf("chave '%s' desconhecida", kid)
+}
+
+// lookupKey busca a chave pelo kid; sem kid, só é aceito um JWKS com uma única chave.
+func (c *Client) lookupKey(kid string) (token.JWK, bool) {
+	if kid == "" && len(c.keys) == 1 {
+		for _, key := range c.keys {
+			return key, true
+		}
+	}
+	key, ok := c.keys[kid]
+	return key, ok
+}
+
+// getJSON faz um GET e decodifica a resposta JSON.
+func (c *Client) getJSON(ctx context.Context, endpoint string, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s retornou HTTP %d", endpoint, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(target)
+}
+
+// stringList converte uma claim de grupos (lista ou texto único) em []string.
+func stringList(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
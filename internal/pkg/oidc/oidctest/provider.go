@@ -0,0 +1,242 @@
+// Package oidctest implementa um provedor OpenID Connect mínimo para testes e desenvolvimento local:
+// descoberta, autorização (aprovada automaticamente), troca de código com PKCE e JWKS.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"gostock/internal/pkg/oidc"
+	"gostock/internal/pkg/token"
+)
+
+// User é a identidade que o provedor devolve ao aprovar a autorização.
+type User struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Groups        []string
+}
+
+// authorization é um código emitido e ainda não trocado.
+type authorization struct {
+	user          User
+	nonce         string
+	codeChallenge string
+	redirectURI   string
+	expiresAt     time.Time
+}
+
+// Provider é o provedor OIDC de teste. O usuário atual (SetUser) é autenticado sem interação.
+type Provider struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string // Vazio: aceita clientes públicos (apenas PKCE)
+
+	key    token.Key
+	server *httptest.Server
+
+	mu    sync.Mutex
+	user  *User
+	codes map[string]authorization
+}
+
+// NewProvider cria o provedor com uma chave RSA nova. O Provider é um http.Handler.
+func NewProvider(issuer, clientID, clientSecret string) (*Provider, error) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao gerar a chave do provedor: %w", err)
+	}
+	return &Provider{
+		Issuer:       issuer,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		key:          token.Key{ID: "oidctest-1", Method: jwt.SigningMethodRS256, Private: private, Public: &private.PublicKey},
+		codes:        map[string]authorization{},
+	}, nil
+}
+
+// Start inicia o provedor em um servidor HTTP local; o emissor é o endereço do servidor.
+// Encerre com Close.
+func Start(clientID, clientSecret string) (*Provider, error) {
+	provider, err := NewProvider("", clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+	provider.server = httptest.NewServer(provider)
+	provider.Issuer = provider.server.URL
+	return provider, nil
+}
+
+// Close encerra o servidor iniciado por Start.
+func (p *Provider) Close() {
+	if p.server != nil {
+		p.server.Close()
+	}
+}
+
+// SetUser define quem o provedor autentica nas próximas autorizações (nil: o usuário recusa).
+func (p *Provider) SetUser(user *User) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.user = user
+}
+
+// Login simula o navegador: abre o endereço de autorização e retorna o redirecionamento para o
+// endereço de retorno do cliente (com code e state, ou error).
+func (p *Provider) Login(authorizationURL string) (*url.URL, error) {
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authorizationURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		return nil, fmt.Errorf("autorização retornou HTTP %d", resp.StatusCode)
+	}
+	return url.Parse(resp.Header.Get("Location"))
+}
+
+// ServeHTTP atende os endpoints do provedor.
+func (p *Provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/.well-known/openid-configuration":
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"issuer":                                p.Issuer,
+			"authorization_endpoint":                p.Issuer + "/authorize",
+			"token_endpoint":                        p.Issuer + "/token",
+			"jwks_uri":                              p.Issuer + "/jwks",
+			"response_types_supported":              []string{"code"},
+			"subject_types_supported":               []string{"public"},
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+			"code_challenge_methods_supported":      []string{"S256"},
+		})
+	case "/jwks":
+		keys, _ := token.NewKeySet(p.key.ID, p.key)
+		writeJSON(w, http.StatusOK, keys.JWKS())
+	case "/authorize":
+		p.authorize(w, r)
+	case "/token":
+		p.token(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// authorize aprova a autorização do usuário atual e redireciona com o código.
+func (p *Provider) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirectURI, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || q.Get("redirect_uri") == "" {
+		http.Error(w, "redirect_uri inválido", http.StatusBadRequest)
+		return
+	}
+	if q.Get("client_id") != p.ClientID || q.Get("response_type") != "code" {
+		http.Error(w, "client_id ou response_type inválido", http.StatusBadRequest)
+		return
+	}
+
+	params := url.Values{"state": {q.Get("state")}}
+	p.mu.Lock()
+	switch {
+	case q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256":
+		params.Set("error", "invalid_request")
+		params.Set("error_description", "PKCE S256 obrigatório")
+	case p.user == nil:
+		params.Set("error", "access_denied")
+	default:
+		code := randomString()
+		p.codes[code] = authorization{
+			user:          *p.user,
+			nonce:         q.Get("nonce"),
+			codeChallenge: q.Get("code_challenge"),
+			redirectURI:   q.Get("redirect_uri"),
+			expiresAt:     time.Now().Add(time.Minute),
+		}
+		params.Set("code", code)
+	}
+	p.mu.Unlock()
+
+	redirectURI.RawQuery = params.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+// token troca o código pelo ID token, conferindo o cliente, o redirect_uri e o verificador PKCE.
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	clientID, clientSecret, hasBasic := r.BasicAuth()
+	if hasBasic {
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID = r.PostForm.Get("client_id")
+	}
+	if clientID != p.ClientID || (p.ClientSecret != "" && clientSecret != p.ClientSecret) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	p.mu.Lock()
+	auth, ok := p.codes[code]
+	delete(p.codes, code) // Códigos são de uso único
+	p.mu.Unlock()
+	if r.PostForm.Get("grant_type") != "authorization_code" || !ok || time.Now().After(auth.expiresAt) ||
+		auth.redirectURI != r.PostForm.Get("redirect_uri") || oidc.S256Challenge(r.PostForm.Get("code_verifier")) != auth.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            p.Issuer,
+		"sub":            auth.user.Subject,
+		"aud":            p.ClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          auth.nonce,
+		"email":          auth.user.Email,
+		"email_verified": auth.user.EmailVerified,
+		"name":           auth.user.Name,
+		"groups":         auth.user.Groups,
+	}
+	idToken := jwt.NewWithClaims(p.key.Method, claims)
+	idToken.Header["kid"] = p.key.ID
+	signed, err := idToken.SignedString(p.key.Private)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     signed,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func randomString() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
@@ -0,0 +1,65 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gostock/internal/pkg/cache"
+)
+
+// ErrUnknownState indica um state desconhecido, expirado ou já usado.
+var ErrUnknownState = errors.New("state OIDC desconhecido ou expirado")
+
+// PendingLogin guarda, entre o redirecionamento e o retorno do provedor, os valores que não podem
+// trafegar pelo navegador.
+type PendingLogin struct {
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	TenantID     string `json:"tenant_id,omitempty"` // Empresa em que o login foi iniciado (vazio: qualquer uma)
+}
+
+// StateStore guarda os logins pendentes no cache, indexados pelo state.
+type StateStore struct {
+	cache cache.Client
+}
+
+// NewStateStore cria o armazenamento de logins pendentes.
+func NewStateStore(cacheClient cache.Client) *StateStore {
+	return &StateStore{cache: cacheClient}
+}
+
+// Save guarda o login pendente pelo tempo informado.
+func (s *StateStore) Save(ctx context.Context, state string, pending PendingLogin, ttl time.Duration) error {
+	data, err := json.Marshal(pending)
+	if err != nil {
+		return err
+	}
+	return s.cache.Set(ctx, stateKey(state), string(data), ttl)
+}
+
+// Take retorna e remove o login pendente: cada state só pode ser usado uma vez.
+func (s *StateStore) Take(ctx context.Context, state string) (PendingLogin, error) {
+	data, err := s.cache.Get(ctx, stateKey(state))
+	if errors.Is(err, cache.ErrCacheMiss) {
+		return PendingLogin{}, ErrUnknownState
+	}
+	if err != nil {
+		return PendingLogin{}, err
+	}
+	if err := s.cache.Delete(ctx, stateKey(state)); err != nil {
+		return PendingLogin{}, err
+	}
+
+	var pending PendingLogin
+	if err := json.Unmarshal([]byte(data), &pending); err != nil {
+		return PendingLogin{}, fmt.Errorf("login OIDC pendente corrompido: %w", err)
+	}
+	return pending, nil
+}
+
+func stateKey(state string) string {
+	return "oidc:state:" + state
+}
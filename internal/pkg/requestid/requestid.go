@@ -0,0 +1,37 @@
+// Package requestid guarda no contexto o ID da requisição, usado para correlacionar a resposta
+// enviada ao cliente com as linhas de log de handlers, serviços e repositórios.
+package requestid
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+// Header é o header em que o cliente (ou o proxy) informa o ID e em que a API o devolve.
+const Header = "X-Request-ID"
+
+// validID limita os IDs aceitos do cliente a um tamanho e a caracteres seguros para logs e headers.
+var validID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+type contextKey struct{}
+
+// WithID retorna um contexto com o ID da requisição.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext retorna o ID da requisição, se houver.
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(contextKey{}).(string)
+	return id, ok && id != ""
+}
+
+// Resolve retorna o ID informado pelo cliente quando válido; caso contrário, gera um novo.
+func Resolve(received string) string {
+	if validID.MatchString(received) {
+		return received
+	}
+	return uuid.NewString()
+}
@@ -0,0 +1,125 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/requestid"
+)
+
+// ProblemContentType é o tipo de mídia das respostas de erro no formato RFC 7807.
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix forma o "type" dos problemas a partir da categoria do erro (ex.: urn:gostock:error:NOT_FOUND).
+const problemTypePrefix = "urn:gostock:error:"
+
+// Send envia a resposta de um handler: com err nil, data em JSON com o status de sucesso; caso contrário,
+// registra o erro no log (5xx como erro, 4xx em debug) e responde com Error.
+func Send(w http.ResponseWriter, r *http.Request, log logger.Logger, data interface{}, err error, successStatus int) {
+	if err != nil {
+		logError(log, r, err)
+		Error(w, r, err)
+		return
+	}
+
+	if data == nil {
+		w.WriteHeader(successStatus)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(successStatus)
+	if jsonErr := json.NewEncoder(w).Encode(data); jsonErr != nil && log != nil {
+		log.WithContext(r.Context()).Error("Falha ao codificar JSON de resposta", jsonErr)
+	}
+}
+
+// Error traduz o erro para o status HTTP e escreve o corpo padronizado. Handlers, middlewares e o
+// roteador usam esta função, de modo que todo erro da API tem o mesmo formato:
+//
+//   - application/json (padrão): {"code", "category", "message", "errors", "request_id"} (domain.ErrorResponse);
+//   - application/problem+json (RFC 7807), quando o cliente o aceita no header Accept: {"type", "title",
+//     "status", "detail", "instance", "code", "errors", "request_id"} (domain.ProblemDetails).
+//
+// "category"/"code" é o código estável para tratamento pelo cliente; "errors" detalha os campos
+// inválidos dos erros de validação. Os headers Retry-After (429) e Allow (405) são preenchidos.
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	status, category, message := apperror.MapToHTTPStatus(err)
+
+	var fields []domain.FieldError
+	var validationErr *apperror.ValidationError
+	if errors.As(err, &validationErr) {
+		for _, f := range validationErr.Fields {
+			fields = append(fields, domain.FieldError{Field: f.Field, Message: f.Message})
+		}
+	}
+	var tooManyErr *apperror.TooManyRequestsError
+	if errors.As(err, &tooManyErr) && tooManyErr.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(tooManyErr.RetryAfter.Seconds()))))
+	}
+	var notAllowedErr *apperror.MethodNotAllowedError
+	if errors.As(err, &notAllowedErr) {
+		w.Header().Set("Allow", notAllowedErr.Allow)
+	}
+
+	// O ID da requisição (middleware.RequestID) correlaciona a resposta com os logs
+	requestID, _ := requestid.FromContext(r.Context())
+
+	var body interface{}
+	if acceptsProblem(r) {
+		w.Header().Set("Content-Type", ProblemContentType)
+		body = domain.ProblemDetails{
+			Type:      problemTypePrefix + category,
+			Title:     http.StatusText(status),
+			Status:    status,
+			Detail:    message,
+			Instance:  r.URL.Path,
+			Code:      category,
+			Errors:    fields,
+			RequestID: requestID,
+		}
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		body = domain.ErrorResponse{
+			Code:      status,
+			Category:  category,
+			Message:   message,
+			Errors:    fields,
+			RequestID: requestID,
+		}
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// acceptsProblem indica se o cliente pediu o formato RFC 7807 no header Accept.
+func acceptsProblem(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		if mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part)); err == nil && mediaType == ProblemContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// logError registra erros de servidor com detalhes e rejeições de cliente em nível debug.
+func logError(log logger.Logger, r *http.Request, err error) {
+	if log == nil {
+		return
+	}
+	log = log.WithContext(r.Context())
+	status, category, _ := apperror.MapToHTTPStatus(err)
+	if status >= 500 {
+		log.Error(fmt.Sprintf("Erro de Servidor: %s", category), err)
+		return
+	}
+	log.Debug(fmt.Sprintf("Requisição rejeitada com status %d. Categoria: %s", status, category), map[string]interface{}{"path": r.URL.Path})
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gostock/internal/pkg/tenant"
+)
+
+// LocalStorage grava arquivos de mídia em um diretório local e os expõe sob uma URL base
+// (ex.: "/media" servido pela própria API, ou o endereço de um CDN que sincroniza o diretório).
+type LocalStorage struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocalStorage cria o armazenamento local, criando o diretório se necessário.
+func NewLocalStorage(dir, baseURL string) (*LocalStorage, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("diretório de mídia inválido: %w", err)
+	}
+	if err := os.MkdirAll(abs, 0o755); err != nil {
+		return nil, fmt.Errorf("falha ao criar diretório de mídia: %w", err)
+	}
+	return &LocalStorage{dir: abs, baseURL: strings.TrimRight(baseURL, "/")}, nil
+}
+
+// Save grava o conteúdo em name (caminho relativo, ex.: "<empresa>/<produto>/<arquivo>.jpg") e retorna a URL pública.
+// O arquivo é escrito em um temporário e renomeado, para que leitores nunca vejam um arquivo parcial.
+func (s *LocalStorage) Save(name string, src io.Reader) (string, error) {
+	target, err := s.resolve(name)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", fmt.Errorf("falha ao criar diretório de mídia: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("falha ao criar arquivo de mídia: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // Sem efeito após o Rename
+
+	if _, err := io.Copy(tmp, src); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("falha ao gravar arquivo de mídia: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return "", fmt.Errorf("falha ao gravar arquivo de mídia: %w", err)
+	}
+	return s.baseURL + "/" + filepath.ToSlash(name), nil
+}
+
+// Delete remove um arquivo gravado por Save. Arquivos inexistentes não são erro.
+func (s *LocalStorage) Delete(name string) error {
+	target, err := s.resolve(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("falha ao remover arquivo de mídia: %w", err)
+	}
+	return nil
+}
+
+// Handler serve os arquivos do diretório (sem listagem de diretórios) apenas para a empresa dona:
+// o primeiro segmento do caminho precisa ser o tenant da requisição (tenant.ID). Os arquivos
+// anteriores à separação por empresas ("<produto>/<arquivo>") pertencem ao tenant padrão. Deve ser
+// montado com http.StripPrefix no caminho correspondente à URL base.
+func (s *LocalStorage) Handler() http.Handler {
+	files := http.FileServer(http.Dir(s.dir))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "" || strings.HasSuffix(r.URL.Path, "/") || !ownedBy(r.URL.Path, tenant.ID(r.Context())) {
+			http.NotFound(w, r)
+			return
+		}
+		files.ServeHTTP(w, r)
+	})
+}
+
+// ownedBy indica se o arquivo pertence à empresa. O caminho é normalizado como no http.FileServer,
+// para que segmentos ".." não levem ao diretório de outra empresa.
+func ownedBy(name, tenantID string) bool {
+	parts := strings.Split(strings.TrimPrefix(path.Clean("/"+name), "/"), "/")
+	switch len(parts) {
+	case 3:
+		return parts[0] == tenantID
+	case 2:
+		return tenant.IsPlatform(tenantID)
+	}
+	return false
+}
+
+// resolve converte o nome relativo em caminho absoluto, recusando nomes que saiam do diretório.
+func (s *LocalStorage) resolve(name string) (string, error) {
+	target := filepath.Join(s.dir, filepath.FromSlash(name))
+	rel, err := filepath.Rel(s.dir, target)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("caminho de mídia inválido: %s", name)
+	}
+	return target, nil
+}
@@ -0,0 +1,57 @@
+package storage_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gostock/internal/pkg/storage"
+	"gostock/internal/pkg/tenant"
+)
+
+// TestHandler_ServesOnlyRequestTenant testa que cada empresa só lê os arquivos do seu diretório,
+// e que os arquivos anteriores à separação por empresas ficam com o tenant padrão.
+func TestHandler_ServesOnlyRequestTenant(t *testing.T) {
+	const tenantA, tenantB = "aaaaaaaa-0000-0000-0000-000000000001", "bbbbbbbb-0000-0000-0000-000000000002"
+	media, err := storage.NewLocalStorage(t.TempDir(), "/media")
+	if err != nil {
+		t.Fatalf("falha ao criar armazenamento: %v", err)
+	}
+	for _, name := range []string{tenantA + "/p1/foto.png", tenantB + "/p2/foto.png", "p0/legado.png"} {
+		if _, err := media.Save(name, strings.NewReader(name)); err != nil {
+			t.Fatalf("falha ao gravar %s: %v", name, err)
+		}
+	}
+	handler := http.StripPrefix("/media", media.Handler())
+
+	cases := []struct {
+		name     string
+		tenantID string
+		target   string
+		status   int
+	}{
+		{"arquivo da própria empresa", tenantA, "/media/" + tenantA + "/p1/foto.png", http.StatusOK},
+		{"arquivo de outra empresa", tenantA, "/media/" + tenantB + "/p2/foto.png", http.StatusNotFound},
+		{"outra empresa via ..", tenantA, "/media/" + tenantA + "/../" + tenantB + "/p2/foto.png", http.StatusNotFound},
+		{"sem tenant não lê arquivos de empresas", "", "/media/" + tenantA + "/p1/foto.png", http.StatusNotFound},
+		{"arquivo legado no tenant padrão", "", "/media/p0/legado.png", http.StatusOK},
+		{"arquivo legado em outra empresa", tenantA, "/media/p0/legado.png", http.StatusNotFound},
+		{"diretório", tenantA, "/media/" + tenantA + "/p1/", http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			if tc.tenantID != "" {
+				req = req.WithContext(tenant.WithID(req.Context(), tc.tenantID))
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+// Package tenant guarda no contexto a empresa (tenant) da requisição. Os repositórios leem o tenant
+// do contexto e filtram todas as consultas por ele, de modo que uma empresa nunca lê nem altera os
+// dados de outra.
+package tenant
+
+import "context"
+
+// DefaultID é o tenant padrão: recebe os dados existentes antes da separação por empresas e as
+// requisições sem tenant (ex.: catálogo público sem o header X-Tenant). Os administradores deste
+// tenant operam a plataforma (cadastro de empresas e papéis).
+const DefaultID = "00000000-0000-0000-0000-000000000001"
+
+type contextKey struct{}
+
+// WithID retorna um contexto com o tenant informado.
+func WithID(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, tenantID)
+}
+
+// FromContext retorna o tenant definido explicitamente no contexto (token, chave de API ou header).
+func FromContext(ctx context.Context) (string, bool) {
+	tenantID, ok := ctx.Value(contextKey{}).(string)
+	return tenantID, ok && tenantID != ""
+}
+
+// ID retorna o tenant do contexto ou, sem tenant definido, o tenant padrão.
+func ID(ctx context.Context) string {
+	if tenantID, ok := FromContext(ctx); ok {
+		return tenantID
+	}
+	return DefaultID
+}
+
+// IsPlatform indica se o tenant é o da operação da plataforma (tenant padrão).
+func IsPlatform(tenantID string) bool {
+	return tenantID == "" || tenantID == DefaultID
+}
+
+// Optional retorna o tenant definido no contexto ou nil (NULL no SQL) quando não há tenant. Usado
+// nas consultas de contas, que valem para todas as empresas quando a requisição ainda não tem
+// empresa (login, renovação de tokens, redefinição de senha): o usuário é que determina o tenant.
+func Optional(ctx context.Context) interface{} {
+	if tenantID, ok := FromContext(ctx); ok {
+		return tenantID
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package token
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	// APIKeyPrefix identifica as chaves de API do GoStock (ex.: em varreduras de segredos vazados).
+	APIKeyPrefix = "gsk_"
+
+	apiKeyIDBytes     = 6  // Parte pública, usada para localizar a chave
+	apiKeySecretBytes = 32 // Parte secreta
+)
+
+// NewAPIKey gera uma chave de API no formato gsk_<id>_<segredo>. Retorna a chave completa (entregue
+// apenas ao cliente), o prefixo público gsk_<id> e o hash SHA-256 que deve ser persistido.
+func NewAPIKey() (key, prefix, hash string, err error) {
+	id := make([]byte, apiKeyIDBytes)
+	secret := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", "", fmt.Errorf("falha ao gerar chave de API: %w", err)
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", fmt.Errorf("falha ao gerar chave de API: %w", err)
+	}
+	prefix = APIKeyPrefix + hex.EncodeToString(id)
+	key = prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	return key, prefix, HashAPIKey(key), nil
+}
+
+// ParseAPIKey extrai o prefixo público da chave. ok é falso se a chave não tiver o formato esperado.
+func ParseAPIKey(key string) (prefix string, ok bool) {
+	if !strings.HasPrefix(key, APIKeyPrefix) {
+		return "", false
+	}
+	// O segredo (base64url) pode conter "_"; o separador é o primeiro após o ID hexadecimal
+	i := len(APIKeyPrefix) + 2*apiKeyIDBytes
+	if len(key) <= i+1 || key[i] != '_' || strings.Contains(key[len(APIKeyPrefix):i], "_") {
+		return "", false
+	}
+	return key[:i], true
+}
+
+// HashAPIKey retorna o hash SHA-256 (hex) da chave completa, no mesmo formato dos refresh tokens.
+func HashAPIKey(key string) string {
+	return HashRefreshToken(key)
+}
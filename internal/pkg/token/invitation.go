@@ -0,0 +1,63 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// invitationAudience distingue os convites dos access tokens: ValidateToken recusa tokens com
+// audiência, e ValidateInvitation exige esta.
+const invitationAudience = "GoStock-Invitation"
+
+// InvitationClaims são as informações de um convite assinado: o e-mail convidado e o papel
+// e a empresa que a conta terá ao ser criada.
+type InvitationClaims struct {
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+	TenantID string `json:"tid,omitempty"` // Ausente nos convites antigos: tenant padrão
+	jwt.RegisteredClaims
+}
+
+// GenerateInvitation assina um convite para o e-mail, o papel e a empresa informados, válido por
+// expiry. O convite usa as mesmas chaves dos access tokens (e a mesma rotação).
+func (s *Service) GenerateInvitation(email, role, tenantID string, expiry time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(expiry)
+	claims := InvitationClaims{
+		Email:    email,
+		Role:     role,
+		TenantID: tenantID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    "GoStock-API",
+			Audience:  jwt.ClaimStrings{invitationAudience},
+		},
+	}
+
+	signed, err := s.sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// ValidateInvitation valida a assinatura e a validade do convite e retorna as suas claims.
+func (s *Service) ValidateInvitation(tokenString string) (*InvitationClaims, error) {
+	claims := &InvitationClaims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, s.verificationKey, jwt.WithAudience(invitationAudience))
+	if err != nil {
+		return nil, fmt.Errorf("convite inválido: %w", err)
+	}
+	if !token.Valid {
+		return nil, errors.New("convite não é válido")
+	}
+	return claims, nil
+}
@@ -6,29 +6,34 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 // TokenService define o contrato para manipulação de JWTs.
 type TokenService interface {
-	GenerateToken(userID string, userRole string) (string, error)
+	GenerateToken(userID string, userRole string, tenantID string, sessionID string) (string, error)
 	ValidateToken(tokenString string) (*CustomClaims, error)
+	Expiry() time.Duration
 }
 
 // CustomClaims define as informações específicas que queremos armazenar no JWT.
 // É obrigatório incorporar jwt.RegisteredClaims.
 type CustomClaims struct {
-	UserID string `json:"user_id"`
-	Role   string `json:"role"`
+	UserID    string `json:"user_id"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid,omitempty"` // Família de refresh tokens (sessão) que emitiu o token
+	TenantID  string `json:"tid,omitempty"` // Empresa do usuário (ausente nos tokens antigos: tenant padrão)
 	jwt.RegisteredClaims
 }
 
 // Service implementa a interface TokenService
 type Service struct {
-	secretKey []byte
+	secretKey []byte  // Segredo HMAC (HS256); com chaves assimétricas, só existe após AcceptLegacyHMAC
+	keys      *KeySet // Chaves assimétricas (RS256/ES256/EdDSA); nil quando se usa apenas o segredo
 	expiry    time.Duration
 }
 
-// NewService cria uma nova instância do serviço Token.
+// NewService cria uma nova instância do serviço Token, assinando com HMAC (HS256).
 func NewService(secretKey string, expiry time.Duration) *Service {
 	return &Service{
 		secretKey: []byte(secretKey),
@@ -36,12 +41,48 @@ func NewService(secretKey string, expiry time.Duration) *Service {
 	}
 }
 
+// NewServiceWithKeys cria o serviço Token assinando com a chave ativa do conjunto. As demais
+// chaves continuam verificando tokens até serem retiradas da configuração. Tokens HS256 são
+// recusados, a menos que AcceptLegacyHMAC seja chamado.
+func NewServiceWithKeys(keys *KeySet, expiry time.Duration) *Service {
+	return &Service{
+		keys:   keys,
+		expiry: expiry,
+	}
+}
+
+// AcceptLegacyHMAC volta a aceitar tokens HS256 assinados com secretKey, emitidos antes da
+// migração para chaves assimétricas. Deve ficar ativo apenas até esses tokens expirarem: quem
+// conhece o segredo consegue forjar tokens para qualquer usuário.
+func (s *Service) AcceptLegacyHMAC(secretKey string) {
+	s.secretKey = []byte(secretKey)
+}
+
+// JWKS retorna as chaves públicas usadas na verificação (vazio quando se usa apenas HMAC).
+func (s *Service) JWKS() JWKS {
+	if s.keys == nil {
+		return JWKS{Keys: []JWK{}}
+	}
+	return s.keys.JWKS()
+}
+
+// Expiry retorna a validade dos access tokens emitidos.
+func (s *Service) Expiry() time.Duration {
+	return s.expiry
+}
+
 // GenerateToken cria um novo JWT assinado contendo o ID e a Role do usuário.
-func (s *Service) GenerateToken(userID string, userRole string) (string, error) {
+// tenantID é a empresa do usuário, que escopa todos os dados acessados com o token.
+// sessionID identifica a sessão (família de refresh tokens) e permite revogar o token no logout.
+// Cada token recebe um ID único (jti) usado pela lista de revogação.
+func (s *Service) GenerateToken(userID string, userRole string, tenantID string, sessionID string) (string, error) {
 	claims := CustomClaims{
-		UserID: userID,
-		Role:   userRole,
+		UserID:    userID,
+		Role:      userRole,
+		SessionID: sessionID,
+		TenantID:  tenantID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.expiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -50,10 +91,23 @@ func (s *Service) GenerateToken(userID string, userRole string) (string, error)
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return s.sign(claims)
+}
 
-	// Assina o token com a chave secreta
-	tokenString, err := token.SignedString(s.secretKey)
+// sign assina as claims com a chave ativa (kid no cabeçalho) ou, sem chaves assimétricas, com HS256.
+func (s *Service) sign(claims jwt.Claims) (string, error) {
+	var tokenString string
+	var err error
+	if s.keys != nil {
+		// Assina com a chave ativa; o kid no cabeçalho indica a chave pública de verificação
+		token := jwt.NewWithClaims(s.keys.active.Method, claims)
+		token.Header["kid"] = s.keys.active.ID
+		tokenString, err = token.SignedString(s.keys.active.Private)
+	} else {
+		// Assina o token com a chave secreta
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		tokenString, err = token.SignedString(s.secretKey)
+	}
 	if err != nil {
 		return "", fmt.Errorf("falha ao assinar o token: %w", err)
 	}
@@ -65,13 +119,7 @@ func (s *Service) GenerateToken(userID string, userRole string) (string, error)
 func (s *Service) ValidateToken(tokenString string) (*CustomClaims, error) {
 	claims := &CustomClaims{}
 
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		// Verifica se o método de assinatura é o esperado (HS256)
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("método de assinatura inesperado: %v", token.Header["alg"])
-		}
-		return s.secretKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, claims, s.verificationKey)
 
 	if err != nil {
 		// Trata erros comuns de JWT, como token expirado ou inválido
@@ -81,7 +129,36 @@ func (s *Service) ValidateToken(tokenString string) (*CustomClaims, error) {
 	if !token.Valid {
 		return nil, errors.New("token não é válido")
 	}
+	// Access tokens não têm audiência; tokens com audiência (ex.: convites) não autenticam requisições
+	if len(claims.Audience) > 0 {
+		return nil, errors.New("token não é um access token")
+	}
 
 	// O claims já foi preenchido durante o ParseWithClaims
 	return claims, nil
 }
+
+// verificationKey escolhe a chave de verificação pelo kid do cabeçalho. O algoritmo do token
+// precisa ser o da chave, o que impede a troca de algoritmo (ex.: HS256 com a chave pública).
+func (s *Service) verificationKey(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		// Verifica se o método de assinatura é o esperado (HS256) e se o segredo está configurado
+		if len(s.secretKey) == 0 {
+			return nil, fmt.Errorf("método de assinatura inesperado: %v", token.Header["alg"])
+		}
+		return s.secretKey, nil
+	}
+
+	if s.keys == nil {
+		return nil, fmt.Errorf("método de assinatura inesperado: %v", token.Header["alg"])
+	}
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keys.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("chave de assinatura desconhecida: %q", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("método de assinatura inesperado para a chave %q: %v", kid, token.Header["alg"])
+	}
+	return key.Public, nil
+}
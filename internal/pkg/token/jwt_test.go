@@ -0,0 +1,181 @@
+package token_test
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+
+	"gostock/internal/pkg/token"
+)
+
+// headerOf lê o cabeçalho do token sem verificar a assinatura.
+func headerOf(t *testing.T, tokenString string) map[string]interface{} {
+	t.Helper()
+	parsed, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
+	if err != nil {
+		t.Fatalf("falha ao ler o token: %v", err)
+	}
+	return parsed.Header
+}
+
+// testClaims são claims válidas de access token para os tokens forjados nos testes.
+func testClaims() token.CustomClaims {
+	return token.CustomClaims{
+		UserID: "user-1",
+		Role:   "admin",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+}
+
+// TestValidateToken_KeyRotation testa a rotação: tokens assinados com a chave anterior continuam
+// válidos enquanto a chave pública estiver configurada, e os novos tokens usam o kid da nova chave.
+func TestValidateToken_KeyRotation(t *testing.T) {
+	oldKey, newKey := newECKey(t), newRSAKey(t)
+
+	before, err := token.LoadKeySet("k2024="+writeKeyPEM(t, oldKey, false), "k2024")
+	if err != nil {
+		t.Fatalf("falha ao carregar chaves: %v", err)
+	}
+	oldToken, err := token.NewServiceWithKeys(before, time.Minute).GenerateToken("user-1", "admin", "tenant-1", "session-1")
+	if err != nil {
+		t.Fatalf("falha ao gerar token: %v", err)
+	}
+	assert.Equal(t, "k2024", headerOf(t, oldToken)["kid"])
+
+	// Após a rotação, a chave antiga fica apenas com a parte pública
+	after, err := token.LoadKeySet("k2025="+writeKeyPEM(t, newKey, false)+",k2024="+writeKeyPEM(t, oldKey, true), "k2025")
+	if err != nil {
+		t.Fatalf("falha ao carregar chaves: %v", err)
+	}
+	svc := token.NewServiceWithKeys(after, time.Minute)
+
+	claims, err := svc.ValidateToken(oldToken)
+	assert.NoError(t, err, "Token da chave anterior deve continuar válido durante a rotação")
+	if assert.NotNil(t, claims) {
+		assert.Equal(t, "user-1", claims.UserID)
+		assert.Equal(t, "tenant-1", claims.TenantID)
+	}
+
+	newToken, err := svc.GenerateToken("user-1", "admin", "tenant-1", "session-1")
+	assert.NoError(t, err)
+	header := headerOf(t, newToken)
+	assert.Equal(t, "k2025", header["kid"])
+	assert.Equal(t, "RS256", header["alg"])
+	_, err = svc.ValidateToken(newToken)
+	assert.NoError(t, err)
+
+	// Retirada a chave antiga, os seus tokens deixam de valer
+	retired, err := token.LoadKeySet("k2025="+writeKeyPEM(t, newKey, false), "k2025")
+	if err != nil {
+		t.Fatalf("falha ao carregar chaves: %v", err)
+	}
+	_, err = token.NewServiceWithKeys(retired, time.Minute).ValidateToken(oldToken)
+	assert.Error(t, err)
+}
+
+// TestValidateToken_UnknownKid testa que tokens com kid ausente ou desconhecido são recusados,
+// mesmo quando assinados com o algoritmo da chave ativa.
+func TestValidateToken_UnknownKid(t *testing.T) {
+	keys, err := token.LoadKeySet("k1="+writeKeyPEM(t, newECKey(t), false), "k1")
+	if err != nil {
+		t.Fatalf("falha ao carregar chaves: %v", err)
+	}
+	svc := token.NewServiceWithKeys(keys, time.Minute)
+
+	stranger := newECKey(t)
+	for _, kid := range []interface{}{"k9", nil} {
+		forged := jwt.NewWithClaims(jwt.SigningMethodES256, testClaims())
+		if kid != nil {
+			forged.Header["kid"] = kid
+		}
+		tokenString, err := forged.SignedString(stranger)
+		if err != nil {
+			t.Fatalf("falha ao assinar token: %v", err)
+		}
+
+		_, err = svc.ValidateToken(tokenString)
+		assert.Error(t, err, "Token com kid %v deve ser recusado", kid)
+	}
+}
+
+// TestValidateToken_AlgorithmMismatch testa que o algoritmo do token precisa ser o da chave do kid:
+// um ES256 apontando para uma chave RSA e um HS256 assinado com a chave pública são recusados.
+func TestValidateToken_AlgorithmMismatch(t *testing.T) {
+	rsaKey, ecKey := newRSAKey(t), newECKey(t)
+	keys, err := token.LoadKeySet("rsa="+writeKeyPEM(t, rsaKey, false), "rsa")
+	if err != nil {
+		t.Fatalf("falha ao carregar chaves: %v", err)
+	}
+	svc := token.NewServiceWithKeys(keys, time.Minute)
+
+	t.Run("ES256 com kid de chave RSA", func(t *testing.T) {
+		forged := jwt.NewWithClaims(jwt.SigningMethodES256, testClaims())
+		forged.Header["kid"] = "rsa"
+		tokenString, err := forged.SignedString(ecKey)
+		if err != nil {
+			t.Fatalf("falha ao assinar token: %v", err)
+		}
+
+		_, err = svc.ValidateToken(tokenString)
+		assert.Error(t, err)
+	})
+
+	t.Run("HS256 com a chave pública como segredo", func(t *testing.T) {
+		publicDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+		if err != nil {
+			t.Fatalf("falha ao serializar chave pública: %v", err)
+		}
+		forged := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+		forged.Header["kid"] = "rsa"
+		tokenString, err := forged.SignedString(publicDER)
+		if err != nil {
+			t.Fatalf("falha ao assinar token: %v", err)
+		}
+
+		_, err = svc.ValidateToken(tokenString)
+		assert.Error(t, err)
+	})
+
+	t.Run("RS256 sem chaves assimétricas", func(t *testing.T) {
+		forged := jwt.NewWithClaims(jwt.SigningMethodRS256, testClaims())
+		tokenString, err := forged.SignedString(rsaKey)
+		if err != nil {
+			t.Fatalf("falha ao assinar token: %v", err)
+		}
+
+		_, err = token.NewService("segredo", time.Minute).ValidateToken(tokenString)
+		assert.Error(t, err)
+	})
+}
+
+// TestValidateToken_LegacyHMAC testa que, com chaves assimétricas, tokens HS256 só são aceitos
+// com a opção explícita AcceptLegacyHMAC, e que um segredo diferente é recusado.
+func TestValidateToken_LegacyHMAC(t *testing.T) {
+	legacy, err := token.NewService("segredo", time.Minute).GenerateToken("user-1", "admin", "tenant-1", "session-1")
+	if err != nil {
+		t.Fatalf("falha ao gerar token: %v", err)
+	}
+	keys, err := token.LoadKeySet("k1="+writeKeyPEM(t, newECKey(t), false), "k1")
+	if err != nil {
+		t.Fatalf("falha ao carregar chaves: %v", err)
+	}
+
+	svc := token.NewServiceWithKeys(keys, time.Minute)
+	_, err = svc.ValidateToken(legacy)
+	assert.Error(t, err, "Sem AcceptLegacyHMAC, tokens HS256 devem ser recusados")
+
+	svc.AcceptLegacyHMAC("segredo")
+	_, err = svc.ValidateToken(legacy)
+	assert.NoError(t, err)
+
+	other := token.NewServiceWithKeys(keys, time.Minute)
+	other.AcceptLegacyHMAC("outro-segredo")
+	_, err = other.ValidateToken(legacy)
+	assert.Error(t, err)
+}
@@ -0,0 +1,258 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Key é uma chave de assinatura identificada por kid. Chaves apenas com a parte pública
+// (Private nil) servem somente para verificar tokens já emitidos, durante uma rotação.
+type Key struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private crypto.Signer
+	Public  crypto.PublicKey
+}
+
+// KeySet reúne as chaves aceitas na verificação e a chave ativa, usada para assinar.
+type KeySet struct {
+	active *Key
+	keys   map[string]*Key
+}
+
+// NewKeySet cria o conjunto de chaves. A chave ativa deve existir e ter a parte privada.
+func NewKeySet(activeID string, keys ...Key) (*KeySet, error) {
+	set := &KeySet{keys: make(map[string]*Key, len(keys))}
+	for i := range keys {
+		k := keys[i]
+		if k.ID == "" {
+			return nil, errors.New("toda chave precisa de um kid")
+		}
+		if _, dup := set.keys[k.ID]; dup {
+			return nil, fmt.Errorf("kid '%s' repetido", k.ID)
+		}
+		set.keys[k.ID] = &k
+	}
+
+	active, ok := set.keys[activeID]
+	if !ok {
+		return nil, fmt.Errorf("a chave ativa '%s' não está entre as chaves configuradas", activeID)
+	}
+	if active.Private == nil {
+		return nil, fmt.Errorf("a chave ativa '%s' precisa da chave privada", activeID)
+	}
+	set.active = active
+	return set, nil
+}
+
+// LoadKeySet carrega as chaves a partir da especificação "kid=arquivo.pem,kid2=arquivo2.pem".
+// Cada arquivo PEM pode conter uma chave privada (PKCS#8, PKCS#1 ou SEC 1) ou apenas a
+// chave pública (PKIX ou PKCS#1).
+func LoadKeySet(spec, activeID string) (*KeySet, error) {
+	var keys []Key
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kid, path, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(kid) == "" || strings.TrimSpace(path) == "" {
+			return nil, fmt.Errorf("entrada de chave inválida '%s' (use kid=arquivo.pem)", entry)
+		}
+		key, err := LoadKeyFile(strings.TrimSpace(kid), strings.TrimSpace(path))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("nenhuma chave configurada")
+	}
+	return NewKeySet(activeID, keys...)
+}
+
+// LoadKeyFile lê uma chave PEM e deduz o algoritmo: RSA (RS256), ECDSA P-256/P-384/P-521
+// (ES256/ES384/ES512) ou Ed25519 (EdDSA).
+func LoadKeyFile(kid, path string) (Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Key{}, fmt.Errorf("falha ao ler a chave '%s': %w", kid, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, fmt.Errorf("o arquivo da chave '%s' não contém um bloco PEM", kid)
+	}
+
+	key := Key{ID: kid}
+	if signer, err := parsePrivateKey(block.Bytes); err == nil {
+		key.Private = signer
+		key.Public = signer.Public()
+	} else if pub, err := parsePublicKey(block.Bytes); err == nil {
+		key.Public = pub
+	} else {
+		return Key{}, fmt.Errorf("o arquivo da chave '%s' não contém uma chave privada ou pública suportada", kid)
+	}
+
+	if key.Method, err = signingMethodFor(key.Public); err != nil {
+		return Key{}, fmt.Errorf("chave '%s': %w", kid, err)
+	}
+	return key, nil
+}
+
+// parsePrivateKey tenta os formatos PKCS#8, PKCS#1 (RSA) e SEC 1 (EC).
+func parsePrivateKey(der []byte) (crypto.Signer, error) {
+	if k, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		if signer, ok := k.(crypto.Signer); ok {
+			return signer, nil
+		}
+		return nil, errors.New("tipo de chave privada não suportado")
+	}
+	if k, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return k, nil
+	}
+	return x509.ParseECPrivateKey(der)
+}
+
+// parsePublicKey tenta os formatos PKIX e PKCS#1 (RSA).
+func parsePublicKey(der []byte) (crypto.PublicKey, error) {
+	if k, err := x509.ParsePKIXPublicKey(der); err == nil {
+		return k, nil
+	}
+	return x509.ParsePKCS1PublicKey(der)
+}
+
+// signingMethodFor retorna o algoritmo JWT correspondente ao tipo da chave pública.
+func signingMethodFor(pub crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		if k.N.BitLen() < 2048 {
+			return nil, errors.New("chaves RSA devem ter ao menos 2048 bits")
+		}
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return jwt.SigningMethodES256, nil
+		case elliptic.P384():
+			return jwt.SigningMethodES384, nil
+		case elliptic.P521():
+			return jwt.SigningMethodES512, nil
+		}
+		return nil, errors.New("curva ECDSA não suportada")
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	}
+	return nil, fmt.Errorf("tipo de chave %T não suportado", pub)
+}
+
+// --- JWKS ---
+
+// JWK é a representação pública de uma chave no formato JSON Web Key (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS é o documento publicado em /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS retorna as chaves públicas do conjunto (ativa e em rotação), ordenadas por kid.
+func (s *KeySet) JWKS() JWKS {
+	set := JWKS{Keys: make([]JWK, 0, len(s.keys))}
+	for _, k := range s.keys {
+		set.Keys = append(set.Keys, k.jwk())
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+// jwk converte a chave pública para JWK.
+func (k *Key) jwk() JWK {
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwk := JWK{Kid: k.ID, Use: "sig", Alg: k.Method.Alg()}
+
+	switch pub := k.Public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = b64(pub.N.Bytes())
+		jwk.E = b64(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = pub.Curve.Params().Name
+		jwk.X = b64(pub.X.FillBytes(make([]byte, size)))
+		jwk.Y = b64(pub.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = b64(pub)
+	}
+	return jwk
+}
+
+// PublicKey converte a JWK na chave pública correspondente (usado na verificação de tokens de
+// emissores externos, como provedores OpenID Connect).
+func (j JWK) PublicKey() (crypto.PublicKey, error) {
+	decode := base64.RawURLEncoding.DecodeString
+	switch j.Kty {
+	case "RSA":
+		n, errN := decode(j.N)
+		e, errE := decode(j.E)
+		if errN != nil || errE != nil || len(n) == 0 || len(e) == 0 {
+			return nil, fmt.Errorf("JWK RSA '%s' inválida", j.Kid)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("curva '%s' da JWK '%s' não suportada", j.Crv, j.Kid)
+		}
+		x, errX := decode(j.X)
+		y, errY := decode(j.Y)
+		if errX != nil || errY != nil {
+			return nil, fmt.Errorf("JWK EC '%s' inválida", j.Kid)
+		}
+		pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, fmt.Errorf("JWK EC '%s' inválida", j.Kid)
+		}
+		return pub, nil
+	case "OKP":
+		x, err := decode(j.X)
+		if err != nil || j.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("JWK OKP '%s' inválida", j.Kid)
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("tipo de chave '%s' da JWK '%s' não suportado", j.Kty, j.Kid)
+}
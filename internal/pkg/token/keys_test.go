@@ -0,0 +1,161 @@
+package token_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gostock/internal/pkg/token"
+)
+
+// writeKeyPEM grava a chave em um arquivo PEM temporário: a privada (PKCS#8) ou, com publicOnly,
+// apenas a pública (PKIX), como nas chaves mantidas só para verificação durante uma rotação.
+func writeKeyPEM(t *testing.T, signer crypto.Signer, publicOnly bool) string {
+	t.Helper()
+	var block *pem.Block
+	if publicOnly {
+		der, err := x509.MarshalPKIXPublicKey(signer.Public())
+		if err != nil {
+			t.Fatalf("falha ao serializar chave pública: %v", err)
+		}
+		block = &pem.Block{Type: "PUBLIC KEY", Bytes: der}
+	} else {
+		der, err := x509.MarshalPKCS8PrivateKey(signer)
+		if err != nil {
+			t.Fatalf("falha ao serializar chave privada: %v", err)
+		}
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	}
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatalf("falha ao gravar chave: %v", err)
+	}
+	return path
+}
+
+func newECKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("falha ao gerar chave EC: %v", err)
+	}
+	return k
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	k, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("falha ao gerar chave RSA: %v", err)
+	}
+	return k
+}
+
+// TestLoadKeyFile_DeducesMethod testa a dedução do algoritmo pelo tipo da chave.
+func TestLoadKeyFile_DeducesMethod(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("falha ao gerar chave Ed25519: %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		signer crypto.Signer
+		alg    string
+	}{
+		{"RSA", newRSAKey(t), "RS256"},
+		{"ECDSA P-256", newECKey(t), "ES256"},
+		{"Ed25519", edKey, "EdDSA"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, err := token.LoadKeyFile("k1", writeKeyPEM(t, tc.signer, false))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.alg, key.Method.Alg())
+			assert.NotNil(t, key.Private)
+
+			pub, err := token.LoadKeyFile("k1", writeKeyPEM(t, tc.signer, true))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.alg, pub.Method.Alg())
+			assert.Nil(t, pub.Private, "Arquivo só com a chave pública não deve produzir chave de assinatura")
+		})
+	}
+}
+
+// TestNewKeySet_ActiveKeyMustBeSigner testa que a chave ativa precisa existir e ter a parte privada.
+func TestNewKeySet_ActiveKeyMustBeSigner(t *testing.T) {
+	pub, err := token.LoadKeyFile("old", writeKeyPEM(t, newECKey(t), true))
+	if err != nil {
+		t.Fatalf("falha ao carregar chave: %v", err)
+	}
+
+	_, err = token.NewKeySet("old", pub)
+	assert.Error(t, err)
+
+	_, err = token.NewKeySet("missing", pub)
+	assert.Error(t, err)
+}
+
+// TestJWKS_ExposesOnlyPublicMaterial testa que o JWKS publica apenas as chaves públicas
+// (inclusive as em rotação), sem a parte privada nem o segredo HMAC.
+func TestJWKS_ExposesOnlyPublicMaterial(t *testing.T) {
+	spec := "new=" + writeKeyPEM(t, newRSAKey(t), false) + ",old=" + writeKeyPEM(t, newECKey(t), true)
+	keys, err := token.LoadKeySet(spec, "new")
+	if err != nil {
+		t.Fatalf("falha ao carregar chaves: %v", err)
+	}
+	svc := token.NewServiceWithKeys(keys, 0)
+	svc.AcceptLegacyHMAC("segredo-hmac-legado")
+
+	body, err := json.Marshal(svc.JWKS())
+	if err != nil {
+		t.Fatalf("falha ao serializar JWKS: %v", err)
+	}
+
+	var doc struct {
+		Keys []map[string]interface{} `json:"keys"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("falha ao ler JWKS: %v", err)
+	}
+	assert.Len(t, doc.Keys, 2, "Apenas as chaves assimétricas devem ser publicadas")
+	for _, k := range doc.Keys {
+		for _, private := range []string{"d", "p", "q", "dp", "dq", "qi", "k"} {
+			assert.NotContains(t, k, private, "JWK %v não deve expor o parâmetro privado %q", k["kid"], private)
+		}
+		assert.NotEqual(t, "oct", k["kty"], "Chaves simétricas não devem ser publicadas")
+	}
+	assert.NotContains(t, string(body), "segredo-hmac-legado")
+
+	hmacOnly := token.NewService("segredo-hmac", 0)
+	assert.Empty(t, hmacOnly.JWKS().Keys, "Com HMAC, o JWKS deve ser vazio")
+}
+
+// TestJWK_PublicKeyRoundTrip testa a conversão da JWK publicada de volta para a chave pública.
+func TestJWK_PublicKeyRoundTrip(t *testing.T) {
+	ecKey := newECKey(t)
+	keys, err := token.LoadKeySet("k1="+writeKeyPEM(t, ecKey, false), "k1")
+	if err != nil {
+		t.Fatalf("falha ao carregar chaves: %v", err)
+	}
+
+	jwks := keys.JWKS()
+	if len(jwks.Keys) != 1 {
+		t.Fatalf("esperada uma JWK, obtidas %d", len(jwks.Keys))
+	}
+	pub, err := jwks.Keys[0].PublicKey()
+	assert.NoError(t, err)
+	assert.True(t, ecKey.PublicKey.Equal(pub))
+}
@@ -0,0 +1,65 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// mfaAudience distingue o desafio do segundo fator dos access tokens: ValidateToken recusa
+// tokens com audiência, e ValidateMFAChallenge exige esta.
+const mfaAudience = "GoStock-MFA"
+
+// Finalidades do desafio: conferir o código de um usuário com o segundo fator ativo, ou
+// cadastrá-lo durante o login quando o papel o exige.
+const (
+	MFAPurposeVerify = "verify"
+	MFAPurposeSetup  = "setup"
+)
+
+// MFAClaims são as informações do desafio emitido no primeiro passo do login.
+type MFAClaims struct {
+	Purpose string `json:"purpose"`
+	jwt.RegisteredClaims
+}
+
+// GenerateMFAChallenge assina o desafio do segundo passo do login para o usuário, válido por expiry.
+func (s *Service) GenerateMFAChallenge(userID, purpose string, expiry time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(expiry)
+	claims := MFAClaims{
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   userID,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    "GoStock-API",
+			Audience:  jwt.ClaimStrings{mfaAudience},
+		},
+	}
+
+	signed, err := s.sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// ValidateMFAChallenge valida a assinatura e a validade do desafio e retorna as suas claims.
+func (s *Service) ValidateMFAChallenge(tokenString string) (*MFAClaims, error) {
+	claims := &MFAClaims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, s.verificationKey, jwt.WithAudience(mfaAudience))
+	if err != nil {
+		return nil, fmt.Errorf("desafio MFA inválido: %w", err)
+	}
+	if !token.Valid || claims.Subject == "" {
+		return nil, errors.New("desafio MFA não é válido")
+	}
+	return claims, nil
+}
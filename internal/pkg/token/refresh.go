@@ -0,0 +1,40 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// refreshTokenBytes é a quantidade de bytes aleatórios de um refresh token.
+const refreshTokenBytes = 32
+
+// NewRefreshToken gera um refresh token opaco e aleatório e o hash que deve ser persistido.
+// O token em texto puro é entregue apenas ao cliente.
+func NewRefreshToken() (string, string, error) {
+	buf := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("falha ao gerar refresh token: %w", err)
+	}
+	plain := base64.RawURLEncoding.EncodeToString(buf)
+	return plain, HashRefreshToken(plain), nil
+}
+
+// HashRefreshToken retorna o hash SHA-256 (hex) usado para buscar o refresh token no banco.
+func HashRefreshToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewOneTimeToken gera um token opaco de uso único (redefinição de senha, verificação de e-mail)
+// e o hash que deve ser persistido, no mesmo formato dos refresh tokens.
+func NewOneTimeToken() (string, string, error) {
+	return NewRefreshToken()
+}
+
+// HashOneTimeToken retorna o hash SHA-256 (hex) usado para buscar o token de uso único no banco.
+func HashOneTimeToken(plain string) string {
+	return HashRefreshToken(plain)
+}
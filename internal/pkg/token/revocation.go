@@ -0,0 +1,96 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gostock/internal/pkg/cache"
+)
+
+// Chaves da lista de revogação no cache. As entradas expiram junto com os tokens que revogam.
+const (
+	revokedTokenKey   = "revoked:jti:%s"
+	revokedSessionKey = "revoked:sid:%s"
+	revokedUserKey    = "revoked:user:%s" // Instante (Unix) a partir do qual os tokens do usuário valem
+)
+
+// RevocationList guarda no cache os access tokens (jti) e as sessões (sid) revogados.
+// Access tokens são stateless; a lista permite invalidá-los antes da expiração (logout,
+// reuso de refresh token).
+type RevocationList struct {
+	cache cache.Client
+}
+
+// NewRevocationList cria a lista de revogação sobre o cliente de cache.
+func NewRevocationList(cacheClient cache.Client) *RevocationList {
+	return &RevocationList{cache: cacheClient}
+}
+
+// RevokeToken revoga um access token até a sua expiração.
+func (l *RevocationList) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if tokenID == "" || ttl <= 0 {
+		return nil // Sem jti não há o que revogar; tokens expirados já são recusados
+	}
+	return l.cache.Set(ctx, fmt.Sprintf(revokedTokenKey, tokenID), "1", ttl)
+}
+
+// RevokeSession revoga todos os access tokens emitidos para a sessão. ttl deve cobrir a validade
+// dos access tokens (os emitidos antes da revogação expiram dentro desse prazo).
+func (l *RevocationList) RevokeSession(ctx context.Context, sessionID string, ttl time.Duration) error {
+	if sessionID == "" {
+		return nil
+	}
+	return l.cache.Set(ctx, fmt.Sprintf(revokedSessionKey, sessionID), "1", ttl)
+}
+
+// RevokeUser revoga todos os access tokens emitidos para o usuário até agora (conta desativada,
+// removida ou com a senha trocada). Tokens emitidos depois continuam válidos. ttl deve cobrir a
+// validade dos access tokens.
+func (l *RevocationList) RevokeUser(ctx context.Context, userID string, ttl time.Duration) error {
+	if userID == "" {
+		return nil
+	}
+	return l.cache.Set(ctx, fmt.Sprintf(revokedUserKey, userID), strconv.FormatInt(time.Now().Unix(), 10), ttl)
+}
+
+// IsRevoked verifica se o token, a sessão a que ele pertence ou os tokens do usuário foram revogados.
+func (l *RevocationList) IsRevoked(ctx context.Context, claims *CustomClaims) (bool, error) {
+	keys := make([]string, 0, 2)
+	if claims.ID != "" {
+		keys = append(keys, fmt.Sprintf(revokedTokenKey, claims.ID))
+	}
+	if claims.SessionID != "" {
+		keys = append(keys, fmt.Sprintf(revokedSessionKey, claims.SessionID))
+	}
+
+	for _, key := range keys {
+		_, err := l.cache.Get(ctx, key)
+		if err == nil {
+			return true, nil
+		}
+		if !errors.Is(err, cache.ErrCacheMiss) {
+			return false, fmt.Errorf("falha ao consultar a lista de revogação: %w", err)
+		}
+	}
+
+	if claims.UserID == "" {
+		return false, nil
+	}
+	value, err := l.cache.Get(ctx, fmt.Sprintf(revokedUserKey, claims.UserID))
+	if errors.Is(err, cache.ErrCacheMiss) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("falha ao consultar a lista de revogação: %w", err)
+	}
+	revokedAt, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return true, nil // Valor inesperado: na dúvida, o token é recusado
+	}
+	// O iat tem precisão de segundos: tokens emitidos no mesmo segundo da revogação também são recusados
+	return claims.IssuedAt == nil || claims.IssuedAt.Unix() <= revokedAt, nil
+}
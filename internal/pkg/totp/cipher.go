@@ -0,0 +1,58 @@
+package totp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// SecretCipher cifra os segredos TOTP gravados no banco (AES-256-GCM). Sem a chave, um vazamento
+// do banco não permite gerar códigos.
+type SecretCipher struct {
+	aead cipher.AEAD
+}
+
+// NewSecretCipher deriva a chave AES-256 da chave configurada (SHA-256).
+func NewSecretCipher(key string) (*SecretCipher, error) {
+	if key == "" {
+		return nil, errors.New("chave de cifragem dos segredos TOTP não configurada")
+	}
+	derived := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(derived[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretCipher{aead: aead}, nil
+}
+
+// Seal cifra o segredo; o resultado (nonce + texto cifrado) é codificado em base64.
+func (c *SecretCipher) Seal(plain string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("falha ao gerar nonce: %w", err)
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plain), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decifra um segredo produzido por Seal.
+func (c *SecretCipher) Open(sealed string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < c.aead.NonceSize() {
+		return "", errors.New("segredo TOTP cifrado inválido")
+	}
+	nonce, ciphertext := data[:c.aead.NonceSize()], data[c.aead.NonceSize():]
+	plain, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("falha ao decifrar segredo TOTP: %w", err)
+	}
+	return string(plain), nil
+}
@@ -0,0 +1,91 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Parâmetros compatíveis com os aplicativos autenticadores (Google Authenticator, Authy, etc.).
+const (
+	Period     = 30 * time.Second // Duração de cada intervalo
+	Digits     = 6
+	secretSize = 20 // 160 bits, o tamanho recomendado pela RFC 4226 para HMAC-SHA1
+	// skew é a quantidade de intervalos aceitos antes e depois do atual (relógios dessincronizados)
+	skew = 1
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret gera um segredo aleatório, codificado em base32 (formato dos autenticadores).
+func GenerateSecret() (string, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("falha ao gerar segredo TOTP: %w", err)
+	}
+	return encoding.EncodeToString(secret), nil
+}
+
+// ProvisioningURI monta a URI otpauth:// usada no QR code de cadastro do autenticador.
+func ProvisioningURI(issuer, account, secret string) string {
+	label := url.PathEscape(issuer + ":" + account)
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(Digits))
+	params.Set("period", fmt.Sprint(int(Period.Seconds())))
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// Step retorna o intervalo TOTP do instante informado.
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(Period.Seconds())
+}
+
+// Code calcula o código do intervalo informado (RFC 6238, HMAC-SHA1).
+func Code(secret string, step int64) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("segredo TOTP inválido: %w", err)
+	}
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	// Truncamento dinâmico (RFC 4226, seção 5.3)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", Digits, value%1_000_000), nil
+}
+
+// Validate confere o código no instante informado, tolerando um intervalo de diferença de
+// relógio. Retorna o intervalo correspondente ao código, para impedir o seu reuso.
+func Validate(secret, code string, now time.Time) (int64, bool) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != Digits {
+		return 0, false
+	}
+
+	current := Step(now)
+	for offset := int64(-skew); offset <= skew; offset++ {
+		expected, err := Code(secret, current+offset)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return current + offset, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,78 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	apperror "gostock/internal/errors"
+)
+
+// MaxBodyBytes é o tamanho máximo dos payloads JSON (uploads e importações têm limites próprios).
+const MaxBodyBytes = 1 << 20 // 1 MiB
+
+// DecodeJSON lê o corpo da requisição em dst e o valida com Struct. Recusa corpos acima de
+// MaxBodyBytes (PayloadTooLargeError), campos desconhecidos, tipos incorretos e mais de um valor
+// JSON (ValidationError, com o campo em "errors" quando identificado).
+func DecodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxBodyBytes))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		return decodeError(err)
+	}
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return decodeError(err)
+		}
+		return apperror.NewValidationError("O corpo da requisição deve conter um único objeto JSON.")
+	}
+	return Struct(dst)
+}
+
+// decodeError traduz os erros do encoding/json para mensagens do cliente.
+func decodeError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var tooLarge *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &tooLarge):
+		return apperror.NewPayloadTooLargeError(fmt.Sprintf("O corpo da requisição excede o limite de %d MiB.", MaxBodyBytes>>20))
+	case errors.Is(err, io.EOF):
+		return apperror.NewValidationError("O corpo da requisição está vazio.")
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return apperror.NewValidationError("Payload inválido. Verifique o formato JSON.")
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			return apperror.NewValidationError(fmt.Sprintf("Payload inválido: esperado %s.", jsonType(typeErr.Type.Kind().String())))
+		}
+		return Error([]apperror.FieldError{{Field: field, Message: fmt.Sprintf("Tipo inválido: esperado %s.", jsonType(typeErr.Type.Kind().String()))}})
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return Error([]apperror.FieldError{{Field: field, Message: "Campo desconhecido."}})
+	}
+	return apperror.NewValidationError(fmt.Sprintf("Payload inválido: %s.", err.Error()))
+}
+
+// jsonType descreve o tipo Go esperado com os nomes do JSON.
+func jsonType(kind string) string {
+	switch {
+	case kind == "string":
+		return "texto"
+	case kind == "bool":
+		return "booleano"
+	case strings.HasPrefix(kind, "int"), strings.HasPrefix(kind, "uint"), strings.HasPrefix(kind, "float"):
+		return "número"
+	case kind == "slice", kind == "array":
+		return "lista"
+	case kind == "struct", kind == "map":
+		return "objeto"
+	}
+	return kind
+}
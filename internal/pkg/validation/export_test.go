@@ -0,0 +1,8 @@
+package validation
+
+import "reflect"
+
+// CheckTags interpreta as tags de t como na validação (panic em regra ou parâmetro inválido).
+func CheckTags(t reflect.Type) {
+	fieldsOf(t)
+}
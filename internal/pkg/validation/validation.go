@@ -0,0 +1,281 @@
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	apperror "gostock/internal/errors"
+)
+
+// Struct valida os campos de v (struct ou ponteiro para struct) pelas tags `validate` e retorna
+// um único ValidationError com todos os campos inválidos, ou nil. Regras suportadas:
+//
+//	required  valor não vazio (texto sem espaços, número diferente de zero, lista com itens, ponteiro preenchido)
+//	uuid      texto no formato UUID
+//	email     endereço de e-mail puro (sem nome de exibição)
+//	min=N     texto com pelo menos N caracteres, lista com N itens ou número >= N
+//	max=N     texto com até N caracteres, lista com até N itens ou número <= N
+//	len=N     texto com exatamente N caracteres ou lista com N itens
+//	oneof=a b valor entre os listados
+//
+// Campos vazios sem "required" não são validados pelas demais regras. Structs aninhadas (inclusive
+// em ponteiros e listas) são validadas recursivamente; os campos são identificados pelo nome JSON
+// (ex.: "settings.timezone", "variants[0].barcode"). validate:"-" ignora o campo.
+func Struct(v interface{}) error {
+	return Error(Fields(v))
+}
+
+// Fields retorna os campos inválidos de v, sem montar o erro; permite somar regras escritas à mão.
+func Fields(v interface{}) []apperror.FieldError {
+	var fields []apperror.FieldError
+	validateValue(reflect.ValueOf(v), "", &fields)
+	return fields
+}
+
+// Error agrega os campos inválidos em um ValidationError (nil quando não há campos). A mensagem
+// repete os campos para os clientes que leem apenas "message".
+func Error(fields []apperror.FieldError) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = f.Field + ": " + f.Message
+	}
+	return apperror.NewFieldValidationError("Dados inválidos. "+strings.Join(parts, " "), fields...)
+}
+
+// rule é uma regra já interpretada de uma tag validate.
+type rule struct {
+	name  string
+	param string
+}
+
+// field descreve um campo exportado de uma struct e as suas regras.
+type field struct {
+	index    int
+	name     string
+	rules    []rule
+	required bool
+	skip     bool
+}
+
+// typeFields guarda os campos já interpretados por tipo (as tags não mudam em tempo de execução).
+var typeFields sync.Map
+
+// fieldsOf interpreta as tags dos campos de t. Regras desconhecidas e parâmetros inválidos são
+// erros de programação e causam panic na primeira validação do tipo.
+func fieldsOf(t reflect.Type) []field {
+	if cached, ok := typeFields.Load(t); ok {
+		return cached.([]field)
+	}
+
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := sf.Name
+		if tag := strings.Split(sf.Tag.Get("json"), ",")[0]; tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+
+		f := field{index: i, name: name}
+		tag := sf.Tag.Get("validate")
+		if tag == "-" {
+			f.skip = true
+		} else if tag != "" {
+			for _, part := range strings.Split(tag, ",") {
+				r := rule{name: part}
+				if key, param, ok := strings.Cut(part, "="); ok {
+					r = rule{name: key, param: param}
+				}
+				switch r.name {
+				case "required":
+					f.required = true
+				case "uuid", "email":
+					f.rules = append(f.rules, r)
+				case "min", "max", "len":
+					if _, err := strconv.ParseFloat(r.param, 64); err != nil {
+						panic(fmt.Sprintf("validation: parâmetro inválido em '%s' em %s.%s", part, t.Name(), sf.Name))
+					}
+					f.rules = append(f.rules, r)
+				case "oneof":
+					if strings.TrimSpace(r.param) == "" {
+						panic(fmt.Sprintf("validation: oneof sem valores em %s.%s", t.Name(), sf.Name))
+					}
+					f.rules = append(f.rules, r)
+				case "numeric", "omitempty":
+					// Garantidos pelo tipo do campo e pela regra padrão para campos vazios
+				default:
+					panic(fmt.Sprintf("validation: regra desconhecida '%s' em %s.%s", r.name, t.Name(), sf.Name))
+				}
+			}
+		}
+		fields = append(fields, f)
+	}
+
+	typeFields.Store(t, fields)
+	return fields
+}
+
+// validateValue percorre structs, ponteiros e listas acumulando os campos inválidos.
+func validateValue(v reflect.Value, path string, errs *[]apperror.FieldError) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		for _, f := range fieldsOf(v.Type()) {
+			if f.skip {
+				continue
+			}
+			fieldPath := f.name
+			if path != "" {
+				fieldPath = path + "." + f.name
+			}
+			value := v.Field(f.index)
+			if message := checkField(f, value); message != "" {
+				*errs = append(*errs, apperror.FieldError{Field: fieldPath, Message: message})
+				continue
+			}
+			validateValue(value, fieldPath, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		if elem := v.Type().Elem(); elem.Kind() != reflect.Struct && !(elem.Kind() == reflect.Ptr && elem.Elem().Kind() == reflect.Struct) {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			validateValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	}
+}
+
+// checkField aplica as regras do campo e retorna a mensagem da primeira regra violada.
+func checkField(f field, v reflect.Value) string {
+	if isEmpty(v) {
+		if f.required {
+			return "É obrigatório."
+		}
+		return ""
+	}
+
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	for _, r := range f.rules {
+		if message := checkRule(r, v); message != "" {
+			return message
+		}
+	}
+	return ""
+}
+
+// isEmpty indica se o valor está ausente (textos só com espaços contam como vazios).
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.Struct:
+		return false
+	default:
+		return v.IsZero()
+	}
+}
+
+// checkRule aplica uma regra a um valor não vazio.
+func checkRule(r rule, v reflect.Value) string {
+	switch r.name {
+	case "uuid":
+		if v.Kind() != reflect.String || !isUUID(v.String()) {
+			return "Deve ser um UUID válido."
+		}
+	case "email":
+		if addr, err := mail.ParseAddress(v.String()); err != nil || addr.Address != v.String() {
+			return "Deve ser um email válido."
+		}
+	case "min", "max", "len":
+		return checkSize(r, v)
+	case "oneof":
+		options := strings.Fields(r.param)
+		value := fmt.Sprint(v.Interface())
+		for _, option := range options {
+			if value == option {
+				return ""
+			}
+		}
+		return fmt.Sprintf("Deve ser um dos valores: %s.", strings.Join(options, ", "))
+	}
+	return ""
+}
+
+// checkSize aplica min, max e len ao tamanho dos textos e listas ou ao valor dos números.
+func checkSize(r rule, v reflect.Value) string {
+	limit, err := strconv.ParseFloat(r.param, 64)
+	if err != nil {
+		panic(fmt.Sprintf("validation: parâmetro inválido em '%s=%s'", r.name, r.param))
+	}
+
+	var size float64
+	var unit string
+	switch v.Kind() {
+	case reflect.String:
+		size, unit = float64(utf8.RuneCountInString(v.String())), "caracteres"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		size, unit = float64(v.Len()), "itens"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		size = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		size = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		size = v.Float()
+	default:
+		return ""
+	}
+
+	switch {
+	case r.name == "min" && size < limit:
+		if unit == "" {
+			return fmt.Sprintf("Deve ser maior ou igual a %s.", r.param)
+		}
+		return fmt.Sprintf("Deve ter pelo menos %s %s.", r.param, unit)
+	case r.name == "max" && size > limit:
+		if unit == "" {
+			return fmt.Sprintf("Deve ser menor ou igual a %s.", r.param)
+		}
+		return fmt.Sprintf("Deve ter no máximo %s %s.", r.param, unit)
+	case r.name == "len" && size != limit:
+		if unit == "" {
+			return fmt.Sprintf("Deve ser igual a %s.", r.param)
+		}
+		return fmt.Sprintf("Deve ter exatamente %s %s.", r.param, unit)
+	}
+	return ""
+}
+
+// isUUID aceita apenas a forma canônica (8-4-4-4-12), como os IDs gerados pela API.
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	_, err := uuid.Parse(s)
+	return err == nil
+}
@@ -0,0 +1,285 @@
+package validation_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gostock/internal/api/product"
+	"gostock/internal/api/role"
+	"gostock/internal/api/user"
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/validation"
+)
+
+// fieldsFor valida v e retorna as mensagens por campo.
+func fieldsFor(v interface{}) map[string]string {
+	messages := map[string]string{}
+	for _, f := range validation.Fields(v) {
+		messages[f.Field] = f.Message
+	}
+	return messages
+}
+
+// TestStruct_Required testa o required em textos, números, listas e ponteiros.
+func TestStruct_Required(t *testing.T) {
+	type payload struct {
+		Name  string   `json:"name" validate:"required"`
+		Count int      `json:"count" validate:"required"`
+		Tags  []string `json:"tags" validate:"required"`
+		Note  *string  `json:"note" validate:"required"`
+	}
+
+	errs := fieldsFor(payload{Name: "   ", Tags: []string{}})
+	assert.Len(t, errs, 4)
+	for _, name := range []string{"name", "count", "tags", "note"} {
+		assert.Equal(t, "É obrigatório.", errs[name], name)
+	}
+
+	note := ""
+	assert.Empty(t, fieldsFor(payload{Name: "a", Count: -1, Tags: []string{"x"}, Note: &note}),
+		"Ponteiro preenchido conta como presente, mesmo apontando para texto vazio")
+}
+
+// TestStruct_Rules testa uuid, email, min, max, len e oneof em textos, listas e números.
+func TestStruct_Rules(t *testing.T) {
+	type payload struct {
+		ID       string   `json:"id" validate:"uuid"`
+		Email    string   `json:"email" validate:"email"`
+		Name     string   `json:"name" validate:"min=2,max=4"`
+		Code     string   `json:"code" validate:"len=3"`
+		Tags     []string `json:"tags" validate:"min=1,max=2"`
+		Pair     []int    `json:"pair" validate:"len=2"`
+		Quantity int      `json:"quantity" validate:"min=1,max=10"`
+		Price    float64  `json:"price" validate:"max=9.5"`
+		Digits   uint     `json:"digits" validate:"len=8"`
+		Status   string   `json:"status" validate:"oneof=ativo inativo"`
+		Level    int      `json:"level" validate:"oneof=1 2 3"`
+	}
+
+	cases := []struct {
+		name    string
+		payload payload
+		field   string
+		message string
+	}{
+		{"uuid inválido", payload{ID: "123"}, "id", "Deve ser um UUID válido."},
+		{"uuid fora da forma canônica", payload{ID: "urn:uuid:123e4567-e89b-12d3-a456-426614174000"}, "id", "Deve ser um UUID válido."},
+		{"email inválido", payload{Email: "sem-arroba"}, "email", "Deve ser um email válido."},
+		{"email com nome de exibição", payload{Email: "Maria <maria@exemplo.com>"}, "email", "Deve ser um email válido."},
+		{"texto curto", payload{Name: "a"}, "name", "Deve ter pelo menos 2 caracteres."},
+		{"texto longo (conta caracteres, não bytes)", payload{Name: "ações"}, "name", "Deve ter no máximo 4 caracteres."},
+		{"texto com tamanho errado", payload{Code: "ab"}, "code", "Deve ter exatamente 3 caracteres."},
+		{"lista longa", payload{Tags: []string{"a", "b", "c"}}, "tags", "Deve ter no máximo 2 itens."},
+		{"lista com tamanho errado", payload{Pair: []int{1}}, "pair", "Deve ter exatamente 2 itens."},
+		{"número abaixo do mínimo", payload{Quantity: -1}, "quantity", "Deve ser maior ou igual a 1."},
+		{"número acima do máximo", payload{Quantity: 11}, "quantity", "Deve ser menor ou igual a 10."},
+		{"decimal acima do máximo", payload{Price: 9.51}, "price", "Deve ser menor ou igual a 9.5."},
+		{"número diferente", payload{Digits: 7}, "digits", "Deve ser igual a 8."},
+		{"texto fora das opções", payload{Status: "excluido"}, "status", "Deve ser um dos valores: ativo, inativo."},
+		{"número fora das opções", payload{Level: 4}, "level", "Deve ser um dos valores: 1, 2, 3."},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := fieldsFor(tc.payload)
+			assert.Equal(t, map[string]string{tc.field: tc.message}, errs)
+		})
+	}
+
+	valid := payload{
+		ID: "123e4567-e89b-12d3-a456-426614174000", Email: "maria@exemplo.com", Name: "ação", Code: "abc",
+		Tags: []string{"a"}, Pair: []int{1, 2}, Quantity: 10, Price: 9.5, Digits: 8, Status: "ativo", Level: 2,
+	}
+	assert.Empty(t, fieldsFor(valid))
+	assert.Empty(t, fieldsFor(payload{}), "Campos vazios sem required não passam pelas demais regras")
+}
+
+// TestStruct_NestedPaths testa os caminhos dos campos em structs aninhadas, ponteiros e listas.
+func TestStruct_NestedPaths(t *testing.T) {
+	type variant struct {
+		Barcode string `json:"barcode" validate:"len=13"`
+	}
+	type settings struct {
+		Timezone string `json:"timezone" validate:"required"`
+	}
+	type payload struct {
+		Settings *settings `json:"settings"`
+		Variants []variant `json:"variants"`
+		Extra    []*variant
+		Ignored  variant `json:"ignored" validate:"-"`
+		Hidden   variant `json:"-"`
+	}
+
+	bad := variant{Barcode: "123"}
+	errs := fieldsFor(&payload{
+		Settings: &settings{},
+		Variants: []variant{{Barcode: "7891234567895"}, bad},
+		Extra:    []*variant{nil, &bad},
+		Ignored:  bad,
+		Hidden:   bad,
+	})
+
+	assert.Equal(t, map[string]string{
+		"settings.timezone":   "É obrigatório.",
+		"variants[1].barcode": "Deve ter exatamente 13 caracteres.",
+		"Extra[1].barcode":    "Deve ter exatamente 13 caracteres.",
+	}, errs)
+	assert.Empty(t, fieldsFor(payload{}), "Ponteiros nil e listas vazias não são percorridos")
+}
+
+// TestStruct_ErrorAggregatesFields testa o ValidationError com todos os campos inválidos.
+func TestStruct_ErrorAggregatesFields(t *testing.T) {
+	type payload struct {
+		Name  string `json:"name" validate:"required"`
+		Email string `json:"email" validate:"required,email"`
+	}
+
+	err := validation.Struct(payload{Email: "x"})
+
+	var validationErr *apperror.ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, []apperror.FieldError{
+			{Field: "name", Message: "É obrigatório."},
+			{Field: "email", Message: "Deve ser um email válido."},
+		}, validationErr.Fields)
+		assert.Contains(t, validationErr.Msg, "name: É obrigatório.")
+		assert.Contains(t, validationErr.Msg, "email: Deve ser um email válido.")
+	}
+	assert.NoError(t, validation.Struct(payload{Name: "a", Email: "a@b.com"}))
+}
+
+// TestCheckTags_InvalidTags testa que regras desconhecidas e parâmetros inválidos causam panic já
+// na leitura das tags, e não apenas quando um valor chega à regra.
+func TestCheckTags_InvalidTags(t *testing.T) {
+	cases := map[string]interface{}{
+		"regra desconhecida": struct {
+			Name string `validate:"requried"`
+		}{},
+		"min sem número": struct {
+			Name string `validate:"min=abc"`
+		}{},
+		"max sem parâmetro": struct {
+			Tags []string `validate:"max"`
+		}{},
+		"oneof sem valores": struct {
+			Status string `validate:"oneof="`
+		}{},
+	}
+	for name, v := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Panics(t, func() { validation.CheckTags(reflect.TypeOf(v)) })
+		})
+	}
+}
+
+// TestCheckTags_RequestDTOs percorre os payloads de todos os endpoints (e os tipos aninhados),
+// para que uma tag inválida quebre o CI em vez da primeira requisição.
+func TestCheckTags_RequestDTOs(t *testing.T) {
+	dtos := []interface{}{
+		domain.Warehouse{}, domain.UserWarehouses{},
+		domain.TenantCreate{}, domain.TenantUpdate{},
+		domain.UserRegistration{}, user.LoginRequest{}, domain.RefreshTokenRequest{}, domain.AdminSetup{},
+		domain.InvitationRequest{}, domain.InvitationAcceptance{},
+		domain.MFAVerification{}, domain.MFAChallengeToken{}, domain.MFACode{}, domain.MFADisable{},
+		domain.ServiceAccountCreate{}, domain.APIKeyCreate{},
+		domain.PasswordChange{}, domain.PasswordForgot{}, domain.PasswordReset{},
+		domain.PriceList{}, domain.VariantPrice{}, domain.VariantBarcode{},
+		role.RoleRequest{}, domain.UserRoleAssignment{},
+		domain.AttributeDefinition{}, domain.VariantGenerationRequest{},
+		product.CategorySchemaRequest{}, product.ProductCustomAttributesRequest{},
+		product.ProductCreateRequest{}, product.ProductRevertRequest{}, product.ProductMediaRequest{},
+		domain.Product{},
+		domain.StockAdjustmentRequest{}, domain.VariantUnits{},
+	}
+
+	seen := map[reflect.Type]bool{}
+	var walk func(typ reflect.Type)
+	walk = func(typ reflect.Type) {
+		for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array || typ.Kind() == reflect.Map {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct || seen[typ] {
+			return
+		}
+		seen[typ] = true
+		assert.NotPanics(t, func() { validation.CheckTags(typ) }, "Tags inválidas em %s", typ)
+		for i := 0; i < typ.NumField(); i++ {
+			if sf := typ.Field(i); sf.IsExported() {
+				walk(sf.Type)
+			}
+		}
+	}
+	for _, dto := range dtos {
+		walk(reflect.TypeOf(dto))
+	}
+}
+
+// TestDecodeJSON testa a leitura do corpo: campos desconhecidos, tipos incorretos, mais de um valor
+// JSON, corpo vazio e corpo acima do limite são recusados.
+func TestDecodeJSON(t *testing.T) {
+	type payload struct {
+		Name     string `json:"name" validate:"required"`
+		Quantity int    `json:"quantity"`
+	}
+	decode := func(body []byte) (payload, error) {
+		var dst payload
+		r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		err := validation.DecodeJSON(httptest.NewRecorder(), r, &dst)
+		return dst, err
+	}
+
+	t.Run("válido", func(t *testing.T) {
+		dst, err := decode([]byte(`{"name": "Caneta", "quantity": 3}`))
+		assert.NoError(t, err)
+		assert.Equal(t, payload{Name: "Caneta", Quantity: 3}, dst)
+	})
+
+	fieldCases := []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{"campo desconhecido", `{"name": "Caneta", "preco": 1}`, "preco"},
+		{"tipo incorreto", `{"name": "Caneta", "quantity": "três"}`, "quantity"},
+		{"regras da struct", `{"quantity": 3}`, "name"},
+	}
+	for _, tc := range fieldCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := decode([]byte(tc.body))
+			var validationErr *apperror.ValidationError
+			if assert.ErrorAs(t, err, &validationErr) && assert.Len(t, validationErr.Fields, 1) {
+				assert.Equal(t, tc.field, validationErr.Fields[0].Field)
+			}
+		})
+	}
+
+	for name, body := range map[string]string{
+		"mais de um valor JSON": `{"name": "Caneta"} {"name": "Lápis"}`,
+		"JSON malformado":       `{"name": "Caneta"`,
+		"corpo vazio":           ``,
+		"não é um objeto":       `["Caneta"]`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := decode([]byte(body))
+			assert.IsType(t, &apperror.ValidationError{}, err)
+		})
+	}
+
+	t.Run("corpo acima do limite", func(t *testing.T) {
+		body := `{"name": "` + strings.Repeat("a", validation.MaxBodyBytes) + `"}`
+		_, err := decode([]byte(body))
+		assert.IsType(t, &apperror.PayloadTooLargeError{}, err)
+	})
+
+	t.Run("valor extra acima do limite", func(t *testing.T) {
+		body := `{"name": "Caneta"} "` + strings.Repeat("a", validation.MaxBodyBytes) + `"`
+		_, err := decode([]byte(body))
+		assert.IsType(t, &apperror.PayloadTooLargeError{}, err)
+	})
+}
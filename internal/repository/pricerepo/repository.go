@@ -0,0 +1,357 @@
+package pricerepo
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"gostock/internal/domain"
+	"gostock/internal/errors"
+	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/tenant"
+)
+
+// Códigos de erro do PostgreSQL tratados explicitamente.
+const (
+	pqUniqueViolation     = "23505"
+	pqForeignKeyViolation = "23503"
+)
+
+// PriceRepository implementa as operações de persistência de tabelas de preços e preços por variante.
+type PriceRepository struct {
+	DB        *sql.DB
+	DBTimeout time.Duration
+	logger    logger.Logger
+}
+
+// NewPriceRepository cria e retorna uma nova instância do Repositório de Preços.
+func NewPriceRepository(db *sql.DB, dbTimeout time.Duration, logger logger.Logger) *PriceRepository {
+	return &PriceRepository{
+		DB:        db,
+		DBTimeout: dbTimeout,
+		logger:    logger,
+	}
+}
+
+// pqCode retorna o código de erro do PostgreSQL, se houver.
+func pqCode(err error) string {
+	var pqErr *pq.Error
+	if stderrors.As(err, &pqErr) {
+		return string(pqErr.Code)
+	}
+	return ""
+}
+
+const priceListColumns = `id, name, currency, customer_segment, is_active, created_at, updated_at`
+
+func scanPriceList(row interface{ Scan(...interface{}) error }, list *domain.PriceList) error {
+	return row.Scan(&list.ID, &list.Name, &list.Currency, &list.CustomerSegment, &list.IsActive, &list.CreatedAt, &list.UpdatedAt)
+}
+
+const variantPriceColumns = `id, price_list_id, variant_id, amount, valid_from, valid_to, created_at, updated_at`
+
+func scanVariantPrice(row interface{ Scan(...interface{}) error }, price *domain.VariantPrice) error {
+	var validFrom, validTo sql.NullTime
+	if err := row.Scan(&price.ID, &price.PriceListID, &price.VariantID, &price.Amount, &validFrom, &validTo, &price.CreatedAt, &price.UpdatedAt); err != nil {
+		return err
+	}
+	price.ValidFrom, price.ValidTo = nil, nil
+	if validFrom.Valid {
+		price.ValidFrom = &validFrom.Time
+	}
+	if validTo.Valid {
+		price.ValidTo = &validTo.Time
+	}
+	return nil
+}
+
+// --- Tabelas de Preços ---
+
+// CreatePriceList insere uma nova tabela de preços.
+func (r *PriceRepository) CreatePriceList(ctx context.Context, list domain.PriceList) (domain.PriceList, error) {
+	r.logger.WithContext(ctx).Debug("Iniciando CreatePriceList no repositório.", map[string]interface{}{"name": list.Name})
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	if list.ID == "" {
+		list.ID = uuid.New().String()
+	}
+	now := time.Now().UTC()
+
+	query := `
+        INSERT INTO price_lists (id, name, currency, customer_segment, is_active, created_at, updated_at, tenant_id)
+        VALUES ($1, $2, $3, $4, $5, $6, $6, $7)
+        RETURNING ` + priceListColumns
+
+	err := scanPriceList(r.DB.QueryRowContext(ctxTimeout, query,
+		list.ID, list.Name, list.Currency, list.CustomerSegment, list.IsActive, now, tenant.ID(ctx),
+	), &list)
+	if pqCode(err) == pqUniqueViolation {
+		return domain.PriceList{}, errors.NewConflictError(fmt.Sprintf("Já existe uma tabela de preços com o nome '%s'.", list.Name))
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao inserir tabela de preços no DB.", err)
+		return domain.PriceList{}, errors.NewDBError("Falha ao criar tabela de preços", err)
+	}
+
+	r.logger.WithContext(ctx).Info("Tabela de preços criada com sucesso.", map[string]interface{}{"id": list.ID, "name": list.Name})
+	return list, nil
+}
+
+// GetPriceListByID busca uma tabela de preços pelo ID.
+func (r *PriceRepository) GetPriceListByID(ctx context.Context, id string) (domain.PriceList, error) {
+	r.logger.WithContext(ctx).Debug("Iniciando GetPriceListByID no repositório.", map[string]interface{}{"id": id})
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	query := `SELECT ` + priceListColumns + ` FROM price_lists WHERE id = $1 AND tenant_id = $2`
+
+	var list domain.PriceList
+	err := scanPriceList(r.DB.QueryRowContext(ctxTimeout, query, id, tenant.ID(ctx)), &list)
+	if err == sql.ErrNoRows {
+		r.logger.WithContext(ctx).Info("Tabela de preços não encontrada.", map[string]interface{}{"id": id})
+		return domain.PriceList{}, errors.NewNotFoundError(fmt.Sprintf("Tabela de preços com ID %s não encontrada.", id))
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao buscar tabela de preços no DB.", err)
+		return domain.PriceList{}, errors.NewDBError("Falha ao buscar tabela de preços", err)
+	}
+
+	return list, nil
+}
+
+// GetAllPriceLists busca todas as tabelas de preços, ordenadas por nome.
+func (r *PriceRepository) GetAllPriceLists(ctx context.Context) ([]domain.PriceList, error) {
+	r.logger.WithContext(ctx).Debug("Iniciando GetAllPriceLists no repositório.", nil)
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	query := `SELECT ` + priceListColumns + ` FROM price_lists WHERE tenant_id = $1 ORDER BY name`
+
+	rows, err := r.DB.QueryContext(ctxTimeout, query, tenant.ID(ctx))
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao executar GetAllPriceLists query.", err)
+		return nil, errors.NewDBError("Falha ao buscar tabelas de preços", err)
+	}
+	defer rows.Close()
+
+	lists := []domain.PriceList{}
+	for rows.Next() {
+		var list domain.PriceList
+		if err := scanPriceList(rows, &list); err != nil {
+			r.logger.WithContext(ctx).Error("Falha ao mapear tabela de preços.", err)
+			return nil, errors.NewDBError("Falha ao mapear tabelas de preços do DB", err)
+		}
+		lists = append(lists, list)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.WithContext(ctx).Error("Erro após iteração das tabelas de preços.", err)
+		return nil, errors.NewDBError("Erro após iteração de tabelas de preços", err)
+	}
+
+	return lists, nil
+}
+
+// UpdatePriceList atualiza nome, moeda, segmento e status de uma tabela de preços.
+func (r *PriceRepository) UpdatePriceList(ctx context.Context, list domain.PriceList) (domain.PriceList, error) {
+	r.logger.WithContext(ctx).Debug("Iniciando UpdatePriceList no repositório.", map[string]interface{}{"id": list.ID})
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	query := `
+        UPDATE price_lists
+        SET name = $1, currency = $2, customer_segment = $3, is_active = $4, updated_at = $5
+        WHERE id = $6 AND tenant_id = $7
+        RETURNING ` + priceListColumns
+
+	err := scanPriceList(r.DB.QueryRowContext(ctxTimeout, query,
+		list.Name, list.Currency, list.CustomerSegment, list.IsActive, time.Now().UTC(), list.ID, tenant.ID(ctx),
+	), &list)
+	if err == sql.ErrNoRows {
+		return domain.PriceList{}, errors.NewNotFoundError(fmt.Sprintf("Tabela de preços com ID %s não encontrada para atualização.", list.ID))
+	}
+	if pqCode(err) == pqUniqueViolation {
+		return domain.PriceList{}, errors.NewConflictError(fmt.Sprintf("Já existe uma tabela de preços com o nome '%s'.", list.Name))
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao atualizar tabela de preços no DB.", err)
+		return domain.PriceList{}, errors.NewDBError("Falha ao atualizar tabela de preços", err)
+	}
+
+	r.logger.WithContext(ctx).Info("Tabela de preços atualizada com sucesso.", map[string]interface{}{"id": list.ID})
+	return list, nil
+}
+
+// DeletePriceList remove uma tabela de preços e (em cascata) seus preços.
+func (r *PriceRepository) DeletePriceList(ctx context.Context, id string) error {
+	r.logger.WithContext(ctx).Debug("Iniciando DeletePriceList no repositório.", map[string]interface{}{"id": id})
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctxTimeout, `DELETE FROM price_lists WHERE id = $1 AND tenant_id = $2`, id, tenant.ID(ctx))
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao deletar tabela de preços do DB.", err)
+		return errors.NewDBError("Falha ao deletar tabela de preços", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDBError("Falha ao verificar linhas afetadas", err)
+	}
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError(fmt.Sprintf("Tabela de preços com ID %s não encontrada para exclusão.", id))
+	}
+
+	r.logger.WithContext(ctx).Info("Tabela de preços deletada com sucesso.", map[string]interface{}{"id": id})
+	return nil
+}
+
+// --- Preços por Variante ---
+
+// CreateVariantPrice insere um preço de variante em uma tabela de preços.
+func (r *PriceRepository) CreateVariantPrice(ctx context.Context, price domain.VariantPrice) (domain.VariantPrice, error) {
+	r.logger.WithContext(ctx).Debug("Iniciando CreateVariantPrice no repositório.", map[string]interface{}{"price_list_id": price.PriceListID, "variant_id": price.VariantID})
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	if price.ID == "" {
+		price.ID = uuid.New().String()
+	}
+	now := time.Now().UTC()
+
+	// A tabela de preços e a variante precisam ser da empresa: sem elas o SELECT não produz linha
+	query := `
+        INSERT INTO variant_prices (id, price_list_id, variant_id, amount, valid_from, valid_to, created_at, updated_at, tenant_id)
+        SELECT $1, pl.id, $3, $4, $5, $6, $7, $7, pl.tenant_id
+        FROM price_lists pl
+        WHERE pl.id = $2 AND pl.tenant_id = $8
+          AND EXISTS (SELECT 1 FROM variants v WHERE v.id = $3 AND v.tenant_id = pl.tenant_id)
+        RETURNING ` + variantPriceColumns
+
+	err := scanVariantPrice(r.DB.QueryRowContext(ctxTimeout, query,
+		price.ID, price.PriceListID, price.VariantID, price.Amount, price.ValidFrom, price.ValidTo, now, tenant.ID(ctx),
+	), &price)
+	if err == sql.ErrNoRows || pqCode(err) == pqForeignKeyViolation {
+		// Sem linha inserida, descobre qual das duas faltou (a FK cobre remoções concorrentes)
+		var listExists bool
+		if err := r.DB.QueryRowContext(ctxTimeout, `SELECT EXISTS (SELECT 1 FROM price_lists WHERE id = $1 AND tenant_id = $2)`,
+			price.PriceListID, tenant.ID(ctx)).Scan(&listExists); err != nil {
+			r.logger.WithContext(ctx).Error("Falha ao verificar tabela de preços no DB.", err)
+			return domain.VariantPrice{}, errors.NewDBError("Falha ao criar preço de variante", err)
+		}
+		if !listExists {
+			return domain.VariantPrice{}, errors.NewNotFoundError(fmt.Sprintf("Tabela de preços com ID %s não encontrada.", price.PriceListID))
+		}
+		return domain.VariantPrice{}, errors.NewNotFoundError(fmt.Sprintf("Variante com ID %s não encontrada.", price.VariantID))
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao inserir preço de variante no DB.", err)
+		return domain.VariantPrice{}, errors.NewDBError("Falha ao criar preço de variante", err)
+	}
+
+	r.logger.WithContext(ctx).Info("Preço de variante criado com sucesso.", map[string]interface{}{"id": price.ID})
+	return price, nil
+}
+
+// GetVariantPrices lista os preços de uma tabela, opcionalmente restritos a uma variante.
+func (r *PriceRepository) GetVariantPrices(ctx context.Context, priceListID, variantID string) ([]domain.VariantPrice, error) {
+	r.logger.WithContext(ctx).Debug("Iniciando GetVariantPrices no repositório.", map[string]interface{}{"price_list_id": priceListID, "variant_id": variantID})
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	query := `SELECT ` + variantPriceColumns + ` FROM variant_prices WHERE price_list_id = $1 AND tenant_id = $2`
+	args := []interface{}{priceListID, tenant.ID(ctx)}
+	if variantID != "" {
+		query += ` AND variant_id = $3`
+		args = append(args, variantID)
+	}
+	query += ` ORDER BY variant_id, valid_from NULLS FIRST, created_at`
+
+	rows, err := r.DB.QueryContext(ctxTimeout, query, args...)
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao executar GetVariantPrices query.", err)
+		return nil, errors.NewDBError("Falha ao buscar preços de variantes", err)
+	}
+	defer rows.Close()
+
+	prices := []domain.VariantPrice{}
+	for rows.Next() {
+		var price domain.VariantPrice
+		if err := scanVariantPrice(rows, &price); err != nil {
+			r.logger.WithContext(ctx).Error("Falha ao mapear preço de variante.", err)
+			return nil, errors.NewDBError("Falha ao mapear preços de variantes do DB", err)
+		}
+		prices = append(prices, price)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDBError("Erro após iteração de preços de variantes", err)
+	}
+
+	return prices, nil
+}
+
+// DeleteVariantPrice remove um preço de variante de uma tabela de preços.
+func (r *PriceRepository) DeleteVariantPrice(ctx context.Context, priceListID, id string) error {
+	r.logger.WithContext(ctx).Debug("Iniciando DeleteVariantPrice no repositório.", map[string]interface{}{"price_list_id": priceListID, "id": id})
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctxTimeout, `DELETE FROM variant_prices WHERE id = $1 AND price_list_id = $2 AND tenant_id = $3`, id, priceListID, tenant.ID(ctx))
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao deletar preço de variante do DB.", err)
+		return errors.NewDBError("Falha ao deletar preço de variante", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDBError("Falha ao verificar linhas afetadas", err)
+	}
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError(fmt.Sprintf("Preço com ID %s não encontrado na tabela %s.", id, priceListID))
+	}
+
+	r.logger.WithContext(ctx).Info("Preço de variante deletado com sucesso.", map[string]interface{}{"id": id})
+	return nil
+}
+
+// FindEffectivePrice retorna o preço vigente da variante na tabela no instante 'at'.
+// Quando várias janelas se sobrepõem (ex.: uma promoção sobre o preço padrão), vence a de
+// início mais recente; em caso de empate, o preço cadastrado por último.
+func (r *PriceRepository) FindEffectivePrice(ctx context.Context, priceListID, variantID string, at time.Time) (domain.VariantPrice, error) {
+	r.logger.WithContext(ctx).Debug("Iniciando FindEffectivePrice no repositório.", map[string]interface{}{"price_list_id": priceListID, "variant_id": variantID, "at": at})
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	query := `
+        SELECT ` + variantPriceColumns + `
+        FROM variant_prices
+        WHERE price_list_id = $1 AND variant_id = $2 AND tenant_id = $4
+          AND (valid_from IS NULL OR valid_from <= $3)
+          AND (valid_to IS NULL OR valid_to > $3)
+        ORDER BY valid_from DESC NULLS LAST, created_at DESC
+        LIMIT 1`
+
+	var price domain.VariantPrice
+	err := scanVariantPrice(r.DB.QueryRowContext(ctxTimeout, query, priceListID, variantID, at, tenant.ID(ctx)), &price)
+	if err == sql.ErrNoRows {
+		return domain.VariantPrice{}, errors.NewNotFoundError(fmt.Sprintf("Nenhum preço vigente para a variante %s na tabela %s.", variantID, priceListID))
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao resolver preço de variante no DB.", err)
+		return domain.VariantPrice{}, errors.NewDBError("Falha ao resolver preço", err)
+	}
+
+	return price, nil
+}
@@ -0,0 +1,157 @@
+package productrepo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"gostock/internal/domain"
+	"gostock/internal/errors"
+	"gostock/internal/pkg/tenant"
+)
+
+const attributeDefinitionColumns = `id, name, allowed_values, created_at, updated_at`
+
+func scanAttributeDefinition(row interface{ Scan(...interface{}) error }) (domain.AttributeDefinition, error) {
+	var (
+		d      domain.AttributeDefinition
+		values []byte
+	)
+	if err := row.Scan(&d.ID, &d.Name, &values, &d.CreatedAt, &d.UpdatedAt); err != nil {
+		return domain.AttributeDefinition{}, err
+	}
+	if err := json.Unmarshal(values, &d.AllowedValues); err != nil {
+		return domain.AttributeDefinition{}, err
+	}
+	return d, nil
+}
+
+// CreateAttributeDefinition grava uma nova definição de atributo.
+// Nomes repetidos (sem diferenciar maiúsculas) retornam ConflictError.
+func (r *ProductRepository) CreateAttributeDefinition(ctx context.Context, def domain.AttributeDefinition) (domain.AttributeDefinition, error) {
+	r.logger.WithContext(ctx).Debug("Iniciando CreateAttributeDefinition no repositório.", map[string]interface{}{"name": def.Name})
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	if def.ID == "" {
+		def.ID = uuid.New().String()
+	}
+	values, err := json.Marshal(def.AllowedValues)
+	if err != nil {
+		return domain.AttributeDefinition{}, errors.NewInternalError("Falha ao serializar valores do atributo.", err)
+	}
+	now := time.Now().UTC()
+
+	query := `
+        INSERT INTO attribute_definitions (id, name, allowed_values, created_at, updated_at, tenant_id)
+        VALUES ($1, $2, $3, $4, $4, $5)
+        RETURNING ` + attributeDefinitionColumns
+
+	created, err := scanAttributeDefinition(r.DB.QueryRowContext(ctxTimeout, query, def.ID, def.Name, values, now, tenant.ID(ctx)))
+	if isUniqueViolation(err) {
+		return domain.AttributeDefinition{}, errors.NewConflictError(fmt.Sprintf("O atributo '%s' já está definido.", def.Name))
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao inserir definição de atributo no DB.", err)
+		return domain.AttributeDefinition{}, errors.NewDBError("Falha ao gravar definição de atributo", err)
+	}
+
+	r.logger.WithContext(ctx).Info("Definição de atributo criada.", map[string]interface{}{"attribute_id": created.ID, "name": created.Name})
+	return created, nil
+}
+
+// FindAttributeDefinitionByID busca uma definição de atributo pelo ID.
+func (r *ProductRepository) FindAttributeDefinitionByID(ctx context.Context, id string) (domain.AttributeDefinition, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	query := `SELECT ` + attributeDefinitionColumns + ` FROM attribute_definitions WHERE id = $1 AND tenant_id = $2`
+	def, err := scanAttributeDefinition(r.DB.QueryRowContext(ctxTimeout, query, id, tenant.ID(ctx)))
+	if err == sql.ErrNoRows {
+		return domain.AttributeDefinition{}, errors.NewNotFoundError(fmt.Sprintf("Atributo com ID %s não encontrado.", id))
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao buscar definição de atributo no DB.", err)
+		return domain.AttributeDefinition{}, errors.NewDBError("Falha ao buscar definição de atributo", err)
+	}
+	return def, nil
+}
+
+// FindAttributeDefinitions lista todas as definições de atributos, ordenadas por nome.
+func (r *ProductRepository) FindAttributeDefinitions(ctx context.Context) ([]domain.AttributeDefinition, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctxTimeout, `SELECT `+attributeDefinitionColumns+` FROM attribute_definitions WHERE tenant_id = $1 ORDER BY name`, tenant.ID(ctx))
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao listar definições de atributos no DB.", err)
+		return nil, errors.NewDBError("Falha ao listar definições de atributos", err)
+	}
+	defer rows.Close()
+
+	defs := make([]domain.AttributeDefinition, 0)
+	for rows.Next() {
+		def, err := scanAttributeDefinition(rows)
+		if err != nil {
+			r.logger.WithContext(ctx).Error("Falha ao mapear definição de atributo do DB.", err)
+			return nil, errors.NewDBError("Falha ao mapear definições de atributos", err)
+		}
+		defs = append(defs, def)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDBError("Erro após iteração de definições de atributos", err)
+	}
+	return defs, nil
+}
+
+// UpdateAttributeDefinition substitui o nome e os valores permitidos de uma definição.
+// Variantes existentes não são alteradas: os valores só restringem novas combinações.
+func (r *ProductRepository) UpdateAttributeDefinition(ctx context.Context, def domain.AttributeDefinition) (domain.AttributeDefinition, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	values, err := json.Marshal(def.AllowedValues)
+	if err != nil {
+		return domain.AttributeDefinition{}, errors.NewInternalError("Falha ao serializar valores do atributo.", err)
+	}
+
+	query := `
+        UPDATE attribute_definitions
+        SET name = $2, allowed_values = $3, updated_at = $4
+        WHERE id = $1 AND tenant_id = $5
+        RETURNING ` + attributeDefinitionColumns
+
+	updated, err := scanAttributeDefinition(r.DB.QueryRowContext(ctxTimeout, query, def.ID, def.Name, values, time.Now().UTC(), tenant.ID(ctx)))
+	if err == sql.ErrNoRows {
+		return domain.AttributeDefinition{}, errors.NewNotFoundError(fmt.Sprintf("Atributo com ID %s não encontrado.", def.ID))
+	}
+	if isUniqueViolation(err) {
+		return domain.AttributeDefinition{}, errors.NewConflictError(fmt.Sprintf("O atributo '%s' já está definido.", def.Name))
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao atualizar definição de atributo no DB.", err)
+		return domain.AttributeDefinition{}, errors.NewDBError("Falha ao atualizar definição de atributo", err)
+	}
+	return updated, nil
+}
+
+// DeleteAttributeDefinition remove uma definição de atributo. As variantes que usam o atributo
+// são mantidas; o eixo apenas deixa de ter valores restritos.
+func (r *ProductRepository) DeleteAttributeDefinition(ctx context.Context, id string) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctxTimeout, `DELETE FROM attribute_definitions WHERE id = $1 AND tenant_id = $2`, id, tenant.ID(ctx))
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao remover definição de atributo no DB.", err)
+		return errors.NewDBError("Falha ao remover definição de atributo", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.NewNotFoundError(fmt.Sprintf("Atributo com ID %s não encontrado.", id))
+	}
+	return nil
+}
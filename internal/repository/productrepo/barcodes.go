@@ -0,0 +1,147 @@
+package productrepo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"gostock/internal/domain"
+	"gostock/internal/errors"
+	"gostock/internal/pkg/tenant"
+)
+
+// FindVariantByBarcode localiza a variante dona de um código de barras. O código principal
+// (variants.barcode) tem precedência sobre os códigos adicionais (variant_barcodes). GTINs são
+// comparados em todas as formas equivalentes (domain.BarcodeForms), com ou sem zeros à esquerda.
+// O resultado traz a variante, se o código é o principal e a unidade que ele representa; o produto
+// não é preenchido.
+func (r *ProductRepository) FindVariantByBarcode(ctx context.Context, code string) (domain.BarcodeLookup, error) {
+	r.logger.WithContext(ctx).Debug("Iniciando busca de variante por código de barras.", map[string]interface{}{"barcode": code})
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	query := `
+        SELECT v.id, v.product_id, v.attribute, v.value, v.attributes, v.barcode, v.price_diff, TRUE AS is_primary, '' AS unit
+        FROM variants v
+        WHERE v.barcode = ANY($1) AND v.tenant_id = $2
+        UNION ALL
+        SELECT v.id, v.product_id, v.attribute, v.value, v.attributes, v.barcode, v.price_diff, FALSE, vb.unit
+        FROM variant_barcodes vb
+        JOIN variants v ON v.id = vb.variant_id
+        WHERE vb.barcode = ANY($1) AND vb.tenant_id = $2
+        ORDER BY is_primary DESC
+        LIMIT 1`
+
+	lookup := domain.BarcodeLookup{Barcode: code}
+	v := &lookup.Variant
+	var priceDiff sql.NullFloat64
+	var attributes []byte
+	err := r.DB.QueryRowContext(ctxTimeout, query, pq.Array(domain.BarcodeForms(code)), tenant.ID(ctx)).Scan(
+		&v.ID, &v.ProductID, &v.Attribute, &v.Value, &attributes, &v.Barcode, &priceDiff, &lookup.Primary, &lookup.Unit,
+	)
+	if err == nil {
+		err = json.Unmarshal(attributes, &v.Attributes)
+	}
+	if err == sql.ErrNoRows {
+		r.logger.WithContext(ctx).Info("Código de barras não encontrado.", map[string]interface{}{"barcode": code})
+		return domain.BarcodeLookup{}, errors.NewNotFoundError(fmt.Sprintf("Nenhuma variante possui o código de barras '%s'.", code))
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao buscar variante por código de barras no DB.", err)
+		return domain.BarcodeLookup{}, errors.NewDBError("Falha ao buscar código de barras", err)
+	}
+	if priceDiff.Valid {
+		v.PriceDiff = priceDiff.Float64
+	}
+	return lookup, nil
+}
+
+// InsertVariantBarcode cadastra um código de barras adicional para uma variante existente.
+// Retorna NotFoundError se a variante não existir e ConflictError se o código (em qualquer forma
+// equivalente) já estiver em uso, seja como código principal ou adicional de qualquer variante da empresa.
+func (r *ProductRepository) InsertVariantBarcode(ctx context.Context, barcode domain.VariantBarcode) (domain.VariantBarcode, error) {
+	r.logger.WithContext(ctx).Debug("Iniciando InsertVariantBarcode no repositório.", map[string]interface{}{"barcode": barcode.Barcode, "variant_id": barcode.VariantID})
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	tenantID := tenant.ID(ctx)
+	var exists bool
+	if err := r.DB.QueryRowContext(ctxTimeout, `SELECT EXISTS (SELECT 1 FROM variants WHERE id = $1 AND tenant_id = $2)`, barcode.VariantID, tenantID).Scan(&exists); err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao verificar variante no DB.", err)
+		return domain.VariantBarcode{}, errors.NewDBError("Falha ao verificar variante", err)
+	}
+	if !exists {
+		return domain.VariantBarcode{}, errors.NewNotFoundError(fmt.Sprintf("Variante com ID %s não encontrada.", barcode.VariantID))
+	}
+
+	// O INSERT só ocorre se nenhuma forma equivalente do código for usada por outra variante;
+	// a chave primária de variant_barcodes impede duplicidade exata entre os adicionais.
+	query := `
+        INSERT INTO variant_barcodes (barcode, variant_id, unit, created_at, tenant_id)
+        SELECT $1, $2, $3, $4, $5
+        WHERE NOT EXISTS (SELECT 1 FROM variants WHERE barcode = ANY($6) AND tenant_id = $5)
+          AND NOT EXISTS (SELECT 1 FROM variant_barcodes WHERE barcode = ANY($6) AND tenant_id = $5)
+        RETURNING created_at`
+
+	forms := pq.Array(domain.BarcodeForms(barcode.Barcode))
+	err := r.DB.QueryRowContext(ctxTimeout, query, barcode.Barcode, barcode.VariantID, barcode.Unit, time.Now().UTC(), tenantID, forms).Scan(&barcode.CreatedAt)
+	if err == sql.ErrNoRows || isUniqueViolation(err) {
+		r.logger.WithContext(ctx).Warn("Código de barras já está em uso.", map[string]interface{}{"barcode": barcode.Barcode})
+		return domain.VariantBarcode{}, errors.NewConflictError(fmt.Sprintf("O código de barras '%s' já está em uso.", barcode.Barcode))
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao inserir código de barras adicional no DB.", err)
+		return domain.VariantBarcode{}, errors.NewDBError("Falha ao gravar código de barras", err)
+	}
+
+	r.logger.WithContext(ctx).Info("Código de barras adicional cadastrado.", map[string]interface{}{"barcode": barcode.Barcode, "variant_id": barcode.VariantID})
+	return barcode, nil
+}
+
+// FindVariantBarcodes lista os códigos de barras adicionais de uma variante.
+func (r *ProductRepository) FindVariantBarcodes(ctx context.Context, variantID string) ([]domain.VariantBarcode, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctxTimeout,
+		`SELECT barcode, variant_id, unit, created_at FROM variant_barcodes WHERE variant_id = $1 AND tenant_id = $2 ORDER BY created_at, barcode`, variantID, tenant.ID(ctx))
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao listar códigos de barras adicionais no DB.", err)
+		return nil, errors.NewDBError("Falha ao listar códigos de barras", err)
+	}
+	defer rows.Close()
+
+	barcodes := make([]domain.VariantBarcode, 0)
+	for rows.Next() {
+		var b domain.VariantBarcode
+		if err := rows.Scan(&b.Barcode, &b.VariantID, &b.Unit, &b.CreatedAt); err != nil {
+			return nil, errors.NewDBError("Falha ao mapear códigos de barras", err)
+		}
+		barcodes = append(barcodes, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDBError("Erro após iteração de códigos de barras", err)
+	}
+	return barcodes, nil
+}
+
+// DeleteVariantBarcode remove um código de barras adicional. Códigos principais não são
+// removidos por aqui (fazem parte da variante) e resultam em NotFoundError.
+func (r *ProductRepository) DeleteVariantBarcode(ctx context.Context, code string) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctxTimeout, `DELETE FROM variant_barcodes WHERE barcode = ANY($1) AND tenant_id = $2`, pq.Array(domain.BarcodeForms(code)), tenant.ID(ctx))
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao remover código de barras adicional no DB.", err)
+		return errors.NewDBError("Falha ao remover código de barras", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.NewNotFoundError(fmt.Sprintf("Código de barras adicional '%s' não encontrado.", code))
+	}
+	return nil
+}
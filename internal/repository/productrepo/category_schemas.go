@@ -0,0 +1,143 @@
+package productrepo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gostock/internal/domain"
+	"gostock/internal/errors"
+	"gostock/internal/pkg/tenant"
+)
+
+const categorySchemaColumns = `category, fields, created_at, updated_at`
+
+func scanCategorySchema(row interface{ Scan(...interface{}) error }) (domain.CategorySchema, error) {
+	var (
+		s      domain.CategorySchema
+		fields []byte
+	)
+	if err := row.Scan(&s.Category, &fields, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		return domain.CategorySchema{}, err
+	}
+	if err := json.Unmarshal(fields, &s.Fields); err != nil {
+		return domain.CategorySchema{}, err
+	}
+	return s, nil
+}
+
+// UpsertCategorySchema cria ou substitui o esquema de atributos personalizados de uma categoria.
+// A categoria é casada sem diferenciar maiúsculas, mantendo a grafia do primeiro cadastro.
+func (r *ProductRepository) UpsertCategorySchema(ctx context.Context, schema domain.CategorySchema) (domain.CategorySchema, error) {
+	r.logger.WithContext(ctx).Debug("Iniciando UpsertCategorySchema no repositório.", map[string]interface{}{"category": schema.Category})
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	fields, err := json.Marshal(schema.Fields)
+	if err != nil {
+		return domain.CategorySchema{}, errors.NewInternalError("Falha ao serializar campos do esquema.", err)
+	}
+	now := time.Now().UTC()
+
+	query := `
+        INSERT INTO category_schemas (category, fields, created_at, updated_at, tenant_id)
+        VALUES ($1, $2, $3, $3, $4)
+        ON CONFLICT (tenant_id, (LOWER(category))) DO UPDATE
+            SET fields = EXCLUDED.fields, updated_at = EXCLUDED.updated_at
+        RETURNING ` + categorySchemaColumns
+
+	saved, err := scanCategorySchema(r.DB.QueryRowContext(ctxTimeout, query, schema.Category, fields, now, tenant.ID(ctx)))
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao gravar esquema de categoria no DB.", err)
+		return domain.CategorySchema{}, errors.NewDBError("Falha ao gravar esquema de categoria", err)
+	}
+
+	// Os produtos em cache carregam os atributos já validados; o novo esquema vale para as próximas gravações.
+	r.logger.WithContext(ctx).Info("Esquema de categoria gravado.", map[string]interface{}{"category": saved.Category, "fields": len(saved.Fields)})
+	return saved, nil
+}
+
+// FindCategorySchema busca o esquema de uma categoria (sem diferenciar maiúsculas).
+func (r *ProductRepository) FindCategorySchema(ctx context.Context, category string) (domain.CategorySchema, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	query := `SELECT ` + categorySchemaColumns + ` FROM category_schemas WHERE LOWER(category) = LOWER($1) AND tenant_id = $2`
+	schema, err := scanCategorySchema(r.DB.QueryRowContext(ctxTimeout, query, category, tenant.ID(ctx)))
+	if err == sql.ErrNoRows {
+		return domain.CategorySchema{}, errors.NewNotFoundError(fmt.Sprintf("A categoria '%s' não possui esquema de atributos.", category))
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao buscar esquema de categoria no DB.", err)
+		return domain.CategorySchema{}, errors.NewDBError("Falha ao buscar esquema de categoria", err)
+	}
+	return schema, nil
+}
+
+// FindCategorySchemas lista os esquemas de todas as categorias, ordenados pelo nome da categoria.
+func (r *ProductRepository) FindCategorySchemas(ctx context.Context) ([]domain.CategorySchema, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctxTimeout, `SELECT `+categorySchemaColumns+` FROM category_schemas WHERE tenant_id = $1 ORDER BY category`, tenant.ID(ctx))
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao listar esquemas de categorias no DB.", err)
+		return nil, errors.NewDBError("Falha ao listar esquemas de categorias", err)
+	}
+	defer rows.Close()
+
+	schemas := make([]domain.CategorySchema, 0)
+	for rows.Next() {
+		schema, err := scanCategorySchema(rows)
+		if err != nil {
+			r.logger.WithContext(ctx).Error("Falha ao mapear esquema de categoria do DB.", err)
+			return nil, errors.NewDBError("Falha ao mapear esquemas de categorias", err)
+		}
+		schemas = append(schemas, schema)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDBError("Erro após iteração de esquemas de categorias", err)
+	}
+	return schemas, nil
+}
+
+// DeleteCategorySchema remove o esquema de uma categoria. Os produtos mantêm os atributos
+// gravados, mas novas gravações com atributos personalizados nessa categoria passam a ser recusadas.
+func (r *ProductRepository) DeleteCategorySchema(ctx context.Context, category string) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctxTimeout, `DELETE FROM category_schemas WHERE LOWER(category) = LOWER($1) AND tenant_id = $2`, category, tenant.ID(ctx))
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao remover esquema de categoria no DB.", err)
+		return errors.NewDBError("Falha ao remover esquema de categoria", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.NewNotFoundError(fmt.Sprintf("A categoria '%s' não possui esquema de atributos.", category))
+	}
+	return nil
+}
+
+// UpdateProductCustomAttributes grava a categoria e os atributos personalizados de um produto.
+func (r *ProductRepository) UpdateProductCustomAttributes(ctx context.Context, product domain.Product) (domain.Product, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctxTimeout,
+		`UPDATE products SET category = $2, custom_attributes = $3, updated_at = $4 WHERE id = $1 AND tenant_id = $5`,
+		product.ID, product.Category, customAttributesJSON(product), product.UpdatedAt, tenant.ID(ctx))
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao atualizar atributos personalizados no DB.", err)
+		return domain.Product{}, errors.NewDBError("Falha ao atualizar atributos personalizados", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return domain.Product{}, errors.NewNotFoundError(fmt.Sprintf("Produto com ID %s não existe na base de dados.", product.ID))
+	}
+
+	if cacheErr := r.Cache.Delete(ctx, productKey(ctx, product.ID)); cacheErr != nil {
+		r.logger.WithContext(ctx).Warn("Falha ao invalidar cache do produto após atualizar atributos.", map[string]interface{}{"product_id": product.ID, "error": cacheErr.Error()})
+	}
+	return product, nil
+}
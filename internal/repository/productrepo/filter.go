@@ -0,0 +1,184 @@
+package productrepo
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"gostock/internal/domain"
+)
+
+// FilterClause monta as condições WHERE (iniciadas por " AND") para um domain.ProductFilter.
+// As colunas são qualificadas com "products." para que a cláusula possa ser usada em queries
+// com JOIN (ex: exportações). Todos os valores são parametrizados a partir de $argStart;
+// retorna a cláusula, os argumentos e o próximo índice de parâmetro livre.
+func FilterClause(filter domain.ProductFilter, argStart int) (string, []interface{}, int) {
+	clause := ""
+	args := []interface{}{}
+	argCounter := argStart // Contador para os parâmetros SQL ($1, $2, ...)
+
+	// Aplicar Filtros (Exemplo: Name e SKU)
+	if filter.Name != "" {
+		clause += fmt.Sprintf(` AND products.name ILIKE $%d ESCAPE '\'`, argCounter) // ILIKE para busca case-insensitive
+		args = append(args, "%"+escapeLike(filter.Name)+"%")
+		argCounter++
+	}
+
+	if filter.SKU != "" {
+		clause += fmt.Sprintf(" AND products.sku = $%d", argCounter)
+		args = append(args, filter.SKU)
+		argCounter++
+	}
+
+	if filter.ActiveOnly {
+		clause += fmt.Sprintf(" AND products.is_active = $%d", argCounter)
+		args = append(args, true)
+		argCounter++
+	}
+
+	// Faixa de preço
+	if filter.MinPrice != nil {
+		clause += fmt.Sprintf(" AND products.price >= $%d", argCounter)
+		args = append(args, *filter.MinPrice)
+		argCounter++
+	}
+	if filter.MaxPrice != nil {
+		clause += fmt.Sprintf(" AND products.price <= $%d", argCounter)
+		args = append(args, *filter.MaxPrice)
+		argCounter++
+	}
+
+	// Faixas de data de criação/atualização
+	if filter.CreatedFrom != nil {
+		clause += fmt.Sprintf(" AND products.created_at >= $%d", argCounter)
+		args = append(args, *filter.CreatedFrom)
+		argCounter++
+	}
+	if filter.CreatedTo != nil {
+		clause += fmt.Sprintf(" AND products.created_at <= $%d", argCounter)
+		args = append(args, *filter.CreatedTo)
+		argCounter++
+	}
+	if filter.UpdatedFrom != nil {
+		clause += fmt.Sprintf(" AND products.updated_at >= $%d", argCounter)
+		args = append(args, *filter.UpdatedFrom)
+		argCounter++
+	}
+	if filter.UpdatedTo != nil {
+		clause += fmt.Sprintf(" AND products.updated_at <= $%d", argCounter)
+		args = append(args, *filter.UpdatedTo)
+		argCounter++
+	}
+
+	// Filtros sobre variantes (subconsultas EXISTS para não duplicar linhas de produto)
+	if filter.HasVariants != nil {
+		if *filter.HasVariants {
+			clause += " AND EXISTS (SELECT 1 FROM variants v WHERE v.product_id = products.id)"
+		} else {
+			clause += " AND NOT EXISTS (SELECT 1 FROM variants v WHERE v.product_id = products.id)"
+		}
+	}
+	if filter.Barcode != "" {
+		clause += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM variants v WHERE v.product_id = products.id AND v.barcode = ANY($%d))", argCounter)
+		args = append(args, pq.Array(domain.BarcodeForms(filter.Barcode)))
+		argCounter++
+	}
+	if filter.AttributeName != "" {
+		// Procura o eixo em qualquer posição da combinação de atributos da variante
+		clause += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM variants v, jsonb_each_text(v.attributes) a WHERE v.product_id = products.id AND a.key ILIKE $%d ESCAPE '\\'", argCounter)
+		args = append(args, escapeLike(filter.AttributeName))
+		argCounter++
+		if filter.AttributeValue != "" {
+			clause += fmt.Sprintf(` AND a.value ILIKE $%d ESCAPE '\'`, argCounter)
+			args = append(args, escapeLike(filter.AttributeValue))
+			argCounter++
+		}
+		clause += ")"
+	}
+	if len(filter.Attributes) > 0 {
+		// Todos os pares devem pertencer à mesma variante (contenção JSONB)
+		attributes, _ := json.Marshal(filter.Attributes)
+		clause += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM variants v WHERE v.product_id = products.id AND v.attributes @> $%d::jsonb)", argCounter)
+		args = append(args, string(attributes))
+		argCounter++
+	}
+
+	// Categoria e atributos personalizados do produto
+	if filter.Category != "" {
+		clause += fmt.Sprintf(" AND LOWER(products.category) = LOWER($%d)", argCounter)
+		args = append(args, filter.Category)
+		argCounter++
+	}
+	for _, name := range sortedKeys(filter.CustomAttributes) {
+		// O valor chega como texto; "10" casa tanto com o número 10 quanto com o texto "10"
+		typed, text := customAttributeFilterJSON(name, filter.CustomAttributes[name])
+		clause += fmt.Sprintf(" AND (products.custom_attributes @> $%d::jsonb OR products.custom_attributes @> $%d::jsonb)", argCounter, argCounter+1)
+		args = append(args, typed, text)
+		argCounter += 2
+	}
+
+	return clause, args, argCounter
+}
+
+// customAttributeFilterJSON monta os objetos JSONB usados na contenção de um filtro por atributo
+// personalizado: o valor interpretado (número ou booleano, quando possível) e o valor como texto.
+func customAttributeFilterJSON(name, raw string) (string, string) {
+	var typed interface{} = raw
+	if b, err := strconv.ParseBool(raw); err == nil {
+		typed = b
+	} else if n, err := strconv.ParseFloat(raw, 64); err == nil && !math.IsInf(n, 0) && !math.IsNaN(n) {
+		typed = n
+	}
+	typedJSON, _ := json.Marshal(map[string]interface{}{name: typed})
+	textJSON, _ := json.Marshal(map[string]string{name: raw})
+	return string(typedJSON), string(textJSON)
+}
+
+// likeEscaper escapa os curingas do LIKE/ILIKE, para que "50%" ou "a_b" sejam buscados literalmente
+// (as cláusulas declaram ESCAPE '\').
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike retorna o valor com os curingas do LIKE escapados.
+func escapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
+
+// sortedKeys retorna as chaves do mapa em ordem, para que a query gerada seja determinística.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// productSortColumns é a whitelist de campos de ordenação para colunas SQL.
+// Valores fora deste mapa nunca chegam à query.
+var productSortColumns = map[string]string{
+	domain.ProductSortName:      "products.name",
+	domain.ProductSortPrice:     "products.price",
+	domain.ProductSortSKU:       "products.sku",
+	domain.ProductSortCreatedAt: "products.created_at",
+	domain.ProductSortUpdatedAt: "products.updated_at",
+}
+
+// OrderByClause monta a lista de ordenação (sem o "ORDER BY") a partir do filtro,
+// usando created_at DESC quando o campo não é informado ou não é suportado.
+// O id do produto entra como desempate para manter a paginação estável.
+func OrderByClause(sortBy, sortOrder string) string {
+	column, ok := productSortColumns[sortBy]
+	if !ok {
+		return "products.created_at DESC, products.id"
+	}
+	direction := "ASC"
+	if sortOrder == domain.SortDesc {
+		direction = "DESC"
+	}
+	return column + " " + direction + ", products.id"
+}
@@ -0,0 +1,268 @@
+package productrepo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"gostock/internal/domain"
+	"gostock/internal/errors"
+	"gostock/internal/pkg/tenant"
+)
+
+// Códigos de erro do PostgreSQL tratados pelo repositório.
+const (
+	pqUniqueViolation     = "23505"
+	pqForeignKeyViolation = "23503"
+)
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return stderrors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return stderrors.As(err, &pqErr) && pqErr.Code == pqForeignKeyViolation
+}
+
+const productVersionColumns = `id, product_id, version, change_type, changes, snapshot, changed_by, changed_by_role, reverted_to, created_at`
+
+func scanProductVersion(row interface{ Scan(...interface{}) error }) (domain.ProductVersion, error) {
+	var (
+		v                 domain.ProductVersion
+		changes, snapshot []byte
+		changedBy         sql.NullString
+		revertedTo        sql.NullInt64
+	)
+	err := row.Scan(&v.ID, &v.ProductID, &v.Version, &v.ChangeType, &changes, &snapshot, &changedBy, &v.ChangedByRole, &revertedTo, &v.CreatedAt)
+	if err != nil {
+		return domain.ProductVersion{}, err
+	}
+	if err := json.Unmarshal(changes, &v.Changes); err != nil {
+		return domain.ProductVersion{}, err
+	}
+	if err := json.Unmarshal(snapshot, &v.Snapshot); err != nil {
+		return domain.ProductVersion{}, err
+	}
+	v.ChangedBy = changedBy.String
+	if revertedTo.Valid {
+		version := int(revertedTo.Int64)
+		v.RevertedTo = &version
+	}
+	return v, nil
+}
+
+// InsertProductVersion grava uma nova versão no histórico do produto.
+// Se o número da versão já existir (gravação concorrente), retorna ConflictError.
+func (r *ProductRepository) InsertProductVersion(ctx context.Context, version domain.ProductVersion) (domain.ProductVersion, error) {
+	r.logger.WithContext(ctx).Debug("Iniciando InsertProductVersion no repositório.", map[string]interface{}{"product_id": version.ProductID, "version": version.Version})
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	if version.ID == "" {
+		version.ID = uuid.New().String()
+	}
+	changes, err := json.Marshal(version.Changes)
+	if err != nil {
+		return domain.ProductVersion{}, errors.NewInternalError("Falha ao serializar alterações do produto.", err)
+	}
+	snapshot, err := json.Marshal(version.Snapshot)
+	if err != nil {
+		return domain.ProductVersion{}, errors.NewInternalError("Falha ao serializar snapshot do produto.", err)
+	}
+	var changedBy sql.NullString
+	if version.ChangedBy != "" {
+		changedBy = sql.NullString{String: version.ChangedBy, Valid: true}
+	}
+
+	query := `
+        INSERT INTO product_versions (id, product_id, version, change_type, changes, snapshot, changed_by, changed_by_role, reverted_to, created_at, tenant_id)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+        RETURNING ` + productVersionColumns
+
+	saved, err := scanProductVersion(r.DB.QueryRowContext(ctxTimeout, query,
+		version.ID, version.ProductID, version.Version, version.ChangeType, changes, snapshot,
+		changedBy, version.ChangedByRole, version.RevertedTo, time.Now().UTC(), tenant.ID(ctx),
+	))
+	if isUniqueViolation(err) {
+		return domain.ProductVersion{}, errors.NewConflictError(fmt.Sprintf("A versão %d do produto %s já existe.", version.Version, version.ProductID))
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao inserir versão do produto no DB.", err)
+		return domain.ProductVersion{}, errors.NewDBError("Falha ao gravar histórico do produto", err)
+	}
+
+	return saved, nil
+}
+
+// FindLatestProductVersion retorna a versão mais recente do produto (NotFoundError se não houver histórico).
+func (r *ProductRepository) FindLatestProductVersion(ctx context.Context, productID string) (domain.ProductVersion, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	query := `SELECT ` + productVersionColumns + ` FROM product_versions WHERE product_id = $1 AND tenant_id = $2 ORDER BY version DESC LIMIT 1`
+	version, err := scanProductVersion(r.DB.QueryRowContext(ctxTimeout, query, productID, tenant.ID(ctx)))
+	if err == sql.ErrNoRows {
+		return domain.ProductVersion{}, errors.NewNotFoundError(fmt.Sprintf("O produto %s não possui histórico.", productID))
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao buscar última versão do produto no DB.", err)
+		return domain.ProductVersion{}, errors.NewDBError("Falha ao buscar histórico do produto", err)
+	}
+	return version, nil
+}
+
+// FindProductVersion retorna uma versão específica do produto.
+func (r *ProductRepository) FindProductVersion(ctx context.Context, productID string, version int) (domain.ProductVersion, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	query := `SELECT ` + productVersionColumns + ` FROM product_versions WHERE product_id = $1 AND version = $2 AND tenant_id = $3`
+	found, err := scanProductVersion(r.DB.QueryRowContext(ctxTimeout, query, productID, version, tenant.ID(ctx)))
+	if err == sql.ErrNoRows {
+		return domain.ProductVersion{}, errors.NewNotFoundError(fmt.Sprintf("Versão %d do produto %s não encontrada.", version, productID))
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao buscar versão do produto no DB.", err)
+		return domain.ProductVersion{}, errors.NewDBError("Falha ao buscar histórico do produto", err)
+	}
+	return found, nil
+}
+
+// FindProductVersions lista o histórico do produto, da versão mais recente para a mais antiga.
+func (r *ProductRepository) FindProductVersions(ctx context.Context, productID string, page, limit int) ([]domain.ProductVersion, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	query := `
+        SELECT ` + productVersionColumns + `
+        FROM product_versions
+        WHERE product_id = $1 AND tenant_id = $4
+        ORDER BY version DESC
+        LIMIT $2 OFFSET $3`
+
+	rows, err := r.DB.QueryContext(ctxTimeout, query, productID, limit, (page-1)*limit, tenant.ID(ctx))
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao executar FindProductVersions query.", err)
+		return nil, errors.NewDBError("Falha ao buscar histórico do produto", err)
+	}
+	defer rows.Close()
+
+	versions := []domain.ProductVersion{}
+	for rows.Next() {
+		version, err := scanProductVersion(rows)
+		if err != nil {
+			r.logger.WithContext(ctx).Error("Falha ao mapear versão do produto.", err)
+			return nil, errors.NewDBError("Falha ao mapear histórico do produto", err)
+		}
+		versions = append(versions, version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDBError("Erro após iteração do histórico do produto", err)
+	}
+	return versions, nil
+}
+
+// RestoreProduct sobrescreve o produto e suas variantes com o estado informado (usado na reversão).
+// Variantes ausentes do estado informado são removidas, exceto se ainda tiverem estoque,
+// caso em que a operação é recusada com ConflictError.
+func (r *ProductRepository) RestoreProduct(ctx context.Context, product domain.Product) (domain.Product, error) {
+	r.logger.WithContext(ctx).Debug("Iniciando RestoreProduct no repositório.", map[string]interface{}{"product_id": product.ID})
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctxTimeout, nil)
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao iniciar transação para RestoreProduct.", err)
+		return domain.Product{}, errors.NewDBError("failed to start tx", err)
+	}
+	defer tx.Rollback() // Sem efeito após o Commit
+
+	tenantID := tenant.ID(ctx)
+	const productSQL = `
+        UPDATE products
+        SET sku = $1, name = $2, description = $3, price = $4, is_active = $5, updated_at = $6,
+            category = $8, custom_attributes = $9
+        WHERE id = $7 AND tenant_id = $10
+        RETURNING created_at`
+	err = tx.QueryRowContext(ctxTimeout, productSQL,
+		product.SKU, product.Name, product.Description, product.Price, product.IsActive, product.UpdatedAt, product.ID,
+		product.Category, customAttributesJSON(product), tenantID,
+	).Scan(&product.CreatedAt)
+	if err == sql.ErrNoRows {
+		return domain.Product{}, errors.NewNotFoundError(fmt.Sprintf("Produto com ID %s não existe na base de dados.", product.ID))
+	}
+	if isUniqueViolation(err) {
+		return domain.Product{}, errors.NewConflictError(fmt.Sprintf("O SKU '%s' já pertence a outro produto.", product.SKU))
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao restaurar produto no DB.", err)
+		return domain.Product{}, errors.NewDBError("failed to restore product", err)
+	}
+
+	keep := make([]string, 0, len(product.Variants))
+	for _, v := range product.Variants {
+		keep = append(keep, v.ID)
+	}
+
+	var stocked string
+	err = tx.QueryRowContext(ctxTimeout, `
+        SELECT v.id FROM variants v
+        WHERE v.product_id = $1 AND NOT (v.id::text = ANY($2))
+          AND EXISTS (SELECT 1 FROM stock_levels sl WHERE sl.variant_id = v.id AND sl.quantity > 0)
+        LIMIT 1`, product.ID, pq.Array(keep)).Scan(&stocked)
+	if err == nil {
+		return domain.Product{}, errors.NewConflictError(fmt.Sprintf("A variante %s não existe na versão restaurada e ainda possui estoque.", stocked))
+	}
+	if err != sql.ErrNoRows {
+		r.logger.WithContext(ctx).Error("Falha ao verificar estoque das variantes na reversão.", err)
+		return domain.Product{}, errors.NewDBError("failed to check variant stock", err)
+	}
+
+	if _, err = tx.ExecContext(ctxTimeout, `DELETE FROM variants WHERE product_id = $1 AND NOT (id::text = ANY($2))`, product.ID, pq.Array(keep)); err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao remover variantes na reversão.", err)
+		return domain.Product{}, errors.NewDBError("failed to delete variants", err)
+	}
+
+	const variantSQL = `
+        INSERT INTO variants (id, product_id, attribute, value, attributes, barcode, price_diff, tenant_id)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+        ON CONFLICT (id) DO UPDATE
+            SET attribute = EXCLUDED.attribute,
+                value = EXCLUDED.value,
+                attributes = EXCLUDED.attributes,
+                barcode = EXCLUDED.barcode,
+                price_diff = EXCLUDED.price_diff
+            WHERE variants.tenant_id = EXCLUDED.tenant_id`
+	for i := range product.Variants {
+		product.Variants[i].ProductID = product.ID
+		v := product.Variants[i]
+		_, err = tx.ExecContext(ctxTimeout, variantSQL, v.ID, v.ProductID, v.Attribute, v.Value, attributesJSON(v), v.Barcode, v.PriceDiff, tenantID)
+		if isUniqueViolation(err) {
+			return domain.Product{}, errors.NewConflictError(fmt.Sprintf("O código de barras '%s' já pertence a outro produto ou a combinação '%s' está repetida.", v.Barcode, v.Value))
+		}
+		if err != nil {
+			r.logger.WithContext(ctx).Error("Falha ao restaurar variante no DB.", err)
+			return domain.Product{}, errors.NewDBError("failed to restore variant", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao commitar transação de RestoreProduct.", err)
+		return domain.Product{}, errors.NewDBError("failed to commit tx", err)
+	}
+
+	if cacheErr := r.Cache.Delete(ctx, productKey(ctx, product.ID)); cacheErr != nil {
+		r.logger.WithContext(ctx).Warn("Falha ao invalidar cache do produto após reversão.", map[string]interface{}{"product_id": product.ID, "error": cacheErr.Error()})
+	}
+
+	r.logger.WithContext(ctx).Info("Produto restaurado com sucesso.", map[string]interface{}{"product_id": product.ID})
+	return product, nil
+}
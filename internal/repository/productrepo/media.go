@@ -0,0 +1,144 @@
+package productrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"gostock/internal/domain"
+	"gostock/internal/errors"
+	"gostock/internal/pkg/tenant"
+)
+
+const productMediaColumns = `id, product_id, COALESCE(variant_id::text, ''), url, path, content_type, alt_text, sort_order, created_at, updated_at`
+
+func scanProductMedia(row interface{ Scan(...interface{}) error }) (domain.ProductMedia, error) {
+	var m domain.ProductMedia
+	err := row.Scan(&m.ID, &m.ProductID, &m.VariantID, &m.URL, &m.Path, &m.ContentType, &m.AltText, &m.SortOrder, &m.CreatedAt, &m.UpdatedAt)
+	return m, err
+}
+
+// nullableUUID converte um ID vazio em NULL.
+func nullableUUID(id string) interface{} {
+	if id == "" {
+		return nil
+	}
+	return id
+}
+
+// InsertProductMedia grava uma mídia do produto. Produto inexistente retorna NotFoundError.
+func (r *ProductRepository) InsertProductMedia(ctx context.Context, media domain.ProductMedia) (domain.ProductMedia, error) {
+	r.logger.WithContext(ctx).Debug("Iniciando InsertProductMedia no repositório.", map[string]interface{}{"product_id": media.ProductID})
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	if media.ID == "" {
+		media.ID = uuid.New().String()
+	}
+	// O produto precisa ser da empresa: sem ele o SELECT não produz linha
+	query := `
+        INSERT INTO product_media (id, product_id, variant_id, url, path, content_type, alt_text, sort_order, created_at, updated_at, tenant_id)
+        SELECT $1, p.id, $3, $4, $5, $6, $7, $8, $9, $9, p.tenant_id
+        FROM products p
+        WHERE p.id = $2 AND p.tenant_id = $10
+        RETURNING ` + productMediaColumns
+
+	created, err := scanProductMedia(r.DB.QueryRowContext(ctxTimeout, query,
+		media.ID, media.ProductID, nullableUUID(media.VariantID), media.URL, media.Path, media.ContentType, media.AltText, media.SortOrder, time.Now().UTC(), tenant.ID(ctx)))
+	if err == sql.ErrNoRows || isForeignKeyViolation(err) {
+		return domain.ProductMedia{}, errors.NewNotFoundError(fmt.Sprintf("Produto com ID %s não existe na base de dados.", media.ProductID))
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao inserir mídia do produto no DB.", err)
+		return domain.ProductMedia{}, errors.NewDBError("Falha ao gravar mídia do produto", err)
+	}
+
+	r.logger.WithContext(ctx).Info("Mídia do produto gravada.", map[string]interface{}{"media_id": created.ID, "product_id": created.ProductID})
+	return created, nil
+}
+
+// FindProductMedia lista as mídias de um produto em ordem de exibição.
+func (r *ProductRepository) FindProductMedia(ctx context.Context, productID string) ([]domain.ProductMedia, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctxTimeout,
+		`SELECT `+productMediaColumns+` FROM product_media WHERE product_id = $1 AND tenant_id = $2 ORDER BY sort_order, created_at`, productID, tenant.ID(ctx))
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao listar mídias do produto no DB.", err)
+		return nil, errors.NewDBError("Falha ao listar mídias do produto", err)
+	}
+	defer rows.Close()
+
+	media := make([]domain.ProductMedia, 0)
+	for rows.Next() {
+		m, err := scanProductMedia(rows)
+		if err != nil {
+			r.logger.WithContext(ctx).Error("Falha ao mapear mídia do produto do DB.", err)
+			return nil, errors.NewDBError("Falha ao mapear mídias do produto", err)
+		}
+		media = append(media, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDBError("Erro após iteração de mídias do produto", err)
+	}
+	return media, nil
+}
+
+// FindProductMediaByID busca uma mídia pelo ID.
+func (r *ProductRepository) FindProductMediaByID(ctx context.Context, id string) (domain.ProductMedia, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	media, err := scanProductMedia(r.DB.QueryRowContext(ctxTimeout, `SELECT `+productMediaColumns+` FROM product_media WHERE id = $1 AND tenant_id = $2`, id, tenant.ID(ctx)))
+	if err == sql.ErrNoRows {
+		return domain.ProductMedia{}, errors.NewNotFoundError(fmt.Sprintf("Mídia com ID %s não encontrada.", id))
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao buscar mídia do produto no DB.", err)
+		return domain.ProductMedia{}, errors.NewDBError("Falha ao buscar mídia do produto", err)
+	}
+	return media, nil
+}
+
+// UpdateProductMedia atualiza a variante associada, o texto alternativo e a ordem de uma mídia.
+func (r *ProductRepository) UpdateProductMedia(ctx context.Context, media domain.ProductMedia) (domain.ProductMedia, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	query := `
+        UPDATE product_media
+        SET variant_id = $2, alt_text = $3, sort_order = $4, updated_at = $5
+        WHERE id = $1 AND tenant_id = $6
+        RETURNING ` + productMediaColumns
+
+	updated, err := scanProductMedia(r.DB.QueryRowContext(ctxTimeout, query,
+		media.ID, nullableUUID(media.VariantID), media.AltText, media.SortOrder, time.Now().UTC(), tenant.ID(ctx)))
+	if err == sql.ErrNoRows {
+		return domain.ProductMedia{}, errors.NewNotFoundError(fmt.Sprintf("Mídia com ID %s não encontrada.", media.ID))
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao atualizar mídia do produto no DB.", err)
+		return domain.ProductMedia{}, errors.NewDBError("Falha ao atualizar mídia do produto", err)
+	}
+	return updated, nil
+}
+
+// DeleteProductMedia remove o registro de uma mídia. O arquivo local, se houver, é removido pelo serviço.
+func (r *ProductRepository) DeleteProductMedia(ctx context.Context, id string) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctxTimeout, `DELETE FROM product_media WHERE id = $1 AND tenant_id = $2`, id, tenant.ID(ctx))
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao remover mídia do produto no DB.", err)
+		return errors.NewDBError("Falha ao remover mídia do produto", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.NewNotFoundError(fmt.Sprintf("Mídia com ID %s não encontrada.", id))
+	}
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	apperror "gostock/internal/errors"
 	"gostock/internal/pkg/cache"
 	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/tenant"
 )
 
 // ProductRepository implementa a interface domain.ProductRepository.
@@ -37,25 +38,26 @@ func NewProductRepository(db *sql.DB, cacheClient cache.Client, dbTimeout time.D
 // Save persiste um novo Produto e suas Variantes no banco de dados.
 // (Implementa um dos métodos da interface domain.ProductRepository)
 func (r *ProductRepository) Save(ctx context.Context, product domain.Product) (domain.Product, error) {
-	r.logger.Debug("Iniciando Save de produto no repositório.", map[string]interface{}{"sku": product.SKU})
+	r.logger.WithContext(ctx).Debug("Iniciando Save de produto no repositório.", map[string]interface{}{"sku": product.SKU})
 	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
 	defer cancel()
 
 	tx, err := r.DB.BeginTx(ctxTimeout, nil)
 	if err != nil {
-		r.logger.Error("Falha ao iniciar transação para Save de produto.", err)
+		r.logger.WithContext(ctx).Error("Falha ao iniciar transação para Save de produto.", err)
 		return domain.Product{}, errors.NewDBError("failed to start tx", err)
 	}
 
 	defer func() {
 		if err != nil {
 			tx.Rollback()
-			r.logger.Warn("Transação de Save de produto desfeita devido a erro.", nil)
+			r.logger.WithContext(ctx).Warn("Transação de Save de produto desfeita devido a erro.", nil)
 		}
 	}()
 
-	const productSQL = `INSERT INTO products (id, sku, name, description, price, is_active, created_at, updated_at)
-                         VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`
+	tenantID := tenant.ID(ctx)
+	const productSQL = `INSERT INTO products (id, sku, name, description, price, is_active, created_at, updated_at, category, custom_attributes, tenant_id)
+                         VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)`
 
 	createdAt := product.CreatedAt.Format(time.RFC3339Nano)
 	updatedAt := product.UpdatedAt.Format(time.RFC3339Nano)
@@ -69,16 +71,19 @@ func (r *ProductRepository) Save(ctx context.Context, product domain.Product) (d
 		product.IsActive,
 		createdAt,
 		updatedAt,
+		product.Category,
+		customAttributesJSON(product),
+		tenantID,
 	)
 
 	if err != nil {
-		r.logger.Error("Falha ao inserir produto no DB.", err)
+		r.logger.WithContext(ctx).Error("Falha ao inserir produto no DB.", err)
 		return domain.Product{}, errors.NewDBError("failed to insert product", err)
 	}
-	r.logger.Debug("Produto inserido no DB.", map[string]interface{}{"product_id": product.ID, "sku": product.SKU})
+	r.logger.WithContext(ctx).Debug("Produto inserido no DB.", map[string]interface{}{"product_id": product.ID, "sku": product.SKU})
 
-	const variantSQL = `INSERT INTO variants(id, product_id, attribute, value, barcode, price_diff)
-                        VALUES ($1,$2,$3,$4,$5,$6)`
+	const variantSQL = `INSERT INTO variants(id, product_id, attribute, value, attributes, barcode, price_diff, tenant_id)
+                        VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`
 
 	for _, v := range product.Variants {
 		_, err = tx.ExecContext(ctxTimeout, variantSQL,
@@ -86,33 +91,177 @@ func (r *ProductRepository) Save(ctx context.Context, product domain.Product) (d
 			v.ProductID,
 			v.Attribute,
 			v.Value,
+			attributesJSON(v),
 			v.Barcode,
 			v.PriceDiff,
+			tenantID,
 		)
+		if isUniqueViolation(err) {
+			r.logger.WithContext(ctx).Warn("Código de barras ou combinação de atributos já existe.", map[string]interface{}{"sku": product.SKU, "barcode": v.Barcode})
+			return domain.Product{}, errors.NewConflictError(fmt.Sprintf("O código de barras '%s' ou a combinação de atributos '%s' já existe.", v.Barcode, v.Value))
+		}
 		if err != nil {
-			r.logger.Error("Falha ao inserir variante no DB.", err)
+			r.logger.WithContext(ctx).Error("Falha ao inserir variante no DB.", err)
 			return domain.Product{}, errors.NewDBError("failed to insert variants", err)
 		}
-		r.logger.Debug("Variante inserida no DB.", map[string]interface{}{"variant_id": v.ID, "product_id": v.ProductID})
+		r.logger.WithContext(ctx).Debug("Variante inserida no DB.", map[string]interface{}{"variant_id": v.ID, "product_id": v.ProductID})
 	}
 
 	if err = tx.Commit(); err != nil {
-		r.logger.Error("Falha ao commitar transação para Save de produto.", err)
+		r.logger.WithContext(ctx).Error("Falha ao commitar transação para Save de produto.", err)
 		return domain.Product{}, errors.NewDBError("failed to commit tx", err)
 	}
 
-	r.logger.Info("Produto e variantes salvos com sucesso no repositório.", map[string]interface{}{"product_id": product.ID, "sku": product.SKU})
+	r.logger.WithContext(ctx).Info("Produto e variantes salvos com sucesso no repositório.", map[string]interface{}{"product_id": product.ID, "sku": product.SKU})
 	return product, nil
 }
 
+// UpsertBySKU insere o produto ou, se o SKU já existir, atualiza nome, descrição e preço.
+// As variantes são inseridas ou atualizadas pelo código de barras; um código de barras
+// que já pertence a outro produto gera ConflictError. Retorna true quando o produto foi criado.
+func (r *ProductRepository) UpsertBySKU(ctx context.Context, product domain.Product) (domain.Product, bool, error) {
+	r.logger.WithContext(ctx).Debug("Iniciando Upsert de produto por SKU no repositório.", map[string]interface{}{"sku": product.SKU})
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctxTimeout, nil)
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao iniciar transação para Upsert de produto.", err)
+		return domain.Product{}, false, errors.NewDBError("failed to start tx", err)
+	}
+	defer tx.Rollback() // Sem efeito após o Commit
+
+	// O SKU é único dentro da empresa
+	tenantID := tenant.ID(ctx)
+	const productSQL = `
+        INSERT INTO products (id, sku, name, description, price, is_active, created_at, updated_at, category, custom_attributes, tenant_id)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+        ON CONFLICT (tenant_id, sku) DO UPDATE
+            SET name = EXCLUDED.name,
+                description = EXCLUDED.description,
+                price = EXCLUDED.price,
+                updated_at = EXCLUDED.updated_at,
+                -- Categoria e especificações só mudam quando informadas (ex.: CSV sem essas colunas)
+                category = COALESCE(NULLIF(EXCLUDED.category, ''), products.category),
+                custom_attributes = CASE WHEN EXCLUDED.custom_attributes = '{}'::jsonb
+                                         THEN products.custom_attributes ELSE EXCLUDED.custom_attributes END
+        RETURNING id, is_active, created_at, category, custom_attributes, (xmax = 0) AS inserted`
+
+	var (
+		created          bool
+		customAttributes []byte
+	)
+	err = tx.QueryRowContext(ctxTimeout, productSQL,
+		product.ID,
+		product.SKU,
+		product.Name,
+		product.Description,
+		product.Price,
+		product.IsActive,
+		product.CreatedAt,
+		product.UpdatedAt,
+		product.Category,
+		customAttributesJSON(product),
+		tenantID,
+	).Scan(&product.ID, &product.IsActive, &product.CreatedAt, &product.Category, &customAttributes, &created)
+	if err == nil {
+		err = scanCustomAttributes(customAttributes, &product)
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao executar Upsert de produto no DB.", err)
+		return domain.Product{}, false, errors.NewDBError("failed to upsert product", err)
+	}
+
+	const variantSQL = `
+        INSERT INTO variants (id, product_id, attribute, value, attributes, barcode, price_diff, tenant_id)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+        ON CONFLICT (tenant_id, barcode) DO UPDATE
+            SET attribute = EXCLUDED.attribute,
+                value = EXCLUDED.value,
+                attributes = EXCLUDED.attributes,
+                price_diff = EXCLUDED.price_diff
+            WHERE variants.product_id = EXCLUDED.product_id
+        RETURNING id`
+
+	for i := range product.Variants {
+		product.Variants[i].ProductID = product.ID
+		v := product.Variants[i]
+		err = tx.QueryRowContext(ctxTimeout, variantSQL,
+			v.ID, v.ProductID, v.Attribute, v.Value, attributesJSON(v), v.Barcode, v.PriceDiff, tenantID,
+		).Scan(&product.Variants[i].ID)
+		if isUniqueViolation(err) {
+			// O código de barras é deste produto, mas a combinação de atributos já pertence a outra variante.
+			return domain.Product{}, false, errors.NewConflictError(fmt.Sprintf("A combinação de atributos '%s' já existe no produto.", v.Value))
+		}
+		if err == sql.ErrNoRows {
+			// O ON CONFLICT não atualizou nada: o código de barras pertence a outro produto.
+			r.logger.WithContext(ctx).Warn("Código de barras já pertence a outro produto.", map[string]interface{}{"sku": product.SKU, "barcode": v.Barcode})
+			return domain.Product{}, false, errors.NewConflictError(fmt.Sprintf("O código de barras '%s' já pertence a outro produto.", v.Barcode))
+		}
+		if err != nil {
+			r.logger.WithContext(ctx).Error("Falha ao executar Upsert de variante no DB.", err)
+			return domain.Product{}, false, errors.NewDBError("failed to upsert variant", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao commitar transação de Upsert de produto.", err)
+		return domain.Product{}, false, errors.NewDBError("failed to commit tx", err)
+	}
+
+	// Invalida o cache para que o próximo FindByID leia os dados atualizados.
+	if cacheErr := r.Cache.Delete(ctx, productKey(ctx, product.ID)); cacheErr != nil {
+		r.logger.WithContext(ctx).Warn("Falha ao invalidar cache do produto após Upsert.", map[string]interface{}{"product_id": product.ID, "error": cacheErr.Error()})
+	}
+
+	r.logger.WithContext(ctx).Info("Upsert de produto concluído.", map[string]interface{}{"product_id": product.ID, "sku": product.SKU, "created": created})
+	return product, created, nil
+}
+
+// attributesJSON serializa a combinação de atributos da variante para a coluna JSONB.
+func attributesJSON(v domain.Variant) []byte {
+	if len(v.Attributes) == 0 {
+		return []byte("{}")
+	}
+	data, _ := json.Marshal(v.Attributes) // map[string]string sempre é serializável
+	return data
+}
+
+// customAttributesJSON serializa os atributos personalizados do produto para a coluna JSONB.
+func customAttributesJSON(p domain.Product) []byte {
+	if len(p.CustomAttributes) == 0 {
+		return []byte("{}")
+	}
+	data, err := json.Marshal(p.CustomAttributes)
+	if err != nil {
+		return []byte("{}") // Valores já normalizados pelo serviço (texto, número ou booleano)
+	}
+	return data
+}
+
+// scanCustomAttributes preenche os atributos personalizados do produto a partir da coluna JSONB.
+// Um objeto vazio resulta em mapa nil, omitido no JSON.
+func scanCustomAttributes(data []byte, p *domain.Product) error {
+	p.CustomAttributes = nil
+	if len(data) == 0 || string(data) == "{}" {
+		return nil
+	}
+	return json.Unmarshal(data, &p.CustomAttributes)
+}
+
 // Outros métodos (FindByID, FindAll, Update, Delete) seriam implementados aqui.
-// Define a chave de cache para produtos.
-const productCacheKey = "product:%s"
+// Define a chave de cache para produtos (por empresa e ID).
+const productCacheKey = "product:%s:%s"
+
+// productKey monta a chave de cache do produto na empresa do contexto.
+func productKey(ctx context.Context, id string) string {
+	return fmt.Sprintf(productCacheKey, tenant.ID(ctx), id)
+}
 
 // FindByID busca um produto pelo ID, utilizando a estratégia Cache-Aside.
 // (Implementa um dos métodos da interface domain.ProductRepository)
 func (r *ProductRepository) FindByID(ctx context.Context, id string) (domain.Product, error) {
-	r.logger.Debug("Iniciando FindByID de produto no repositório.", map[string]interface{}{"product_id_attempt": id})
+	r.logger.WithContext(ctx).Debug("Iniciando FindByID de produto no repositório.", map[string]interface{}{"product_id_attempt": id})
 
 	// 1. Contexto
 	ctxGo, cancel := context.WithTimeout(ctx,
@@ -120,7 +269,7 @@ func (r *ProductRepository) FindByID(ctx context.Context, id string) (domain.Pro
 	defer cancel()
 
 	// Chave de Cache
-	key := fmt.Sprintf(productCacheKey, id)
+	key := productKey(ctx, id)
 	var product domain.Product
 
 	// --- 2. Estratégia Cache-Aside (READ) ---
@@ -131,30 +280,31 @@ func (r *ProductRepository) FindByID(ctx context.Context, id string) (domain.Pro
 	if err == nil {
 		// Cache HIT
 		if json.Unmarshal([]byte(cachedData), &product) == nil {
-			r.logger.Info("Produto encontrado no cache.", map[string]interface{}{"product_id": id})
+			r.logger.WithContext(ctx).Info("Produto encontrado no cache.", map[string]interface{}{"product_id": id})
 			// Sucesso na desserialização, retorna o produto do cache
 			return product, nil
 		}
-		r.logger.Error("Falha ao desserializar produto do cache.", err)
+		r.logger.WithContext(ctx).Error("Falha ao desserializar produto do cache.", err)
 		// Se a desserialização falhar, logar e continuar para o DB
 	} else if err != cache.ErrCacheMiss { // ErrCacheMiss indica que a chave não existe
 		// Se houver um erro real de cache (ex: conexão perdida), logamos, mas continuamos.
-		r.logger.Warn("Erro ao ler do cache Redis (não é um cache miss).", map[string]interface{}{"error": err.Error()})
+		r.logger.WithContext(ctx).Warn("Erro ao ler do cache Redis (não é um cache miss).", map[string]interface{}{"error": err.Error()})
 	} else {
-		r.logger.Debug("Cache miss para produto.", map[string]interface{}{"product_id": id})
+		r.logger.WithContext(ctx).Debug("Cache miss para produto.", map[string]interface{}{"product_id": id})
 	}
 
 	// --- 3. Busca no Banco de Dados (PostgreSQL) ---
-	r.logger.Debug("Buscando produto no DB.", map[string]interface{}{"product_id": id})
+	r.logger.WithContext(ctx).Debug("Buscando produto no DB.", map[string]interface{}{"product_id": id})
 	// Query SQL
 	productSQL := `
-		SELECT id, sku, name, description, price, is_active, created_at, updated_at
+		SELECT id, sku, name, description, price, is_active, created_at, updated_at, category, custom_attributes
 		FROM products 
-		WHERE id = $1`
+		WHERE id = $1 AND tenant_id = $2`
 
-	row := r.DB.QueryRowContext(ctxGo, productSQL, id)
+	row := r.DB.QueryRowContext(ctxGo, productSQL, id, tenant.ID(ctx))
 
 	// Mapeamento dos campos do DB para a struct domain.Product
+	var customAttributes []byte
 	err = row.Scan(
 		&product.ID,
 		&product.SKU,
@@ -164,62 +314,68 @@ func (r *ProductRepository) FindByID(ctx context.Context, id string) (domain.Pro
 		&product.IsActive,
 		&product.CreatedAt,
 		&product.UpdatedAt,
+		&product.Category,
+		&customAttributes,
 	)
+	if err == nil {
+		err = scanCustomAttributes(customAttributes, &product)
+	}
 
 	// 4. Tratamento do Erro de Busca (Crucial para o 404)
 	if err == sql.ErrNoRows {
-		r.logger.Info("Produto não encontrado no DB.", map[string]interface{}{"product_id": id})
+		r.logger.WithContext(ctx).Info("Produto não encontrado no DB.", map[string]interface{}{"product_id": id})
 		// Se não houver linhas, retornamos um erro de Domínio NotFoundError
 		// O Serviço receberá isso e o Handler o mapeará para 404.
 		return domain.Product{}, errors.NewNotFoundError(fmt.Sprintf("Produto com ID %s não existe na base de dados.", id))
 	}
 	if err != nil {
-		r.logger.Error("Falha ao buscar produto no DB.", err)
+		r.logger.WithContext(ctx).Error("Falha ao buscar produto no DB.", err)
 		// Qualquer outro erro é um InternalError (DB falhou, timeout, etc.)
 		return domain.Product{}, errors.NewDBError("Falha ao buscar produto no DB", err)
 	}
-	r.logger.Debug("Produto encontrado no DB.", map[string]interface{}{"product_id": product.ID, "sku": product.SKU})
+	r.logger.WithContext(ctx).Debug("Produto encontrado no DB.", map[string]interface{}{"product_id": product.ID, "sku": product.SKU})
+
+	// 🚨 NOVO: Buscar e anexar variações
+	variants, err := r.FindVariantsByProductID(ctx, product.ID)
+	if err != nil {
+		r.logger.WithContext(ctx).Warn("Falha ao buscar variações para o produto (pode ser aceitável).", map[string]interface{}{"product_id": product.ID, "error": err.Error()})
+		// Se a busca de variações falhar, logamos mas podemos optar por retornar o produto sem elas
+		// Ou retornar o erro, dependendo da criticidade. Retornar o erro é mais seguro.
+		return domain.Product{}, err
+	}
+	product.Variants = variants
 
 	// --- 5. Estratégia Cache-Aside (WRITE) ---
-	// Se encontrado no DB, populamos o cache para futuras requisições.
+	// Se encontrado no DB, populamos o cache (já com as variações) para futuras requisições.
 	productJSON, marshalErr := json.Marshal(product)
 	if marshalErr == nil {
-		r.logger.Debug("Salvando produto no cache.", map[string]interface{}{"product_id": product.ID})
+		r.logger.WithContext(ctx).Debug("Salvando produto no cache.", map[string]interface{}{"product_id": product.ID})
 		// Define o produto no cache com uma expiração (TTL)
 		// TTL de 5 minutos, por exemplo (deve vir do config)
 		r.Cache.Set(ctxGo, key, productJSON, 5*time.Minute)
 	} else {
-		r.logger.Error("Falha ao serializar produto para cache.", marshalErr)
+		r.logger.WithContext(ctx).Error("Falha ao serializar produto para cache.", marshalErr)
 	}
 
-	// 🚨 NOVO: Buscar e anexar variações
-	variants, err := r.FindVariantsByProductID(ctx, product.ID)
-	if err != nil {
-		r.logger.Warn("Falha ao buscar variações para o produto (pode ser aceitável).", map[string]interface{}{"product_id": product.ID, "error": err.Error()})
-		// Se a busca de variações falhar, logamos mas podemos optar por retornar o produto sem elas
-		// Ou retornar o erro, dependendo da criticidade. Retornar o erro é mais seguro.
-		return domain.Product{}, err
-	}
-	product.Variants = variants
-	r.logger.Info("Produto e suas variantes recuperados com sucesso do repositório.", map[string]interface{}{"product_id": product.ID, "sku": product.SKU})
+	r.logger.WithContext(ctx).Info("Produto e suas variantes recuperados com sucesso do repositório.", map[string]interface{}{"product_id": product.ID, "sku": product.SKU})
 	return product, nil
 }
 
 // FindVariantsByProductID busca todas as variações para um dado ID de produto.
 func (r *ProductRepository) FindVariantsByProductID(ctx context.Context, productID string) ([]domain.Variant, error) {
-	r.logger.Debug("Iniciando busca de variantes por ProductID.", map[string]interface{}{"product_id": productID})
+	r.logger.WithContext(ctx).Debug("Iniciando busca de variantes por ProductID.", map[string]interface{}{"product_id": productID})
 	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
 	defer cancel()
 
 	query := `
-        SELECT id, product_id, attribute, value, barcode, price_diff
+        SELECT id, product_id, attribute, value, attributes, barcode, price_diff
         FROM variants
-        WHERE product_id = $1
+        WHERE product_id = $1 AND tenant_id = $2
     `
 
-	rows, err := r.DB.QueryContext(ctxTimeout, query, productID)
+	rows, err := r.DB.QueryContext(ctxTimeout, query, productID, tenant.ID(ctx))
 	if err != nil {
-		r.logger.Error("Falha ao executar QueryContext para buscar variantes.", err)
+		r.logger.WithContext(ctx).Error("Falha ao executar QueryContext para buscar variantes.", err)
 		return nil, apperror.NewDBError("Falha ao buscar variações do produto (DB)", err)
 	}
 	defer rows.Close()
@@ -229,13 +385,17 @@ func (r *ProductRepository) FindVariantsByProductID(ctx context.Context, product
 	for rows.Next() {
 		var v domain.Variant
 		var priceDiff sql.NullFloat64 // Usar NullFloat64 para lidar com valores NULL no DB
+		var attributes []byte
 
 		err := rows.Scan(
-			&v.ID, &v.ProductID, &v.Attribute, &v.Value, &v.Barcode,
+			&v.ID, &v.ProductID, &v.Attribute, &v.Value, &attributes, &v.Barcode,
 			&priceDiff, // Scan para NullFloat64
 		)
+		if err == nil {
+			err = json.Unmarshal(attributes, &v.Attributes)
+		}
 		if err != nil {
-			r.logger.Error("Falha ao mapear linha de variante do DB.", err)
+			r.logger.WithContext(ctx).Error("Falha ao mapear linha de variante do DB.", err)
 			return nil, apperror.NewDBError("Falha ao mapear variações do produto (DB)", err)
 		}
 
@@ -248,18 +408,18 @@ func (r *ProductRepository) FindVariantsByProductID(ctx context.Context, product
 	}
 
 	if err := rows.Err(); err != nil {
-		r.logger.Error("Erro após iteração das linhas de variantes do DB.", err)
+		r.logger.WithContext(ctx).Error("Erro após iteração das linhas de variantes do DB.", err)
 		return nil, apperror.NewDBError("Erro após iteração de variações (DB)", err)
 	}
 
-	r.logger.Info("Variantes encontradas com sucesso.", map[string]interface{}{"product_id": productID, "count": len(variants)})
+	r.logger.WithContext(ctx).Info("Variantes encontradas com sucesso.", map[string]interface{}{"product_id": productID, "count": len(variants)})
 	return variants, nil
 }
 
 // FindAll busca uma lista de produtos, aplicando filtros e paginação.
 // (Implementa um dos métodos da interface domain.ProductRepository)
 func (r *ProductRepository) FindAll(ctx context.Context, filter domain.ProductFilter) ([]domain.Product, error) {
-	r.logger.Debug("Iniciando FindAll de produtos no repositório.", map[string]interface{}{"filter": filter})
+	r.logger.WithContext(ctx).Debug("Iniciando FindAll de produtos no repositório.", map[string]interface{}{"filter": filter})
 
 	ctxGo, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
 	defer cancel()
@@ -268,34 +428,18 @@ func (r *ProductRepository) FindAll(ctx context.Context, filter domain.ProductFi
 
 	// Base da Query
 	query := `
-        SELECT id, sku, name, description, price, is_active, created_at, updated_at
+        SELECT id, sku, name, description, price, is_active, created_at, updated_at, category, custom_attributes
         FROM products 
-        WHERE 1=1 ` // 1=1 é um truque para facilitar a concatenação de WHERE clauses
-
-	args := []interface{}{}
-	argCounter := 1 // Contador para os parâmetros SQL ($1, $2, ...)
-
-	// Aplicar Filtros (Exemplo: Name e SKU)
-	if filter.Name != "" {
-		query += fmt.Sprintf(" AND name ILIKE $%d", argCounter) // ILIKE para busca case-insensitive
-		args = append(args, "%"+filter.Name+"%")
-		argCounter++
-	}
-
-	if filter.SKU != "" {
-		query += fmt.Sprintf(" AND sku = $%d", argCounter)
-		args = append(args, filter.SKU)
-		argCounter++
-	}
+        WHERE products.tenant_id = $1 ` // Demais condições são concatenadas com AND
 
-	if filter.ActiveOnly {
-		query += fmt.Sprintf(" AND is_active = $%d", argCounter)
-		args = append(args, true)
-		argCounter++
-	}
+	// Filtros (compartilhados com a exportação, ver FilterClause)
+	where, args, argCounter := FilterClause(filter, 2)
+	query += where
+	args = append([]interface{}{tenant.ID(ctx)}, args...)
 
-	// Opcional: Ordenar para garantir consistência na paginação
-	query += " ORDER BY created_at DESC"
+	// Ordenação: apenas colunas da whitelist são interpoladas na query.
+	// O id entra como critério de desempate para manter a paginação estável.
+	query += " ORDER BY " + OrderByClause(filter.SortBy, filter.SortOrder)
 
 	// --- 2. Aplicar Paginação (LIMIT e OFFSET) ---
 
@@ -316,12 +460,12 @@ func (r *ProductRepository) FindAll(ctx context.Context, filter domain.ProductFi
 	query += fmt.Sprintf(" OFFSET $%d", argCounter)
 	args = append(args, offset)
 
-	r.logger.Debug("Executando FindAll query", map[string]interface{}{"sql": query, "args_count": len(args)})
+	r.logger.WithContext(ctx).Debug("Executando FindAll query", map[string]interface{}{"sql": query, "args_count": len(args)})
 
 	// --- 3. Executar a Query e Mapear Resultados ---
 	rows, err := r.DB.QueryContext(ctxGo, query, args...)
 	if err != nil {
-		r.logger.Error("Falha ao executar FindAll query.", err)
+		r.logger.WithContext(ctx).Error("Falha ao executar FindAll query.", err)
 		return nil, errors.NewDBError("Falha ao buscar produtos (FindAll)", err)
 	}
 	defer rows.Close()
@@ -329,6 +473,7 @@ func (r *ProductRepository) FindAll(ctx context.Context, filter domain.ProductFi
 	var products []domain.Product
 	for rows.Next() {
 		var p domain.Product
+		var customAttributes []byte
 		err := rows.Scan(
 			&p.ID,
 			&p.SKU,
@@ -338,9 +483,14 @@ func (r *ProductRepository) FindAll(ctx context.Context, filter domain.ProductFi
 			&p.IsActive,
 			&p.CreatedAt,
 			&p.UpdatedAt,
+			&p.Category,
+			&customAttributes,
 		)
+		if err == nil {
+			err = scanCustomAttributes(customAttributes, &p)
+		}
 		if err != nil {
-			r.logger.Error("Falha ao mapear produto na iteração de FindAll.", err)
+			r.logger.WithContext(ctx).Error("Falha ao mapear produto na iteração de FindAll.", err)
 			return nil, errors.NewDBError("Falha ao mapear produtos do DB (FindAll)", err)
 		}
 
@@ -352,10 +502,85 @@ func (r *ProductRepository) FindAll(ctx context.Context, filter domain.ProductFi
 	}
 
 	if err := rows.Err(); err != nil {
-		r.logger.Error("Erro após iteração de produtos FindAll.", err)
+		r.logger.WithContext(ctx).Error("Erro após iteração de produtos FindAll.", err)
 		return nil, errors.NewDBError("Erro após iteração de produtos (FindAll)", err)
 	}
 
-	r.logger.Info("FindAll concluído com sucesso.", map[string]interface{}{"total_results": len(products)})
+	r.logger.WithContext(ctx).Info("FindAll concluído com sucesso.", map[string]interface{}{"total_results": len(products)})
 	return products, nil
 }
+
+// StreamExportRows percorre o catálogo filtrado linha a linha (uma linha por variante),
+// chamando fn para cada uma, sem carregar o resultado inteiro em memória.
+// Não aplica DBTimeout: a duração é limitada pelo contexto da requisição, pois exportações
+// grandes podem levar minutos. Paginação (Page/Limit) é ignorada.
+func (r *ProductRepository) StreamExportRows(ctx context.Context, filter domain.ProductFilter, fn func(domain.ProductExportRow) error) error {
+	r.logger.WithContext(ctx).Debug("Iniciando exportação de produtos no repositório.", map[string]interface{}{"filter": filter})
+
+	query := `
+        SELECT products.id, products.sku, products.name, COALESCE(products.description, ''), products.price,
+               products.is_active, products.created_at, products.updated_at,
+               pv.id, pv.attribute, pv.value, pv.barcode, pv.price_diff,
+               COALESCE(json_object_agg(sl.warehouse_id, sl.quantity) FILTER (WHERE sl.warehouse_id IS NOT NULL), '{}')
+        FROM products
+        LEFT JOIN variants pv ON pv.product_id = products.id
+        LEFT JOIN stock_levels sl ON sl.variant_id = pv.id
+        WHERE products.tenant_id = $1 `
+
+	where, args, _ := FilterClause(filter, 2)
+	query += where
+	args = append([]interface{}{tenant.ID(ctx)}, args...)
+	query += " GROUP BY products.id, pv.id"
+	query += " ORDER BY " + OrderByClause(filter.SortBy, filter.SortOrder) + ", pv.barcode"
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao executar query de exportação de produtos.", err)
+		return errors.NewDBError("Falha ao exportar produtos", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var row domain.ProductExportRow
+		var variantID, attribute, value, barcode sql.NullString
+		var priceDiff sql.NullFloat64
+		var quantities []byte
+
+		if err := rows.Scan(
+			&row.ProductID, &row.SKU, &row.Name, &row.Description, &row.Price,
+			&row.IsActive, &row.CreatedAt, &row.UpdatedAt,
+			&variantID, &attribute, &value, &barcode, &priceDiff,
+			&quantities,
+		); err != nil {
+			r.logger.WithContext(ctx).Error("Falha ao mapear linha da exportação de produtos.", err)
+			return errors.NewDBError("Falha ao mapear linha da exportação de produtos", err)
+		}
+
+		row.VariantID = variantID.String
+		row.Attribute = attribute.String
+		row.Value = value.String
+		row.Barcode = barcode.String
+		row.PriceDiff = priceDiff.Float64
+		if err := json.Unmarshal(quantities, &row.Quantities); err != nil {
+			r.logger.WithContext(ctx).Error("Falha ao decodificar quantidades por armazém.", err)
+			return errors.NewInternalError("Falha ao decodificar quantidades por armazém.", err)
+		}
+		for _, qty := range row.Quantities {
+			row.TotalQuantity += qty
+		}
+
+		if err := fn(row); err != nil {
+			return err // Erro de escrita (ex: cliente desconectou); o chamador decide como tratar
+		}
+		count++
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.WithContext(ctx).Error("Erro após iteração da exportação de produtos.", err)
+		return errors.NewDBError("Erro após iteração da exportação de produtos", err)
+	}
+
+	r.logger.WithContext(ctx).Info("Exportação de produtos concluída no repositório.", map[string]interface{}{"rows": count})
+	return nil
+}
@@ -0,0 +1,224 @@
+package rolerepo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/cache"
+	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/tenant"
+)
+
+// Códigos de erro do PostgreSQL tratados pelo repositório.
+const (
+	pqUniqueViolation     = "23505"
+	pqForeignKeyViolation = "23503"
+)
+
+// roleCacheKey é a chave de cache de um papel. Os papéis são consultados a cada requisição
+// protegida por permissão, por isso ficam em cache (invalidado nas alterações).
+const roleCacheKey = "role:%s"
+
+// roleCacheTTL é uma salvaguarda caso uma invalidação falhe.
+const roleCacheTTL = 5 * time.Minute
+
+// RoleRepository implementa a persistência de papéis e a atribuição de papéis a usuários.
+type RoleRepository struct {
+	DB        *sql.DB
+	Cache     cache.Client
+	DBTimeout time.Duration
+	logger    logger.Logger
+}
+
+// NewRoleRepository cria e retorna uma nova instância do Repositório de Papéis.
+func NewRoleRepository(db *sql.DB, cacheClient cache.Client, dbTimeout time.Duration, logger logger.Logger) *RoleRepository {
+	return &RoleRepository{
+		DB:        db,
+		Cache:     cacheClient,
+		DBTimeout: dbTimeout,
+		logger:    logger,
+	}
+}
+
+const roleColumns = `name, description, permissions, built_in, created_at, updated_at`
+
+// CreateRole insere um novo papel.
+func (r *RoleRepository) CreateRole(ctx context.Context, role domain.Role) (domain.Role, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	permissions, _ := json.Marshal(role.Permissions) // []Permission sempre é serializável
+	query := `INSERT INTO roles (name, description, permissions, built_in, created_at, updated_at)
+              VALUES ($1, $2, $3, FALSE, NOW(), NOW()) RETURNING ` + roleColumns
+	created, err := scanRole(r.DB.QueryRowContext(ctxTimeout, query, role.Name, role.Description, permissions))
+	if err != nil {
+		if isPQError(err, pqUniqueViolation) {
+			return domain.Role{}, apperror.NewConflictError(fmt.Sprintf("O papel '%s' já existe.", role.Name))
+		}
+		r.logger.WithContext(ctx).Error("Falha ao inserir papel no DB.", err)
+		return domain.Role{}, apperror.NewDBError("failed to insert role (DB)", err)
+	}
+	return created, nil
+}
+
+// FindRole busca um papel pelo nome, usando a estratégia Cache-Aside.
+func (r *RoleRepository) FindRole(ctx context.Context, name string) (domain.Role, error) {
+	key := fmt.Sprintf(roleCacheKey, name)
+	if cached, err := r.Cache.Get(ctx, key); err == nil {
+		var role domain.Role
+		if json.Unmarshal([]byte(cached), &role) == nil {
+			return role, nil
+		}
+	} else if err != cache.ErrCacheMiss {
+		r.logger.WithContext(ctx).Warn("Falha ao ler papel do cache.", map[string]interface{}{"role": name, "error": err.Error()})
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	role, err := scanRole(r.DB.QueryRowContext(ctxTimeout, `SELECT `+roleColumns+` FROM roles WHERE name = $1`, name))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Role{}, apperror.NewNotFoundError(fmt.Sprintf("Papel '%s' não encontrado.", name))
+		}
+		r.logger.WithContext(ctx).Error("Falha ao buscar papel no DB.", err)
+		return domain.Role{}, apperror.NewDBError("failed to find role (DB)", err)
+	}
+
+	if data, err := json.Marshal(role); err == nil {
+		r.Cache.Set(ctx, key, data, roleCacheTTL)
+	}
+	return role, nil
+}
+
+// FindRoles lista todos os papéis em ordem alfabética.
+func (r *RoleRepository) FindRoles(ctx context.Context) ([]domain.Role, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctxTimeout, `SELECT `+roleColumns+` FROM roles ORDER BY name`)
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao listar papéis no DB.", err)
+		return nil, apperror.NewDBError("failed to list roles (DB)", err)
+	}
+	defer rows.Close()
+
+	roles := []domain.Role{}
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, apperror.NewDBError("failed to scan role (DB)", err)
+		}
+		roles = append(roles, role)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperror.NewDBError("failed to list roles (DB)", err)
+	}
+	return roles, nil
+}
+
+// UpdateRole altera a descrição e as permissões de um papel.
+func (r *RoleRepository) UpdateRole(ctx context.Context, role domain.Role) (domain.Role, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	permissions, _ := json.Marshal(role.Permissions)
+	query := `UPDATE roles SET description = $2, permissions = $3, updated_at = NOW()
+              WHERE name = $1 RETURNING ` + roleColumns
+	updated, err := scanRole(r.DB.QueryRowContext(ctxTimeout, query, role.Name, role.Description, permissions))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Role{}, apperror.NewNotFoundError(fmt.Sprintf("Papel '%s' não encontrado.", role.Name))
+		}
+		r.logger.WithContext(ctx).Error("Falha ao atualizar papel no DB.", err)
+		return domain.Role{}, apperror.NewDBError("failed to update role (DB)", err)
+	}
+
+	r.invalidate(ctx, role.Name)
+	return updated, nil
+}
+
+// DeleteRole remove um papel. Papéis atribuídos a usuários não podem ser removidos.
+func (r *RoleRepository) DeleteRole(ctx context.Context, name string) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctxTimeout, `DELETE FROM roles WHERE name = $1`, name)
+	if err != nil {
+		if isPQError(err, pqForeignKeyViolation) {
+			return apperror.NewConflictError(fmt.Sprintf("O papel '%s' está atribuído a usuários; altere o papel deles antes de removê-lo.", name))
+		}
+		r.logger.WithContext(ctx).Error("Falha ao remover papel no DB.", err)
+		return apperror.NewDBError("failed to delete role (DB)", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return apperror.NewNotFoundError(fmt.Sprintf("Papel '%s' não encontrado.", name))
+	}
+
+	r.invalidate(ctx, name)
+	return nil
+}
+
+// AssignUserRole altera o papel de um usuário.
+func (r *RoleRepository) AssignUserRole(ctx context.Context, userID, role string) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	// Com empresa no contexto, só altera usuários dela
+	result, err := r.DB.ExecContext(ctxTimeout,
+		`UPDATE users SET role = $2, updated_at = NOW() WHERE id = $1 AND ($3::uuid IS NULL OR tenant_id = $3::uuid)`,
+		userID, role, tenant.Optional(ctx))
+	if err != nil {
+		if isPQError(err, pqForeignKeyViolation) {
+			return apperror.NewNotFoundError(fmt.Sprintf("Papel '%s' não encontrado.", role))
+		}
+		r.logger.WithContext(ctx).Error("Falha ao atribuir papel ao usuário no DB.", err)
+		return apperror.NewDBError("failed to assign user role (DB)", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return apperror.NewNotFoundError(fmt.Sprintf("Usuário com ID '%s' não encontrado.", userID))
+	}
+	return nil
+}
+
+// invalidate remove o papel do cache após uma alteração.
+func (r *RoleRepository) invalidate(ctx context.Context, name string) {
+	if err := r.Cache.Delete(ctx, fmt.Sprintf(roleCacheKey, name)); err != nil {
+		r.logger.WithContext(ctx).Warn("Falha ao invalidar papel no cache.", map[string]interface{}{"role": name, "error": err.Error()})
+	}
+}
+
+// rowScanner abstrai *sql.Row e *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanRole lê um papel nas colunas de roleColumns.
+func scanRole(row rowScanner) (domain.Role, error) {
+	var role domain.Role
+	var permissions []byte
+	if err := row.Scan(&role.Name, &role.Description, &permissions, &role.BuiltIn, &role.CreatedAt, &role.UpdatedAt); err != nil {
+		return domain.Role{}, err
+	}
+	if err := json.Unmarshal(permissions, &role.Permissions); err != nil {
+		return domain.Role{}, err
+	}
+	if role.Permissions == nil {
+		role.Permissions = []domain.Permission{}
+	}
+	return role, nil
+}
+
+// isPQError verifica se o erro é um erro do PostgreSQL com o código informado.
+func isPQError(err error, code string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && string(pqErr.Code) == code
+}
@@ -7,10 +7,13 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 
 	"gostock/internal/domain"
 	"gostock/internal/errors"
 	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/tenant"
+	"gostock/internal/repository/productrepo"
 )
 
 // StockRepository implementa a interface domain.StockRepository (a ser definida no domínio, ou aqui se for um subdomínio).
@@ -31,7 +34,7 @@ func NewStockRepository(db *sql.DB, dbTimeout time.Duration, logger logger.Logge
 
 // GetStockLevel busca o nível de estoque para uma variante em um armazém.
 func (r *StockRepository) GetStockLevel(ctx context.Context, variantID, warehouseID string) (domain.StockLevel, error) {
-	r.logger.Debug("Buscando nível de estoque no repositório.", map[string]interface{}{"variant_id": variantID, "warehouse_id": warehouseID})
+	r.logger.WithContext(ctx).Debug("Buscando nível de estoque no repositório.", map[string]interface{}{"variant_id": variantID, "warehouse_id": warehouseID})
 
 	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
 	defer cancel()
@@ -39,29 +42,64 @@ func (r *StockRepository) GetStockLevel(ctx context.Context, variantID, warehous
 	query := `
         SELECT id, variant_id, warehouse_id, quantity, version, created_at, updated_at
         FROM stock_levels
-        WHERE variant_id = $1 AND warehouse_id = $2`
+        WHERE variant_id = $1 AND warehouse_id = $2 AND tenant_id = $3`
 
 	var sl domain.StockLevel
-	err := r.DB.QueryRowContext(ctxTimeout, query, variantID, warehouseID).Scan(
+	err := r.DB.QueryRowContext(ctxTimeout, query, variantID, warehouseID, tenant.ID(ctx)).Scan(
 		&sl.ID, &sl.VariantID, &sl.WarehouseID, &sl.Quantity, &sl.Version, &sl.CreatedAt, &sl.UpdatedAt,
 	)
 
 	if err == sql.ErrNoRows {
-		r.logger.Info("Nível de estoque não encontrado.", map[string]interface{}{"variant_id": variantID, "warehouse_id": warehouseID})
+		r.logger.WithContext(ctx).Info("Nível de estoque não encontrado.", map[string]interface{}{"variant_id": variantID, "warehouse_id": warehouseID})
 		return domain.StockLevel{}, errors.NewNotFoundError(fmt.Sprintf("Estoque para variante %s no armazém %s não encontrado.", variantID, warehouseID))
 	}
 	if err != nil {
-		r.logger.Error("Falha ao buscar nível de estoque no DB.", err)
+		r.logger.WithContext(ctx).Error("Falha ao buscar nível de estoque no DB.", err)
 		return domain.StockLevel{}, errors.NewDBError("Falha ao buscar nível de estoque", err)
 	}
 
-	r.logger.Debug("Nível de estoque encontrado.", map[string]interface{}{"variant_id": variantID, "warehouse_id": warehouseID, "quantity": sl.Quantity, "version": sl.Version})
+	r.logger.WithContext(ctx).Debug("Nível de estoque encontrado.", map[string]interface{}{"variant_id": variantID, "warehouse_id": warehouseID, "quantity": sl.Quantity, "version": sl.Version})
 	return sl, nil
 }
 
+// FindStockLevelsByVariant lista os níveis de estoque de uma variante em todos os armazéns.
+func (r *StockRepository) FindStockLevelsByVariant(ctx context.Context, variantID string) ([]domain.StockLevel, error) {
+	r.logger.WithContext(ctx).Debug("Listando níveis de estoque da variante no repositório.", map[string]interface{}{"variant_id": variantID})
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	query := `
+        SELECT id, variant_id, warehouse_id, quantity, version, created_at, updated_at
+        FROM stock_levels
+        WHERE variant_id = $1 AND tenant_id = $2
+        ORDER BY warehouse_id`
+
+	rows, err := r.DB.QueryContext(ctxTimeout, query, variantID, tenant.ID(ctx))
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao listar níveis de estoque da variante no DB.", err)
+		return nil, errors.NewDBError("Falha ao listar níveis de estoque", err)
+	}
+	defer rows.Close()
+
+	levels := make([]domain.StockLevel, 0)
+	for rows.Next() {
+		var sl domain.StockLevel
+		if err := rows.Scan(&sl.ID, &sl.VariantID, &sl.WarehouseID, &sl.Quantity, &sl.Version, &sl.CreatedAt, &sl.UpdatedAt); err != nil {
+			r.logger.WithContext(ctx).Error("Falha ao mapear nível de estoque do DB.", err)
+			return nil, errors.NewDBError("Falha ao mapear níveis de estoque", err)
+		}
+		levels = append(levels, sl)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDBError("Erro após iteração de níveis de estoque", err)
+	}
+	return levels, nil
+}
+
 // UpdateStockLevel aplica um ajuste ao estoque, utilizando transação e controle de concorrência otimista (OCC).
 func (r *StockRepository) UpdateStockLevel(ctx context.Context, adjustment domain.StockAdjustmentRequest) (domain.StockLevel, error) {
-	r.logger.Debug("Iniciando atualização de estoque no repositório.", map[string]interface{}{
+	r.logger.WithContext(ctx).Debug("Iniciando atualização de estoque no repositório.", map[string]interface{}{
 		"variant_id": adjustment.VariantID,
 		"warehouse_id": adjustment.WarehouseID,
 		"delta": adjustment.Delta,
@@ -72,7 +110,7 @@ func (r *StockRepository) UpdateStockLevel(ctx context.Context, adjustment domai
 
 	tx, err := r.DB.BeginTx(ctxTimeout, nil)
 	if err != nil {
-		r.logger.Error("Falha ao iniciar transação para atualização de estoque.", err)
+		r.logger.WithContext(ctx).Error("Falha ao iniciar transação para atualização de estoque.", err)
 		return domain.StockLevel{}, errors.NewDBError("Falha ao iniciar transação", err)
 	}
 	defer tx.Rollback() // Rollback em caso de erro
@@ -83,9 +121,9 @@ func (r *StockRepository) UpdateStockLevel(ctx context.Context, adjustment domai
 	querySelect := `
         SELECT id, variant_id, warehouse_id, quantity, version, created_at, updated_at
         FROM stock_levels
-        WHERE variant_id = $1 AND warehouse_id = $2 FOR UPDATE`
+        WHERE variant_id = $1 AND warehouse_id = $2 AND tenant_id = $3 FOR UPDATE`
 
-	err = tx.QueryRowContext(ctxTimeout, querySelect, adjustment.VariantID, adjustment.WarehouseID).Scan(
+	err = tx.QueryRowContext(ctxTimeout, querySelect, adjustment.VariantID, adjustment.WarehouseID, tenant.ID(ctx)).Scan(
 		&currentStock.ID, &currentStock.VariantID, &currentStock.WarehouseID, &currentStock.Quantity,
 		&currentStock.Version, &currentStock.CreatedAt, &currentStock.UpdatedAt,
 	)
@@ -95,43 +133,60 @@ func (r *StockRepository) UpdateStockLevel(ctx context.Context, adjustment domai
 		newID := uuid.New().String()
 		newQuantity := adjustment.Delta
 		if newQuantity < 0 {
-			r.logger.Warn("Tentativa de criar estoque com quantidade negativa.", map[string]interface{}{"variant_id": adjustment.VariantID, "warehouse_id": adjustment.WarehouseID, "delta": adjustment.Delta})
+			r.logger.WithContext(ctx).Warn("Tentativa de criar estoque com quantidade negativa.", map[string]interface{}{"variant_id": adjustment.VariantID, "warehouse_id": adjustment.WarehouseID, "delta": adjustment.Delta})
 			return domain.StockLevel{}, errors.NewValidationError("Não é possível criar estoque com quantidade negativa.")
 		}
 
+		// A variante e o armazém precisam pertencer à empresa: os IDs são visíveis no catálogo
+		// público, e a linha criada ocuparia o par (variante, armazém) de outra empresa.
+		var variantOwned, warehouseOwned bool
+		queryOwnership := `
+            SELECT EXISTS (SELECT 1 FROM variants WHERE id = $1 AND tenant_id = $3),
+                   EXISTS (SELECT 1 FROM warehouses WHERE id = $2 AND tenant_id = $3)`
+		if err := tx.QueryRowContext(ctxTimeout, queryOwnership, adjustment.VariantID, adjustment.WarehouseID, tenant.ID(ctx)).Scan(&variantOwned, &warehouseOwned); err != nil {
+			r.logger.WithContext(ctx).Error("Falha ao verificar variante e armazém do ajuste de estoque.", err)
+			return domain.StockLevel{}, errors.NewDBError("Falha ao verificar variante e armazém", err)
+		}
+		if !variantOwned {
+			return domain.StockLevel{}, errors.NewNotFoundError(fmt.Sprintf("Variante com ID %s não encontrada.", adjustment.VariantID))
+		}
+		if !warehouseOwned {
+			return domain.StockLevel{}, errors.NewNotFoundError(fmt.Sprintf("Armazém com ID %s não encontrado.", adjustment.WarehouseID))
+		}
+
 		queryInsert := `
-            INSERT INTO stock_levels (id, variant_id, warehouse_id, quantity, version, created_at, updated_at)
-            VALUES ($1, $2, $3, $4, $5, $6, $7)
+            INSERT INTO stock_levels (id, variant_id, warehouse_id, quantity, version, created_at, updated_at, tenant_id)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
             RETURNING id, variant_id, warehouse_id, quantity, version, created_at, updated_at`
 
 		var newSl domain.StockLevel
 		err = tx.QueryRowContext(ctxTimeout, queryInsert,
-			newID, adjustment.VariantID, adjustment.WarehouseID, newQuantity, 1, time.Now(), time.Now(),
+			newID, adjustment.VariantID, adjustment.WarehouseID, newQuantity, 1, time.Now(), time.Now(), tenant.ID(ctx),
 		).Scan(
 			&newSl.ID, &newSl.VariantID, &newSl.WarehouseID, &newSl.Quantity,
 			&newSl.Version, &newSl.CreatedAt, &newSl.UpdatedAt,
 		)
 		if err != nil {
-			r.logger.Error("Falha ao inserir novo nível de estoque.", err)
+			r.logger.WithContext(ctx).Error("Falha ao inserir novo nível de estoque.", err)
 			return domain.StockLevel{}, errors.NewDBError("Falha ao inserir novo nível de estoque", err)
 		}
 
 		if commitErr := tx.Commit(); commitErr != nil {
-			r.logger.Error("Falha ao commitar transação de inserção de estoque.", commitErr)
+			r.logger.WithContext(ctx).Error("Falha ao commitar transação de inserção de estoque.", commitErr)
 			return domain.StockLevel{}, errors.NewDBError("Falha ao commitar transação", commitErr)
 		}
-		r.logger.Info("Novo nível de estoque criado com sucesso.", map[string]interface{}{"variant_id": adjustment.VariantID, "warehouse_id": adjustment.WarehouseID, "quantity": newSl.Quantity})
+		r.logger.WithContext(ctx).Info("Novo nível de estoque criado com sucesso.", map[string]interface{}{"variant_id": adjustment.VariantID, "warehouse_id": adjustment.WarehouseID, "quantity": newSl.Quantity})
 		return newSl, nil
 
 	} else if err != nil {
-		r.logger.Error("Falha ao selecionar nível de estoque para atualização.", err)
+		r.logger.WithContext(ctx).Error("Falha ao selecionar nível de estoque para atualização.", err)
 		return domain.StockLevel{}, errors.NewDBError("Falha ao buscar estoque para atualização", err)
 	}
 
 	// 2. Aplicar o ajuste e verificar se a quantidade resultará em negativo
 	newQuantity := currentStock.Quantity + adjustment.Delta
 	if newQuantity < 0 {
-		r.logger.Warn("Tentativa de ajustar estoque para quantidade negativa.", map[string]interface{}{"variant_id": adjustment.VariantID, "warehouse_id": adjustment.WarehouseID, "current_quantity": currentStock.Quantity, "delta": adjustment.Delta})
+		r.logger.WithContext(ctx).Warn("Tentativa de ajustar estoque para quantidade negativa.", map[string]interface{}{"variant_id": adjustment.VariantID, "warehouse_id": adjustment.WarehouseID, "current_quantity": currentStock.Quantity, "delta": adjustment.Delta})
 		return domain.StockLevel{}, errors.NewValidationError("Ajuste resultaria em quantidade de estoque negativa.")
 	}
 
@@ -139,7 +194,7 @@ func (r *StockRepository) UpdateStockLevel(ctx context.Context, adjustment domai
 	queryUpdate := `
         UPDATE stock_levels
         SET quantity = $1, version = $2, updated_at = $3
-        WHERE variant_id = $4 AND warehouse_id = $5 AND version = $6`
+        WHERE variant_id = $4 AND warehouse_id = $5 AND version = $6 AND tenant_id = $7`
 
 	result, err := tx.ExecContext(ctxTimeout, queryUpdate,
 		newQuantity,
@@ -148,20 +203,21 @@ func (r *StockRepository) UpdateStockLevel(ctx context.Context, adjustment domai
 		adjustment.VariantID,
 		adjustment.WarehouseID,
 		currentStock.Version, // Checa a versão antiga para OCC
+		tenant.ID(ctx),
 	)
 	if err != nil {
-		r.logger.Error("Falha ao atualizar nível de estoque.", err)
+		r.logger.WithContext(ctx).Error("Falha ao atualizar nível de estoque.", err)
 		return domain.StockLevel{}, errors.NewDBError("Falha ao atualizar estoque", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		r.logger.Error("Falha ao verificar linhas afetadas após atualização de estoque.", err)
+		r.logger.WithContext(ctx).Error("Falha ao verificar linhas afetadas após atualização de estoque.", err)
 		return domain.StockLevel{}, errors.NewDBError("Falha ao verificar linhas afetadas", err)
 	}
 
 	if rowsAffected == 0 {
-		r.logger.Warn("Falha no controle de concorrência otimista (OCC). Versão do registro desatualizada.", map[string]interface{}{
+		r.logger.WithContext(ctx).Warn("Falha no controle de concorrência otimista (OCC). Versão do registro desatualizada.", map[string]interface{}{
 			"variant_id": adjustment.VariantID,
 			"warehouse_id": adjustment.WarehouseID,
 			"expected_version": currentStock.Version,
@@ -172,14 +228,14 @@ func (r *StockRepository) UpdateStockLevel(ctx context.Context, adjustment domai
 
 	// 4. Commitar a transação
 	if commitErr := tx.Commit(); commitErr != nil {
-		r.logger.Error("Falha ao commitar transação de atualização de estoque.", commitErr)
+		r.logger.WithContext(ctx).Error("Falha ao commitar transação de atualização de estoque.", commitErr)
 		return domain.StockLevel{}, errors.NewDBError("Falha ao commitar transação", commitErr)
 	}
 
 	currentStock.Quantity = newQuantity
 	currentStock.Version++
 	currentStock.UpdatedAt = time.Now() // Atualiza o campo UpdatedAt para refletir a mudança
-	r.logger.Info("Nível de estoque atualizado com sucesso.", map[string]interface{}{
+	r.logger.WithContext(ctx).Info("Nível de estoque atualizado com sucesso.", map[string]interface{}{
 		"variant_id": adjustment.VariantID,
 		"warehouse_id": adjustment.WarehouseID,
 		"new_quantity": newQuantity,
@@ -187,3 +243,67 @@ func (r *StockRepository) UpdateStockLevel(ctx context.Context, adjustment domai
 	})
 	return currentStock, nil
 }
+
+// StreamExportRows percorre os níveis de estoque filtrados linha a linha, chamando fn para cada um.
+// Os filtros de produto são os mesmos da listagem (productrepo.FilterClause).
+// Não aplica DBTimeout: a duração é limitada pelo contexto da requisição.
+func (r *StockRepository) StreamExportRows(ctx context.Context, filter domain.StockExportFilter, fn func(domain.StockExportRow) error) error {
+	r.logger.WithContext(ctx).Debug("Iniciando exportação de estoque no repositório.", map[string]interface{}{"filter": filter})
+
+	query := `
+        SELECT sl.warehouse_id, w.name, products.id, products.sku, products.name,
+               sl.variant_id, pv.attribute, pv.value, COALESCE(pv.barcode, ''),
+               sl.quantity, sl.version, sl.updated_at
+        FROM stock_levels sl
+        JOIN warehouses w ON w.id = sl.warehouse_id
+        JOIN variants pv ON pv.id = sl.variant_id
+        JOIN products ON products.id = pv.product_id
+        WHERE sl.tenant_id = $1 `
+
+	where, args, argCounter := productrepo.FilterClause(filter.Product, 2)
+	query += where
+	args = append([]interface{}{tenant.ID(ctx)}, args...)
+	if filter.WarehouseID != "" {
+		query += fmt.Sprintf(" AND sl.warehouse_id = $%d", argCounter)
+		args = append(args, filter.WarehouseID)
+		argCounter++
+	}
+	if filter.WarehouseIDs != nil {
+		query += fmt.Sprintf(" AND sl.warehouse_id = ANY($%d::uuid[])", argCounter)
+		args = append(args, pq.Array(filter.WarehouseIDs))
+	}
+	query += " ORDER BY w.name, sl.warehouse_id, " + productrepo.OrderByClause(filter.Product.SortBy, filter.Product.SortOrder) + ", pv.barcode"
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao executar query de exportação de estoque.", err)
+		return errors.NewDBError("Falha ao exportar estoque", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var row domain.StockExportRow
+		if err := rows.Scan(
+			&row.WarehouseID, &row.WarehouseName, &row.ProductID, &row.SKU, &row.ProductName,
+			&row.VariantID, &row.Attribute, &row.Value, &row.Barcode,
+			&row.Quantity, &row.Version, &row.UpdatedAt,
+		); err != nil {
+			r.logger.WithContext(ctx).Error("Falha ao mapear linha da exportação de estoque.", err)
+			return errors.NewDBError("Falha ao mapear linha da exportação de estoque", err)
+		}
+
+		if err := fn(row); err != nil {
+			return err
+		}
+		count++
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.WithContext(ctx).Error("Erro após iteração da exportação de estoque.", err)
+		return errors.NewDBError("Erro após iteração da exportação de estoque", err)
+	}
+
+	r.logger.WithContext(ctx).Info("Exportação de estoque concluída no repositório.", map[string]interface{}{"rows": count})
+	return nil
+}
@@ -0,0 +1,156 @@
+package stockrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"gostock/internal/domain"
+	"gostock/internal/errors"
+	"gostock/internal/pkg/tenant"
+)
+
+// GetVariantUnits busca a configuração de unidades de medida de uma variante.
+// Retorna NotFoundError se a variante não tiver unidades configuradas.
+func (r *StockRepository) GetVariantUnits(ctx context.Context, variantID string) (domain.VariantUnits, error) {
+	r.logger.WithContext(ctx).Debug("Buscando unidades de medida da variante no repositório.", map[string]interface{}{"variant_id": variantID})
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	tenantID := tenant.ID(ctx)
+	units := domain.VariantUnits{VariantID: variantID, Conversions: []domain.UnitConversion{}}
+	err := r.DB.QueryRowContext(ctxTimeout,
+		`SELECT base_unit, decimal_places, updated_at FROM variant_units WHERE variant_id = $1 AND tenant_id = $2`, variantID, tenantID,
+	).Scan(&units.BaseUnit, &units.DecimalPlaces, &units.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return domain.VariantUnits{}, errors.NewNotFoundError(fmt.Sprintf("A variante %s não possui unidades de medida configuradas.", variantID))
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao buscar unidades de medida da variante.", err)
+		return domain.VariantUnits{}, errors.NewDBError("Falha ao buscar unidades de medida", err)
+	}
+
+	// factor::text preserva a representação decimal exata (sem passar por float64).
+	rows, err := r.DB.QueryContext(ctxTimeout,
+		`SELECT unit, factor::text FROM variant_unit_conversions WHERE variant_id = $1 AND tenant_id = $2 ORDER BY factor, unit`, variantID, tenantID)
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao buscar conversões de unidades da variante.", err)
+		return domain.VariantUnits{}, errors.NewDBError("Falha ao buscar conversões de unidades", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c domain.UnitConversion
+		if err := rows.Scan(&c.Unit, &c.Factor); err != nil {
+			return domain.VariantUnits{}, errors.NewDBError("Falha ao mapear conversões de unidades", err)
+		}
+		c.Factor = trimDecimalZeros(c.Factor) // NUMERIC(20,6) devolve "12.000000"
+		units.Conversions = append(units.Conversions, c)
+	}
+	if err := rows.Err(); err != nil {
+		return domain.VariantUnits{}, errors.NewDBError("Erro após iteração de conversões de unidades", err)
+	}
+
+	return units, nil
+}
+
+// SaveVariantUnits cria ou substitui a configuração de unidades de uma variante.
+// Alterar a precisão (decimal_places) de uma variante que já tem estoque mudaria o significado
+// das quantidades armazenadas, então é recusado com ConflictError. Variantes de outra empresa
+// resultam em NotFoundError.
+func (r *StockRepository) SaveVariantUnits(ctx context.Context, units domain.VariantUnits) (domain.VariantUnits, error) {
+	r.logger.WithContext(ctx).Debug("Salvando unidades de medida da variante no repositório.", map[string]interface{}{"variant_id": units.VariantID})
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctxTimeout, nil)
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao iniciar transação para SaveVariantUnits.", err)
+		return domain.VariantUnits{}, errors.NewDBError("failed to start tx", err)
+	}
+	defer tx.Rollback() // Sem efeito após o Commit
+
+	tenantID := tenant.ID(ctx)
+	var exists bool
+	if err := tx.QueryRowContext(ctxTimeout, `SELECT EXISTS (SELECT 1 FROM variants WHERE id = $1 AND tenant_id = $2)`, units.VariantID, tenantID).Scan(&exists); err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao verificar variante no DB.", err)
+		return domain.VariantUnits{}, errors.NewDBError("Falha ao verificar variante", err)
+	}
+	if !exists {
+		return domain.VariantUnits{}, errors.NewNotFoundError(fmt.Sprintf("Variante com ID %s não encontrada.", units.VariantID))
+	}
+
+	currentPlaces := 0 // Variantes sem configuração são discretas
+	err = tx.QueryRowContext(ctxTimeout,
+		`SELECT decimal_places FROM variant_units WHERE variant_id = $1 AND tenant_id = $2 FOR UPDATE`, units.VariantID, tenantID,
+	).Scan(&currentPlaces)
+	if err != nil && err != sql.ErrNoRows {
+		return domain.VariantUnits{}, errors.NewDBError("Falha ao buscar unidades de medida", err)
+	}
+
+	if currentPlaces != units.DecimalPlaces {
+		var hasStock bool
+		err = tx.QueryRowContext(ctxTimeout,
+			`SELECT EXISTS (SELECT 1 FROM stock_levels WHERE variant_id = $1 AND tenant_id = $2 AND quantity <> 0)`, units.VariantID, tenantID,
+		).Scan(&hasStock)
+		if err != nil {
+			return domain.VariantUnits{}, errors.NewDBError("Falha ao verificar estoque da variante", err)
+		}
+		if hasStock {
+			return domain.VariantUnits{}, errors.NewConflictError("Não é possível alterar 'decimal_places' de uma variante com estoque.")
+		}
+	}
+
+	units.UpdatedAt = time.Now().UTC()
+	result, err := tx.ExecContext(ctxTimeout, `
+        INSERT INTO variant_units (variant_id, base_unit, decimal_places, updated_at, tenant_id)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (variant_id) DO UPDATE
+            SET base_unit = EXCLUDED.base_unit,
+                decimal_places = EXCLUDED.decimal_places,
+                updated_at = EXCLUDED.updated_at
+            WHERE variant_units.tenant_id = EXCLUDED.tenant_id`,
+		units.VariantID, units.BaseUnit, units.DecimalPlaces, units.UpdatedAt, tenantID)
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao gravar unidades de medida da variante.", err)
+		return domain.VariantUnits{}, errors.NewDBError("Falha ao gravar unidades de medida", err)
+	}
+	// O ON CONFLICT não atualiza linhas de outra empresa; nesse caso nada foi gravado
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		r.logger.WithContext(ctx).Warn("Unidades de medida da variante não gravadas.", map[string]interface{}{"variant_id": units.VariantID})
+		return domain.VariantUnits{}, errors.NewNotFoundError(fmt.Sprintf("Variante com ID %s não encontrada.", units.VariantID))
+	}
+
+	if _, err = tx.ExecContext(ctxTimeout, `DELETE FROM variant_unit_conversions WHERE variant_id = $1 AND tenant_id = $2`, units.VariantID, tenantID); err != nil {
+		return domain.VariantUnits{}, errors.NewDBError("Falha ao substituir conversões de unidades", err)
+	}
+	for _, c := range units.Conversions {
+		_, err = tx.ExecContext(ctxTimeout,
+			`INSERT INTO variant_unit_conversions (variant_id, unit, factor, tenant_id) VALUES ($1, $2, $3, $4)`,
+			units.VariantID, c.Unit, c.Factor, tenantID)
+		if err != nil {
+			r.logger.WithContext(ctx).Error("Falha ao gravar conversão de unidade.", err)
+			return domain.VariantUnits{}, errors.NewDBError("Falha ao gravar conversões de unidades", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao commitar transação de SaveVariantUnits.", err)
+		return domain.VariantUnits{}, errors.NewDBError("failed to commit tx", err)
+	}
+
+	r.logger.WithContext(ctx).Info("Unidades de medida da variante salvas.", map[string]interface{}{"variant_id": units.VariantID, "base_unit": units.BaseUnit})
+	return units, nil
+}
+
+// trimDecimalZeros remove zeros à direita da parte fracionária ("12.500000" -> "12.5", "12.000000" -> "12").
+func trimDecimalZeros(s string) string {
+	if !strings.Contains(s, ".") {
+		return s
+	}
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
@@ -0,0 +1,203 @@
+package tenantrepo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/cache"
+	"gostock/internal/pkg/logger"
+)
+
+// pqUniqueViolation é o código do PostgreSQL para violação de unicidade.
+const pqUniqueViolation = "23505"
+
+// Chaves de cache das empresas. As requisições públicas resolvem o slug (header X-Tenant) e o
+// login consulta a empresa do usuário, por isso ficam em cache (invalidado nas alterações).
+const (
+	tenantCacheKey     = "tenant:%s"
+	tenantSlugCacheKey = "tenant-slug:%s"
+)
+
+// tenantCacheTTL é uma salvaguarda caso uma invalidação falhe.
+const tenantCacheTTL = 5 * time.Minute
+
+// TenantRepository implementa a persistência das empresas (tenants).
+type TenantRepository struct {
+	DB        *sql.DB
+	Cache     cache.Client
+	DBTimeout time.Duration
+	logger    logger.Logger
+}
+
+// NewTenantRepository cria e retorna uma nova instância do Repositório de Empresas.
+func NewTenantRepository(db *sql.DB, cacheClient cache.Client, dbTimeout time.Duration, logger logger.Logger) *TenantRepository {
+	return &TenantRepository{
+		DB:        db,
+		Cache:     cacheClient,
+		DBTimeout: dbTimeout,
+		logger:    logger,
+	}
+}
+
+const tenantColumns = `id, slug, name, settings, disabled, created_at, updated_at`
+
+// CreateTenant insere uma nova empresa. Slugs repetidos retornam ConflictError.
+func (r *TenantRepository) CreateTenant(ctx domain.Context, t domain.Tenant) (domain.Tenant, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	settings, _ := json.Marshal(t.Settings) // TenantSettings sempre é serializável
+	query := `INSERT INTO tenants (id, slug, name, settings, disabled, created_at, updated_at)
+              VALUES ($1, $2, $3, $4, FALSE, NOW(), NOW()) RETURNING ` + tenantColumns
+	created, err := scanTenant(r.DB.QueryRowContext(ctxTimeout, query, t.ID, t.Slug, t.Name, settings))
+	if err != nil {
+		if isPQError(err, pqUniqueViolation) {
+			return domain.Tenant{}, apperror.NewConflictError(fmt.Sprintf("Já existe uma empresa com o identificador '%s'.", t.Slug))
+		}
+		r.logger.WithContext(ctx).Error("Falha ao inserir empresa no DB.", err)
+		return domain.Tenant{}, apperror.NewDBError("failed to insert tenant (DB)", err)
+	}
+	return created, nil
+}
+
+// FindTenant busca uma empresa pelo ID, usando a estratégia Cache-Aside.
+func (r *TenantRepository) FindTenant(ctx domain.Context, id string) (domain.Tenant, error) {
+	return r.findCached(ctx.(context.Context), fmt.Sprintf(tenantCacheKey, id), `id = $1`, id,
+		fmt.Sprintf("Empresa com ID '%s' não encontrada.", id))
+}
+
+// FindTenantBySlug busca uma empresa pelo slug, usando a estratégia Cache-Aside.
+func (r *TenantRepository) FindTenantBySlug(ctx domain.Context, slug string) (domain.Tenant, error) {
+	return r.findCached(ctx.(context.Context), fmt.Sprintf(tenantSlugCacheKey, slug), `slug = $1`, slug,
+		fmt.Sprintf("Empresa '%s' não encontrada.", slug))
+}
+
+// findCached busca uma empresa pela condição informada, lendo e populando o cache na chave dada.
+func (r *TenantRepository) findCached(ctx context.Context, key, condition, value, notFound string) (domain.Tenant, error) {
+	if cached, err := r.Cache.Get(ctx, key); err == nil {
+		var t domain.Tenant
+		if json.Unmarshal([]byte(cached), &t) == nil {
+			return t, nil
+		}
+	} else if err != cache.ErrCacheMiss {
+		r.logger.WithContext(ctx).Warn("Falha ao ler empresa do cache.", map[string]interface{}{"key": key, "error": err.Error()})
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	t, err := scanTenant(r.DB.QueryRowContext(ctxTimeout, `SELECT `+tenantColumns+` FROM tenants WHERE `+condition, value))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Tenant{}, apperror.NewNotFoundError(notFound)
+		}
+		r.logger.WithContext(ctx).Error("Falha ao buscar empresa no DB.", err)
+		return domain.Tenant{}, apperror.NewDBError("failed to find tenant (DB)", err)
+	}
+
+	if data, err := json.Marshal(t); err == nil {
+		r.Cache.Set(ctx, key, data, tenantCacheTTL)
+	}
+	return t, nil
+}
+
+// ListTenants lista todas as empresas em ordem alfabética de slug.
+func (r *TenantRepository) ListTenants(ctx domain.Context) ([]domain.Tenant, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctxTimeout, `SELECT `+tenantColumns+` FROM tenants ORDER BY slug`)
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao listar empresas no DB.", err)
+		return nil, apperror.NewDBError("failed to list tenants (DB)", err)
+	}
+	defer rows.Close()
+
+	tenants := []domain.Tenant{}
+	for rows.Next() {
+		t, err := scanTenant(rows)
+		if err != nil {
+			return nil, apperror.NewDBError("failed to scan tenant (DB)", err)
+		}
+		tenants = append(tenants, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperror.NewDBError("failed to list tenants (DB)", err)
+	}
+	return tenants, nil
+}
+
+// UpdateTenant altera o nome, as configurações e o status de uma empresa (o slug é fixo).
+func (r *TenantRepository) UpdateTenant(ctx domain.Context, t domain.Tenant) (domain.Tenant, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	settings, _ := json.Marshal(t.Settings)
+	query := `UPDATE tenants SET name = $2, settings = $3, disabled = $4, updated_at = NOW()
+              WHERE id = $1 RETURNING ` + tenantColumns
+	updated, err := scanTenant(r.DB.QueryRowContext(ctxTimeout, query, t.ID, t.Name, settings, t.Disabled))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Tenant{}, apperror.NewNotFoundError(fmt.Sprintf("Empresa com ID '%s' não encontrada.", t.ID))
+		}
+		r.logger.WithContext(ctx).Error("Falha ao atualizar empresa no DB.", err)
+		return domain.Tenant{}, apperror.NewDBError("failed to update tenant (DB)", err)
+	}
+
+	r.invalidate(ctx.(context.Context), updated)
+	return updated, nil
+}
+
+// CountTenantUsers conta os usuários da empresa, incluindo as contas de serviço.
+func (r *TenantRepository) CountTenantUsers(ctx domain.Context, id string) (int, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	var count int
+	if err := r.DB.QueryRowContext(ctxTimeout, `SELECT COUNT(*) FROM users WHERE tenant_id = $1`, id).Scan(&count); err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao contar usuários da empresa no DB.", err)
+		return 0, apperror.NewDBError("failed to count tenant users (DB)", err)
+	}
+	return count, nil
+}
+
+// invalidate remove a empresa do cache (por ID e por slug) após uma alteração.
+func (r *TenantRepository) invalidate(ctx context.Context, t domain.Tenant) {
+	for _, key := range []string{fmt.Sprintf(tenantCacheKey, t.ID), fmt.Sprintf(tenantSlugCacheKey, t.Slug)} {
+		if err := r.Cache.Delete(ctx, key); err != nil {
+			r.logger.WithContext(ctx).Warn("Falha ao invalidar empresa no cache.", map[string]interface{}{"tenant_id": t.ID, "error": err.Error()})
+		}
+	}
+}
+
+// rowScanner abstrai *sql.Row e *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTenant lê uma empresa nas colunas de tenantColumns.
+func scanTenant(row rowScanner) (domain.Tenant, error) {
+	var t domain.Tenant
+	var settings []byte
+	if err := row.Scan(&t.ID, &t.Slug, &t.Name, &settings, &t.Disabled, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		return domain.Tenant{}, err
+	}
+	if err := json.Unmarshal(settings, &t.Settings); err != nil {
+		return domain.Tenant{}, err
+	}
+	return t, nil
+}
+
+// isPQError verifica se o erro é um erro do PostgreSQL com o código informado.
+func isPQError(err error, code string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && string(pqErr.Code) == code
+}
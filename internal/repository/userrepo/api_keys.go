@@ -0,0 +1,162 @@
+package userrepo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/tenant"
+)
+
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_by, created_at, revoked_at`
+
+// SaveAPIKey grava uma nova chave de API (apenas o hash do segredo).
+func (r *UserRepository) SaveAPIKey(ctx domain.Context, key domain.APIKey) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	scopes, _ := json.Marshal(key.Scopes) // []Permission sempre é serializável
+	var createdBy sql.NullString
+	if key.CreatedBy != "" {
+		createdBy = sql.NullString{String: key.CreatedBy, Valid: true}
+	}
+
+	query := `INSERT INTO api_keys (id, user_id, name, prefix, key_hash, scopes, expires_at, created_by, created_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	_, err := r.DB.ExecContext(ctxTimeout, query, key.ID, key.UserID, key.Name, key.Prefix, key.KeyHash, scopes, key.ExpiresAt, createdBy, key.CreatedAt)
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao inserir chave de API no DB.", err)
+		return apperror.NewDBError("failed to insert api key (DB)", err)
+	}
+	return nil
+}
+
+// FindAPIKeyByPrefix busca a chave de API pelo prefixo (parte pública da chave).
+func (r *UserRepository) FindAPIKeyByPrefix(ctx domain.Context, prefix string) (domain.APIKey, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	key, err := scanAPIKey(r.DB.QueryRowContext(ctxTimeout, `SELECT `+apiKeyColumns+` FROM api_keys WHERE prefix = $1`, prefix))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.APIKey{}, apperror.NewNotFoundError("Chave de API não encontrada.")
+		}
+		r.logger.WithContext(ctx).Error("Falha ao buscar chave de API por prefixo no DB.", err)
+		return domain.APIKey{}, apperror.NewDBError("failed to find api key (DB)", err)
+	}
+	return key, nil
+}
+
+// GetAPIKey busca a chave de API pelo ID.
+func (r *UserRepository) GetAPIKey(ctx domain.Context, id string) (domain.APIKey, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	// Com empresa no contexto, só encontra chaves de usuários dela
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys
+              WHERE id = $1 AND user_id IN (SELECT id FROM users WHERE ` + tenantScope(2) + `)`
+	key, err := scanAPIKey(r.DB.QueryRowContext(ctxTimeout, query, id, tenant.Optional(ctx.(context.Context))))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.APIKey{}, apperror.NewNotFoundError(fmt.Sprintf("Chave de API com ID '%s' não encontrada", id))
+		}
+		r.logger.WithContext(ctx).Error("Falha ao buscar chave de API no DB.", err)
+		return domain.APIKey{}, apperror.NewDBError("failed to find api key (DB)", err)
+	}
+	return key, nil
+}
+
+// ListAPIKeys retorna as chaves do usuário, das mais recentes para as mais antigas (inclusive as revogadas).
+func (r *UserRepository) ListAPIKeys(ctx domain.Context, userID string) ([]domain.APIKey, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctxTimeout, `SELECT `+apiKeyColumns+` FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC`, userID)
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao listar chaves de API no DB.", err)
+		return nil, apperror.NewDBError("failed to list api keys (DB)", err)
+	}
+	defer rows.Close()
+
+	keys := []domain.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, apperror.NewDBError("failed to scan api key (DB)", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperror.NewDBError("failed to iterate api keys (DB)", err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey revoga a chave. Revogar uma chave já revogada não altera a data original.
+func (r *UserRepository) RevokeAPIKey(ctx domain.Context, id string) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	query := `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $2)
+              WHERE id = $1 AND user_id IN (SELECT id FROM users WHERE ` + tenantScope(3) + `)`
+	result, err := r.DB.ExecContext(ctxTimeout, query, id, time.Now().UTC(), tenant.Optional(ctx.(context.Context)))
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao revogar chave de API no DB.", err)
+		return apperror.NewDBError("failed to revoke api key (DB)", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return apperror.NewDBError("failed to revoke api key (DB)", err)
+	}
+	if affected == 0 {
+		return apperror.NewNotFoundError(fmt.Sprintf("Chave de API com ID '%s' não encontrada", id))
+	}
+	return nil
+}
+
+// TouchAPIKey registra o último uso da chave.
+func (r *UserRepository) TouchAPIKey(ctx domain.Context, id string, usedAt time.Time) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	if _, err := r.DB.ExecContext(ctxTimeout, `UPDATE api_keys SET last_used_at = $2 WHERE id = $1`, id, usedAt); err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao registrar uso da chave de API no DB.", err)
+		return apperror.NewDBError("failed to touch api key (DB)", err)
+	}
+	return nil
+}
+
+// rowScanner abstrai *sql.Row e *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanAPIKey converte uma linha (colunas de apiKeyColumns) em domain.APIKey.
+func scanAPIKey(row rowScanner) (domain.APIKey, error) {
+	var key domain.APIKey
+	var scopes []byte
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	var createdBy sql.NullString
+	if err := row.Scan(&key.ID, &key.UserID, &key.Name, &key.Prefix, &key.KeyHash, &scopes, &expiresAt, &lastUsedAt, &createdBy, &key.CreatedAt, &revokedAt); err != nil {
+		return domain.APIKey{}, err
+	}
+	if err := json.Unmarshal(scopes, &key.Scopes); err != nil {
+		return domain.APIKey{}, err
+	}
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	key.CreatedBy = createdBy.String
+	return key, nil
+}
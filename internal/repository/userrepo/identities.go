@@ -0,0 +1,47 @@
+package userrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+)
+
+// FindIdentity busca a identidade externa pelo emissor e sub.
+func (r *UserRepository) FindIdentity(ctx domain.Context, issuer, subject string) (domain.UserIdentity, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	query := `SELECT issuer, subject, user_id, email, created_at, last_login_at FROM user_identities
+              WHERE issuer = $1 AND subject = $2`
+
+	var identity domain.UserIdentity
+	err := r.DB.QueryRowContext(ctxTimeout, query, issuer, subject).Scan(
+		&identity.Issuer, &identity.Subject, &identity.UserID, &identity.Email, &identity.CreatedAt, &identity.LastLoginAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.UserIdentity{}, apperror.NewNotFoundError("Identidade externa não vinculada.")
+		}
+		r.logger.WithContext(ctx).Error("Falha ao buscar identidade externa no DB.", err)
+		return domain.UserIdentity{}, apperror.NewDBError("failed to find user identity (DB)", err)
+	}
+	return identity, nil
+}
+
+// SaveIdentity grava a identidade externa ou atualiza o e-mail e o último login, se já existir.
+func (r *UserRepository) SaveIdentity(ctx domain.Context, identity domain.UserIdentity) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	query := `INSERT INTO user_identities (issuer, subject, user_id, email, created_at, last_login_at)
+              VALUES ($1, $2, $3, $4, $5, $6)
+              ON CONFLICT (issuer, subject) DO UPDATE SET email = EXCLUDED.email, last_login_at = EXCLUDED.last_login_at`
+	if _, err := r.DB.ExecContext(ctxTimeout, query, identity.Issuer, identity.Subject, identity.UserID, identity.Email, identity.CreatedAt, identity.LastLoginAt); err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao gravar identidade externa no DB.", err)
+		return apperror.NewDBError("failed to save user identity (DB)", err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package userrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/tenant"
+)
+
+// List retorna uma página de usuários ordenada por e-mail e o total de usuários cadastrados (da empresa do contexto, se houver).
+func (r *UserRepository) List(ctx domain.Context, limit, offset int) ([]domain.User, int, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	tenantID := tenant.Optional(ctx.(context.Context))
+	var total int
+	if err := r.DB.QueryRowContext(ctxTimeout, `SELECT COUNT(*) FROM users WHERE `+tenantScope(1), tenantID).Scan(&total); err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao contar usuários no DB.", err)
+		return nil, 0, apperror.NewDBError("failed to count users (DB)", err)
+	}
+
+	query := `SELECT ` + userColumns + ` FROM users WHERE ` + tenantScope(3) + ` ORDER BY email LIMIT $1 OFFSET $2`
+	rows, err := r.DB.QueryContext(ctxTimeout, query, limit, offset, tenantID)
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao listar usuários no DB.", err)
+		return nil, 0, apperror.NewDBError("failed to list users (DB)", err)
+	}
+	defer rows.Close()
+
+	users := []domain.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, apperror.NewDBError("failed to scan user (DB)", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, apperror.NewDBError("failed to iterate users (DB)", err)
+	}
+	return users, total, nil
+}
+
+// SetDisabled desativa ou reativa a conta do usuário e retorna o usuário atualizado.
+func (r *UserRepository) SetDisabled(ctx domain.Context, id string, disabled bool) (domain.User, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	query := `UPDATE users SET disabled = $2, updated_at = $3 WHERE id = $1 AND ` + tenantScope(4) + `
+              RETURNING ` + userColumns
+
+	user, err := scanUser(r.DB.QueryRowContext(ctxTimeout, query, id, disabled, time.Now(), tenant.Optional(ctx.(context.Context))))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.User{}, apperror.NewNotFoundError(fmt.Sprintf("Usuário com ID '%s' não encontrado", id))
+		}
+		r.logger.WithContext(ctx).Error("Falha ao alterar status do usuário no DB.", err)
+		return domain.User{}, apperror.NewDBError("failed to update user status (DB)", err)
+	}
+	return user, nil
+}
+
+// UpdatePassword grava o novo hash da senha do usuário.
+func (r *UserRepository) UpdatePassword(ctx domain.Context, id string, passwordHash string) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctxTimeout, `UPDATE users SET password_hash = $2, updated_at = $3 WHERE id = $1 AND `+tenantScope(4),
+		id, passwordHash, time.Now(), tenant.Optional(ctx.(context.Context)))
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao atualizar senha do usuário no DB.", err)
+		return apperror.NewDBError("failed to update user password (DB)", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return apperror.NewNotFoundError(fmt.Sprintf("Usuário com ID '%s' não encontrado", id))
+	}
+	return nil
+}
+
+// MarkEmailVerified marca o e-mail do usuário como verificado.
+func (r *UserRepository) MarkEmailVerified(ctx domain.Context, id string) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctxTimeout, `UPDATE users SET email_verified = TRUE, updated_at = $2 WHERE id = $1 AND `+tenantScope(3),
+		id, time.Now(), tenant.Optional(ctx.(context.Context)))
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao marcar e-mail como verificado no DB.", err)
+		return apperror.NewDBError("failed to mark email verified (DB)", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return apperror.NewNotFoundError(fmt.Sprintf("Usuário com ID '%s' não encontrado", id))
+	}
+	return nil
+}
+
+// Delete remove o usuário. Refresh tokens e atribuições de armazéns são removidos em cascata.
+func (r *UserRepository) Delete(ctx domain.Context, id string) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctxTimeout, `DELETE FROM users WHERE id = $1 AND `+tenantScope(2), id, tenant.Optional(ctx.(context.Context)))
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao remover usuário no DB.", err)
+		return apperror.NewDBError("failed to delete user (DB)", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return apperror.NewNotFoundError(fmt.Sprintf("Usuário com ID '%s' não encontrado", id))
+	}
+
+	r.logger.WithContext(ctx).Info("Usuário removido.", map[string]interface{}{"user_id": id})
+	return nil
+}
+
+// CountByRole conta os usuários com o papel informado.
+func (r *UserRepository) CountByRole(ctx domain.Context, role domain.UserRole) (int, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	var count int
+	if err := r.DB.QueryRowContext(ctxTimeout, `SELECT COUNT(*) FROM users WHERE role = $1 AND `+tenantScope(2), role, tenant.Optional(ctx.(context.Context))).Scan(&count); err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao contar usuários por papel no DB.", err)
+		return 0, apperror.NewDBError("failed to count users by role (DB)", err)
+	}
+	return count, nil
+}
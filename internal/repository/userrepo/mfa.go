@@ -0,0 +1,168 @@
+package userrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+)
+
+// GetUserMFA busca o cadastro do segundo fator do usuário.
+func (r *UserRepository) GetUserMFA(ctx domain.Context, userID string) (domain.UserMFA, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	query := `SELECT user_id, secret, enabled, last_used_step, created_at, enabled_at FROM user_mfa WHERE user_id = $1`
+
+	var mfa domain.UserMFA
+	var lastUsedStep sql.NullInt64
+	var enabledAt sql.NullTime
+	err := r.DB.QueryRowContext(ctxTimeout, query, userID).Scan(
+		&mfa.UserID, &mfa.Secret, &mfa.Enabled, &lastUsedStep, &mfa.CreatedAt, &enabledAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.UserMFA{}, apperror.NewNotFoundError("Segundo fator não cadastrado.")
+		}
+		r.logger.WithContext(ctx).Error("Falha ao buscar segundo fator no DB.", err)
+		return domain.UserMFA{}, apperror.NewDBError("failed to find user mfa (DB)", err)
+	}
+	mfa.LastUsedStep = lastUsedStep.Int64
+	if enabledAt.Valid {
+		mfa.EnabledAt = &enabledAt.Time
+	}
+	return mfa, nil
+}
+
+// SaveUserMFA grava ou substitui o cadastro do segundo fator do usuário.
+func (r *UserRepository) SaveUserMFA(ctx domain.Context, mfa domain.UserMFA) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	var lastUsedStep sql.NullInt64
+	if mfa.LastUsedStep > 0 {
+		lastUsedStep = sql.NullInt64{Int64: mfa.LastUsedStep, Valid: true}
+	}
+	query := `INSERT INTO user_mfa (user_id, secret, enabled, last_used_step, created_at, enabled_at)
+              VALUES ($1, $2, $3, $4, $5, $6)
+              ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, enabled = EXCLUDED.enabled,
+                  last_used_step = EXCLUDED.last_used_step, created_at = EXCLUDED.created_at, enabled_at = EXCLUDED.enabled_at`
+	if _, err := r.DB.ExecContext(ctxTimeout, query, mfa.UserID, mfa.Secret, mfa.Enabled, lastUsedStep, mfa.CreatedAt, mfa.EnabledAt); err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao gravar segundo fator no DB.", err)
+		return apperror.NewDBError("failed to save user mfa (DB)", err)
+	}
+	return nil
+}
+
+// AdvanceMFAStep registra o intervalo TOTP usado. A condição no WHERE garante que um código (ou
+// um mais antigo) não seja aceito duas vezes, mesmo entre requisições concorrentes.
+func (r *UserRepository) AdvanceMFAStep(ctx domain.Context, userID string, step int64) (bool, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	query := `UPDATE user_mfa SET last_used_step = $2
+              WHERE user_id = $1 AND (last_used_step IS NULL OR last_used_step < $2)`
+	result, err := r.DB.ExecContext(ctxTimeout, query, userID, step)
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao registrar código TOTP usado no DB.", err)
+		return false, apperror.NewDBError("failed to advance mfa step (DB)", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, apperror.NewDBError("failed to advance mfa step (DB)", err)
+	}
+	return affected == 1, nil
+}
+
+// DeleteUserMFA remove o cadastro do segundo fator e os códigos de recuperação.
+func (r *UserRepository) DeleteUserMFA(ctx domain.Context, userID string) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctxTimeout, nil)
+	if err != nil {
+		return apperror.NewDBError("failed to begin transaction", err)
+	}
+	defer tx.Rollback() // Sem efeito após o Commit
+
+	if _, err := tx.ExecContext(ctxTimeout, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao remover códigos de recuperação no DB.", err)
+		return apperror.NewDBError("failed to delete recovery codes (DB)", err)
+	}
+	if _, err := tx.ExecContext(ctxTimeout, `DELETE FROM user_mfa WHERE user_id = $1`, userID); err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao remover segundo fator no DB.", err)
+		return apperror.NewDBError("failed to delete user mfa (DB)", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return apperror.NewDBError("failed to commit transaction", err)
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes substitui os códigos de recuperação do usuário em uma transação.
+func (r *UserRepository) ReplaceRecoveryCodes(ctx domain.Context, userID string, codeHashes []string) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctxTimeout, nil)
+	if err != nil {
+		return apperror.NewDBError("failed to begin transaction", err)
+	}
+	defer tx.Rollback() // Sem efeito após o Commit
+
+	if _, err := tx.ExecContext(ctxTimeout, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao remover códigos de recuperação no DB.", err)
+		return apperror.NewDBError("failed to delete recovery codes (DB)", err)
+	}
+	now := time.Now().UTC()
+	for _, hash := range codeHashes {
+		if _, err := tx.ExecContext(ctxTimeout,
+			`INSERT INTO user_recovery_codes (id, user_id, code_hash, created_at) VALUES ($1, $2, $3, $4)`,
+			uuid.NewString(), userID, hash, now,
+		); err != nil {
+			r.logger.WithContext(ctx).Error("Falha ao inserir código de recuperação no DB.", err)
+			return apperror.NewDBError("failed to insert recovery code (DB)", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return apperror.NewDBError("failed to commit transaction", err)
+	}
+	return nil
+}
+
+// UseRecoveryCode marca o código como usado, apenas se ainda não foi.
+func (r *UserRepository) UseRecoveryCode(ctx domain.Context, userID, codeHash string) (bool, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	query := `UPDATE user_recovery_codes SET used_at = $3 WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`
+	result, err := r.DB.ExecContext(ctxTimeout, query, userID, codeHash, time.Now().UTC())
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao usar código de recuperação no DB.", err)
+		return false, apperror.NewDBError("failed to use recovery code (DB)", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, apperror.NewDBError("failed to use recovery code (DB)", err)
+	}
+	return affected == 1, nil
+}
+
+// CountRecoveryCodes conta os códigos de recuperação ainda não usados.
+func (r *UserRepository) CountRecoveryCodes(ctx domain.Context, userID string) (int, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	var count int
+	query := `SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = $1 AND used_at IS NULL`
+	if err := r.DB.QueryRowContext(ctxTimeout, query, userID).Scan(&count); err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao contar códigos de recuperação no DB.", err)
+		return 0, apperror.NewDBError("failed to count recovery codes (DB)", err)
+	}
+	return count, nil
+}
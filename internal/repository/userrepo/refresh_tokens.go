@@ -0,0 +1,105 @@
+package userrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+)
+
+// SaveRefreshToken grava um novo refresh token (apenas o hash).
+func (r *UserRepository) SaveRefreshToken(ctx domain.Context, token domain.RefreshToken) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	query := `INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at, created_at)
+              VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := r.DB.ExecContext(ctxTimeout, query, token.ID, token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt, token.CreatedAt)
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao inserir refresh token no DB.", err)
+		return apperror.NewDBError("failed to insert refresh token (DB)", err)
+	}
+	return nil
+}
+
+// FindRefreshTokenByHash busca um refresh token pelo hash.
+func (r *UserRepository) FindRefreshTokenByHash(ctx domain.Context, tokenHash string) (domain.RefreshToken, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	query := `SELECT id, user_id, family_id, token_hash, expires_at, created_at, replaced_by, revoked_at
+              FROM refresh_tokens WHERE token_hash = $1`
+
+	var token domain.RefreshToken
+	var replacedBy sql.NullString
+	var revokedAt sql.NullTime
+	err := r.DB.QueryRowContext(ctxTimeout, query, tokenHash).Scan(
+		&token.ID, &token.UserID, &token.FamilyID, &token.TokenHash, &token.ExpiresAt, &token.CreatedAt, &replacedBy, &revokedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.RefreshToken{}, apperror.NewNotFoundError("Refresh token não encontrado.")
+		}
+		r.logger.WithContext(ctx).Error("Falha ao buscar refresh token no DB.", err)
+		return domain.RefreshToken{}, apperror.NewDBError("failed to find refresh token (DB)", err)
+	}
+
+	token.ReplacedBy = replacedBy.String
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	return token, nil
+}
+
+// RotateRefreshToken marca o token como substituído. A condição no WHERE garante que, entre
+// requisições concorrentes com o mesmo token, apenas uma consiga a rotação.
+func (r *UserRepository) RotateRefreshToken(ctx domain.Context, id, replacedBy string) (bool, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	query := `UPDATE refresh_tokens SET replaced_by = $2
+              WHERE id = $1 AND replaced_by IS NULL AND revoked_at IS NULL`
+	result, err := r.DB.ExecContext(ctxTimeout, query, id, replacedBy)
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao rotacionar refresh token no DB.", err)
+		return false, apperror.NewDBError("failed to rotate refresh token (DB)", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, apperror.NewDBError("failed to rotate refresh token (DB)", err)
+	}
+	return affected == 1, nil
+}
+
+// RevokeRefreshTokenFamily revoga todos os refresh tokens ainda ativos de uma sessão.
+func (r *UserRepository) RevokeRefreshTokenFamily(ctx domain.Context, familyID string) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	query := `UPDATE refresh_tokens SET revoked_at = $2 WHERE family_id = $1 AND revoked_at IS NULL`
+	if _, err := r.DB.ExecContext(ctxTimeout, query, familyID, time.Now().UTC()); err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao revogar família de refresh tokens no DB.", err)
+		return apperror.NewDBError("failed to revoke refresh token family (DB)", err)
+	}
+
+	r.logger.WithContext(ctx).Info("Família de refresh tokens revogada.", map[string]interface{}{"family_id": familyID})
+	return nil
+}
+
+// RevokeUserRefreshTokens revoga os refresh tokens ainda ativos de todas as sessões do usuário.
+func (r *UserRepository) RevokeUserRefreshTokens(ctx domain.Context, userID string) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	query := `UPDATE refresh_tokens SET revoked_at = $2 WHERE user_id = $1 AND revoked_at IS NULL`
+	if _, err := r.DB.ExecContext(ctxTimeout, query, userID, time.Now().UTC()); err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao revogar refresh tokens do usuário no DB.", err)
+		return apperror.NewDBError("failed to revoke user refresh tokens (DB)", err)
+	}
+
+	r.logger.WithContext(ctx).Info("Refresh tokens do usuário revogados.", map[string]interface{}{"user_id": userID})
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	"gostock/internal/domain"
 	apperror "gostock/internal/errors"
 	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/tenant"
 )
 
 // UserRepository implementa a interface domain.UserRepository
@@ -27,8 +28,8 @@ type UserRepository struct {
 // NewUserRepository cria uma nova instância do UserRepository, injetando o DB.
 func NewUserRepository(db *sql.DB, dbTimeout time.Duration, logger logger.Logger) *UserRepository {
 	// Definimos a query SQL para inserção de usuário
-	insertSQL := `INSERT INTO users (id, email, password_hash, role, created_at, updated_at) 
-                  VALUES ($1, $2, $3, $4, $5, $6)`
+	insertSQL := `INSERT INTO users (id, email, password_hash, role, created_at, updated_at, email_verified, service_account, tenant_id) 
+                  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
 
 	return &UserRepository{
 		DB:        db,
@@ -42,9 +43,36 @@ func NewUserRepository(db *sql.DB, dbTimeout time.Duration, logger logger.Logger
 	}
 }
 
+// userColumns são as colunas lidas por scanUser.
+const userColumns = `id, tenant_id, email, password_hash, role, disabled, email_verified, service_account, created_at, updated_at`
+
+// tenantScope filtra os usuários pela empresa do parâmetro $n (valor de tenant.Optional).
+// Com o parâmetro NULL, sem empresa no contexto, o filtro não restringe nada.
+func tenantScope(n int) string {
+	return fmt.Sprintf("($%d::uuid IS NULL OR tenant_id = $%d::uuid)", n, n)
+}
+
+// scanUser lê um usuário nas colunas de userColumns.
+func scanUser(row rowScanner) (domain.User, error) {
+	var user domain.User
+	err := row.Scan(
+		&user.ID,
+		&user.TenantID,
+		&user.Email,
+		&user.PasswordHash,
+		&user.Role,
+		&user.Disabled,
+		&user.EmailVerified,
+		&user.ServiceAccount,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	return user, err
+}
+
 // Save insere um novo usuário no banco de dados.
 func (r *UserRepository) Save(ctx domain.Context, user domain.User) (domain.User, error) {
-	r.logger.Debug("Iniciando Save de usuário no repositório.", map[string]interface{}{"email": user.Email})
+	r.logger.WithContext(ctx).Debug("Iniciando Save de usuário no repositório.", map[string]interface{}{"email": user.Email})
 
 	// 1. Configura Contexto com Timeout
 	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
@@ -54,7 +82,10 @@ func (r *UserRepository) Save(ctx domain.Context, user domain.User) (domain.User
 	user.ID = uuid.NewString()
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = user.CreatedAt
-	r.logger.Debug("Gerado novo ID e timestamps para o usuário.", map[string]interface{}{"user_id": user.ID, "email": user.Email})
+	if user.TenantID == "" {
+		user.TenantID = tenant.ID(ctx.(context.Context))
+	}
+	r.logger.WithContext(ctx).Debug("Gerado novo ID e timestamps para o usuário.", map[string]interface{}{"user_id": user.ID, "email": user.Email})
 
 	// 3. Executa o INSERT
 	_, err := r.DB.ExecContext(
@@ -66,58 +97,71 @@ func (r *UserRepository) Save(ctx domain.Context, user domain.User) (domain.User
 		user.Role,
 		user.CreatedAt,
 		user.UpdatedAt,
+		user.EmailVerified,
+		user.ServiceAccount,
+		user.TenantID,
 	)
 
 	if err != nil {
-		r.logger.Error("Falha ao inserir usuário no DB.", err)
+		r.logger.WithContext(ctx).Error("Falha ao inserir usuário no DB.", err)
 		// Verifica se é um erro de duplicidade (ex: email já existe)
 		// No PostgreSQL, isso exige verificar o erro específico do driver (pq)
 		// Por enquanto, simplificamos como um erro interno de DB
 		return domain.User{}, apperror.NewDBError("failed to insert user (DB)", err)
 	}
 
-	r.logger.Info("Usuário salvo com sucesso no repositório.", map[string]interface{}{"user_id": user.ID, "email": user.Email})
+	r.logger.WithContext(ctx).Info("Usuário salvo com sucesso no repositório.", map[string]interface{}{"user_id": user.ID, "email": user.Email})
 	return user, nil
 }
 
 // FindByEmail busca um usuário pelo endereço de e-mail.
 func (r *UserRepository) FindByEmail(ctx domain.Context, email string) (domain.User, error) {
-	r.logger.Debug("Iniciando FindByEmail de usuário no repositório.", map[string]interface{}{"email_attempt": email})
+	r.logger.WithContext(ctx).Debug("Iniciando FindByEmail de usuário no repositório.", map[string]interface{}{"email_attempt": email})
 
 	// 1. Configura Contexto com Timeout
 	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
 	defer cancel()
 
 	// 2. Define a query SQL
-	query := `SELECT id, email, password_hash, role, created_at, updated_at FROM users WHERE email = $1`
-	r.logger.Debug("Executando query FindByEmail.", map[string]interface{}{"email": email})
+	//    Sem tenant no contexto (login) a busca vale para todas as empresas: o e-mail é único
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1 AND ` + tenantScope(2)
+	r.logger.WithContext(ctx).Debug("Executando query FindByEmail.", map[string]interface{}{"email": email})
 
 	// 3. Executa a busca
-	row := r.DB.QueryRowContext(ctxTimeout, query, email)
-
-	var user domain.User
+	row := r.DB.QueryRowContext(ctxTimeout, query, email, tenant.Optional(ctx.(context.Context)))
 
 	// 4. Mapeia o resultado para a struct User
-	err := row.Scan(
-		&user.ID,
-		&user.Email,
-		&user.PasswordHash,
-		&user.Role,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	user, err := scanUser(row)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			r.logger.Info("Usuário não encontrado no DB por email.", map[string]interface{}{"email": email})
+			r.logger.WithContext(ctx).Info("Usuário não encontrado no DB por email.", map[string]interface{}{"email": email})
 			// Retorna um erro tipado 404
 			return domain.User{}, apperror.NewNotFoundError(fmt.Sprintf("Usuário com email '%s' não encontrado", email))
 		}
-		r.logger.Error("Falha ao buscar usuário por email no DB.", err)
+		r.logger.WithContext(ctx).Error("Falha ao buscar usuário por email no DB.", err)
 		// Retorna um erro interno de DB para qualquer outra falha de SQL
 		return domain.User{}, apperror.NewDBError("failed to find user by email (DB)", err)
 	}
 
-	r.logger.Info("Usuário encontrado no repositório por email.", map[string]interface{}{"user_id": user.ID, "email": user.Email})
+	r.logger.WithContext(ctx).Info("Usuário encontrado no repositório por email.", map[string]interface{}{"user_id": user.ID, "email": user.Email})
+	return user, nil
+}
+
+// FindByID busca um usuário pelo ID.
+func (r *UserRepository) FindByID(ctx domain.Context, id string) (domain.User, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1 AND ` + tenantScope(2)
+
+	user, err := scanUser(r.DB.QueryRowContext(ctxTimeout, query, id, tenant.Optional(ctx.(context.Context))))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.User{}, apperror.NewNotFoundError(fmt.Sprintf("Usuário com ID '%s' não encontrado", id))
+		}
+		r.logger.WithContext(ctx).Error("Falha ao buscar usuário por ID no DB.", err)
+		return domain.User{}, apperror.NewDBError("failed to find user by id (DB)", err)
+	}
 	return user, nil
 }
@@ -0,0 +1,83 @@
+package userrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+)
+
+// SaveUserToken grava um novo token de uso único (apenas o hash).
+func (r *UserRepository) SaveUserToken(ctx domain.Context, token domain.UserToken) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	query := `INSERT INTO user_tokens (id, user_id, purpose, token_hash, expires_at, created_at)
+              VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := r.DB.ExecContext(ctxTimeout, query, token.ID, token.UserID, token.Purpose, token.TokenHash, token.ExpiresAt, token.CreatedAt)
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao inserir token de uso único no DB.", err)
+		return apperror.NewDBError("failed to insert user token (DB)", err)
+	}
+	return nil
+}
+
+// FindUserTokenByHash busca um token de uso único pela finalidade e pelo hash.
+func (r *UserRepository) FindUserTokenByHash(ctx domain.Context, purpose domain.UserTokenPurpose, tokenHash string) (domain.UserToken, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	query := `SELECT id, user_id, purpose, token_hash, expires_at, created_at, used_at
+              FROM user_tokens WHERE purpose = $1 AND token_hash = $2`
+
+	var token domain.UserToken
+	var usedAt sql.NullTime
+	err := r.DB.QueryRowContext(ctxTimeout, query, purpose, tokenHash).Scan(
+		&token.ID, &token.UserID, &token.Purpose, &token.TokenHash, &token.ExpiresAt, &token.CreatedAt, &usedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.UserToken{}, apperror.NewNotFoundError("Token não encontrado.")
+		}
+		r.logger.WithContext(ctx).Error("Falha ao buscar token de uso único no DB.", err)
+		return domain.UserToken{}, apperror.NewDBError("failed to find user token (DB)", err)
+	}
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+	return token, nil
+}
+
+// ConsumeUserToken marca o token como usado. A condição no WHERE garante que, entre requisições
+// concorrentes com o mesmo token, apenas uma o consuma.
+func (r *UserRepository) ConsumeUserToken(ctx domain.Context, id string) (bool, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctxTimeout, `UPDATE user_tokens SET used_at = $2 WHERE id = $1 AND used_at IS NULL`, id, time.Now().UTC())
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao consumir token de uso único no DB.", err)
+		return false, apperror.NewDBError("failed to consume user token (DB)", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, apperror.NewDBError("failed to consume user token (DB)", err)
+	}
+	return affected == 1, nil
+}
+
+// InvalidateUserTokens invalida os tokens ainda não usados do usuário para a finalidade.
+func (r *UserRepository) InvalidateUserTokens(ctx domain.Context, userID string, purpose domain.UserTokenPurpose) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx.(context.Context), r.DBTimeout)
+	defer cancel()
+
+	query := `UPDATE user_tokens SET used_at = $3 WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`
+	if _, err := r.DB.ExecContext(ctxTimeout, query, userID, purpose, time.Now().UTC()); err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao invalidar tokens de uso único no DB.", err)
+		return apperror.NewDBError("failed to invalidate user tokens (DB)", err)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package warehouserepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/tenant"
+)
+
+// GetUserWarehouses retorna os armazéns atribuídos ao usuário.
+// Retorna NotFoundError se o usuário não existir.
+func (r *WarehouseRepository) GetUserWarehouses(ctx context.Context, userID string) (domain.UserWarehouses, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	// O LEFT JOIN distingue usuário inexistente (nenhuma linha) de usuário sem armazéns (uma linha com NULL)
+	query := `
+        SELECT uw.warehouse_id
+        FROM users u
+        LEFT JOIN user_warehouses uw ON uw.user_id = u.id AND uw.tenant_id = u.tenant_id
+        WHERE u.id = $1 AND u.tenant_id = $2
+        ORDER BY uw.warehouse_id`
+
+	rows, err := r.DB.QueryContext(ctxTimeout, query, userID, tenant.ID(ctx))
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao buscar armazéns do usuário no DB.", err)
+		return domain.UserWarehouses{}, apperror.NewDBError("failed to query user warehouses (DB)", err)
+	}
+	defer rows.Close()
+
+	result := domain.UserWarehouses{UserID: userID, WarehouseIDs: []string{}}
+	found := false
+	for rows.Next() {
+		found = true
+		var warehouseID sql.NullString
+		if err := rows.Scan(&warehouseID); err != nil {
+			return domain.UserWarehouses{}, apperror.NewDBError("failed to scan user warehouse (DB)", err)
+		}
+		if warehouseID.Valid {
+			result.WarehouseIDs = append(result.WarehouseIDs, warehouseID.String)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return domain.UserWarehouses{}, apperror.NewDBError("failed to iterate user warehouses (DB)", err)
+	}
+	if !found {
+		return domain.UserWarehouses{}, apperror.NewNotFoundError(fmt.Sprintf("Usuário com ID '%s' não encontrado.", userID))
+	}
+	return result, nil
+}
+
+// SetUserWarehouses substitui os armazéns atribuídos ao usuário.
+// Retorna NotFoundError se o usuário ou algum dos armazéns não existir.
+func (r *WarehouseRepository) SetUserWarehouses(ctx context.Context, assignment domain.UserWarehouses) (domain.UserWarehouses, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctxTimeout, nil)
+	if err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao iniciar transação para SetUserWarehouses.", err)
+		return domain.UserWarehouses{}, apperror.NewDBError("failed to start tx", err)
+	}
+	defer tx.Rollback() // Sem efeito após o Commit
+
+	tenantID := tenant.ID(ctx)
+	var exists bool
+	if err := tx.QueryRowContext(ctxTimeout, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND tenant_id = $2)`, assignment.UserID, tenantID).Scan(&exists); err != nil {
+		return domain.UserWarehouses{}, apperror.NewDBError("failed to check user (DB)", err)
+	}
+	if !exists {
+		return domain.UserWarehouses{}, apperror.NewNotFoundError(fmt.Sprintf("Usuário com ID '%s' não encontrado.", assignment.UserID))
+	}
+
+	if _, err := tx.ExecContext(ctxTimeout, `DELETE FROM user_warehouses WHERE user_id = $1 AND tenant_id = $2`, assignment.UserID, tenantID); err != nil {
+		r.logger.WithContext(ctx).Error("Falha ao remover armazéns do usuário no DB.", err)
+		return domain.UserWarehouses{}, apperror.NewDBError("failed to clear user warehouses (DB)", err)
+	}
+	if len(assignment.WarehouseIDs) > 0 {
+		// Só entram armazéns da empresa: um ID de outra empresa conta como inexistente
+		result, err := tx.ExecContext(ctxTimeout, `
+            INSERT INTO user_warehouses (user_id, warehouse_id, tenant_id)
+            SELECT $1, w.id, w.tenant_id FROM warehouses w
+            WHERE w.id = ANY($2::uuid[]) AND w.tenant_id = $3`,
+			assignment.UserID, pq.Array(assignment.WarehouseIDs), tenantID)
+		if err != nil {
+			r.logger.WithContext(ctx).Error("Falha ao atribuir armazéns ao usuário no DB.", err)
+			return domain.UserWarehouses{}, apperror.NewDBError("failed to assign user warehouses (DB)", err)
+		}
+		inserted, err := result.RowsAffected()
+		if err != nil {
+			return domain.UserWarehouses{}, apperror.NewDBError("failed to assign user warehouses (DB)", err)
+		}
+		if int(inserted) != len(distinct(assignment.WarehouseIDs)) {
+			return domain.UserWarehouses{}, apperror.NewNotFoundError("Um ou mais armazéns informados não existem.")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.UserWarehouses{}, apperror.NewDBError("failed to commit tx", err)
+	}
+	return assignment, nil
+}
+
+// distinct retorna os valores sem repetição, na ordem em que aparecem.
+func distinct(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
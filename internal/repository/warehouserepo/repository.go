@@ -11,6 +11,7 @@ import (
 	"gostock/internal/domain"
 	"gostock/internal/errors"
 	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/tenant"
 )
 
 // WarehouseRepository implementa a interface para operações CRUD de armazéns.
@@ -31,7 +32,7 @@ func NewWarehouseRepository(db *sql.DB, dbTimeout time.Duration, logger logger.L
 
 // CreateWarehouse insere um novo armazém no banco de dados.
 func (r *WarehouseRepository) CreateWarehouse(ctx context.Context, warehouse domain.Warehouse) (domain.Warehouse, error) {
-	r.logger.Debug("Iniciando CreateWarehouse no repositório.", map[string]interface{}{"name": warehouse.Name})
+	r.logger.WithContext(ctx).Debug("Iniciando CreateWarehouse no repositório.", map[string]interface{}{"name": warehouse.Name})
 
 	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
 	defer cancel()
@@ -44,27 +45,27 @@ func (r *WarehouseRepository) CreateWarehouse(ctx context.Context, warehouse dom
 	warehouse.UpdatedAt = now
 
 	query := `
-        INSERT INTO warehouses (id, name, created_at, updated_at)
-        VALUES ($1, $2, $3, $4)
+        INSERT INTO warehouses (id, name, created_at, updated_at, tenant_id)
+        VALUES ($1, $2, $3, $4, $5)
         RETURNING id, name, created_at, updated_at`
 
 	err := r.DB.QueryRowContext(ctxTimeout, query,
-		warehouse.ID, warehouse.Name, warehouse.CreatedAt, warehouse.UpdatedAt,
+		warehouse.ID, warehouse.Name, warehouse.CreatedAt, warehouse.UpdatedAt, tenant.ID(ctx),
 	).Scan(
 		&warehouse.ID, &warehouse.Name, &warehouse.CreatedAt, &warehouse.UpdatedAt,
 	)
 	if err != nil {
-		r.logger.Error("Falha ao inserir armazém no DB.", err)
+		r.logger.WithContext(ctx).Error("Falha ao inserir armazém no DB.", err)
 		return domain.Warehouse{}, errors.NewDBError("Falha ao criar armazém", err)
 	}
 
-	r.logger.Info("Armazém criado com sucesso.", map[string]interface{}{"id": warehouse.ID, "name": warehouse.Name})
+	r.logger.WithContext(ctx).Info("Armazém criado com sucesso.", map[string]interface{}{"id": warehouse.ID, "name": warehouse.Name})
 	return warehouse, nil
 }
 
 // GetWarehouseByID busca um armazém pelo ID.
 func (r *WarehouseRepository) GetWarehouseByID(ctx context.Context, id string) (domain.Warehouse, error) {
-	r.logger.Debug("Iniciando GetWarehouseByID no repositório.", map[string]interface{}{"id": id})
+	r.logger.WithContext(ctx).Debug("Iniciando GetWarehouseByID no repositório.", map[string]interface{}{"id": id})
 
 	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
 	defer cancel()
@@ -72,29 +73,29 @@ func (r *WarehouseRepository) GetWarehouseByID(ctx context.Context, id string) (
 	query := `
         SELECT id, name, created_at, updated_at
         FROM warehouses
-        WHERE id = $1`
+        WHERE id = $1 AND tenant_id = $2`
 
 	var warehouse domain.Warehouse
-	err := r.DB.QueryRowContext(ctxTimeout, query, id).Scan(
+	err := r.DB.QueryRowContext(ctxTimeout, query, id, tenant.ID(ctx)).Scan(
 		&warehouse.ID, &warehouse.Name, &warehouse.CreatedAt, &warehouse.UpdatedAt,
 	)
 
 	if err == sql.ErrNoRows {
-		r.logger.Info("Armazém não encontrado.", map[string]interface{}{"id": id})
+		r.logger.WithContext(ctx).Info("Armazém não encontrado.", map[string]interface{}{"id": id})
 		return domain.Warehouse{}, errors.NewNotFoundError(fmt.Sprintf("Armazém com ID %s não encontrado.", id))
 	}
 	if err != nil {
-		r.logger.Error("Falha ao buscar armazém no DB.", err)
+		r.logger.WithContext(ctx).Error("Falha ao buscar armazém no DB.", err)
 		return domain.Warehouse{}, errors.NewDBError("Falha ao buscar armazém", err)
 	}
 
-	r.logger.Info("Armazém encontrado.", map[string]interface{}{"id": id, "name": warehouse.Name})
+	r.logger.WithContext(ctx).Info("Armazém encontrado.", map[string]interface{}{"id": id, "name": warehouse.Name})
 	return warehouse, nil
 }
 
 // GetAllWarehouses busca todos os armazéns.
 func (r *WarehouseRepository) GetAllWarehouses(ctx context.Context) ([]domain.Warehouse, error) {
-	r.logger.Debug("Iniciando GetAllWarehouses no repositório.", nil)
+	r.logger.WithContext(ctx).Debug("Iniciando GetAllWarehouses no repositório.", nil)
 
 	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
 	defer cancel()
@@ -102,11 +103,12 @@ func (r *WarehouseRepository) GetAllWarehouses(ctx context.Context) ([]domain.Wa
 	query := `
         SELECT id, name, created_at, updated_at
         FROM warehouses
+        WHERE tenant_id = $1
         ORDER BY name`
 
-	rows, err := r.DB.QueryContext(ctxTimeout, query)
+	rows, err := r.DB.QueryContext(ctxTimeout, query, tenant.ID(ctx))
 	if err != nil {
-		r.logger.Error("Falha ao executar GetAllWarehouses query.", err)
+		r.logger.WithContext(ctx).Error("Falha ao executar GetAllWarehouses query.", err)
 		return nil, errors.NewDBError("Falha ao buscar todos os armazéns", err)
 	}
 	defer rows.Close()
@@ -118,24 +120,24 @@ func (r *WarehouseRepository) GetAllWarehouses(ctx context.Context) ([]domain.Wa
 			&warehouse.ID, &warehouse.Name, &warehouse.CreatedAt, &warehouse.UpdatedAt,
 		)
 		if err != nil {
-			r.logger.Error("Falha ao mapear armazém na iteração de GetAllWarehouses.", err)
+			r.logger.WithContext(ctx).Error("Falha ao mapear armazém na iteração de GetAllWarehouses.", err)
 			return nil, errors.NewDBError("Falha ao mapear armazéns do DB", err)
 		}
 		warehouses = append(warehouses, warehouse)
 	}
 
 	if err := rows.Err(); err != nil {
-		r.logger.Error("Erro após iteração das linhas de armazéns.", err)
+		r.logger.WithContext(ctx).Error("Erro após iteração das linhas de armazéns.", err)
 		return nil, errors.NewDBError("Erro após iteração de armazéns", err)
 	}
 
-	r.logger.Info("GetAllWarehouses concluído com sucesso.", map[string]interface{}{"total_warehouses": len(warehouses)})
+	r.logger.WithContext(ctx).Info("GetAllWarehouses concluído com sucesso.", map[string]interface{}{"total_warehouses": len(warehouses)})
 	return warehouses, nil
 }
 
 // UpdateWarehouse atualiza um armazém existente.
 func (r *WarehouseRepository) UpdateWarehouse(ctx context.Context, warehouse domain.Warehouse) (domain.Warehouse, error) {
-	r.logger.Debug("Iniciando UpdateWarehouse no repositório.", map[string]interface{}{"id": warehouse.ID, "name": warehouse.Name})
+	r.logger.WithContext(ctx).Debug("Iniciando UpdateWarehouse no repositório.", map[string]interface{}{"id": warehouse.ID, "name": warehouse.Name})
 
 	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
 	defer cancel()
@@ -145,56 +147,56 @@ func (r *WarehouseRepository) UpdateWarehouse(ctx context.Context, warehouse dom
 	query := `
         UPDATE warehouses
         SET name = $1, updated_at = $2
-        WHERE id = $3
+        WHERE id = $3 AND tenant_id = $4
         RETURNING id, name, created_at, updated_at`
 
 	err := r.DB.QueryRowContext(ctxTimeout, query,
-		warehouse.Name, warehouse.UpdatedAt, warehouse.ID,
+		warehouse.Name, warehouse.UpdatedAt, warehouse.ID, tenant.ID(ctx),
 	).Scan(
 		&warehouse.ID, &warehouse.Name, &warehouse.CreatedAt, &warehouse.UpdatedAt,
 	)
 
 	if err == sql.ErrNoRows {
-		r.logger.Info("Armazém não encontrado para atualização.", map[string]interface{}{"id": warehouse.ID})
+		r.logger.WithContext(ctx).Info("Armazém não encontrado para atualização.", map[string]interface{}{"id": warehouse.ID})
 		return domain.Warehouse{}, errors.NewNotFoundError(fmt.Sprintf("Armazém com ID %s não encontrado para atualização.", warehouse.ID))
 	}
 	if err != nil {
-		r.logger.Error("Falha ao atualizar armazém no DB.", err)
+		r.logger.WithContext(ctx).Error("Falha ao atualizar armazém no DB.", err)
 		return domain.Warehouse{}, errors.NewDBError("Falha ao atualizar armazém", err)
 	}
 
-	r.logger.Info("Armazém atualizado com sucesso.", map[string]interface{}{"id": warehouse.ID, "name": warehouse.Name})
+	r.logger.WithContext(ctx).Info("Armazém atualizado com sucesso.", map[string]interface{}{"id": warehouse.ID, "name": warehouse.Name})
 	return warehouse, nil
 }
 
 // DeleteWarehouse remove um armazém pelo ID.
 func (r *WarehouseRepository) DeleteWarehouse(ctx context.Context, id string) error {
-	r.logger.Debug("Iniciando DeleteWarehouse no repositório.", map[string]interface{}{"id": id})
+	r.logger.WithContext(ctx).Debug("Iniciando DeleteWarehouse no repositório.", map[string]interface{}{"id": id})
 
 	ctxTimeout, cancel := context.WithTimeout(ctx, r.DBTimeout)
 	defer cancel()
 
 	query := `
         DELETE FROM warehouses
-        WHERE id = $1`
+        WHERE id = $1 AND tenant_id = $2`
 
-	result, err := r.DB.ExecContext(ctxTimeout, query, id)
+	result, err := r.DB.ExecContext(ctxTimeout, query, id, tenant.ID(ctx))
 	if err != nil {
-		r.logger.Error("Falha ao deletar armazém do DB.", err)
+		r.logger.WithContext(ctx).Error("Falha ao deletar armazém do DB.", err)
 		return errors.NewDBError("Falha ao deletar armazém", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		r.logger.Error("Falha ao verificar linhas afetadas após DeleteWarehouse.", err)
+		r.logger.WithContext(ctx).Error("Falha ao verificar linhas afetadas após DeleteWarehouse.", err)
 		return errors.NewDBError("Falha ao verificar linhas afetadas", err)
 	}
 
 	if rowsAffected == 0 {
-		r.logger.Info("Armazém não encontrado para exclusão.", map[string]interface{}{"id": id})
+		r.logger.WithContext(ctx).Info("Armazém não encontrado para exclusão.", map[string]interface{}{"id": id})
 		return errors.NewNotFoundError(fmt.Sprintf("Armazém com ID %s não encontrado para exclusão.", id))
 	}
 
-	r.logger.Info("Armazém deletado com sucesso.", map[string]interface{}{"id": id})
+	r.logger.WithContext(ctx).Info("Armazém deletado com sucesso.", map[string]interface{}{"id": id})
 	return nil
 }
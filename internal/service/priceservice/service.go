@@ -0,0 +1,259 @@
+package priceservice
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/logger"
+)
+
+// PriceRepository define o contrato que o Serviço de Preços espera da camada de Persistência.
+type PriceRepository interface {
+	CreatePriceList(ctx context.Context, list domain.PriceList) (domain.PriceList, error)
+	GetPriceListByID(ctx context.Context, id string) (domain.PriceList, error)
+	GetAllPriceLists(ctx context.Context) ([]domain.PriceList, error)
+	UpdatePriceList(ctx context.Context, list domain.PriceList) (domain.PriceList, error)
+	DeletePriceList(ctx context.Context, id string) error
+
+	CreateVariantPrice(ctx context.Context, price domain.VariantPrice) (domain.VariantPrice, error)
+	GetVariantPrices(ctx context.Context, priceListID, variantID string) ([]domain.VariantPrice, error)
+	DeleteVariantPrice(ctx context.Context, priceListID, id string) error
+	FindEffectivePrice(ctx context.Context, priceListID, variantID string, at time.Time) (domain.VariantPrice, error)
+}
+
+// Service implementa as regras de negócio de tabelas de preços e resolução de preços.
+type Service struct {
+	repo   PriceRepository
+	logger logger.Logger
+}
+
+// NewService cria e retorna uma nova instância do Serviço de Preços.
+func NewService(repo PriceRepository, logger logger.Logger) *Service {
+	return &Service{repo: repo, logger: logger}
+}
+
+// toGoContext converte o contexto de domínio, caindo para context.Background() se necessário.
+func (s *Service) toGoContext(ctx domain.Context, operation string) context.Context {
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background() para "+operation, nil)
+		return context.Background()
+	}
+	return ctxGo
+}
+
+// --- Tabelas de Preços ---
+
+// CreatePriceList cria uma nova tabela de preços após validações de negócio.
+func (s *Service) CreatePriceList(ctx domain.Context, list domain.PriceList) (domain.PriceList, error) {
+	s.logger.WithContext(ctx).Debug("Iniciando criação de tabela de preços no serviço.", map[string]interface{}{"name": list.Name})
+
+	list, err := s.normalizePriceList(list)
+	if err != nil {
+		s.logger.WithContext(ctx).Warn("Falha na validação da tabela de preços.", map[string]interface{}{"name": list.Name, "error": err.Error()})
+		return domain.PriceList{}, err
+	}
+
+	created, err := s.repo.CreatePriceList(s.toGoContext(ctx, "CreatePriceList"), list)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao criar tabela de preços no repositório.", err)
+		return domain.PriceList{}, err // Erros do repositório já são ConflictError ou DBError
+	}
+
+	s.logger.WithContext(ctx).Info("Tabela de preços criada com sucesso.", map[string]interface{}{"id": created.ID, "currency": created.Currency})
+	return created, nil
+}
+
+// GetPriceListByID busca uma tabela de preços pelo ID.
+func (s *Service) GetPriceListByID(ctx domain.Context, id string) (domain.PriceList, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return domain.PriceList{}, apperror.NewValidationError("O ID da tabela de preços deve ser um UUID válido.")
+	}
+	return s.repo.GetPriceListByID(s.toGoContext(ctx, "GetPriceListByID"), id)
+}
+
+// GetAllPriceLists busca todas as tabelas de preços.
+func (s *Service) GetAllPriceLists(ctx domain.Context) ([]domain.PriceList, error) {
+	lists, err := s.repo.GetAllPriceLists(s.toGoContext(ctx, "GetAllPriceLists"))
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao buscar tabelas de preços no repositório.", err)
+		return nil, apperror.NewInternalError("Falha interna ao buscar tabelas de preços.", err)
+	}
+	return lists, nil
+}
+
+// UpdatePriceList atualiza uma tabela de preços existente.
+func (s *Service) UpdatePriceList(ctx domain.Context, list domain.PriceList) (domain.PriceList, error) {
+	s.logger.WithContext(ctx).Debug("Iniciando atualização de tabela de preços no serviço.", map[string]interface{}{"id": list.ID})
+
+	if _, err := uuid.Parse(list.ID); err != nil {
+		return domain.PriceList{}, apperror.NewValidationError("O ID da tabela de preços deve ser um UUID válido.")
+	}
+	list, err := s.normalizePriceList(list)
+	if err != nil {
+		s.logger.WithContext(ctx).Warn("Falha na validação da tabela de preços para atualização.", map[string]interface{}{"id": list.ID, "error": err.Error()})
+		return domain.PriceList{}, err
+	}
+
+	updated, err := s.repo.UpdatePriceList(s.toGoContext(ctx, "UpdatePriceList"), list)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao atualizar tabela de preços no repositório.", err)
+		return domain.PriceList{}, err
+	}
+
+	s.logger.WithContext(ctx).Info("Tabela de preços atualizada com sucesso.", map[string]interface{}{"id": updated.ID})
+	return updated, nil
+}
+
+// DeletePriceList remove uma tabela de preços e todos os seus preços.
+func (s *Service) DeletePriceList(ctx domain.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return apperror.NewValidationError("O ID da tabela de preços deve ser um UUID válido.")
+	}
+	if err := s.repo.DeletePriceList(s.toGoContext(ctx, "DeletePriceList"), id); err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao deletar tabela de preços no repositório.", err)
+		return err
+	}
+	s.logger.WithContext(ctx).Info("Tabela de preços deletada com sucesso.", map[string]interface{}{"id": id})
+	return nil
+}
+
+// normalizePriceList valida e normaliza (espaços, caixa da moeda) os dados de uma tabela de preços.
+func (s *Service) normalizePriceList(list domain.PriceList) (domain.PriceList, error) {
+	list.Name = strings.TrimSpace(list.Name)
+	list.Currency = strings.ToUpper(strings.TrimSpace(list.Currency))
+	list.CustomerSegment = strings.TrimSpace(list.CustomerSegment)
+
+	if len(list.Name) < 3 || len(list.Name) > 100 {
+		return list, apperror.NewValidationError("O nome da tabela de preços deve ter entre 3 e 100 caracteres.")
+	}
+	if _, ok := domain.CurrencyMinorUnits(list.Currency); !ok {
+		return list, apperror.NewValidationError("A moeda deve ser um código ISO 4217 suportado (ex.: BRL, USD, EUR).")
+	}
+	if len(list.CustomerSegment) > 100 {
+		return list, apperror.NewValidationError("O segmento de clientes deve ter no máximo 100 caracteres.")
+	}
+	return list, nil
+}
+
+// --- Preços por Variante ---
+
+// CreateVariantPrice cadastra o preço de uma variante em uma tabela, com janela de validade opcional.
+func (s *Service) CreateVariantPrice(ctx domain.Context, price domain.VariantPrice) (domain.VariantPrice, error) {
+	s.logger.WithContext(ctx).Debug("Iniciando criação de preço de variante no serviço.", map[string]interface{}{"price_list_id": price.PriceListID, "variant_id": price.VariantID})
+
+	if _, err := uuid.Parse(price.PriceListID); err != nil {
+		return domain.VariantPrice{}, apperror.NewValidationError("O ID da tabela de preços deve ser um UUID válido.")
+	}
+	if _, err := uuid.Parse(price.VariantID); err != nil {
+		return domain.VariantPrice{}, apperror.NewValidationError("O campo 'variant_id' deve ser um UUID válido.")
+	}
+	if price.Amount < 0 {
+		return domain.VariantPrice{}, apperror.NewValidationError("O valor do preço não pode ser negativo.")
+	}
+	if price.ValidFrom != nil && price.ValidTo != nil && !price.ValidTo.After(*price.ValidFrom) {
+		return domain.VariantPrice{}, apperror.NewValidationError("O campo 'valid_to' deve ser posterior a 'valid_from'.")
+	}
+
+	created, err := s.repo.CreateVariantPrice(s.toGoContext(ctx, "CreateVariantPrice"), price)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao criar preço de variante no repositório.", err)
+		return domain.VariantPrice{}, err // NotFoundError (tabela ou variante inexistente) ou DBError
+	}
+
+	s.logger.WithContext(ctx).Info("Preço de variante criado com sucesso.", map[string]interface{}{"id": created.ID, "amount": created.Amount})
+	return created, nil
+}
+
+// GetVariantPrices lista os preços de uma tabela (opcionalmente de uma única variante).
+func (s *Service) GetVariantPrices(ctx domain.Context, priceListID, variantID string) ([]domain.VariantPrice, error) {
+	if _, err := uuid.Parse(priceListID); err != nil {
+		return nil, apperror.NewValidationError("O ID da tabela de preços deve ser um UUID válido.")
+	}
+	if variantID != "" {
+		if _, err := uuid.Parse(variantID); err != nil {
+			return nil, apperror.NewValidationError("O parâmetro 'variant_id' deve ser um UUID válido.")
+		}
+	}
+
+	ctxGo := s.toGoContext(ctx, "GetVariantPrices")
+	// Garante 404 para tabelas inexistentes em vez de uma lista vazia.
+	if _, err := s.repo.GetPriceListByID(ctxGo, priceListID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetVariantPrices(ctxGo, priceListID, variantID)
+}
+
+// DeleteVariantPrice remove um preço de uma tabela.
+func (s *Service) DeleteVariantPrice(ctx domain.Context, priceListID, id string) error {
+	if _, err := uuid.Parse(priceListID); err != nil {
+		return apperror.NewValidationError("O ID da tabela de preços deve ser um UUID válido.")
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return apperror.NewValidationError("O ID do preço deve ser um UUID válido.")
+	}
+	if err := s.repo.DeleteVariantPrice(s.toGoContext(ctx, "DeleteVariantPrice"), priceListID, id); err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao deletar preço de variante no repositório.", err)
+		return err
+	}
+	s.logger.WithContext(ctx).Info("Preço de variante deletado com sucesso.", map[string]interface{}{"id": id})
+	return nil
+}
+
+// --- Resolução de Preço ---
+
+// ResolvePrice retorna o preço efetivo da variante na tabela de preços no instante 'at'
+// (RFC3339; vazio significa agora).
+func (s *Service) ResolvePrice(ctx domain.Context, variantID, priceListID, at string) (domain.ResolvedPrice, error) {
+	s.logger.WithContext(ctx).Debug("Iniciando resolução de preço no serviço.", map[string]interface{}{"variant_id": variantID, "price_list_id": priceListID, "at": at})
+
+	if _, err := uuid.Parse(variantID); err != nil {
+		return domain.ResolvedPrice{}, apperror.NewValidationError("O parâmetro 'variant_id' deve ser um UUID válido.")
+	}
+	if _, err := uuid.Parse(priceListID); err != nil {
+		return domain.ResolvedPrice{}, apperror.NewValidationError("O parâmetro 'price_list_id' deve ser um UUID válido.")
+	}
+
+	instant := time.Now().UTC()
+	if at != "" {
+		parsed, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			return domain.ResolvedPrice{}, apperror.NewValidationError("O parâmetro 'at' deve estar no formato RFC3339 (ex.: 2025-12-24T10:00:00Z).")
+		}
+		instant = parsed.UTC()
+	}
+
+	ctxGo := s.toGoContext(ctx, "ResolvePrice")
+	list, err := s.repo.GetPriceListByID(ctxGo, priceListID)
+	if err != nil {
+		return domain.ResolvedPrice{}, err
+	}
+	if !list.IsActive {
+		return domain.ResolvedPrice{}, apperror.NewConflictError("A tabela de preços está inativa.")
+	}
+
+	price, err := s.repo.FindEffectivePrice(ctxGo, priceListID, variantID, instant)
+	if err != nil {
+		return domain.ResolvedPrice{}, err // NotFoundError quando não há preço vigente
+	}
+
+	resolved := domain.ResolvedPrice{
+		VariantID:   variantID,
+		PriceListID: priceListID,
+		PriceID:     price.ID,
+		Currency:    list.Currency,
+		Amount:      price.Amount,
+		Formatted:   domain.FormatMinorUnits(price.Amount, list.Currency),
+		At:          instant,
+		ValidFrom:   price.ValidFrom,
+		ValidTo:     price.ValidTo,
+	}
+
+	s.logger.WithContext(ctx).Info("Preço resolvido com sucesso.", map[string]interface{}{"variant_id": variantID, "price_id": price.ID, "amount": price.Amount})
+	return resolved, nil
+}
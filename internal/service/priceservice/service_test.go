@@ -0,0 +1,235 @@
+package priceservice_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/logger"
+	"gostock/internal/service/priceservice"
+)
+
+// MockPriceRepository é uma implementação mock da interface PriceRepository
+type MockPriceRepository struct {
+	mock.Mock
+}
+
+func (m *MockPriceRepository) CreatePriceList(ctx context.Context, list domain.PriceList) (domain.PriceList, error) {
+	args := m.Called(ctx, list)
+	return args.Get(0).(domain.PriceList), args.Error(1)
+}
+
+func (m *MockPriceRepository) GetPriceListByID(ctx context.Context, id string) (domain.PriceList, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.PriceList), args.Error(1)
+}
+
+func (m *MockPriceRepository) GetAllPriceLists(ctx context.Context) ([]domain.PriceList, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.PriceList), args.Error(1)
+}
+
+func (m *MockPriceRepository) UpdatePriceList(ctx context.Context, list domain.PriceList) (domain.PriceList, error) {
+	args := m.Called(ctx, list)
+	return args.Get(0).(domain.PriceList), args.Error(1)
+}
+
+func (m *MockPriceRepository) DeletePriceList(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockPriceRepository) CreateVariantPrice(ctx context.Context, price domain.VariantPrice) (domain.VariantPrice, error) {
+	args := m.Called(ctx, price)
+	return args.Get(0).(domain.VariantPrice), args.Error(1)
+}
+
+func (m *MockPriceRepository) GetVariantPrices(ctx context.Context, priceListID, variantID string) ([]domain.VariantPrice, error) {
+	args := m.Called(ctx, priceListID, variantID)
+	return args.Get(0).([]domain.VariantPrice), args.Error(1)
+}
+
+func (m *MockPriceRepository) DeleteVariantPrice(ctx context.Context, priceListID, id string) error {
+	args := m.Called(ctx, priceListID, id)
+	return args.Error(0)
+}
+
+func (m *MockPriceRepository) FindEffectivePrice(ctx context.Context, priceListID, variantID string, at time.Time) (domain.VariantPrice, error) {
+	args := m.Called(ctx, priceListID, variantID, at)
+	return args.Get(0).(domain.VariantPrice), args.Error(1)
+}
+
+func newTestService(repo *MockPriceRepository) *priceservice.Service {
+	return priceservice.NewService(repo, logger.NewLogger("debug"))
+}
+
+// --- Tabelas de Preços ---
+
+// TestCreatePriceList_Success_NormalizesCurrency testa que a moeda é normalizada para maiúsculas.
+func TestCreatePriceList_Success_NormalizesCurrency(t *testing.T) {
+	mockRepo := new(MockPriceRepository)
+	svc := newTestService(mockRepo)
+
+	expected := domain.PriceList{Name: "Atacado", Currency: "BRL", CustomerSegment: "atacado", IsActive: true}
+	mockRepo.On("CreatePriceList", mock.Anything, expected).Return(expected, nil)
+
+	result, err := svc.CreatePriceList(context.Background(), domain.PriceList{Name: " Atacado ", Currency: "brl", CustomerSegment: "atacado", IsActive: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "BRL", result.Currency)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestCreatePriceList_Fail_UnsupportedCurrency testa a rejeição de moedas fora da ISO 4217 suportada.
+func TestCreatePriceList_Fail_UnsupportedCurrency(t *testing.T) {
+	mockRepo := new(MockPriceRepository)
+	svc := newTestService(mockRepo)
+
+	_, err := svc.CreatePriceList(context.Background(), domain.PriceList{Name: "Atacado", Currency: "XYZ"})
+
+	assert.Error(t, err)
+	assert.IsType(t, &apperror.ValidationError{}, err)
+	mockRepo.AssertNotCalled(t, "CreatePriceList", mock.Anything, mock.Anything)
+}
+
+// TestCreatePriceList_Fail_DuplicateName testa a propagação do conflito de nome vindo do repositório.
+func TestCreatePriceList_Fail_DuplicateName(t *testing.T) {
+	mockRepo := new(MockPriceRepository)
+	svc := newTestService(mockRepo)
+
+	mockRepo.On("CreatePriceList", mock.Anything, mock.Anything).
+		Return(domain.PriceList{}, apperror.NewConflictError("Já existe uma tabela de preços com o nome 'Atacado'."))
+
+	_, err := svc.CreatePriceList(context.Background(), domain.PriceList{Name: "Atacado", Currency: "USD"})
+
+	assert.Error(t, err)
+	assert.IsType(t, &apperror.ConflictError{}, err)
+}
+
+// --- Preços por Variante ---
+
+// TestCreateVariantPrice_Fail_InvalidWindow testa a rejeição de janelas com término antes do início.
+func TestCreateVariantPrice_Fail_InvalidWindow(t *testing.T) {
+	mockRepo := new(MockPriceRepository)
+	svc := newTestService(mockRepo)
+
+	from := time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC)
+	to := from.Add(-time.Hour)
+	_, err := svc.CreateVariantPrice(context.Background(), domain.VariantPrice{
+		PriceListID: uuid.New().String(),
+		VariantID:   uuid.New().String(),
+		Amount:      4990,
+		ValidFrom:   &from,
+		ValidTo:     &to,
+	})
+
+	assert.Error(t, err)
+	assert.IsType(t, &apperror.ValidationError{}, err)
+	assert.Contains(t, err.Error(), "valid_to")
+}
+
+// TestCreateVariantPrice_Fail_NegativeAmount testa a rejeição de valores negativos.
+func TestCreateVariantPrice_Fail_NegativeAmount(t *testing.T) {
+	mockRepo := new(MockPriceRepository)
+	svc := newTestService(mockRepo)
+
+	_, err := svc.CreateVariantPrice(context.Background(), domain.VariantPrice{
+		PriceListID: uuid.New().String(),
+		VariantID:   uuid.New().String(),
+		Amount:      -1,
+	})
+
+	assert.Error(t, err)
+	assert.IsType(t, &apperror.ValidationError{}, err)
+}
+
+// TestCreateVariantPrice_Fail_VariantNotFound testa a propagação do NotFound de uma variante
+// inexistente ou de outra empresa.
+func TestCreateVariantPrice_Fail_VariantNotFound(t *testing.T) {
+	mockRepo := new(MockPriceRepository)
+	svc := newTestService(mockRepo)
+
+	variantID := uuid.New().String()
+	mockRepo.On("CreateVariantPrice", mock.Anything, mock.Anything).
+		Return(domain.VariantPrice{}, apperror.NewNotFoundError("Variante com ID "+variantID+" não encontrada."))
+
+	_, err := svc.CreateVariantPrice(context.Background(), domain.VariantPrice{
+		PriceListID: uuid.New().String(),
+		VariantID:   variantID,
+		Amount:      4990,
+	})
+
+	assert.IsType(t, &apperror.NotFoundError{}, err)
+	assert.Contains(t, err.Error(), "Variante")
+	mockRepo.AssertExpectations(t)
+}
+
+// --- Resolução de Preço ---
+
+// TestResolvePrice_Success testa a resolução do preço vigente em um instante informado.
+func TestResolvePrice_Success(t *testing.T) {
+	mockRepo := new(MockPriceRepository)
+	svc := newTestService(mockRepo)
+
+	listID := uuid.New().String()
+	variantID := uuid.New().String()
+	at := time.Date(2025, 12, 24, 10, 0, 0, 0, time.UTC)
+	promoStart := time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC)
+
+	mockRepo.On("GetPriceListByID", mock.Anything, listID).
+		Return(domain.PriceList{ID: listID, Currency: "BRL", IsActive: true}, nil)
+	mockRepo.On("FindEffectivePrice", mock.Anything, listID, variantID, at).
+		Return(domain.VariantPrice{ID: "promo", Amount: 3990, ValidFrom: &promoStart}, nil)
+
+	resolved, err := svc.ResolvePrice(context.Background(), variantID, listID, "2025-12-24T07:00:00-03:00")
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3990), resolved.Amount)
+	assert.Equal(t, "39.90", resolved.Formatted)
+	assert.Equal(t, "BRL", resolved.Currency)
+	assert.Equal(t, "promo", resolved.PriceID)
+	assert.Equal(t, at, resolved.At)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestResolvePrice_Fail_InactiveList testa que tabelas inativas não resolvem preços.
+func TestResolvePrice_Fail_InactiveList(t *testing.T) {
+	mockRepo := new(MockPriceRepository)
+	svc := newTestService(mockRepo)
+
+	listID := uuid.New().String()
+	mockRepo.On("GetPriceListByID", mock.Anything, listID).
+		Return(domain.PriceList{ID: listID, Currency: "BRL", IsActive: false}, nil)
+
+	_, err := svc.ResolvePrice(context.Background(), uuid.New().String(), listID, "")
+
+	assert.Error(t, err)
+	assert.IsType(t, &apperror.ConflictError{}, err)
+	mockRepo.AssertNotCalled(t, "FindEffectivePrice", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestResolvePrice_Fail_InvalidTimestamp testa a rejeição de instantes fora do formato RFC3339.
+func TestResolvePrice_Fail_InvalidTimestamp(t *testing.T) {
+	mockRepo := new(MockPriceRepository)
+	svc := newTestService(mockRepo)
+
+	_, err := svc.ResolvePrice(context.Background(), uuid.New().String(), uuid.New().String(), "24/12/2025")
+
+	assert.Error(t, err)
+	assert.IsType(t, &apperror.ValidationError{}, err)
+}
+
+// TestFormatMinorUnits testa a formatação de valores em unidades mínimas por moeda.
+func TestFormatMinorUnits(t *testing.T) {
+	assert.Equal(t, "49.90", domain.FormatMinorUnits(4990, "BRL"))
+	assert.Equal(t, "0.05", domain.FormatMinorUnits(5, "USD"))
+	assert.Equal(t, "-1.50", domain.FormatMinorUnits(-150, "EUR"))
+	assert.Equal(t, "1500", domain.FormatMinorUnits(1500, "JPY"))
+	assert.Equal(t, "1.250", domain.FormatMinorUnits(1250, "KWD"))
+}
@@ -0,0 +1,299 @@
+package productservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+)
+
+// maxAttributeValues limita a quantidade de valores permitidos por definição de atributo.
+const maxAttributeValues = 200
+
+// --- Definições de atributos ---
+
+// CreateAttributeDefinition cadastra um eixo de variação (ex.: "Tamanho") com seus valores permitidos.
+func (s *Service) CreateAttributeDefinition(ctx domain.Context, def domain.AttributeDefinition) (domain.AttributeDefinition, error) {
+	if err := normalizeAttributeDefinition(&def); err != nil {
+		s.logger.WithContext(ctx).Warn("Definição de atributo inválida.", map[string]interface{}{"name": def.Name, "error": err.Error()})
+		return domain.AttributeDefinition{}, err
+	}
+
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background()", nil)
+	}
+
+	def.ID = ""
+	return s.repo.CreateAttributeDefinition(ctxGo, def)
+}
+
+// GetAttributeDefinitions lista as definições de atributos.
+func (s *Service) GetAttributeDefinitions(ctx domain.Context) ([]domain.AttributeDefinition, error) {
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background()", nil)
+	}
+	return s.repo.FindAttributeDefinitions(ctxGo)
+}
+
+// GetAttributeDefinitionByID busca uma definição de atributo.
+func (s *Service) GetAttributeDefinitionByID(ctx domain.Context, id string) (domain.AttributeDefinition, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return domain.AttributeDefinition{}, apperror.NewValidationError("O ID do atributo deve ser um UUID válido.")
+	}
+
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background()", nil)
+	}
+	return s.repo.FindAttributeDefinitionByID(ctxGo, id)
+}
+
+// UpdateAttributeDefinition substitui o nome e os valores permitidos de uma definição.
+// Variantes existentes não são alteradas; os novos valores valem para as próximas combinações.
+func (s *Service) UpdateAttributeDefinition(ctx domain.Context, def domain.AttributeDefinition) (domain.AttributeDefinition, error) {
+	if _, err := uuid.Parse(def.ID); err != nil {
+		return domain.AttributeDefinition{}, apperror.NewValidationError("O ID do atributo deve ser um UUID válido.")
+	}
+	if err := normalizeAttributeDefinition(&def); err != nil {
+		return domain.AttributeDefinition{}, err
+	}
+
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background()", nil)
+	}
+	return s.repo.UpdateAttributeDefinition(ctxGo, def)
+}
+
+// DeleteAttributeDefinition remove uma definição de atributo.
+func (s *Service) DeleteAttributeDefinition(ctx domain.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return apperror.NewValidationError("O ID do atributo deve ser um UUID válido.")
+	}
+
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background()", nil)
+	}
+	return s.repo.DeleteAttributeDefinition(ctxGo, id)
+}
+
+// normalizeAttributeDefinition remove espaços e valida nome e valores (não vazios e sem repetição).
+func normalizeAttributeDefinition(def *domain.AttributeDefinition) error {
+	def.Name = strings.TrimSpace(def.Name)
+	if def.Name == "" || len(def.Name) > 50 {
+		return apperror.NewValidationError("O nome do atributo deve ter entre 1 e 50 caracteres.")
+	}
+	if len(def.AllowedValues) == 0 || len(def.AllowedValues) > maxAttributeValues {
+		return apperror.NewValidationError(fmt.Sprintf("O atributo deve ter entre 1 e %d valores permitidos.", maxAttributeValues))
+	}
+
+	seen := make(map[string]bool, len(def.AllowedValues))
+	for i, value := range def.AllowedValues {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return apperror.NewValidationError(fmt.Sprintf("O valor %d do atributo '%s' está vazio.", i+1, def.Name))
+		}
+		if seen[strings.ToLower(value)] {
+			return apperror.NewValidationError(fmt.Sprintf("O valor '%s' está repetido no atributo '%s'.", value, def.Name))
+		}
+		seen[strings.ToLower(value)] = true
+		def.AllowedValues[i] = value
+	}
+	return nil
+}
+
+// --- Combinações de atributos das variantes ---
+
+// attributeDefinitions carrega as definições de atributos indexadas pelo nome em minúsculas.
+func (s *Service) attributeDefinitions(ctx context.Context) (map[string]domain.AttributeDefinition, error) {
+	defs, err := s.repo.FindAttributeDefinitions(ctx)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao carregar definições de atributos.", err)
+		return nil, err
+	}
+	byName := make(map[string]domain.AttributeDefinition, len(defs))
+	for _, def := range defs {
+		byName[strings.ToLower(def.Name)] = def
+	}
+	return byName, nil
+}
+
+// normalizeVariants converte as variantes para o modelo de combinações de atributos
+// (ver domain.Variant.NormalizeAttributes).
+func normalizeVariants(variants []domain.Variant) {
+	for i := range variants {
+		variants[i].NormalizeAttributes()
+	}
+}
+
+// applyAttributeDefinitions confere as combinações com as definições de atributos: atributos
+// definidos passam a usar o nome e o valor como cadastrados e só aceitam os valores permitidos;
+// atributos sem definição são aceitos livremente. As variantes devem estar normalizadas.
+func applyAttributeDefinitions(variants []domain.Variant, defs map[string]domain.AttributeDefinition) error {
+	for i := range variants {
+		canonical := make(map[string]string, len(variants[i].Attributes))
+		for name, value := range variants[i].Attributes {
+			if def, ok := defs[strings.ToLower(name)]; ok {
+				allowed, ok := def.CanonicalValue(value)
+				if !ok {
+					return apperror.NewValidationError(fmt.Sprintf("O valor '%s' não é permitido para o atributo '%s' (variação %d). Valores permitidos: %s.",
+						value, def.Name, i+1, strings.Join(def.AllowedValues, ", ")))
+				}
+				name, value = def.Name, allowed
+			}
+			if _, repeated := canonical[name]; repeated {
+				return apperror.NewValidationError(fmt.Sprintf("O atributo '%s' está repetido na variação %d.", name, i+1))
+			}
+			canonical[name] = value
+		}
+		variants[i].Attributes = canonical
+		variants[i].NormalizeAttributes()
+	}
+	return nil
+}
+
+// --- Gerador de variantes ---
+
+// GenerateVariants cria as variantes que faltam no produto para o produto cartesiano dos valores
+// escolhidos de cada atributo. Todos os atributos precisam estar definidos; combinações já
+// existentes são mantidas. As novas variantes recebem um código de barras interno
+// "<SKU>-<sequência>", que pode ser complementado com GTINs em /v1/barcodes.
+func (s *Service) GenerateVariants(ctx domain.Context, productID string, req domain.VariantGenerationRequest) (domain.VariantGenerationResult, error) {
+	s.logger.WithContext(ctx).Debug("Iniciando geração de variantes no serviço.", map[string]interface{}{"product_id": productID})
+
+	if _, err := uuid.Parse(productID); err != nil {
+		return domain.VariantGenerationResult{}, apperror.NewValidationError("O ID do produto deve ser um UUID válido.")
+	}
+	if req.PriceDiff < 0 {
+		return domain.VariantGenerationResult{}, apperror.NewValidationError("A diferença de preço não pode ser negativa.")
+	}
+
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background() para GenerateVariants", nil)
+	}
+
+	defs, err := s.attributeDefinitions(ctxGo)
+	if err != nil {
+		return domain.VariantGenerationResult{}, err
+	}
+	axes, err := resolveGenerationAxes(req.Attributes, defs)
+	if err != nil {
+		return domain.VariantGenerationResult{}, err
+	}
+	combinations, err := domain.AttributeCombinations(axes)
+	if err != nil {
+		return domain.VariantGenerationResult{}, apperror.NewValidationError(fmt.Sprintf("Não foi possível gerar as variantes: %s.", err.Error()))
+	}
+
+	product, err := s.repo.FindByID(ctxGo, productID)
+	if err != nil {
+		var notFound *apperror.NotFoundError
+		if errors.As(err, &notFound) {
+			return domain.VariantGenerationResult{}, apperror.NewNotFoundError(fmt.Sprintf("Produto com ID %s não foi encontrado.", productID))
+		}
+		return domain.VariantGenerationResult{}, err
+	}
+	normalizeVariants(product.Variants)
+
+	existing := make(map[string]bool, len(product.Variants))
+	barcodes := make(map[string]bool, len(product.Variants))
+	for _, v := range product.Variants {
+		existing[v.CombinationKey()] = true
+		barcodes[v.Barcode] = true
+	}
+
+	result := domain.VariantGenerationResult{Created: []domain.Variant{}}
+	sequence := len(product.Variants)
+	for _, combination := range combinations {
+		variant := domain.Variant{ID: uuid.New().String(), ProductID: product.ID, Attributes: combination, PriceDiff: req.PriceDiff}
+		variant.NormalizeAttributes()
+		if existing[variant.CombinationKey()] {
+			result.Skipped++
+			continue
+		}
+		for variant.Barcode == "" || barcodes[variant.Barcode] {
+			sequence++
+			variant.Barcode = fmt.Sprintf("%s-%03d", product.SKU, sequence)
+		}
+		barcodes[variant.Barcode] = true
+		result.Created = append(result.Created, variant)
+	}
+
+	if len(result.Created) == 0 {
+		s.logger.WithContext(ctx).Info("Todas as combinações já existem; nenhuma variante gerada.", map[string]interface{}{"product_id": productID})
+		result.Product = product
+		return result, nil
+	}
+
+	product.Variants = append(product.Variants, result.Created...)
+	product.UpdatedAt = time.Now().UTC()
+	if err := s.validateProduct(product); err != nil {
+		return domain.VariantGenerationResult{}, err
+	}
+
+	saved, _, err := s.repo.UpsertBySKU(ctxGo, product)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao gravar variantes geradas.", err)
+		return domain.VariantGenerationResult{}, err
+	}
+	s.recordVersion(ctxGo, saved, domain.ProductChangeVariants, nil)
+
+	s.logger.WithContext(ctx).Info("Variantes geradas com sucesso.", map[string]interface{}{"product_id": productID, "created": len(result.Created), "skipped": result.Skipped})
+	result.Product = saved
+	return result, nil
+}
+
+// resolveGenerationAxes valida os atributos pedidos ao gerador contra as definições, usando os
+// nomes e valores cadastrados. Uma lista vazia de valores seleciona todos os valores permitidos.
+func resolveGenerationAxes(requested map[string][]string, defs map[string]domain.AttributeDefinition) (map[string][]string, error) {
+	if len(requested) == 0 {
+		return nil, apperror.NewValidationError("Informe ao menos um atributo em 'attributes'.")
+	}
+
+	axes := make(map[string][]string, len(requested))
+	for name, values := range requested {
+		def, ok := defs[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, apperror.NewValidationError(fmt.Sprintf("O atributo '%s' não está definido. Cadastre-o em /v1/attributes.", name))
+		}
+		if _, repeated := axes[def.Name]; repeated {
+			return nil, apperror.NewValidationError(fmt.Sprintf("O atributo '%s' está repetido.", def.Name))
+		}
+		if len(values) == 0 {
+			axes[def.Name] = def.AllowedValues
+			continue
+		}
+
+		chosen := make([]string, 0, len(values))
+		seen := make(map[string]bool, len(values))
+		for _, value := range values {
+			allowed, ok := def.CanonicalValue(strings.TrimSpace(value))
+			if !ok {
+				return nil, apperror.NewValidationError(fmt.Sprintf("O valor '%s' não é permitido para o atributo '%s'. Valores permitidos: %s.",
+					value, def.Name, strings.Join(def.AllowedValues, ", ")))
+			}
+			if !seen[allowed] {
+				seen[allowed] = true
+				chosen = append(chosen, allowed)
+			}
+		}
+		axes[def.Name] = chosen
+	}
+	return axes, nil
+}
@@ -0,0 +1,166 @@
+package productservice_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/logger"
+	"gostock/internal/service/productservice"
+)
+
+func attributeDefinitionsFixture() []domain.AttributeDefinition {
+	return []domain.AttributeDefinition{
+		{ID: uuid.New().String(), Name: "Cor", AllowedValues: []string{"Azul", "Verde", "Preto"}},
+		{ID: uuid.New().String(), Name: "Tamanho", AllowedValues: []string{"P", "M", "G"}},
+	}
+}
+
+// TestAttributeCombinations testa a ordem determinística do produto cartesiano e o limite de combinações.
+func TestAttributeCombinations(t *testing.T) {
+	combinations, err := domain.AttributeCombinations(map[string][]string{
+		"Tamanho": {"P", "M"},
+		"Cor":     {"Azul", "Verde"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]string{
+		{"Cor": "Azul", "Tamanho": "P"},
+		{"Cor": "Azul", "Tamanho": "M"},
+		{"Cor": "Verde", "Tamanho": "P"},
+		{"Cor": "Verde", "Tamanho": "M"},
+	}, combinations)
+
+	values := make([]string, 30)
+	for i := range values {
+		values[i] = uuid.New().String()
+	}
+	_, err = domain.AttributeCombinations(map[string][]string{"A": values, "B": values})
+	assert.Error(t, err)
+}
+
+// TestCreateProduct_Success_CanonicalizesAttributes testa a adoção do nome e do valor cadastrados
+// e a derivação do rótulo legado para variantes com vários atributos.
+func TestCreateProduct_Success_CanonicalizesAttributes(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+
+	mockRepo.On("FindAttributeDefinitions", mock.Anything).Return(attributeDefinitionsFixture(), nil)
+	var created domain.Product
+	mockRepo.On("Save", mock.Anything, mock.AnythingOfType("domain.Product")).
+		Run(func(args mock.Arguments) { created = args.Get(1).(domain.Product) }).
+		Return(domain.Product{}, nil)
+
+	_, err := svc.CreateProduct(context.Background(),
+		domain.Product{SKU: "CAM-001", Name: "Camiseta", Price: 49.9},
+		[]domain.Variant{{Attributes: map[string]string{" cor": "azul", "TAMANHO": "m "}, Barcode: "7890000000017"}})
+
+	assert.NoError(t, err)
+	if assert.Len(t, created.Variants, 1) {
+		v := created.Variants[0]
+		assert.Equal(t, map[string]string{"Cor": "Azul", "Tamanho": "M"}, v.Attributes)
+		assert.Equal(t, "Cor / Tamanho", v.Attribute)
+		assert.Equal(t, "Azul / M", v.Value)
+	}
+}
+
+// TestCreateProduct_Fail_AttributeValueNotAllowed testa a rejeição de valor fora da definição do atributo.
+func TestCreateProduct_Fail_AttributeValueNotAllowed(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+
+	mockRepo.On("FindAttributeDefinitions", mock.Anything).Return(attributeDefinitionsFixture(), nil)
+
+	_, err := svc.CreateProduct(context.Background(),
+		domain.Product{SKU: "CAM-001", Name: "Camiseta", Price: 49.9},
+		[]domain.Variant{{Attributes: map[string]string{"Tamanho": "GG"}, Barcode: "7890000000017"}})
+
+	assert.IsType(t, &apperror.ValidationError{}, err)
+	assert.Contains(t, err.Error(), "GG")
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+// TestCreateProduct_Fail_DuplicateCombination testa a rejeição da mesma combinação em duas variações,
+// sem diferenciar maiúsculas.
+func TestCreateProduct_Fail_DuplicateCombination(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+
+	_, err := svc.CreateProduct(context.Background(),
+		domain.Product{SKU: "CAM-001", Name: "Camiseta", Price: 49.9},
+		[]domain.Variant{
+			{Attributes: map[string]string{"Cor": "Azul", "Tamanho": "M"}, Barcode: "7890000000017"},
+			{Attributes: map[string]string{"cor": "AZUL", "tamanho": "m"}, Barcode: "7890000000024"},
+		})
+
+	assert.IsType(t, &apperror.ValidationError{}, err)
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+// TestGenerateVariants_Success_SkipsExisting testa a criação apenas das combinações que faltam,
+// com códigos internos sequenciais.
+func TestGenerateVariants_Success_SkipsExisting(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+
+	productID := uuid.New().String()
+	product := domain.Product{ID: productID, SKU: "CAM-001", Name: "Camiseta", Price: 49.9, Variants: []domain.Variant{
+		{ID: uuid.New().String(), ProductID: productID, Attributes: map[string]string{"Cor": "Azul", "Tamanho": "P"}, Barcode: "7890000000017"},
+	}}
+
+	mockRepo.On("FindAttributeDefinitions", mock.Anything).Return(attributeDefinitionsFixture(), nil)
+	mockRepo.On("FindByID", mock.Anything, productID).Return(product, nil)
+	var saved domain.Product
+	mockRepo.On("UpsertBySKU", mock.Anything, mock.AnythingOfType("domain.Product")).
+		Run(func(args mock.Arguments) { saved = args.Get(1).(domain.Product) }).
+		Return(domain.Product{}, false, nil)
+
+	result, err := svc.GenerateVariants(context.Background(), productID, domain.VariantGenerationRequest{
+		Attributes: map[string][]string{"cor": {"azul"}, "Tamanho": {}},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Skipped)
+	if assert.Len(t, result.Created, 2) {
+		assert.Equal(t, map[string]string{"Cor": "Azul", "Tamanho": "M"}, result.Created[0].Attributes)
+		assert.Equal(t, "CAM-001-002", result.Created[0].Barcode)
+		assert.Equal(t, map[string]string{"Cor": "Azul", "Tamanho": "G"}, result.Created[1].Attributes)
+		assert.Equal(t, "CAM-001-003", result.Created[1].Barcode)
+	}
+	assert.Len(t, saved.Variants, 3)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGenerateVariants_Fail_UndefinedAttribute testa a exigência de definição para todo eixo gerado.
+func TestGenerateVariants_Fail_UndefinedAttribute(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+
+	mockRepo.On("FindAttributeDefinitions", mock.Anything).Return(attributeDefinitionsFixture(), nil)
+
+	_, err := svc.GenerateVariants(context.Background(), uuid.New().String(), domain.VariantGenerationRequest{
+		Attributes: map[string][]string{"Material": {"Algodão"}},
+	})
+
+	assert.IsType(t, &apperror.ValidationError{}, err)
+	mockRepo.AssertNotCalled(t, "FindByID", mock.Anything, mock.Anything)
+}
+
+// TestGetProducts_Success_AttributeFilters testa a conversão dos parâmetros attr.<Nome> em filtro de atributos.
+func TestGetProducts_Success_AttributeFilters(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+
+	expectedFilter := domain.ProductFilter{Page: 1, Limit: 10, Attributes: map[string]string{"Cor": "Azul", "Tamanho": "M"}}
+	mockRepo.On("FindAll", mock.Anything, expectedFilter).Return([]domain.Product{}, nil)
+
+	_, err := svc.GetProducts(context.Background(), 1, 10, map[string]string{"attr.Cor": "Azul", "attr.Tamanho": " M "})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
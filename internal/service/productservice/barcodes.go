@@ -0,0 +1,95 @@
+package productservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+)
+
+// LookupBarcode resolve um código de barras (principal ou adicional) para o produto e a variante.
+func (s *Service) LookupBarcode(ctx domain.Context, code string) (domain.BarcodeLookup, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return domain.BarcodeLookup{}, apperror.NewValidationError("O código de barras é obrigatório.")
+	}
+
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background()", nil)
+	}
+
+	lookup, err := s.repo.FindVariantByBarcode(ctxGo, code)
+	if err != nil {
+		return domain.BarcodeLookup{}, err
+	}
+
+	lookup.Product, err = s.repo.FindByID(ctxGo, lookup.Variant.ProductID)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao buscar produto da variante do código de barras.", err)
+		return domain.BarcodeLookup{}, err
+	}
+
+	s.logger.WithContext(ctx).Info("Código de barras resolvido.", map[string]interface{}{"barcode": code, "variant_id": lookup.Variant.ID, "product_id": lookup.Product.ID})
+	return lookup, nil
+}
+
+// AddVariantBarcode cadastra um código de barras adicional para a variante, opcionalmente
+// associado a uma unidade de medida (ex.: o código da caixa mapeado para "cx").
+func (s *Service) AddVariantBarcode(ctx domain.Context, barcode domain.VariantBarcode) (domain.VariantBarcode, error) {
+	barcode.Barcode = strings.TrimSpace(barcode.Barcode)
+	barcode.Unit = strings.ToLower(strings.TrimSpace(barcode.Unit))
+
+	if _, err := uuid.Parse(barcode.VariantID); err != nil {
+		return domain.VariantBarcode{}, apperror.NewValidationError("O 'variant_id' deve ser um UUID válido.")
+	}
+	if err := domain.ValidateBarcode(barcode.Barcode); err != nil {
+		return domain.VariantBarcode{}, apperror.NewValidationError(fmt.Sprintf("Código de barras inválido: %s.", err.Error()))
+	}
+	if len(barcode.Unit) > 20 {
+		return domain.VariantBarcode{}, apperror.NewValidationError("A unidade deve ter no máximo 20 caracteres.")
+	}
+
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background()", nil)
+	}
+
+	return s.repo.InsertVariantBarcode(ctxGo, barcode)
+}
+
+// GetVariantBarcodes lista os códigos de barras adicionais da variante.
+func (s *Service) GetVariantBarcodes(ctx domain.Context, variantID string) ([]domain.VariantBarcode, error) {
+	if _, err := uuid.Parse(variantID); err != nil {
+		return nil, apperror.NewValidationError("O 'variant_id' deve ser um UUID válido.")
+	}
+
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background()", nil)
+	}
+
+	return s.repo.FindVariantBarcodes(ctxGo, variantID)
+}
+
+// RemoveVariantBarcode remove um código de barras adicional.
+func (s *Service) RemoveVariantBarcode(ctx domain.Context, code string) error {
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background()", nil)
+	}
+
+	if err := s.repo.DeleteVariantBarcode(ctxGo, strings.TrimSpace(code)); err != nil {
+		return err
+	}
+	s.logger.WithContext(ctx).Info("Código de barras adicional removido.", map[string]interface{}{"barcode": code})
+	return nil
+}
@@ -0,0 +1,123 @@
+package productservice_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/logger"
+	"gostock/internal/service/productservice"
+)
+
+// TestCreateProduct_Fail_InvalidGTINCheckDigit testa a rejeição de EAN-13 com dígito verificador errado.
+func TestCreateProduct_Fail_InvalidGTINCheckDigit(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+
+	_, err := svc.CreateProduct(context.Background(),
+		domain.Product{SKU: "CAM-001", Name: "Camiseta", Price: 49.9},
+		[]domain.Variant{{Attribute: "Cor", Value: "Azul", Barcode: "7891234567890"}})
+
+	assert.Error(t, err)
+	assert.IsType(t, &apperror.ValidationError{}, err)
+	assert.Contains(t, err.Error(), "esperado 5")
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+// TestCreateProduct_Fail_DuplicateBarcodeInProduct testa a rejeição do mesmo código em duas variações.
+func TestCreateProduct_Fail_DuplicateBarcodeInProduct(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+
+	_, err := svc.CreateProduct(context.Background(),
+		domain.Product{SKU: "CAM-001", Name: "Camiseta", Price: 49.9},
+		[]domain.Variant{
+			{Attribute: "Cor", Value: "Azul", Barcode: "7890000000017"},
+			{Attribute: "Cor", Value: "Verde", Barcode: "7890000000017"},
+		})
+
+	assert.Error(t, err)
+	assert.IsType(t, &apperror.ValidationError{}, err)
+}
+
+// TestValidateBarcode testa a verificação dos dígitos de cada simbologia GTIN e a aceitação de códigos internos.
+func TestValidateBarcode(t *testing.T) {
+	valid := []string{"96385074", "036000291452", "7891234567895", "17891234567892", "INT-000123", "12345"}
+	for _, code := range valid {
+		assert.NoError(t, domain.ValidateBarcode(code), code)
+	}
+
+	invalid := []string{"", "96385075", "036000291453", "7891234567890", "17891234567890"}
+	for _, code := range invalid {
+		assert.Error(t, domain.ValidateBarcode(code), code)
+	}
+}
+
+// TestLookupBarcode_Success_CaseBarcode testa a resolução de um código adicional (caixa) para o produto.
+func TestLookupBarcode_Success_CaseBarcode(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+
+	productID := uuid.New().String()
+	variant := domain.Variant{ID: uuid.New().String(), ProductID: productID, Attribute: "Cor", Value: "Azul", Barcode: "7890000000017"}
+	product := domain.Product{ID: productID, SKU: "CAM-001", Name: "Camiseta", Variants: []domain.Variant{variant}}
+
+	mockRepo.On("FindVariantByBarcode", mock.Anything, "17890000000014").
+		Return(domain.BarcodeLookup{Barcode: "17890000000014", Unit: "cx", Variant: variant}, nil)
+	mockRepo.On("FindByID", mock.Anything, productID).Return(product, nil)
+
+	lookup, err := svc.LookupBarcode(context.Background(), " 17890000000014 ")
+
+	assert.NoError(t, err)
+	assert.False(t, lookup.Primary)
+	assert.Equal(t, "cx", lookup.Unit)
+	assert.Equal(t, variant, lookup.Variant)
+	assert.Equal(t, product, lookup.Product)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestLookupBarcode_Fail_NotFound testa a propagação do 404 para códigos não cadastrados.
+func TestLookupBarcode_Fail_NotFound(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+
+	mockRepo.On("FindVariantByBarcode", mock.Anything, "0000").
+		Return(domain.BarcodeLookup{}, apperror.NewNotFoundError("Nenhuma variante possui o código de barras '0000'."))
+
+	_, err := svc.LookupBarcode(context.Background(), "0000")
+
+	assert.IsType(t, &apperror.NotFoundError{}, err)
+	mockRepo.AssertNotCalled(t, "FindByID", mock.Anything, mock.Anything)
+}
+
+// TestAddVariantBarcode_Success_Normalizes testa o cadastro de um código adicional com unidade normalizada.
+func TestAddVariantBarcode_Success_Normalizes(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+
+	variantID := uuid.New().String()
+	expected := domain.VariantBarcode{Barcode: "17890000000014", VariantID: variantID, Unit: "cx"}
+	mockRepo.On("InsertVariantBarcode", mock.Anything, expected).Return(expected, nil)
+
+	created, err := svc.AddVariantBarcode(context.Background(), domain.VariantBarcode{Barcode: "17890000000014 ", VariantID: variantID, Unit: " CX"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, created)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestAddVariantBarcode_Fail_InvalidCheckDigit testa a rejeição de GTIN-14 inválido antes de acessar o repositório.
+func TestAddVariantBarcode_Fail_InvalidCheckDigit(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+
+	_, err := svc.AddVariantBarcode(context.Background(), domain.VariantBarcode{Barcode: "17890000000015", VariantID: uuid.New().String()})
+
+	assert.IsType(t, &apperror.ValidationError{}, err)
+	mockRepo.AssertNotCalled(t, "InsertVariantBarcode", mock.Anything, mock.Anything)
+}
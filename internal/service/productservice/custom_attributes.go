@@ -0,0 +1,174 @@
+package productservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+)
+
+// --- Esquemas de atributos personalizados por categoria ---
+
+// SaveCategorySchema cria ou substitui o esquema de atributos personalizados de uma categoria.
+// Produtos já gravados não são revalidados; o esquema vale para as próximas gravações.
+func (s *Service) SaveCategorySchema(ctx domain.Context, schema domain.CategorySchema) (domain.CategorySchema, error) {
+	if err := schema.Validate(); err != nil {
+		return domain.CategorySchema{}, apperror.NewValidationError(fmt.Sprintf("Esquema de categoria inválido: %s.", err.Error()))
+	}
+	if schema.Fields == nil {
+		schema.Fields = []domain.CustomAttributeField{}
+	}
+
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background()", nil)
+	}
+	return s.repo.UpsertCategorySchema(ctxGo, schema)
+}
+
+// GetCategorySchemas lista os esquemas de todas as categorias.
+func (s *Service) GetCategorySchemas(ctx domain.Context) ([]domain.CategorySchema, error) {
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background()", nil)
+	}
+	return s.repo.FindCategorySchemas(ctxGo)
+}
+
+// GetCategorySchema busca o esquema de uma categoria.
+func (s *Service) GetCategorySchema(ctx domain.Context, category string) (domain.CategorySchema, error) {
+	category = strings.TrimSpace(category)
+	if category == "" {
+		return domain.CategorySchema{}, apperror.NewValidationError("A categoria é obrigatória.")
+	}
+
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background()", nil)
+	}
+	return s.repo.FindCategorySchema(ctxGo, category)
+}
+
+// DeleteCategorySchema remove o esquema de uma categoria.
+func (s *Service) DeleteCategorySchema(ctx domain.Context, category string) error {
+	category = strings.TrimSpace(category)
+	if category == "" {
+		return apperror.NewValidationError("A categoria é obrigatória.")
+	}
+
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background()", nil)
+	}
+	return s.repo.DeleteCategorySchema(ctxGo, category)
+}
+
+// --- Atributos personalizados do produto ---
+
+// UpdateProductCustomAttributes substitui a categoria e os atributos personalizados de um produto,
+// validando-os com o esquema da categoria. A alteração é registrada no histórico.
+func (s *Service) UpdateProductCustomAttributes(ctx domain.Context, productID, category string, attrs map[string]interface{}) (domain.Product, error) {
+	if _, err := uuid.Parse(productID); err != nil {
+		return domain.Product{}, apperror.NewValidationError("O ID do produto deve ser um UUID válido.")
+	}
+
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background() para UpdateProductCustomAttributes", nil)
+	}
+
+	product, err := s.repo.FindByID(ctxGo, productID)
+	if err != nil {
+		var notFound *apperror.NotFoundError
+		if errors.As(err, &notFound) {
+			return domain.Product{}, apperror.NewNotFoundError(fmt.Sprintf("Produto com ID %s não foi encontrado.", productID))
+		}
+		return domain.Product{}, err
+	}
+
+	product.Category = category
+	product.CustomAttributes = attrs
+	if err := s.applyCategorySchema(ctxGo, &product, nil); err != nil {
+		return domain.Product{}, err
+	}
+	product.UpdatedAt = time.Now().UTC()
+
+	saved, err := s.repo.UpdateProductCustomAttributes(ctxGo, product)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao gravar atributos personalizados do produto.", err)
+		return domain.Product{}, err
+	}
+	s.recordVersion(ctxGo, saved, domain.ProductChangeCustomAttributes, nil)
+
+	s.logger.WithContext(ctx).Info("Atributos personalizados do produto atualizados.", map[string]interface{}{"product_id": productID, "category": saved.Category})
+	return saved, nil
+}
+
+// applyCategorySchema normaliza a categoria e valida os atributos personalizados do produto com o
+// esquema da categoria, usando a grafia cadastrada da categoria e os valores convertidos para o
+// tipo de cada campo. Atributos personalizados exigem uma categoria com esquema; categorias sem
+// esquema são aceitas apenas sem atributos. schemas, se não for nil, guarda os esquemas já
+// carregados (ex.: durante uma importação), indexados pela categoria em minúsculas.
+func (s *Service) applyCategorySchema(ctx context.Context, product *domain.Product, schemas map[string]*domain.CategorySchema) error {
+	product.Category = strings.TrimSpace(product.Category)
+	if len(product.CustomAttributes) == 0 {
+		product.CustomAttributes = nil
+	}
+	if product.Category == "" {
+		if product.CustomAttributes != nil {
+			return apperror.NewValidationError("Atributos personalizados exigem uma categoria ('category').")
+		}
+		return nil
+	}
+	if len(product.Category) > 100 {
+		return apperror.NewValidationError("A categoria deve ter no máximo 100 caracteres.")
+	}
+
+	key := strings.ToLower(product.Category)
+	schema, cached := schemas[key]
+	if !cached {
+		found, err := s.repo.FindCategorySchema(ctx, product.Category)
+		var notFound *apperror.NotFoundError
+		switch {
+		case err == nil:
+			schema = &found
+		case errors.As(err, &notFound):
+			schema = nil
+		default:
+			s.logger.WithContext(ctx).Error("Falha ao carregar esquema da categoria.", err)
+			return err
+		}
+		if schemas != nil {
+			schemas[key] = schema
+		}
+	}
+
+	if schema == nil {
+		if product.CustomAttributes != nil {
+			return apperror.NewValidationError(fmt.Sprintf("A categoria '%s' não possui esquema de atributos; cadastre-o em /v1/categories/%s/schema.", product.Category, product.Category))
+		}
+		return nil
+	}
+
+	attrs, err := schema.ValidateCustomAttributes(product.CustomAttributes)
+	if err != nil {
+		return apperror.NewValidationError(fmt.Sprintf("Atributos personalizados inválidos: %s.", err.Error()))
+	}
+	product.Category = schema.Category
+	product.CustomAttributes = attrs
+	if len(attrs) == 0 {
+		product.CustomAttributes = nil
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package productservice_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/tenant"
+	"gostock/internal/service/productservice"
+)
+
+// MockMediaRepository é um ProductRepository que também implementa as mídias de produto.
+type MockMediaRepository struct {
+	MockProductRepository
+}
+
+func (m *MockMediaRepository) InsertProductMedia(ctx context.Context, media domain.ProductMedia) (domain.ProductMedia, error) {
+	args := m.Called(ctx, media)
+	return args.Get(0).(domain.ProductMedia), args.Error(1)
+}
+
+func (m *MockMediaRepository) FindProductMedia(ctx context.Context, productID string) ([]domain.ProductMedia, error) {
+	args := m.Called(ctx, productID)
+	return args.Get(0).([]domain.ProductMedia), args.Error(1)
+}
+
+func (m *MockMediaRepository) FindProductMediaByID(ctx context.Context, id string) (domain.ProductMedia, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.ProductMedia), args.Error(1)
+}
+
+func (m *MockMediaRepository) UpdateProductMedia(ctx context.Context, media domain.ProductMedia) (domain.ProductMedia, error) {
+	args := m.Called(ctx, media)
+	return args.Get(0).(domain.ProductMedia), args.Error(1)
+}
+
+func (m *MockMediaRepository) DeleteProductMedia(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// MockMediaStorage simula o armazenamento local de arquivos.
+type MockMediaStorage struct {
+	mock.Mock
+}
+
+func (m *MockMediaStorage) Save(name string, src io.Reader) (string, error) {
+	data, _ := io.ReadAll(src)
+	args := m.Called(name, data)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockMediaStorage) Delete(name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func apparelSchema() domain.CategorySchema {
+	return domain.CategorySchema{Category: "Vestuário", Fields: []domain.CustomAttributeField{
+		{Name: "material", Type: domain.CustomAttributeEnum, Required: true, Values: []string{"Algodão", "Poliéster"}},
+		{Name: "peso_kg", Type: domain.CustomAttributeNumber},
+		{Name: "lavavel", Type: domain.CustomAttributeBool},
+		{Name: "origem", Type: domain.CustomAttributeString},
+	}}
+}
+
+// TestValidateCustomAttributes testa a conversão de tipos, os campos obrigatórios e os campos desconhecidos.
+func TestValidateCustomAttributes(t *testing.T) {
+	schema := apparelSchema()
+
+	attrs, err := schema.ValidateCustomAttributes(map[string]interface{}{"Material": "algodão", "peso_kg": "0.25", "lavavel": true, "origem": " Brasil "})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"material": "Algodão", "peso_kg": 0.25, "lavavel": true, "origem": "Brasil"}, attrs)
+
+	_, err = schema.ValidateCustomAttributes(map[string]interface{}{"peso_kg": 0.2})
+	assert.ErrorContains(t, err, "obrigatório")
+
+	_, err = schema.ValidateCustomAttributes(map[string]interface{}{"material": "Seda"})
+	assert.Error(t, err)
+
+	_, err = schema.ValidateCustomAttributes(map[string]interface{}{"material": "Algodão", "peso_kg": "leve"})
+	assert.ErrorContains(t, err, "numérico")
+
+	_, err = schema.ValidateCustomAttributes(map[string]interface{}{"material": "Algodão", "cor": "Azul"})
+	assert.ErrorContains(t, err, "não existe")
+}
+
+// TestSaveCategorySchema_Fail_InvalidField testa a rejeição de tipos desconhecidos e enums sem valores.
+func TestSaveCategorySchema_Fail_InvalidField(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+
+	for _, field := range []domain.CustomAttributeField{
+		{Name: "peso", Type: "decimal"},
+		{Name: "material", Type: domain.CustomAttributeEnum},
+		{Name: "origem", Type: domain.CustomAttributeString, Values: []string{"Brasil"}},
+	} {
+		_, err := svc.SaveCategorySchema(context.Background(), domain.CategorySchema{Category: "Vestuário", Fields: []domain.CustomAttributeField{field}})
+		assert.IsType(t, &apperror.ValidationError{}, err, field.Name)
+	}
+	mockRepo.AssertNotCalled(t, "UpsertCategorySchema", mock.Anything, mock.Anything)
+}
+
+// TestCreateProduct_Success_CustomAttributes testa a validação das especificações pelo esquema da categoria.
+func TestCreateProduct_Success_CustomAttributes(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+
+	mockRepo.On("FindAttributeDefinitions", mock.Anything).Return([]domain.AttributeDefinition{}, nil)
+	mockRepo.On("FindCategorySchema", mock.Anything, "vestuário").Return(apparelSchema(), nil)
+	var saved domain.Product
+	mockRepo.On("Save", mock.Anything, mock.AnythingOfType("domain.Product")).
+		Run(func(args mock.Arguments) { saved = args.Get(1).(domain.Product) }).
+		Return(domain.Product{}, nil)
+
+	_, err := svc.CreateProduct(context.Background(),
+		domain.Product{SKU: "CAM-001", Name: "Camiseta", Price: 49.9, Category: "vestuário",
+			CustomAttributes: map[string]interface{}{"material": "POLIÉSTER", "peso_kg": 0.2}},
+		[]domain.Variant{{Attribute: "Cor", Value: "Azul", Barcode: "7890000000017"}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Vestuário", saved.Category)
+	assert.Equal(t, map[string]interface{}{"material": "Poliéster", "peso_kg": 0.2}, saved.CustomAttributes)
+}
+
+// TestCreateProduct_Fail_CustomAttributesWithoutSchema testa a exigência de esquema para categorias com especificações.
+func TestCreateProduct_Fail_CustomAttributesWithoutSchema(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+
+	mockRepo.On("FindAttributeDefinitions", mock.Anything).Return([]domain.AttributeDefinition{}, nil)
+	mockRepo.On("FindCategorySchema", mock.Anything, "Brinquedos").
+		Return(domain.CategorySchema{}, apperror.NewNotFoundError("A categoria 'Brinquedos' não possui esquema de atributos."))
+
+	_, err := svc.CreateProduct(context.Background(),
+		domain.Product{SKU: "BRQ-001", Name: "Pião", Price: 9.9, Category: "Brinquedos", CustomAttributes: map[string]interface{}{"idade": 3}},
+		[]domain.Variant{{Attribute: "Cor", Value: "Azul", Barcode: "7890000000017"}})
+
+	assert.IsType(t, &apperror.ValidationError{}, err)
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+// TestGetProducts_Success_CustomAttributeFilters testa a leitura dos filtros de categoria e spec.<campo>.
+func TestGetProducts_Success_CustomAttributeFilters(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+
+	expectedFilter := domain.ProductFilter{Page: 1, Limit: 10, Category: "Vestuário", CustomAttributes: map[string]string{"material": "Algodão"}}
+	mockRepo.On("FindAll", mock.Anything, expectedFilter).Return([]domain.Product{}, nil)
+
+	_, err := svc.GetProducts(context.Background(), 1, 10, map[string]string{"category": "Vestuário", "spec.material": "Algodão"})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestUploadProductMedia_Success testa a gravação do arquivo com extensão pelo tipo detectado.
+func TestUploadProductMedia_Success(t *testing.T) {
+	mockRepo := new(MockMediaRepository)
+	storage := new(MockMediaStorage)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+	svc.SetMediaStorage(storage)
+
+	productID := uuid.New().String()
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 32)...)
+
+	// Sem tenant no contexto, o arquivo vai para o diretório do tenant padrão
+	prefix := tenant.DefaultID + "/" + productID + "/"
+	storage.On("Save", mock.MatchedBy(func(name string) bool {
+		return len(name) > len(prefix) && name[:len(prefix)] == prefix && name[len(name)-4:] == ".png"
+	}), png).Return("/media/foto.png", nil)
+	mockRepo.On("InsertProductMedia", mock.Anything, mock.MatchedBy(func(m domain.ProductMedia) bool {
+		return m.ProductID == productID && m.URL == "/media/foto.png" && m.ContentType == "image/png" && m.AltText == "Frente"
+	})).Return(domain.ProductMedia{ID: uuid.New().String(), ProductID: productID, URL: "/media/foto.png"}, nil)
+
+	created, err := svc.UploadProductMedia(context.Background(), productID, domain.MediaUpload{Filename: "foto.png", AltText: " Frente "}, bytes.NewReader(png))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/media/foto.png", created.URL)
+	storage.AssertExpectations(t)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestUploadProductMedia_Fail_UnsupportedType testa a rejeição de arquivos que não são imagens.
+func TestUploadProductMedia_Fail_UnsupportedType(t *testing.T) {
+	mockRepo := new(MockMediaRepository)
+	storage := new(MockMediaStorage)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+	svc.SetMediaStorage(storage)
+
+	_, err := svc.UploadProductMedia(context.Background(), uuid.New().String(), domain.MediaUpload{Filename: "nota.txt"}, bytes.NewReader([]byte("texto simples")))
+
+	assert.IsType(t, &apperror.ValidationError{}, err)
+	storage.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+// TestAddProductMedia_Fail_VariantFromOtherProduct testa a associação apenas com variantes do próprio produto.
+func TestAddProductMedia_Fail_VariantFromOtherProduct(t *testing.T) {
+	mockRepo := new(MockMediaRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+
+	productID := uuid.New().String()
+	mockRepo.On("FindByID", mock.Anything, productID).
+		Return(domain.Product{ID: productID, Variants: []domain.Variant{{ID: uuid.New().String()}}}, nil)
+
+	_, err := svc.AddProductMedia(context.Background(), domain.ProductMedia{
+		ProductID: productID, URL: "https://cdn.example.com/a.jpg", VariantID: uuid.New().String(),
+	})
+
+	assert.IsType(t, &apperror.ValidationError{}, err)
+	mockRepo.AssertNotCalled(t, "InsertProductMedia", mock.Anything, mock.Anything)
+}
+
+// TestDeleteProductMedia_Success_RemovesFile testa a remoção do arquivo local junto com o registro.
+func TestDeleteProductMedia_Success_RemovesFile(t *testing.T) {
+	mockRepo := new(MockMediaRepository)
+	storage := new(MockMediaStorage)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+	svc.SetMediaStorage(storage)
+
+	productID, mediaID := uuid.New().String(), uuid.New().String()
+	mockRepo.On("FindProductMediaByID", mock.Anything, mediaID).
+		Return(domain.ProductMedia{ID: mediaID, ProductID: productID, Path: productID + "/" + mediaID + ".jpg"}, nil)
+	mockRepo.On("DeleteProductMedia", mock.Anything, mediaID).Return(nil)
+	storage.On("Delete", productID+"/"+mediaID+".jpg").Return(nil)
+
+	err := svc.DeleteProductMedia(context.Background(), productID, mediaID)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	storage.AssertExpectations(t)
+}
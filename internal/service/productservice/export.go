@@ -0,0 +1,43 @@
+package productservice
+
+import (
+	"context"
+	"errors"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+)
+
+// --- Implementação: ExportProducts ---
+
+// ExportProducts percorre o catálogo com os mesmos filtros da listagem (ver BuildProductFilter)
+// e entrega cada linha (produto + variante + estoque por armazém) para fn, que deve escrevê-la.
+// Nenhuma linha é acumulada em memória.
+func (s *Service) ExportProducts(ctx domain.Context, filters map[string]string, fn func(domain.ProductExportRow) error) error {
+	s.logger.WithContext(ctx).Debug("Iniciando exportação de produtos no serviço.", map[string]interface{}{"filters": filters})
+
+	productFilter, err := s.BuildProductFilter(filters)
+	if err != nil {
+		s.logger.WithContext(ctx).Warn("Parâmetros de exportação de produtos inválidos.", map[string]interface{}{"filters": filters, "error": err.Error()})
+		return err
+	}
+
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background() para ExportProducts", nil)
+	}
+
+	if err := s.repo.StreamExportRows(ctxGo, productFilter, fn); err != nil {
+		var appErr apperror.AppError
+		if errors.As(err, &appErr) {
+			s.logger.WithContext(ctx).Error("Falha ao exportar produtos no repositório.", err)
+			return err
+		}
+		// Erros não tipados vêm do escritor (fn), normalmente o cliente desconectando.
+		return apperror.NewInternalError("Exportação de produtos interrompida.", err)
+	}
+
+	s.logger.WithContext(ctx).Info("Exportação de produtos concluída.", nil)
+	return nil
+}
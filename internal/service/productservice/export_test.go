@@ -0,0 +1,52 @@
+package productservice_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/logger"
+	"gostock/internal/service/productservice"
+)
+
+// TestExportProducts_Success testa que os filtros da listagem são aplicados e as linhas entregues ao escritor.
+func TestExportProducts_Success(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+
+	rows := []domain.ProductExportRow{
+		{ProductID: "p1", SKU: "CAM-001", VariantID: "v1", Quantities: map[string]int{"w1": 4}, TotalQuantity: 4},
+		{ProductID: "p1", SKU: "CAM-001", VariantID: "v2", Quantities: map[string]int{}},
+	}
+	mockRepo.On("StreamExportRows", mock.Anything, mock.MatchedBy(func(f domain.ProductFilter) bool {
+		return f.SKU == "CAM-001" && f.SortBy == domain.ProductSortPrice && f.SortOrder == domain.SortDesc
+	})).Return(rows, nil)
+
+	var written []domain.ProductExportRow
+	err := svc.ExportProducts(context.Background(),
+		map[string]string{"sku": "CAM-001", "sort_by": "price", "sort_order": "desc"},
+		func(row domain.ProductExportRow) error {
+			written = append(written, row)
+			return nil
+		})
+
+	assert.NoError(t, err)
+	assert.Equal(t, rows, written)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestExportProducts_Fail_InvalidFilter testa que filtros inválidos são rejeitados antes de consultar o repositório.
+func TestExportProducts_Fail_InvalidFilter(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+
+	err := svc.ExportProducts(context.Background(), map[string]string{"min_price": "abc"}, func(domain.ProductExportRow) error { return nil })
+
+	assert.Error(t, err)
+	assert.IsType(t, &apperror.ValidationError{}, err)
+	mockRepo.AssertNotCalled(t, "StreamExportRows", mock.Anything, mock.Anything)
+}
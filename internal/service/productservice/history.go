@@ -0,0 +1,169 @@
+package productservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/middleware"
+)
+
+// HistoryRepository define as operações de histórico de versões de produtos.
+// É uma capacidade opcional do repositório: se o ProductRepository injetado também a
+// implementar, o serviço passa a registrar uma versão a cada alteração de produto.
+type HistoryRepository interface {
+	InsertProductVersion(ctx context.Context, version domain.ProductVersion) (domain.ProductVersion, error)
+	FindLatestProductVersion(ctx context.Context, productID string) (domain.ProductVersion, error)
+	FindProductVersion(ctx context.Context, productID string, version int) (domain.ProductVersion, error)
+	FindProductVersions(ctx context.Context, productID string, page, limit int) ([]domain.ProductVersion, error)
+	RestoreProduct(ctx context.Context, product domain.Product) (domain.Product, error)
+}
+
+// maxVersionAttempts limita as tentativas de gravar uma versão quando há gravações concorrentes.
+const maxVersionAttempts = 3
+
+// recordVersion grava uma nova versão do produto com os campos alterados em relação à última versão.
+// Falhas são apenas registradas em log: o histórico não deve desfazer uma alteração já gravada.
+func (s *Service) recordVersion(ctx context.Context, product domain.Product, changeType domain.ProductChangeType, revertedTo *int) {
+	if s.history == nil {
+		return
+	}
+
+	version := domain.ProductVersion{
+		ProductID:  product.ID,
+		ChangeType: changeType,
+		Snapshot:   product,
+		RevertedTo: revertedTo,
+	}
+	// O autor da alteração vem das claims do JWT anexadas ao contexto pelo middleware de autenticação.
+	if claims, ok := middleware.GetUserClaimsFromContext(ctx); ok {
+		version.ChangedBy = claims.UserID
+		version.ChangedByRole = string(claims.Role)
+	}
+
+	for attempt := 1; attempt <= maxVersionAttempts; attempt++ {
+		var previous *domain.Product
+		latest, err := s.history.FindLatestProductVersion(ctx, product.ID)
+		var notFound *apperror.NotFoundError
+		switch {
+		case err == nil:
+			previous = &latest.Snapshot
+		case !errors.As(err, &notFound):
+			s.logger.WithContext(ctx).Error("Falha ao buscar última versão do produto.", err)
+			return
+		}
+
+		version.Version = latest.Version + 1
+		version.Changes = domain.DiffProducts(previous, product)
+		if previous != nil && len(version.Changes) == 0 && changeType != domain.ProductChangeRevert {
+			s.logger.WithContext(ctx).Debug("Nenhuma alteração no produto; versão não registrada.", map[string]interface{}{"product_id": product.ID})
+			return
+		}
+
+		_, err = s.history.InsertProductVersion(ctx, version)
+		var conflict *apperror.ConflictError
+		if errors.As(err, &conflict) {
+			continue // Outra alteração gravou esta versão primeiro: recalcula sobre a nova última versão
+		}
+		if err != nil {
+			s.logger.WithContext(ctx).Error("Falha ao registrar versão do produto.", err)
+			return
+		}
+		s.logger.WithContext(ctx).Info("Versão do produto registrada.", map[string]interface{}{"product_id": product.ID, "version": version.Version, "change_type": changeType})
+		return
+	}
+	s.logger.WithContext(ctx).Warn("Versão do produto não registrada após tentativas concorrentes.", map[string]interface{}{"product_id": product.ID})
+}
+
+// --- Implementação: GetProductHistory ---
+
+// GetProductHistory lista as versões do produto, da mais recente para a mais antiga.
+func (s *Service) GetProductHistory(ctx domain.Context, productID string, page, limit int) ([]domain.ProductVersion, error) {
+	s.logger.WithContext(ctx).Debug("Iniciando busca de histórico do produto no serviço.", map[string]interface{}{"product_id": productID})
+
+	if _, err := uuid.Parse(productID); err != nil {
+		return nil, apperror.NewValidationError("O ID do produto deve ser um UUID válido.")
+	}
+	if s.history == nil {
+		return nil, apperror.NewInternalError("Histórico de produtos indisponível.", nil)
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background() para GetProductHistory", nil)
+	}
+
+	// Garante 404 para produtos inexistentes em vez de um histórico vazio.
+	if _, err := s.GetProductByID(ctxGo, productID); err != nil {
+		return nil, err
+	}
+
+	versions, err := s.history.FindProductVersions(ctxGo, productID, page, limit)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao buscar histórico do produto no repositório.", err)
+		return nil, err
+	}
+	return versions, nil
+}
+
+// --- Implementação: RevertProduct ---
+
+// RevertProduct restaura o produto (e suas variantes) ao estado de uma versão anterior.
+// A reversão é registrada como uma nova versão, preservando o histórico.
+func (s *Service) RevertProduct(ctx domain.Context, productID string, version int) (domain.Product, error) {
+	s.logger.WithContext(ctx).Debug("Iniciando reversão de produto no serviço.", map[string]interface{}{"product_id": productID, "version": version})
+
+	if _, err := uuid.Parse(productID); err != nil {
+		return domain.Product{}, apperror.NewValidationError("O ID do produto deve ser um UUID válido.")
+	}
+	if version < 1 {
+		return domain.Product{}, apperror.NewValidationError("A versão deve ser um número inteiro positivo.")
+	}
+	if s.history == nil {
+		return domain.Product{}, apperror.NewInternalError("Histórico de produtos indisponível.", nil)
+	}
+
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background() para RevertProduct", nil)
+	}
+
+	target, err := s.history.FindProductVersion(ctxGo, productID, version)
+	if err != nil {
+		return domain.Product{}, err
+	}
+
+	restored := target.Snapshot
+	restored.ID = productID
+	restored.UpdatedAt = time.Now().UTC()
+	// Versões anteriores ao modelo de atributos trazem apenas attribute/value. As definições de
+	// atributos atuais não são aplicadas: a reversão restaura o estado como era.
+	restored.Variants = append([]domain.Variant(nil), restored.Variants...)
+	normalizeVariants(restored.Variants)
+	if err := s.validateProduct(restored); err != nil {
+		return domain.Product{}, apperror.NewConflictError(fmt.Sprintf("A versão %d não pode ser restaurada: %s", version, err.Error()))
+	}
+
+	restored, err = s.history.RestoreProduct(ctxGo, restored)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao restaurar produto no repositório.", err)
+		return domain.Product{}, err
+	}
+
+	s.recordVersion(ctxGo, restored, domain.ProductChangeRevert, &version)
+	s.logger.WithContext(ctx).Info("Produto revertido com sucesso.", map[string]interface{}{"product_id": productID, "version": version})
+	return restored, nil
+}
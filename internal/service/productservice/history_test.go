@@ -0,0 +1,208 @@
+package productservice_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/middleware"
+	"gostock/internal/service/productservice"
+)
+
+// MockHistoryRepository é um ProductRepository que também implementa o histórico de versões.
+type MockHistoryRepository struct {
+	MockProductRepository
+}
+
+func (m *MockHistoryRepository) InsertProductVersion(ctx context.Context, version domain.ProductVersion) (domain.ProductVersion, error) {
+	args := m.Called(ctx, version)
+	return args.Get(0).(domain.ProductVersion), args.Error(1)
+}
+
+func (m *MockHistoryRepository) FindLatestProductVersion(ctx context.Context, productID string) (domain.ProductVersion, error) {
+	args := m.Called(ctx, productID)
+	return args.Get(0).(domain.ProductVersion), args.Error(1)
+}
+
+func (m *MockHistoryRepository) FindProductVersion(ctx context.Context, productID string, version int) (domain.ProductVersion, error) {
+	args := m.Called(ctx, productID, version)
+	return args.Get(0).(domain.ProductVersion), args.Error(1)
+}
+
+func (m *MockHistoryRepository) FindProductVersions(ctx context.Context, productID string, page, limit int) ([]domain.ProductVersion, error) {
+	args := m.Called(ctx, productID, page, limit)
+	return args.Get(0).([]domain.ProductVersion), args.Error(1)
+}
+
+func (m *MockHistoryRepository) RestoreProduct(ctx context.Context, product domain.Product) (domain.Product, error) {
+	args := m.Called(ctx, product)
+	return args.Get(0).(domain.Product), args.Error(1)
+}
+
+// adminContext simula o contexto anexado pelo middleware de autenticação.
+func adminContext(userID string) context.Context {
+	return context.WithValue(context.Background(), middleware.UserClaimsKey, middleware.UserClaims{UserID: userID, Role: domain.RoleAdmin})
+}
+
+// TestCreateProduct_RecordsFirstVersionWithAuthor testa que a criação registra a versão 1 com o autor do JWT.
+func TestCreateProduct_RecordsFirstVersionWithAuthor(t *testing.T) {
+	mockRepo := new(MockHistoryRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+	mockRepo.On("FindAttributeDefinitions", mock.Anything).Return([]domain.AttributeDefinition{}, nil)
+	userID := uuid.New().String()
+
+	saved := domain.Product{ID: uuid.New().String(), SKU: "CAM-001", Name: "Camiseta", Price: 49.9, IsActive: true}
+	mockRepo.On("Save", mock.Anything, mock.AnythingOfType("domain.Product")).Return(saved, nil)
+	mockRepo.On("FindLatestProductVersion", mock.Anything, mock.Anything).
+		Return(domain.ProductVersion{}, apperror.NewNotFoundError("sem histórico"))
+	mockRepo.On("InsertProductVersion", mock.Anything, mock.MatchedBy(func(v domain.ProductVersion) bool {
+		return v.Version == 1 && v.ChangeType == domain.ProductChangeCreate &&
+			v.ChangedBy == userID && v.ChangedByRole == string(domain.RoleAdmin) &&
+			v.Snapshot.SKU == "CAM-001" && len(v.Changes) > 0
+	})).Return(domain.ProductVersion{}, nil)
+
+	_, err := svc.CreateProduct(adminContext(userID), domain.Product{SKU: "CAM-001", Name: "Camiseta", Price: 49.9},
+		[]domain.Variant{{Attribute: "Cor", Value: "Azul", Barcode: "7890000000017"}})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestImportProducts_RecordsDiffAgainstLatestVersion testa que um upsert registra apenas os campos alterados.
+func TestImportProducts_RecordsDiffAgainstLatestVersion(t *testing.T) {
+	mockRepo := new(MockHistoryRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+	mockRepo.On("FindAttributeDefinitions", mock.Anything).Return([]domain.AttributeDefinition{}, nil)
+	productID := uuid.New().String()
+
+	previous := domain.Product{ID: productID, SKU: "CAM-001", Name: "Camiseta", Price: 49.9, IsActive: true}
+	current := previous
+	current.Price = 59.9
+
+	mockRepo.On("UpsertBySKU", mock.Anything, mock.Anything).Return(domain.Product{ID: productID}, false, nil)
+	mockRepo.On("FindByID", mock.Anything, productID).Return(current, nil)
+	mockRepo.On("FindLatestProductVersion", mock.Anything, productID).
+		Return(domain.ProductVersion{ProductID: productID, Version: 4, Snapshot: previous}, nil)
+	mockRepo.On("InsertProductVersion", mock.Anything, mock.MatchedBy(func(v domain.ProductVersion) bool {
+		return v.Version == 5 && v.ChangeType == domain.ProductChangeImport &&
+			len(v.Changes) == 1 && v.Changes[0].Field == "price" && v.Changes[0].Old == 49.9 && v.Changes[0].New == 59.9
+	})).Return(domain.ProductVersion{}, nil)
+
+	report, err := svc.ImportProducts(adminContext(uuid.New().String()), domain.ImportFormatNDJSON,
+		strings.NewReader(`{"sku":"CAM-001","name":"Camiseta","price":59.9,"variants":[{"attribute":"Cor","value":"Azul","barcode":"7890000000017"}]}`+"\n"), false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Updated)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestRecordVersion_RetriesOnConcurrentVersion testa a nova tentativa quando outra gravação ocupa o número da versão.
+func TestRecordVersion_RetriesOnConcurrentVersion(t *testing.T) {
+	mockRepo := new(MockHistoryRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+	mockRepo.On("FindAttributeDefinitions", mock.Anything).Return([]domain.AttributeDefinition{}, nil)
+
+	mockRepo.On("Save", mock.Anything, mock.Anything).
+		Return(domain.Product{ID: uuid.New().String(), SKU: "CAM-001", Name: "Camiseta", Price: 49.9}, nil)
+	mockRepo.On("FindLatestProductVersion", mock.Anything, mock.Anything).
+		Return(domain.ProductVersion{}, apperror.NewNotFoundError("sem histórico")).Once()
+	mockRepo.On("FindLatestProductVersion", mock.Anything, mock.Anything).
+		Return(domain.ProductVersion{Version: 1}, nil).Once()
+	mockRepo.On("InsertProductVersion", mock.Anything, mock.MatchedBy(func(v domain.ProductVersion) bool { return v.Version == 1 })).
+		Return(domain.ProductVersion{}, apperror.NewConflictError("versão já existe")).Once()
+	mockRepo.On("InsertProductVersion", mock.Anything, mock.MatchedBy(func(v domain.ProductVersion) bool { return v.Version == 2 })).
+		Return(domain.ProductVersion{}, nil).Once()
+
+	_, err := svc.CreateProduct(context.Background(), domain.Product{SKU: "CAM-001", Name: "Camiseta", Price: 49.9},
+		[]domain.Variant{{Attribute: "Cor", Value: "Azul", Barcode: "7890000000017"}})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestRevertProduct_Success testa a restauração de uma versão e o registro da reversão no histórico.
+func TestRevertProduct_Success(t *testing.T) {
+	mockRepo := new(MockHistoryRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+	productID := uuid.New().String()
+	userID := uuid.New().String()
+
+	v2 := domain.Product{ID: productID, SKU: "CAM-001", Name: "Camiseta", Price: 49.9, IsActive: true,
+		Variants: []domain.Variant{{ID: "v1", Attribute: "Cor", Value: "Azul", Barcode: "7890000000017"}}}
+	v3 := v2
+	v3.Price = 59.9
+
+	mockRepo.On("FindProductVersion", mock.Anything, productID, 2).
+		Return(domain.ProductVersion{ProductID: productID, Version: 2, Snapshot: v2}, nil)
+	mockRepo.On("RestoreProduct", mock.Anything, mock.MatchedBy(func(p domain.Product) bool {
+		return p.ID == productID && p.Price == 49.9
+	})).Return(v2, nil)
+	mockRepo.On("FindLatestProductVersion", mock.Anything, productID).
+		Return(domain.ProductVersion{ProductID: productID, Version: 3, Snapshot: v3}, nil)
+	mockRepo.On("InsertProductVersion", mock.Anything, mock.MatchedBy(func(v domain.ProductVersion) bool {
+		return v.Version == 4 && v.ChangeType == domain.ProductChangeRevert &&
+			v.RevertedTo != nil && *v.RevertedTo == 2 && v.ChangedBy == userID &&
+			len(v.Changes) == 1 && v.Changes[0].Field == "price"
+	})).Return(domain.ProductVersion{}, nil)
+
+	restored, err := svc.RevertProduct(adminContext(userID), productID, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 49.9, restored.Price)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestRevertProduct_Fail_VersionNotFound testa a reversão para uma versão inexistente.
+func TestRevertProduct_Fail_VersionNotFound(t *testing.T) {
+	mockRepo := new(MockHistoryRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+	productID := uuid.New().String()
+
+	mockRepo.On("FindProductVersion", mock.Anything, productID, 9).
+		Return(domain.ProductVersion{}, apperror.NewNotFoundError("versão não encontrada"))
+
+	_, err := svc.RevertProduct(context.Background(), productID, 9)
+
+	assert.Error(t, err)
+	assert.IsType(t, &apperror.NotFoundError{}, err)
+	mockRepo.AssertNotCalled(t, "RestoreProduct", mock.Anything, mock.Anything)
+}
+
+// TestRevertProduct_Fail_InvalidVersion testa a validação do número da versão.
+func TestRevertProduct_Fail_InvalidVersion(t *testing.T) {
+	svc := productservice.NewService(new(MockHistoryRepository), logger.NewLogger("debug"))
+
+	_, err := svc.RevertProduct(context.Background(), uuid.New().String(), 0)
+
+	assert.Error(t, err)
+	assert.IsType(t, &apperror.ValidationError{}, err)
+}
+
+// TestDiffProducts_Variants testa o diff de variantes alteradas, adicionadas e removidas.
+func TestDiffProducts_Variants(t *testing.T) {
+	before := domain.Product{SKU: "CAM-001", Variants: []domain.Variant{
+		{ID: "v1", Attribute: "Cor", Value: "Azul", Barcode: "111"},
+		{ID: "v2", Attribute: "Cor", Value: "Verde", Barcode: "222"},
+	}}
+	after := domain.Product{SKU: "CAM-001", Variants: []domain.Variant{
+		{ID: "v1", Attribute: "Cor", Value: "Azul", Barcode: "111", PriceDiff: 2.5},
+		{ID: "v3", Attribute: "Cor", Value: "Preto", Barcode: "333"},
+	}}
+
+	changes := domain.DiffProducts(&before, after)
+
+	fields := make([]string, 0, len(changes))
+	for _, c := range changes {
+		fields = append(fields, c.Field)
+	}
+	assert.Equal(t, []string{"variants[v1].price_diff", "variants[v3]", "variants[v2]"}, fields)
+	assert.Nil(t, changes[1].Old)
+	assert.Nil(t, changes[2].New)
+}
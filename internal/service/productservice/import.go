@@ -0,0 +1,539 @@
+package productservice
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/tenant"
+)
+
+const (
+	// maxImportErrors limita os erros por linha guardados no relatório (memória constante).
+	maxImportErrors = 1000
+	// importProgressEvery define a cada quantos produtos o progresso de um job é publicado.
+	importProgressEvery = 100
+	// importJobRetention define por quanto tempo jobs finalizados ficam disponíveis para consulta.
+	importJobRetention = 24 * time.Hour
+	// maxNDJSONLineBytes é o tamanho máximo de uma linha NDJSON (um produto com variantes).
+	maxNDJSONLineBytes = 1 << 20
+)
+
+// csvImportColumns são as colunas reconhecidas no cabeçalho do CSV.
+// Cada linha representa uma variante; linhas consecutivas com o mesmo SKU formam um produto.
+// Variantes com vários atributos usam uma coluna "attr:<Nome>" por atributo (ex.: "attr:Tamanho");
+// atributos personalizados do produto usam uma coluna "spec:<campo>" (lidos da primeira linha do SKU).
+var csvImportColumns = []string{"sku", "name", "description", "price", "category", "attribute", "value", "barcode", "price_diff"}
+
+// Prefixos das colunas de atributos de variante e de atributos personalizados no cabeçalho do CSV.
+const (
+	csvAttributeColumnPrefix = "attr:"
+	csvSpecColumnPrefix      = "spec:"
+)
+
+// importRecord é um produto montado a partir de uma ou mais linhas do arquivo.
+type importRecord struct {
+	row     int // Primeira linha do produto no arquivo
+	rows    int // Quantidade de linhas que compõem o produto
+	product domain.Product
+	err     error // Erro de parsing (o produto não será validado nem gravado)
+}
+
+// importJobStore mantém em memória o estado dos jobs de importação desta instância.
+type importJobStore struct {
+	mu   sync.RWMutex
+	jobs map[string]domain.ImportReport
+}
+
+func newImportJobStore() *importJobStore {
+	return &importJobStore{jobs: make(map[string]domain.ImportReport)}
+}
+
+// save publica uma cópia do relatório e descarta jobs finalizados há mais de importJobRetention.
+func (st *importJobStore) save(report domain.ImportReport) {
+	report.Errors = append([]domain.ImportRowError(nil), report.Errors...)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.jobs[report.JobID] = report
+	for id, job := range st.jobs {
+		if job.FinishedAt != nil && time.Since(*job.FinishedAt) > importJobRetention {
+			delete(st.jobs, id)
+		}
+	}
+}
+
+func (st *importJobStore) get(id string) (domain.ImportReport, bool) {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	report, ok := st.jobs[id]
+	return report, ok
+}
+
+// --- Implementação: ImportProducts ---
+
+// ImportProducts processa o arquivo de forma síncrona (streaming) e retorna o relatório final.
+// Cada produto é validado com as mesmas regras de CreateProduct e gravado por upsert de SKU;
+// em modo dryRun nada é gravado.
+func (s *Service) ImportProducts(ctx domain.Context, format domain.ImportFormat, src io.Reader, dryRun bool) (domain.ImportReport, error) {
+	s.logger.WithContext(ctx).Debug("Iniciando importação síncrona de produtos.", map[string]interface{}{"format": format, "dry_run": dryRun})
+
+	if err := validateImportFormat(format); err != nil {
+		return domain.ImportReport{}, err
+	}
+
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background() para ImportProducts", nil)
+	}
+
+	report := newImportReport(format, dryRun)
+	report.TenantID = tenant.ID(ctxGo)
+	report.Status = domain.ImportStatusRunning
+	if err := s.runImport(ctxGo, src, &report); err != nil {
+		s.finishImport(ctxGo, &report, err)
+		return domain.ImportReport{}, err
+	}
+	s.finishImport(ctxGo, &report, nil)
+	return report, nil
+}
+
+// StartProductImport copia o arquivo para um diretório temporário e o processa em segundo plano.
+// Retorna imediatamente o relatório com status "pending"; o progresso é consultado via GetImportJob.
+func (s *Service) StartProductImport(ctx domain.Context, format domain.ImportFormat, src io.Reader, dryRun bool) (domain.ImportReport, error) {
+	s.logger.WithContext(ctx).Debug("Iniciando importação assíncrona de produtos.", map[string]interface{}{"format": format, "dry_run": dryRun})
+
+	if err := validateImportFormat(format); err != nil {
+		return domain.ImportReport{}, err
+	}
+
+	// O corpo da requisição deixa de existir quando o handler retorna, então é preciso
+	// gravá-lo em disco antes de liberar a requisição.
+	spool, err := os.CreateTemp("", "gostock-import-*")
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao criar arquivo temporário para importação.", err)
+		return domain.ImportReport{}, apperror.NewInternalError("Falha ao preparar importação.", err)
+	}
+	if _, err := io.Copy(spool, src); err != nil {
+		spool.Close()
+		os.Remove(spool.Name())
+		s.logger.WithContext(ctx).Warn("Falha ao receber arquivo de importação.", map[string]interface{}{"error": err.Error()})
+		return domain.ImportReport{}, apperror.NewValidationError(fmt.Sprintf("Falha ao ler o arquivo de importação: %s", err.Error()))
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		spool.Close()
+		os.Remove(spool.Name())
+		return domain.ImportReport{}, apperror.NewInternalError("Falha ao preparar importação.", err)
+	}
+
+	jobCtx := context.Background()
+	if ctxGo, ok := ctx.(context.Context); ok {
+		jobCtx = context.WithoutCancel(ctxGo)
+	}
+
+	report := newImportReport(format, dryRun)
+	report.TenantID = tenant.ID(jobCtx)
+	s.imports.save(report)
+
+	go func() {
+		defer os.Remove(spool.Name())
+		defer spool.Close()
+
+		// O contexto da requisição é cancelado ao fim do handler; o job usa um contexto sem
+		// cancelamento que preserva os valores (as claims do usuário, usadas no histórico).
+		jobReport := report
+		jobReport.Status = domain.ImportStatusRunning
+		s.imports.save(jobReport)
+
+		err := s.runImport(jobCtx, spool, &jobReport)
+		s.finishImport(jobCtx, &jobReport, err)
+	}()
+
+	s.logger.WithContext(ctx).Info("Job de importação de produtos criado.", map[string]interface{}{"job_id": report.JobID, "format": format, "dry_run": dryRun})
+	return report, nil
+}
+
+// GetImportJob retorna o estado atual de um job de importação. Jobs de outra empresa são tratados
+// como inexistentes, como os demais recursos escopados por tenant.
+func (s *Service) GetImportJob(ctx domain.Context, id string) (domain.ImportReport, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return domain.ImportReport{}, apperror.NewValidationError("O ID do job de importação deve ser um UUID válido.")
+	}
+
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background()", nil)
+	}
+
+	report, ok := s.imports.get(id)
+	if !ok || report.TenantID != tenant.ID(ctxGo) {
+		return domain.ImportReport{}, apperror.NewNotFoundError(fmt.Sprintf("Job de importação %s não encontrado.", id))
+	}
+	return report, nil
+}
+
+// --- Funções auxiliares da importação ---
+
+func validateImportFormat(format domain.ImportFormat) error {
+	if format != domain.ImportFormatCSV && format != domain.ImportFormatNDJSON {
+		return apperror.NewValidationError("Formato de importação inválido. Use 'csv' ou 'ndjson'.")
+	}
+	return nil
+}
+
+func newImportReport(format domain.ImportFormat, dryRun bool) domain.ImportReport {
+	return domain.ImportReport{
+		JobID:     uuid.New().String(),
+		Status:    domain.ImportStatusPending,
+		Format:    format,
+		DryRun:    dryRun,
+		Errors:    []domain.ImportRowError{},
+		StartedAt: time.Now().UTC(),
+	}
+}
+
+// finishImport marca o relatório como finalizado, registra o resultado e o publica. ctx é o
+// contexto do job, que mantém o ID da requisição que o criou para correlacionar os logs.
+func (s *Service) finishImport(ctx context.Context, report *domain.ImportReport, err error) {
+	now := time.Now().UTC()
+	report.FinishedAt = &now
+	if err != nil {
+		report.Status = domain.ImportStatusFailed
+		report.Message = err.Error()
+		s.logger.WithContext(ctx).Warn("Importação de produtos interrompida.", map[string]interface{}{"job_id": report.JobID, "error": err.Error()})
+	} else {
+		report.Status = domain.ImportStatusCompleted
+		s.logger.WithContext(ctx).Info("Importação de produtos concluída.", map[string]interface{}{
+			"job_id":  report.JobID,
+			"dry_run": report.DryRun,
+			"rows":    report.TotalRows,
+			"created": report.Created,
+			"updated": report.Updated,
+			"failed":  report.Failed,
+		})
+	}
+	s.imports.save(*report)
+}
+
+// runImport percorre o arquivo e aplica cada produto, atualizando o relatório.
+// Só retorna erro para falhas que impedem a leitura do arquivo como um todo.
+func (s *Service) runImport(ctx context.Context, src io.Reader, report *domain.ImportReport) error {
+	// Em dry_run o banco não muda durante a importação; os SKUs já vistos no arquivo
+	// são lembrados para contar corretamente "created" x "updated".
+	seen := make(map[string]bool)
+
+	// As definições de atributos são carregadas uma vez, no primeiro produto do arquivo;
+	// os esquemas de categoria, na primeira vez que cada categoria aparece.
+	var defs map[string]domain.AttributeDefinition
+	schemas := make(map[string]*domain.CategorySchema)
+
+	apply := func(rec importRecord) error {
+		if err := ctx.Err(); err != nil {
+			return apperror.NewInternalError("Importação cancelada.", err)
+		}
+		if defs == nil {
+			var err error
+			if defs, err = s.attributeDefinitions(ctx); err != nil {
+				return apperror.NewInternalError("Falha ao carregar definições de atributos.", err)
+			}
+		}
+		report.TotalRows += rec.rows
+		report.Products++
+		s.applyImportRecord(ctx, rec, report, seen, defs, schemas)
+		if report.Products%importProgressEvery == 0 {
+			s.imports.save(*report)
+		}
+		return nil
+	}
+
+	switch report.Format {
+	case domain.ImportFormatCSV:
+		return parseCSVImport(src, apply)
+	default:
+		return parseNDJSONImport(src, apply)
+	}
+}
+
+// applyImportRecord valida e grava (ou simula a gravação de) um produto do arquivo.
+func (s *Service) applyImportRecord(ctx context.Context, rec importRecord, report *domain.ImportReport, seen map[string]bool, defs map[string]domain.AttributeDefinition, schemas map[string]*domain.CategorySchema) {
+	product := rec.product
+	if rec.err != nil {
+		addImportError(report, rec.row, product.SKU, rec.err)
+		return
+	}
+	normalizeVariants(product.Variants)
+	if err := s.validateProduct(product); err != nil {
+		addImportError(report, rec.row, product.SKU, err)
+		return
+	}
+	if err := applyAttributeDefinitions(product.Variants, defs); err != nil {
+		addImportError(report, rec.row, product.SKU, err)
+		return
+	}
+	if err := s.applyCategorySchema(ctx, &product, schemas); err != nil {
+		addImportError(report, rec.row, product.SKU, err)
+		return
+	}
+
+	now := time.Now().UTC()
+	product.ID = uuid.New().String() // Mantido apenas se o SKU ainda não existir
+	product.IsActive = true
+	product.CreatedAt = now
+	product.UpdatedAt = now
+	for i := range product.Variants {
+		if product.Variants[i].ID == "" {
+			product.Variants[i].ID = uuid.New().String()
+		}
+		product.Variants[i].ProductID = product.ID
+	}
+
+	if report.DryRun {
+		exists := seen[product.SKU]
+		if !exists {
+			existing, err := s.repo.FindAll(ctx, domain.ProductFilter{Page: 1, Limit: 1, SKU: product.SKU})
+			if err != nil {
+				s.logger.WithContext(ctx).Error("Falha ao verificar SKU existente durante dry_run.", err)
+				addImportError(report, rec.row, product.SKU, apperror.NewInternalError("Falha ao verificar SKU existente.", err))
+				return
+			}
+			exists = len(existing) > 0
+		}
+		seen[product.SKU] = true
+		if exists {
+			report.Updated++
+		} else {
+			report.Created++
+		}
+		return
+	}
+
+	saved, created, err := s.repo.UpsertBySKU(ctx, product)
+	if err != nil {
+		s.logger.WithContext(ctx).Warn("Falha ao gravar produto importado.", map[string]interface{}{"row": rec.row, "sku": product.SKU, "error": err.Error()})
+		addImportError(report, rec.row, product.SKU, err)
+		return
+	}
+	s.recordImportVersion(ctx, saved, created)
+	if created {
+		report.Created++
+	} else {
+		report.Updated++
+	}
+}
+
+// recordImportVersion registra no histórico o estado do produto após o upsert. O upsert não
+// remove variantes ausentes do arquivo, então o estado completo é relido do repositório.
+func (s *Service) recordImportVersion(ctx context.Context, saved domain.Product, created bool) {
+	if s.history == nil {
+		return
+	}
+	current, err := s.repo.FindByID(ctx, saved.ID)
+	if err != nil {
+		s.logger.WithContext(ctx).Warn("Falha ao reler produto importado para o histórico.", map[string]interface{}{"product_id": saved.ID, "error": err.Error()})
+		return
+	}
+	changeType := domain.ProductChangeImport
+	if created {
+		changeType = domain.ProductChangeCreate
+	}
+	s.recordVersion(ctx, current, changeType, nil)
+}
+
+// addImportError registra a falha de um produto, respeitando o limite de erros reportados.
+func addImportError(report *domain.ImportReport, row int, sku string, err error) {
+	report.Failed++
+	if len(report.Errors) >= maxImportErrors {
+		report.Truncated = true
+		return
+	}
+	message := err.Error()
+	// Para erros internos não expomos a causa (ex: erro SQL) no relatório.
+	var internalErr *apperror.InternalError
+	if errors.As(err, &internalErr) {
+		message = "Falha interna ao gravar o produto."
+	}
+	report.Errors = append(report.Errors, domain.ImportRowError{Row: row, SKU: sku, Message: message})
+}
+
+// parseCSVImport lê o CSV linha a linha, agrupando linhas consecutivas do mesmo SKU em um produto.
+func parseCSVImport(src io.Reader, emit func(importRecord) error) error {
+	reader := csv.NewReader(src)
+	reader.FieldsPerRecord = -1 // Colunas ausentes são tratadas como vazias
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return apperror.NewValidationError("O arquivo CSV está vazio.")
+	}
+	if err != nil {
+		return apperror.NewValidationError(fmt.Sprintf("Cabeçalho CSV inválido: %s", err.Error()))
+	}
+
+	columns := make(map[string]int, len(header))
+	attributeColumns := make(map[string]int) // Nome do atributo (como no cabeçalho) -> índice
+	specColumns := make(map[string]int)      // Nome do atributo personalizado -> índice
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if len(name) > len(csvAttributeColumnPrefix) && strings.EqualFold(name[:len(csvAttributeColumnPrefix)], csvAttributeColumnPrefix) {
+			attributeColumns[strings.TrimSpace(name[len(csvAttributeColumnPrefix):])] = i
+			continue
+		}
+		if len(name) > len(csvSpecColumnPrefix) && strings.EqualFold(name[:len(csvSpecColumnPrefix)], csvSpecColumnPrefix) {
+			specColumns[strings.TrimSpace(name[len(csvSpecColumnPrefix):])] = i
+			continue
+		}
+		columns[strings.ToLower(name)] = i
+	}
+	for _, required := range []string{"sku", "name", "price"} {
+		if _, ok := columns[required]; !ok {
+			return apperror.NewValidationError(fmt.Sprintf("Coluna obrigatória '%s' ausente no cabeçalho CSV. Colunas aceitas: %s.", required, strings.Join(csvImportColumns, ", ")))
+		}
+	}
+
+	field := func(record []string, name string) string {
+		idx, ok := columns[name]
+		if !ok || idx >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[idx])
+	}
+
+	var current *importRecord
+	flush := func() error {
+		if current == nil {
+			return nil
+		}
+		rec := *current
+		current = nil
+		return emit(rec)
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return apperror.NewValidationError(fmt.Sprintf("Falha ao ler o CSV: %s", err.Error()))
+			}
+			// Linha malformada: reporta e segue para a próxima.
+			if flushErr := flush(); flushErr != nil {
+				return flushErr
+			}
+			if emitErr := emit(importRecord{row: parseErr.Line, rows: 1, err: apperror.NewValidationError("Linha CSV malformada.")}); emitErr != nil {
+				return emitErr
+			}
+			continue
+		}
+
+		sku := field(record, "sku")
+		if current == nil || current.product.SKU != sku {
+			if err := flush(); err != nil {
+				return err
+			}
+			current = &importRecord{row: line, product: domain.Product{
+				SKU:         sku,
+				Name:        field(record, "name"),
+				Description: field(record, "description"),
+				Category:    field(record, "category"),
+				Variants:    []domain.Variant{},
+			}}
+			// Valores em texto; o esquema da categoria converte números e booleanos
+			for name, idx := range specColumns {
+				if idx < len(record) && strings.TrimSpace(record[idx]) != "" {
+					if current.product.CustomAttributes == nil {
+						current.product.CustomAttributes = make(map[string]interface{})
+					}
+					current.product.CustomAttributes[name] = strings.TrimSpace(record[idx])
+				}
+			}
+			if raw := field(record, "price"); raw != "" {
+				price, parseErr := strconv.ParseFloat(raw, 64)
+				if parseErr != nil {
+					current.err = apperror.NewValidationError(fmt.Sprintf("Preço '%s' inválido na linha %d.", raw, line))
+				}
+				current.product.Price = price
+			}
+		}
+		current.rows++
+
+		variant := domain.Variant{
+			Attribute: field(record, "attribute"),
+			Value:     field(record, "value"),
+			Barcode:   field(record, "barcode"),
+		}
+		for name, idx := range attributeColumns {
+			if idx < len(record) && strings.TrimSpace(record[idx]) != "" {
+				if variant.Attributes == nil {
+					variant.Attributes = make(map[string]string)
+				}
+				variant.Attributes[name] = strings.TrimSpace(record[idx])
+			}
+		}
+		if len(variant.Attributes) > 0 && variant.Attribute != "" {
+			// As colunas attribute/value somam mais um eixo à combinação
+			variant.Attributes[variant.Attribute] = variant.Value
+		}
+		if raw := field(record, "price_diff"); raw != "" {
+			priceDiff, parseErr := strconv.ParseFloat(raw, 64)
+			if parseErr != nil && current.err == nil {
+				current.err = apperror.NewValidationError(fmt.Sprintf("Diferença de preço '%s' inválida na linha %d.", raw, line))
+			}
+			variant.PriceDiff = priceDiff
+		}
+		if variant.Attribute != "" || variant.Value != "" || variant.Barcode != "" || len(variant.Attributes) > 0 {
+			current.product.Variants = append(current.product.Variants, variant)
+		}
+	}
+
+	return flush()
+}
+
+// parseNDJSONImport lê um produto (com suas variantes) por linha no formato JSON.
+func parseNDJSONImport(src io.Reader, emit func(importRecord) error) error {
+	scanner := bufio.NewScanner(src)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLineBytes)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := strings.TrimSpace(scanner.Text())
+		if raw == "" {
+			continue
+		}
+
+		rec := importRecord{row: line, rows: 1}
+		if err := json.Unmarshal([]byte(raw), &rec.product); err != nil {
+			rec.err = apperror.NewValidationError(fmt.Sprintf("JSON inválido na linha %d.", line))
+		}
+		if err := emit(rec); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return apperror.NewValidationError(fmt.Sprintf("A linha %d excede o tamanho máximo de %d bytes.", line+1, maxNDJSONLineBytes))
+		}
+		return apperror.NewValidationError(fmt.Sprintf("Falha ao ler o NDJSON: %s", err.Error()))
+	}
+	return nil
+}
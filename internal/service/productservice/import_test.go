@@ -0,0 +1,211 @@
+package productservice_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/requestid"
+	"gostock/internal/pkg/tenant"
+	"gostock/internal/service/productservice"
+)
+
+const importCSV = `sku,name,description,price,attribute,value,barcode,price_diff
+CAM-001,Camiseta,Algodão,49.90,Cor,Vermelho,7890000000017,0
+CAM-001,Camiseta,Algodão,49.90,Cor,Azul,7890000000024,2.50
+CAL-001,Calça,,abc,Tamanho,M,7890000000031,0
+BON-001,Boné,,29.90,,,,
+`
+
+// TestImportProducts_CSV_DryRun testa a validação de um CSV sem gravação, com erros por linha.
+func TestImportProducts_CSV_DryRun(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+	mockRepo.On("FindAttributeDefinitions", mock.Anything).Return([]domain.AttributeDefinition{}, nil)
+
+	// CAM-001 já existe no catálogo: deve ser contado como atualização.
+	mockRepo.On("FindAll", mock.Anything, domain.ProductFilter{Page: 1, Limit: 1, SKU: "CAM-001"}).
+		Return([]domain.Product{{SKU: "CAM-001"}}, nil).Once()
+
+	report, err := svc.ImportProducts(context.Background(), domain.ImportFormatCSV, strings.NewReader(importCSV), true)
+
+	assert.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, domain.ImportStatusCompleted, report.Status)
+	assert.Equal(t, 4, report.TotalRows)
+	assert.Equal(t, 3, report.Products)
+	assert.Equal(t, 0, report.Created)
+	assert.Equal(t, 1, report.Updated)
+	assert.Equal(t, 2, report.Failed)
+	if assert.Len(t, report.Errors, 2) {
+		assert.Equal(t, domain.ImportRowError{Row: 4, SKU: "CAL-001", Message: "Erro de Validação: Preço 'abc' inválido na linha 4."}, report.Errors[0])
+		assert.Equal(t, 5, report.Errors[1].Row)
+		assert.Contains(t, report.Errors[1].Message, "pelo menos uma variação")
+	}
+	mockRepo.AssertNotCalled(t, "UpsertBySKU", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestImportProducts_NDJSON_Upsert testa a gravação por upsert de SKU a partir de NDJSON.
+func TestImportProducts_NDJSON_Upsert(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+	mockRepo.On("FindAttributeDefinitions", mock.Anything).Return([]domain.AttributeDefinition{}, nil)
+
+	input := `{"sku":"CAM-001","name":"Camiseta","price":49.9,"variants":[{"attribute":"Cor","value":"Azul","barcode":"7890000000024"}]}
+
+{"sku":"CAM-002","name":"Camiseta Gola V","price":59.9,"variants":[{"attribute":"Cor","value":"Preto","barcode":"7890000000048"}]}
+{"sku": "quebrado"
+`
+	mockRepo.On("UpsertBySKU", mock.Anything, mock.MatchedBy(func(p domain.Product) bool { return p.SKU == "CAM-001" })).
+		Return(domain.Product{}, false, nil).Once()
+	mockRepo.On("UpsertBySKU", mock.Anything, mock.MatchedBy(func(p domain.Product) bool {
+		return p.SKU == "CAM-002" && p.IsActive && p.ID != "" && p.Variants[0].ProductID == p.ID
+	})).Return(domain.Product{}, true, nil).Once()
+
+	report, err := svc.ImportProducts(context.Background(), domain.ImportFormatNDJSON, strings.NewReader(input), false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, report.TotalRows)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 1, report.Updated)
+	assert.Equal(t, 1, report.Failed)
+	if assert.Len(t, report.Errors, 1) {
+		assert.Equal(t, 4, report.Errors[0].Row)
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+// TestImportProducts_RepoErrorIsReportedPerRow testa que falhas do repositório não interrompem a importação.
+func TestImportProducts_RepoErrorIsReportedPerRow(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+	mockRepo.On("FindAttributeDefinitions", mock.Anything).Return([]domain.AttributeDefinition{}, nil)
+
+	input := `{"sku":"A","name":"A","price":1,"variants":[{"attribute":"Cor","value":"Azul","barcode":"1"}]}
+{"sku":"B","name":"B","price":1,"variants":[{"attribute":"Cor","value":"Azul","barcode":"1"}]}
+`
+	mockRepo.On("UpsertBySKU", mock.Anything, mock.MatchedBy(func(p domain.Product) bool { return p.SKU == "A" })).
+		Return(domain.Product{}, true, nil).Once()
+	mockRepo.On("UpsertBySKU", mock.Anything, mock.MatchedBy(func(p domain.Product) bool { return p.SKU == "B" })).
+		Return(domain.Product{}, false, apperror.NewConflictError("O código de barras '1' já pertence a outro produto.")).Once()
+
+	report, err := svc.ImportProducts(context.Background(), domain.ImportFormatNDJSON, strings.NewReader(input), false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, []domain.ImportRowError{{Row: 2, SKU: "B", Message: "Conflito de estado: O código de barras '1' já pertence a outro produto."}}, report.Errors)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestImportProducts_Fail_InvalidInput testa formatos e cabeçalhos inválidos.
+func TestImportProducts_Fail_InvalidInput(t *testing.T) {
+	svc := productservice.NewService(new(MockProductRepository), logger.NewLogger("debug"))
+
+	_, err := svc.ImportProducts(context.Background(), domain.ImportFormat("xlsx"), strings.NewReader(""), true)
+	assert.IsType(t, &apperror.ValidationError{}, err)
+
+	_, err = svc.ImportProducts(context.Background(), domain.ImportFormatCSV, strings.NewReader("sku,name\nA,B\n"), true)
+	assert.IsType(t, &apperror.ValidationError{}, err)
+	assert.Contains(t, err.Error(), "'price'")
+}
+
+// TestStartProductImport_BackgroundJob testa o processamento em segundo plano e a consulta de status.
+func TestStartProductImport_BackgroundJob(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+	mockRepo.On("FindAttributeDefinitions", mock.Anything).Return([]domain.AttributeDefinition{}, nil)
+
+	mockRepo.On("UpsertBySKU", mock.Anything, mock.Anything).Return(domain.Product{}, true, nil)
+
+	report, err := svc.StartProductImport(context.Background(), domain.ImportFormatCSV, strings.NewReader(importCSV), false)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.ImportStatusPending, report.Status)
+
+	var job domain.ImportReport
+	assert.Eventually(t, func() bool {
+		job, err = svc.GetImportJob(context.Background(), report.JobID)
+		return err == nil && job.Status == domain.ImportStatusCompleted
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, job.Created)
+	assert.Equal(t, 2, job.Failed)
+	assert.NotNil(t, job.FinishedAt)
+
+	_, err = svc.GetImportJob(context.Background(), "00000000-0000-0000-0000-000000000000")
+	assert.IsType(t, &apperror.NotFoundError{}, err)
+}
+
+// TestGetImportJob_ScopedToTenant testa que o status de um job só é visível para a empresa que o criou.
+func TestGetImportJob_ScopedToTenant(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+	mockRepo.On("FindAttributeDefinitions", mock.Anything).Return([]domain.AttributeDefinition{}, nil)
+	mockRepo.On("UpsertBySKU", mock.Anything, mock.Anything).Return(domain.Product{}, true, nil)
+
+	tenantA := tenant.WithID(context.Background(), "11111111-1111-1111-1111-111111111111")
+	tenantB := tenant.WithID(context.Background(), "22222222-2222-2222-2222-222222222222")
+
+	report, err := svc.StartProductImport(tenantA, domain.ImportFormatCSV, strings.NewReader(importCSV), false)
+	if err != nil {
+		t.Fatalf("falha ao criar job: %v", err)
+	}
+
+	assert.Eventually(t, func() bool {
+		job, err := svc.GetImportJob(tenantA, report.JobID)
+		return err == nil && job.Status == domain.ImportStatusCompleted
+	}, 2*time.Second, 10*time.Millisecond)
+
+	_, err = svc.GetImportJob(tenantB, report.JobID)
+	assert.IsType(t, &apperror.NotFoundError{}, err, "Outra empresa não deve ver o job")
+
+	_, err = svc.GetImportJob(context.Background(), report.JobID)
+	assert.IsType(t, &apperror.NotFoundError{}, err, "Requisições sem tenant (tenant padrão) não devem ver o job")
+}
+
+// TestStartProductImport_LogsWithRequestID testa que o log de conclusão do job em segundo plano
+// traz o ID da requisição que criou o job.
+func TestStartProductImport_LogsWithRequestID(t *testing.T) {
+	var output bytes.Buffer
+	log.SetOutput(&output)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	mockRepo := new(MockProductRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("info"))
+	mockRepo.On("FindAttributeDefinitions", mock.Anything).Return([]domain.AttributeDefinition{}, nil)
+	mockRepo.On("UpsertBySKU", mock.Anything, mock.Anything).Return(domain.Product{}, true, nil)
+
+	ctx := requestid.WithID(context.Background(), "req-import-1")
+	report, err := svc.StartProductImport(ctx, domain.ImportFormatCSV, strings.NewReader(importCSV), false)
+	if err != nil {
+		t.Fatalf("falha ao criar job: %v", err)
+	}
+	// O relatório final é publicado depois do log de conclusão
+	assert.Eventually(t, func() bool {
+		job, err := svc.GetImportJob(ctx, report.JobID)
+		return err == nil && job.Status == domain.ImportStatusCompleted
+	}, 2*time.Second, 10*time.Millisecond)
+
+	var finished *logger.LogEntry
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		var entry logger.LogEntry
+		if json.Unmarshal([]byte(line), &entry) == nil && entry.Message == "Importação de produtos concluída." {
+			finished = &entry
+		}
+	}
+	if assert.NotNil(t, finished, "Log de conclusão não encontrado") {
+		assert.Equal(t, "req-import-1", finished.RequestID)
+		assert.Equal(t, report.JobID, finished.Fields["job_id"])
+	}
+}
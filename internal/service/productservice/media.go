@@ -0,0 +1,229 @@
+package productservice
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/tenant"
+)
+
+// MediaRepository define as operações de mídias de produto. Assim como o histórico, é uma
+// capacidade opcional do repositório: sem ela, os endpoints de mídia retornam erro e a busca
+// por ID não inclui mídias.
+type MediaRepository interface {
+	InsertProductMedia(ctx context.Context, media domain.ProductMedia) (domain.ProductMedia, error)
+	FindProductMedia(ctx context.Context, productID string) ([]domain.ProductMedia, error)
+	FindProductMediaByID(ctx context.Context, id string) (domain.ProductMedia, error)
+	UpdateProductMedia(ctx context.Context, media domain.ProductMedia) (domain.ProductMedia, error)
+	DeleteProductMedia(ctx context.Context, id string) error
+}
+
+// MediaStorage grava os arquivos enviados por upload (ex.: storage.LocalStorage).
+// name é um caminho relativo; Save retorna a URL pública do arquivo.
+type MediaStorage interface {
+	Save(name string, src io.Reader) (string, error)
+	Delete(name string) error
+}
+
+// uploadContentTypes são os tipos de arquivo aceitos no upload e a extensão usada ao gravá-los.
+var uploadContentTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// maxMediaAltText limita o texto alternativo de uma mídia.
+const maxMediaAltText = 255
+
+// SetMediaStorage habilita o upload de arquivos de mídia para o armazenamento informado.
+func (s *Service) SetMediaStorage(storage MediaStorage) {
+	s.storage = storage
+}
+
+// GetProductMedia lista as mídias de um produto em ordem de exibição.
+func (s *Service) GetProductMedia(ctx domain.Context, productID string) ([]domain.ProductMedia, error) {
+	ctxGo, err := s.mediaContext(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	return s.media.FindProductMedia(ctxGo, productID)
+}
+
+// AddProductMedia cadastra uma mídia externa (URL http/https) para o produto.
+func (s *Service) AddProductMedia(ctx domain.Context, media domain.ProductMedia) (domain.ProductMedia, error) {
+	ctxGo, err := s.mediaContext(ctx, media.ProductID)
+	if err != nil {
+		return domain.ProductMedia{}, err
+	}
+
+	media.URL = strings.TrimSpace(media.URL)
+	parsed, parseErr := url.Parse(media.URL)
+	if parseErr != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return domain.ProductMedia{}, apperror.NewValidationError("A 'url' da mídia deve ser um endereço http ou https absoluto.")
+	}
+	media.ID, media.Path = "", ""
+	if err := s.validateMediaMetadata(ctxGo, &media); err != nil {
+		return domain.ProductMedia{}, err
+	}
+
+	return s.media.InsertProductMedia(ctxGo, media)
+}
+
+// UploadProductMedia grava uma imagem enviada por upload no armazenamento local e a cadastra
+// como mídia do produto. O tipo é detectado pelo conteúdo (JPEG, PNG, GIF ou WebP).
+func (s *Service) UploadProductMedia(ctx domain.Context, productID string, upload domain.MediaUpload, src io.Reader) (domain.ProductMedia, error) {
+	ctxGo, err := s.mediaContext(ctx, productID)
+	if err != nil {
+		return domain.ProductMedia{}, err
+	}
+	if s.storage == nil {
+		return domain.ProductMedia{}, apperror.NewValidationError("O upload de arquivos não está habilitado; cadastre a mídia por URL.")
+	}
+
+	media := domain.ProductMedia{ProductID: productID, VariantID: upload.VariantID, AltText: upload.AltText, SortOrder: upload.SortOrder}
+	if err := s.validateMediaMetadata(ctxGo, &media); err != nil {
+		return domain.ProductMedia{}, err
+	}
+
+	// O tipo é detectado pelos primeiros bytes, sem confiar na extensão ou no Content-Type do cliente.
+	reader := bufio.NewReaderSize(src, 512)
+	head, _ := reader.Peek(512)
+	media.ContentType = http.DetectContentType(head)
+	ext, ok := uploadContentTypes[media.ContentType]
+	if !ok {
+		return domain.ProductMedia{}, apperror.NewValidationError(fmt.Sprintf("Tipo de arquivo '%s' não suportado. Envie JPEG, PNG, GIF ou WebP.", media.ContentType))
+	}
+
+	media.ID = uuid.New().String()
+	// Cada empresa tem o seu diretório; o handler de /media/ só serve os arquivos do tenant da requisição
+	media.Path = tenant.ID(ctxGo) + "/" + productID + "/" + media.ID + ext
+	if media.URL, err = s.storage.Save(media.Path, reader); err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao gravar arquivo de mídia.", err)
+		return domain.ProductMedia{}, apperror.NewInternalError("Falha ao gravar o arquivo enviado.", err)
+	}
+
+	created, err := s.media.InsertProductMedia(ctxGo, media)
+	if err != nil {
+		// Sem o registro, o arquivo ficaria órfão no diretório
+		if delErr := s.storage.Delete(media.Path); delErr != nil {
+			s.logger.WithContext(ctx).Warn("Falha ao remover arquivo de mídia órfão.", map[string]interface{}{"path": media.Path, "error": delErr.Error()})
+		}
+		return domain.ProductMedia{}, err
+	}
+
+	s.logger.WithContext(ctx).Info("Mídia enviada para o produto.", map[string]interface{}{"product_id": productID, "media_id": created.ID, "filename": upload.Filename, "size": upload.Size})
+	return created, nil
+}
+
+// UpdateProductMedia altera a variante associada, o texto alternativo e a ordem de uma mídia do produto.
+func (s *Service) UpdateProductMedia(ctx domain.Context, media domain.ProductMedia) (domain.ProductMedia, error) {
+	ctxGo, err := s.mediaContext(ctx, media.ProductID)
+	if err != nil {
+		return domain.ProductMedia{}, err
+	}
+	if _, err := s.findProductMedia(ctxGo, media.ProductID, media.ID); err != nil {
+		return domain.ProductMedia{}, err
+	}
+	if err := s.validateMediaMetadata(ctxGo, &media); err != nil {
+		return domain.ProductMedia{}, err
+	}
+	return s.media.UpdateProductMedia(ctxGo, media)
+}
+
+// DeleteProductMedia remove uma mídia do produto e, se for um arquivo enviado, o arquivo local.
+func (s *Service) DeleteProductMedia(ctx domain.Context, productID, mediaID string) error {
+	ctxGo, err := s.mediaContext(ctx, productID)
+	if err != nil {
+		return err
+	}
+	media, err := s.findProductMedia(ctxGo, productID, mediaID)
+	if err != nil {
+		return err
+	}
+	if err := s.media.DeleteProductMedia(ctxGo, mediaID); err != nil {
+		return err
+	}
+
+	if media.Path != "" && s.storage != nil {
+		if err := s.storage.Delete(media.Path); err != nil {
+			// O registro já foi removido; o arquivo restante não é visível pela API.
+			s.logger.WithContext(ctx).Warn("Falha ao remover arquivo de mídia.", map[string]interface{}{"path": media.Path, "error": err.Error()})
+		}
+	}
+	s.logger.WithContext(ctx).Info("Mídia do produto removida.", map[string]interface{}{"product_id": productID, "media_id": mediaID})
+	return nil
+}
+
+// mediaContext valida o ID do produto, confere se o repositório suporta mídias e converte o contexto.
+func (s *Service) mediaContext(ctx domain.Context, productID string) (context.Context, error) {
+	if _, err := uuid.Parse(productID); err != nil {
+		return nil, apperror.NewValidationError("O ID do produto deve ser um UUID válido.")
+	}
+	if s.media == nil {
+		return nil, apperror.NewInternalError("Mídias de produto não são suportadas pelo repositório.", nil)
+	}
+
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background()", nil)
+	}
+	return ctxGo, nil
+}
+
+// findProductMedia busca uma mídia e confere se ela pertence ao produto.
+func (s *Service) findProductMedia(ctx context.Context, productID, mediaID string) (domain.ProductMedia, error) {
+	if _, err := uuid.Parse(mediaID); err != nil {
+		return domain.ProductMedia{}, apperror.NewValidationError("O ID da mídia deve ser um UUID válido.")
+	}
+	media, err := s.media.FindProductMediaByID(ctx, mediaID)
+	if err != nil {
+		return domain.ProductMedia{}, err
+	}
+	if media.ProductID != productID {
+		return domain.ProductMedia{}, apperror.NewNotFoundError(fmt.Sprintf("Mídia com ID %s não encontrada no produto.", mediaID))
+	}
+	return media, nil
+}
+
+// validateMediaMetadata confere o texto alternativo e a ordem e, quando há variant_id, se a
+// variante pertence ao produto (o que também confirma a existência do produto).
+func (s *Service) validateMediaMetadata(ctx context.Context, media *domain.ProductMedia) error {
+	media.AltText = strings.TrimSpace(media.AltText)
+	media.VariantID = strings.TrimSpace(media.VariantID)
+	if len(media.AltText) > maxMediaAltText {
+		return apperror.NewValidationError(fmt.Sprintf("O texto alternativo deve ter no máximo %d caracteres.", maxMediaAltText))
+	}
+	if media.SortOrder < 0 {
+		return apperror.NewValidationError("A ordem ('sort_order') não pode ser negativa.")
+	}
+	if media.VariantID == "" {
+		return nil
+	}
+
+	product, err := s.repo.FindByID(ctx, media.ProductID)
+	if err != nil {
+		var notFound *apperror.NotFoundError
+		if errors.As(err, &notFound) {
+			return apperror.NewNotFoundError(fmt.Sprintf("Produto com ID %s não foi encontrado.", media.ProductID))
+		}
+		return err
+	}
+	for _, v := range product.Variants {
+		if v.ID == media.VariantID {
+			return nil
+		}
+	}
+	return apperror.NewValidationError(fmt.Sprintf("A variante %s não pertence ao produto.", media.VariantID))
+}
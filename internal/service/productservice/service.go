@@ -3,6 +3,8 @@ package productservice
 import (
 	"context" // Necessário para o casting e chamadas de infraestrutura
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	// Importar o pacote errors nativo (para errors.Is e errors.Unwrap)
@@ -13,6 +15,7 @@ import (
 	"gostock/internal/domain"
 	apperror "gostock/internal/errors" // 🚨 CORREÇÃO: Usar o nome renomeado para evitar conflito
 	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/validation"
 )
 
 // ProductRepository define o contrato (interface) que este Serviço espera
@@ -24,35 +27,92 @@ type ProductRepository interface {
 	Save(ctx context.Context, product domain.Product) (domain.Product, error)
 	FindByID(ctx context.Context, id string) (domain.Product, error)
 	FindAll(ctx context.Context, filter domain.ProductFilter) ([]domain.Product, error)
+	UpsertBySKU(ctx context.Context, product domain.Product) (domain.Product, bool, error)
+	StreamExportRows(ctx context.Context, filter domain.ProductFilter, fn func(domain.ProductExportRow) error) error
+
+	// Códigos de barras (principal e adicionais)
+	FindVariantByBarcode(ctx context.Context, code string) (domain.BarcodeLookup, error)
+	InsertVariantBarcode(ctx context.Context, barcode domain.VariantBarcode) (domain.VariantBarcode, error)
+	FindVariantBarcodes(ctx context.Context, variantID string) ([]domain.VariantBarcode, error)
+	DeleteVariantBarcode(ctx context.Context, code string) error
+
+	// Definições de atributos de variantes
+	CreateAttributeDefinition(ctx context.Context, def domain.AttributeDefinition) (domain.AttributeDefinition, error)
+	FindAttributeDefinitionByID(ctx context.Context, id string) (domain.AttributeDefinition, error)
+	FindAttributeDefinitions(ctx context.Context) ([]domain.AttributeDefinition, error)
+	UpdateAttributeDefinition(ctx context.Context, def domain.AttributeDefinition) (domain.AttributeDefinition, error)
+	DeleteAttributeDefinition(ctx context.Context, id string) error
+
+	// Esquemas de atributos personalizados por categoria
+	UpsertCategorySchema(ctx context.Context, schema domain.CategorySchema) (domain.CategorySchema, error)
+	FindCategorySchema(ctx context.Context, category string) (domain.CategorySchema, error)
+	FindCategorySchemas(ctx context.Context) ([]domain.CategorySchema, error)
+	DeleteCategorySchema(ctx context.Context, category string) error
+	UpdateProductCustomAttributes(ctx context.Context, product domain.Product) (domain.Product, error)
 }
 
 // Service é a estrutura que implementa a interface domain.ProductService.
 type Service struct {
-	repo   ProductRepository
-	logger logger.Logger
+	repo    ProductRepository
+	logger  logger.Logger
+	imports *importJobStore   // Jobs de importação em andamento/finalizados
+	history HistoryRepository // nil se o repositório não suportar histórico de versões
+	media   MediaRepository   // nil se o repositório não suportar mídias de produto
+	storage MediaStorage      // nil se o upload de arquivos não estiver configurado
 }
 
 // NewService cria e retorna uma nova instância do Serviço de Produto.
 func NewService(repo ProductRepository, logger logger.Logger) *Service {
-	return &Service{repo: repo, logger: logger}
+	s := &Service{repo: repo, logger: logger, imports: newImportJobStore()}
+	if history, ok := repo.(HistoryRepository); ok {
+		s.history = history
+	}
+	if media, ok := repo.(MediaRepository); ok {
+		s.media = media
+	}
+	return s
 }
 
 // --- Implementação: CreateProduct ---
 func (s *Service) CreateProduct(ctx domain.Context, product domain.Product, variants []domain.Variant) (domain.Product, error) {
-	s.logger.Debug("Iniciando criação de produto no serviço.", map[string]interface{}{"sku": product.SKU})
+	s.logger.WithContext(ctx).Debug("Iniciando criação de produto no serviço.", map[string]interface{}{"sku": product.SKU})
 
 	product.Variants = variants
 
+	// 1. Casting e Contexto
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background()", nil)
+	}
+
 	// 🚨 NOVO: 1. Validação de Domínio
+	normalizeVariants(product.Variants)
 	if err := s.validateProduct(product); err != nil {
-		s.logger.Warn("Falha na validação do produto ao criar.", map[string]interface{}{"sku": product.SKU, "error": err.Error()})
+		s.logger.WithContext(ctx).Warn("Falha na validação do produto ao criar.", map[string]interface{}{"sku": product.SKU, "error": err.Error()})
+		return domain.Product{}, err
+	}
+
+	// Combinações de atributos das variantes, conferidas com as definições de atributos
+	defs, err := s.attributeDefinitions(ctxGo)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	if err := applyAttributeDefinitions(product.Variants, defs); err != nil {
+		s.logger.WithContext(ctx).Warn("Atributos de variantes inválidos ao criar produto.", map[string]interface{}{"sku": product.SKU, "error": err.Error()})
+		return domain.Product{}, err
+	}
+
+	// Atributos personalizados, conferidos com o esquema da categoria
+	if err := s.applyCategorySchema(ctxGo, &product, nil); err != nil {
+		s.logger.WithContext(ctx).Warn("Atributos personalizados inválidos ao criar produto.", map[string]interface{}{"sku": product.SKU, "error": err.Error()})
 		return domain.Product{}, err
 	}
 
 	// 2. Geração de IDs (se a variação não tiver ID, o serviço a define)
 	if product.ID == "" {
 		product.ID = uuid.New().String()
-		s.logger.Debug("Gerado novo ID para o produto.", map[string]interface{}{"product_id": product.ID})
+		s.logger.WithContext(ctx).Debug("Gerado novo ID para o produto.", map[string]interface{}{"product_id": product.ID})
 	}
 	product.IsActive = true
 	now := time.Now().UTC()
@@ -62,38 +122,33 @@ func (s *Service) CreateProduct(ctx domain.Context, product domain.Product, vari
 	for i := range product.Variants {
 		if product.Variants[i].ID == "" {
 			product.Variants[i].ID = uuid.New().String()
-			s.logger.Debug("Gerado novo ID para a variante.", map[string]interface{}{"variant_id": product.Variants[i].ID})
+			s.logger.WithContext(ctx).Debug("Gerado novo ID para a variante.", map[string]interface{}{"variant_id": product.Variants[i].ID})
 		}
 		// Linkar a chave estrangeira (ProductID)
 		product.Variants[i].ProductID = product.ID
 	}
 
-	// 1. Casting e Contexto
-	ctxGo, ok := ctx.(context.Context)
-	if !ok {
-		ctxGo = context.Background()
-		s.logger.Warn("Contexto de domínio inválido, usando context.Background()", nil)
-	}
-
 	// 3. Delegação para a Camada de Persistência (Repository)
 	createdProduct, err := s.repo.Save(ctxGo, product) // Chamada com ctxGo
 	if err != nil {
-		s.logger.Error("Falha ao salvar produto no repositório.", err)
+		s.logger.WithContext(ctx).Error("Falha ao salvar produto no repositório.", err)
 		// Propaga o erro retornado pelo Repositório (que deve ser um apperror.InternalError ou similar)
 		return domain.Product{}, fmt.Errorf("falha ao salvar produto no repositório: %w", err)
 	}
 
-	s.logger.Info("Produto criado com sucesso.", map[string]interface{}{"product_id": createdProduct.ID, "sku": createdProduct.SKU})
+	s.recordVersion(ctxGo, createdProduct, domain.ProductChangeCreate, nil)
+
+	s.logger.WithContext(ctx).Info("Produto criado com sucesso.", map[string]interface{}{"product_id": createdProduct.ID, "sku": createdProduct.SKU})
 	return createdProduct, nil
 }
 
 // --- Implementação: GetProductByID (Única e Corrigida) ---
 func (s *Service) GetProductByID(ctx domain.Context, id string) (domain.Product, error) {
-	s.logger.Debug("Iniciando busca de produto por ID no serviço.", map[string]interface{}{"product_id_attempt": id})
+	s.logger.WithContext(ctx).Debug("Iniciando busca de produto por ID no serviço.", map[string]interface{}{"product_id_attempt": id})
 
 	// 1. Validação de Formato (Business Logic)
 	if _, err := uuid.Parse(id); err != nil {
-		s.logger.Warn("ID de produto inválido fornecido.", map[string]interface{}{"product_id_provided": id, "error": err.Error()})
+		s.logger.WithContext(ctx).Warn("ID de produto inválido fornecido.", map[string]interface{}{"product_id_provided": id, "error": err.Error()})
 		return domain.Product{}, apperror.NewValidationError("O ID do produto deve ser um UUID válido.")
 	}
 
@@ -101,7 +156,7 @@ func (s *Service) GetProductByID(ctx domain.Context, id string) (domain.Product,
 	ctxGo, ok := ctx.(context.Context)
 	if !ok {
 		ctxGo = context.Background()
-		s.logger.Warn("Contexto de domínio inválido, usando context.Background()", nil)
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background()", nil)
 	}
 
 	// 3. Delegação para o Repositório
@@ -114,72 +169,88 @@ func (s *Service) GetProductByID(ctx domain.Context, id string) (domain.Product,
 		// 🚨 CORREÇÃO: Usar errors.Is do pacote nativo Go para verificar a cadeia de erros
 		var notFound *apperror.NotFoundError
 		if errors.Is(err, notFound) {
-			s.logger.Info("Produto não encontrado.", map[string]interface{}{"product_id": id})
+			s.logger.WithContext(ctx).Info("Produto não encontrado.", map[string]interface{}{"product_id": id})
 			// Se o Repositório retornou NotFound, retornamos o erro de negócio 404.
 			return domain.Product{}, apperror.NewNotFoundError(fmt.Sprintf("Produto com ID %s não foi encontrado.", id))
 		}
 
-		s.logger.Error("Erro ao buscar produto no repositório.", err)
+		s.logger.WithContext(ctx).Error("Erro ao buscar produto no repositório.", err)
 		// Para qualquer outro erro (DB falhou, conexão perdida - 500), propagamos o erro de infraestrutura.
 		return domain.Product{}, err
 	}
 
-	s.logger.Info("Produto encontrado com sucesso.", map[string]interface{}{"product_id": product.ID, "sku": product.SKU})
+	// Mídias (imagens) do produto, quando o repositório as suporta
+	if s.media != nil {
+		if product.Media, err = s.media.FindProductMedia(ctxGo, product.ID); err != nil {
+			s.logger.WithContext(ctx).Error("Erro ao buscar mídias do produto.", err)
+			return domain.Product{}, err
+		}
+	}
+
+	s.logger.WithContext(ctx).Info("Produto encontrado com sucesso.", map[string]interface{}{"product_id": product.ID, "sku": product.SKU})
 	// 5. Sucesso
 	return product, nil
 }
 
-// validateProduct verifica as regras de negócio básicas do produto e suas variações.
+// validateProduct verifica as regras de negócio básicas do produto e suas variações. As regras de
+// campo vêm das tags validate de domain.Product; as que envolvem mais de um campo ou variação são
+// verificadas aqui. Todos os campos inválidos são reportados juntos.
 func (s *Service) validateProduct(p domain.Product) error {
-	if p.SKU == "" {
-		return apperror.NewValidationError("O SKU do produto é obrigatório.")
-	}
-	if p.Name == "" {
-		return apperror.NewValidationError("O nome do produto é obrigatório.")
-	}
-	if p.Price <= 0 {
-		return apperror.NewValidationError("O preço do produto deve ser um valor positivo.")
+	fields := validation.Fields(p)
+	invalid := func(field, msg string) {
+		fields = append(fields, apperror.FieldError{Field: field, Message: msg})
 	}
 
 	// Validação das Variações
 	if len(p.Variants) == 0 {
-		return apperror.NewValidationError("O produto deve ter pelo menos uma variação.")
+		invalid("variants", "O produto deve ter pelo menos uma variação.")
 	}
 
+	barcodes := make(map[string]bool, len(p.Variants))
+	combinations := make(map[string]bool, len(p.Variants))
 	for i, v := range p.Variants {
-		if v.Attribute == "" || v.Value == "" {
-			return apperror.NewValidationError(fmt.Sprintf("Atributo ou valor da variação %d está vazio.", i+1))
+		path := fmt.Sprintf("variants[%d]", i)
+		if len(v.Attributes) == 0 {
+			invalid(path+".attributes", fmt.Sprintf("Atributo ou valor da variação %d está vazio.", i+1))
 		}
-		if v.PriceDiff < 0 {
-			return apperror.NewValidationError(fmt.Sprintf("A diferença de preço da variação %d não pode ser negativa.", i+1))
+		for name, value := range v.Attributes {
+			if name == "" || value == "" {
+				invalid(path+".attributes", fmt.Sprintf("Atributo ou valor da variação %d está vazio.", i+1))
+				break
+			}
+		}
+		if len(v.Attributes) > 0 {
+			if combinations[v.CombinationKey()] {
+				invalid(path+".attributes", fmt.Sprintf("A combinação de atributos '%s' da variação %d está repetida no produto.", v.Value, i+1))
+			}
+			combinations[v.CombinationKey()] = true
 		}
 		if v.Barcode == "" {
-			return apperror.NewValidationError(fmt.Sprintf("O código de barras da variação %d é obrigatório.", i+1))
+			continue // Reportado pela tag required
+		}
+		if err := domain.ValidateBarcode(v.Barcode); err != nil {
+			invalid(path+".barcode", fmt.Sprintf("Variação %d: %s.", i+1, err.Error()))
+		} else if barcodes[v.Barcode] {
+			invalid(path+".barcode", fmt.Sprintf("O código de barras '%s' está repetido em mais de uma variação.", v.Barcode))
 		}
+		barcodes[v.Barcode] = true
 	}
 
-	return nil
+	return validation.Error(fields)
 }
 
 // --- Implementação: GetProducts ---
 func (s *Service) GetProducts(ctx domain.Context, page, limit int, filters map[string]string) ([]domain.Product, error) {
-	s.logger.Debug("Iniciando listagem de produtos no serviço.", map[string]interface{}{"page": page, "limit": limit, "filters": filters})
+	s.logger.WithContext(ctx).Debug("Iniciando listagem de produtos no serviço.", map[string]interface{}{"page": page, "limit": limit, "filters": filters})
 
 	// Construir o ProductFilter a partir dos parâmetros
-	productFilter := domain.ProductFilter{
-		Page:  page,
-		Limit: limit,
-	}
-
-	if name, ok := filters["name"]; ok {
-		productFilter.Name = name
-	}
-	if sku, ok := filters["sku"]; ok {
-		productFilter.SKU = sku
-	}
-	if active, ok := filters["is_active"]; ok {
-		productFilter.ActiveOnly = (active == "true")
+	productFilter, err := s.BuildProductFilter(filters)
+	if err != nil {
+		s.logger.WithContext(ctx).Warn("Parâmetros de listagem de produtos inválidos.", map[string]interface{}{"filters": filters, "error": err.Error()})
+		return nil, err
 	}
+	productFilter.Page = page
+	productFilter.Limit = limit
 
 	// 1. Aplica Regras de Limite (Safeguarding)
 	if productFilter.Limit > 100 {
@@ -193,14 +264,14 @@ func (s *Service) GetProducts(ctx domain.Context, page, limit int, filters map[s
 	ctxGo, ok := ctx.(context.Context)
 	if !ok {
 		ctxGo = context.Background()
-		s.logger.Warn("Contexto de domínio inválido, usando context.Background() para GetProducts", nil)
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background() para GetProducts", nil)
 	}
 
 	// 3. Delegação para o Repositório
 	products, err := s.repo.FindAll(ctxGo, productFilter)
 
 	if err != nil {
-		s.logger.Error("Erro ao buscar produtos no repositório.", err)
+		s.logger.WithContext(ctx).Error("Erro ao buscar produtos no repositório.", err)
 		// Wrap errors in InternalError if they are not already translated domain errors
 		var internalErr *apperror.InternalError
 		if !errors.As(err, &internalErr) { // If it's not already an InternalError
@@ -209,6 +280,176 @@ func (s *Service) GetProducts(ctx domain.Context, page, limit int, filters map[s
 		return nil, err // If it's already an InternalError, propagate as is
 	}
 
-	s.logger.Info("Produtos listados com sucesso.", map[string]interface{}{"total_products": len(products)})
+	s.logger.WithContext(ctx).Info("Produtos listados com sucesso.", map[string]interface{}{"total_products": len(products)})
 	return products, nil
 }
+
+// BuildProductFilter traduz os parâmetros de query da listagem de produtos (name, sku,
+// is_active, ordenação e filtros avançados) para um domain.ProductFilter, sem paginação.
+// É compartilhado pela listagem e pelas exportações para que ambas aceitem os mesmos filtros.
+func (s *Service) BuildProductFilter(filters map[string]string) (domain.ProductFilter, error) {
+	var productFilter domain.ProductFilter
+
+	if name, ok := filters["name"]; ok {
+		productFilter.Name = name
+	}
+	if sku, ok := filters["sku"]; ok {
+		productFilter.SKU = sku
+	}
+	if active, ok := filters["is_active"]; ok {
+		productFilter.ActiveOnly = (active == "true")
+	}
+
+	// Ordenação e filtros avançados (preço, datas, variantes)
+	if err := s.applyAdvancedFilters(&productFilter, filters); err != nil {
+		return domain.ProductFilter{}, err
+	}
+	return productFilter, nil
+}
+
+// attributeFilterPrefix identifica os parâmetros de filtro por atributo de variante (ex.: attr.Tamanho=M).
+const attributeFilterPrefix = "attr."
+
+// customAttributeFilterPrefix identifica os parâmetros de filtro por atributo personalizado (ex.: spec.material=Algodão).
+const customAttributeFilterPrefix = "spec."
+
+// allowedSortFields lista os campos aceitos no parâmetro sort_by.
+var allowedSortFields = map[string]bool{
+	domain.ProductSortName:      true,
+	domain.ProductSortPrice:     true,
+	domain.ProductSortSKU:       true,
+	domain.ProductSortCreatedAt: true,
+	domain.ProductSortUpdatedAt: true,
+}
+
+// applyAdvancedFilters traduz os parâmetros de ordenação e filtros avançados
+// (preço, datas e variantes) para o ProductFilter, validando cada valor.
+func (s *Service) applyAdvancedFilters(f *domain.ProductFilter, filters map[string]string) error {
+	if sortBy, ok := filters["sort_by"]; ok && sortBy != "" {
+		sortBy = strings.ToLower(sortBy)
+		if !allowedSortFields[sortBy] {
+			return apperror.NewValidationError(fmt.Sprintf("Campo de ordenação '%s' não suportado. Use: name, price, sku, created_at ou updated_at.", sortBy))
+		}
+		f.SortBy = sortBy
+	}
+	if order, ok := filters["sort_order"]; ok && order != "" {
+		order = strings.ToLower(order)
+		if order != domain.SortAsc && order != domain.SortDesc {
+			return apperror.NewValidationError("O parâmetro 'sort_order' deve ser 'asc' ou 'desc'.")
+		}
+		f.SortOrder = order
+	}
+
+	var err error
+	if f.MinPrice, err = parseOptionalPrice(filters, "min_price"); err != nil {
+		return err
+	}
+	if f.MaxPrice, err = parseOptionalPrice(filters, "max_price"); err != nil {
+		return err
+	}
+	if f.MinPrice != nil && f.MaxPrice != nil && *f.MinPrice > *f.MaxPrice {
+		return apperror.NewValidationError("O parâmetro 'min_price' não pode ser maior que 'max_price'.")
+	}
+
+	if f.CreatedFrom, err = parseOptionalDate(filters, "created_from", false); err != nil {
+		return err
+	}
+	if f.CreatedTo, err = parseOptionalDate(filters, "created_to", true); err != nil {
+		return err
+	}
+	if f.UpdatedFrom, err = parseOptionalDate(filters, "updated_from", false); err != nil {
+		return err
+	}
+	if f.UpdatedTo, err = parseOptionalDate(filters, "updated_to", true); err != nil {
+		return err
+	}
+	if f.CreatedFrom != nil && f.CreatedTo != nil && f.CreatedFrom.After(*f.CreatedTo) {
+		return apperror.NewValidationError("O parâmetro 'created_from' não pode ser posterior a 'created_to'.")
+	}
+	if f.UpdatedFrom != nil && f.UpdatedTo != nil && f.UpdatedFrom.After(*f.UpdatedTo) {
+		return apperror.NewValidationError("O parâmetro 'updated_from' não pode ser posterior a 'updated_to'.")
+	}
+
+	if raw, ok := filters["has_variants"]; ok && raw != "" {
+		hasVariants, parseErr := strconv.ParseBool(raw)
+		if parseErr != nil {
+			return apperror.NewValidationError("O parâmetro 'has_variants' deve ser 'true' ou 'false'.")
+		}
+		f.HasVariants = &hasVariants
+	}
+
+	f.Barcode = filters["barcode"]
+	f.AttributeName = filters["attribute"]
+	f.AttributeValue = filters["attribute_value"]
+	if f.AttributeValue != "" && f.AttributeName == "" {
+		return apperror.NewValidationError("O parâmetro 'attribute_value' exige o parâmetro 'attribute'.")
+	}
+
+	// Parâmetros "attr.<Nome>=<valor>" filtram pela combinação de atributos de uma mesma variante
+	for key, value := range filters {
+		name, ok := strings.CutPrefix(key, attributeFilterPrefix)
+		if !ok {
+			continue
+		}
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if name == "" || value == "" {
+			return apperror.NewValidationError(fmt.Sprintf("O filtro '%s' deve ter o formato '%s<Nome>=<valor>'.", key, attributeFilterPrefix))
+		}
+		if f.Attributes == nil {
+			f.Attributes = make(map[string]string)
+		}
+		f.Attributes[name] = value
+	}
+
+	// Categoria e parâmetros "spec.<campo>=<valor>" sobre os atributos personalizados do produto
+	f.Category = strings.TrimSpace(filters["category"])
+	for key, value := range filters {
+		name, ok := strings.CutPrefix(key, customAttributeFilterPrefix)
+		if !ok {
+			continue
+		}
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if name == "" || value == "" {
+			return apperror.NewValidationError(fmt.Sprintf("O filtro '%s' deve ter o formato '%s<campo>=<valor>'.", key, customAttributeFilterPrefix))
+		}
+		if f.CustomAttributes == nil {
+			f.CustomAttributes = make(map[string]string)
+		}
+		f.CustomAttributes[name] = value
+	}
+
+	return nil
+}
+
+// parseOptionalPrice lê um preço opcional dos filtros. Ausente retorna nil.
+func parseOptionalPrice(filters map[string]string, key string) (*float64, error) {
+	raw, ok := filters[key]
+	if !ok || raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || value < 0 {
+		return nil, apperror.NewValidationError(fmt.Sprintf("O parâmetro '%s' deve ser um número não negativo.", key))
+	}
+	return &value, nil
+}
+
+// parseOptionalDate lê uma data opcional dos filtros, aceitando RFC3339 ou AAAA-MM-DD.
+// Para datas sem horário usadas como limite superior (endOfDay), o dia inteiro é incluído.
+func parseOptionalDate(filters map[string]string, key string, endOfDay bool) (*time.Time, error) {
+	raw, ok := filters[key]
+	if !ok || raw == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return nil, apperror.NewValidationError(fmt.Sprintf("O parâmetro '%s' deve ser uma data no formato AAAA-MM-DD ou RFC3339.", key))
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &t, nil
+}
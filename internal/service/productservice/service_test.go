@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -35,6 +36,94 @@ func (m *MockProductRepository) FindAll(ctx context.Context, filter domain.Produ
 	return args.Get(0).([]domain.Product), args.Error(1)
 }
 
+func (m *MockProductRepository) UpsertBySKU(ctx context.Context, product domain.Product) (domain.Product, bool, error) {
+	args := m.Called(ctx, product)
+	return args.Get(0).(domain.Product), args.Bool(1), args.Error(2)
+}
+
+func (m *MockProductRepository) StreamExportRows(ctx context.Context, filter domain.ProductFilter, fn func(domain.ProductExportRow) error) error {
+	args := m.Called(ctx, filter)
+	// Linhas configuradas no mock são entregues ao callback, como faria o repositório.
+	if rows, ok := args.Get(0).([]domain.ProductExportRow); ok {
+		for _, row := range rows {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockProductRepository) FindVariantByBarcode(ctx context.Context, code string) (domain.BarcodeLookup, error) {
+	args := m.Called(ctx, code)
+	return args.Get(0).(domain.BarcodeLookup), args.Error(1)
+}
+
+func (m *MockProductRepository) InsertVariantBarcode(ctx context.Context, barcode domain.VariantBarcode) (domain.VariantBarcode, error) {
+	args := m.Called(ctx, barcode)
+	return args.Get(0).(domain.VariantBarcode), args.Error(1)
+}
+
+func (m *MockProductRepository) FindVariantBarcodes(ctx context.Context, variantID string) ([]domain.VariantBarcode, error) {
+	args := m.Called(ctx, variantID)
+	return args.Get(0).([]domain.VariantBarcode), args.Error(1)
+}
+
+func (m *MockProductRepository) DeleteVariantBarcode(ctx context.Context, code string) error {
+	args := m.Called(ctx, code)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) CreateAttributeDefinition(ctx context.Context, def domain.AttributeDefinition) (domain.AttributeDefinition, error) {
+	args := m.Called(ctx, def)
+	return args.Get(0).(domain.AttributeDefinition), args.Error(1)
+}
+
+func (m *MockProductRepository) FindAttributeDefinitionByID(ctx context.Context, id string) (domain.AttributeDefinition, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.AttributeDefinition), args.Error(1)
+}
+
+func (m *MockProductRepository) FindAttributeDefinitions(ctx context.Context) ([]domain.AttributeDefinition, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.AttributeDefinition), args.Error(1)
+}
+
+func (m *MockProductRepository) UpdateAttributeDefinition(ctx context.Context, def domain.AttributeDefinition) (domain.AttributeDefinition, error) {
+	args := m.Called(ctx, def)
+	return args.Get(0).(domain.AttributeDefinition), args.Error(1)
+}
+
+func (m *MockProductRepository) DeleteAttributeDefinition(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) UpsertCategorySchema(ctx context.Context, schema domain.CategorySchema) (domain.CategorySchema, error) {
+	args := m.Called(ctx, schema)
+	return args.Get(0).(domain.CategorySchema), args.Error(1)
+}
+
+func (m *MockProductRepository) FindCategorySchema(ctx context.Context, category string) (domain.CategorySchema, error) {
+	args := m.Called(ctx, category)
+	return args.Get(0).(domain.CategorySchema), args.Error(1)
+}
+
+func (m *MockProductRepository) FindCategorySchemas(ctx context.Context) ([]domain.CategorySchema, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.CategorySchema), args.Error(1)
+}
+
+func (m *MockProductRepository) DeleteCategorySchema(ctx context.Context, category string) error {
+	args := m.Called(ctx, category)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) UpdateProductCustomAttributes(ctx context.Context, product domain.Product) (domain.Product, error) {
+	args := m.Called(ctx, product)
+	return args.Get(0).(domain.Product), args.Error(1)
+}
+
 // TestGetProducts_Success_NoFilters testa a busca de produtos sem filtros.
 func TestGetProducts_Success_NoFilters(t *testing.T) {
 	mockRepo := new(MockProductRepository)
@@ -182,5 +271,102 @@ func TestGetProducts_InvalidPageOrLimit(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+// TestGetProducts_Success_SortAndAdvancedFilters testa a tradução dos parâmetros de ordenação e filtros avançados.
+func TestGetProducts_Success_SortAndAdvancedFilters(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockLogger := logger.NewLogger("debug")
 
+	svc := productservice.NewService(mockRepo, mockLogger)
 
+	filters := map[string]string{
+		"sort_by":         "PRICE",
+		"sort_order":      "desc",
+		"min_price":       "10.5",
+		"max_price":       "99",
+		"created_from":    "2025-01-01",
+		"created_to":      "2025-01-31",
+		"updated_from":    "2025-02-01T10:00:00Z",
+		"has_variants":    "true",
+		"barcode":         "7891234567890",
+		"attribute":       "Cor",
+		"attribute_value": "Vermelho",
+	}
+
+	minPrice, maxPrice := 10.5, 99.0
+	createdFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	createdTo := time.Date(2025, 1, 31, 23, 59, 59, 999999999, time.UTC)
+	updatedFrom := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+	hasVariants := true
+	expectedFilter := domain.ProductFilter{
+		Page:           1,
+		Limit:          10,
+		SortBy:         domain.ProductSortPrice,
+		SortOrder:      domain.SortDesc,
+		MinPrice:       &minPrice,
+		MaxPrice:       &maxPrice,
+		CreatedFrom:    &createdFrom,
+		CreatedTo:      &createdTo,
+		UpdatedFrom:    &updatedFrom,
+		HasVariants:    &hasVariants,
+		Barcode:        "7891234567890",
+		AttributeName:  "Cor",
+		AttributeValue: "Vermelho",
+	}
+
+	mockRepo.On("FindAll", mock.Anything, expectedFilter).Return([]domain.Product{}, nil)
+
+	_, err := svc.GetProducts(context.Background(), 1, 10, filters)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGetProducts_Fail_InvalidAdvancedFilters testa a rejeição de parâmetros de ordenação/filtro inválidos.
+func TestGetProducts_Fail_InvalidAdvancedFilters(t *testing.T) {
+	cases := map[string]map[string]string{
+		"campo de ordenação fora da whitelist": {"sort_by": "description; DROP TABLE products"},
+		"direção de ordenação inválida":        {"sort_by": "name", "sort_order": "up"},
+		"preço não numérico":                   {"min_price": "abc"},
+		"preço negativo":                       {"max_price": "-1"},
+		"faixa de preço invertida":             {"min_price": "50", "max_price": "10"},
+		"data inválida":                        {"created_from": "31/01/2025"},
+		"faixa de datas invertida":             {"updated_from": "2025-03-01", "updated_to": "2025-02-01"},
+		"has_variants inválido":                {"has_variants": "maybe"},
+		"valor de atributo sem atributo":       {"attribute_value": "Vermelho"},
+	}
+
+	for name, filters := range cases {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(MockProductRepository)
+			svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+
+			_, err := svc.GetProducts(context.Background(), 1, 10, filters)
+
+			assert.Error(t, err)
+			assert.IsType(t, &apperror.ValidationError{}, err)
+			mockRepo.AssertNotCalled(t, "FindAll", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// TestCreateProduct_Fail_ReportsAllInvalidFields testa que os campos inválidos do produto e das
+// variações são reportados juntos, identificados pelo caminho no JSON.
+func TestCreateProduct_Fail_ReportsAllInvalidFields(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	svc := productservice.NewService(mockRepo, logger.NewLogger("debug"))
+
+	_, err := svc.CreateProduct(context.Background(),
+		domain.Product{Name: "Camiseta", Price: -1},
+		[]domain.Variant{{Attribute: "Cor", Value: "Azul", PriceDiff: -2}})
+
+	validationErr, ok := err.(*apperror.ValidationError)
+	if !ok {
+		t.Fatalf("esperava ValidationError, obteve %v", err)
+	}
+	var fields []string
+	for _, f := range validationErr.Fields {
+		fields = append(fields, f.Field)
+	}
+	assert.ElementsMatch(t, []string{"sku", "price", "variants[0].barcode", "variants[0].price_diff"}, fields)
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,202 @@
+package roleservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/middleware"
+)
+
+// RoleRepository define o contrato que o Serviço de Papéis espera da camada de Persistência.
+type RoleRepository interface {
+	CreateRole(ctx context.Context, role domain.Role) (domain.Role, error)
+	FindRole(ctx context.Context, name string) (domain.Role, error)
+	FindRoles(ctx context.Context) ([]domain.Role, error)
+	UpdateRole(ctx context.Context, role domain.Role) (domain.Role, error)
+	DeleteRole(ctx context.Context, name string) error
+	AssignUserRole(ctx context.Context, userID, role string) error
+}
+
+// roleNamePattern restringe os nomes de papéis (usados no JWT e nas URLs).
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,49}$`)
+
+// TokenRevoker é o contrato da lista de revogação de access tokens (token.RevocationList).
+type TokenRevoker interface {
+	RevokeUser(ctx context.Context, userID string, ttl time.Duration) error
+}
+
+// Service implementa as regras de papéis e permissões.
+type Service struct {
+	repo        RoleRepository
+	revocations TokenRevoker
+	tokenExpiry time.Duration // Validade dos access tokens (prazo da revogação por usuário)
+	logger      logger.Logger
+}
+
+// NewService cria e retorna uma nova instância do Serviço de Papéis. revocations invalida os
+// access tokens emitidos com o papel anterior quando o papel de um usuário muda; tokenExpiry é a
+// validade desses tokens.
+func NewService(repo RoleRepository, revocations TokenRevoker, tokenExpiry time.Duration, logger logger.Logger) *Service {
+	return &Service{repo: repo, revocations: revocations, tokenExpiry: tokenExpiry, logger: logger}
+}
+
+// HasPermission indica se o papel concede a permissão. Papéis inexistentes não concedem nada.
+// É usado pelo middleware RequirePermission a cada requisição (os papéis ficam em cache).
+func (s *Service) HasPermission(ctx context.Context, role domain.UserRole, permission domain.Permission) (bool, error) {
+	found, err := s.repo.FindRole(ctx, string(role))
+	if err != nil {
+		var notFound *apperror.NotFoundError
+		if errors.As(err, &notFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return found.HasPermission(permission), nil
+}
+
+// GetPermissions retorna o catálogo de permissões que podem ser atribuídas a papéis.
+func (s *Service) GetPermissions(ctx domain.Context) []domain.PermissionInfo {
+	return domain.Permissions
+}
+
+// GetRoles lista todos os papéis.
+func (s *Service) GetRoles(ctx domain.Context) ([]domain.Role, error) {
+	return s.repo.FindRoles(s.context(ctx))
+}
+
+// GetRole busca um papel pelo nome.
+func (s *Service) GetRole(ctx domain.Context, name string) (domain.Role, error) {
+	return s.repo.FindRole(s.context(ctx), strings.ToLower(strings.TrimSpace(name)))
+}
+
+// CreateRole cria um papel com um conjunto de permissões do catálogo.
+func (s *Service) CreateRole(ctx domain.Context, role domain.Role) (domain.Role, error) {
+	role.Name = strings.ToLower(strings.TrimSpace(role.Name))
+	if !roleNamePattern.MatchString(role.Name) {
+		return domain.Role{}, apperror.NewValidationError("O nome do papel deve ter de 2 a 50 caracteres: letras minúsculas, números, '_' ou '-', começando por uma letra.")
+	}
+	if err := normalizeRole(&role); err != nil {
+		return domain.Role{}, err
+	}
+
+	created, err := s.repo.CreateRole(s.context(ctx), role)
+	if err != nil {
+		return domain.Role{}, err
+	}
+	s.logger.WithContext(ctx).Info("Papel criado.", map[string]interface{}{"role": created.Name, "permissions": created.Permissions})
+	return created, nil
+}
+
+// UpdateRole substitui a descrição e as permissões de um papel. O papel admin não pode ser alterado.
+func (s *Service) UpdateRole(ctx domain.Context, role domain.Role) (domain.Role, error) {
+	role.Name = strings.ToLower(strings.TrimSpace(role.Name))
+	if role.Name == string(domain.RoleAdmin) {
+		return domain.Role{}, apperror.NewConflictError("O papel 'admin' tem todas as permissões e não pode ser alterado.")
+	}
+	if err := normalizeRole(&role); err != nil {
+		return domain.Role{}, err
+	}
+
+	updated, err := s.repo.UpdateRole(s.context(ctx), role)
+	if err != nil {
+		return domain.Role{}, err
+	}
+	s.logger.WithContext(ctx).Info("Papel atualizado.", map[string]interface{}{"role": updated.Name, "permissions": updated.Permissions})
+	return updated, nil
+}
+
+// DeleteRole remove um papel que não é do sistema e não está atribuído a usuários.
+func (s *Service) DeleteRole(ctx domain.Context, name string) error {
+	ctxGo := s.context(ctx)
+	role, err := s.repo.FindRole(ctxGo, strings.ToLower(strings.TrimSpace(name)))
+	if err != nil {
+		return err
+	}
+	if role.BuiltIn {
+		return apperror.NewConflictError(fmt.Sprintf("O papel '%s' é do sistema e não pode ser removido.", role.Name))
+	}
+
+	if err := s.repo.DeleteRole(ctxGo, role.Name); err != nil {
+		return err
+	}
+	s.logger.WithContext(ctx).Info("Papel removido.", map[string]interface{}{"role": role.Name})
+	return nil
+}
+
+// AssignUserRole altera o papel de um usuário. Os access tokens já emitidos (com o papel anterior
+// nas claims) são revogados, e o novo papel vale a partir do próximo token (login ou renovação).
+// Um administrador não pode remover o próprio papel de admin, o que evitaria deixar o sistema sem
+// administradores por engano.
+func (s *Service) AssignUserRole(ctx domain.Context, userID, roleName string) error {
+	if _, err := uuid.Parse(userID); err != nil {
+		return apperror.NewValidationError("O ID do usuário deve ser um UUID válido.")
+	}
+	roleName = strings.ToLower(strings.TrimSpace(roleName))
+	if roleName == "" {
+		return apperror.NewValidationError("O papel ('role') é obrigatório.")
+	}
+
+	ctxGo := s.context(ctx)
+	if claims, ok := middleware.GetUserClaimsFromContext(ctxGo); ok && claims.UserID == userID && roleName != string(domain.RoleAdmin) {
+		return apperror.NewConflictError("Não é possível remover o próprio papel de administrador.")
+	}
+	if _, err := s.repo.FindRole(ctxGo, roleName); err != nil {
+		return err
+	}
+
+	if err := s.repo.AssignUserRole(ctxGo, userID, roleName); err != nil {
+		return err
+	}
+	// Sem a revogação, os tokens emitidos antes manteriam o papel anterior até expirar
+	if err := s.revocations.RevokeUser(ctxGo, userID, s.tokenExpiry); err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao revogar access tokens do usuário após a troca de papel.", err)
+		return apperror.NewInternalError("Papel atribuído, mas não foi possível invalidar os tokens emitidos.", err)
+	}
+	s.logger.WithContext(ctx).Info("Papel atribuído ao usuário.", map[string]interface{}{"user_id": userID, "role": roleName})
+	return nil
+}
+
+// normalizeRole valida a descrição e as permissões (do catálogo, sem repetição e sem o curinga '*').
+func normalizeRole(role *domain.Role) error {
+	role.Description = strings.TrimSpace(role.Description)
+	if len(role.Description) > 255 {
+		return apperror.NewValidationError("A descrição do papel deve ter no máximo 255 caracteres.")
+	}
+
+	seen := make(map[domain.Permission]bool, len(role.Permissions))
+	permissions := make([]domain.Permission, 0, len(role.Permissions))
+	for _, p := range role.Permissions {
+		p = domain.Permission(strings.ToLower(strings.TrimSpace(string(p))))
+		if p == domain.PermissionAll {
+			return apperror.NewValidationError("A permissão '*' é exclusiva do papel admin.")
+		}
+		if !domain.IsKnownPermission(p) {
+			return apperror.NewValidationError(fmt.Sprintf("Permissão desconhecida: '%s'. Consulte GET /v1/permissions.", p))
+		}
+		if !seen[p] {
+			seen[p] = true
+			permissions = append(permissions, p)
+		}
+	}
+	role.Permissions = permissions
+	return nil
+}
+
+// context converte o contexto de domínio para context.Context.
+func (s *Service) context(ctx domain.Context) context.Context {
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background()", nil)
+		return context.Background()
+	}
+	return ctxGo
+}
@@ -0,0 +1,270 @@
+package roleservice_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/cache"
+	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/middleware"
+	"gostock/internal/pkg/tenant"
+	"gostock/internal/pkg/token"
+	"gostock/internal/service/roleservice"
+)
+
+// MockRoleRepository é uma implementação mock da interface RoleRepository
+type MockRoleRepository struct {
+	mock.Mock
+}
+
+func (m *MockRoleRepository) CreateRole(ctx context.Context, role domain.Role) (domain.Role, error) {
+	args := m.Called(ctx, role)
+	return args.Get(0).(domain.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) FindRole(ctx context.Context, name string) (domain.Role, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(domain.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) FindRoles(ctx context.Context) ([]domain.Role, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) UpdateRole(ctx context.Context, role domain.Role) (domain.Role, error) {
+	args := m.Called(ctx, role)
+	return args.Get(0).(domain.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) DeleteRole(ctx context.Context, name string) error {
+	args := m.Called(ctx, name)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) AssignUserRole(ctx context.Context, userID, role string) error {
+	args := m.Called(ctx, userID, role)
+	return args.Error(0)
+}
+
+// memoryCache é um cache.Client em memória (sem expiração) para a lista de revogação.
+// Com err preenchido, as gravações falham.
+type memoryCache struct {
+	values map[string]string
+	err    error
+}
+
+func (c *memoryCache) Get(ctx context.Context, key string) (string, error) {
+	value, ok := c.values[key]
+	if !ok {
+		return "", cache.ErrCacheMiss
+	}
+	return value, nil
+}
+
+func (c *memoryCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.values[key] = value.(string)
+	return nil
+}
+
+func (c *memoryCache) Delete(ctx context.Context, key string) error {
+	delete(c.values, key)
+	return nil
+}
+
+func (c *memoryCache) Incr(ctx context.Context, key string) error { return nil }
+
+func (c *memoryCache) GetInt(ctx context.Context, key string) (int, error) {
+	return 0, cache.ErrCacheMiss
+}
+
+func newTestService() (*roleservice.Service, *MockRoleRepository) {
+	svc, repo, _ := newTestServiceWithCache()
+	return svc, repo
+}
+
+// newTestServiceWithCache cria o serviço com a lista de revogação real sobre um cache em memória.
+func newTestServiceWithCache() (*roleservice.Service, *MockRoleRepository, *memoryCache) {
+	repo := new(MockRoleRepository)
+	store := &memoryCache{values: map[string]string{}}
+	return roleservice.NewService(repo, token.NewRevocationList(store), 15*time.Minute, logger.NewLogger("debug")), repo, store
+}
+
+// TestHasPermission testa a resolução de permissões, incluindo o curinga do admin e papéis inexistentes.
+func TestHasPermission(t *testing.T) {
+	svc, repo := newTestService()
+	ctx := context.Background()
+
+	repo.On("FindRole", ctx, "warehouse_staff").Return(domain.Role{Name: "warehouse_staff", Permissions: []domain.Permission{domain.PermissionStockAdjust}}, nil)
+	repo.On("FindRole", ctx, "admin").Return(domain.Role{Name: "admin", Permissions: []domain.Permission{domain.PermissionAll}}, nil)
+	repo.On("FindRole", ctx, "removido").Return(domain.Role{}, apperror.NewNotFoundError("Papel 'removido' não encontrado."))
+
+	allowed, err := svc.HasPermission(ctx, "warehouse_staff", domain.PermissionStockAdjust)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = svc.HasPermission(ctx, "warehouse_staff", domain.PermissionProductWrite)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+
+	allowed, err = svc.HasPermission(ctx, domain.RoleAdmin, domain.PermissionWarehouseManage)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = svc.HasPermission(ctx, "removido", domain.PermissionStockAdjust)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+// TestCreateRole_Success_NormalizesPermissions testa a normalização do nome e a remoção de permissões repetidas.
+func TestCreateRole_Success_NormalizesPermissions(t *testing.T) {
+	svc, repo := newTestService()
+
+	expected := domain.Role{Name: "estoquista", Description: "Equipe", Permissions: []domain.Permission{domain.PermissionStockAdjust, domain.PermissionStockConfigure}}
+	repo.On("CreateRole", mock.Anything, expected).Return(expected, nil)
+
+	_, err := svc.CreateRole(context.Background(), domain.Role{
+		Name: " Estoquista ", Description: " Equipe ",
+		Permissions: []domain.Permission{"stock:adjust", " STOCK:CONFIGURE", "stock:adjust"},
+	})
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+// TestCreateRole_Fail_Validation testa a recusa de nomes inválidos, permissões desconhecidas e do curinga.
+func TestCreateRole_Fail_Validation(t *testing.T) {
+	svc, repo := newTestService()
+
+	for _, role := range []domain.Role{
+		{Name: "a"},
+		{Name: "com espaço"},
+		{Name: "vendedor", Permissions: []domain.Permission{"sales:write"}},
+		{Name: "superuser", Permissions: []domain.Permission{domain.PermissionAll}},
+	} {
+		_, err := svc.CreateRole(context.Background(), role)
+		assert.IsType(t, &apperror.ValidationError{}, err, role.Name)
+	}
+	repo.AssertNotCalled(t, "CreateRole", mock.Anything, mock.Anything)
+}
+
+// TestUpdateRole_Fail_Admin testa a proteção do papel admin.
+func TestUpdateRole_Fail_Admin(t *testing.T) {
+	svc, repo := newTestService()
+
+	_, err := svc.UpdateRole(context.Background(), domain.Role{Name: "admin", Permissions: []domain.Permission{}})
+
+	assert.IsType(t, &apperror.ConflictError{}, err)
+	repo.AssertNotCalled(t, "UpdateRole", mock.Anything, mock.Anything)
+}
+
+// TestDeleteRole_Fail_BuiltIn testa a proteção dos papéis do sistema.
+func TestDeleteRole_Fail_BuiltIn(t *testing.T) {
+	svc, repo := newTestService()
+	repo.On("FindRole", mock.Anything, "user").Return(domain.Role{Name: "user", BuiltIn: true}, nil)
+
+	err := svc.DeleteRole(context.Background(), "user")
+
+	assert.IsType(t, &apperror.ConflictError{}, err)
+	repo.AssertNotCalled(t, "DeleteRole", mock.Anything, mock.Anything)
+}
+
+// TestAssignUserRole_Success testa a atribuição de um papel existente.
+func TestAssignUserRole_Success(t *testing.T) {
+	svc, repo := newTestService()
+	userID := uuid.NewString()
+
+	repo.On("FindRole", mock.Anything, "warehouse_staff").Return(domain.Role{Name: "warehouse_staff"}, nil)
+	repo.On("AssignUserRole", mock.Anything, userID, "warehouse_staff").Return(nil)
+
+	err := svc.AssignUserRole(context.Background(), userID, "Warehouse_Staff")
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+// TestAssignUserRole_RevokesIssuedTokens testa que os access tokens emitidos antes da troca de
+// papel (com o papel anterior nas claims) são recusados pela lista de revogação.
+func TestAssignUserRole_RevokesIssuedTokens(t *testing.T) {
+	svc, repo, store := newTestServiceWithCache()
+	revocations := token.NewRevocationList(store)
+	tokens := token.NewService("segredo", 15*time.Minute)
+	userID := uuid.NewString()
+	ctx := context.Background()
+
+	issued, err := tokens.GenerateToken(userID, "admin", tenant.DefaultID, uuid.NewString())
+	if err != nil {
+		t.Fatalf("falha ao gerar token: %v", err)
+	}
+	claims, err := tokens.ValidateToken(issued)
+	if err != nil {
+		t.Fatalf("falha ao validar token: %v", err)
+	}
+	revoked, err := revocations.IsRevoked(ctx, claims)
+	if err != nil || revoked {
+		t.Fatalf("token não deveria estar revogado antes da troca de papel (revoked=%v, err=%v)", revoked, err)
+	}
+
+	repo.On("FindRole", mock.Anything, "user").Return(domain.Role{Name: "user"}, nil)
+	repo.On("AssignUserRole", mock.Anything, userID, "user").Return(nil)
+
+	err = svc.AssignUserRole(ctx, userID, "user")
+
+	assert.NoError(t, err)
+	revoked, err = revocations.IsRevoked(ctx, claims)
+	assert.NoError(t, err)
+	assert.True(t, revoked, "Token emitido com o papel anterior deve ser recusado")
+}
+
+// TestAssignUserRole_Fail_RevocationError testa que a falha ao revogar os tokens é reportada.
+func TestAssignUserRole_Fail_RevocationError(t *testing.T) {
+	svc, repo, store := newTestServiceWithCache()
+	store.err = errors.New("redis indisponível")
+	userID := uuid.NewString()
+
+	repo.On("FindRole", mock.Anything, "user").Return(domain.Role{Name: "user"}, nil)
+	repo.On("AssignUserRole", mock.Anything, userID, "user").Return(nil)
+
+	err := svc.AssignUserRole(context.Background(), userID, "user")
+
+	assert.IsType(t, &apperror.InternalError{}, err)
+	repo.AssertExpectations(t)
+}
+
+// TestAssignUserRole_Fail_RepoErrorKeepsTokens testa que, se o papel não for gravado, os tokens
+// do usuário não são revogados.
+func TestAssignUserRole_Fail_RepoErrorKeepsTokens(t *testing.T) {
+	svc, repo, store := newTestServiceWithCache()
+	userID := uuid.NewString()
+
+	repo.On("FindRole", mock.Anything, "user").Return(domain.Role{Name: "user"}, nil)
+	repo.On("AssignUserRole", mock.Anything, userID, "user").Return(apperror.NewNotFoundError("Usuário não encontrado."))
+
+	err := svc.AssignUserRole(context.Background(), userID, "user")
+
+	assert.IsType(t, &apperror.NotFoundError{}, err)
+	assert.Empty(t, store.values)
+}
+
+// TestAssignUserRole_Fail_OwnAdminRole testa que o administrador não remove o próprio papel de admin.
+func TestAssignUserRole_Fail_OwnAdminRole(t *testing.T) {
+	svc, repo := newTestService()
+	userID := uuid.NewString()
+	ctx := context.WithValue(context.Background(), middleware.UserClaimsKey, middleware.UserClaims{UserID: userID, Role: domain.RoleAdmin})
+
+	err := svc.AssignUserRole(ctx, userID, "user")
+
+	assert.IsType(t, &apperror.ConflictError{}, err)
+	repo.AssertNotCalled(t, "AssignUserRole", mock.Anything, mock.Anything, mock.Anything)
+}
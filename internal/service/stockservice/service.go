@@ -3,54 +3,122 @@ package stockservice
 import (
 	"context"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 
 	"errors"
+
+	"github.com/google/uuid"
+
 	"gostock/internal/domain"
 	apperror "gostock/internal/errors"
 	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/middleware"
 )
 
 // StockRepository define o contrato que o Serviço de Estoque espera da camada de Persistência.
 type StockRepository interface {
 	GetStockLevel(ctx context.Context, variantID, warehouseID string) (domain.StockLevel, error)
+	FindStockLevelsByVariant(ctx context.Context, variantID string) ([]domain.StockLevel, error)
 	UpdateStockLevel(ctx context.Context, adjustment domain.StockAdjustmentRequest) (domain.StockLevel, error)
+	StreamExportRows(ctx context.Context, filter domain.StockExportFilter, fn func(domain.StockExportRow) error) error
+	GetVariantUnits(ctx context.Context, variantID string) (domain.VariantUnits, error)
+	SaveVariantUnits(ctx context.Context, units domain.VariantUnits) (domain.VariantUnits, error)
 	// Add more methods for Warehouse CRUD if needed later
 }
 
+// WarehouseAccess fornece os armazéns atribuídos a cada usuário, que limitam o acesso ao estoque.
+type WarehouseAccess interface {
+	GetUserWarehouses(ctx context.Context, userID string) (domain.UserWarehouses, error)
+}
+
 // Service é a estrutura que implementa a interface domain.StockService (a ser definida).
 type Service struct {
 	repo   StockRepository
+	access WarehouseAccess
 	logger logger.Logger
 }
 
 // NewService cria e retorna uma nova instância do Serviço de Estoque.
-func NewService(repo StockRepository, logger logger.Logger) *Service {
-	return &Service{repo: repo, logger: logger}
+func NewService(repo StockRepository, access WarehouseAccess, logger logger.Logger) *Service {
+	return &Service{repo: repo, access: access, logger: logger}
+}
+
+// warehouseScope retorna os armazéns que o usuário do contexto pode operar. nil indica acesso
+// global: administradores e chamadas internas, sem usuário autenticado no contexto.
+func (s *Service) warehouseScope(ctx context.Context) ([]string, error) {
+	claims, ok := middleware.GetUserClaimsFromContext(ctx)
+	if !ok || claims.Role == domain.RoleAdmin {
+		return nil, nil
+	}
+
+	assigned, err := s.access.GetUserWarehouses(ctx, claims.UserID)
+	var notFound *apperror.NotFoundError
+	if errors.As(err, &notFound) {
+		return []string{}, nil
+	}
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao buscar armazéns atribuídos ao usuário.", err)
+		return nil, apperror.NewInternalError("Falha interna ao verificar o acesso ao armazém.", err)
+	}
+	return assigned.WarehouseIDs, nil
+}
+
+// checkWarehouseAccess retorna erro 403 se o usuário do contexto não estiver atribuído ao armazém.
+func (s *Service) checkWarehouseAccess(ctx context.Context, warehouseID string) error {
+	scope, err := s.warehouseScope(ctx)
+	if err != nil || scope == nil {
+		return err
+	}
+	for _, id := range scope {
+		if strings.EqualFold(id, warehouseID) {
+			return nil
+		}
+	}
+	claims, _ := middleware.GetUserClaimsFromContext(ctx)
+	s.logger.WithContext(ctx).Warn("Acesso ao armazém negado.", map[string]interface{}{"user_id": claims.UserID, "warehouse_id": warehouseID})
+	return apperror.NewForbiddenError(fmt.Sprintf("Acesso negado ao armazém '%s': o usuário não está atribuído a ele.", warehouseID))
 }
 
 // AdjustStock aplica um ajuste ao nível de estoque de um produto em um armazém.
 func (s *Service) AdjustStock(ctx domain.Context, adjustment domain.StockAdjustmentRequest) (domain.StockLevel, error) {
-	s.logger.Debug("Iniciando ajuste de estoque no serviço.", map[string]interface{}{
+	s.logger.WithContext(ctx).Debug("Iniciando ajuste de estoque no serviço.", map[string]interface{}{
 		"variant_id":   adjustment.VariantID,
 		"warehouse_id": adjustment.WarehouseID,
 		"delta":        adjustment.Delta,
 	})
 
-	// Basic validation (more comprehensive validation can be in the handler or domain)
-	if adjustment.Delta == 0 {
-		return domain.StockLevel{}, apperror.NewValidationError("O ajuste de estoque (delta) não pode ser zero.")
-	}
-
 	// Casting e Configuração do Contexto (Converte domain.Context para context.Context)
 	ctxGo, ok := ctx.(context.Context)
 	if !ok {
 		ctxGo = context.Background()
-		s.logger.Warn("Contexto de domínio inválido, usando context.Background() para AdjustStock", nil)
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background() para AdjustStock", nil)
+	}
+
+	// Usuários que não são administradores só ajustam o estoque dos armazéns atribuídos a eles
+	if err := s.checkWarehouseAccess(ctxGo, adjustment.WarehouseID); err != nil {
+		return domain.StockLevel{}, err
+	}
+
+	// Ajustes com unidade ou quantidade decimal são convertidos para unidades-base
+	var units *domain.VariantUnits
+	if adjustment.Unit != "" || adjustment.Quantity != "" {
+		resolved, err := s.toBaseUnits(ctxGo, &adjustment)
+		if err != nil {
+			return domain.StockLevel{}, err
+		}
+		units = &resolved
+	}
+
+	// Basic validation (more comprehensive validation can be in the handler or domain)
+	if adjustment.Delta == 0 {
+		return domain.StockLevel{}, apperror.NewValidationError("O ajuste de estoque (delta) não pode ser zero.")
 	}
 
 	stockLevel, err := s.repo.UpdateStockLevel(ctxGo, adjustment)
 	if err != nil {
-		s.logger.Error("Falha ao ajustar estoque no repositório.", err)
+		s.logger.WithContext(ctx).Error("Falha ao ajustar estoque no repositório.", err)
 		// Translate repository errors to service/domain errors if necessary
 		var conflictErr *apperror.ConflictError
 		if errors.As(err, &conflictErr) {
@@ -63,7 +131,12 @@ func (s *Service) AdjustStock(ctx domain.Context, adjustment domain.StockAdjustm
 		return domain.StockLevel{}, apperror.NewInternalError("Falha interna ao ajustar estoque.", err)
 	}
 
-	s.logger.Info("Estoque ajustado com sucesso.", map[string]interface{}{
+	if units != nil {
+		stockLevel.BaseUnit = units.BaseUnit
+		stockLevel.BaseQuantity = units.FormatStorage(int64(stockLevel.Quantity))
+	}
+
+	s.logger.WithContext(ctx).Info("Estoque ajustado com sucesso.", map[string]interface{}{
 		"variant_id":   stockLevel.VariantID,
 		"warehouse_id": stockLevel.WarehouseID,
 		"new_quantity": stockLevel.Quantity,
@@ -71,3 +144,204 @@ func (s *Service) AdjustStock(ctx domain.Context, adjustment domain.StockAdjustm
 	})
 	return stockLevel, nil
 }
+
+// ExportStock percorre os níveis de estoque filtrados e entrega cada linha para fn,
+// que deve escrevê-la. Nenhuma linha é acumulada em memória.
+func (s *Service) ExportStock(ctx domain.Context, filter domain.StockExportFilter, fn func(domain.StockExportRow) error) error {
+	s.logger.WithContext(ctx).Debug("Iniciando exportação de estoque no serviço.", map[string]interface{}{"warehouse_id": filter.WarehouseID})
+
+	if filter.WarehouseID != "" {
+		if _, err := uuid.Parse(filter.WarehouseID); err != nil {
+			return apperror.NewValidationError("O parâmetro 'warehouse_id' deve ser um UUID válido.")
+		}
+	}
+
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background() para ExportStock", nil)
+	}
+
+	// Sem armazém informado, usuários com escopo exportam apenas os armazéns atribuídos a eles
+	if filter.WarehouseID != "" {
+		if err := s.checkWarehouseAccess(ctxGo, filter.WarehouseID); err != nil {
+			return err
+		}
+	} else {
+		scope, err := s.warehouseScope(ctxGo)
+		if err != nil {
+			return err
+		}
+		if scope != nil && len(scope) == 0 {
+			return apperror.NewForbiddenError("Acesso negado: o usuário não está atribuído a nenhum armazém.")
+		}
+		filter.WarehouseIDs = scope
+	}
+
+	if err := s.repo.StreamExportRows(ctxGo, filter, fn); err != nil {
+		var appErr apperror.AppError
+		if errors.As(err, &appErr) {
+			s.logger.WithContext(ctx).Error("Falha ao exportar estoque no repositório.", err)
+			return err
+		}
+		// Erros não tipados vêm do escritor (fn), normalmente o cliente desconectando.
+		return apperror.NewInternalError("Exportação de estoque interrompida.", err)
+	}
+
+	s.logger.WithContext(ctx).Info("Exportação de estoque concluída.", map[string]interface{}{"warehouse_id": filter.WarehouseID})
+	return nil
+}
+
+// toBaseUnits converte a quantidade do ajuste (Delta ou Quantity, na unidade informada) para a
+// quantidade armazenada em unidades-base, gravando-a em adjustment.Delta.
+func (s *Service) toBaseUnits(ctx context.Context, adjustment *domain.StockAdjustmentRequest) (domain.VariantUnits, error) {
+	if adjustment.Quantity != "" && adjustment.Delta != 0 {
+		return domain.VariantUnits{}, apperror.NewValidationError("Informe 'delta' ou 'quantity', não ambos.")
+	}
+	if _, err := uuid.Parse(adjustment.VariantID); err != nil {
+		return domain.VariantUnits{}, apperror.NewValidationError("O campo 'variant_id' deve ser um UUID válido.")
+	}
+
+	units, err := s.variantUnits(ctx, adjustment.VariantID)
+	if err != nil {
+		return domain.VariantUnits{}, err
+	}
+
+	quantity := adjustment.Quantity.String()
+	if quantity == "" {
+		quantity = strconv.Itoa(adjustment.Delta)
+	}
+	unit := strings.ToLower(strings.TrimSpace(adjustment.Unit))
+
+	stored, err := units.ToStorage(quantity, unit)
+	if err != nil {
+		return domain.VariantUnits{}, apperror.NewValidationError(fmt.Sprintf("Unidade de medida: %s.", err.Error()))
+	}
+	if stored > math.MaxInt32 || stored < math.MinInt32 {
+		return domain.VariantUnits{}, apperror.NewValidationError("A quantidade convertida excede o limite do estoque.")
+	}
+
+	s.logger.WithContext(ctx).Debug("Quantidade convertida para unidades-base.", map[string]interface{}{
+		"variant_id": adjustment.VariantID, "quantity": quantity, "unit": unit, "stored": stored, "base_unit": units.BaseUnit,
+	})
+	adjustment.Delta = int(stored)
+	adjustment.Quantity = ""
+	adjustment.Unit = ""
+	return units, nil
+}
+
+// variantUnits busca as unidades da variante, usando a configuração padrão (unidade discreta "un")
+// quando nada foi cadastrado.
+func (s *Service) variantUnits(ctx context.Context, variantID string) (domain.VariantUnits, error) {
+	units, err := s.repo.GetVariantUnits(ctx, variantID)
+	var notFound *apperror.NotFoundError
+	if errors.As(err, &notFound) {
+		return domain.DefaultVariantUnits(variantID), nil
+	}
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao buscar unidades de medida da variante.", err)
+		return domain.VariantUnits{}, apperror.NewInternalError("Falha interna ao buscar unidades de medida.", err)
+	}
+	return units, nil
+}
+
+// GetVariantStock retorna o estoque atual da variante em cada armazém, com a quantidade
+// também expressa na unidade-base (BaseUnit/BaseQuantity). Usuários que não são administradores
+// veem apenas os armazéns atribuídos a eles.
+func (s *Service) GetVariantStock(ctx domain.Context, variantID string) ([]domain.StockLevel, error) {
+	if _, err := uuid.Parse(variantID); err != nil {
+		return nil, apperror.NewValidationError("O ID da variante deve ser um UUID válido.")
+	}
+
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background() para GetVariantStock", nil)
+	}
+
+	levels, err := s.repo.FindStockLevelsByVariant(ctxGo, variantID)
+	if err != nil {
+		return nil, err
+	}
+	scope, err := s.warehouseScope(ctxGo)
+	if err != nil {
+		return nil, err
+	}
+	if scope != nil {
+		levels = filterLevelsByWarehouse(levels, scope)
+	}
+	units, err := s.variantUnits(ctxGo, variantID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range levels {
+		levels[i].BaseUnit = units.BaseUnit
+		levels[i].BaseQuantity = units.FormatStorage(int64(levels[i].Quantity))
+	}
+	return levels, nil
+}
+
+// filterLevelsByWarehouse mantém apenas os níveis de estoque dos armazéns informados.
+func filterLevelsByWarehouse(levels []domain.StockLevel, warehouseIDs []string) []domain.StockLevel {
+	allowed := make(map[string]bool, len(warehouseIDs))
+	for _, id := range warehouseIDs {
+		allowed[strings.ToLower(id)] = true
+	}
+	filtered := make([]domain.StockLevel, 0, len(levels))
+	for _, level := range levels {
+		if allowed[strings.ToLower(level.WarehouseID)] {
+			filtered = append(filtered, level)
+		}
+	}
+	return filtered
+}
+
+// GetVariantUnits retorna a configuração de unidades de medida de uma variante.
+func (s *Service) GetVariantUnits(ctx domain.Context, variantID string) (domain.VariantUnits, error) {
+	if _, err := uuid.Parse(variantID); err != nil {
+		return domain.VariantUnits{}, apperror.NewValidationError("O ID da variante deve ser um UUID válido.")
+	}
+
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background() para GetVariantUnits", nil)
+	}
+	return s.variantUnits(ctxGo, variantID)
+}
+
+// SetVariantUnits cria ou substitui a configuração de unidades de medida de uma variante. A variante
+// precisa pertencer à empresa da requisição (verificado pelo repositório).
+func (s *Service) SetVariantUnits(ctx domain.Context, units domain.VariantUnits) (domain.VariantUnits, error) {
+	s.logger.WithContext(ctx).Debug("Iniciando configuração de unidades de medida no serviço.", map[string]interface{}{"variant_id": units.VariantID})
+
+	if _, err := uuid.Parse(units.VariantID); err != nil {
+		return domain.VariantUnits{}, apperror.NewValidationError("O ID da variante deve ser um UUID válido.")
+	}
+	units.BaseUnit = strings.ToLower(strings.TrimSpace(units.BaseUnit))
+	if units.Conversions == nil {
+		units.Conversions = []domain.UnitConversion{}
+	}
+	for i := range units.Conversions {
+		units.Conversions[i].Unit = strings.ToLower(strings.TrimSpace(units.Conversions[i].Unit))
+		units.Conversions[i].Factor = strings.TrimSpace(units.Conversions[i].Factor)
+	}
+	if err := units.Validate(); err != nil {
+		return domain.VariantUnits{}, apperror.NewValidationError(fmt.Sprintf("Unidades de medida inválidas: %s.", err.Error()))
+	}
+
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background() para SetVariantUnits", nil)
+	}
+
+	saved, err := s.repo.SaveVariantUnits(ctxGo, units)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao salvar unidades de medida no repositório.", err)
+		return domain.VariantUnits{}, err // NotFoundError (variante de outra empresa), ConflictError ou DBError
+	}
+
+	s.logger.WithContext(ctx).Info("Unidades de medida configuradas.", map[string]interface{}{"variant_id": saved.VariantID, "base_unit": saved.BaseUnit, "conversions": len(saved.Conversions)})
+	return saved, nil
+}
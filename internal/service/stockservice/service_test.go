@@ -1,8 +1,13 @@
 package stockservice_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,6 +18,8 @@ import (
 	"gostock/internal/domain"
 	apperror "gostock/internal/errors"
 	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/middleware"
+	"gostock/internal/pkg/requestid"
 	"gostock/internal/service/stockservice"
 )
 
@@ -31,12 +38,50 @@ func (m *MockStockRepository) UpdateStockLevel(ctx context.Context, adjustment d
 	return args.Get(0).(domain.StockLevel), args.Error(1)
 }
 
+func (m *MockStockRepository) StreamExportRows(ctx context.Context, filter domain.StockExportFilter, fn func(domain.StockExportRow) error) error {
+	args := m.Called(ctx, filter)
+	// Linhas configuradas no mock são entregues ao callback, como faria o repositório.
+	if rows, ok := args.Get(0).([]domain.StockExportRow); ok {
+		for _, row := range rows {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockStockRepository) FindStockLevelsByVariant(ctx context.Context, variantID string) ([]domain.StockLevel, error) {
+	args := m.Called(ctx, variantID)
+	return args.Get(0).([]domain.StockLevel), args.Error(1)
+}
+
+func (m *MockStockRepository) GetVariantUnits(ctx context.Context, variantID string) (domain.VariantUnits, error) {
+	args := m.Called(ctx, variantID)
+	return args.Get(0).(domain.VariantUnits), args.Error(1)
+}
+
+func (m *MockStockRepository) SaveVariantUnits(ctx context.Context, units domain.VariantUnits) (domain.VariantUnits, error) {
+	args := m.Called(ctx, units)
+	return args.Get(0).(domain.VariantUnits), args.Error(1)
+}
+
+// MockWarehouseAccess é uma implementação mock da interface WarehouseAccess
+type MockWarehouseAccess struct {
+	mock.Mock
+}
+
+func (m *MockWarehouseAccess) GetUserWarehouses(ctx context.Context, userID string) (domain.UserWarehouses, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(domain.UserWarehouses), args.Error(1)
+}
+
 // TestAdjustStock_Success_ExistingStock testa um ajuste de estoque bem-sucedido para um item existente.
 func TestAdjustStock_Success_ExistingStock(t *testing.T) {
 	mockRepo := new(MockStockRepository)
 	mockLogger := logger.NewLogger("debug") // Usar um logger mock ou nulo em testes reais.
 
-	svc := stockservice.NewService(mockRepo, mockLogger)
+	svc := stockservice.NewService(mockRepo, new(MockWarehouseAccess), mockLogger)
 
 	// Dados de teste
 	variantID := uuid.New().String()
@@ -89,7 +134,7 @@ func TestAdjustStock_Success_NewStock(t *testing.T) {
 	mockRepo := new(MockStockRepository)
 	mockLogger := logger.NewLogger("debug")
 
-	svc := stockservice.NewService(mockRepo, mockLogger)
+	svc := stockservice.NewService(mockRepo, new(MockWarehouseAccess), mockLogger)
 
 	variantID := uuid.New().String()
 	warehouseID := uuid.New().String()
@@ -128,7 +173,7 @@ func TestAdjustStock_Fail_NegativeResultingStock(t *testing.T) {
 	mockRepo := new(MockStockRepository)
 	mockLogger := logger.NewLogger("debug")
 
-	svc := stockservice.NewService(mockRepo, mockLogger)
+	svc := stockservice.NewService(mockRepo, new(MockWarehouseAccess), mockLogger)
 
 	variantID := uuid.New().String()
 	warehouseID := uuid.New().String()
@@ -158,7 +203,7 @@ func TestAdjustStock_Fail_OCCConflict(t *testing.T) {
 	mockRepo := new(MockStockRepository)
 	mockLogger := logger.NewLogger("debug")
 
-	svc := stockservice.NewService(mockRepo, mockLogger)
+	svc := stockservice.NewService(mockRepo, new(MockWarehouseAccess), mockLogger)
 
 	variantID := uuid.New().String()
 	warehouseID := uuid.New().String()
@@ -188,7 +233,7 @@ func TestAdjustStock_Fail_ZeroDelta(t *testing.T) {
 	mockRepo := new(MockStockRepository)
 	mockLogger := logger.NewLogger("debug")
 
-	svc := stockservice.NewService(mockRepo, mockLogger)
+	svc := stockservice.NewService(mockRepo, new(MockWarehouseAccess), mockLogger)
 
 	variantID := uuid.New().String()
 	warehouseID := uuid.New().String()
@@ -217,7 +262,7 @@ func TestAdjustStock_Fail_InternalError(t *testing.T) {
 	mockRepo := new(MockStockRepository)
 	mockLogger := logger.NewLogger("debug")
 
-	svc := stockservice.NewService(mockRepo, mockLogger)
+	svc := stockservice.NewService(mockRepo, new(MockWarehouseAccess), mockLogger)
 
 	variantID := uuid.New().String()
 	warehouseID := uuid.New().String()
@@ -242,3 +287,344 @@ func TestAdjustStock_Fail_InternalError(t *testing.T) {
 	assert.Contains(t, err.Error(), "Falha interna ao ajustar estoque.")
 	mockRepo.AssertExpectations(t)
 }
+
+// TestExportStock_Success testa que as linhas do repositório são entregues ao escritor.
+func TestExportStock_Success(t *testing.T) {
+	mockRepo := new(MockStockRepository)
+	svc := stockservice.NewService(mockRepo, new(MockWarehouseAccess), logger.NewLogger("debug"))
+
+	warehouseID := uuid.New().String()
+	filter := domain.StockExportFilter{WarehouseID: warehouseID}
+	rows := []domain.StockExportRow{
+		{WarehouseID: warehouseID, SKU: "CAM-001", VariantID: uuid.New().String(), Quantity: 10},
+		{WarehouseID: warehouseID, SKU: "CAM-001", VariantID: uuid.New().String(), Quantity: 3},
+	}
+	mockRepo.On("StreamExportRows", mock.Anything, filter).Return(rows, nil)
+
+	var written []domain.StockExportRow
+	err := svc.ExportStock(context.Background(), filter, func(row domain.StockExportRow) error {
+		written = append(written, row)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, rows, written)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestExportStock_Fail_InvalidWarehouse testa a rejeição de um warehouse_id inválido.
+func TestExportStock_Fail_InvalidWarehouse(t *testing.T) {
+	mockRepo := new(MockStockRepository)
+	svc := stockservice.NewService(mockRepo, new(MockWarehouseAccess), logger.NewLogger("debug"))
+
+	err := svc.ExportStock(context.Background(), domain.StockExportFilter{WarehouseID: "abc"}, func(domain.StockExportRow) error { return nil })
+
+	assert.Error(t, err)
+	assert.IsType(t, &apperror.ValidationError{}, err)
+	mockRepo.AssertNotCalled(t, "StreamExportRows", mock.Anything, mock.Anything)
+}
+
+// TestExportStock_Fail_WriterError testa que falhas do escritor interrompem a exportação.
+func TestExportStock_Fail_WriterError(t *testing.T) {
+	mockRepo := new(MockStockRepository)
+	svc := stockservice.NewService(mockRepo, new(MockWarehouseAccess), logger.NewLogger("debug"))
+
+	rows := []domain.StockExportRow{{SKU: "CAM-001"}, {SKU: "CAM-002"}}
+	mockRepo.On("StreamExportRows", mock.Anything, domain.StockExportFilter{}).Return(rows, nil)
+
+	calls := 0
+	err := svc.ExportStock(context.Background(), domain.StockExportFilter{}, func(domain.StockExportRow) error {
+		calls++
+		return errors.New("broken pipe")
+	})
+
+	assert.Error(t, err)
+	assert.IsType(t, &apperror.InternalError{}, err)
+	assert.Equal(t, 1, calls)
+}
+
+// TestAdjustStock_Success_CaseUnitConvertedToBase testa a conversão de caixas para unidades-base.
+func TestAdjustStock_Success_CaseUnitConvertedToBase(t *testing.T) {
+	mockRepo := new(MockStockRepository)
+	svc := stockservice.NewService(mockRepo, new(MockWarehouseAccess), logger.NewLogger("debug"))
+
+	variantID := uuid.New().String()
+	warehouseID := uuid.New().String()
+	units := domain.VariantUnits{VariantID: variantID, BaseUnit: "un", Conversions: []domain.UnitConversion{{Unit: "cx", Factor: "12"}, {Unit: "pallet", Factor: "480"}}}
+
+	mockRepo.On("GetVariantUnits", mock.Anything, variantID).Return(units, nil)
+	mockRepo.On("UpdateStockLevel", mock.Anything, domain.StockAdjustmentRequest{VariantID: variantID, WarehouseID: warehouseID, Delta: 24}).
+		Return(domain.StockLevel{VariantID: variantID, WarehouseID: warehouseID, Quantity: 30, Version: 2}, nil)
+
+	level, err := svc.AdjustStock(context.Background(), domain.StockAdjustmentRequest{VariantID: variantID, WarehouseID: warehouseID, Delta: 2, Unit: "CX"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 30, level.Quantity)
+	assert.Equal(t, "un", level.BaseUnit)
+	assert.Equal(t, "30", level.BaseQuantity)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestAdjustStock_Success_DecimalVariant testa quantidades fracionárias em variantes decimais (kg com 3 casas).
+func TestAdjustStock_Success_DecimalVariant(t *testing.T) {
+	mockRepo := new(MockStockRepository)
+	svc := stockservice.NewService(mockRepo, new(MockWarehouseAccess), logger.NewLogger("debug"))
+
+	variantID := uuid.New().String()
+	warehouseID := uuid.New().String()
+	units := domain.VariantUnits{VariantID: variantID, BaseUnit: "kg", DecimalPlaces: 3, Conversions: []domain.UnitConversion{{Unit: "g", Factor: "0.001"}}}
+
+	mockRepo.On("GetVariantUnits", mock.Anything, variantID).Return(units, nil)
+	mockRepo.On("UpdateStockLevel", mock.Anything, domain.StockAdjustmentRequest{VariantID: variantID, WarehouseID: warehouseID, Delta: -1250}).
+		Return(domain.StockLevel{VariantID: variantID, WarehouseID: warehouseID, Quantity: 8750}, nil)
+
+	level, err := svc.AdjustStock(context.Background(), domain.StockAdjustmentRequest{VariantID: variantID, WarehouseID: warehouseID, Quantity: "-1.25", Unit: "kg"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "8.750", level.BaseQuantity)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestAdjustStock_Fail_FractionOfDiscreteUnit testa a rejeição de frações em variantes discretas.
+func TestAdjustStock_Fail_FractionOfDiscreteUnit(t *testing.T) {
+	mockRepo := new(MockStockRepository)
+	svc := stockservice.NewService(mockRepo, new(MockWarehouseAccess), logger.NewLogger("debug"))
+
+	variantID := uuid.New().String()
+	mockRepo.On("GetVariantUnits", mock.Anything, variantID).Return(domain.VariantUnits{}, apperror.NewNotFoundError("sem unidades"))
+
+	_, err := svc.AdjustStock(context.Background(), domain.StockAdjustmentRequest{VariantID: variantID, WarehouseID: uuid.New().String(), Quantity: "1.5"})
+
+	assert.Error(t, err)
+	assert.IsType(t, &apperror.ValidationError{}, err)
+	mockRepo.AssertNotCalled(t, "UpdateStockLevel", mock.Anything, mock.Anything)
+}
+
+// TestAdjustStock_Fail_UnknownUnit testa a rejeição de unidades não configuradas para a variante.
+func TestAdjustStock_Fail_UnknownUnit(t *testing.T) {
+	mockRepo := new(MockStockRepository)
+	svc := stockservice.NewService(mockRepo, new(MockWarehouseAccess), logger.NewLogger("debug"))
+
+	variantID := uuid.New().String()
+	mockRepo.On("GetVariantUnits", mock.Anything, variantID).Return(domain.VariantUnits{VariantID: variantID, BaseUnit: "un"}, nil)
+
+	_, err := svc.AdjustStock(context.Background(), domain.StockAdjustmentRequest{VariantID: variantID, WarehouseID: uuid.New().String(), Delta: 1, Unit: "pallet"})
+
+	assert.Error(t, err)
+	assert.IsType(t, &apperror.ValidationError{}, err)
+	assert.Contains(t, err.Error(), "pallet")
+}
+
+// TestSetVariantUnits_Fail_FactorBeyondPrecision testa a rejeição de fatores fracionários em variantes discretas.
+func TestSetVariantUnits_Fail_FactorBeyondPrecision(t *testing.T) {
+	mockRepo := new(MockStockRepository)
+	svc := stockservice.NewService(mockRepo, new(MockWarehouseAccess), logger.NewLogger("debug"))
+
+	_, err := svc.SetVariantUnits(context.Background(), domain.VariantUnits{
+		VariantID:   uuid.New().String(),
+		BaseUnit:    "un",
+		Conversions: []domain.UnitConversion{{Unit: "meia-cx", Factor: "6.5"}},
+	})
+
+	assert.Error(t, err)
+	assert.IsType(t, &apperror.ValidationError{}, err)
+	mockRepo.AssertNotCalled(t, "SaveVariantUnits", mock.Anything, mock.Anything)
+}
+
+// TestSetVariantUnits_Success_Normalizes testa a normalização dos nomes das unidades.
+func TestSetVariantUnits_Success_Normalizes(t *testing.T) {
+	mockRepo := new(MockStockRepository)
+	svc := stockservice.NewService(mockRepo, new(MockWarehouseAccess), logger.NewLogger("debug"))
+
+	variantID := uuid.New().String()
+	expected := domain.VariantUnits{VariantID: variantID, BaseUnit: "un", Conversions: []domain.UnitConversion{{Unit: "cx", Factor: "12"}}}
+	mockRepo.On("SaveVariantUnits", mock.Anything, expected).Return(expected, nil)
+
+	saved, err := svc.SetVariantUnits(context.Background(), domain.VariantUnits{
+		VariantID:   variantID,
+		BaseUnit:    " UN ",
+		Conversions: []domain.UnitConversion{{Unit: "CX", Factor: " 12 "}},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, saved)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestSetVariantUnits_Fail_VariantNotFound testa que a variante inexistente (ou de outra empresa)
+// resulta em NotFoundError, sem mascarar o erro do repositório.
+func TestSetVariantUnits_Fail_VariantNotFound(t *testing.T) {
+	mockRepo := new(MockStockRepository)
+	svc := stockservice.NewService(mockRepo, new(MockWarehouseAccess), logger.NewLogger("debug"))
+
+	variantID := uuid.New().String()
+	mockRepo.On("SaveVariantUnits", mock.Anything, mock.Anything).Return(domain.VariantUnits{}, apperror.NewNotFoundError("Variante com ID "+variantID+" não encontrada."))
+
+	_, err := svc.SetVariantUnits(context.Background(), domain.VariantUnits{VariantID: variantID, BaseUnit: "un"})
+
+	assert.IsType(t, &apperror.NotFoundError{}, err)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGetVariantStock_Success testa a listagem do estoque da variante com a quantidade na unidade-base.
+func TestGetVariantStock_Success(t *testing.T) {
+	mockRepo := new(MockStockRepository)
+	svc := stockservice.NewService(mockRepo, new(MockWarehouseAccess), logger.NewLogger("debug"))
+
+	variantID := uuid.New().String()
+	mockRepo.On("FindStockLevelsByVariant", mock.Anything, variantID).Return([]domain.StockLevel{
+		{VariantID: variantID, WarehouseID: "w1", Quantity: 2500},
+		{VariantID: variantID, WarehouseID: "w2", Quantity: 0},
+	}, nil)
+	mockRepo.On("GetVariantUnits", mock.Anything, variantID).Return(domain.VariantUnits{VariantID: variantID, BaseUnit: "kg", DecimalPlaces: 3}, nil)
+
+	levels, err := svc.GetVariantStock(context.Background(), variantID)
+
+	assert.NoError(t, err)
+	assert.Len(t, levels, 2)
+	assert.Equal(t, "kg", levels[0].BaseUnit)
+	assert.Equal(t, "2.500", levels[0].BaseQuantity)
+	assert.Equal(t, "0.000", levels[1].BaseQuantity)
+}
+
+// --- Testes de escopo por armazém ---
+
+// userContext simula um usuário autenticado pelo middleware.
+func userContext(userID string, role domain.UserRole) context.Context {
+	return context.WithValue(context.Background(), middleware.UserClaimsKey, middleware.UserClaims{UserID: userID, Role: role})
+}
+
+func TestAdjustStock_Forbidden_WarehouseNotAssigned(t *testing.T) {
+	mockRepo := new(MockStockRepository)
+	mockAccess := new(MockWarehouseAccess)
+	svc := stockservice.NewService(mockRepo, mockAccess, logger.NewLogger("debug"))
+
+	userID := uuid.New().String()
+	mockAccess.On("GetUserWarehouses", mock.Anything, userID).
+		Return(domain.UserWarehouses{UserID: userID, WarehouseIDs: []string{uuid.New().String()}}, nil)
+
+	adjustment := domain.StockAdjustmentRequest{VariantID: uuid.New().String(), WarehouseID: uuid.New().String(), Delta: 5}
+	_, err := svc.AdjustStock(userContext(userID, domain.RoleUser), adjustment)
+
+	var unauthorized *apperror.UnauthorizedError
+	assert.ErrorAs(t, err, &unauthorized)
+	assert.Equal(t, http.StatusForbidden, unauthorized.HTTPStatus())
+	assert.Contains(t, err.Error(), adjustment.WarehouseID)
+	mockRepo.AssertNotCalled(t, "UpdateStockLevel", mock.Anything, mock.Anything)
+}
+
+func TestAdjustStock_Success_AssignedWarehouse(t *testing.T) {
+	mockRepo := new(MockStockRepository)
+	mockAccess := new(MockWarehouseAccess)
+	svc := stockservice.NewService(mockRepo, mockAccess, logger.NewLogger("debug"))
+
+	userID := uuid.New().String()
+	adjustment := domain.StockAdjustmentRequest{VariantID: uuid.New().String(), WarehouseID: uuid.New().String(), Delta: 5}
+	mockAccess.On("GetUserWarehouses", mock.Anything, userID).
+		Return(domain.UserWarehouses{UserID: userID, WarehouseIDs: []string{adjustment.WarehouseID}}, nil)
+	mockRepo.On("UpdateStockLevel", mock.Anything, adjustment).
+		Return(domain.StockLevel{VariantID: adjustment.VariantID, WarehouseID: adjustment.WarehouseID, Quantity: 5, Version: 1}, nil)
+
+	level, err := svc.AdjustStock(userContext(userID, domain.RoleUser), adjustment)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, level.Quantity)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAdjustStock_AdminIsGlobal(t *testing.T) {
+	mockRepo := new(MockStockRepository)
+	mockAccess := new(MockWarehouseAccess)
+	svc := stockservice.NewService(mockRepo, mockAccess, logger.NewLogger("debug"))
+
+	adjustment := domain.StockAdjustmentRequest{VariantID: uuid.New().String(), WarehouseID: uuid.New().String(), Delta: -2}
+	mockRepo.On("UpdateStockLevel", mock.Anything, adjustment).
+		Return(domain.StockLevel{VariantID: adjustment.VariantID, WarehouseID: adjustment.WarehouseID, Quantity: 3}, nil)
+
+	_, err := svc.AdjustStock(userContext(uuid.New().String(), domain.RoleAdmin), adjustment)
+
+	assert.NoError(t, err)
+	mockAccess.AssertNotCalled(t, "GetUserWarehouses", mock.Anything, mock.Anything)
+}
+
+func TestGetVariantStock_FiltersUnassignedWarehouses(t *testing.T) {
+	mockRepo := new(MockStockRepository)
+	mockAccess := new(MockWarehouseAccess)
+	svc := stockservice.NewService(mockRepo, mockAccess, logger.NewLogger("debug"))
+
+	userID, variantID := uuid.New().String(), uuid.New().String()
+	own, other := uuid.New().String(), uuid.New().String()
+	mockRepo.On("FindStockLevelsByVariant", mock.Anything, variantID).Return([]domain.StockLevel{
+		{VariantID: variantID, WarehouseID: own, Quantity: 4},
+		{VariantID: variantID, WarehouseID: other, Quantity: 9},
+	}, nil)
+	mockRepo.On("GetVariantUnits", mock.Anything, variantID).Return(domain.VariantUnits{}, apperror.NewNotFoundError("sem unidades"))
+	mockAccess.On("GetUserWarehouses", mock.Anything, userID).
+		Return(domain.UserWarehouses{UserID: userID, WarehouseIDs: []string{own}}, nil)
+
+	levels, err := svc.GetVariantStock(userContext(userID, domain.RoleUser), variantID)
+
+	assert.NoError(t, err)
+	assert.Len(t, levels, 1)
+	assert.Equal(t, own, levels[0].WarehouseID)
+}
+
+func TestExportStock_RestrictsToAssignedWarehouses(t *testing.T) {
+	mockRepo := new(MockStockRepository)
+	mockAccess := new(MockWarehouseAccess)
+	svc := stockservice.NewService(mockRepo, mockAccess, logger.NewLogger("debug"))
+
+	userID := uuid.New().String()
+	assigned := []string{uuid.New().String(), uuid.New().String()}
+	mockAccess.On("GetUserWarehouses", mock.Anything, userID).
+		Return(domain.UserWarehouses{UserID: userID, WarehouseIDs: assigned}, nil)
+	mockRepo.On("StreamExportRows", mock.Anything, domain.StockExportFilter{WarehouseIDs: assigned}).Return(nil, nil)
+
+	err := svc.ExportStock(userContext(userID, domain.RoleUser), domain.StockExportFilter{}, func(domain.StockExportRow) error { return nil })
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExportStock_Forbidden_NoWarehouses(t *testing.T) {
+	mockRepo := new(MockStockRepository)
+	mockAccess := new(MockWarehouseAccess)
+	svc := stockservice.NewService(mockRepo, mockAccess, logger.NewLogger("debug"))
+
+	userID := uuid.New().String()
+	mockAccess.On("GetUserWarehouses", mock.Anything, userID).
+		Return(domain.UserWarehouses{UserID: userID, WarehouseIDs: []string{}}, nil)
+
+	err := svc.ExportStock(userContext(userID, domain.RoleUser), domain.StockExportFilter{}, func(domain.StockExportRow) error { return nil })
+
+	status, category, _ := apperror.MapToHTTPStatus(err)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, "FORBIDDEN", category)
+	mockRepo.AssertNotCalled(t, "StreamExportRows", mock.Anything, mock.Anything)
+}
+
+// TestAdjustStock_Fail_LogsRequestID testa que as linhas de log de um ajuste com falha trazem o ID
+// da requisição do contexto, para correlacioná-las com o erro recebido pelo cliente.
+func TestAdjustStock_Fail_LogsRequestID(t *testing.T) {
+	var output bytes.Buffer
+	log.SetOutput(&output)
+	defer log.SetOutput(os.Stderr)
+
+	mockRepo := new(MockStockRepository)
+	svc := stockservice.NewService(mockRepo, new(MockWarehouseAccess), logger.NewLogger("debug"))
+
+	mockRepo.On("UpdateStockLevel", mock.Anything, mock.AnythingOfType("domain.StockAdjustmentRequest")).
+		Return(domain.StockLevel{}, apperror.NewConflictError("O estoque foi modificado por outra operação. Tente novamente."))
+
+	ctx := requestid.WithID(context.Background(), "req-123")
+	_, err := svc.AdjustStock(ctx, domain.StockAdjustmentRequest{VariantID: uuid.New().String(), WarehouseID: uuid.New().String(), Delta: 1})
+
+	assert.IsType(t, &apperror.ConflictError{}, err)
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) == 0 || lines[0] == "" {
+		t.Fatalf("nenhuma linha de log registrada")
+	}
+	for _, line := range lines {
+		assert.Contains(t, line, `"request_id":"req-123"`)
+	}
+}
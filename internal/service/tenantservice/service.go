@@ -0,0 +1,185 @@
+package tenantservice
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/tenant"
+)
+
+// slugPattern restringe o identificador público da empresa (usado no header X-Tenant e em URLs).
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,48}[a-z0-9]$`)
+
+// currencyPattern valida o código ISO 4217 da moeda padrão.
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// DefaultSettings são as configurações das novas empresas criadas sem configurações explícitas.
+// O registro público começa desativado: os usuários entram por convite do administrador da empresa.
+var DefaultSettings = domain.TenantSettings{
+	DefaultCurrency:   "BRL",
+	Timezone:          "America/Sao_Paulo",
+	Locale:            "pt-BR",
+	AllowRegistration: false,
+}
+
+// Service implementa as regras de cadastro e configuração das empresas (tenants).
+type Service struct {
+	repo   domain.TenantRepository
+	logger logger.Logger
+}
+
+// NewService cria e retorna uma nova instância do Serviço de Empresas.
+func NewService(repo domain.TenantRepository, logger logger.Logger) *Service {
+	return &Service{repo: repo, logger: logger}
+}
+
+// ResolveTenant busca a empresa pelo slug informado no header X-Tenant (middleware de empresas).
+func (s *Service) ResolveTenant(ctx context.Context, slug string) (domain.Tenant, error) {
+	return s.repo.FindTenantBySlug(ctx, strings.ToLower(strings.TrimSpace(slug)))
+}
+
+// GetTenants lista todas as empresas (operação da plataforma).
+func (s *Service) GetTenants(ctx domain.Context) ([]domain.Tenant, error) {
+	return s.repo.ListTenants(s.context(ctx))
+}
+
+// GetTenant busca uma empresa pelo ID (operação da plataforma).
+func (s *Service) GetTenant(ctx domain.Context, id string) (domain.Tenant, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return domain.Tenant{}, apperror.NewValidationError("O ID da empresa deve ser um UUID válido.")
+	}
+	return s.repo.FindTenant(s.context(ctx), id)
+}
+
+// CreateTenant cadastra uma empresa. Sem configurações no payload, usa DefaultSettings.
+// O primeiro administrador da empresa é criado por convite (POST /v1/invitations com X-Tenant).
+func (s *Service) CreateTenant(ctx domain.Context, req domain.TenantCreate) (domain.Tenant, error) {
+	t := domain.Tenant{
+		ID:       uuid.NewString(),
+		Slug:     strings.ToLower(strings.TrimSpace(req.Slug)),
+		Name:     strings.TrimSpace(req.Name),
+		Settings: DefaultSettings,
+	}
+	if !slugPattern.MatchString(t.Slug) {
+		return domain.Tenant{}, apperror.NewValidationError("O identificador ('slug') da empresa deve ter de 3 a 50 caracteres: letras minúsculas, números ou '-', sem '-' nas pontas.")
+	}
+	if req.Settings != nil {
+		t.Settings = *req.Settings
+	}
+	if err := validateTenant(&t); err != nil {
+		return domain.Tenant{}, err
+	}
+
+	created, err := s.repo.CreateTenant(s.context(ctx), t)
+	if err != nil {
+		return domain.Tenant{}, err
+	}
+	s.logger.WithContext(ctx).Info("Empresa criada.", map[string]interface{}{"tenant_id": created.ID, "slug": created.Slug})
+	return created, nil
+}
+
+// UpdateTenant altera uma empresa (operação da plataforma), inclusive a sua ativação.
+func (s *Service) UpdateTenant(ctx domain.Context, id string, req domain.TenantUpdate) (domain.Tenant, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return domain.Tenant{}, apperror.NewValidationError("O ID da empresa deve ser um UUID válido.")
+	}
+	if req.Disabled != nil && *req.Disabled && tenant.IsPlatform(id) {
+		return domain.Tenant{}, apperror.NewConflictError("A empresa padrão opera a plataforma e não pode ser desativada.")
+	}
+	return s.update(s.context(ctx), id, req)
+}
+
+// GetCurrentTenant retorna a empresa do usuário autenticado.
+func (s *Service) GetCurrentTenant(ctx domain.Context) (domain.Tenant, error) {
+	ctxGo := s.context(ctx)
+	return s.repo.FindTenant(ctxGo, tenant.ID(ctxGo))
+}
+
+// UpdateCurrentTenant altera o nome e as configurações da empresa do administrador autenticado.
+// A ativação da empresa é exclusiva da operação da plataforma.
+func (s *Service) UpdateCurrentTenant(ctx domain.Context, req domain.TenantUpdate) (domain.Tenant, error) {
+	if req.Disabled != nil {
+		return domain.Tenant{}, apperror.NewForbiddenError("Apenas a administração da plataforma pode ativar ou desativar empresas.")
+	}
+	ctxGo := s.context(ctx)
+	return s.update(ctxGo, tenant.ID(ctxGo), req)
+}
+
+// update aplica os campos informados à empresa e grava a alteração.
+func (s *Service) update(ctx context.Context, id string, req domain.TenantUpdate) (domain.Tenant, error) {
+	t, err := s.repo.FindTenant(ctx, id)
+	if err != nil {
+		return domain.Tenant{}, err
+	}
+	if req.Name != nil {
+		t.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Settings != nil {
+		t.Settings = *req.Settings
+	}
+	if req.Disabled != nil {
+		t.Disabled = *req.Disabled
+	}
+	if err := validateTenant(&t); err != nil {
+		return domain.Tenant{}, err
+	}
+
+	updated, err := s.repo.UpdateTenant(ctx, t)
+	if err != nil {
+		return domain.Tenant{}, err
+	}
+	s.logger.WithContext(ctx).Info("Empresa atualizada.", map[string]interface{}{"tenant_id": updated.ID, "disabled": updated.Disabled})
+	return updated, nil
+}
+
+// validateTenant valida o nome e normaliza as configurações da empresa.
+func validateTenant(t *domain.Tenant) error {
+	var fields []apperror.FieldError
+	invalid := func(field, msg string) {
+		fields = append(fields, apperror.FieldError{Field: field, Message: msg})
+	}
+
+	if t.Name == "" || len(t.Name) > 255 {
+		invalid("name", "O nome da empresa é obrigatório e deve ter no máximo 255 caracteres.")
+	}
+
+	settings := &t.Settings
+	settings.DefaultCurrency = strings.ToUpper(strings.TrimSpace(settings.DefaultCurrency))
+	if !currencyPattern.MatchString(settings.DefaultCurrency) {
+		invalid("settings.default_currency", "A moeda padrão deve ser um código ISO 4217, como 'BRL'.")
+	}
+	settings.Timezone = strings.TrimSpace(settings.Timezone)
+	if _, err := time.LoadLocation(settings.Timezone); err != nil || settings.Timezone == "" {
+		invalid("settings.timezone", fmt.Sprintf("Fuso horário inválido: '%s'.", settings.Timezone))
+	}
+	settings.Locale = strings.TrimSpace(settings.Locale)
+	if settings.Locale == "" || len(settings.Locale) > 35 {
+		invalid("settings.locale", "O idioma é obrigatório, como 'pt-BR'.")
+	}
+	if settings.MaxUsers < 0 {
+		invalid("settings.max_users", "O limite de usuários não pode ser negativo (0: sem limite).")
+	}
+
+	if len(fields) > 0 {
+		return apperror.NewFieldValidationError("Dados da empresa inválidos.", fields...)
+	}
+	return nil
+}
+
+// context converte o contexto de domínio para context.Context.
+func (s *Service) context(ctx domain.Context) context.Context {
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background()", nil)
+		return context.Background()
+	}
+	return ctxGo
+}
@@ -0,0 +1,172 @@
+package tenantservice_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/tenant"
+	"gostock/internal/service/tenantservice"
+)
+
+// MockTenantRepository é uma implementação mock da interface domain.TenantRepository
+type MockTenantRepository struct {
+	mock.Mock
+}
+
+func (m *MockTenantRepository) CreateTenant(ctx domain.Context, t domain.Tenant) (domain.Tenant, error) {
+	args := m.Called(ctx, t)
+	return args.Get(0).(domain.Tenant), args.Error(1)
+}
+
+func (m *MockTenantRepository) FindTenant(ctx domain.Context, id string) (domain.Tenant, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.Tenant), args.Error(1)
+}
+
+func (m *MockTenantRepository) FindTenantBySlug(ctx domain.Context, slug string) (domain.Tenant, error) {
+	args := m.Called(ctx, slug)
+	return args.Get(0).(domain.Tenant), args.Error(1)
+}
+
+func (m *MockTenantRepository) ListTenants(ctx domain.Context) ([]domain.Tenant, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.Tenant), args.Error(1)
+}
+
+func (m *MockTenantRepository) UpdateTenant(ctx domain.Context, t domain.Tenant) (domain.Tenant, error) {
+	args := m.Called(ctx, t)
+	return args.Get(0).(domain.Tenant), args.Error(1)
+}
+
+func (m *MockTenantRepository) CountTenantUsers(ctx domain.Context, id string) (int, error) {
+	args := m.Called(ctx, id)
+	return args.Int(0), args.Error(1)
+}
+
+const acmeID = "7b0c6a52-3f1e-4b8e-9d2a-0c6f1e2d3a4b"
+
+func newTestService() (*tenantservice.Service, *MockTenantRepository) {
+	repo := new(MockTenantRepository)
+	return tenantservice.NewService(repo, logger.NewLogger("debug")), repo
+}
+
+func acmeTenant() domain.Tenant {
+	return domain.Tenant{ID: acmeID, Slug: "acme", Name: "ACME", Settings: tenantservice.DefaultSettings}
+}
+
+// TestCreateTenant_DefaultSettings testa a criação com as configurações padrão e o slug normalizado.
+func TestCreateTenant_DefaultSettings(t *testing.T) {
+	svc, repo := newTestService()
+	ctx := context.Background()
+
+	repo.On("CreateTenant", ctx, mock.MatchedBy(func(t domain.Tenant) bool {
+		return t.Slug == "acme" && t.Name == "ACME" && t.Settings == tenantservice.DefaultSettings && t.ID != ""
+	})).Return(acmeTenant(), nil)
+
+	created, err := svc.CreateTenant(ctx, domain.TenantCreate{Slug: " ACME ", Name: "ACME"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", created.Slug)
+	repo.AssertExpectations(t)
+}
+
+// TestCreateTenant_InvalidInput testa a validação do slug e das configurações.
+func TestCreateTenant_InvalidInput(t *testing.T) {
+	svc, repo := newTestService()
+	ctx := context.Background()
+
+	cases := map[string]domain.TenantCreate{
+		"slug inválido":   {Slug: "a", Name: "ACME"},
+		"nome ausente":    {Slug: "acme", Name: " "},
+		"moeda inválida":  {Slug: "acme", Name: "ACME", Settings: &domain.TenantSettings{DefaultCurrency: "real", Timezone: "UTC", Locale: "pt-BR"}},
+		"fuso inválido":   {Slug: "acme", Name: "ACME", Settings: &domain.TenantSettings{DefaultCurrency: "BRL", Timezone: "Lua/Base", Locale: "pt-BR"}},
+		"limite negativo": {Slug: "acme", Name: "ACME", Settings: &domain.TenantSettings{DefaultCurrency: "BRL", Timezone: "UTC", Locale: "pt-BR", MaxUsers: -1}},
+	}
+	for name, req := range cases {
+		_, err := svc.CreateTenant(ctx, req)
+		assert.IsType(t, &apperror.ValidationError{}, err, name)
+	}
+	repo.AssertNotCalled(t, "CreateTenant", mock.Anything, mock.Anything)
+}
+
+// TestCreateTenant_InvalidFields testa que todos os campos inválidos são detalhados no erro de validação.
+func TestCreateTenant_InvalidFields(t *testing.T) {
+	svc, _ := newTestService()
+
+	_, err := svc.CreateTenant(context.Background(), domain.TenantCreate{Slug: "acme", Name: "ACME",
+		Settings: &domain.TenantSettings{DefaultCurrency: "real", Timezone: "Lua/Base", Locale: "pt-BR"}})
+
+	validationErr, ok := err.(*apperror.ValidationError)
+	if !ok {
+		t.Fatalf("esperava ValidationError, obteve %v", err)
+	}
+	var fields []string
+	for _, f := range validationErr.Fields {
+		fields = append(fields, f.Field)
+	}
+	assert.Equal(t, []string{"settings.default_currency", "settings.timezone"}, fields)
+}
+
+// TestUpdateTenant_CannotDisableDefault testa que a empresa padrão (plataforma) não pode ser desativada.
+func TestUpdateTenant_CannotDisableDefault(t *testing.T) {
+	svc, repo := newTestService()
+	disabled := true
+
+	_, err := svc.UpdateTenant(context.Background(), tenant.DefaultID, domain.TenantUpdate{Disabled: &disabled})
+
+	assert.IsType(t, &apperror.ConflictError{}, err)
+	repo.AssertNotCalled(t, "UpdateTenant", mock.Anything, mock.Anything)
+}
+
+// TestUpdateTenant_Disable testa a desativação de uma empresa pela plataforma, mantendo os demais campos.
+func TestUpdateTenant_Disable(t *testing.T) {
+	svc, repo := newTestService()
+	ctx := context.Background()
+	disabled := true
+
+	expected := acmeTenant()
+	expected.Disabled = true
+	repo.On("FindTenant", ctx, acmeID).Return(acmeTenant(), nil)
+	repo.On("UpdateTenant", ctx, expected).Return(expected, nil)
+
+	updated, err := svc.UpdateTenant(ctx, acmeID, domain.TenantUpdate{Disabled: &disabled})
+
+	assert.NoError(t, err)
+	assert.True(t, updated.Disabled)
+	repo.AssertExpectations(t)
+}
+
+// TestUpdateCurrentTenant testa que o administrador da empresa altera apenas a própria empresa.
+func TestUpdateCurrentTenant(t *testing.T) {
+	svc, repo := newTestService()
+	ctx := tenant.WithID(context.Background(), acmeID)
+	name := "ACME Distribuidora"
+
+	expected := acmeTenant()
+	expected.Name = name
+	repo.On("FindTenant", ctx, acmeID).Return(acmeTenant(), nil)
+	repo.On("UpdateTenant", ctx, expected).Return(expected, nil)
+
+	updated, err := svc.UpdateCurrentTenant(ctx, domain.TenantUpdate{Name: &name})
+
+	assert.NoError(t, err)
+	assert.Equal(t, name, updated.Name)
+	repo.AssertExpectations(t)
+}
+
+// TestUpdateCurrentTenant_CannotToggleDisabled testa que a ativação é exclusiva da plataforma.
+func TestUpdateCurrentTenant_CannotToggleDisabled(t *testing.T) {
+	svc, repo := newTestService()
+	enabled := false
+
+	_, err := svc.UpdateCurrentTenant(tenant.WithID(context.Background(), acmeID), domain.TenantUpdate{Disabled: &enabled})
+
+	assert.IsType(t, &apperror.UnauthorizedError{}, err)
+	repo.AssertNotCalled(t, "FindTenant", mock.Anything, mock.Anything)
+}
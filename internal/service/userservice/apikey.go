@@ -0,0 +1,256 @@
+package userservice
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/middleware"
+	"gostock/internal/pkg/token"
+)
+
+const (
+	// apiKeyTouchInterval limita a gravação do último uso a uma vez por minuto por chave,
+	// para que integrações com muitas requisições não gerem uma escrita por chamada.
+	apiKeyTouchInterval = time.Minute
+	apiKeyNameMaxLength = 100
+)
+
+// SetAPIKeys habilita as chaves de API e as contas de serviço. Sem esta configuração, o header
+// X-API-Key é recusado e apenas os access tokens são aceitos.
+func (s *UserService) SetAPIKeys(repo domain.APIKeyRepository) {
+	s.apiKeys = repo
+}
+
+// CreateServiceAccount cria uma conta de serviço: um usuário sem senha, que não faz login e acessa a
+// API apenas com chaves de API. O papel da conta limita as permissões de todas as suas chaves.
+func (s *UserService) CreateServiceAccount(ctx context.Context, request domain.ServiceAccountCreate) (domain.User, error) {
+	if s.apiKeys == nil || s.roles == nil {
+		return domain.User{}, apperror.NewInternalError("Contas de serviço não configuradas.", nil)
+	}
+
+	email := strings.TrimSpace(request.Email)
+	if err := validateEmail(email); err != nil {
+		return domain.User{}, err
+	}
+	role := strings.ToLower(strings.TrimSpace(request.Role))
+	if role == "" {
+		role = string(domain.DefaultRole)
+	}
+	if _, err := s.roles.FindRole(ctx, role); err != nil {
+		return domain.User{}, err // NotFoundError para papel inexistente
+	}
+
+	user, err := s.saveUser(ctx, domain.User{
+		Email:          email,
+		Role:           domain.UserRole(role),
+		ServiceAccount: true,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	})
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	s.logger.WithContext(ctx).Info("Conta de serviço criada.", map[string]interface{}{"user_id": user.ID, "email": user.Email, "role": role})
+	return user, nil
+}
+
+// CreateAPIKey cria uma chave de API para o usuário autenticado ou, se ele for admin, para o usuário
+// indicado em UserID (em geral, uma conta de serviço). A chave completa só é retornada aqui.
+func (s *UserService) CreateAPIKey(ctx context.Context, request domain.APIKeyCreate) (domain.APIKeyCreated, error) {
+	if s.apiKeys == nil {
+		return domain.APIKeyCreated{}, apperror.NewInternalError("Chaves de API não configuradas.", nil)
+	}
+	claims, ok := middleware.GetUserClaimsFromContext(ctx)
+	if !ok {
+		return domain.APIKeyCreated{}, apperror.NewUnauthorizedError("Autorização necessária.")
+	}
+
+	name := strings.TrimSpace(request.Name)
+	if name == "" || len(name) > apiKeyNameMaxLength {
+		return domain.APIKeyCreated{}, apperror.NewValidationError("O nome da chave é obrigatório (até 100 caracteres).")
+	}
+	scopes, err := normalizeScopes(request.Scopes)
+	if err != nil {
+		return domain.APIKeyCreated{}, err
+	}
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		return domain.APIKeyCreated{}, apperror.NewValidationError("A data de expiração deve estar no futuro.")
+	}
+
+	ownerID, err := s.apiKeyOwner(claims, request.UserID)
+	if err != nil {
+		return domain.APIKeyCreated{}, err
+	}
+	owner, err := s.UserRepo.FindByID(ctx, ownerID)
+	if err != nil {
+		return domain.APIKeyCreated{}, err
+	}
+	if owner.Disabled {
+		return domain.APIKeyCreated{}, apperror.NewConflictError("Não é possível criar chaves para uma conta desativada.")
+	}
+
+	plain, prefix, hash, err := token.NewAPIKey()
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao gerar chave de API.", err)
+		return domain.APIKeyCreated{}, apperror.NewInternalError("Falha ao gerar a chave de API.", err)
+	}
+	key := domain.APIKey{
+		ID:        uuid.NewString(),
+		UserID:    owner.ID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   hash,
+		Scopes:    scopes,
+		ExpiresAt: request.ExpiresAt,
+		CreatedBy: claims.UserID,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.apiKeys.SaveAPIKey(ctx, key); err != nil {
+		return domain.APIKeyCreated{}, err
+	}
+
+	s.logger.WithContext(ctx).Info("Chave de API criada.", map[string]interface{}{"api_key_id": key.ID, "prefix": prefix, "user_id": owner.ID, "created_by": claims.UserID, "scopes": scopes})
+	return domain.APIKeyCreated{APIKey: key, Key: plain}, nil
+}
+
+// ListAPIKeys lista as chaves do usuário autenticado ou, se ele for admin, do usuário informado.
+func (s *UserService) ListAPIKeys(ctx context.Context, userID string) ([]domain.APIKey, error) {
+	if s.apiKeys == nil {
+		return nil, apperror.NewInternalError("Chaves de API não configuradas.", nil)
+	}
+	claims, ok := middleware.GetUserClaimsFromContext(ctx)
+	if !ok {
+		return nil, apperror.NewUnauthorizedError("Autorização necessária.")
+	}
+	ownerID, err := s.apiKeyOwner(claims, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.apiKeys.ListAPIKeys(ctx, ownerID)
+}
+
+// RevokeAPIKey revoga a chave. O dono revoga as próprias chaves; o admin, qualquer chave.
+func (s *UserService) RevokeAPIKey(ctx context.Context, id string) error {
+	if s.apiKeys == nil {
+		return apperror.NewInternalError("Chaves de API não configuradas.", nil)
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return apperror.NewValidationError("O ID da chave deve ser um UUID válido.")
+	}
+	claims, ok := middleware.GetUserClaimsFromContext(ctx)
+	if !ok {
+		return apperror.NewUnauthorizedError("Autorização necessária.")
+	}
+
+	key, err := s.apiKeys.GetAPIKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	// Chaves de outros usuários são tratadas como inexistentes, para não revelar os IDs
+	if key.UserID != claims.UserID && claims.Role != domain.RoleAdmin {
+		return apperror.NewNotFoundError("Chave de API não encontrada.")
+	}
+	if err := s.apiKeys.RevokeAPIKey(ctx, id); err != nil {
+		return err
+	}
+
+	s.logger.WithContext(ctx).Info("Chave de API revogada.", map[string]interface{}{"api_key_id": id, "prefix": key.Prefix, "user_id": key.UserID, "revoked_by": claims.UserID})
+	return nil
+}
+
+// AuthenticateAPIKey valida a chave recebida no header X-API-Key e retorna a chave e o seu dono.
+// Chaves com formato inválido, desconhecidas, revogadas ou expiradas e chaves de contas desativadas
+// recebem a mesma resposta. O último uso é registrado, no máximo, uma vez por minuto.
+func (s *UserService) AuthenticateAPIKey(ctx context.Context, plain string) (domain.APIKey, domain.User, error) {
+	invalid := apperror.NewUnauthorizedError("Chave de API inválida, expirada ou revogada.")
+	if s.apiKeys == nil {
+		return domain.APIKey{}, domain.User{}, invalid
+	}
+
+	prefix, ok := token.ParseAPIKey(plain)
+	if !ok {
+		return domain.APIKey{}, domain.User{}, invalid
+	}
+	key, err := s.apiKeys.FindAPIKeyByPrefix(ctx, prefix)
+	var notFoundErr *apperror.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		s.logger.WithContext(ctx).Warn("Chave de API desconhecida.", map[string]interface{}{"prefix": prefix})
+		return domain.APIKey{}, domain.User{}, invalid
+	}
+	if err != nil {
+		return domain.APIKey{}, domain.User{}, err
+	}
+	if subtle.ConstantTimeCompare([]byte(token.HashAPIKey(plain)), []byte(key.KeyHash)) != 1 {
+		s.logger.WithContext(ctx).Warn("Chave de API com segredo incorreto.", map[string]interface{}{"prefix": prefix})
+		return domain.APIKey{}, domain.User{}, invalid
+	}
+	now := time.Now()
+	if !key.Active(now) {
+		s.logger.WithContext(ctx).Info("Chave de API expirada ou revogada.", map[string]interface{}{"api_key_id": key.ID, "prefix": prefix})
+		return domain.APIKey{}, domain.User{}, invalid
+	}
+
+	owner, err := s.UserRepo.FindByID(ctx, key.UserID)
+	if errors.As(err, &notFoundErr) {
+		return domain.APIKey{}, domain.User{}, invalid
+	}
+	if err != nil {
+		return domain.APIKey{}, domain.User{}, err
+	}
+	if owner.Disabled {
+		s.logger.WithContext(ctx).Warn("Chave de API de conta desativada.", map[string]interface{}{"api_key_id": key.ID, "user_id": owner.ID})
+		return domain.APIKey{}, domain.User{}, invalid
+	}
+	if err := s.checkTenantActive(ctx, owner); err != nil {
+		return domain.APIKey{}, domain.User{}, err
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.apiKeys.TouchAPIKey(ctx, key.ID, now.UTC()); err != nil {
+			s.logger.WithContext(ctx).Error("Falha ao registrar o uso da chave de API.", err)
+		}
+	}
+	return key, owner, nil
+}
+
+// apiKeyOwner resolve o dono das chaves de uma operação: o próprio usuário ou, apenas para admin,
+// o usuário informado.
+func (s *UserService) apiKeyOwner(claims middleware.UserClaims, userID string) (string, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" || userID == claims.UserID {
+		return claims.UserID, nil
+	}
+	if claims.Role != domain.RoleAdmin {
+		return "", apperror.NewForbiddenError("Apenas administradores gerenciam chaves de outros usuários.")
+	}
+	if _, err := uuid.Parse(userID); err != nil {
+		return "", apperror.NewValidationError("O ID do usuário deve ser um UUID válido.")
+	}
+	return userID, nil
+}
+
+// normalizeScopes valida os escopos no catálogo de permissões e remove as repetições. Uma chave sem
+// escopos só acessa as rotas de leitura.
+func normalizeScopes(requested []domain.Permission) ([]domain.Permission, error) {
+	scopes := make([]domain.Permission, 0, len(requested))
+	seen := make(map[domain.Permission]bool, len(requested))
+	for _, scope := range requested {
+		scope = domain.Permission(strings.TrimSpace(string(scope)))
+		if !domain.IsKnownPermission(scope) {
+			return nil, apperror.NewValidationError("Escopo desconhecido: '" + string(scope) + "'. Consulte GET /v1/permissions.")
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
@@ -0,0 +1,245 @@
+package userservice_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/middleware"
+	"gostock/internal/pkg/token"
+	"gostock/internal/service/userservice"
+)
+
+// MockAPIKeyRepository simula a persistência das chaves de API.
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) SaveAPIKey(ctx domain.Context, key domain.APIKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) FindAPIKeyByPrefix(ctx domain.Context, prefix string) (domain.APIKey, error) {
+	args := m.Called(ctx, prefix)
+	return args.Get(0).(domain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetAPIKey(ctx domain.Context, id string) (domain.APIKey, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) ListAPIKeys(ctx domain.Context, userID string) ([]domain.APIKey, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]domain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) RevokeAPIKey(ctx domain.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) TouchAPIKey(ctx domain.Context, id string, usedAt time.Time) error {
+	args := m.Called(ctx, id, usedAt)
+	return args.Error(0)
+}
+
+func newAPIKeyService() (*userservice.UserService, *MockUserRepository, *MockAPIKeyRepository) {
+	svc, repo, _, _ := newTestService()
+	keys := new(MockAPIKeyRepository)
+	svc.SetAPIKeys(keys)
+	return svc, repo, keys
+}
+
+// userContext simula um usuário comum autenticado pelo middleware.
+func userContext(userID string) context.Context {
+	return context.WithValue(context.Background(), middleware.UserClaimsKey, middleware.UserClaims{UserID: userID, Role: domain.RoleUser})
+}
+
+// storedKey gera uma chave válida e o registro correspondente no banco.
+func storedKey(userID string) (string, domain.APIKey) {
+	plain, prefix, hash, _ := token.NewAPIKey()
+	return plain, domain.APIKey{ID: uuid.NewString(), UserID: userID, Name: "ERP", Prefix: prefix, KeyHash: hash, Scopes: []domain.Permission{domain.PermissionStockAdjust}}
+}
+
+func TestCreateAPIKey_Success_ReturnsSecretOnce(t *testing.T) {
+	svc, repo, keys := newAPIKeyService()
+	repo.On("FindByID", mock.Anything, "user-1").Return(domain.User{ID: "user-1", Role: domain.RoleUser}, nil)
+	var saved domain.APIKey
+	keys.On("SaveAPIKey", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(domain.APIKey)
+	}).Return(nil)
+
+	created, err := svc.CreateAPIKey(userContext("user-1"), domain.APIKeyCreate{
+		Name:   "Conector ERP",
+		Scopes: []domain.Permission{domain.PermissionStockAdjust, domain.PermissionStockAdjust, domain.PermissionExportRead},
+	})
+
+	assert.NoError(t, err)
+	prefix, ok := token.ParseAPIKey(created.Key)
+	assert.True(t, ok)
+	assert.Equal(t, saved.Prefix, prefix)
+	assert.Equal(t, token.HashAPIKey(created.Key), saved.KeyHash) // Apenas o hash é gravado
+	assert.NotContains(t, saved.KeyHash, created.Key)
+	assert.Equal(t, []domain.Permission{domain.PermissionStockAdjust, domain.PermissionExportRead}, saved.Scopes)
+	assert.Equal(t, "user-1", saved.UserID)
+	assert.Equal(t, "user-1", saved.CreatedBy)
+}
+
+func TestCreateAPIKey_Fail_UnknownScope(t *testing.T) {
+	svc, _, keys := newAPIKeyService()
+
+	_, err := svc.CreateAPIKey(userContext("user-1"), domain.APIKeyCreate{Name: "ERP", Scopes: []domain.Permission{"stock:delete"}})
+
+	var validation *apperror.ValidationError
+	assert.ErrorAs(t, err, &validation)
+	keys.AssertNotCalled(t, "SaveAPIKey", mock.Anything, mock.Anything)
+}
+
+func TestCreateAPIKey_Fail_ForOtherUserWithoutAdmin(t *testing.T) {
+	svc, _, keys := newAPIKeyService()
+
+	_, err := svc.CreateAPIKey(userContext("user-1"), domain.APIKeyCreate{Name: "ERP", UserID: uuid.NewString()})
+
+	var forbidden *apperror.UnauthorizedError
+	assert.ErrorAs(t, err, &forbidden)
+	assert.True(t, forbidden.Forbidden)
+	keys.AssertNotCalled(t, "SaveAPIKey", mock.Anything, mock.Anything)
+}
+
+func TestCreateAPIKey_AdminForServiceAccount(t *testing.T) {
+	svc, repo, keys := newAPIKeyService()
+	accountID := uuid.NewString()
+	repo.On("FindByID", mock.Anything, accountID).Return(domain.User{ID: accountID, Role: "warehouse_staff", ServiceAccount: true}, nil)
+	keys.On("SaveAPIKey", mock.Anything, mock.MatchedBy(func(k domain.APIKey) bool {
+		return k.UserID == accountID && k.CreatedBy == "admin-1"
+	})).Return(nil)
+
+	expiresAt := time.Now().Add(24 * time.Hour)
+	created, err := svc.CreateAPIKey(adminContext("admin-1"), domain.APIKeyCreate{Name: "E-commerce", UserID: accountID, ExpiresAt: &expiresAt})
+
+	assert.NoError(t, err)
+	assert.Equal(t, accountID, created.UserID)
+	assert.Empty(t, created.Scopes) // Sem escopos: apenas leitura
+	keys.AssertExpectations(t)
+}
+
+func TestAuthenticateAPIKey_Success_TracksLastUse(t *testing.T) {
+	svc, repo, keys := newAPIKeyService()
+	plain, key := storedKey("user-1")
+	keys.On("FindAPIKeyByPrefix", mock.Anything, key.Prefix).Return(key, nil)
+	keys.On("TouchAPIKey", mock.Anything, key.ID, mock.Anything).Return(nil)
+	repo.On("FindByID", mock.Anything, "user-1").Return(domain.User{ID: "user-1", Role: "warehouse_staff"}, nil)
+
+	found, owner, err := svc.AuthenticateAPIKey(context.Background(), plain)
+
+	assert.NoError(t, err)
+	assert.Equal(t, key.ID, found.ID)
+	assert.Equal(t, domain.UserRole("warehouse_staff"), owner.Role)
+	keys.AssertExpectations(t)
+}
+
+func TestAuthenticateAPIKey_RecentlyUsed_SkipsTouch(t *testing.T) {
+	svc, repo, keys := newAPIKeyService()
+	plain, key := storedKey("user-1")
+	usedAt := time.Now().Add(-10 * time.Second)
+	key.LastUsedAt = &usedAt
+	keys.On("FindAPIKeyByPrefix", mock.Anything, key.Prefix).Return(key, nil)
+	repo.On("FindByID", mock.Anything, "user-1").Return(domain.User{ID: "user-1", Role: domain.RoleUser}, nil)
+
+	_, _, err := svc.AuthenticateAPIKey(context.Background(), plain)
+
+	assert.NoError(t, err)
+	keys.AssertNotCalled(t, "TouchAPIKey", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAuthenticateAPIKey_Fail_Rejected(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name   string
+		modify func(plain string, key *domain.APIKey, owner *domain.User) string
+	}{
+		{"segredo incorreto", func(plain string, key *domain.APIKey, owner *domain.User) string { return plain + "x" }},
+		{"formato inválido", func(plain string, key *domain.APIKey, owner *domain.User) string { return "Bearer " + plain }},
+		{"revogada", func(plain string, key *domain.APIKey, owner *domain.User) string { key.RevokedAt = &past; return plain }},
+		{"expirada", func(plain string, key *domain.APIKey, owner *domain.User) string { key.ExpiresAt = &past; return plain }},
+		{"dono desativado", func(plain string, key *domain.APIKey, owner *domain.User) string { owner.Disabled = true; return plain }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo, keys := newAPIKeyService()
+			plain, key := storedKey("user-1")
+			owner := domain.User{ID: "user-1", Role: domain.RoleUser}
+			plain = tt.modify(plain, &key, &owner)
+			keys.On("FindAPIKeyByPrefix", mock.Anything, key.Prefix).Return(key, nil)
+			repo.On("FindByID", mock.Anything, "user-1").Return(owner, nil)
+
+			_, _, err := svc.AuthenticateAPIKey(context.Background(), plain)
+
+			var unauthorized *apperror.UnauthorizedError
+			assert.ErrorAs(t, err, &unauthorized)
+			keys.AssertNotCalled(t, "TouchAPIKey", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestRevokeAPIKey_Fail_OtherUsersKeyIsHidden(t *testing.T) {
+	svc, _, keys := newAPIKeyService()
+	_, key := storedKey("user-2")
+	keys.On("GetAPIKey", mock.Anything, key.ID).Return(key, nil)
+
+	err := svc.RevokeAPIKey(userContext("user-1"), key.ID)
+
+	var notFound *apperror.NotFoundError
+	assert.ErrorAs(t, err, &notFound)
+	keys.AssertNotCalled(t, "RevokeAPIKey", mock.Anything, mock.Anything)
+}
+
+func TestRevokeAPIKey_Success_Owner(t *testing.T) {
+	svc, _, keys := newAPIKeyService()
+	_, key := storedKey("user-1")
+	keys.On("GetAPIKey", mock.Anything, key.ID).Return(key, nil)
+	keys.On("RevokeAPIKey", mock.Anything, key.ID).Return(nil)
+
+	err := svc.RevokeAPIKey(userContext("user-1"), key.ID)
+
+	assert.NoError(t, err)
+	keys.AssertExpectations(t)
+}
+
+func TestCreateServiceAccount_WithoutPassword(t *testing.T) {
+	svc, repo, keys := newAPIKeyService()
+	roles := new(MockRoleFinder)
+	svc.SetOnboarding(roles, nil, userservice.OnboardingConfig{})
+	svc.SetAPIKeys(keys)
+	roles.On("FindRole", mock.Anything, "warehouse_staff").Return(domain.Role{Name: "warehouse_staff"}, nil)
+	repo.On("Save", mock.Anything, mock.MatchedBy(func(u domain.User) bool {
+		return u.ServiceAccount && u.PasswordHash == "" && u.Role == "warehouse_staff"
+	})).Return(domain.User{ID: "svc-1", Email: "erp@integracoes.gostock.com", Role: "warehouse_staff", ServiceAccount: true}, nil)
+
+	account, err := svc.CreateServiceAccount(adminContext("admin-1"), domain.ServiceAccountCreate{Email: "erp@integracoes.gostock.com", Role: "Warehouse_Staff"})
+
+	assert.NoError(t, err)
+	assert.True(t, account.ServiceAccount)
+	repo.AssertExpectations(t)
+}
+
+func TestLogin_Fail_ServiceAccount(t *testing.T) {
+	svc, repo, _ := newAPIKeyService()
+	repo.On("FindByEmail", mock.Anything, "erp@integracoes.gostock.com").
+		Return(domain.User{ID: "svc-1", Email: "erp@integracoes.gostock.com", Role: domain.RoleUser, ServiceAccount: true}, nil)
+
+	_, err := svc.Login(context.Background(), "erp@integracoes.gostock.com", "qualquer")
+
+	var unauthorized *apperror.UnauthorizedError
+	assert.ErrorAs(t, err, &unauthorized)
+	repo.AssertNotCalled(t, "SaveRefreshToken", mock.Anything, mock.Anything)
+}
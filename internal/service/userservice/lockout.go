@@ -0,0 +1,194 @@
+package userservice
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/loginguard"
+	"gostock/internal/pkg/middleware"
+)
+
+// LoginGuard é o contrato da proteção contra força bruta no login (loginguard.Guard).
+type LoginGuard interface {
+	Check(ctx context.Context, email, ip string) (time.Time, error)
+	RecordFailure(ctx context.Context, email, ip string) (loginguard.Attempt, error)
+	RecordSuccess(ctx context.Context, email string) error
+	Status(ctx context.Context, scope domain.LockoutScope, subject string) (domain.LoginLockout, error)
+	Clear(ctx context.Context, scope domain.LockoutScope, subject string) error
+}
+
+// SetLoginGuard habilita a contagem de falhas de login por conta e por IP, com atrasos
+// progressivos e bloqueios temporários. Sem ela, o login conta apenas com o rate limit por IP.
+func (s *UserService) SetLoginGuard(guard LoginGuard) {
+	s.loginGuard = guard
+}
+
+// checkLoginLockout recusa a tentativa se o e-mail ou o IP estiverem bloqueados. A resposta é a
+// mesma para e-mails cadastrados ou não. Falhas do cache não impedem o login (apenas registradas).
+func (s *UserService) checkLoginLockout(ctx context.Context, email, ip string) error {
+	if s.loginGuard == nil {
+		return nil
+	}
+	until, err := s.loginGuard.Check(ctx, email, ip)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao consultar bloqueio de login; seguindo sem a proteção.", err)
+		return nil
+	}
+	if until.IsZero() {
+		return nil
+	}
+	s.logger.WithContext(ctx).Warn("Evento de segurança: tentativa de login durante bloqueio.", map[string]interface{}{
+		"event": "login_blocked", "email": email, "ip": ip, "locked_until": until,
+	})
+	return loginLockedError(until)
+}
+
+// loginFailed registra a falha de login, aplica o atraso progressivo e retorna o erro da
+// tentativa: credenciais inválidas ou, se esta falha atingiu o limite, o bloqueio.
+func (s *UserService) loginFailed(ctx context.Context, email, ip, reason string) error {
+	fields := map[string]interface{}{"event": "login_failed", "reason": reason, "email": email, "ip": ip}
+	invalid := apperror.NewUnauthorizedError("Credenciais inválidas.")
+	if s.loginGuard == nil {
+		s.logger.WithContext(ctx).Warn("Evento de segurança: falha de login.", fields)
+		return invalid
+	}
+
+	attempt, err := s.loginGuard.RecordFailure(ctx, email, ip)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao registrar tentativa de login.", err)
+		return invalid
+	}
+	fields["failed_attempts"] = attempt.Failures
+	s.logger.WithContext(ctx).Warn("Evento de segurança: falha de login.", fields)
+
+	if !attempt.LockedUntil.IsZero() {
+		s.logger.WithContext(ctx).Warn("Evento de segurança: login bloqueado por excesso de tentativas.", map[string]interface{}{
+			"event": "login_locked", "email": email, "ip": ip, "locked_until": attempt.LockedUntil,
+		})
+		return loginLockedError(attempt.LockedUntil)
+	}
+
+	wait(ctx, attempt.Delay)
+	return invalid
+}
+
+// GetUserLockout retorna a situação da conta na proteção contra força bruta.
+func (s *UserService) GetUserLockout(ctx context.Context, id string) (domain.LoginLockout, error) {
+	if s.loginGuard == nil {
+		return domain.LoginLockout{}, apperror.NewInternalError("Proteção de login não configurada.", nil)
+	}
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return domain.LoginLockout{}, err
+	}
+	return s.lockoutStatus(ctx, domain.LockoutScopeAccount, user.Email)
+}
+
+// ClearUserLockout desbloqueia a conta e zera as falhas de login.
+func (s *UserService) ClearUserLockout(ctx context.Context, id string) error {
+	if s.loginGuard == nil {
+		return apperror.NewInternalError("Proteção de login não configurada.", nil)
+	}
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.clearLockout(ctx, domain.LockoutScopeAccount, user.Email)
+}
+
+// GetIPLockout retorna a situação do IP na proteção contra força bruta.
+func (s *UserService) GetIPLockout(ctx context.Context, ip string) (domain.LoginLockout, error) {
+	if s.loginGuard == nil {
+		return domain.LoginLockout{}, apperror.NewInternalError("Proteção de login não configurada.", nil)
+	}
+	ip, err := parseIP(ip)
+	if err != nil {
+		return domain.LoginLockout{}, err
+	}
+	return s.lockoutStatus(ctx, domain.LockoutScopeIP, ip)
+}
+
+// ClearIPLockout desbloqueia o IP e zera as falhas de login.
+func (s *UserService) ClearIPLockout(ctx context.Context, ip string) error {
+	if s.loginGuard == nil {
+		return apperror.NewInternalError("Proteção de login não configurada.", nil)
+	}
+	ip, err := parseIP(ip)
+	if err != nil {
+		return err
+	}
+	return s.clearLockout(ctx, domain.LockoutScopeIP, ip)
+}
+
+func (s *UserService) lockoutStatus(ctx context.Context, scope domain.LockoutScope, subject string) (domain.LoginLockout, error) {
+	status, err := s.loginGuard.Status(ctx, scope, subject)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao consultar bloqueio de login.", err)
+		return domain.LoginLockout{}, apperror.NewInternalError("Falha ao consultar o bloqueio de login.", err)
+	}
+	return status, nil
+}
+
+func (s *UserService) clearLockout(ctx context.Context, scope domain.LockoutScope, subject string) error {
+	if err := s.loginGuard.Clear(ctx, scope, subject); err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao remover bloqueio de login.", err)
+		return apperror.NewInternalError("Falha ao remover o bloqueio de login.", err)
+	}
+
+	fields := map[string]interface{}{"event": "lockout_cleared", "scope": scope, "subject": subject}
+	if claims, ok := middleware.GetUserClaimsFromContext(ctx); ok {
+		fields["admin_id"] = claims.UserID
+	}
+	s.logger.WithContext(ctx).Warn("Evento de segurança: bloqueio de login removido.", fields)
+	return nil
+}
+
+// loginLockedError é a resposta uniforme de bloqueio, sem indicar se o e-mail existe nem se o
+// bloqueio é da conta ou do IP.
+func loginLockedError(until time.Time) error {
+	retryAfter := max(time.Until(until), time.Second)
+	return apperror.NewTooManyRequestsError("Muitas tentativas de login. Tente novamente mais tarde.", retryAfter)
+}
+
+// wait aguarda o atraso progressivo, encerrando antes se a requisição for cancelada.
+func wait(ctx context.Context, delay time.Duration) {
+	if delay <= 0 {
+		return
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// parseIP valida e normaliza o IP informado pelo administrador.
+func parseIP(value string) (string, error) {
+	ip := net.ParseIP(strings.TrimSpace(value))
+	if ip == nil {
+		return "", apperror.NewValidationError("Informe um IP válido.")
+	}
+	return ip.String(), nil
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// compareDummyPassword gasta o mesmo tempo de uma verificação de senha para e-mails não
+// cadastrados, para que o tempo de resposta não revele quais contas existem.
+func compareDummyPassword(password string) {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("gostock-dummy-password"), bcrypt.DefaultCost)
+	})
+	_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+}
@@ -0,0 +1,224 @@
+package userservice_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/cache"
+	"gostock/internal/pkg/loginguard"
+	"gostock/internal/pkg/middleware"
+)
+
+// MockLoginGuard simula a proteção contra força bruta.
+type MockLoginGuard struct {
+	mock.Mock
+}
+
+func (m *MockLoginGuard) Check(ctx context.Context, email, ip string) (time.Time, error) {
+	args := m.Called(ctx, email, ip)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *MockLoginGuard) RecordFailure(ctx context.Context, email, ip string) (loginguard.Attempt, error) {
+	args := m.Called(ctx, email, ip)
+	return args.Get(0).(loginguard.Attempt), args.Error(1)
+}
+
+func (m *MockLoginGuard) RecordSuccess(ctx context.Context, email string) error {
+	args := m.Called(ctx, email)
+	return args.Error(0)
+}
+
+func (m *MockLoginGuard) Status(ctx context.Context, scope domain.LockoutScope, subject string) (domain.LoginLockout, error) {
+	args := m.Called(ctx, scope, subject)
+	return args.Get(0).(domain.LoginLockout), args.Error(1)
+}
+
+func (m *MockLoginGuard) Clear(ctx context.Context, scope domain.LockoutScope, subject string) error {
+	args := m.Called(ctx, scope, subject)
+	return args.Error(0)
+}
+
+// memoryCache é um cache.Client em memória (sem expiração) para exercitar o loginguard.Guard.
+type memoryCache struct {
+	values map[string]string
+}
+
+func newMemoryCache() *memoryCache { return &memoryCache{values: map[string]string{}} }
+
+func (c *memoryCache) Get(ctx context.Context, key string) (string, error) {
+	value, ok := c.values[key]
+	if !ok {
+		return "", cache.ErrCacheMiss
+	}
+	return value, nil
+}
+
+func (c *memoryCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	switch v := value.(type) {
+	case int:
+		c.values[key] = strconv.Itoa(v)
+	default:
+		c.values[key] = v.(string)
+	}
+	return nil
+}
+
+func (c *memoryCache) Delete(ctx context.Context, key string) error {
+	delete(c.values, key)
+	return nil
+}
+
+func (c *memoryCache) Incr(ctx context.Context, key string) error {
+	n, _ := strconv.Atoi(c.values[key])
+	c.values[key] = strconv.Itoa(n + 1)
+	return nil
+}
+
+func (c *memoryCache) GetInt(ctx context.Context, key string) (int, error) {
+	value, ok := c.values[key]
+	if !ok {
+		return 0, cache.ErrCacheMiss
+	}
+	return strconv.Atoi(value)
+}
+
+func loginContext(ip string) context.Context {
+	return middleware.WithClientIP(context.Background(), ip)
+}
+
+func TestLogin_Fail_LockedReturnsTooManyRequests(t *testing.T) {
+	svc, repo, _, _ := newTestService()
+	guard := new(MockLoginGuard)
+	svc.SetLoginGuard(guard)
+	guard.On("Check", mock.Anything, "ana@gostock.com", "10.0.0.1").Return(time.Now().Add(10*time.Minute), nil)
+
+	_, err := svc.Login(loginContext("10.0.0.1"), "ana@gostock.com", "senha123")
+
+	var tooMany *apperror.TooManyRequestsError
+	assert.ErrorAs(t, err, &tooMany)
+	assert.InDelta(t, (10 * time.Minute).Seconds(), tooMany.RetryAfter.Seconds(), 5)
+	repo.AssertNotCalled(t, "FindByEmail", mock.Anything, mock.Anything)
+}
+
+func TestLogin_Fail_UnknownEmailSameErrorAsWrongPassword(t *testing.T) {
+	svc, repo, _, _ := newTestService()
+	guard := new(MockLoginGuard)
+	svc.SetLoginGuard(guard)
+	hash, _ := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.MinCost)
+	repo.On("FindByEmail", mock.Anything, "ana@gostock.com").Return(domain.User{ID: "user-1", PasswordHash: string(hash)}, nil)
+	repo.On("FindByEmail", mock.Anything, "ninguem@gostock.com").Return(domain.User{}, apperror.NewNotFoundError("não encontrado"))
+	guard.On("Check", mock.Anything, mock.Anything, mock.Anything).Return(time.Time{}, nil)
+	guard.On("RecordFailure", mock.Anything, mock.Anything, "10.0.0.1").Return(loginguard.Attempt{Failures: 1}, nil)
+
+	_, errWrongPassword := svc.Login(loginContext("10.0.0.1"), "ana@gostock.com", "errada")
+	_, errUnknownEmail := svc.Login(loginContext("10.0.0.1"), "ninguem@gostock.com", "errada")
+
+	assert.Equal(t, errWrongPassword, errUnknownEmail)
+	guard.AssertNumberOfCalls(t, "RecordFailure", 2)
+}
+
+func TestLogin_Success_ClearsAccountFailures(t *testing.T) {
+	svc, repo, tokens, _ := newTestService()
+	guard := new(MockLoginGuard)
+	svc.SetLoginGuard(guard)
+	hash, _ := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.MinCost)
+	repo.On("FindByEmail", mock.Anything, "ana@gostock.com").Return(domain.User{ID: "user-1", PasswordHash: string(hash), Role: domain.RoleUser}, nil)
+	repo.On("SaveRefreshToken", mock.Anything, mock.Anything).Return(nil)
+	tokens.On("GenerateToken", "user-1", "user", mock.Anything, mock.Anything).Return("jwt", nil)
+	guard.On("Check", mock.Anything, "ana@gostock.com", "10.0.0.1").Return(time.Time{}, nil)
+	guard.On("RecordSuccess", mock.Anything, "ana@gostock.com").Return(nil)
+
+	_, err := svc.Login(loginContext("10.0.0.1"), "ana@gostock.com", "senha123")
+
+	assert.NoError(t, err)
+	guard.AssertExpectations(t)
+}
+
+// TestLogin_Guard_ProgressiveLockout exercita o loginguard.Guard real: o bloqueio na quinta
+// falha, a recusa durante o bloqueio e a duração dobrada na reincidência.
+func TestLogin_Guard_ProgressiveLockout(t *testing.T) {
+	svc, repo, _, _ := newTestService()
+	memory := newMemoryCache()
+	guard := loginguard.NewGuard(memory, loginguard.Policy{
+		MaxAttempts: 5, IPMaxAttempts: 100, Window: time.Minute, Lockout: time.Minute, MaxLockout: time.Hour,
+		DelayStep: time.Millisecond, MaxDelay: time.Millisecond,
+	})
+	svc.SetLoginGuard(guard)
+	repo.On("FindByEmail", mock.Anything, mock.Anything).Return(domain.User{}, apperror.NewNotFoundError("não encontrado"))
+
+	var unauthorized *apperror.UnauthorizedError
+	for i := 0; i < 4; i++ {
+		_, err := svc.Login(loginContext("10.0.0.1"), "Ana@GoStock.com", "errada")
+		assert.ErrorAs(t, err, &unauthorized)
+	}
+	_, err := svc.Login(loginContext("10.0.0.1"), "ana@gostock.com", "errada")
+	var tooMany *apperror.TooManyRequestsError
+	assert.ErrorAs(t, err, &tooMany)
+	assert.InDelta(t, time.Minute.Seconds(), tooMany.RetryAfter.Seconds(), 2)
+
+	// Bloqueado: a senha nem é conferida
+	_, err = svc.Login(loginContext("10.0.0.2"), "ana@gostock.com", "errada")
+	assert.ErrorAs(t, err, &tooMany)
+	repo.AssertNumberOfCalls(t, "FindByEmail", 5)
+
+	status, err := guard.Status(context.Background(), domain.LockoutScopeAccount, "ana@gostock.com")
+	assert.NoError(t, err)
+	assert.True(t, status.Locked)
+	assert.Equal(t, 1, status.Lockouts)
+
+	// Reincidência após o fim do bloqueio: a duração dobra
+	memory.Delete(context.Background(), "login:lock:account:ana@gostock.com")
+	for i := 0; i < 5; i++ {
+		_, err = svc.Login(loginContext("10.0.0.1"), "ana@gostock.com", "errada")
+	}
+	assert.ErrorAs(t, err, &tooMany)
+	assert.InDelta(t, (2 * time.Minute).Seconds(), tooMany.RetryAfter.Seconds(), 2)
+}
+
+func TestGetUserLockout_UsesAccountEmail(t *testing.T) {
+	svc, repo, _, _ := newTestService()
+	guard := new(MockLoginGuard)
+	svc.SetLoginGuard(guard)
+	id := "6f1c3a52-0c7e-4b8e-9a57-3d2f1e0b9c11"
+	repo.On("FindByID", mock.Anything, id).Return(domain.User{ID: id, Email: "ana@gostock.com"}, nil)
+	guard.On("Status", mock.Anything, domain.LockoutScopeAccount, "ana@gostock.com").
+		Return(domain.LoginLockout{Scope: domain.LockoutScopeAccount, Subject: "ana@gostock.com", Locked: true}, nil)
+
+	status, err := svc.GetUserLockout(context.Background(), id)
+
+	assert.NoError(t, err)
+	assert.True(t, status.Locked)
+}
+
+func TestClearIPLockout_Fail_InvalidIP(t *testing.T) {
+	svc, _, _, _ := newTestService()
+	guard := new(MockLoginGuard)
+	svc.SetLoginGuard(guard)
+
+	err := svc.ClearIPLockout(context.Background(), "não-é-ip")
+
+	var validation *apperror.ValidationError
+	assert.ErrorAs(t, err, &validation)
+	guard.AssertNotCalled(t, "Clear", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClearIPLockout_Success(t *testing.T) {
+	svc, _, _, _ := newTestService()
+	guard := new(MockLoginGuard)
+	svc.SetLoginGuard(guard)
+	guard.On("Clear", mock.Anything, domain.LockoutScopeIP, "2001:db8::1").Return(nil)
+
+	err := svc.ClearIPLockout(context.Background(), "2001:DB8:0::1")
+
+	assert.NoError(t, err)
+	guard.AssertExpectations(t)
+}
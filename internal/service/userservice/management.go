@@ -0,0 +1,136 @@
+package userservice
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/middleware"
+)
+
+// ListUsers retorna uma página da listagem de usuários (ordenada por e-mail).
+func (s *UserService) ListUsers(ctx context.Context, page, limit int) (domain.UserList, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	users, total, err := s.UserRepo.List(ctx, limit, (page-1)*limit)
+	if err != nil {
+		return domain.UserList{}, err
+	}
+	return domain.UserList{Users: users, Page: page, Limit: limit, Total: total}, nil
+}
+
+// GetUser busca um usuário pelo ID.
+func (s *UserService) GetUser(ctx context.Context, id string) (domain.User, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return domain.User{}, apperror.NewValidationError("O ID do usuário deve ser um UUID válido.")
+	}
+	return s.UserRepo.FindByID(ctx, id)
+}
+
+// SetUserDisabled desativa ou reativa a conta do usuário. Ao desativar, todas as sessões são
+// encerradas: os refresh tokens são revogados e os access tokens já emitidos deixam de valer.
+func (s *UserService) SetUserDisabled(ctx context.Context, id string, disabled bool) (domain.User, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return domain.User{}, apperror.NewValidationError("O ID do usuário deve ser um UUID válido.")
+	}
+	if disabled && isCurrentUser(ctx, id) {
+		return domain.User{}, apperror.NewConflictError("Não é possível desativar a própria conta.")
+	}
+
+	user, err := s.UserRepo.SetDisabled(ctx, id, disabled)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if disabled {
+		if err := s.revokeUser(ctx, id); err != nil {
+			return domain.User{}, err
+		}
+	}
+
+	s.logger.WithContext(ctx).Info("Status da conta alterado.", map[string]interface{}{"user_id": id, "disabled": disabled})
+	return user, nil
+}
+
+// DeleteUser remove o usuário e invalida os access tokens já emitidos para ele.
+func (s *UserService) DeleteUser(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return apperror.NewValidationError("O ID do usuário deve ser um UUID válido.")
+	}
+	if isCurrentUser(ctx, id) {
+		return apperror.NewConflictError("Não é possível remover a própria conta.")
+	}
+
+	if err := s.UserRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	// Os refresh tokens são removidos em cascata; resta recusar os access tokens ainda válidos
+	if err := s.Revocations.RevokeUser(ctx, id, s.TokenSvc.Expiry()); err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao revogar access tokens do usuário removido.", err)
+		return apperror.NewInternalError("Usuário removido, mas não foi possível invalidar os tokens emitidos.", err)
+	}
+
+	s.logger.WithContext(ctx).Info("Usuário removido.", map[string]interface{}{"user_id": id})
+	return nil
+}
+
+// ChangePassword troca a senha do próprio usuário após conferir a senha atual. Todas as sessões,
+// inclusive a atual, são encerradas e o usuário precisa fazer login novamente.
+func (s *UserService) ChangePassword(ctx context.Context, userID string, change domain.PasswordChange) error {
+	if change.CurrentPassword == "" || change.NewPassword == "" {
+		return apperror.NewValidationError("A senha atual e a nova senha são obrigatórias.")
+	}
+	if change.CurrentPassword == change.NewPassword {
+		return apperror.NewValidationError("A nova senha deve ser diferente da atual.")
+	}
+
+	user, err := s.UserRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(change.CurrentPassword)); err != nil {
+		s.logger.WithContext(ctx).Warn("Troca de senha com senha atual incorreta.", map[string]interface{}{"user_id": userID})
+		return apperror.NewUnauthorizedError("Senha atual incorreta.")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(change.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao gerar hash da senha.", err)
+		return apperror.NewInternalError("Falha ao gerar hash da senha.", err)
+	}
+	if err := s.UserRepo.UpdatePassword(ctx, userID, string(hashedPassword)); err != nil {
+		return err
+	}
+	if err := s.revokeUser(ctx, userID); err != nil {
+		return err
+	}
+
+	s.logger.WithContext(ctx).Info("Senha alterada; sessões do usuário encerradas.", map[string]interface{}{"user_id": userID})
+	return nil
+}
+
+// revokeUser encerra todas as sessões do usuário: revoga os refresh tokens e, pela lista de
+// revogação, os access tokens já emitidos.
+func (s *UserService) revokeUser(ctx context.Context, userID string) error {
+	if err := s.RefreshRepo.RevokeUserRefreshTokens(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.Revocations.RevokeUser(ctx, userID, s.TokenSvc.Expiry()); err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao revogar access tokens do usuário.", err)
+		return apperror.NewInternalError("Falha ao encerrar as sessões do usuário.", err)
+	}
+	return nil
+}
+
+// isCurrentUser indica se o ID é o do usuário autenticado na requisição.
+func isCurrentUser(ctx context.Context, userID string) bool {
+	claims, ok := middleware.GetUserClaimsFromContext(ctx)
+	return ok && claims.UserID == userID
+}
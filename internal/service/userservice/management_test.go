@@ -0,0 +1,127 @@
+package userservice_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/middleware"
+)
+
+// adminContext simula um administrador autenticado pelo middleware.
+func adminContext(userID string) context.Context {
+	return context.WithValue(context.Background(), middleware.UserClaimsKey, middleware.UserClaims{UserID: userID, Role: domain.RoleAdmin})
+}
+
+func TestLogin_Fail_DisabledAccount(t *testing.T) {
+	svc, repo, _, _ := newTestService()
+	hash, _ := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.MinCost)
+	repo.On("FindByEmail", mock.Anything, "a@gostock.com").
+		Return(domain.User{ID: "user-1", Email: "a@gostock.com", PasswordHash: string(hash), Role: domain.RoleUser, Disabled: true}, nil)
+
+	_, err := svc.Login(context.Background(), "a@gostock.com", "senha123")
+
+	var unauthorized *apperror.UnauthorizedError
+	assert.ErrorAs(t, err, &unauthorized)
+	assert.Contains(t, err.Error(), "desativada")
+	repo.AssertNotCalled(t, "SaveRefreshToken", mock.Anything, mock.Anything)
+}
+
+func TestListUsers_Paginates(t *testing.T) {
+	svc, repo, _, _ := newTestService()
+	users := []domain.User{{ID: uuid.NewString(), Email: "b@gostock.com"}}
+	repo.On("List", mock.Anything, 20, 40).Return(users, 41, nil)
+
+	list, err := svc.ListUsers(context.Background(), 3, 20)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.UserList{Users: users, Page: 3, Limit: 20, Total: 41}, list)
+}
+
+func TestSetUserDisabled_RevokesSessions(t *testing.T) {
+	svc, repo, _, revoker := newTestService()
+	userID := uuid.NewString()
+	repo.On("SetDisabled", mock.Anything, userID, true).Return(domain.User{ID: userID, Disabled: true}, nil)
+	repo.On("RevokeUserRefreshTokens", mock.Anything, userID).Return(nil)
+	revoker.On("RevokeUser", mock.Anything, userID, mock.Anything).Return(nil)
+
+	user, err := svc.SetUserDisabled(adminContext(uuid.NewString()), userID, true)
+
+	assert.NoError(t, err)
+	assert.True(t, user.Disabled)
+	repo.AssertExpectations(t)
+	revoker.AssertExpectations(t)
+}
+
+func TestSetUserDisabled_Enable_KeepsSessions(t *testing.T) {
+	svc, repo, _, revoker := newTestService()
+	userID := uuid.NewString()
+	repo.On("SetDisabled", mock.Anything, userID, false).Return(domain.User{ID: userID}, nil)
+
+	_, err := svc.SetUserDisabled(adminContext(uuid.NewString()), userID, false)
+
+	assert.NoError(t, err)
+	revoker.AssertNotCalled(t, "RevokeUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSetUserDisabled_Fail_Self(t *testing.T) {
+	svc, repo, _, _ := newTestService()
+	adminID := uuid.NewString()
+
+	_, err := svc.SetUserDisabled(adminContext(adminID), adminID, true)
+
+	var conflict *apperror.ConflictError
+	assert.ErrorAs(t, err, &conflict)
+	repo.AssertNotCalled(t, "SetDisabled", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeleteUser_RevokesAccessTokens(t *testing.T) {
+	svc, repo, _, revoker := newTestService()
+	userID := uuid.NewString()
+	repo.On("Delete", mock.Anything, userID).Return(nil)
+	revoker.On("RevokeUser", mock.Anything, userID, mock.Anything).Return(nil)
+
+	err := svc.DeleteUser(adminContext(uuid.NewString()), userID)
+
+	assert.NoError(t, err)
+	revoker.AssertExpectations(t)
+}
+
+func TestChangePassword_Success_RevokesSessions(t *testing.T) {
+	svc, repo, _, revoker := newTestService()
+	userID := uuid.NewString()
+	hash, _ := bcrypt.GenerateFromPassword([]byte("antiga123"), bcrypt.MinCost)
+	repo.On("FindByID", mock.Anything, userID).Return(domain.User{ID: userID, PasswordHash: string(hash)}, nil)
+
+	var newHash string
+	repo.On("UpdatePassword", mock.Anything, userID, mock.Anything).
+		Run(func(args mock.Arguments) { newHash = args.String(2) }).
+		Return(nil)
+	repo.On("RevokeUserRefreshTokens", mock.Anything, userID).Return(nil)
+	revoker.On("RevokeUser", mock.Anything, userID, mock.Anything).Return(nil)
+
+	err := svc.ChangePassword(context.Background(), userID, domain.PasswordChange{CurrentPassword: "antiga123", NewPassword: "nova456"})
+
+	assert.NoError(t, err)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(newHash), []byte("nova456")))
+	revoker.AssertExpectations(t)
+}
+
+func TestChangePassword_Fail_WrongCurrentPassword(t *testing.T) {
+	svc, repo, _, _ := newTestService()
+	userID := uuid.NewString()
+	hash, _ := bcrypt.GenerateFromPassword([]byte("antiga123"), bcrypt.MinCost)
+	repo.On("FindByID", mock.Anything, userID).Return(domain.User{ID: userID, PasswordHash: string(hash)}, nil)
+
+	err := svc.ChangePassword(context.Background(), userID, domain.PasswordChange{CurrentPassword: "errada", NewPassword: "nova456"})
+
+	var unauthorized *apperror.UnauthorizedError
+	assert.ErrorAs(t, err, &unauthorized)
+	repo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,481 @@
+package userservice
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/middleware"
+	"gostock/internal/pkg/token"
+	"gostock/internal/pkg/totp"
+)
+
+// recoveryCodeCount é a quantidade de códigos de recuperação gerados por vez.
+const recoveryCodeCount = 10
+
+// MFAChallengeSigner é o contrato de assinatura do desafio do segundo passo do login (token.Service).
+type MFAChallengeSigner interface {
+	GenerateMFAChallenge(userID, purpose string, expiry time.Duration) (string, time.Time, error)
+	ValidateMFAChallenge(tokenString string) (*token.MFAClaims, error)
+}
+
+// SecretCipher cifra os segredos TOTP gravados no banco (totp.SecretCipher).
+type SecretCipher interface {
+	Seal(plain string) (string, error)
+	Open(sealed string) (string, error)
+}
+
+// MFAConfig configura a autenticação em dois fatores.
+type MFAConfig struct {
+	Issuer          string            // Nome exibido no aplicativo autenticador
+	ChallengeExpiry time.Duration     // Validade do desafio entre os dois passos do login
+	RequiredRoles   []domain.UserRole // Papéis que não fazem login sem o segundo fator (ex.: admin)
+}
+
+// SetMFA habilita a autenticação em dois fatores (TOTP). Sem esta configuração, o login tem
+// apenas um passo e os endpoints de cadastro respondem 500.
+func (s *UserService) SetMFA(repo domain.MFARepository, challenges MFAChallengeSigner, cipher SecretCipher, cfg MFAConfig) {
+	s.mfaRepo = repo
+	s.mfaChallenges = challenges
+	s.mfaCipher = cipher
+	s.mfaConfig = cfg
+}
+
+// mfaChallenge decide o segundo passo do login: com o segundo fator ativo, ou exigido pelo papel
+// e ainda não cadastrado, retorna o desafio no lugar dos tokens da sessão (required = true).
+func (s *UserService) mfaChallenge(ctx context.Context, user domain.User) (domain.AuthTokens, bool, error) {
+	if s.mfaRepo == nil {
+		return domain.AuthTokens{}, false, nil
+	}
+	enabled, err := s.mfaEnabled(ctx, user.ID)
+	if err != nil {
+		return domain.AuthTokens{}, false, err
+	}
+
+	purpose := token.MFAPurposeVerify
+	if !enabled {
+		if !s.mfaRequired(user.Role) {
+			return domain.AuthTokens{}, false, nil
+		}
+		purpose = token.MFAPurposeSetup
+	}
+
+	challenge, _, err := s.mfaChallenges.GenerateMFAChallenge(user.ID, purpose, s.mfaConfig.ChallengeExpiry)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao assinar desafio MFA.", err)
+		return domain.AuthTokens{}, false, apperror.NewInternalError("Falha ao gerar token de autenticação.", err)
+	}
+	s.logger.WithContext(ctx).Info("Login aguardando o segundo fator.", map[string]interface{}{"user_id": user.ID, "purpose": purpose})
+	return domain.AuthTokens{
+		MFARequired:      true,
+		MFASetupRequired: purpose == token.MFAPurposeSetup,
+		MFAToken:         challenge,
+	}, true, nil
+}
+
+// VerifyMFA conclui o login com o código do segundo fator e inicia a sessão. Com um desafio de
+// cadastro, o código confirma o autenticador registrado em StartMFASetup e a resposta traz
+// também os códigos de recuperação. Códigos errados contam como falhas de login.
+func (s *UserService) VerifyMFA(ctx context.Context, verification domain.MFAVerification) (domain.AuthTokens, error) {
+	if s.mfaRepo == nil {
+		return domain.AuthTokens{}, apperror.NewInternalError("Autenticação em dois fatores não configurada.", nil)
+	}
+	if verification.MFAToken == "" || verification.Code == "" {
+		return domain.AuthTokens{}, apperror.NewValidationError("O desafio (mfa_token) e o código são obrigatórios.")
+	}
+
+	claims, user, err := s.challengeUser(ctx, verification.MFAToken)
+	if err != nil {
+		return domain.AuthTokens{}, err
+	}
+	ip := middleware.GetClientIPFromContext(ctx)
+	if err := s.checkLoginLockout(ctx, user.Email, ip); err != nil {
+		return domain.AuthTokens{}, err
+	}
+
+	var recoveryCodes []string
+	switch claims.Purpose {
+	case token.MFAPurposeSetup:
+		codes, err := s.ConfirmMFA(ctx, user.ID, verification.Code)
+		if err != nil {
+			var unauthorized *apperror.UnauthorizedError
+			if errors.As(err, &unauthorized) {
+				return domain.AuthTokens{}, s.loginFailed(ctx, user.Email, ip, "wrong_mfa_code")
+			}
+			return domain.AuthTokens{}, err
+		}
+		recoveryCodes = codes.Codes
+	default:
+		mfa, err := s.activeMFA(ctx, user.ID)
+		if err != nil {
+			var conflictErr *apperror.ConflictError
+			if errors.As(err, &conflictErr) {
+				return domain.AuthTokens{}, apperror.NewUnauthorizedError("Desafio MFA inválido ou expirado.")
+			}
+			return domain.AuthTokens{}, err
+		}
+		valid, err := s.checkMFACode(ctx, mfa, verification.Code, true)
+		if err != nil {
+			return domain.AuthTokens{}, err
+		}
+		if !valid {
+			return domain.AuthTokens{}, s.loginFailed(ctx, user.Email, ip, "wrong_mfa_code")
+		}
+	}
+
+	if s.loginGuard != nil {
+		if err := s.loginGuard.RecordSuccess(ctx, user.Email); err != nil {
+			s.logger.WithContext(ctx).Error("Falha ao zerar as falhas de login.", err)
+		}
+	}
+	tokens, err := s.issueTokens(ctx, user, uuid.NewString(), uuid.NewString())
+	if err != nil {
+		return domain.AuthTokens{}, err
+	}
+	tokens.RecoveryCodes = recoveryCodes
+
+	s.logger.WithContext(ctx).Info("Login concluído com o segundo fator.", map[string]interface{}{"user_id": user.ID})
+	return tokens, nil
+}
+
+// StartMFASetup inicia, durante o login, o cadastro do segundo fator exigido pelo papel do usuário.
+func (s *UserService) StartMFASetup(ctx context.Context, challenge string) (domain.MFAEnrollment, error) {
+	if s.mfaRepo == nil {
+		return domain.MFAEnrollment{}, apperror.NewInternalError("Autenticação em dois fatores não configurada.", nil)
+	}
+	claims, user, err := s.challengeUser(ctx, challenge)
+	if err != nil {
+		return domain.MFAEnrollment{}, err
+	}
+	if claims.Purpose != token.MFAPurposeSetup {
+		return domain.MFAEnrollment{}, apperror.NewUnauthorizedError("Desafio MFA inválido ou expirado.")
+	}
+	return s.EnrollMFA(ctx, user.ID)
+}
+
+// GetMFAStatus retorna a situação do segundo fator do usuário.
+func (s *UserService) GetMFAStatus(ctx context.Context, userID string) (domain.MFAStatus, error) {
+	if s.mfaRepo == nil {
+		return domain.MFAStatus{}, apperror.NewInternalError("Autenticação em dois fatores não configurada.", nil)
+	}
+	user, err := s.UserRepo.FindByID(ctx, userID)
+	if err != nil {
+		return domain.MFAStatus{}, err
+	}
+
+	status := domain.MFAStatus{Required: s.mfaRequired(user.Role)}
+	mfa, err := s.mfaRepo.GetUserMFA(ctx, userID)
+	var notFoundErr *apperror.NotFoundError
+	if errors.As(err, &notFoundErr) || (err == nil && !mfa.Enabled) {
+		return status, nil
+	}
+	if err != nil {
+		return domain.MFAStatus{}, err
+	}
+
+	remaining, err := s.mfaRepo.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return domain.MFAStatus{}, err
+	}
+	status.Enabled = true
+	status.EnabledAt = mfa.EnabledAt
+	status.RecoveryCodesRemaining = remaining
+	return status, nil
+}
+
+// EnrollMFA gera um novo segredo TOTP, pendente até a confirmação com o primeiro código
+// (ConfirmMFA). Um cadastro pendente anterior é substituído.
+func (s *UserService) EnrollMFA(ctx context.Context, userID string) (domain.MFAEnrollment, error) {
+	if s.mfaRepo == nil {
+		return domain.MFAEnrollment{}, apperror.NewInternalError("Autenticação em dois fatores não configurada.", nil)
+	}
+	user, err := s.UserRepo.FindByID(ctx, userID)
+	if err != nil {
+		return domain.MFAEnrollment{}, err
+	}
+	enabled, err := s.mfaEnabled(ctx, userID)
+	if err != nil {
+		return domain.MFAEnrollment{}, err
+	}
+	if enabled {
+		return domain.MFAEnrollment{}, apperror.NewConflictError("O segundo fator já está ativo. Desative-o antes de cadastrar outro autenticador.")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao gerar segredo TOTP.", err)
+		return domain.MFAEnrollment{}, apperror.NewInternalError("Falha ao gerar o segredo.", err)
+	}
+	sealed, err := s.mfaCipher.Seal(secret)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao cifrar segredo TOTP.", err)
+		return domain.MFAEnrollment{}, apperror.NewInternalError("Falha ao gerar o segredo.", err)
+	}
+	if err := s.mfaRepo.SaveUserMFA(ctx, domain.UserMFA{UserID: userID, Secret: sealed, CreatedAt: time.Now().UTC()}); err != nil {
+		return domain.MFAEnrollment{}, err
+	}
+
+	s.logger.WithContext(ctx).Info("Cadastro do segundo fator iniciado.", map[string]interface{}{"user_id": userID})
+	return domain.MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.mfaConfig.Issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmMFA ativa o segundo fator com o primeiro código do autenticador e gera os códigos de
+// recuperação, exibidos apenas nesta resposta.
+func (s *UserService) ConfirmMFA(ctx context.Context, userID, code string) (domain.RecoveryCodes, error) {
+	if s.mfaRepo == nil {
+		return domain.RecoveryCodes{}, apperror.NewInternalError("Autenticação em dois fatores não configurada.", nil)
+	}
+	mfa, err := s.mfaRepo.GetUserMFA(ctx, userID)
+	if err != nil {
+		var notFoundErr *apperror.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			return domain.RecoveryCodes{}, apperror.NewConflictError("Nenhum cadastro pendente. Inicie o cadastro do autenticador.")
+		}
+		return domain.RecoveryCodes{}, err
+	}
+	if mfa.Enabled {
+		return domain.RecoveryCodes{}, apperror.NewConflictError("O segundo fator já está ativo.")
+	}
+
+	secret, err := s.mfaCipher.Open(mfa.Secret)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao decifrar segredo TOTP.", err)
+		return domain.RecoveryCodes{}, apperror.NewInternalError("Falha ao conferir o código.", err)
+	}
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return domain.RecoveryCodes{}, apperror.NewUnauthorizedError("Código inválido.")
+	}
+
+	now := time.Now().UTC()
+	mfa.Enabled = true
+	mfa.EnabledAt = &now
+	mfa.LastUsedStep = step
+	if err := s.mfaRepo.SaveUserMFA(ctx, mfa); err != nil {
+		return domain.RecoveryCodes{}, err
+	}
+	codes, err := s.replaceRecoveryCodes(ctx, userID)
+	if err != nil {
+		return domain.RecoveryCodes{}, err
+	}
+
+	s.logger.WithContext(ctx).Warn("Evento de segurança: segundo fator ativado.", map[string]interface{}{"event": "mfa_enabled", "user_id": userID})
+	return codes, nil
+}
+
+// DisableMFA desativa o segundo fator após conferir a senha e um código (TOTP ou de recuperação).
+// Usuários de papéis que exigem o segundo fator não podem desativá-lo.
+func (s *UserService) DisableMFA(ctx context.Context, userID string, request domain.MFADisable) error {
+	if s.mfaRepo == nil {
+		return apperror.NewInternalError("Autenticação em dois fatores não configurada.", nil)
+	}
+	if request.Password == "" || request.Code == "" {
+		return apperror.NewValidationError("A senha e o código são obrigatórios.")
+	}
+	user, err := s.UserRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if s.mfaRequired(user.Role) {
+		return apperror.NewConflictError("O seu papel exige o segundo fator; ele não pode ser desativado.")
+	}
+	mfa, err := s.activeMFA(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(request.Password)); err != nil {
+		return apperror.NewUnauthorizedError("Senha ou código incorretos.")
+	}
+	valid, err := s.checkMFACode(ctx, mfa, request.Code, true)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return apperror.NewUnauthorizedError("Senha ou código incorretos.")
+	}
+
+	if err := s.mfaRepo.DeleteUserMFA(ctx, userID); err != nil {
+		return err
+	}
+	s.logger.WithContext(ctx).Warn("Evento de segurança: segundo fator desativado.", map[string]interface{}{"event": "mfa_disabled", "user_id": userID})
+	return nil
+}
+
+// RegenerateRecoveryCodes substitui os códigos de recuperação após conferir um código do
+// autenticador. Os códigos anteriores deixam de valer.
+func (s *UserService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) (domain.RecoveryCodes, error) {
+	if s.mfaRepo == nil {
+		return domain.RecoveryCodes{}, apperror.NewInternalError("Autenticação em dois fatores não configurada.", nil)
+	}
+	mfa, err := s.activeMFA(ctx, userID)
+	if err != nil {
+		return domain.RecoveryCodes{}, err
+	}
+	valid, err := s.checkMFACode(ctx, mfa, code, false)
+	if err != nil {
+		return domain.RecoveryCodes{}, err
+	}
+	if !valid {
+		return domain.RecoveryCodes{}, apperror.NewUnauthorizedError("Código inválido.")
+	}
+
+	codes, err := s.replaceRecoveryCodes(ctx, userID)
+	if err != nil {
+		return domain.RecoveryCodes{}, err
+	}
+	s.logger.WithContext(ctx).Warn("Evento de segurança: códigos de recuperação regenerados.", map[string]interface{}{"event": "mfa_recovery_codes_regenerated", "user_id": userID})
+	return codes, nil
+}
+
+// ResetUserMFA remove o segundo fator de outro usuário (ex.: autenticador perdido sem códigos de
+// recuperação) e encerra as sessões dele. Se o papel exigir, o cadastro é refeito no próximo login.
+func (s *UserService) ResetUserMFA(ctx context.Context, id string) error {
+	if s.mfaRepo == nil {
+		return apperror.NewInternalError("Autenticação em dois fatores não configurada.", nil)
+	}
+	if _, err := s.GetUser(ctx, id); err != nil {
+		return err
+	}
+	if err := s.mfaRepo.DeleteUserMFA(ctx, id); err != nil {
+		return err
+	}
+	if err := s.revokeUser(ctx, id); err != nil {
+		return err
+	}
+
+	fields := map[string]interface{}{"event": "mfa_reset", "user_id": id}
+	if claims, ok := middleware.GetUserClaimsFromContext(ctx); ok {
+		fields["admin_id"] = claims.UserID
+	}
+	s.logger.WithContext(ctx).Warn("Evento de segurança: segundo fator removido por um administrador.", fields)
+	return nil
+}
+
+// challengeUser valida o desafio do segundo passo e carrega o usuário.
+func (s *UserService) challengeUser(ctx context.Context, challenge string) (*token.MFAClaims, domain.User, error) {
+	invalid := apperror.NewUnauthorizedError("Desafio MFA inválido ou expirado.")
+
+	claims, err := s.mfaChallenges.ValidateMFAChallenge(challenge)
+	if err != nil {
+		s.logger.WithContext(ctx).Warn("Desafio MFA inválido.", map[string]interface{}{"error": err.Error()})
+		return nil, domain.User{}, invalid
+	}
+	user, err := s.UserRepo.FindByID(ctx, claims.Subject)
+	if err != nil {
+		var notFoundErr *apperror.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			return nil, domain.User{}, invalid
+		}
+		return nil, domain.User{}, err
+	}
+	if user.Disabled {
+		return nil, domain.User{}, apperror.NewUnauthorizedError("Conta desativada. Procure um administrador.")
+	}
+	return claims, user, nil
+}
+
+// checkMFACode confere um código TOTP (não reutilizável) ou, se permitido, um código de recuperação.
+func (s *UserService) checkMFACode(ctx context.Context, mfa domain.UserMFA, code string, allowRecovery bool) (bool, error) {
+	secret, err := s.mfaCipher.Open(mfa.Secret)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao decifrar segredo TOTP.", err)
+		return false, apperror.NewInternalError("Falha ao conferir o código.", err)
+	}
+	if step, ok := totp.Validate(secret, code, time.Now()); ok {
+		// O mesmo código vale por até 90s; cada intervalo só é aceito uma vez
+		return s.mfaRepo.AdvanceMFAStep(ctx, mfa.UserID, step)
+	}
+	if !allowRecovery {
+		return false, nil
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		return false, nil
+	}
+	used, err := s.mfaRepo.UseRecoveryCode(ctx, mfa.UserID, token.HashOneTimeToken(normalized))
+	if err != nil || !used {
+		return false, err
+	}
+	s.logger.WithContext(ctx).Warn("Evento de segurança: código de recuperação usado.", map[string]interface{}{"event": "mfa_recovery_code_used", "user_id": mfa.UserID})
+	return true, nil
+}
+
+// activeMFA retorna o cadastro ativo do usuário (Conflict se o segundo fator não está ativo).
+func (s *UserService) activeMFA(ctx context.Context, userID string) (domain.UserMFA, error) {
+	mfa, err := s.mfaRepo.GetUserMFA(ctx, userID)
+	var notFoundErr *apperror.NotFoundError
+	if errors.As(err, &notFoundErr) || (err == nil && !mfa.Enabled) {
+		return domain.UserMFA{}, apperror.NewConflictError("O segundo fator não está ativo.")
+	}
+	return mfa, err
+}
+
+// mfaEnabled indica se o usuário tem o segundo fator ativo.
+func (s *UserService) mfaEnabled(ctx context.Context, userID string) (bool, error) {
+	mfa, err := s.mfaRepo.GetUserMFA(ctx, userID)
+	if err != nil {
+		var notFoundErr *apperror.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			return false, nil
+		}
+		return false, err
+	}
+	return mfa.Enabled, nil
+}
+
+// mfaRequired indica se o papel exige o segundo fator.
+func (s *UserService) mfaRequired(role domain.UserRole) bool {
+	return slices.Contains(s.mfaConfig.RequiredRoles, role)
+}
+
+// replaceRecoveryCodes gera novos códigos de recuperação e grava apenas os hashes.
+func (s *UserService) replaceRecoveryCodes(ctx context.Context, userID string) (domain.RecoveryCodes, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			s.logger.WithContext(ctx).Error("Falha ao gerar código de recuperação.", err)
+			return domain.RecoveryCodes{}, apperror.NewInternalError("Falha ao gerar os códigos de recuperação.", err)
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, token.HashOneTimeToken(normalizeRecoveryCode(code)))
+	}
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return domain.RecoveryCodes{}, err
+	}
+	return domain.RecoveryCodes{Codes: codes}, nil
+}
+
+// newRecoveryCode gera um código no formato "xxxxx-xxxxx" (50 bits aleatórios, base32).
+func newRecoveryCode() (string, error) {
+	raw := make([]byte, 10)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	encoded := strings.ToLower(base32.StdEncoding.EncodeToString(raw))[:10]
+	return encoded[:5] + "-" + encoded[5:], nil
+}
+
+// normalizeRecoveryCode ignora caixa, espaços e hífens digitados pelo usuário.
+func normalizeRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	if len(normalized) != 10 {
+		return ""
+	}
+	return normalized
+}
@@ -0,0 +1,254 @@
+package userservice_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/token"
+	"gostock/internal/pkg/totp"
+	"gostock/internal/service/userservice"
+)
+
+// MockMFARepository simula a persistência do segundo fator.
+type MockMFARepository struct {
+	mock.Mock
+}
+
+func (m *MockMFARepository) GetUserMFA(ctx domain.Context, userID string) (domain.UserMFA, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(domain.UserMFA), args.Error(1)
+}
+
+func (m *MockMFARepository) SaveUserMFA(ctx domain.Context, mfa domain.UserMFA) error {
+	args := m.Called(ctx, mfa)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) AdvanceMFAStep(ctx domain.Context, userID string, step int64) (bool, error) {
+	args := m.Called(ctx, userID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMFARepository) DeleteUserMFA(ctx domain.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) ReplaceRecoveryCodes(ctx domain.Context, userID string, codeHashes []string) error {
+	args := m.Called(ctx, userID, codeHashes)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) UseRecoveryCode(ctx domain.Context, userID, codeHash string) (bool, error) {
+	args := m.Called(ctx, userID, codeHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMFARepository) CountRecoveryCodes(ctx domain.Context, userID string) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+type mfaFixture struct {
+	svc     *userservice.UserService
+	repo    *MockUserRepository
+	tokens  *MockTokenService
+	mfaRepo *MockMFARepository
+	signer  *token.Service
+	cipher  *totp.SecretCipher
+}
+
+// newMFAService configura o serviço com o segundo fator exigido para o papel admin.
+func newMFAService() mfaFixture {
+	svc, repo, tokens, _ := newTestService()
+	mfaRepo := new(MockMFARepository)
+	signer := token.NewService("segredo-de-teste", 15*time.Minute)
+	cipher, _ := totp.NewSecretCipher("chave-de-teste")
+	svc.SetMFA(mfaRepo, signer, cipher, userservice.MFAConfig{
+		Issuer:          "GoStock",
+		ChallengeExpiry: 5 * time.Minute,
+		RequiredRoles:   []domain.UserRole{domain.RoleAdmin},
+	})
+	return mfaFixture{svc: svc, repo: repo, tokens: tokens, mfaRepo: mfaRepo, signer: signer, cipher: cipher}
+}
+
+// enabledMFA cria um cadastro ativo e retorna o segredo em texto puro.
+func (f mfaFixture) enabledMFA(userID string) (domain.UserMFA, string) {
+	secret, _ := totp.GenerateSecret()
+	sealed, _ := f.cipher.Seal(secret)
+	return domain.UserMFA{UserID: userID, Secret: sealed, Enabled: true}, secret
+}
+
+func (f mfaFixture) loginUser(role domain.UserRole) domain.User {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.MinCost)
+	user := domain.User{ID: "user-1", Email: "ana@gostock.com", PasswordHash: string(hash), Role: role}
+	f.repo.On("FindByEmail", mock.Anything, "ana@gostock.com").Return(user, nil)
+	f.repo.On("FindByID", mock.Anything, "user-1").Return(user, nil)
+	return user
+}
+
+func TestTOTP_RFC6238Vector(t *testing.T) {
+	// Segredo "12345678901234567890" (RFC 6238, apêndice B); T = 59s -> 94287082 (8 dígitos)
+	code, err := totp.Code("GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", totp.Step(time.Unix(59, 0)))
+	assert.NoError(t, err)
+	assert.Equal(t, "287082", code)
+}
+
+func TestEnrollAndConfirmMFA_GeneratesRecoveryCodes(t *testing.T) {
+	f := newMFAService()
+	f.loginUser(domain.RoleUser)
+	f.mfaRepo.On("GetUserMFA", mock.Anything, "user-1").Return(domain.UserMFA{}, apperror.NewNotFoundError("não cadastrado")).Once()
+
+	var pending domain.UserMFA
+	f.mfaRepo.On("SaveUserMFA", mock.Anything, mock.MatchedBy(func(m domain.UserMFA) bool { return !m.Enabled })).Run(func(args mock.Arguments) {
+		pending = args.Get(1).(domain.UserMFA)
+	}).Return(nil).Once()
+
+	enrollment, err := f.svc.EnrollMFA(context.Background(), "user-1")
+	assert.NoError(t, err)
+	assert.Contains(t, enrollment.ProvisioningURI, "otpauth://totp/GoStock:ana@gostock.com?")
+	assert.NotEqual(t, enrollment.Secret, pending.Secret) // Gravado cifrado
+
+	f.mfaRepo.On("GetUserMFA", mock.Anything, "user-1").Return(pending, nil).Once()
+	f.mfaRepo.On("SaveUserMFA", mock.Anything, mock.MatchedBy(func(m domain.UserMFA) bool {
+		return m.Enabled && m.EnabledAt != nil && m.LastUsedStep > 0
+	})).Return(nil).Once()
+	var hashes []string
+	f.mfaRepo.On("ReplaceRecoveryCodes", mock.Anything, "user-1", mock.Anything).Run(func(args mock.Arguments) {
+		hashes = args.Get(2).([]string)
+	}).Return(nil)
+
+	code, _ := totp.Code(enrollment.Secret, totp.Step(time.Now()))
+	codes, err := f.svc.ConfirmMFA(context.Background(), "user-1", code)
+
+	assert.NoError(t, err)
+	assert.Len(t, codes.Codes, 10)
+	assert.Len(t, hashes, 10)
+	assert.NotContains(t, hashes, codes.Codes[0])
+	f.mfaRepo.AssertExpectations(t)
+}
+
+func TestLogin_MFAEnabled_ReturnsChallenge(t *testing.T) {
+	f := newMFAService()
+	f.loginUser(domain.RoleUser)
+	mfa, _ := f.enabledMFA("user-1")
+	f.mfaRepo.On("GetUserMFA", mock.Anything, "user-1").Return(mfa, nil)
+
+	tokens, err := f.svc.Login(context.Background(), "ana@gostock.com", "senha123")
+
+	assert.NoError(t, err)
+	assert.True(t, tokens.MFARequired)
+	assert.False(t, tokens.MFASetupRequired)
+	assert.NotEmpty(t, tokens.MFAToken)
+	assert.Empty(t, tokens.AccessToken)
+	f.repo.AssertNotCalled(t, "SaveRefreshToken", mock.Anything, mock.Anything)
+
+	// O desafio não autentica requisições
+	_, err = f.signer.ValidateToken(tokens.MFAToken)
+	assert.Error(t, err)
+}
+
+func TestLogin_AdminWithoutMFA_RequiresSetup(t *testing.T) {
+	f := newMFAService()
+	f.loginUser(domain.RoleAdmin)
+	f.mfaRepo.On("GetUserMFA", mock.Anything, "user-1").Return(domain.UserMFA{}, apperror.NewNotFoundError("não cadastrado"))
+
+	tokens, err := f.svc.Login(context.Background(), "ana@gostock.com", "senha123")
+
+	assert.NoError(t, err)
+	assert.True(t, tokens.MFARequired)
+	assert.True(t, tokens.MFASetupRequired)
+	assert.Empty(t, tokens.AccessToken)
+}
+
+func TestVerifyMFA_Success_TOTP(t *testing.T) {
+	f := newMFAService()
+	f.loginUser(domain.RoleUser)
+	mfa, secret := f.enabledMFA("user-1")
+	f.mfaRepo.On("GetUserMFA", mock.Anything, "user-1").Return(mfa, nil)
+	f.mfaRepo.On("AdvanceMFAStep", mock.Anything, "user-1", mock.Anything).Return(true, nil)
+	f.repo.On("SaveRefreshToken", mock.Anything, mock.Anything).Return(nil)
+	f.tokens.On("GenerateToken", "user-1", "user", mock.Anything, mock.Anything).Return("jwt", nil)
+
+	challenge, _, _ := f.signer.GenerateMFAChallenge("user-1", token.MFAPurposeVerify, time.Minute)
+	code, _ := totp.Code(secret, totp.Step(time.Now()))
+	tokens, err := f.svc.VerifyMFA(context.Background(), domain.MFAVerification{MFAToken: challenge, Code: code})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "jwt", tokens.AccessToken)
+	assert.NotEmpty(t, tokens.RefreshToken)
+}
+
+func TestVerifyMFA_Fail_ReusedCode(t *testing.T) {
+	f := newMFAService()
+	f.loginUser(domain.RoleUser)
+	mfa, secret := f.enabledMFA("user-1")
+	f.mfaRepo.On("GetUserMFA", mock.Anything, "user-1").Return(mfa, nil)
+	f.mfaRepo.On("AdvanceMFAStep", mock.Anything, "user-1", mock.Anything).Return(false, nil)
+
+	challenge, _, _ := f.signer.GenerateMFAChallenge("user-1", token.MFAPurposeVerify, time.Minute)
+	code, _ := totp.Code(secret, totp.Step(time.Now()))
+	_, err := f.svc.VerifyMFA(context.Background(), domain.MFAVerification{MFAToken: challenge, Code: code})
+
+	var unauthorized *apperror.UnauthorizedError
+	assert.ErrorAs(t, err, &unauthorized)
+	f.repo.AssertNotCalled(t, "SaveRefreshToken", mock.Anything, mock.Anything)
+}
+
+func TestVerifyMFA_Success_RecoveryCode(t *testing.T) {
+	f := newMFAService()
+	f.loginUser(domain.RoleUser)
+	mfa, _ := f.enabledMFA("user-1")
+	f.mfaRepo.On("GetUserMFA", mock.Anything, "user-1").Return(mfa, nil)
+	f.mfaRepo.On("UseRecoveryCode", mock.Anything, "user-1", token.HashOneTimeToken("abcde12345")).Return(true, nil)
+	f.repo.On("SaveRefreshToken", mock.Anything, mock.Anything).Return(nil)
+	f.tokens.On("GenerateToken", "user-1", "user", mock.Anything, mock.Anything).Return("jwt", nil)
+
+	challenge, _, _ := f.signer.GenerateMFAChallenge("user-1", token.MFAPurposeVerify, time.Minute)
+	tokens, err := f.svc.VerifyMFA(context.Background(), domain.MFAVerification{MFAToken: challenge, Code: "ABCDE-12345"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "jwt", tokens.AccessToken)
+}
+
+func TestVerifyMFA_Fail_AccessTokenIsNotChallenge(t *testing.T) {
+	f := newMFAService()
+	accessToken, _ := f.signer.GenerateToken("user-1", "user", "", "sessao")
+
+	_, err := f.svc.VerifyMFA(context.Background(), domain.MFAVerification{MFAToken: accessToken, Code: "123456"})
+
+	var unauthorized *apperror.UnauthorizedError
+	assert.ErrorAs(t, err, &unauthorized)
+}
+
+func TestDisableMFA_Fail_RequiredByRole(t *testing.T) {
+	f := newMFAService()
+	f.loginUser(domain.RoleAdmin)
+
+	err := f.svc.DisableMFA(context.Background(), "user-1", domain.MFADisable{Password: "senha123", Code: "123456"})
+
+	var conflict *apperror.ConflictError
+	assert.ErrorAs(t, err, &conflict)
+	f.mfaRepo.AssertNotCalled(t, "DeleteUserMFA", mock.Anything, mock.Anything)
+}
+
+func TestRefreshTokens_Fail_AdminWithoutMFA(t *testing.T) {
+	f := newMFAService()
+	f.loginUser(domain.RoleAdmin)
+	f.repo.On("FindRefreshTokenByHash", mock.Anything, token.HashRefreshToken("refresh")).
+		Return(domain.RefreshToken{ID: "rt-1", UserID: "user-1", FamilyID: "fam-1", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+	f.mfaRepo.On("GetUserMFA", mock.Anything, "user-1").Return(domain.UserMFA{}, apperror.NewNotFoundError("não cadastrado"))
+
+	_, err := f.svc.RefreshTokens(context.Background(), "refresh")
+
+	var unauthorized *apperror.UnauthorizedError
+	assert.ErrorAs(t, err, &unauthorized)
+	f.repo.AssertNotCalled(t, "SaveRefreshToken", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,267 @@
+package userservice
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/oidc"
+	"gostock/internal/pkg/tenant"
+)
+
+// OIDCProvider é o contrato do cliente do provedor OpenID Connect (oidc.Client).
+type OIDCProvider interface {
+	Issuer() string
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier string) (string, error)
+	VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (oidc.Identity, error)
+}
+
+// OIDCStateStore guarda os logins pendentes entre o redirecionamento e o retorno (oidc.StateStore).
+type OIDCStateStore interface {
+	Save(ctx context.Context, state string, pending oidc.PendingLogin, ttl time.Duration) error
+	Take(ctx context.Context, state string) (oidc.PendingLogin, error)
+}
+
+// RoleAssigner altera o papel de um usuário (rolerepo.RoleRepository).
+type RoleAssigner interface {
+	AssignUserRole(ctx context.Context, userID, role string) error
+}
+
+// OIDCRoleMapping associa um grupo do provedor a um papel do GoStock.
+type OIDCRoleMapping struct {
+	Group string
+	Role  string
+}
+
+// OIDCConfig configura o login com o provedor OpenID Connect.
+type OIDCConfig struct {
+	DefaultRole   string            // Papel dos usuários criados no primeiro login (e sem grupo mapeado)
+	AutoProvision bool              // Cria o usuário no primeiro login; se falso, só contas já existentes entram
+	RoleMapping   []OIDCRoleMapping // Grupo -> papel, em ordem de prioridade (vazio: o papel não é sincronizado)
+	StateExpiry   time.Duration     // Tempo máximo entre o redirecionamento e o retorno do provedor
+}
+
+// SetOIDC habilita o login com o provedor OpenID Connect. O login local continua disponível;
+// sem esta configuração, os endpoints /v1/oidc respondem 404.
+func (s *UserService) SetOIDC(provider OIDCProvider, states OIDCStateStore, identities domain.UserIdentityRepository, roleAssigner RoleAssigner, cfg OIDCConfig) {
+	if cfg.DefaultRole == "" {
+		cfg.DefaultRole = string(domain.DefaultRole)
+	}
+	if cfg.StateExpiry <= 0 {
+		cfg.StateExpiry = 10 * time.Minute
+	}
+	s.oidcProvider = provider
+	s.oidcStates = states
+	s.identities = identities
+	s.roleAssigner = roleAssigner
+	s.oidcConfig = cfg
+}
+
+// StartOIDCLogin inicia o login OIDC: gera state, nonce e o verificador PKCE, guarda-os até o
+// retorno e monta o endereço de autorização do provedor.
+func (s *UserService) StartOIDCLogin(ctx context.Context) (domain.OIDCAuthorization, error) {
+	if s.oidcProvider == nil {
+		return domain.OIDCAuthorization{}, apperror.NewNotFoundError("Login OIDC não configurado.")
+	}
+
+	state, err := oidc.RandomString()
+	if err != nil {
+		return domain.OIDCAuthorization{}, apperror.NewInternalError("Falha ao iniciar o login OIDC.", err)
+	}
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		return domain.OIDCAuthorization{}, apperror.NewInternalError("Falha ao iniciar o login OIDC.", err)
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		return domain.OIDCAuthorization{}, apperror.NewInternalError("Falha ao iniciar o login OIDC.", err)
+	}
+
+	authURL, err := s.oidcProvider.AuthCodeURL(ctx, state, nonce, challenge)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao contatar o provedor OIDC.", err)
+		return domain.OIDCAuthorization{}, apperror.NewInternalError("Provedor de login indisponível.", err)
+	}
+	pending := oidc.PendingLogin{Nonce: nonce, CodeVerifier: verifier}
+	pending.TenantID, _ = tenant.FromContext(ctx) // O retorno do provedor não traz o header X-Tenant
+	if err := s.oidcStates.Save(ctx, state, pending, s.oidcConfig.StateExpiry); err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao guardar o login OIDC pendente.", err)
+		return domain.OIDCAuthorization{}, apperror.NewInternalError("Falha ao iniciar o login OIDC.", err)
+	}
+	return domain.OIDCAuthorization{AuthorizationURL: authURL, State: state}, nil
+}
+
+// CompleteOIDCLogin conclui o login no retorno do provedor: confere o state, troca o código pelo ID
+// token, valida-o e resolve o usuário (identidade já vinculada, conta local com o mesmo e-mail
+// verificado ou um usuário novo). O segundo fator e a emissão dos tokens seguem o login local.
+func (s *UserService) CompleteOIDCLogin(ctx context.Context, callback domain.OIDCCallback) (domain.AuthTokens, error) {
+	if s.oidcProvider == nil {
+		return domain.AuthTokens{}, apperror.NewNotFoundError("Login OIDC não configurado.")
+	}
+	if callback.State == "" {
+		return domain.AuthTokens{}, apperror.NewValidationError("O parâmetro 'state' é obrigatório.")
+	}
+
+	// O state é consumido mesmo quando o provedor retorna erro: cada tentativa vale uma vez
+	pending, err := s.oidcStates.Take(ctx, callback.State)
+	if errors.Is(err, oidc.ErrUnknownState) {
+		s.logger.WithContext(ctx).Warn("Retorno OIDC com state desconhecido ou expirado.", nil)
+		return domain.AuthTokens{}, apperror.NewUnauthorizedError("Login expirado ou já concluído. Inicie o login novamente.")
+	}
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao recuperar o login OIDC pendente.", err)
+		return domain.AuthTokens{}, apperror.NewInternalError("Falha ao concluir o login OIDC.", err)
+	}
+	if callback.Error != "" {
+		s.logger.WithContext(ctx).Info("Provedor OIDC recusou a autorização.", map[string]interface{}{"error": callback.Error, "description": callback.ErrorDescription})
+		return domain.AuthTokens{}, apperror.NewUnauthorizedError("Login recusado pelo provedor: " + callback.Error + ".")
+	}
+	if callback.Code == "" {
+		return domain.AuthTokens{}, apperror.NewValidationError("O parâmetro 'code' é obrigatório.")
+	}
+	if pending.TenantID != "" {
+		ctx = tenant.WithID(ctx, pending.TenantID)
+	}
+
+	rawIDToken, err := s.oidcProvider.Exchange(ctx, callback.Code, pending.CodeVerifier)
+	if err != nil {
+		s.logger.WithContext(ctx).Warn("Falha na troca do código OIDC.", map[string]interface{}{"error": err.Error()})
+		return domain.AuthTokens{}, apperror.NewUnauthorizedError("Não foi possível concluir o login com o provedor.")
+	}
+	identity, err := s.oidcProvider.VerifyIDToken(ctx, rawIDToken, pending.Nonce)
+	if err != nil {
+		s.logger.WithContext(ctx).Warn("ID token OIDC recusado.", map[string]interface{}{"error": err.Error()})
+		return domain.AuthTokens{}, apperror.NewUnauthorizedError("Não foi possível concluir o login com o provedor.")
+	}
+
+	user, err := s.oidcUser(ctx, identity)
+	if err != nil {
+		return domain.AuthTokens{}, err
+	}
+	if user.Disabled {
+		s.logger.WithContext(ctx).Warn("Tentativa de login OIDC em conta desativada.", map[string]interface{}{"user_id": user.ID})
+		return domain.AuthTokens{}, apperror.NewUnauthorizedError("Conta desativada. Procure um administrador.")
+	}
+	if user, err = s.syncOIDCRole(ctx, user, identity.Groups); err != nil {
+		return domain.AuthTokens{}, err
+	}
+
+	now := time.Now().UTC()
+	if err := s.identities.SaveIdentity(ctx, domain.UserIdentity{
+		Issuer:      identity.Issuer,
+		Subject:     identity.Subject,
+		UserID:      user.ID,
+		Email:       identity.Email,
+		CreatedAt:   now,
+		LastLoginAt: now,
+	}); err != nil {
+		return domain.AuthTokens{}, err
+	}
+
+	if challenge, required, err := s.mfaChallenge(ctx, user); err != nil || required {
+		return challenge, err
+	}
+	tokens, err := s.issueTokens(ctx, user, uuid.NewString(), uuid.NewString())
+	if err != nil {
+		return domain.AuthTokens{}, err
+	}
+	s.logger.WithContext(ctx).Info("Login OIDC concluído.", map[string]interface{}{"user_id": user.ID, "issuer": identity.Issuer, "subject": identity.Subject})
+	return tokens, nil
+}
+
+// oidcUser resolve o usuário da identidade externa. Uma conta local só é vinculada pelo e-mail se o
+// provedor o declarar verificado; caso contrário, alguém poderia assumir a conta registrando o
+// mesmo e-mail no provedor.
+func (s *UserService) oidcUser(ctx context.Context, identity oidc.Identity) (domain.User, error) {
+	var notFoundErr *apperror.NotFoundError
+
+	linked, err := s.identities.FindIdentity(ctx, identity.Issuer, identity.Subject)
+	if err == nil {
+		user, err := s.UserRepo.FindByID(ctx, linked.UserID)
+		if errors.As(err, &notFoundErr) {
+			// Com a empresa definida no início do login, a conta vinculada é de outra empresa
+			return domain.User{}, apperror.NewForbiddenError("Esta conta não pertence à empresa informada.")
+		}
+		return user, err
+	}
+	if !errors.As(err, &notFoundErr) {
+		return domain.User{}, err
+	}
+
+	email := strings.TrimSpace(identity.Email)
+	if email == "" || !identity.EmailVerified {
+		s.logger.WithContext(ctx).Warn("Identidade OIDC sem e-mail verificado.", map[string]interface{}{"issuer": identity.Issuer, "subject": identity.Subject})
+		return domain.User{}, apperror.NewForbiddenError("O provedor não informou um e-mail verificado para esta conta.")
+	}
+
+	user, err := s.UserRepo.FindByEmail(ctx, email)
+	if err == nil {
+		if user.ServiceAccount {
+			return domain.User{}, apperror.NewForbiddenError("Contas de serviço não fazem login.")
+		}
+		s.logger.WithContext(ctx).Info("Identidade OIDC vinculada a conta existente.", map[string]interface{}{"user_id": user.ID, "issuer": identity.Issuer, "subject": identity.Subject})
+		return user, nil
+	}
+	if !errors.As(err, &notFoundErr) {
+		return domain.User{}, err
+	}
+
+	if !s.oidcConfig.AutoProvision {
+		s.logger.WithContext(ctx).Info("Login OIDC de usuário sem conta (criação automática desativada).", map[string]interface{}{"email": email})
+		return domain.User{}, apperror.NewForbiddenError("Não há conta no GoStock para este usuário. Procure um administrador.")
+	}
+	role := s.mappedRole(identity.Groups)
+	if s.roles != nil {
+		if _, err := s.roles.FindRole(ctx, role); err != nil {
+			s.logger.WithContext(ctx).Error("Papel do login OIDC não existe.", err)
+			return domain.User{}, apperror.NewInternalError("Papel configurado para o login OIDC não existe.", err)
+		}
+	}
+	user, err = s.saveUser(ctx, domain.User{
+		Email:         email,
+		Role:          domain.UserRole(role),
+		EmailVerified: true, // Verificado pelo provedor
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	})
+	if err != nil {
+		return domain.User{}, err
+	}
+	s.logger.WithContext(ctx).Info("Usuário criado pelo login OIDC.", map[string]interface{}{"user_id": user.ID, "email": email, "role": role})
+	return user, nil
+}
+
+// syncOIDCRole aplica, a cada login, o papel dos grupos do provedor quando há mapeamento configurado.
+func (s *UserService) syncOIDCRole(ctx context.Context, user domain.User, groups []string) (domain.User, error) {
+	if len(s.oidcConfig.RoleMapping) == 0 || s.roleAssigner == nil {
+		return user, nil
+	}
+	role := s.mappedRole(groups)
+	if role == string(user.Role) {
+		return user, nil
+	}
+	if err := s.roleAssigner.AssignUserRole(ctx, user.ID, role); err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao sincronizar o papel do login OIDC.", err)
+		return domain.User{}, apperror.NewInternalError("Falha ao aplicar o papel do login OIDC.", err)
+	}
+	s.logger.WithContext(ctx).Info("Papel sincronizado pelos grupos do provedor OIDC.", map[string]interface{}{"user_id": user.ID, "from": user.Role, "to": role})
+	user.Role = domain.UserRole(role)
+	return user, nil
+}
+
+// mappedRole retorna o papel do primeiro mapeamento cujo grupo o usuário tem ou, sem nenhum, o padrão.
+func (s *UserService) mappedRole(groups []string) string {
+	for _, mapping := range s.oidcConfig.RoleMapping {
+		if slices.Contains(groups, mapping.Group) {
+			return mapping.Role
+		}
+	}
+	return s.oidcConfig.DefaultRole
+}
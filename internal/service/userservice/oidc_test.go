@@ -0,0 +1,281 @@
+package userservice_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/oidc"
+	"gostock/internal/pkg/oidc/oidctest"
+	"gostock/internal/service/userservice"
+)
+
+// MockIdentityRepository simula a persistência das identidades externas.
+type MockIdentityRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdentityRepository) FindIdentity(ctx domain.Context, issuer, subject string) (domain.UserIdentity, error) {
+	args := m.Called(ctx, issuer, subject)
+	return args.Get(0).(domain.UserIdentity), args.Error(1)
+}
+
+func (m *MockIdentityRepository) SaveIdentity(ctx domain.Context, identity domain.UserIdentity) error {
+	args := m.Called(ctx, identity)
+	return args.Error(0)
+}
+
+// MockRoleAssigner simula a alteração do papel do usuário.
+type MockRoleAssigner struct {
+	mock.Mock
+}
+
+func (m *MockRoleAssigner) AssignUserRole(ctx context.Context, userID, role string) error {
+	args := m.Called(ctx, userID, role)
+	return args.Error(0)
+}
+
+// oidcFixture reúne o serviço, o provedor de teste e os mocks do login OIDC.
+type oidcFixture struct {
+	svc        *userservice.UserService
+	provider   *oidctest.Provider
+	repo       *MockUserRepository
+	tokens     *MockTokenService
+	identities *MockIdentityRepository
+	roles      *MockRoleFinder
+	assigner   *MockRoleAssigner
+}
+
+// newOIDCFixture configura o serviço contra um provedor OIDC local (oidctest), com cliente confidencial.
+func newOIDCFixture(t *testing.T, cfg userservice.OIDCConfig) *oidcFixture {
+	provider, err := oidctest.Start("gostock", "segredo-do-cliente")
+	if err != nil {
+		t.Fatalf("falha ao iniciar o provedor OIDC de teste: %v", err)
+	}
+	t.Cleanup(provider.Close)
+
+	svc, repo, tokens, _ := newTestService()
+	f := &oidcFixture{
+		svc:        svc,
+		provider:   provider,
+		repo:       repo,
+		tokens:     tokens,
+		identities: new(MockIdentityRepository),
+		roles:      new(MockRoleFinder),
+		assigner:   new(MockRoleAssigner),
+	}
+	svc.SetOnboarding(f.roles, nil, userservice.OnboardingConfig{})
+	client := oidc.NewClient(oidc.Config{
+		IssuerURL:    provider.Issuer,
+		ClientID:     "gostock",
+		ClientSecret: "segredo-do-cliente",
+		RedirectURL:  "http://localhost:8080/v1/oidc/callback",
+		Scopes:       []string{"profile", "email"},
+	})
+	svc.SetOIDC(client, oidc.NewStateStore(newMemoryCache()), f.identities, f.assigner, cfg)
+
+	repo.On("SaveRefreshToken", mock.Anything, mock.AnythingOfType("domain.RefreshToken")).Return(nil).Maybe()
+	tokens.On("GenerateToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("jwt", nil).Maybe()
+	f.identities.On("SaveIdentity", mock.Anything, mock.AnythingOfType("domain.UserIdentity")).Return(nil).Maybe()
+	f.roles.On("FindRole", mock.Anything, mock.Anything).Return(domain.Role{}, nil).Maybe()
+	return f
+}
+
+// login simula o navegador: inicia o login, passa pelo provedor e conclui com o retorno.
+func (f *oidcFixture) login(t *testing.T) (domain.AuthTokens, error) {
+	start, err := f.svc.StartOIDCLogin(context.Background())
+	if err != nil {
+		t.Fatalf("falha ao iniciar o login OIDC: %v", err)
+	}
+	redirect, err := f.provider.Login(start.AuthorizationURL)
+	if err != nil {
+		t.Fatalf("falha na autorização do provedor: %v", err)
+	}
+	assert.Equal(t, start.State, redirect.Query().Get("state"))
+
+	return f.svc.CompleteOIDCLogin(context.Background(), domain.OIDCCallback{
+		Code:  redirect.Query().Get("code"),
+		State: redirect.Query().Get("state"),
+		Error: redirect.Query().Get("error"),
+	})
+}
+
+func (f *oidcFixture) noIdentity() {
+	f.identities.On("FindIdentity", mock.Anything, f.provider.Issuer, mock.Anything).
+		Return(domain.UserIdentity{}, apperror.NewNotFoundError("Identidade externa não vinculada."))
+}
+
+// TestOIDCLogin_Success_ProvisionsUserWithDefaultRole testa o primeiro login: o usuário é criado
+// com o papel padrão, o e-mail verificado pelo provedor e a identidade vinculada.
+func TestOIDCLogin_Success_ProvisionsUserWithDefaultRole(t *testing.T) {
+	f := newOIDCFixture(t, userservice.OIDCConfig{AutoProvision: true})
+	f.provider.SetUser(&oidctest.User{Subject: "sub-maria", Email: "maria@empresa.com", EmailVerified: true})
+	f.noIdentity()
+	f.repo.On("FindByEmail", mock.Anything, "maria@empresa.com").Return(domain.User{}, apperror.NewNotFoundError("Usuário não encontrado."))
+	var created domain.User
+	f.repo.On("Save", mock.Anything, mock.AnythingOfType("domain.User")).
+		Run(func(args mock.Arguments) { created = args.Get(1).(domain.User) }).
+		Return(domain.User{ID: "user-1", Email: "maria@empresa.com", Role: domain.RoleUser, EmailVerified: true}, nil)
+
+	tokens, err := f.login(t)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "jwt", tokens.AccessToken)
+	assert.NotEmpty(t, tokens.RefreshToken)
+	assert.Equal(t, domain.RoleUser, created.Role)
+	assert.True(t, created.EmailVerified)
+	assert.Empty(t, created.PasswordHash, "usuários do provedor não têm senha local")
+	f.identities.AssertCalled(t, "SaveIdentity", mock.Anything, mock.MatchedBy(func(identity domain.UserIdentity) bool {
+		return identity.Issuer == f.provider.Issuer && identity.Subject == "sub-maria" && identity.UserID == "user-1"
+	}))
+}
+
+// TestOIDCLogin_RoleMapping_NewUserGetsMappedRole testa o papel derivado dos grupos no primeiro login.
+func TestOIDCLogin_RoleMapping_NewUserGetsMappedRole(t *testing.T) {
+	f := newOIDCFixture(t, userservice.OIDCConfig{
+		AutoProvision: true,
+		RoleMapping:   []userservice.OIDCRoleMapping{{Group: "ti-admins", Role: "admin"}, {Group: "estoque", Role: "manager"}},
+	})
+	f.provider.SetUser(&oidctest.User{Subject: "sub-joao", Email: "joao@empresa.com", EmailVerified: true, Groups: []string{"estoque", "ti-admins"}})
+	f.noIdentity()
+	f.repo.On("FindByEmail", mock.Anything, "joao@empresa.com").Return(domain.User{}, apperror.NewNotFoundError("Usuário não encontrado."))
+	f.repo.On("Save", mock.Anything, mock.MatchedBy(func(u domain.User) bool { return u.Role == domain.RoleAdmin })).
+		Return(domain.User{ID: "user-2", Email: "joao@empresa.com", Role: domain.RoleAdmin}, nil)
+
+	_, err := f.login(t)
+
+	assert.NoError(t, err)
+	f.roles.AssertCalled(t, "FindRole", mock.Anything, "admin")
+	f.assigner.AssertNotCalled(t, "AssignUserRole", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestOIDCLogin_RoleMapping_SyncsLinkedUser testa a atualização do papel a cada login: sem grupo
+// mapeado, o usuário volta ao papel padrão.
+func TestOIDCLogin_RoleMapping_SyncsLinkedUser(t *testing.T) {
+	f := newOIDCFixture(t, userservice.OIDCConfig{
+		AutoProvision: true,
+		RoleMapping:   []userservice.OIDCRoleMapping{{Group: "ti-admins", Role: "admin"}},
+	})
+	f.provider.SetUser(&oidctest.User{Subject: "sub-ana", Email: "ana@empresa.com", EmailVerified: true, Groups: []string{"vendas"}})
+	f.identities.On("FindIdentity", mock.Anything, f.provider.Issuer, "sub-ana").Return(domain.UserIdentity{UserID: "user-3"}, nil)
+	f.repo.On("FindByID", mock.Anything, "user-3").Return(domain.User{ID: "user-3", Role: domain.RoleAdmin}, nil)
+	f.assigner.On("AssignUserRole", mock.Anything, "user-3", "user").Return(nil)
+
+	_, err := f.login(t)
+
+	assert.NoError(t, err)
+	f.assigner.AssertExpectations(t)
+	f.tokens.AssertCalled(t, "GenerateToken", "user-3", "user", mock.Anything, mock.Anything)
+}
+
+// TestOIDCLogin_LinksExistingAccountByVerifiedEmail testa o vínculo com a conta local de mesmo e-mail.
+func TestOIDCLogin_LinksExistingAccountByVerifiedEmail(t *testing.T) {
+	f := newOIDCFixture(t, userservice.OIDCConfig{AutoProvision: true})
+	f.provider.SetUser(&oidctest.User{Subject: "sub-carla", Email: "carla@empresa.com", EmailVerified: true})
+	f.noIdentity()
+	f.repo.On("FindByEmail", mock.Anything, "carla@empresa.com").Return(domain.User{ID: "user-4", Email: "carla@empresa.com", Role: domain.RoleUser}, nil)
+
+	_, err := f.login(t)
+
+	assert.NoError(t, err)
+	f.repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	f.identities.AssertCalled(t, "SaveIdentity", mock.Anything, mock.MatchedBy(func(identity domain.UserIdentity) bool {
+		return identity.UserID == "user-4" && identity.Subject == "sub-carla"
+	}))
+}
+
+// TestOIDCLogin_Fail_UnverifiedEmailIsNotLinked testa que um e-mail não verificado não assume a conta local.
+func TestOIDCLogin_Fail_UnverifiedEmailIsNotLinked(t *testing.T) {
+	f := newOIDCFixture(t, userservice.OIDCConfig{AutoProvision: true})
+	f.provider.SetUser(&oidctest.User{Subject: "sub-x", Email: "carla@empresa.com", EmailVerified: false})
+	f.noIdentity()
+
+	_, err := f.login(t)
+
+	var authErr *apperror.UnauthorizedError
+	assert.ErrorAs(t, err, &authErr)
+	assert.True(t, authErr.Forbidden)
+	f.repo.AssertNotCalled(t, "FindByEmail", mock.Anything, mock.Anything)
+	f.identities.AssertNotCalled(t, "SaveIdentity", mock.Anything, mock.Anything)
+}
+
+// TestOIDCLogin_Fail_AutoProvisionDisabled testa que, sem criação automática, só contas existentes entram.
+func TestOIDCLogin_Fail_AutoProvisionDisabled(t *testing.T) {
+	f := newOIDCFixture(t, userservice.OIDCConfig{AutoProvision: false})
+	f.provider.SetUser(&oidctest.User{Subject: "sub-novo", Email: "novo@empresa.com", EmailVerified: true})
+	f.noIdentity()
+	f.repo.On("FindByEmail", mock.Anything, "novo@empresa.com").Return(domain.User{}, apperror.NewNotFoundError("Usuário não encontrado."))
+
+	_, err := f.login(t)
+
+	var authErr *apperror.UnauthorizedError
+	assert.ErrorAs(t, err, &authErr)
+	assert.True(t, authErr.Forbidden)
+	f.repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+// TestOIDCLogin_Fail_DisabledAccount testa que a conta desativada não entra pelo provedor.
+func TestOIDCLogin_Fail_DisabledAccount(t *testing.T) {
+	f := newOIDCFixture(t, userservice.OIDCConfig{AutoProvision: true})
+	f.provider.SetUser(&oidctest.User{Subject: "sub-ze", Email: "ze@empresa.com", EmailVerified: true})
+	f.identities.On("FindIdentity", mock.Anything, f.provider.Issuer, "sub-ze").Return(domain.UserIdentity{UserID: "user-5"}, nil)
+	f.repo.On("FindByID", mock.Anything, "user-5").Return(domain.User{ID: "user-5", Role: domain.RoleUser, Disabled: true}, nil)
+
+	_, err := f.login(t)
+
+	assert.IsType(t, &apperror.UnauthorizedError{}, err)
+	f.tokens.AssertNotCalled(t, "GenerateToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestOIDCLogin_Fail_ProviderDenied testa a recusa do usuário no provedor.
+func TestOIDCLogin_Fail_ProviderDenied(t *testing.T) {
+	f := newOIDCFixture(t, userservice.OIDCConfig{AutoProvision: true})
+	f.provider.SetUser(nil)
+
+	_, err := f.login(t)
+
+	assert.IsType(t, &apperror.UnauthorizedError{}, err)
+}
+
+// TestCompleteOIDCLogin_Fail_StateIsSingleUse testa que o retorno não pode ser repetido com o mesmo state.
+func TestCompleteOIDCLogin_Fail_StateIsSingleUse(t *testing.T) {
+	f := newOIDCFixture(t, userservice.OIDCConfig{AutoProvision: true})
+	f.provider.SetUser(&oidctest.User{Subject: "sub-ana", Email: "ana@empresa.com", EmailVerified: true})
+	f.identities.On("FindIdentity", mock.Anything, f.provider.Issuer, "sub-ana").Return(domain.UserIdentity{UserID: "user-3"}, nil)
+	f.repo.On("FindByID", mock.Anything, "user-3").Return(domain.User{ID: "user-3", Role: domain.RoleUser}, nil)
+
+	start, err := f.svc.StartOIDCLogin(context.Background())
+	assert.NoError(t, err)
+	redirect, err := f.provider.Login(start.AuthorizationURL)
+	if err != nil {
+		t.Fatalf("falha na autorização do provedor: %v", err)
+	}
+	callback := domain.OIDCCallback{Code: redirect.Query().Get("code"), State: redirect.Query().Get("state")}
+
+	_, err = f.svc.CompleteOIDCLogin(context.Background(), callback)
+	assert.NoError(t, err)
+	_, err = f.svc.CompleteOIDCLogin(context.Background(), callback)
+	assert.IsType(t, &apperror.UnauthorizedError{}, err)
+}
+
+// TestCompleteOIDCLogin_Fail_UnknownState testa o retorno com um state que não foi emitido.
+func TestCompleteOIDCLogin_Fail_UnknownState(t *testing.T) {
+	f := newOIDCFixture(t, userservice.OIDCConfig{AutoProvision: true})
+
+	_, err := f.svc.CompleteOIDCLogin(context.Background(), domain.OIDCCallback{Code: "abc", State: "forjado"})
+
+	assert.IsType(t, &apperror.UnauthorizedError{}, err)
+}
+
+// TestStartOIDCLogin_Fail_NotConfigured testa os endpoints sem o provedor configurado.
+func TestStartOIDCLogin_Fail_NotConfigured(t *testing.T) {
+	svc, _, _, _ := newTestService()
+
+	_, err := svc.StartOIDCLogin(context.Background())
+
+	assert.IsType(t, &apperror.NotFoundError{}, err)
+}
@@ -0,0 +1,191 @@
+package userservice
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/tenant"
+	"gostock/internal/pkg/token"
+)
+
+// RoleFinder é o contrato de consulta de papéis usado para validar o papel dos convites.
+type RoleFinder interface {
+	FindRole(ctx context.Context, name string) (domain.Role, error)
+}
+
+// InvitationSigner é o contrato de assinatura e validação dos convites (token.Service).
+type InvitationSigner interface {
+	GenerateInvitation(email, role, tenantID string, expiry time.Duration) (string, time.Time, error)
+	ValidateInvitation(tokenString string) (*token.InvitationClaims, error)
+}
+
+// OnboardingConfig configura a criação do primeiro administrador e os convites.
+type OnboardingConfig struct {
+	SetupToken       string        // Token de uso único para criar o primeiro admin (vazio: desativado)
+	InvitationExpiry time.Duration // Validade dos convites
+	InvitationURL    string        // Endereço de aceite do convite; o token é anexado como ?token=
+}
+
+// SetOnboarding habilita o setup do primeiro administrador e os convites. Sem esta configuração,
+// apenas o registro público (papel padrão) e o comando de criação de admin estão disponíveis.
+func (s *UserService) SetOnboarding(roles RoleFinder, invitations InvitationSigner, cfg OnboardingConfig) {
+	s.roles = roles
+	s.invitations = invitations
+	s.onboarding = cfg
+}
+
+// CreateAdmin cria uma conta com o papel admin. É usado pelo comando createadmin, executado por
+// quem tem acesso ao servidor, e pelo setup do primeiro administrador.
+func (s *UserService) CreateAdmin(ctx context.Context, registration domain.UserRegistration) (domain.User, error) {
+	email := strings.TrimSpace(registration.Email)
+	if err := validateCredentials(email, registration.Password); err != nil {
+		return domain.User{}, err
+	}
+
+	user, err := s.createUser(ctx, email, registration.Password, domain.RoleAdmin, true)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	s.logger.WithContext(ctx).Info("Administrador criado.", map[string]interface{}{"user_id": user.ID, "email": user.Email})
+	return user, nil
+}
+
+// SetupAdmin cria o primeiro administrador com o token de setup (SETUP_TOKEN). O token só vale
+// enquanto não houver nenhum administrador; depois disso, novos admins são criados por convite,
+// atribuição de papel ou pelo comando createadmin.
+func (s *UserService) SetupAdmin(ctx context.Context, setup domain.AdminSetup) (domain.User, error) {
+	if s.onboarding.SetupToken == "" {
+		return domain.User{}, apperror.NewNotFoundError("Setup inicial desativado (SETUP_TOKEN não configurado).")
+	}
+	if subtle.ConstantTimeCompare([]byte(setup.SetupToken), []byte(s.onboarding.SetupToken)) != 1 {
+		s.logger.WithContext(ctx).Warn("Tentativa de setup com token inválido.", nil)
+		return domain.User{}, apperror.NewUnauthorizedError("Token de setup inválido.")
+	}
+
+	admins, err := s.UserRepo.CountByRole(ctx, domain.RoleAdmin)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if admins > 0 {
+		return domain.User{}, apperror.NewConflictError("O administrador inicial já foi criado; o token de setup não é mais aceito.")
+	}
+
+	return s.CreateAdmin(ctx, domain.UserRegistration{Email: setup.Email, Password: setup.Password})
+}
+
+// InviteUser assina um convite para o e-mail com o papel informado, na empresa do contexto. O
+// convite expira após InvitationExpiry e só pode ser aceito uma vez, pois a conta criada ocupa o e-mail.
+func (s *UserService) InviteUser(ctx context.Context, request domain.InvitationRequest) (domain.Invitation, error) {
+	if s.invitations == nil || s.roles == nil {
+		return domain.Invitation{}, apperror.NewInternalError("Convites não configurados.", nil)
+	}
+
+	email := strings.TrimSpace(request.Email)
+	if err := validateEmail(email); err != nil {
+		return domain.Invitation{}, err
+	}
+	role := strings.ToLower(strings.TrimSpace(request.Role))
+	if role == "" {
+		role = string(domain.DefaultRole)
+	}
+	if _, err := s.roles.FindRole(ctx, role); err != nil {
+		return domain.Invitation{}, err // NotFoundError para papel inexistente
+	}
+
+	_, err := s.UserRepo.FindByEmail(ctx, email)
+	var notFoundErr *apperror.NotFoundError
+	if err == nil {
+		return domain.Invitation{}, apperror.NewConflictError(fmt.Sprintf("O email '%s' já está em uso.", email))
+	}
+	if !errors.As(err, &notFoundErr) {
+		return domain.Invitation{}, err
+	}
+
+	signed, expiresAt, err := s.invitations.GenerateInvitation(email, role, tenant.ID(ctx), s.onboarding.InvitationExpiry)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao assinar convite.", err)
+		return domain.Invitation{}, apperror.NewInternalError("Falha ao gerar o convite.", err)
+	}
+
+	s.logger.WithContext(ctx).Info("Convite emitido.", map[string]interface{}{"email": email, "role": role, "expires_at": expiresAt})
+	return domain.Invitation{
+		Email:     email,
+		Role:      role,
+		Token:     signed,
+		Link:      invitationLink(s.onboarding.InvitationURL, signed),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// AcceptInvitation cria a conta do convite com a senha escolhida pelo convidado.
+func (s *UserService) AcceptInvitation(ctx context.Context, acceptance domain.InvitationAcceptance) (domain.User, error) {
+	if s.invitations == nil {
+		return domain.User{}, apperror.NewInternalError("Convites não configurados.", nil)
+	}
+	if acceptance.Token == "" {
+		return domain.User{}, apperror.NewValidationError("O token do convite é obrigatório.")
+	}
+
+	claims, err := s.invitations.ValidateInvitation(acceptance.Token)
+	if err != nil {
+		s.logger.WithContext(ctx).Warn("Convite inválido ou expirado.", map[string]interface{}{"error": err.Error()})
+		return domain.User{}, apperror.NewUnauthorizedError("Convite inválido ou expirado.")
+	}
+	if err := validateCredentials(claims.Email, acceptance.Password); err != nil {
+		return domain.User{}, err
+	}
+
+	// Um convite já aceito resulta em conflito: o e-mail já pertence à conta criada. O convite
+	// chegou pelo e-mail, o que já comprova a posse do endereço. A conta é criada na empresa do
+	// convite (convites antigos, sem empresa, valem para o tenant padrão).
+	invitationTenant := claims.TenantID
+	if invitationTenant == "" {
+		invitationTenant = tenant.DefaultID
+	}
+	ctx = tenant.WithID(ctx, invitationTenant)
+	user, err := s.createUser(ctx, claims.Email, acceptance.Password, domain.UserRole(claims.Role), true)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	s.logger.WithContext(ctx).Info("Convite aceito.", map[string]interface{}{"user_id": user.ID, "email": user.Email, "role": user.Role})
+	return user, nil
+}
+
+// validateCredentials exige e-mail válido e senha preenchida.
+func validateCredentials(email, password string) error {
+	if email == "" || password == "" {
+		return apperror.NewValidationError("Email e senha são obrigatórios.")
+	}
+	return validateEmail(email)
+}
+
+// validateEmail aceita apenas o endereço puro (sem nome de exibição, ex.: "Ana <ana@x.com>").
+func validateEmail(email string) error {
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return apperror.NewValidationError("Informe um email válido.")
+	}
+	return nil
+}
+
+// invitationLink anexa o token ao endereço de aceite configurado.
+func invitationLink(baseURL, signed string) string {
+	if baseURL == "" {
+		return ""
+	}
+	separator := "?"
+	if strings.Contains(baseURL, "?") {
+		separator = "&"
+	}
+	return baseURL + separator + "token=" + url.QueryEscape(signed)
+}
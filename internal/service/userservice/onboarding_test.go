@@ -0,0 +1,146 @@
+package userservice_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/token"
+	"gostock/internal/service/userservice"
+)
+
+// MockRoleFinder simula a consulta de papéis.
+type MockRoleFinder struct {
+	mock.Mock
+}
+
+func (m *MockRoleFinder) FindRole(ctx context.Context, name string) (domain.Role, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(domain.Role), args.Error(1)
+}
+
+// newOnboardingService configura o serviço com um token de setup e convites assinados com HS256.
+func newOnboardingService() (*userservice.UserService, *MockUserRepository, *MockRoleFinder, *token.Service) {
+	svc, repo, _, _ := newTestService()
+	roles := new(MockRoleFinder)
+	signer := token.NewService("segredo-de-teste", 15*time.Minute)
+	svc.SetOnboarding(roles, signer, userservice.OnboardingConfig{
+		SetupToken:       "setup-123",
+		InvitationExpiry: time.Hour,
+		InvitationURL:    "https://app.gostock.com/convite",
+	})
+	return svc, repo, roles, signer
+}
+
+func TestRegister_AlwaysDefaultRole(t *testing.T) {
+	svc, repo, _, _ := newTestService()
+	repo.On("Save", mock.Anything, mock.MatchedBy(func(u domain.User) bool { return u.Role == domain.DefaultRole })).
+		Return(domain.User{ID: "user-1", Email: "a@gostock.com", Role: domain.DefaultRole}, nil)
+
+	user, err := svc.Register(context.Background(), domain.UserRegistration{Email: "a@gostock.com", Password: "senha123"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.RoleUser, user.Role)
+	repo.AssertExpectations(t)
+}
+
+func TestSetupAdmin_Fail_InvalidToken(t *testing.T) {
+	svc, repo, _, _ := newOnboardingService()
+
+	_, err := svc.SetupAdmin(context.Background(), domain.AdminSetup{SetupToken: "errado", Email: "admin@gostock.com", Password: "senha123"})
+
+	var unauthorized *apperror.UnauthorizedError
+	assert.ErrorAs(t, err, &unauthorized)
+	repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+func TestSetupAdmin_Fail_AdminAlreadyExists(t *testing.T) {
+	svc, repo, _, _ := newOnboardingService()
+	repo.On("CountByRole", mock.Anything, domain.RoleAdmin).Return(1, nil)
+
+	_, err := svc.SetupAdmin(context.Background(), domain.AdminSetup{SetupToken: "setup-123", Email: "admin@gostock.com", Password: "senha123"})
+
+	var conflict *apperror.ConflictError
+	assert.ErrorAs(t, err, &conflict)
+	repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+func TestSetupAdmin_Success_CreatesFirstAdmin(t *testing.T) {
+	svc, repo, _, _ := newOnboardingService()
+	repo.On("CountByRole", mock.Anything, domain.RoleAdmin).Return(0, nil)
+	repo.On("Save", mock.Anything, mock.MatchedBy(func(u domain.User) bool { return u.Role == domain.RoleAdmin })).
+		Return(domain.User{ID: "admin-1", Email: "admin@gostock.com", Role: domain.RoleAdmin}, nil)
+
+	admin, err := svc.SetupAdmin(context.Background(), domain.AdminSetup{SetupToken: "setup-123", Email: "admin@gostock.com", Password: "senha123"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.RoleAdmin, admin.Role)
+}
+
+func TestSetupAdmin_Fail_Disabled(t *testing.T) {
+	svc, _, _, _ := newTestService()
+
+	_, err := svc.SetupAdmin(context.Background(), domain.AdminSetup{SetupToken: "", Email: "admin@gostock.com", Password: "senha123"})
+
+	var notFound *apperror.NotFoundError
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func TestInviteAndAccept_CreatesUserWithInvitedRole(t *testing.T) {
+	svc, repo, roles, _ := newOnboardingService()
+	roles.On("FindRole", mock.Anything, "warehouse_staff").Return(domain.Role{Name: "warehouse_staff"}, nil)
+	repo.On("FindByEmail", mock.Anything, "ana@gostock.com").Return(domain.User{}, apperror.NewNotFoundError("não encontrado"))
+
+	invitation, err := svc.InviteUser(context.Background(), domain.InvitationRequest{Email: "ana@gostock.com", Role: "Warehouse_Staff"})
+	assert.NoError(t, err)
+	assert.Equal(t, "warehouse_staff", invitation.Role)
+	assert.True(t, strings.HasPrefix(invitation.Link, "https://app.gostock.com/convite?token="))
+	assert.WithinDuration(t, time.Now().Add(time.Hour), invitation.ExpiresAt, time.Minute)
+
+	repo.On("Save", mock.Anything, mock.MatchedBy(func(u domain.User) bool {
+		return u.Email == "ana@gostock.com" && u.Role == "warehouse_staff" && u.EmailVerified
+	})).Return(domain.User{ID: "user-2", Email: "ana@gostock.com", Role: "warehouse_staff"}, nil)
+
+	user, err := svc.AcceptInvitation(context.Background(), domain.InvitationAcceptance{Token: invitation.Token, Password: "senha123"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.UserRole("warehouse_staff"), user.Role)
+	repo.AssertExpectations(t)
+}
+
+func TestInviteUser_Fail_UnknownRole(t *testing.T) {
+	svc, _, roles, _ := newOnboardingService()
+	roles.On("FindRole", mock.Anything, "gerente").Return(domain.Role{}, apperror.NewNotFoundError("Papel 'gerente' não encontrado."))
+
+	_, err := svc.InviteUser(context.Background(), domain.InvitationRequest{Email: "ana@gostock.com", Role: "gerente"})
+
+	var notFound *apperror.NotFoundError
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func TestAcceptInvitation_Fail_AccessTokenIsNotInvitation(t *testing.T) {
+	svc, repo, _, signer := newOnboardingService()
+	accessToken, _ := signer.GenerateToken("user-1", "admin", "", "sessao-1")
+
+	_, err := svc.AcceptInvitation(context.Background(), domain.InvitationAcceptance{Token: accessToken, Password: "senha123"})
+
+	var unauthorized *apperror.UnauthorizedError
+	assert.ErrorAs(t, err, &unauthorized)
+	repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+func TestInvitation_IsNotAcceptedAsAccessToken(t *testing.T) {
+	signer := token.NewService("segredo-de-teste", 15*time.Minute)
+	invitation, _, err := signer.GenerateInvitation("ana@gostock.com", "admin", "", time.Hour)
+	assert.NoError(t, err)
+
+	_, err = signer.ValidateToken(invitation)
+
+	assert.Error(t, err)
+}
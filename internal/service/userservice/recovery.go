@@ -0,0 +1,224 @@
+package userservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/mailer"
+	"gostock/internal/pkg/token"
+)
+
+// AccountEmailConfig configura a redefinição de senha e a verificação de e-mail.
+type AccountEmailConfig struct {
+	PasswordResetExpiry       time.Duration // Validade dos links de redefinição de senha
+	PasswordResetURL          string        // Formulário de redefinição (vazio: o e-mail traz só o token)
+	EmailVerificationExpiry   time.Duration // Validade dos links de verificação de e-mail
+	VerifyEmailURL            string        // Endereço de verificação; o token é anexado como ?token=
+	EmailVerificationRequired bool          // Bloqueia o login de contas com e-mail não verificado
+}
+
+// SetAccountEmails habilita a redefinição de senha e a verificação de e-mail. Sem esta
+// configuração, os cadastros não recebem e-mail de verificação e os endpoints respondem 500.
+func (s *UserService) SetAccountEmails(tokens domain.UserTokenRepository, mail mailer.Mailer, cfg AccountEmailConfig) {
+	s.userTokens = tokens
+	s.mailer = mail
+	s.accountEmails = cfg
+}
+
+// ForgotPassword envia ao e-mail um link de redefinição de senha. A resposta é a mesma para
+// e-mails inexistentes ou contas desativadas, para não revelar quais contas existem.
+func (s *UserService) ForgotPassword(ctx context.Context, email string) error {
+	if s.userTokens == nil || s.mailer == nil {
+		return apperror.NewInternalError("Redefinição de senha não configurada.", nil)
+	}
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return apperror.NewValidationError("O email é obrigatório.")
+	}
+
+	user, err := s.UserRepo.FindByEmail(ctx, email)
+	if err != nil {
+		var notFoundErr *apperror.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			s.logger.WithContext(ctx).Info("Redefinição de senha pedida para email não cadastrado.", map[string]interface{}{"email": email})
+			return nil
+		}
+		return err
+	}
+	if user.Disabled {
+		s.logger.WithContext(ctx).Warn("Redefinição de senha pedida para conta desativada.", map[string]interface{}{"user_id": user.ID})
+		return nil
+	}
+	if user.ServiceAccount {
+		s.logger.WithContext(ctx).Warn("Redefinição de senha pedida para conta de serviço.", map[string]interface{}{"user_id": user.ID})
+		return nil
+	}
+
+	// Apenas o link mais recente vale
+	if err := s.userTokens.InvalidateUserTokens(ctx, user.ID, domain.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+	plain, err := s.issueUserToken(ctx, user.ID, domain.TokenPurposePasswordReset, s.accountEmails.PasswordResetExpiry)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Recebemos um pedido de redefinição de senha para a sua conta GoStock.\n\n%s\n\n"+
+		"O link expira em %s e só pode ser usado uma vez. Se você não fez o pedido, ignore este e-mail.",
+		tokenInstructions(s.accountEmails.PasswordResetURL, plain), s.accountEmails.PasswordResetExpiry)
+	if err := s.mailer.Send(ctx, mailer.Message{To: user.Email, Subject: "Redefinição de senha - GoStock", Body: body}); err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao enviar e-mail de redefinição de senha.", err)
+		return apperror.NewInternalError("Falha ao enviar o e-mail de redefinição de senha.", err)
+	}
+
+	s.logger.WithContext(ctx).Info("Link de redefinição de senha enviado.", map[string]interface{}{"user_id": user.ID})
+	return nil
+}
+
+// ResetPassword troca a senha com o token recebido por e-mail. O token é consumido, as demais
+// redefinições pendentes são invalidadas e todas as sessões do usuário são encerradas.
+func (s *UserService) ResetPassword(ctx context.Context, reset domain.PasswordReset) error {
+	if s.userTokens == nil {
+		return apperror.NewInternalError("Redefinição de senha não configurada.", nil)
+	}
+	if reset.Token == "" || reset.NewPassword == "" {
+		return apperror.NewValidationError("O token e a nova senha são obrigatórios.")
+	}
+
+	userToken, err := s.consumeUserToken(ctx, domain.TokenPurposePasswordReset, reset.Token)
+	if err != nil {
+		return err
+	}
+	user, err := s.UserRepo.FindByID(ctx, userToken.UserID)
+	if err != nil {
+		return err
+	}
+	if user.Disabled {
+		return apperror.NewUnauthorizedError("Conta desativada. Procure um administrador.")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(reset.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao gerar hash da senha.", err)
+		return apperror.NewInternalError("Falha ao gerar hash da senha.", err)
+	}
+	if err := s.UserRepo.UpdatePassword(ctx, user.ID, string(hashedPassword)); err != nil {
+		return err
+	}
+	// Quem recebeu o link no e-mail comprovou ser dono do endereço
+	if !user.EmailVerified {
+		if err := s.UserRepo.MarkEmailVerified(ctx, user.ID); err != nil {
+			return err
+		}
+	}
+	if err := s.userTokens.InvalidateUserTokens(ctx, user.ID, domain.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+	if err := s.revokeUser(ctx, user.ID); err != nil {
+		return err
+	}
+
+	s.logger.WithContext(ctx).Info("Senha redefinida; sessões do usuário encerradas.", map[string]interface{}{"user_id": user.ID})
+	return nil
+}
+
+// VerifyEmail confirma o e-mail do usuário com o token enviado no registro.
+func (s *UserService) VerifyEmail(ctx context.Context, plainToken string) error {
+	if s.userTokens == nil {
+		return apperror.NewInternalError("Verificação de e-mail não configurada.", nil)
+	}
+	if plainToken == "" {
+		return apperror.NewValidationError("O token de verificação é obrigatório.")
+	}
+
+	userToken, err := s.consumeUserToken(ctx, domain.TokenPurposeEmailVerification, plainToken)
+	if err != nil {
+		return err
+	}
+	if err := s.UserRepo.MarkEmailVerified(ctx, userToken.UserID); err != nil {
+		return err
+	}
+
+	s.logger.WithContext(ctx).Info("E-mail verificado.", map[string]interface{}{"user_id": userToken.UserID})
+	return nil
+}
+
+// sendVerificationEmail emite o token de verificação e envia o link ao usuário recém-registrado.
+func (s *UserService) sendVerificationEmail(ctx context.Context, user domain.User) error {
+	plain, err := s.issueUserToken(ctx, user.ID, domain.TokenPurposeEmailVerification, s.accountEmails.EmailVerificationExpiry)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Bem-vindo ao GoStock! Confirme o seu e-mail:\n\n%s\n\nO link expira em %s.",
+		tokenInstructions(s.accountEmails.VerifyEmailURL, plain), s.accountEmails.EmailVerificationExpiry)
+	return s.mailer.Send(ctx, mailer.Message{To: user.Email, Subject: "Confirme o seu e-mail - GoStock", Body: body})
+}
+
+// issueUserToken gera um token de uso único e persiste apenas o hash.
+func (s *UserService) issueUserToken(ctx context.Context, userID string, purpose domain.UserTokenPurpose, expiry time.Duration) (string, error) {
+	plain, hash, err := token.NewOneTimeToken()
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao gerar token de uso único.", err)
+		return "", apperror.NewInternalError("Falha ao gerar o token.", err)
+	}
+
+	now := time.Now().UTC()
+	if err := s.userTokens.SaveUserToken(ctx, domain.UserToken{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hash,
+		ExpiresAt: now.Add(expiry),
+		CreatedAt: now,
+	}); err != nil {
+		return "", err
+	}
+	return plain, nil
+}
+
+// consumeUserToken valida o token (finalidade, uso e validade) e o marca como usado. Entre
+// requisições concorrentes com o mesmo token, apenas uma o consome.
+func (s *UserService) consumeUserToken(ctx context.Context, purpose domain.UserTokenPurpose, plain string) (domain.UserToken, error) {
+	invalid := apperror.NewUnauthorizedError("Token inválido ou expirado.")
+
+	userToken, err := s.userTokens.FindUserTokenByHash(ctx, purpose, token.HashOneTimeToken(plain))
+	if err != nil {
+		var notFoundErr *apperror.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			s.logger.WithContext(ctx).Warn("Token de uso único desconhecido.", map[string]interface{}{"purpose": purpose})
+			return domain.UserToken{}, invalid
+		}
+		return domain.UserToken{}, err
+	}
+	if userToken.UsedAt != nil || time.Now().After(userToken.ExpiresAt) {
+		s.logger.WithContext(ctx).Warn("Token de uso único já usado ou expirado.", map[string]interface{}{"user_id": userToken.UserID, "purpose": purpose})
+		return domain.UserToken{}, invalid
+	}
+
+	consumed, err := s.userTokens.ConsumeUserToken(ctx, userToken.ID)
+	if err != nil {
+		return domain.UserToken{}, err
+	}
+	if !consumed {
+		return domain.UserToken{}, invalid
+	}
+	return userToken, nil
+}
+
+// tokenInstructions monta o trecho do e-mail com o link (quando há endereço configurado) ou
+// apenas o token, para ser informado no aplicativo.
+func tokenInstructions(baseURL, plain string) string {
+	if baseURL == "" {
+		return "Token: " + plain
+	}
+	return invitationLink(baseURL, plain)
+}
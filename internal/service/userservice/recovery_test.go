@@ -0,0 +1,227 @@
+package userservice_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/mailer"
+	"gostock/internal/pkg/token"
+	"gostock/internal/service/userservice"
+)
+
+// MockUserTokenRepository simula a persistência dos tokens de uso único.
+type MockUserTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserTokenRepository) SaveUserToken(ctx domain.Context, t domain.UserToken) error {
+	args := m.Called(ctx, t)
+	return args.Error(0)
+}
+
+func (m *MockUserTokenRepository) FindUserTokenByHash(ctx domain.Context, purpose domain.UserTokenPurpose, tokenHash string) (domain.UserToken, error) {
+	args := m.Called(ctx, purpose, tokenHash)
+	return args.Get(0).(domain.UserToken), args.Error(1)
+}
+
+func (m *MockUserTokenRepository) ConsumeUserToken(ctx domain.Context, id string) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserTokenRepository) InvalidateUserTokens(ctx domain.Context, userID string, purpose domain.UserTokenPurpose) error {
+	args := m.Called(ctx, userID, purpose)
+	return args.Error(0)
+}
+
+// MockMailer simula o envio de e-mails.
+type MockMailer struct {
+	mock.Mock
+}
+
+func (m *MockMailer) Send(ctx context.Context, msg mailer.Message) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+// newRecoveryService configura o serviço com redefinição de senha e verificação de e-mail.
+func newRecoveryService(requireVerified bool) (*userservice.UserService, *MockUserRepository, *MockUserTokenRepository, *MockMailer, *MockRevoker) {
+	svc, repo, _, revoker := newTestService()
+	userTokens := new(MockUserTokenRepository)
+	mail := new(MockMailer)
+	svc.SetAccountEmails(userTokens, mail, userservice.AccountEmailConfig{
+		PasswordResetExpiry:       time.Hour,
+		PasswordResetURL:          "https://app.gostock.com/redefinir",
+		EmailVerificationExpiry:   48 * time.Hour,
+		VerifyEmailURL:            "https://api.gostock.com/v1/verify-email",
+		EmailVerificationRequired: requireVerified,
+	})
+	return svc, repo, userTokens, mail, revoker
+}
+
+// tokenFromBody extrai o token do link enviado no e-mail.
+func tokenFromBody(body string) string {
+	_, after, _ := strings.Cut(body, "?token=")
+	return strings.Fields(after)[0]
+}
+
+func TestRegister_SendsVerificationEmail(t *testing.T) {
+	svc, repo, userTokens, mail, _ := newRecoveryService(false)
+	repo.On("Save", mock.Anything, mock.MatchedBy(func(u domain.User) bool { return !u.EmailVerified })).
+		Return(domain.User{ID: "user-1", Email: "ana@gostock.com"}, nil)
+
+	var saved domain.UserToken
+	userTokens.On("SaveUserToken", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(domain.UserToken)
+	}).Return(nil)
+	var sent mailer.Message
+	mail.On("Send", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sent = args.Get(1).(mailer.Message)
+	}).Return(nil)
+
+	_, err := svc.Register(context.Background(), domain.UserRegistration{Email: "ana@gostock.com", Password: "senha123"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.TokenPurposeEmailVerification, saved.Purpose)
+	assert.Equal(t, "ana@gostock.com", sent.To)
+	assert.Contains(t, sent.Body, "https://api.gostock.com/v1/verify-email?token=")
+	// Apenas o hash é persistido
+	plain := tokenFromBody(sent.Body)
+	assert.NotEqual(t, plain, saved.TokenHash)
+	assert.Equal(t, token.HashOneTimeToken(plain), saved.TokenHash)
+}
+
+func TestRegister_Success_WhenVerificationEmailFails(t *testing.T) {
+	svc, repo, userTokens, mail, _ := newRecoveryService(false)
+	repo.On("Save", mock.Anything, mock.Anything).Return(domain.User{ID: "user-1", Email: "ana@gostock.com"}, nil)
+	userTokens.On("SaveUserToken", mock.Anything, mock.Anything).Return(nil)
+	mail.On("Send", mock.Anything, mock.Anything).Return(errors.New("smtp indisponível"))
+
+	user, err := svc.Register(context.Background(), domain.UserRegistration{Email: "ana@gostock.com", Password: "senha123"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", user.ID)
+}
+
+func TestForgotPassword_UnknownEmail_NoEnumeration(t *testing.T) {
+	svc, repo, userTokens, mail, _ := newRecoveryService(false)
+	repo.On("FindByEmail", mock.Anything, "ninguem@gostock.com").Return(domain.User{}, apperror.NewNotFoundError("não encontrado"))
+
+	err := svc.ForgotPassword(context.Background(), "ninguem@gostock.com")
+
+	assert.NoError(t, err)
+	userTokens.AssertNotCalled(t, "SaveUserToken", mock.Anything, mock.Anything)
+	mail.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+}
+
+func TestForgotPassword_Success_InvalidatesPreviousAndSendsLink(t *testing.T) {
+	svc, repo, userTokens, mail, _ := newRecoveryService(false)
+	repo.On("FindByEmail", mock.Anything, "ana@gostock.com").Return(domain.User{ID: "user-1", Email: "ana@gostock.com"}, nil)
+	userTokens.On("InvalidateUserTokens", mock.Anything, "user-1", domain.TokenPurposePasswordReset).Return(nil).Once()
+	userTokens.On("SaveUserToken", mock.Anything, mock.MatchedBy(func(t domain.UserToken) bool {
+		return t.UserID == "user-1" && t.Purpose == domain.TokenPurposePasswordReset && t.ExpiresAt.After(time.Now().Add(59*time.Minute))
+	})).Return(nil)
+	mail.On("Send", mock.Anything, mock.MatchedBy(func(msg mailer.Message) bool {
+		return msg.To == "ana@gostock.com" && strings.Contains(msg.Body, "https://app.gostock.com/redefinir?token=")
+	})).Return(nil)
+
+	err := svc.ForgotPassword(context.Background(), "ana@gostock.com")
+
+	assert.NoError(t, err)
+	userTokens.AssertExpectations(t)
+	mail.AssertExpectations(t)
+}
+
+func TestResetPassword_Success_RevokesSessions(t *testing.T) {
+	svc, repo, userTokens, _, revoker := newRecoveryService(false)
+	hash := token.HashOneTimeToken("token-plano")
+	userTokens.On("FindUserTokenByHash", mock.Anything, domain.TokenPurposePasswordReset, hash).
+		Return(domain.UserToken{ID: "tok-1", UserID: "user-1", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+	userTokens.On("ConsumeUserToken", mock.Anything, "tok-1").Return(true, nil)
+	userTokens.On("InvalidateUserTokens", mock.Anything, "user-1", domain.TokenPurposePasswordReset).Return(nil)
+	repo.On("FindByID", mock.Anything, "user-1").Return(domain.User{ID: "user-1"}, nil)
+
+	var newHash string
+	repo.On("UpdatePassword", mock.Anything, "user-1", mock.Anything).Run(func(args mock.Arguments) {
+		newHash = args.String(2)
+	}).Return(nil)
+	repo.On("MarkEmailVerified", mock.Anything, "user-1").Return(nil)
+	repo.On("RevokeUserRefreshTokens", mock.Anything, "user-1").Return(nil)
+	revoker.On("RevokeUser", mock.Anything, "user-1", mock.Anything).Return(nil)
+
+	err := svc.ResetPassword(context.Background(), domain.PasswordReset{Token: "token-plano", NewPassword: "nova-senha"})
+
+	assert.NoError(t, err)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(newHash), []byte("nova-senha")))
+	repo.AssertExpectations(t)
+	revoker.AssertExpectations(t)
+}
+
+func TestResetPassword_Fail_UsedOrExpired(t *testing.T) {
+	usedAt := time.Now().Add(-time.Minute)
+	cases := map[string]domain.UserToken{
+		"usado":    {ID: "tok-1", UserID: "user-1", ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt},
+		"expirado": {ID: "tok-1", UserID: "user-1", ExpiresAt: time.Now().Add(-time.Minute)},
+	}
+	for name, stored := range cases {
+		t.Run(name, func(t *testing.T) {
+			svc, repo, userTokens, _, _ := newRecoveryService(false)
+			userTokens.On("FindUserTokenByHash", mock.Anything, domain.TokenPurposePasswordReset, mock.Anything).Return(stored, nil)
+
+			err := svc.ResetPassword(context.Background(), domain.PasswordReset{Token: "token-plano", NewPassword: "nova-senha"})
+
+			var unauthorized *apperror.UnauthorizedError
+			assert.ErrorAs(t, err, &unauthorized)
+			userTokens.AssertNotCalled(t, "ConsumeUserToken", mock.Anything, mock.Anything)
+			repo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestResetPassword_Fail_ConcurrentUse(t *testing.T) {
+	svc, repo, userTokens, _, _ := newRecoveryService(false)
+	userTokens.On("FindUserTokenByHash", mock.Anything, domain.TokenPurposePasswordReset, mock.Anything).
+		Return(domain.UserToken{ID: "tok-1", UserID: "user-1", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+	userTokens.On("ConsumeUserToken", mock.Anything, "tok-1").Return(false, nil)
+
+	err := svc.ResetPassword(context.Background(), domain.PasswordReset{Token: "token-plano", NewPassword: "nova-senha"})
+
+	var unauthorized *apperror.UnauthorizedError
+	assert.ErrorAs(t, err, &unauthorized)
+	repo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestVerifyEmail_Success(t *testing.T) {
+	svc, repo, userTokens, _, _ := newRecoveryService(false)
+	userTokens.On("FindUserTokenByHash", mock.Anything, domain.TokenPurposeEmailVerification, token.HashOneTimeToken("verif")).
+		Return(domain.UserToken{ID: "tok-2", UserID: "user-1", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+	userTokens.On("ConsumeUserToken", mock.Anything, "tok-2").Return(true, nil)
+	repo.On("MarkEmailVerified", mock.Anything, "user-1").Return(nil)
+
+	err := svc.VerifyEmail(context.Background(), "verif")
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestLogin_Fail_UnverifiedEmailWhenRequired(t *testing.T) {
+	svc, repo, _, _, _ := newRecoveryService(true)
+	hash, _ := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.MinCost)
+	repo.On("FindByEmail", mock.Anything, "ana@gostock.com").
+		Return(domain.User{ID: "user-1", Email: "ana@gostock.com", PasswordHash: string(hash), Role: domain.RoleUser}, nil)
+
+	_, err := svc.Login(context.Background(), "ana@gostock.com", "senha123")
+
+	var unauthorized *apperror.UnauthorizedError
+	assert.ErrorAs(t, err, &unauthorized)
+	assert.True(t, unauthorized.Forbidden)
+}
@@ -6,130 +6,241 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 
 	"gostock/internal/domain"
 	apperror "gostock/internal/errors"
 	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/mailer"
+	"gostock/internal/pkg/middleware"
 	"gostock/internal/pkg/token"
 )
 
 // UserService define o serviço de lógica de negócio para a entidade User.
 type UserService struct {
-	UserRepo domain.UserRepository
-	TokenSvc TokenService
-	logger   logger.Logger
+	UserRepo      domain.UserRepository
+	RefreshRepo   domain.RefreshTokenRepository
+	TokenSvc      TokenService
+	Revocations   TokenRevoker
+	refreshExpiry time.Duration
+	logger        logger.Logger
+
+	// Setup do primeiro administrador e convites (SetOnboarding)
+	roles       RoleFinder
+	invitations InvitationSigner
+	onboarding  OnboardingConfig
+
+	// Redefinição de senha e verificação de e-mail (SetAccountEmails)
+	userTokens    domain.UserTokenRepository
+	mailer        mailer.Mailer
+	accountEmails AccountEmailConfig
+
+	// Chaves de API e contas de serviço (SetAPIKeys)
+	apiKeys domain.APIKeyRepository
+
+	// Proteção contra força bruta no login (SetLoginGuard)
+	loginGuard LoginGuard
+
+	// Autenticação em dois fatores (SetMFA)
+	mfaRepo       domain.MFARepository
+	mfaChallenges MFAChallengeSigner
+	mfaCipher     SecretCipher
+	mfaConfig     MFAConfig
+
+	// Login com provedor OpenID Connect (SetOIDC)
+	oidcProvider OIDCProvider
+	oidcStates   OIDCStateStore
+	identities   domain.UserIdentityRepository
+	roleAssigner RoleAssigner
+	oidcConfig   OIDCConfig
+
+	// Configurações das empresas (SetTenants)
+	tenants TenantFinder
 }
 
 // TokenService é o contrato da camada de token (internal/pkg/token)
 type TokenService interface {
-	GenerateToken(userID string, userRole string) (string, error)
+	GenerateToken(userID string, userRole string, tenantID string, sessionID string) (string, error)
 	ValidateToken(tokenString string) (*token.CustomClaims, error) // Assumindo importação correta
+	Expiry() time.Duration
+}
+
+// TokenRevoker é o contrato da lista de revogação de access tokens (token.RevocationList).
+type TokenRevoker interface {
+	RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+	RevokeSession(ctx context.Context, sessionID string, ttl time.Duration) error
+	RevokeUser(ctx context.Context, userID string, ttl time.Duration) error
 }
 
 // NewService cria uma nova instância do UserService, injetando o Repositório.
-func NewService(repo domain.UserRepository, tokenSvc TokenService, logger logger.Logger) *UserService {
+// refreshExpiry é a validade de cada refresh token emitido.
+func NewService(repo domain.UserRepository, refreshRepo domain.RefreshTokenRepository, tokenSvc TokenService, revocations TokenRevoker, refreshExpiry time.Duration, logger logger.Logger) *UserService {
 	return &UserService{
-		UserRepo: repo,
-		TokenSvc: tokenSvc, // Salva o serviço injetado
-		logger:   logger,
+		UserRepo:      repo,
+		RefreshRepo:   refreshRepo,
+		TokenSvc:      tokenSvc, // Salva o serviço injetado
+		Revocations:   revocations,
+		refreshExpiry: refreshExpiry,
+		logger:        logger,
 	}
 }
 
 // Register registra um novo usuário no sistema.
 // Ele faz o hashing da senha e lida com validações básicas.
 func (s *UserService) Register(ctx context.Context, registration domain.UserRegistration) (domain.User, error) {
-	s.logger.Debug("Iniciando registro de novo usuário.", map[string]interface{}{"email": registration.Email})
+	s.logger.WithContext(ctx).Debug("Iniciando registro de novo usuário.", map[string]interface{}{"email": registration.Email})
 
 	// 1. Validação Básica (Simplificada)
 	if registration.Email == "" || registration.Password == "" {
-		s.logger.Warn("Tentativa de registro com email ou senha vazios.", nil)
+		s.logger.WithContext(ctx).Warn("Tentativa de registro com email ou senha vazios.", nil)
 		return domain.User{}, apperror.NewValidationError("Email e senha são obrigatórios.")
 	}
 
-	// 2. Hashing da Senha
+	// 2. A empresa precisa aceitar o registro público; toda conta criada por ele recebe o papel padrão
+	if err := s.checkRegistrationAllowed(ctx); err != nil {
+		return domain.User{}, err
+	}
+	user, err := s.createUser(ctx, registration.Email, registration.Password, domain.DefaultRole, false)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	// 3. Link de verificação do e-mail; a conta já existe, então a falha no envio só é registrada
+	// (o usuário pode pedir um novo link pela redefinição de senha)
+	if s.userTokens != nil && s.mailer != nil {
+		if err := s.sendVerificationEmail(ctx, user); err != nil {
+			s.logger.WithContext(ctx).Error("Falha ao enviar e-mail de verificação.", err)
+		}
+	}
+
+	s.logger.WithContext(ctx).Info("Usuário registrado com sucesso.", map[string]interface{}{"user_id": user.ID, "email": user.Email})
+	return user, nil
+}
+
+// createUser faz o hashing da senha e grava a conta com o papel informado. emailVerified indica
+// se a posse do e-mail já foi comprovada (convite recebido no e-mail, admin criado no servidor).
+func (s *UserService) createUser(ctx context.Context, email, password string, role domain.UserRole, emailVerified bool) (domain.User, error) {
+	// 1. Hashing da Senha
 	// Gera um hash forte para a senha informada.
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(registration.Password), bcrypt.DefaultCost)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		s.logger.Error("Falha ao gerar hash da senha.", err)
+		s.logger.WithContext(ctx).Error("Falha ao gerar hash da senha.", err)
 		return domain.User{}, apperror.NewInternalError("Falha ao gerar hash da senha.", err)
 	}
-	s.logger.Debug("Senha hash gerada com sucesso.", map[string]interface{}{"email": registration.Email})
+	s.logger.WithContext(ctx).Debug("Senha hash gerada com sucesso.", map[string]interface{}{"email": email})
 
-	// 3. Criação do Objeto User
+	// 2. Criação do Objeto User
 	newUser := domain.User{
-		Email:        registration.Email,
-		PasswordHash: string(hashedPassword),
-		Role:         "user", // Define o role padrão
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		Email:         email,
+		PasswordHash:  string(hashedPassword),
+		Role:          role,
+		EmailVerified: emailVerified,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 	}
 
-	// 4. Chamada ao Repositório para Persistência
+	// 3. Chamada ao Repositório para Persistência
+	return s.saveUser(ctx, newUser)
+}
+
+// saveUser grava a nova conta, tratando o e-mail duplicado como conflito. A conta pertence à
+// empresa do contexto quando TenantID não é informado.
+func (s *UserService) saveUser(ctx context.Context, newUser domain.User) (domain.User, error) {
+	if err := s.checkUserLimit(ctx, newUser.TenantID); err != nil {
+		return domain.User{}, err
+	}
 	user, err := s.UserRepo.Save(ctx, newUser)
 
 	if err != nil {
 		var dbErr *apperror.InternalError
 		if errors.As(err, &dbErr) {
-			s.logger.Warn("Tentativa de registro com email duplicado.", map[string]interface{}{"email": registration.Email, "error": err.Error()})
+			s.logger.WithContext(ctx).Warn("Tentativa de registro com email duplicado.", map[string]interface{}{"email": newUser.Email, "error": err.Error()})
 			return domain.User{}, apperror.NewConflictError(
-				fmt.Sprintf("O email '%s' já está em uso.", registration.Email),
+				fmt.Sprintf("O email '%s' já está em uso.", newUser.Email),
 			)
 		}
-		s.logger.Error("Erro ao salvar usuário no repositório durante o registro.", err)
+		s.logger.WithContext(ctx).Error("Erro ao salvar usuário no repositório durante o registro.", err)
 		return domain.User{}, err
 	}
-
-	s.logger.Info("Usuário registrado com sucesso.", map[string]interface{}{"user_id": user.ID, "email": user.Email})
 	return user, nil
 }
 
-// Login autentica um usuário, verifica a senha e gera um JWT.
-func (s *UserService) Login(ctx context.Context, email string, password string) (string, error) {
-	s.logger.Debug("Iniciando tentativa de login.", map[string]interface{}{"email_attempt": email})
+// Login autentica um usuário, verifica a senha e inicia uma sessão, emitindo um access token
+// (JWT de curta duração) e um refresh token.
+func (s *UserService) Login(ctx context.Context, email string, password string) (domain.AuthTokens, error) {
+	s.logger.WithContext(ctx).Debug("Iniciando tentativa de login.", map[string]interface{}{"email_attempt": email})
 
 	// 1. Validação Básica
 	if email == "" || password == "" {
-		s.logger.Warn("Tentativa de login com email ou senha vazios.", nil)
+		s.logger.WithContext(ctx).Warn("Tentativa de login com email ou senha vazios.", nil)
 		// Usamos UnauthorizedError para login/senha inválidos
-		return "", apperror.NewUnauthorizedError("Email e senha são obrigatórios.")
+		return domain.AuthTokens{}, apperror.NewUnauthorizedError("Email e senha são obrigatórios.")
+	}
+
+	// 2. Conta ou IP bloqueados por excesso de falhas
+	ip := middleware.GetClientIPFromContext(ctx)
+	if err := s.checkLoginLockout(ctx, email, ip); err != nil {
+		return domain.AuthTokens{}, err
 	}
 
-	// 2. Buscar Usuário pelo Email
+	// 3. Buscar Usuário pelo Email
 	user, err := s.UserRepo.FindByEmail(ctx, email)
 	if err != nil {
 		var notFoundErr *apperror.NotFoundError
 		if errors.As(err, &notFoundErr) {
-			s.logger.Info("Tentativa de login com email não encontrado.", map[string]interface{}{"email": email})
-			return "", apperror.NewUnauthorizedError("Credenciais inválidas.")
+			// Mesmo tempo e mesma resposta de uma senha incorreta: não revela quais e-mails existem
+			compareDummyPassword(password)
+			return domain.AuthTokens{}, s.loginFailed(ctx, email, ip, "unknown_email")
 		}
-		s.logger.Error("Erro interno ao buscar usuário por email.", err)
+		s.logger.WithContext(ctx).Error("Erro interno ao buscar usuário por email.", err)
 		// Retorna erro interno se falhar a busca (DB error)
-		return "", err
+		return domain.AuthTokens{}, err
 	}
-	s.logger.Debug("Usuário encontrado.", map[string]interface{}{"user_id": user.ID, "email": user.Email})
+	s.logger.WithContext(ctx).Debug("Usuário encontrado.", map[string]interface{}{"user_id": user.ID, "email": user.Email})
 
-	// 3. Comparar Senhas (Hashing)
+	// 4. Comparar Senhas (Hashing)
+	// Contas de serviço não têm senha: a resposta é a mesma de uma senha incorreta
+	if user.ServiceAccount {
+		compareDummyPassword(password)
+		return domain.AuthTokens{}, s.loginFailed(ctx, email, ip, "service_account")
+	}
 	// Compara a senha informada (texto puro) com o hash salvo no DB.
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		s.logger.Warn("Tentativa de login com senha incorreta.", map[string]interface{}{"email": email})
-		// Se a comparação falhar (senha incorreta)
-		return "", apperror.NewUnauthorizedError("Credenciais inválidas.")
+		return domain.AuthTokens{}, s.loginFailed(ctx, email, ip, "wrong_password")
+	}
+	s.logger.WithContext(ctx).Debug("Senha verificada com sucesso.", map[string]interface{}{"email": email})
+	if s.loginGuard != nil {
+		if err := s.loginGuard.RecordSuccess(ctx, email); err != nil {
+			s.logger.WithContext(ctx).Error("Falha ao zerar as falhas de login.", err)
+		}
+	}
+
+	// A conta desativada só é informada após a senha correta, para não revelar o status a terceiros
+	if user.Disabled {
+		s.logger.WithContext(ctx).Warn("Tentativa de login em conta desativada.", map[string]interface{}{"user_id": user.ID})
+		return domain.AuthTokens{}, apperror.NewUnauthorizedError("Conta desativada. Procure um administrador.")
+	}
+	if s.accountEmails.EmailVerificationRequired && !user.EmailVerified {
+		s.logger.WithContext(ctx).Info("Tentativa de login com e-mail não verificado.", map[string]interface{}{"user_id": user.ID})
+		return domain.AuthTokens{}, apperror.NewForbiddenError("E-mail não verificado. Confirme pelo link enviado no cadastro.")
+	}
+
+	// 5. Segundo fator: o login termina em POST /v1/login/mfa, com o código do autenticador
+	if challenge, required, err := s.mfaChallenge(ctx, user); err != nil || required {
+		return challenge, err
 	}
-	s.logger.Debug("Senha verificada com sucesso.", map[string]interface{}{"email": email})
 
-	// 4. Gerar JWT
-	// Se a senha estiver correta, geramos o token JWT
-	tokenString, err := s.TokenSvc.GenerateToken(user.ID, string(user.Role))
+	// 6. Gerar os Tokens
+	// Se a senha estiver correta, iniciamos uma nova sessão (família de refresh tokens)
+	tokens, err := s.issueTokens(ctx, user, uuid.NewString(), uuid.NewString())
 	if err != nil {
-		s.logger.Error("Falha ao gerar token de autenticação.", err)
-		// Log interno de erro de geração de token
-		return "", apperror.NewInternalError("Falha ao gerar token de autenticação.", err)
+		return domain.AuthTokens{}, err
 	}
-	s.logger.Info("Token JWT gerado com sucesso para o usuário.", map[string]interface{}{"user_id": user.ID})
+	s.logger.WithContext(ctx).Info("Token JWT gerado com sucesso para o usuário.", map[string]interface{}{"user_id": user.ID})
 
-	// 5. Sucesso
-	return tokenString, nil
+	// 7. Sucesso
+	return tokens, nil
 }
 
 // validateProduct verifica as regras de negócio básicas do produto e suas variações.
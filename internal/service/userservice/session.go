@@ -0,0 +1,163 @@
+package userservice
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/token"
+)
+
+// RefreshTokens troca um refresh token válido por um novo par de tokens (rotação). Cada refresh
+// token só pode ser usado uma vez: a reapresentação de um token já trocado indica vazamento,
+// e toda a sessão (família) é revogada, inclusive os access tokens já emitidos.
+func (s *UserService) RefreshTokens(ctx context.Context, refreshToken string) (domain.AuthTokens, error) {
+	if refreshToken == "" {
+		return domain.AuthTokens{}, apperror.NewUnauthorizedError("O refresh token é obrigatório.")
+	}
+
+	stored, err := s.RefreshRepo.FindRefreshTokenByHash(ctx, token.HashRefreshToken(refreshToken))
+	if err != nil {
+		var notFoundErr *apperror.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			s.logger.WithContext(ctx).Warn("Tentativa de renovação com refresh token desconhecido.", nil)
+			return domain.AuthTokens{}, apperror.NewUnauthorizedError("Refresh token inválido.")
+		}
+		return domain.AuthTokens{}, err
+	}
+
+	// 1. Reuso de um token já trocado: revoga a sessão inteira
+	if stored.ReplacedBy != "" {
+		s.logger.WithContext(ctx).Warn("Reuso de refresh token detectado; revogando a sessão.", map[string]interface{}{"user_id": stored.UserID, "family_id": stored.FamilyID})
+		if err := s.revokeSession(ctx, stored.FamilyID); err != nil {
+			return domain.AuthTokens{}, err
+		}
+		return domain.AuthTokens{}, apperror.NewUnauthorizedError("Refresh token já utilizado. A sessão foi encerrada; faça login novamente.")
+	}
+	if stored.RevokedAt != nil {
+		return domain.AuthTokens{}, apperror.NewUnauthorizedError("Sessão encerrada. Faça login novamente.")
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return domain.AuthTokens{}, apperror.NewUnauthorizedError("Refresh token expirado. Faça login novamente.")
+	}
+
+	// 2. O papel do usuário é relido para que o novo access token reflita alterações
+	user, err := s.UserRepo.FindByID(ctx, stored.UserID)
+	if err != nil {
+		var notFoundErr *apperror.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			return domain.AuthTokens{}, apperror.NewUnauthorizedError("Refresh token inválido.")
+		}
+		return domain.AuthTokens{}, err
+	}
+	if user.Disabled {
+		return domain.AuthTokens{}, apperror.NewUnauthorizedError("Conta desativada. Procure um administrador.")
+	}
+	// Sessões anteriores à exigência do segundo fator (ou à atribuição do papel) não são renovadas
+	if s.mfaRepo != nil && s.mfaRequired(user.Role) {
+		enabled, err := s.mfaEnabled(ctx, user.ID)
+		if err != nil {
+			return domain.AuthTokens{}, err
+		}
+		if !enabled {
+			return domain.AuthTokens{}, apperror.NewUnauthorizedError("O seu papel exige o segundo fator. Faça login novamente para cadastrá-lo.")
+		}
+	}
+
+	// 3. Emite o novo par e só então marca o token usado como substituído. Se outra requisição
+	// concorrente já o trocou, trata-se de reuso e a sessão é revogada (incluindo o par novo).
+	newID := uuid.NewString()
+	tokens, err := s.issueTokens(ctx, user, stored.FamilyID, newID)
+	if err != nil {
+		return domain.AuthTokens{}, err
+	}
+	rotated, err := s.RefreshRepo.RotateRefreshToken(ctx, stored.ID, newID)
+	if err != nil {
+		return domain.AuthTokens{}, err
+	}
+	if !rotated {
+		s.logger.WithContext(ctx).Warn("Refresh token usado concorrentemente; revogando a sessão.", map[string]interface{}{"user_id": stored.UserID, "family_id": stored.FamilyID})
+		if err := s.revokeSession(ctx, stored.FamilyID); err != nil {
+			return domain.AuthTokens{}, err
+		}
+		return domain.AuthTokens{}, apperror.NewUnauthorizedError("Refresh token já utilizado. A sessão foi encerrada; faça login novamente.")
+	}
+
+	s.logger.WithContext(ctx).Info("Tokens renovados.", map[string]interface{}{"user_id": user.ID, "family_id": stored.FamilyID})
+	return tokens, nil
+}
+
+// Logout encerra a sessão do access token informado: o token é revogado imediatamente e os
+// refresh tokens da sessão deixam de ser aceitos.
+func (s *UserService) Logout(ctx context.Context, userID, tokenID, sessionID string, expiresAt time.Time) error {
+	if err := s.Revocations.RevokeToken(ctx, tokenID, expiresAt); err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao revogar access token.", err)
+		return apperror.NewInternalError("Falha ao encerrar a sessão.", err)
+	}
+	if sessionID != "" {
+		if err := s.revokeSession(ctx, sessionID); err != nil {
+			return err
+		}
+	}
+
+	s.logger.WithContext(ctx).Info("Logout realizado.", map[string]interface{}{"user_id": userID, "family_id": sessionID})
+	return nil
+}
+
+// issueTokens grava um novo refresh token (com o ID informado) na sessão e emite o access token.
+// Usuários de empresas desativadas não recebem tokens (nem no login, nem na renovação).
+func (s *UserService) issueTokens(ctx context.Context, user domain.User, familyID, refreshID string) (domain.AuthTokens, error) {
+	if err := s.checkTenantActive(ctx, user); err != nil {
+		return domain.AuthTokens{}, err
+	}
+
+	plain, hash, err := token.NewRefreshToken()
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao gerar refresh token.", err)
+		return domain.AuthTokens{}, apperror.NewInternalError("Falha ao gerar token de autenticação.", err)
+	}
+
+	now := time.Now().UTC()
+	refresh := domain.RefreshToken{
+		ID:        refreshID,
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hash,
+		ExpiresAt: now.Add(s.refreshExpiry),
+		CreatedAt: now,
+	}
+	if err := s.RefreshRepo.SaveRefreshToken(ctx, refresh); err != nil {
+		return domain.AuthTokens{}, err
+	}
+
+	accessToken, err := s.TokenSvc.GenerateToken(user.ID, string(user.Role), user.TenantID, familyID)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao gerar token de autenticação.", err)
+		return domain.AuthTokens{}, apperror.NewInternalError("Falha ao gerar token de autenticação.", err)
+	}
+
+	return domain.AuthTokens{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.TokenSvc.Expiry().Seconds()),
+		RefreshToken: plain,
+		Token:        accessToken,
+	}, nil
+}
+
+// revokeSession revoga os refresh tokens da sessão e, pela lista de revogação, os access tokens
+// já emitidos para ela (que expiram no máximo após a validade de um access token).
+func (s *UserService) revokeSession(ctx context.Context, familyID string) error {
+	if err := s.RefreshRepo.RevokeRefreshTokenFamily(ctx, familyID); err != nil {
+		return err
+	}
+	if err := s.Revocations.RevokeSession(ctx, familyID, s.TokenSvc.Expiry()); err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao revogar access tokens da sessão.", err)
+		return apperror.NewInternalError("Falha ao encerrar a sessão.", err)
+	}
+	return nil
+}
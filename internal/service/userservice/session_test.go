@@ -0,0 +1,263 @@
+package userservice_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/logger"
+	"gostock/internal/pkg/token"
+	"gostock/internal/service/userservice"
+)
+
+// MockUserRepository simula o repositório de usuários e de refresh tokens.
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) Save(ctx domain.Context, user domain.User) (domain.User, error) {
+	args := m.Called(ctx, user)
+	return args.Get(0).(domain.User), args.Error(1)
+}
+
+func (m *MockUserRepository) FindByEmail(ctx domain.Context, email string) (domain.User, error) {
+	args := m.Called(ctx, email)
+	return args.Get(0).(domain.User), args.Error(1)
+}
+
+func (m *MockUserRepository) FindByID(ctx domain.Context, id string) (domain.User, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.User), args.Error(1)
+}
+
+func (m *MockUserRepository) SaveRefreshToken(ctx domain.Context, t domain.RefreshToken) error {
+	args := m.Called(ctx, t)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) FindRefreshTokenByHash(ctx domain.Context, tokenHash string) (domain.RefreshToken, error) {
+	args := m.Called(ctx, tokenHash)
+	return args.Get(0).(domain.RefreshToken), args.Error(1)
+}
+
+func (m *MockUserRepository) RotateRefreshToken(ctx domain.Context, id, replacedBy string) (bool, error) {
+	args := m.Called(ctx, id, replacedBy)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) RevokeRefreshTokenFamily(ctx domain.Context, familyID string) error {
+	args := m.Called(ctx, familyID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) RevokeUserRefreshTokens(ctx domain.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) List(ctx domain.Context, limit, offset int) ([]domain.User, int, error) {
+	args := m.Called(ctx, limit, offset)
+	return args.Get(0).([]domain.User), args.Int(1), args.Error(2)
+}
+
+func (m *MockUserRepository) CountByRole(ctx domain.Context, role domain.UserRole) (int, error) {
+	args := m.Called(ctx, role)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockUserRepository) SetDisabled(ctx domain.Context, id string, disabled bool) (domain.User, error) {
+	args := m.Called(ctx, id, disabled)
+	return args.Get(0).(domain.User), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdatePassword(ctx domain.Context, id string, passwordHash string) error {
+	args := m.Called(ctx, id, passwordHash)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) MarkEmailVerified(ctx domain.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Delete(ctx domain.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// MockTokenService simula a emissão de access tokens.
+type MockTokenService struct {
+	mock.Mock
+}
+
+func (m *MockTokenService) GenerateToken(userID string, userRole string, tenantID string, sessionID string) (string, error) {
+	args := m.Called(userID, userRole, tenantID, sessionID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockTokenService) ValidateToken(tokenString string) (*token.CustomClaims, error) {
+	args := m.Called(tokenString)
+	return args.Get(0).(*token.CustomClaims), args.Error(1)
+}
+
+func (m *MockTokenService) Expiry() time.Duration {
+	return 15 * time.Minute
+}
+
+// MockRevoker simula a lista de revogação.
+type MockRevoker struct {
+	mock.Mock
+}
+
+func (m *MockRevoker) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	args := m.Called(ctx, tokenID, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockRevoker) RevokeSession(ctx context.Context, sessionID string, ttl time.Duration) error {
+	args := m.Called(ctx, sessionID, ttl)
+	return args.Error(0)
+}
+
+func (m *MockRevoker) RevokeUser(ctx context.Context, userID string, ttl time.Duration) error {
+	args := m.Called(ctx, userID, ttl)
+	return args.Error(0)
+}
+
+func newTestService() (*userservice.UserService, *MockUserRepository, *MockTokenService, *MockRevoker) {
+	repo := new(MockUserRepository)
+	tokens := new(MockTokenService)
+	revoker := new(MockRevoker)
+	svc := userservice.NewService(repo, repo, tokens, revoker, 24*time.Hour, logger.NewLogger("debug"))
+	return svc, repo, tokens, revoker
+}
+
+// TestLogin_Success_IssuesTokenPair testa a emissão do access token e do refresh token da nova sessão.
+func TestLogin_Success_IssuesTokenPair(t *testing.T) {
+	svc, repo, tokens, _ := newTestService()
+	hash, _ := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.MinCost)
+	user := domain.User{ID: "user-1", Email: "a@gostock.com", PasswordHash: string(hash), Role: domain.RoleAdmin}
+
+	var saved domain.RefreshToken
+	repo.On("FindByEmail", mock.Anything, "a@gostock.com").Return(user, nil)
+	repo.On("SaveRefreshToken", mock.Anything, mock.AnythingOfType("domain.RefreshToken")).
+		Run(func(args mock.Arguments) { saved = args.Get(1).(domain.RefreshToken) }).
+		Return(nil)
+	tokens.On("GenerateToken", "user-1", "admin", mock.Anything, mock.AnythingOfType("string")).Return("jwt", nil)
+
+	result, err := svc.Login(context.Background(), "a@gostock.com", "senha123")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "jwt", result.AccessToken)
+	assert.Equal(t, "jwt", result.Token)
+	assert.Equal(t, 900, result.ExpiresIn)
+	assert.NotEmpty(t, result.RefreshToken)
+	assert.Equal(t, token.HashRefreshToken(result.RefreshToken), saved.TokenHash)
+	assert.NotEmpty(t, saved.FamilyID)
+	tokens.AssertCalled(t, "GenerateToken", "user-1", "admin", mock.Anything, saved.FamilyID)
+}
+
+// TestRefreshTokens_Success_Rotates testa a troca do refresh token por um novo par na mesma sessão.
+func TestRefreshTokens_Success_Rotates(t *testing.T) {
+	svc, repo, tokens, _ := newTestService()
+	stored := domain.RefreshToken{ID: "rt-1", UserID: "user-1", FamilyID: "fam-1", ExpiresAt: time.Now().Add(time.Hour)}
+
+	var saved domain.RefreshToken
+	repo.On("FindRefreshTokenByHash", mock.Anything, token.HashRefreshToken("antigo")).Return(stored, nil)
+	repo.On("FindByID", mock.Anything, "user-1").Return(domain.User{ID: "user-1", Role: domain.RoleUser}, nil)
+	repo.On("SaveRefreshToken", mock.Anything, mock.AnythingOfType("domain.RefreshToken")).
+		Run(func(args mock.Arguments) { saved = args.Get(1).(domain.RefreshToken) }).
+		Return(nil)
+	repo.On("RotateRefreshToken", mock.Anything, "rt-1", mock.AnythingOfType("string")).Return(true, nil)
+	tokens.On("GenerateToken", "user-1", "user", mock.Anything, "fam-1").Return("jwt-novo", nil)
+
+	result, err := svc.RefreshTokens(context.Background(), "antigo")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "jwt-novo", result.AccessToken)
+	assert.NotEqual(t, "antigo", result.RefreshToken)
+	assert.Equal(t, "fam-1", saved.FamilyID)
+	repo.AssertCalled(t, "RotateRefreshToken", mock.Anything, "rt-1", saved.ID)
+	repo.AssertNotCalled(t, "RevokeRefreshTokenFamily", mock.Anything, mock.Anything)
+}
+
+// TestRefreshTokens_Fail_ReuseRevokesFamily testa a revogação da sessão ao reapresentar um token já trocado.
+func TestRefreshTokens_Fail_ReuseRevokesFamily(t *testing.T) {
+	svc, repo, tokens, revoker := newTestService()
+	stored := domain.RefreshToken{ID: "rt-1", UserID: "user-1", FamilyID: "fam-1", ExpiresAt: time.Now().Add(time.Hour), ReplacedBy: "rt-2"}
+
+	repo.On("FindRefreshTokenByHash", mock.Anything, token.HashRefreshToken("vazado")).Return(stored, nil)
+	repo.On("RevokeRefreshTokenFamily", mock.Anything, "fam-1").Return(nil)
+	revoker.On("RevokeSession", mock.Anything, "fam-1", 15*time.Minute).Return(nil)
+
+	_, err := svc.RefreshTokens(context.Background(), "vazado")
+
+	assert.IsType(t, &apperror.UnauthorizedError{}, err)
+	repo.AssertExpectations(t)
+	revoker.AssertExpectations(t)
+	tokens.AssertNotCalled(t, "GenerateToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestRefreshTokens_Fail_ConcurrentUse testa a revogação quando outra requisição já rotacionou o token.
+func TestRefreshTokens_Fail_ConcurrentUse(t *testing.T) {
+	svc, repo, tokens, revoker := newTestService()
+	stored := domain.RefreshToken{ID: "rt-1", UserID: "user-1", FamilyID: "fam-1", ExpiresAt: time.Now().Add(time.Hour)}
+
+	repo.On("FindRefreshTokenByHash", mock.Anything, mock.Anything).Return(stored, nil)
+	repo.On("FindByID", mock.Anything, "user-1").Return(domain.User{ID: "user-1", Role: domain.RoleUser}, nil)
+	repo.On("SaveRefreshToken", mock.Anything, mock.Anything).Return(nil)
+	repo.On("RotateRefreshToken", mock.Anything, "rt-1", mock.Anything).Return(false, nil)
+	repo.On("RevokeRefreshTokenFamily", mock.Anything, "fam-1").Return(nil)
+	tokens.On("GenerateToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("jwt", nil)
+	revoker.On("RevokeSession", mock.Anything, "fam-1", mock.Anything).Return(nil)
+
+	_, err := svc.RefreshTokens(context.Background(), "duplicado")
+
+	assert.IsType(t, &apperror.UnauthorizedError{}, err)
+	repo.AssertCalled(t, "RevokeRefreshTokenFamily", mock.Anything, "fam-1")
+}
+
+// TestRefreshTokens_Fail_Expired testa a recusa de refresh tokens expirados.
+func TestRefreshTokens_Fail_Expired(t *testing.T) {
+	svc, repo, _, _ := newTestService()
+	stored := domain.RefreshToken{ID: "rt-1", UserID: "user-1", FamilyID: "fam-1", ExpiresAt: time.Now().Add(-time.Minute)}
+	repo.On("FindRefreshTokenByHash", mock.Anything, mock.Anything).Return(stored, nil)
+
+	_, err := svc.RefreshTokens(context.Background(), "expirado")
+
+	assert.IsType(t, &apperror.UnauthorizedError{}, err)
+	repo.AssertNotCalled(t, "SaveRefreshToken", mock.Anything, mock.Anything)
+}
+
+// TestRefreshTokens_Fail_Unknown testa a recusa de tokens que não existem.
+func TestRefreshTokens_Fail_Unknown(t *testing.T) {
+	svc, repo, _, _ := newTestService()
+	repo.On("FindRefreshTokenByHash", mock.Anything, mock.Anything).
+		Return(domain.RefreshToken{}, apperror.NewNotFoundError("Refresh token não encontrado."))
+
+	_, err := svc.RefreshTokens(context.Background(), "qualquer")
+
+	assert.IsType(t, &apperror.UnauthorizedError{}, err)
+}
+
+// TestLogout_Success testa a revogação do access token e da sessão.
+func TestLogout_Success(t *testing.T) {
+	svc, repo, _, revoker := newTestService()
+	expiresAt := time.Now().Add(10 * time.Minute)
+
+	revoker.On("RevokeToken", mock.Anything, "jti-1", expiresAt).Return(nil)
+	repo.On("RevokeRefreshTokenFamily", mock.Anything, "fam-1").Return(nil)
+	revoker.On("RevokeSession", mock.Anything, "fam-1", 15*time.Minute).Return(nil)
+
+	err := svc.Logout(context.Background(), "user-1", "jti-1", "fam-1", expiresAt)
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+	revoker.AssertExpectations(t)
+}
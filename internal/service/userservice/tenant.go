@@ -0,0 +1,90 @@
+package userservice
+
+import (
+	"context"
+	"errors"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/tenant"
+)
+
+// TenantFinder é o contrato de consulta das empresas usado nas regras de conta (tenantrepo.TenantRepository).
+type TenantFinder interface {
+	FindTenant(ctx domain.Context, id string) (domain.Tenant, error)
+	CountTenantUsers(ctx domain.Context, id string) (int, error)
+}
+
+// SetTenants aplica as configurações das empresas às contas: empresas desativadas não autenticam,
+// o registro público depende de AllowRegistration e novas contas respeitam MaxUsers. Sem esta
+// configuração, todas as contas pertencem ao tenant padrão sem restrições.
+func (s *UserService) SetTenants(tenants TenantFinder) {
+	s.tenants = tenants
+}
+
+// userTenant retorna a empresa do usuário (ou a do contexto, para contas ainda não gravadas).
+func (s *UserService) userTenant(ctx context.Context, tenantID string) (domain.Tenant, error) {
+	if tenantID == "" {
+		tenantID = tenant.ID(ctx)
+	}
+	found, err := s.tenants.FindTenant(ctx, tenantID)
+	var notFoundErr *apperror.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return domain.Tenant{}, apperror.NewUnauthorizedError("Empresa não encontrada.")
+	}
+	return found, err
+}
+
+// checkTenantActive recusa a autenticação de usuários de empresas desativadas.
+func (s *UserService) checkTenantActive(ctx context.Context, user domain.User) error {
+	if s.tenants == nil {
+		return nil
+	}
+	found, err := s.userTenant(ctx, user.TenantID)
+	if err != nil {
+		return err
+	}
+	if found.Disabled {
+		s.logger.WithContext(ctx).Warn("Autenticação recusada: empresa desativada.", map[string]interface{}{"user_id": user.ID, "tenant_id": found.ID})
+		return apperror.NewUnauthorizedError("Empresa desativada. Procure o suporte.")
+	}
+	return nil
+}
+
+// checkRegistrationAllowed recusa o registro público quando a empresa não o aceita.
+func (s *UserService) checkRegistrationAllowed(ctx context.Context) error {
+	if s.tenants == nil {
+		return nil
+	}
+	found, err := s.userTenant(ctx, "")
+	if err != nil {
+		return err
+	}
+	if found.Disabled || !found.Settings.AllowRegistration {
+		return apperror.NewForbiddenError("O registro público está desativado para esta empresa. Solicite um convite.")
+	}
+	return nil
+}
+
+// checkUserLimit recusa novas contas quando a empresa atingiu o limite de usuários (MaxUsers).
+func (s *UserService) checkUserLimit(ctx context.Context, tenantID string) error {
+	if s.tenants == nil {
+		return nil
+	}
+	found, err := s.userTenant(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if found.Settings.MaxUsers <= 0 {
+		return nil
+	}
+	count, err := s.tenants.CountTenantUsers(ctx, found.ID)
+	if err != nil {
+		return err
+	}
+	if count >= found.Settings.MaxUsers {
+		s.logger.WithContext(ctx).Warn("Limite de usuários da empresa atingido.", map[string]interface{}{"tenant_id": found.ID, "max_users": found.Settings.MaxUsers})
+		return apperror.NewConflictError("A empresa atingiu o limite de usuários do plano.")
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package userservice_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+
+	"gostock/internal/domain"
+	apperror "gostock/internal/errors"
+	"gostock/internal/pkg/tenant"
+)
+
+// MockTenantFinder é uma implementação mock da interface TenantFinder
+type MockTenantFinder struct {
+	mock.Mock
+}
+
+func (m *MockTenantFinder) FindTenant(ctx domain.Context, id string) (domain.Tenant, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.Tenant), args.Error(1)
+}
+
+func (m *MockTenantFinder) CountTenantUsers(ctx domain.Context, id string) (int, error) {
+	args := m.Called(ctx, id)
+	return args.Int(0), args.Error(1)
+}
+
+const acmeTenantID = "7b0c6a52-3f1e-4b8e-9d2a-0c6f1e2d3a4b"
+
+// TestLogin_Fail_TenantDisabled testa que usuários de empresas desativadas não recebem tokens.
+func TestLogin_Fail_TenantDisabled(t *testing.T) {
+	svc, repo, tokens, _ := newTestService()
+	tenants := new(MockTenantFinder)
+	svc.SetTenants(tenants)
+	hash, _ := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.MinCost)
+	user := domain.User{ID: "user-1", Email: "a@acme.com", PasswordHash: string(hash), Role: domain.RoleAdmin, TenantID: acmeTenantID}
+
+	repo.On("FindByEmail", mock.Anything, "a@acme.com").Return(user, nil)
+	tenants.On("FindTenant", mock.Anything, acmeTenantID).Return(domain.Tenant{ID: acmeTenantID, Disabled: true}, nil)
+
+	_, err := svc.Login(context.Background(), "a@acme.com", "senha123")
+
+	assert.IsType(t, &apperror.UnauthorizedError{}, err)
+	tokens.AssertNotCalled(t, "GenerateToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestLogin_Success_TenantInToken testa que o access token carrega a empresa do usuário.
+func TestLogin_Success_TenantInToken(t *testing.T) {
+	svc, repo, tokens, _ := newTestService()
+	tenants := new(MockTenantFinder)
+	svc.SetTenants(tenants)
+	hash, _ := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.MinCost)
+	user := domain.User{ID: "user-1", Email: "a@acme.com", PasswordHash: string(hash), Role: domain.RoleAdmin, TenantID: acmeTenantID}
+
+	repo.On("FindByEmail", mock.Anything, "a@acme.com").Return(user, nil)
+	repo.On("SaveRefreshToken", mock.Anything, mock.AnythingOfType("domain.RefreshToken")).Return(nil)
+	tenants.On("FindTenant", mock.Anything, acmeTenantID).Return(domain.Tenant{ID: acmeTenantID}, nil)
+	tokens.On("GenerateToken", "user-1", "admin", acmeTenantID, mock.AnythingOfType("string")).Return("jwt", nil)
+
+	_, err := svc.Login(context.Background(), "a@acme.com", "senha123")
+
+	assert.NoError(t, err)
+	tokens.AssertExpectations(t)
+}
+
+// TestRegister_Fail_RegistrationDisabled testa que o registro público respeita a configuração da empresa.
+func TestRegister_Fail_RegistrationDisabled(t *testing.T) {
+	svc, repo, _, _ := newTestService()
+	tenants := new(MockTenantFinder)
+	svc.SetTenants(tenants)
+	ctx := tenant.WithID(context.Background(), acmeTenantID)
+
+	tenants.On("FindTenant", ctx, acmeTenantID).Return(domain.Tenant{ID: acmeTenantID, Settings: domain.TenantSettings{AllowRegistration: false}}, nil)
+
+	_, err := svc.Register(ctx, domain.UserRegistration{Email: "novo@acme.com", Password: "senha123"})
+
+	assert.IsType(t, &apperror.UnauthorizedError{}, err)
+	repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+// TestRegister_Fail_UserLimitReached testa o limite de usuários (MaxUsers) da empresa.
+func TestRegister_Fail_UserLimitReached(t *testing.T) {
+	svc, repo, _, _ := newTestService()
+	tenants := new(MockTenantFinder)
+	svc.SetTenants(tenants)
+	ctx := tenant.WithID(context.Background(), acmeTenantID)
+
+	acme := domain.Tenant{ID: acmeTenantID, Settings: domain.TenantSettings{AllowRegistration: true, MaxUsers: 5}}
+	tenants.On("FindTenant", ctx, acmeTenantID).Return(acme, nil)
+	tenants.On("CountTenantUsers", ctx, acmeTenantID).Return(5, nil)
+
+	_, err := svc.Register(ctx, domain.UserRegistration{Email: "novo@acme.com", Password: "senha123"})
+
+	assert.IsType(t, &apperror.ConflictError{}, err)
+	repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
@@ -19,6 +19,8 @@ type WarehouseRepository interface {
 	GetAllWarehouses(ctx context.Context) ([]domain.Warehouse, error)
 	UpdateWarehouse(ctx context.Context, warehouse domain.Warehouse) (domain.Warehouse, error)
 	DeleteWarehouse(ctx context.Context, id string) error
+	GetUserWarehouses(ctx context.Context, userID string) (domain.UserWarehouses, error)
+	SetUserWarehouses(ctx context.Context, assignment domain.UserWarehouses) (domain.UserWarehouses, error)
 }
 
 // Service é a estrutura que implementa a interface domain.WarehouseService (a ser definida).
@@ -34,129 +36,183 @@ func NewService(repo WarehouseRepository, logger logger.Logger) *Service {
 
 // CreateWarehouse cria um novo armazém após validações de negócio.
 func (s *Service) CreateWarehouse(ctx domain.Context, warehouse domain.Warehouse) (domain.Warehouse, error) {
-	s.logger.Debug("Iniciando criação de armazém no serviço.", map[string]interface{}{"name": warehouse.Name})
+	s.logger.WithContext(ctx).Debug("Iniciando criação de armazém no serviço.", map[string]interface{}{"name": warehouse.Name})
 
 	if err := s.validateWarehouseName(warehouse.Name); err != nil {
-		s.logger.Warn("Falha na validação do nome do armazém.", map[string]interface{}{"name": warehouse.Name, "error": err.Error()})
+		s.logger.WithContext(ctx).Warn("Falha na validação do nome do armazém.", map[string]interface{}{"name": warehouse.Name, "error": err.Error()})
 		return domain.Warehouse{}, err
 	}
 
 	ctxGo, ok := ctx.(context.Context)
 	if !ok {
 		ctxGo = context.Background()
-		s.logger.Warn("Contexto de domínio inválido, usando context.Background() para CreateWarehouse", nil)
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background() para CreateWarehouse", nil)
 	}
 
 	createdWarehouse, err := s.repo.CreateWarehouse(ctxGo, warehouse)
 	if err != nil {
-		s.logger.Error("Falha ao criar armazém no repositório.", err)
+		s.logger.WithContext(ctx).Error("Falha ao criar armazém no repositório.", err)
 		return domain.Warehouse{}, apperror.NewInternalError("Falha interna ao criar armazém.", err)
 	}
 
-	s.logger.Info("Armazém criado com sucesso.", map[string]interface{}{"id": createdWarehouse.ID, "name": createdWarehouse.Name})
+	s.logger.WithContext(ctx).Info("Armazém criado com sucesso.", map[string]interface{}{"id": createdWarehouse.ID, "name": createdWarehouse.Name})
 	return createdWarehouse, nil
 }
 
 // GetWarehouseByID busca um armazém pelo ID após validações de formato.
 func (s *Service) GetWarehouseByID(ctx domain.Context, id string) (domain.Warehouse, error) {
-	s.logger.Debug("Iniciando busca de armazém por ID no serviço.", map[string]interface{}{"id": id})
+	s.logger.WithContext(ctx).Debug("Iniciando busca de armazém por ID no serviço.", map[string]interface{}{"id": id})
 
 	if _, err := uuid.Parse(id); err != nil {
-		s.logger.Warn("ID de armazém inválido fornecido.", map[string]interface{}{"id": id, "error": err.Error()})
+		s.logger.WithContext(ctx).Warn("ID de armazém inválido fornecido.", map[string]interface{}{"id": id, "error": err.Error()})
 		return domain.Warehouse{}, apperror.NewValidationError("O ID do armazém deve ser um UUID válido.")
 	}
 
 	ctxGo, ok := ctx.(context.Context)
 	if !ok {
 		ctxGo = context.Background()
-		s.logger.Warn("Contexto de domínio inválido, usando context.Background() para GetWarehouseByID", nil)
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background() para GetWarehouseByID", nil)
 	}
 
 	warehouse, err := s.repo.GetWarehouseByID(ctxGo, id)
 	if err != nil {
-		s.logger.Error("Falha ao buscar armazém no repositório.", err)
+		s.logger.WithContext(ctx).Error("Falha ao buscar armazém no repositório.", err)
 		return domain.Warehouse{}, err // Erros do repositório já são NotFoundError ou DBError
 	}
 
-	s.logger.Info("Armazém encontrado.", map[string]interface{}{"id": warehouse.ID, "name": warehouse.Name})
+	s.logger.WithContext(ctx).Info("Armazém encontrado.", map[string]interface{}{"id": warehouse.ID, "name": warehouse.Name})
 	return warehouse, nil
 }
 
 // GetAllWarehouses busca todos os armazéns.
 func (s *Service) GetAllWarehouses(ctx domain.Context) ([]domain.Warehouse, error) {
-	s.logger.Debug("Iniciando busca de todos os armazéns no serviço.", nil)
+	s.logger.WithContext(ctx).Debug("Iniciando busca de todos os armazéns no serviço.", nil)
 
 	ctxGo, ok := ctx.(context.Context)
 	if !ok {
 		ctxGo = context.Background()
-		s.logger.Warn("Contexto de domínio inválido, usando context.Background() para GetAllWarehouses", nil)
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background() para GetAllWarehouses", nil)
 	}
 
 	warehouses, err := s.repo.GetAllWarehouses(ctxGo)
 	if err != nil {
-		s.logger.Error("Falha ao buscar todos os armazéns no repositório.", err)
+		s.logger.WithContext(ctx).Error("Falha ao buscar todos os armazéns no repositório.", err)
 		return nil, apperror.NewInternalError("Falha interna ao buscar armazéns.", err)
 	}
 
-	s.logger.Info("Todos os armazéns encontrados com sucesso.", map[string]interface{}{"count": len(warehouses)})
+	s.logger.WithContext(ctx).Info("Todos os armazéns encontrados com sucesso.", map[string]interface{}{"count": len(warehouses)})
 	return warehouses, nil
 }
 
 // UpdateWarehouse atualiza um armazém existente.
 func (s *Service) UpdateWarehouse(ctx domain.Context, warehouse domain.Warehouse) (domain.Warehouse, error) {
-	s.logger.Debug("Iniciando atualização de armazém no serviço.", map[string]interface{}{"id": warehouse.ID, "name": warehouse.Name})
+	s.logger.WithContext(ctx).Debug("Iniciando atualização de armazém no serviço.", map[string]interface{}{"id": warehouse.ID, "name": warehouse.Name})
 
 	if _, err := uuid.Parse(warehouse.ID); err != nil {
-		s.logger.Warn("ID de armazém inválido fornecido para atualização.", map[string]interface{}{"id": warehouse.ID, "error": err.Error()})
+		s.logger.WithContext(ctx).Warn("ID de armazém inválido fornecido para atualização.", map[string]interface{}{"id": warehouse.ID, "error": err.Error()})
 		return domain.Warehouse{}, apperror.NewValidationError("O ID do armazém deve ser um UUID válido.")
 	}
 
 	if err := s.validateWarehouseName(warehouse.Name); err != nil {
-		s.logger.Warn("Falha na validação do nome do armazém para atualização.", map[string]interface{}{"name": warehouse.Name, "error": err.Error()})
+		s.logger.WithContext(ctx).Warn("Falha na validação do nome do armazém para atualização.", map[string]interface{}{"name": warehouse.Name, "error": err.Error()})
 		return domain.Warehouse{}, err
 	}
 
 	ctxGo, ok := ctx.(context.Context)
 	if !ok {
 		ctxGo = context.Background()
-		s.logger.Warn("Contexto de domínio inválido, usando context.Background() para UpdateWarehouse", nil)
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background() para UpdateWarehouse", nil)
 	}
 
 	updatedWarehouse, err := s.repo.UpdateWarehouse(ctxGo, warehouse)
 	if err != nil {
-		s.logger.Error("Falha ao atualizar armazém no repositório.", err)
+		s.logger.WithContext(ctx).Error("Falha ao atualizar armazém no repositório.", err)
 		return domain.Warehouse{}, err // Erros do repositório já são NotFoundError ou DBError
 	}
 
-	s.logger.Info("Armazém atualizado com sucesso.", map[string]interface{}{"id": updatedWarehouse.ID, "name": updatedWarehouse.Name})
+	s.logger.WithContext(ctx).Info("Armazém atualizado com sucesso.", map[string]interface{}{"id": updatedWarehouse.ID, "name": updatedWarehouse.Name})
 	return updatedWarehouse, nil
 }
 
 // DeleteWarehouse remove um armazém.
 func (s *Service) DeleteWarehouse(ctx domain.Context, id string) error {
-	s.logger.Debug("Iniciando exclusão de armazém no serviço.", map[string]interface{}{"id": id})
+	s.logger.WithContext(ctx).Debug("Iniciando exclusão de armazém no serviço.", map[string]interface{}{"id": id})
 
 	if _, err := uuid.Parse(id); err != nil {
-		s.logger.Warn("ID de armazém inválido fornecido para exclusão.", map[string]interface{}{"id": id, "error": err.Error()})
+		s.logger.WithContext(ctx).Warn("ID de armazém inválido fornecido para exclusão.", map[string]interface{}{"id": id, "error": err.Error()})
 		return apperror.NewValidationError("O ID do armazém deve ser um UUID válido.")
 	}
 
 	ctxGo, ok := ctx.(context.Context)
 	if !ok {
 		ctxGo = context.Background()
-		s.logger.Warn("Contexto de domínio inválido, usando context.Background() para DeleteWarehouse", nil)
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background() para DeleteWarehouse", nil)
 	}
 
 	err := s.repo.DeleteWarehouse(ctxGo, id)
 	if err != nil {
-		s.logger.Error("Falha ao deletar armazém no repositório.", err)
+		s.logger.WithContext(ctx).Error("Falha ao deletar armazém no repositório.", err)
 		return err // Erros do repositório já são NotFoundError ou DBError
 	}
 
-	s.logger.Info("Armazém deletado com sucesso.", map[string]interface{}{"id": id})
+	s.logger.WithContext(ctx).Info("Armazém deletado com sucesso.", map[string]interface{}{"id": id})
 	return nil
 }
 
+// GetUserWarehouses retorna os armazéns atribuídos a um usuário.
+func (s *Service) GetUserWarehouses(ctx domain.Context, userID string) (domain.UserWarehouses, error) {
+	if _, err := uuid.Parse(userID); err != nil {
+		return domain.UserWarehouses{}, apperror.NewValidationError("O ID do usuário deve ser um UUID válido.")
+	}
+
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background() para GetUserWarehouses", nil)
+	}
+
+	return s.repo.GetUserWarehouses(ctxGo, userID) // NotFoundError ou DBError
+}
+
+// SetUserWarehouses substitui os armazéns atribuídos a um usuário. Uma lista vazia remove todas
+// as atribuições; administradores têm acesso global e não dependem delas.
+func (s *Service) SetUserWarehouses(ctx domain.Context, assignment domain.UserWarehouses) (domain.UserWarehouses, error) {
+	s.logger.WithContext(ctx).Debug("Iniciando atribuição de armazéns ao usuário no serviço.", map[string]interface{}{"user_id": assignment.UserID})
+
+	if _, err := uuid.Parse(assignment.UserID); err != nil {
+		return domain.UserWarehouses{}, apperror.NewValidationError("O ID do usuário deve ser um UUID válido.")
+	}
+	// Normaliza e remove duplicatas, preservando a ordem informada
+	seen := make(map[string]bool, len(assignment.WarehouseIDs))
+	warehouseIDs := make([]string, 0, len(assignment.WarehouseIDs))
+	for _, id := range assignment.WarehouseIDs {
+		parsed, err := uuid.Parse(strings.TrimSpace(id))
+		if err != nil {
+			return domain.UserWarehouses{}, apperror.NewValidationError("Os IDs de armazém ('warehouse_ids') devem ser UUIDs válidos.")
+		}
+		if !seen[parsed.String()] {
+			seen[parsed.String()] = true
+			warehouseIDs = append(warehouseIDs, parsed.String())
+		}
+	}
+	assignment.WarehouseIDs = warehouseIDs
+
+	ctxGo, ok := ctx.(context.Context)
+	if !ok {
+		ctxGo = context.Background()
+		s.logger.WithContext(ctx).Warn("Contexto de domínio inválido, usando context.Background() para SetUserWarehouses", nil)
+	}
+
+	saved, err := s.repo.SetUserWarehouses(ctxGo, assignment)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Falha ao atribuir armazéns ao usuário no repositório.", err)
+		return domain.UserWarehouses{}, err // Erros do repositório já são NotFoundError ou DBError
+	}
+
+	s.logger.WithContext(ctx).Info("Armazéns atribuídos ao usuário.", map[string]interface{}{"user_id": saved.UserID, "warehouses": len(saved.WarehouseIDs)})
+	return saved, nil
+}
+
 // validateWarehouseName é uma função auxiliar para validar o nome do armazém.
 func (s *Service) validateWarehouseName(name string) error {
 	if strings.TrimSpace(name) == "" {
@@ -3,6 +3,7 @@ package warehouseservice_test
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -46,6 +47,16 @@ func (m *MockWarehouseRepository) DeleteWarehouse(ctx context.Context, id string
 	return args.Error(0)
 }
 
+func (m *MockWarehouseRepository) GetUserWarehouses(ctx context.Context, userID string) (domain.UserWarehouses, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(domain.UserWarehouses), args.Error(1)
+}
+
+func (m *MockWarehouseRepository) SetUserWarehouses(ctx context.Context, assignment domain.UserWarehouses) (domain.UserWarehouses, error) {
+	args := m.Called(ctx, assignment)
+	return args.Get(0).(domain.UserWarehouses), args.Error(1)
+}
+
 // Helper function to create a basic logger
 func newTestLogger() logger.Logger {
 	return logger.NewLogger("debug") // Or a mock logger if you want to assert logs
@@ -368,3 +379,47 @@ func TestDeleteWarehouse_Fail_RepoError(t *testing.T) {
 	assert.Equal(t, repoError, err)
 	mockRepo.AssertExpectations(t)
 }
+
+// --- Testes para SetUserWarehouses ---
+
+func TestSetUserWarehouses_Success_Deduplicates(t *testing.T) {
+	mockRepo := new(MockWarehouseRepository)
+	svc := warehouseservice.NewService(mockRepo, newTestLogger())
+
+	userID, warehouseID := uuid.New().String(), uuid.New().String()
+	expected := domain.UserWarehouses{UserID: userID, WarehouseIDs: []string{warehouseID}}
+	mockRepo.On("SetUserWarehouses", mock.Anything, expected).Return(expected, nil)
+
+	result, err := svc.SetUserWarehouses(context.Background(), domain.UserWarehouses{
+		UserID:       userID,
+		WarehouseIDs: []string{warehouseID, " " + strings.ToUpper(warehouseID) + " "},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSetUserWarehouses_Fail_InvalidWarehouseID(t *testing.T) {
+	mockRepo := new(MockWarehouseRepository)
+	svc := warehouseservice.NewService(mockRepo, newTestLogger())
+
+	_, err := svc.SetUserWarehouses(context.Background(), domain.UserWarehouses{UserID: uuid.New().String(), WarehouseIDs: []string{"loja-1"}})
+
+	var validationErr *apperror.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	mockRepo.AssertNotCalled(t, "SetUserWarehouses", mock.Anything, mock.Anything)
+}
+
+func TestSetUserWarehouses_Fail_UnknownWarehouse(t *testing.T) {
+	mockRepo := new(MockWarehouseRepository)
+	svc := warehouseservice.NewService(mockRepo, newTestLogger())
+
+	mockRepo.On("SetUserWarehouses", mock.Anything, mock.Anything).
+		Return(domain.UserWarehouses{}, apperror.NewNotFoundError("Um ou mais armazéns informados não existem."))
+
+	_, err := svc.SetUserWarehouses(context.Background(), domain.UserWarehouses{UserID: uuid.New().String(), WarehouseIDs: []string{uuid.New().String()}})
+
+	var notFound *apperror.NotFoundError
+	assert.ErrorAs(t, err, &notFound)
+}
@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE price_lists (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL UNIQUE,
+    currency CHAR(3) NOT NULL,
+    customer_segment VARCHAR(100) NOT NULL DEFAULT '',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Valores em unidades mínimas da moeda (ex.: centavos); valid_to é exclusivo.
+CREATE TABLE variant_prices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    price_list_id UUID NOT NULL REFERENCES price_lists(id) ON DELETE CASCADE,
+    variant_id UUID NOT NULL REFERENCES variants(id) ON DELETE CASCADE,
+    amount BIGINT NOT NULL CHECK (amount >= 0),
+    valid_from TIMESTAMP WITH TIME ZONE,
+    valid_to TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT variant_prices_valid_window CHECK (valid_to IS NULL OR valid_from IS NULL OR valid_to > valid_from)
+);
+
+CREATE INDEX idx_variant_prices_lookup ON variant_prices (price_list_id, variant_id, valid_from DESC);
+
+-- +goose Down
+DROP TABLE variant_prices;
+DROP TABLE price_lists;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Histórico de versões de produtos: snapshot completo (produto + variantes) e diffs por campo.
+CREATE TABLE product_versions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    product_id UUID NOT NULL,
+    version INT NOT NULL,
+    change_type VARCHAR(20) NOT NULL,
+    changes JSONB NOT NULL DEFAULT '[]',
+    snapshot JSONB NOT NULL,
+    changed_by UUID,
+    changed_by_role VARCHAR(50) NOT NULL DEFAULT '',
+    reverted_to INT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_product_version UNIQUE (product_id, version)
+);
+
+-- +goose Down
+DROP TABLE product_versions;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Unidades de medida por variante. O estoque (stock_levels.quantity) é armazenado em unidades-base,
+-- escaladas por 10^decimal_places em variantes decimais (ex.: kg com 3 casas = gramas).
+CREATE TABLE variant_units (
+    variant_id UUID PRIMARY KEY,
+    base_unit VARCHAR(20) NOT NULL,
+    decimal_places SMALLINT NOT NULL DEFAULT 0 CHECK (decimal_places BETWEEN 0 AND 6),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Fatores de conversão: quantas unidades-base cabem em cada unidade (ex.: cx = 12, pallet = 480).
+CREATE TABLE variant_unit_conversions (
+    variant_id UUID NOT NULL REFERENCES variant_units(variant_id) ON DELETE CASCADE,
+    unit VARCHAR(20) NOT NULL,
+    factor NUMERIC(20, 6) NOT NULL CHECK (factor > 0),
+    PRIMARY KEY (variant_id, unit)
+);
+
+-- +goose Down
+DROP TABLE variant_unit_conversions;
+DROP TABLE variant_units;
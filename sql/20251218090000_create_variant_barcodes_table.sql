@@ -0,0 +1,15 @@
+-- +goose Up
+-- Códigos de barras adicionais por variante (ex.: o código da caixa, além do código da unidade).
+-- O código principal continua em variants.barcode; 'unit' indica a unidade de medida que o código
+-- representa (ver variant_units) e fica vazio quando o código identifica a unidade-base.
+CREATE TABLE variant_barcodes (
+    barcode VARCHAR(64) PRIMARY KEY,
+    variant_id UUID NOT NULL,
+    unit VARCHAR(20) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_variant_barcodes_variant_id ON variant_barcodes (variant_id);
+
+-- +goose Down
+DROP TABLE variant_barcodes;
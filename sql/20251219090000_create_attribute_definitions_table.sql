@@ -0,0 +1,27 @@
+-- +goose Up
+-- Definições de atributos de variantes (eixos como "Cor" e "Tamanho") com os valores permitidos.
+CREATE TABLE attribute_definitions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(50) NOT NULL,
+    allowed_values JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX unique_attribute_definition_name ON attribute_definitions (LOWER(name));
+
+-- Uma variante passa a ser uma combinação de atributos (ex.: {"Cor": "Vermelho", "Tamanho": "M"}).
+-- As colunas attribute/value são mantidas como rótulo derivado para leitores legados.
+ALTER TABLE variants ADD COLUMN attributes JSONB NOT NULL DEFAULT '{}';
+
+UPDATE variants
+SET attributes = jsonb_build_object(attribute, value)
+WHERE attribute IS NOT NULL AND attribute <> '';
+
+-- Cada combinação de atributos é única dentro do produto.
+CREATE UNIQUE INDEX unique_variant_attributes ON variants (product_id, attributes);
+
+-- +goose Down
+DROP INDEX unique_variant_attributes;
+ALTER TABLE variants DROP COLUMN attributes;
+DROP TABLE attribute_definitions;
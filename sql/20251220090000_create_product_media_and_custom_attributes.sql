@@ -0,0 +1,44 @@
+-- +goose Up
+-- Categoria e especificações livres do produto (peso, dimensões, material...), validadas pelo
+-- esquema da categoria e guardadas como JSONB para permitir filtros por contenção.
+ALTER TABLE products ADD COLUMN category VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE products ADD COLUMN custom_attributes JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_products_category ON products (LOWER(category));
+CREATE INDEX idx_products_custom_attributes ON products USING GIN (custom_attributes jsonb_path_ops);
+
+-- Esquema de atributos personalizados por categoria: lista de campos tipados
+-- ({"name", "type", "required", "values"}), um registro por categoria.
+CREATE TABLE category_schemas (
+    category VARCHAR(100) PRIMARY KEY,
+    fields JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX unique_category_schema_name ON category_schemas (LOWER(category));
+
+-- Imagens e demais mídias do produto. 'path' é preenchido apenas para arquivos enviados ao
+-- armazenamento local; mídias externas guardam só a URL. variant_id associa a mídia a uma variante.
+CREATE TABLE product_media (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    variant_id UUID,
+    url TEXT NOT NULL,
+    path TEXT NOT NULL DEFAULT '',
+    content_type VARCHAR(100) NOT NULL DEFAULT '',
+    alt_text VARCHAR(255) NOT NULL DEFAULT '',
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_product_media_product ON product_media (product_id, sort_order);
+
+-- +goose Down
+DROP TABLE product_media;
+DROP TABLE category_schemas;
+DROP INDEX idx_products_custom_attributes;
+DROP INDEX idx_products_category;
+ALTER TABLE products DROP COLUMN custom_attributes;
+ALTER TABLE products DROP COLUMN category;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Refresh tokens rotativos. Apenas o hash SHA-256 do token é gravado; tokens da mesma sessão
+-- (login) compartilham o family_id, usado para revogar a sessão inteira.
+CREATE TABLE refresh_tokens (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    family_id UUID NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    replaced_by UUID, -- Preenchido quando o token é trocado por um novo (rotação)
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_refresh_tokens_family ON refresh_tokens (family_id);
+CREATE INDEX idx_refresh_tokens_user ON refresh_tokens (user_id);
+
+-- +goose Down
+DROP TABLE refresh_tokens;
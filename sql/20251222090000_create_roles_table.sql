@@ -0,0 +1,27 @@
+-- +goose Up
+-- Papéis configuráveis: cada papel é um conjunto de permissões. O papel 'admin' tem todas ('*').
+CREATE TABLE roles (
+    name VARCHAR(50) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    permissions JSONB NOT NULL DEFAULT '[]',
+    built_in BOOLEAN NOT NULL DEFAULT FALSE, -- Papéis do sistema não podem ser removidos
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO roles (name, description, permissions, built_in) VALUES
+    ('admin', 'Administrador: todas as permissões', '["*"]', TRUE),
+    ('user', 'Usuário autenticado: apenas leitura', '[]', TRUE),
+    ('guest', 'Convidado: apenas leitura', '[]', TRUE),
+    ('warehouse_staff', 'Equipe de armazém: ajuste de estoque', '["stock:adjust"]', FALSE);
+
+-- Papéis já atribuídos a usuários e não previstos acima são preservados (sem permissões)
+INSERT INTO roles (name, permissions)
+SELECT DISTINCT role, '[]'::jsonb FROM users
+WHERE role NOT IN (SELECT name FROM roles);
+
+ALTER TABLE users ADD CONSTRAINT fk_users_role FOREIGN KEY (role) REFERENCES roles(name);
+
+-- +goose Down
+ALTER TABLE users DROP CONSTRAINT fk_users_role;
+DROP TABLE roles;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Armazéns atribuídos a cada usuário: quem não é administrador só opera o estoque desses armazéns.
+CREATE TABLE user_warehouses (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    warehouse_id UUID NOT NULL REFERENCES warehouses(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, warehouse_id)
+);
+
+CREATE INDEX idx_user_warehouses_warehouse_id ON user_warehouses (warehouse_id);
+
+-- +goose Down
+DROP TABLE user_warehouses;
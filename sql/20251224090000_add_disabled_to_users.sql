@@ -0,0 +1,6 @@
+-- +goose Up
+-- Contas desativadas pelo administrador: o login é recusado e os tokens emitidos são invalidados.
+ALTER TABLE users ADD COLUMN disabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN disabled;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Tokens de uso único enviados por e-mail (redefinição de senha e verificação de e-mail).
+-- Apenas o hash SHA-256 do token é gravado.
+CREATE TABLE user_tokens (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(30) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE -- Preenchido quando o token é usado ou invalidado
+);
+
+CREATE INDEX idx_user_tokens_user_purpose ON user_tokens (user_id, purpose);
+
+-- Contas existentes são consideradas verificadas; novas contas do registro público começam sem verificação.
+ALTER TABLE users ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE users SET email_verified = TRUE;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN email_verified;
+DROP TABLE user_tokens;
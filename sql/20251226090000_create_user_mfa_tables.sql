@@ -0,0 +1,25 @@
+-- +goose Up
+-- Autenticação em dois fatores (TOTP, RFC 6238). O segredo é gravado cifrado (AES-GCM).
+-- Enquanto enabled = FALSE, o cadastro está pendente de confirmação com o primeiro código.
+CREATE TABLE user_mfa (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    last_used_step BIGINT, -- Último intervalo de 30s aceito (impede o reuso de um código)
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    enabled_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Códigos de recuperação de uso único (apenas o hash SHA-256).
+CREATE TABLE user_recovery_codes (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash CHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (user_id, code_hash)
+);
+
+-- +goose Down
+DROP TABLE user_recovery_codes;
+DROP TABLE user_mfa;
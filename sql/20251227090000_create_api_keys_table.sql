@@ -0,0 +1,24 @@
+-- +goose Up
+-- Contas de serviço: usuários sem senha, usados apenas por integrações (ERP, e-commerce) com chaves de API.
+ALTER TABLE users ADD COLUMN service_account BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Chaves de API: o prefixo identifica a chave e é exibido nas listagens; do segredo, apenas o hash SHA-256.
+CREATE TABLE api_keys (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE, -- Dono da chave (usuário ou conta de serviço)
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(32) NOT NULL UNIQUE,
+    key_hash CHAR(64) NOT NULL,
+    scopes JSONB NOT NULL DEFAULT '[]', -- Permissões liberadas para a chave (limitadas às do papel do dono)
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_api_keys_user_id ON api_keys (user_id);
+
+-- +goose Down
+DROP TABLE api_keys;
+ALTER TABLE users DROP COLUMN service_account;
@@ -0,0 +1,110 @@
+-- +goose Up
+-- Empresas (tenants) que compartilham a instalação. Cada tabela de negócio ganha tenant_id e os
+-- repositórios filtram todas as consultas por ele. O tenant padrão recebe os dados já existentes
+-- e é o da operação da plataforma (cadastro de empresas e papéis).
+CREATE TABLE tenants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    slug VARCHAR(63) NOT NULL UNIQUE, -- Identificador público (header X-Tenant)
+    name VARCHAR(255) NOT NULL,
+    settings JSONB NOT NULL DEFAULT '{}', -- domain.TenantSettings
+    disabled BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO tenants (id, slug, name, settings)
+VALUES ('00000000-0000-0000-0000-000000000001', 'default', 'GoStock',
+        '{"default_currency": "BRL", "timezone": "America/Sao_Paulo", "locale": "pt-BR", "allow_registration": true}');
+
+ALTER TABLE users ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+ALTER TABLE warehouses ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+ALTER TABLE products ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+ALTER TABLE variants ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+ALTER TABLE stock_levels ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+ALTER TABLE price_lists ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+ALTER TABLE variant_prices ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+ALTER TABLE product_versions ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+ALTER TABLE variant_units ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+ALTER TABLE variant_unit_conversions ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+ALTER TABLE variant_barcodes ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+ALTER TABLE attribute_definitions ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+ALTER TABLE category_schemas ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+ALTER TABLE product_media ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+ALTER TABLE user_warehouses ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+
+CREATE INDEX idx_users_tenant_id ON users (tenant_id);
+CREATE INDEX idx_variants_tenant_id ON variants (tenant_id);
+CREATE INDEX idx_stock_levels_tenant_id ON stock_levels (tenant_id);
+CREATE INDEX idx_variant_prices_tenant_id ON variant_prices (tenant_id);
+CREATE INDEX idx_product_media_tenant_id ON product_media (tenant_id);
+
+-- Unicidades passam a valer dentro de cada empresa (o e-mail do usuário continua global: o login
+-- identifica a conta e, por ela, a empresa).
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_sku_key;
+CREATE UNIQUE INDEX unique_products_tenant_sku ON products (tenant_id, sku);
+
+ALTER TABLE variants DROP CONSTRAINT IF EXISTS variants_barcode_key;
+ALTER TABLE variants DROP CONSTRAINT IF EXISTS product_variants_barcode_key;
+CREATE UNIQUE INDEX unique_variants_tenant_barcode ON variants (tenant_id, barcode);
+
+ALTER TABLE warehouses DROP CONSTRAINT IF EXISTS warehouses_name_key;
+CREATE UNIQUE INDEX unique_warehouses_tenant_name ON warehouses (tenant_id, name);
+
+ALTER TABLE price_lists DROP CONSTRAINT IF EXISTS price_lists_name_key;
+CREATE UNIQUE INDEX unique_price_lists_tenant_name ON price_lists (tenant_id, name);
+
+DROP INDEX unique_attribute_definition_name;
+CREATE UNIQUE INDEX unique_attribute_definition_name ON attribute_definitions (tenant_id, LOWER(name));
+
+ALTER TABLE category_schemas DROP CONSTRAINT category_schemas_pkey;
+ALTER TABLE category_schemas ADD PRIMARY KEY (tenant_id, category);
+DROP INDEX unique_category_schema_name;
+CREATE UNIQUE INDEX unique_category_schema_name ON category_schemas (tenant_id, LOWER(category));
+
+ALTER TABLE variant_barcodes DROP CONSTRAINT variant_barcodes_pkey;
+ALTER TABLE variant_barcodes ADD PRIMARY KEY (tenant_id, barcode);
+
+ALTER TABLE stock_levels DROP CONSTRAINT unique_variant_warehouse;
+ALTER TABLE stock_levels ADD CONSTRAINT unique_variant_warehouse UNIQUE (tenant_id, variant_id, warehouse_id);
+
+-- +goose Down
+ALTER TABLE stock_levels DROP CONSTRAINT unique_variant_warehouse;
+ALTER TABLE stock_levels ADD CONSTRAINT unique_variant_warehouse UNIQUE (variant_id, warehouse_id);
+
+ALTER TABLE variant_barcodes DROP CONSTRAINT variant_barcodes_pkey;
+ALTER TABLE variant_barcodes ADD PRIMARY KEY (barcode);
+
+DROP INDEX unique_category_schema_name;
+ALTER TABLE category_schemas DROP CONSTRAINT category_schemas_pkey;
+ALTER TABLE category_schemas ADD PRIMARY KEY (category);
+CREATE UNIQUE INDEX unique_category_schema_name ON category_schemas (LOWER(category));
+
+DROP INDEX unique_attribute_definition_name;
+CREATE UNIQUE INDEX unique_attribute_definition_name ON attribute_definitions (LOWER(name));
+
+DROP INDEX unique_price_lists_tenant_name;
+ALTER TABLE price_lists ADD CONSTRAINT price_lists_name_key UNIQUE (name);
+DROP INDEX unique_warehouses_tenant_name;
+ALTER TABLE warehouses ADD CONSTRAINT warehouses_name_key UNIQUE (name);
+DROP INDEX unique_variants_tenant_barcode;
+ALTER TABLE variants ADD CONSTRAINT variants_barcode_key UNIQUE (barcode);
+DROP INDEX unique_products_tenant_sku;
+ALTER TABLE products ADD CONSTRAINT products_sku_key UNIQUE (sku);
+
+ALTER TABLE user_warehouses DROP COLUMN tenant_id;
+ALTER TABLE product_media DROP COLUMN tenant_id;
+ALTER TABLE category_schemas DROP COLUMN tenant_id;
+ALTER TABLE attribute_definitions DROP COLUMN tenant_id;
+ALTER TABLE variant_barcodes DROP COLUMN tenant_id;
+ALTER TABLE variant_unit_conversions DROP COLUMN tenant_id;
+ALTER TABLE variant_units DROP COLUMN tenant_id;
+ALTER TABLE product_versions DROP COLUMN tenant_id;
+ALTER TABLE variant_prices DROP COLUMN tenant_id;
+ALTER TABLE price_lists DROP COLUMN tenant_id;
+ALTER TABLE stock_levels DROP COLUMN tenant_id;
+ALTER TABLE variants DROP COLUMN tenant_id;
+ALTER TABLE products DROP COLUMN tenant_id;
+ALTER TABLE warehouses DROP COLUMN tenant_id;
+ALTER TABLE users DROP COLUMN tenant_id;
+
+DROP TABLE tenants;